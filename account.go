@@ -25,7 +25,7 @@ type accounts struct {
 }
 
 func (a *accounts) create(w http.ResponseWriter, r *http.Request) {
-	var email string
+	var email, region string
 	fromCLI := true
 	memoryMode := false
 	bypassStripe := false
@@ -41,8 +41,10 @@ func (a *accounts) create(w http.ResponseWriter, r *http.Request) {
 		}
 
 		email = strings.ToLower(r.Form.Get("email"))
+		region = r.Form.Get("region")
 	} else {
 		email = strings.ToLower(r.URL.Query().Get("email"))
+		region = r.URL.Query().Get("region")
 
 		if config.Current.AppEnv != AppEnvProd {
 			memoryMode = r.URL.Query().Get("mem") == "1"
@@ -65,6 +67,12 @@ func (a *accounts) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the tenant is created in the region keeping its data
+	region, ok := databaseRegion(w, region)
+	if !ok {
+		return
+	}
+
 	exists, err := backend.DB.EmailExists(email)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -129,7 +137,13 @@ func (a *accounts) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bc, pw, err := a.createNewDatabase(cust.ID, email, active, memoryMode)
+	recordAdminEvent(r, model.AdminEvent{
+		Type:     model.AdminTenantCreated,
+		Actor:    email,
+		TenantID: cust.ID,
+	})
+
+	bc, pw, err := a.createNewDatabase(cust.ID, email, region, active, memoryMode)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -265,7 +279,12 @@ func (a *accounts) addDatabase(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bc, pw, err := a.createNewDatabase(conf.TenantID, auth.Email, conf.IsActive, false)
+	region, ok := databaseRegion(w, r.URL.Query().Get("region"))
+	if !ok {
+		return
+	}
+
+	bc, pw, err := a.createNewDatabase(conf.TenantID, auth.Email, region, conf.IsActive, false)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -322,7 +341,7 @@ func (a *accounts) addDatabase(w http.ResponseWriter, r *http.Request) {
 	respond(w, http.StatusOK, data)
 }
 
-func (a *accounts) createNewDatabase(tenantID, email string, active, memoryMode bool) (model.DatabaseConfig, string, error) {
+func (a *accounts) createNewDatabase(tenantID, email, region string, active, memoryMode bool) (model.DatabaseConfig, string, error) {
 	base := model.DatabaseConfig{}
 
 	// make sure the DB name is unique
@@ -349,6 +368,7 @@ func (a *accounts) createNewDatabase(tenantID, email string, active, memoryMode
 		Name:          dbName,
 		IsActive:      active,
 		AllowedDomain: []string{"localhost"},
+		Region:        region,
 	}
 
 	bc, err := backend.DB.CreateDatabase(base)
@@ -371,6 +391,23 @@ func (a *accounts) createNewDatabase(tenantID, email string, active, memoryMode
 	return bc, pw, nil
 }
 
+// databaseRegion returns the region of a new database, the instance's one
+// when none is requested. The databases of the other regions are created
+// by their instances, the request is refused when it's not ok.
+func databaseRegion(w http.ResponseWriter, region string) (string, bool) {
+	if len(region) == 0 || region == middleware.Region {
+		return middleware.Region, true
+	}
+
+	if _, ok := middleware.RegionURLs[region]; !ok {
+		http.Error(w, "unknown region "+region, http.StatusBadRequest)
+		return "", false
+	}
+
+	middleware.Misdirected(w, region)
+	return "", false
+}
+
 func (a *accounts) auth(w http.ResponseWriter, r *http.Request) {
 	_, auth, err := middleware.Extract(r, true)
 	if err != nil {
@@ -423,7 +460,10 @@ func (a *accounts) addUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method == http.MethodPost {
-		var data model.Login
+		var data = new(struct {
+			model.Login
+			Role int `json:"role"`
+		})
 		if err := parseBody(r.Body, &data); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
@@ -434,6 +474,14 @@ func (a *accounts) addUser(w http.ResponseWriter, r *http.Request) {
 
 		data.Email = strings.ToLower(data.Email)
 
+		if data.Role < 0 || data.Role >= 100 {
+			http.Error(w, "invalid role", http.StatusBadRequest)
+			return
+		} else if auth.Role < middleware.RootRole && data.Role >= auth.Role {
+			http.Error(w, "you can only add users with a role lower than yours", http.StatusUnauthorized)
+			return
+		}
+
 		if exists, err := backend.DB.UserEmailExists(conf.Name, data.Email); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -443,12 +491,14 @@ func (a *accounts) addUser(w http.ResponseWriter, r *http.Request) {
 		}
 
 		mship := backend.Membership(conf)
-		_, _, err = mship.CreateUser(auth.AccountID, data.Email, data.Password, 0)
+		_, tok, err := mship.CreateUser(auth.AccountID, data.Email, data.Password, data.Role)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		emitUserEvent(conf, model.WebhookUserCreated, tok)
+
 		respond(w, http.StatusOK, true)
 	}
 	users, err := backend.DB.ListUsers(conf.Name, auth.AccountID)
@@ -460,6 +510,80 @@ func (a *accounts) addUser(w http.ResponseWriter, r *http.Request) {
 	respond(w, http.StatusOK, users)
 }
 
+func (a *accounts) userReq(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		a.updateUser(w, r)
+	case http.MethodDelete:
+		a.deleteUser(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// updateUser changes the role of a member of the current account. Apart from
+// root, a user can only manage members with a lower role than their own and
+// cannot grant a role equal or higher than their own.
+func (a *accounts) updateUser(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data = new(struct {
+		Role int `json:"role"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 3)
+
+	u, err := backend.DB.GetUserByID(conf.Name, auth.AccountID, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// only root can manage a peer, others need a strictly higher role
+	if auth.Role < middleware.RootRole && (auth.Role <= u.Role || auth.Role <= data.Role) {
+		http.Error(w, "permission level not high enough", http.StatusUnauthorized)
+		return
+	} else if data.Role < 0 || data.Role >= 100 {
+		http.Error(w, "invalid role", http.StatusBadRequest)
+		return
+	}
+
+	if err := backend.DB.SetUserRole(conf.Name, u.Email, data.Role); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// active sessions of this user get the new role right away
+	token := fmt.Sprintf("%s|%s", u.ID, u.Token)
+
+	var member model.Auth
+	if err := backend.Cache.GetTyped(token, &member); err == nil {
+		member.Role = data.Role
+		if err := backend.Cache.SetTyped(token, member); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	recordAuthEvent(r, conf, model.AuditEvent{
+		AccountID: auth.AccountID,
+		UserID:    auth.UserID,
+		Email:     auth.Email,
+		Type:      model.AuditRoleChanged,
+		Detail:    fmt.Sprintf("%s role set to %d", u.Email, data.Role),
+	})
+
+	respond(w, http.StatusOK, true)
+}
+
 func (a *accounts) deleteUser(w http.ResponseWriter, r *http.Request) {
 	conf, auth, err := middleware.Extract(r, true)
 	if err != nil {
@@ -485,5 +609,17 @@ func (a *accounts) deleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	backend.ScheduleLinkedFilesDeletion(conf.Name, model.FileLink{UserID: id})
+
+	recordAuthEvent(r, conf, model.AuditEvent{
+		AccountID: auth.AccountID,
+		UserID:    auth.UserID,
+		Email:     auth.Email,
+		Type:      model.AuditUserDeleted,
+		Detail:    fmt.Sprintf("user %s (%s) removed", u.ID, u.Email),
+	})
+
+	emitUserEvent(conf, model.WebhookUserDeleted, u)
+
 	respond(w, http.StatusOK, true)
 }
@@ -1,10 +1,15 @@
 package staticbackend
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
 	"github.com/staticbackendhq/core/model"
 )
 
@@ -66,6 +71,67 @@ func TestUserAddRemoveFromAccount(t *testing.T) {
 	}
 }
 
+func TestAccountMemberRoles(t *testing.T) {
+	u := new(struct {
+		model.Login
+		Role int `json:"role"`
+	})
+	u.Email = "member@test.com"
+	u.Password = "member1234"
+	u.Role = 10
+
+	resp := dbReq(t, acct.addUser, "POST", "/account/users", u)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	tok, err := backend.DB.FindUserByEmail(dbName, u.Email)
+	if err != nil {
+		t.Fatal(err)
+	} else if tok.AccountID != testAccountID {
+		t.Errorf("expected member account to be %s got %s", testAccountID, tok.AccountID)
+	} else if tok.Role != 10 {
+		t.Errorf("expected member role to be 10 got %d", tok.Role)
+	}
+
+	data := new(struct {
+		Role int `json:"role"`
+	})
+	data.Role = 50
+
+	resp2 := dbReq(t, acct.userReq, "PUT", "/account/users/"+tok.ID, data)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp2))
+	}
+
+	tok, err = backend.DB.GetUserByID(dbName, testAccountID, tok.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if tok.Role != 50 {
+		t.Errorf("expected member role to be 50 got %d", tok.Role)
+	}
+
+	// root role cannot be granted to a member
+	data.Role = 100
+	resp3 := dbReq(t, acct.userReq, "PUT", "/account/users/"+tok.ID, data)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode <= 299 {
+		t.Fatal("expected granting role 100 to fail")
+	}
+
+	resp4 := dbReq(t, acct.userReq, "DELETE", "/account/users/"+tok.ID, nil)
+	defer resp4.Body.Close()
+
+	if resp4.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp4))
+	}
+}
+
 func TestAddNewDatabase(t *testing.T) {
 	resp := dbReq(t, acct.addDatabase, "GET", "/account/add-db", nil)
 	defer resp.Body.Close()
@@ -74,3 +140,115 @@ func TestAddNewDatabase(t *testing.T) {
 		t.Fatal(GetResponseBody(t, resp))
 	}
 }
+
+func TestAccountMemberCannotUpdatePeer(t *testing.T) {
+	conf, err := backend.DB.FindDatabase(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usrSvc := backend.Membership(conf)
+
+	token, _, err := usrSvc.CreateUser(testAccountID, "peer1@test.com", "peer1234", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, peer, err := usrSvc.CreateUser(testAccountID, "peer2@test.com", "peer1234", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := new(struct {
+		Role int `json:"role"`
+	})
+	data.Role = 10
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("PUT", "/account/users/"+peer.ID, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("SB-PUBLIC-KEY", pubKey)
+	req.Header.Set("Authorization", "Bearer "+string(token))
+
+	w := httptest.NewRecorder()
+
+	h := middleware.Chain(http.HandlerFunc(acct.userReq),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequireAuth(backend.DB, backend.Cache),
+	)
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 when demoting a peer got %d", w.Code)
+	}
+
+	tok, err := backend.DB.GetUserByID(dbName, testAccountID, peer.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if tok.Role != 50 {
+		t.Errorf("expected peer role to stay 50 got %d", tok.Role)
+	}
+}
+
+// tokenReq executes a request authenticated with a specific user token
+func tokenReq(t *testing.T, hf func(http.ResponseWriter, *http.Request), method, path, token string, v interface{}) *http.Response {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("SB-PUBLIC-KEY", pubKey)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+
+	h := middleware.Chain(http.HandlerFunc(hf),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequireAuth(backend.DB, backend.Cache),
+	)
+	h.ServeHTTP(w, req)
+
+	return w.Result()
+}
+
+func TestAddUserRoleAboveOwn(t *testing.T) {
+	conf, err := backend.DB.FindDatabase(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := backend.Membership(conf).CreateUser(testAccountID, "manager@test.com", "manager1234", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := new(struct {
+		model.Login
+		Role int `json:"role"`
+	})
+	u.Email = "peer@test.com"
+	u.Password = "peer1234"
+	u.Role = 50
+
+	resp := tokenReq(t, acct.addUser, "POST", "/account/users", string(token), u)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 adding a user with the same role got %d", resp.StatusCode)
+	}
+
+	u.Role = 10
+
+	resp2 := tokenReq(t, acct.addUser, "POST", "/account/users", string(token), u)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp2))
+	}
+}
@@ -0,0 +1,267 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+// adminDatabase suspends or reactivates a database from
+// /admin/databases/{id}/suspend and /admin/databases/{id}/reactivate or
+// mints a time-boxed root token for an operator from
+// /admin/databases/{id}/impersonate with {operator, reason, minutes}
+func adminDatabase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 3)
+	if len(id) == 0 {
+		http.Error(w, "missing database id", http.StatusBadRequest)
+		return
+	}
+
+	switch getURLPart(r.URL.Path, 4) {
+	case "suspend":
+		var data struct {
+			Reason string `json:"reason"`
+		}
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		conf, err := backend.SuspendDatabase(id, data.Reason)
+		if err != nil {
+			http.Error(w, err.Error(), adminStatus(err))
+			return
+		}
+
+		recordAdminEvent(r, model.AdminEvent{
+			Type:     model.AdminDatabaseSuspended,
+			Actor:    "admin",
+			TenantID: conf.TenantID,
+			DBName:   conf.Name,
+			Detail:   data.Reason,
+		})
+
+		backend.Log.Info().Msgf("database %s suspended for %s", conf.Name, data.Reason)
+		respond(w, http.StatusOK, true)
+	case "reactivate":
+		conf, err := backend.ReactivateDatabase(id)
+		if err != nil {
+			http.Error(w, err.Error(), adminStatus(err))
+			return
+		}
+
+		recordAdminEvent(r, model.AdminEvent{
+			Type:     model.AdminDatabaseReactivated,
+			Actor:    "admin",
+			TenantID: conf.TenantID,
+			DBName:   conf.Name,
+		})
+
+		backend.Log.Info().Msgf("database %s reactivated", conf.Name)
+		respond(w, http.StatusOK, true)
+	case "impersonate":
+		var data struct {
+			Operator string `json:"operator"`
+			Reason   string `json:"reason"`
+			Minutes  int    `json:"minutes"`
+		}
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		token, imp, err := backend.Impersonate(id, data.Operator, data.Reason, time.Duration(data.Minutes)*time.Minute)
+		if err != nil {
+			http.Error(w, err.Error(), adminStatus(err))
+			return
+		}
+
+		recordAdminEvent(r, model.AdminEvent{
+			Type:     model.AdminImpersonation,
+			Actor:    "admin",
+			TenantID: imp.TenantID,
+			DBName:   imp.DBName,
+			Target:   imp.Operator,
+			Detail:   imp.Reason + " until " + imp.Expires.Format(time.RFC3339),
+		})
+
+		backend.Log.Info().Msgf("%s impersonates the root of %s until %s", imp.Operator, imp.DBName, imp.Expires)
+
+		resp := new(struct {
+			PublicKey string    `json:"pk"`
+			RootToken string    `json:"rootToken"`
+			Expires   time.Time `json:"expires"`
+		})
+		resp.PublicKey = id
+		resp.RootToken = token
+		resp.Expires = imp.Expires
+
+		respond(w, http.StatusOK, resp)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// adminTenantFlags lists the feature flags of a tenant from
+// /admin/tenants/{id}/flags, sets one with {enabled} from
+// /admin/tenants/{id}/flags/{name} or removes it so its default applies
+func adminTenantFlags(w http.ResponseWriter, r *http.Request) {
+	tenantID := getURLPart(r.URL.Path, 3)
+	if len(tenantID) == 0 || getURLPart(r.URL.Path, 4) != "flags" {
+		http.NotFound(w, r)
+		return
+	}
+
+	name := getURLPart(r.URL.Path, 5)
+
+	switch {
+	case len(name) == 0 && r.Method == http.MethodGet:
+		flags, err := backend.TenantFlags(tenantID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, flags)
+	case len(name) > 0 && r.Method == http.MethodPut:
+		var data struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := backend.SetFeatureFlag(tenantID, name, data.Enabled); err != nil {
+			http.Error(w, err.Error(), adminStatus(err))
+			return
+		}
+
+		recordAdminEvent(r, model.AdminEvent{
+			Type:     model.AdminFlagChanged,
+			Actor:    "admin",
+			TenantID: tenantID,
+			Target:   name,
+			Detail:   strconv.FormatBool(data.Enabled),
+		})
+
+		respond(w, http.StatusOK, true)
+	case len(name) > 0 && r.Method == http.MethodDelete:
+		if err := backend.DeleteFeatureFlag(tenantID, name); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		recordAdminEvent(r, model.AdminEvent{
+			Type:     model.AdminFlagChanged,
+			Actor:    "admin",
+			TenantID: tenantID,
+			Target:   name,
+			Detail:   "default",
+		})
+
+		respond(w, http.StatusOK, true)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// adminAuditEvents returns the most recent privileged operations of the
+// platform audit log from /admin/audit, filtered by the tenantId, db, type,
+// since and until query string parameters
+func adminAuditEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	filter := model.AdminEventFilter{
+		TenantID: qs.Get("tenantId"),
+		DBName:   qs.Get("db"),
+		Type:     qs.Get("type"),
+		Limit:    100,
+	}
+
+	if s := qs.Get("limit"); len(s) > 0 {
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	var err error
+	if s := qs.Get("since"); len(s) > 0 {
+		filter.Since, err = parseDate(s)
+		if err != nil {
+			http.Error(w, "invalid since date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if s := qs.Get("until"); len(s) > 0 {
+		filter.Until, err = parseDate(s)
+		if err != nil {
+			http.Error(w, "invalid until date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := backend.ListAdminEvents(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if events == nil {
+		events = make([]model.AdminEvent, 0)
+	}
+
+	respond(w, http.StatusOK, events)
+}
+
+// adminStatus returns the status of a failed admin operation, a bad request
+// when the suspension reason or the flag name is invalid
+func adminStatus(err error) int {
+	if errors.Is(err, backend.ErrInvalidSuspension) || errors.Is(err, backend.ErrInvalidFlag) || errors.Is(err, backend.ErrInvalidImpersonation) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// adminRewrapSecrets wraps the data keys of the secrets still wrapped by a
+// previous master key with the current one from /admin/vault/rewrap, the
+// primary instance also does it every SecretRewrapInterval
+func adminRewrapSecrets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n, err := backend.RewrapSecrets()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAdminEvent(r, model.AdminEvent{
+		Type:   model.AdminSecretsRewrapped,
+		Actor:  "admin",
+		Target: backend.Vault.KeyID(),
+		Detail: strconv.Itoa(n),
+	})
+
+	respond(w, http.StatusOK, n)
+}
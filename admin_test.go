@@ -0,0 +1,272 @@
+package staticbackend
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAdminSuspendDatabase(t *testing.T) {
+	admin := middleware.Chain(http.HandlerFunc(adminDatabase), middleware.RequireAdmin("admin-token"))
+	api := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+	)
+
+	adminReq := func(action, token, body string) int {
+		req := httptest.NewRequest("POST", "/admin/databases/"+pubKey+"/"+action, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		admin.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	apiReq := func() int {
+		req := httptest.NewRequest("GET", "/db/suspended", nil)
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := adminReq("suspend", "wrong", `{"reason": "abuse"}`); code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 with a wrong token got %d", code)
+	} else if code := adminReq("suspend", "admin-token", `{"reason": "other"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 with an invalid reason got %d", code)
+	}
+
+	defer backend.ReactivateDatabase(pubKey)
+
+	tests := []struct {
+		reason string
+		status int
+	}{
+		{model.SuspendedAbuse, http.StatusForbidden},
+		{model.SuspendedNonPayment, http.StatusPaymentRequired},
+	}
+	for _, tc := range tests {
+		if code := adminReq("suspend", "admin-token", `{"reason": "`+tc.reason+`"}`); code != http.StatusOK {
+			t.Fatalf("expected status 200 suspending got %d", code)
+		} else if code := apiReq(); code != tc.status {
+			t.Errorf("expected status %d when suspended for %s got %d", tc.status, tc.reason, code)
+		}
+	}
+
+	if code := adminReq("reactivate", "admin-token", ""); code != http.StatusOK {
+		t.Fatalf("expected status 200 reactivating got %d", code)
+	} else if code := apiReq(); code != http.StatusOK {
+		t.Errorf("expected status 200 once reactivated got %d", code)
+	}
+}
+
+func TestAdminAuditEvents(t *testing.T) {
+	suspend := middleware.Chain(http.HandlerFunc(adminDatabase), middleware.RequireAdmin("admin-token"))
+	audit := middleware.Chain(http.HandlerFunc(adminAuditEvents), middleware.RequireAdmin("admin-token"))
+
+	req := httptest.NewRequest("POST", "/admin/databases/"+pubKey+"/suspend", bytes.NewBufferString(`{"reason": "abuse"}`))
+	req.Header.Set("Authorization", "Bearer admin-token")
+
+	w := httptest.NewRecorder()
+	suspend.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 suspending got %d", w.Code)
+	}
+	backend.ReactivateDatabase(pubKey)
+
+	// the backups' deletion is a destructive operation of the root token
+	resp := dbReq(t, backups, "POST", "/backup", nil, true)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var b model.Backup
+	if err := parseBody(resp.Body, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	resp = dbReq(t, backupActions, "DELETE", "/backup/"+b.ID, nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	auditReq := func(qs string) []model.AdminEvent {
+		req := httptest.NewRequest("GET", "/admin/audit?"+qs, nil)
+		req.Header.Set("Authorization", "Bearer admin-token")
+
+		w := httptest.NewRecorder()
+		audit.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 listing the admin events got %d", w.Code)
+		}
+
+		var events []model.AdminEvent
+		if err := json.NewDecoder(w.Body).Decode(&events); err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	events := auditReq("db=" + dbName + "&type=" + model.AdminDatabaseSuspended)
+	if len(events) == 0 || events[0].Actor != "admin" || events[0].Detail != model.SuspendedAbuse {
+		t.Errorf("expected the suspension in the audit log got %v", events)
+	}
+
+	events = auditReq("db=" + dbName + "&type=" + model.AdminRootOperation + "&limit=1")
+	if len(events) != 1 || events[0].Target != "DELETE /backup/"+b.ID {
+		t.Errorf("expected the deleted backup in the audit log got %v", events)
+	}
+
+	req = httptest.NewRequest("GET", "/admin/audit?since=yesterday", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+
+	w = httptest.NewRecorder()
+	audit.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 with an invalid date got %d", w.Code)
+	}
+}
+
+func TestAdminTenantFlags(t *testing.T) {
+	tenantID := "flags-tenant"
+	defer backend.DeleteFeatureFlag(tenantID, "admin-beta")
+
+	flags := middleware.Chain(http.HandlerFunc(adminTenantFlags), middleware.RequireAdmin("admin-token"))
+
+	flagsReq := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/tenants/"+tenantID+"/flags"+path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer admin-token")
+
+		w := httptest.NewRecorder()
+		flags.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := flagsReq("PUT", "/admin-beta", `{"enabled": true}`); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 setting the flag got %d", w.Code)
+	}
+
+	w := flagsReq("GET", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 listing the flags got %d", w.Code)
+	}
+
+	var list map[string]bool
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	} else if !list["admin-beta"] {
+		t.Errorf("expected admin-beta to be enabled got %v", list)
+	}
+
+	if w := flagsReq("PUT", "/Not%20Valid", `{"enabled": true}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 with an invalid name got %d", w.Code)
+	}
+
+	if w := flagsReq("DELETE", "/admin-beta", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 removing the flag got %d", w.Code)
+	}
+
+	if ok, err := backend.FlagEnabled(tenantID, "admin-beta"); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Errorf("expected admin-beta to be disabled once removed")
+	}
+
+	events, err := backend.ListAdminEvents(model.AdminEventFilter{TenantID: tenantID, Type: model.AdminFlagChanged})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) < 2 || events[0].Target != "admin-beta" {
+		t.Errorf("expected the flag changes in the audit log got %v", events)
+	}
+}
+
+func TestAdminImpersonation(t *testing.T) {
+	admin := middleware.Chain(http.HandlerFunc(adminDatabase), middleware.RequireAdmin("admin-token"))
+	root := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, auth, err := middleware.Extract(r, true)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			respond(w, http.StatusOK, auth)
+		}),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequireRoot(backend.DB, backend.Cache),
+	)
+
+	impersonate := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/databases/"+pubKey+"/impersonate", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer admin-token")
+
+		w := httptest.NewRecorder()
+		admin.ServeHTTP(w, req)
+		return w
+	}
+
+	rootReq := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		root.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := impersonate(`{"operator": "support@sb.test"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a reason got %d", w.Code)
+	} else if w := impersonate(`{"operator": "support@sb.test", "reason": "ticket 42", "minutes": 600}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 above the max duration got %d", w.Code)
+	}
+
+	w := impersonate(`{"operator": "support@sb.test", "reason": "ticket 42", "minutes": 15}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 impersonating got %d: %s", w.Code, w.Body.String())
+	}
+
+	var minted struct {
+		PublicKey string    `json:"pk"`
+		RootToken string    `json:"rootToken"`
+		Expires   time.Time `json:"expires"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&minted); err != nil {
+		t.Fatal(err)
+	} else if minted.PublicKey != pubKey || !strings.HasPrefix(minted.RootToken, model.ImpersonationTokenPrefix) || time.Until(minted.Expires) > 15*time.Minute {
+		t.Fatalf("unexpected impersonation %v", minted)
+	}
+
+	w = rootReq(minted.RootToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 with the impersonation token got %d: %s", w.Code, w.Body.String())
+	}
+
+	var auth model.Auth
+	if err := json.NewDecoder(w.Body).Decode(&auth); err != nil {
+		t.Fatal(err)
+	} else if auth.Role != middleware.RootRole || auth.Impersonator != "support@sb.test" {
+		t.Errorf("expected the root user impersonated by support@sb.test got %v", auth)
+	}
+
+	if w := rootReq(model.ImpersonationTokenPrefix + "unknown"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 with an unknown token got %d", w.Code)
+	}
+
+	events, err := backend.ListAdminEvents(model.AdminEventFilter{DBName: dbName, Type: model.AdminImpersonation, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 1 || events[0].Target != "support@sb.test" || !strings.HasPrefix(events[0].Detail, "ticket 42") {
+		t.Errorf("expected the impersonation in the audit log got %v", events)
+	}
+}
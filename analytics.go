@@ -0,0 +1,51 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+// requestAnalytics returns the requests of the database as time series for
+// the dashboard charts. The interval query string parameter is hour or
+// day, periods the length of the series and by groups the requests by
+// endpoint, status or country.
+func requestAnalytics(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	interval := qs.Get("interval")
+	if len(interval) == 0 {
+		interval = backend.AnalyticsHourly
+	}
+
+	def, max := backend.AnalyticsHours, backend.MaxAnalyticsHours
+	if interval == backend.AnalyticsDaily {
+		def, max = backend.AnalyticsDays, backend.MaxAnalyticsDays
+	}
+
+	periods, ok := statsPeriods(qs.Get("periods"), def, max)
+	if !ok {
+		http.Error(w, "invalid periods", http.StatusBadRequest)
+		return
+	}
+
+	ra, err := backend.RequestAnalytics(conf, interval, periods, qs.Get("by"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, backend.ErrInvalidInterval) || errors.Is(err, backend.ErrInvalidGrouping) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	respond(w, http.StatusOK, ra)
+}
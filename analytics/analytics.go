@@ -0,0 +1,185 @@
+// Package analytics rolls the requests of the databases up per hour in
+// memory and writes the rollups to the database in batches. The requests
+// are only counted by endpoint class, status class and country so the
+// clients cannot be identified.
+package analytics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+// Store saves the request rollups, adding their values to the stored ones
+type Store interface {
+	AddRequestRollups(records []model.RequestRollup) error
+}
+
+// Number of buffered rollups triggering a write before the flush interval
+const batchSize = 500
+
+// HourFormat is the format of the hours of the rollups
+const HourFormat = "2006-01-02T15"
+
+type rollupKey struct {
+	tenantID string
+	dbName   string
+	hour     string
+	endpoint string
+	status   int
+	country  string
+}
+
+type rollupValue struct {
+	count       int64
+	totalMillis int64
+	maxMillis   int64
+}
+
+// Collector accumulates the requests per database, hour, endpoint class,
+// status class and country until it's flushed
+type Collector struct {
+	mu      sync.Mutex
+	rollups map[rollupKey]rollupValue
+	full    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	now     func() time.Time
+}
+
+// New returns an empty Collector
+func New() *Collector {
+	return &Collector{
+		rollups: make(map[rollupKey]rollupValue),
+		full:    make(chan struct{}, 1),
+		now:     time.Now,
+	}
+}
+
+// Default is the Collector of the server
+var Default = New()
+
+// Record adds a request of the database to the current hour
+func Record(conf model.DatabaseConfig, endpoint string, status int, elapsed time.Duration, country string) {
+	Default.Record(conf, endpoint, status, elapsed, country)
+}
+
+// Record adds a request of the database to the current hour
+func (c *Collector) Record(conf model.DatabaseConfig, endpoint string, status int, elapsed time.Duration, country string) {
+	key := rollupKey{
+		tenantID: conf.TenantID,
+		dbName:   conf.Name,
+		hour:     c.now().UTC().Format(HourFormat),
+		endpoint: endpoint,
+		status:   status / 100 * 100,
+		country:  country,
+	}
+
+	ms := elapsed.Milliseconds()
+
+	c.mu.Lock()
+	v := c.rollups[key]
+	v.count++
+	v.totalMillis += ms
+	if ms > v.maxMillis {
+		v.maxMillis = ms
+	}
+	c.rollups[key] = v
+	size := len(c.rollups)
+	c.mu.Unlock()
+
+	if size >= batchSize {
+		select {
+		case c.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes the buffered rollups in one batch. They are kept for the
+// next flush when the write fails.
+func (c *Collector) Flush(store Store) error {
+	c.mu.Lock()
+	rollups := c.rollups
+	c.rollups = make(map[rollupKey]rollupValue)
+	c.mu.Unlock()
+
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	records := make([]model.RequestRollup, 0, len(rollups))
+	for k, v := range rollups {
+		records = append(records, model.RequestRollup{
+			TenantID:    k.tenantID,
+			DBName:      k.dbName,
+			Hour:        k.hour,
+			Endpoint:    k.endpoint,
+			Status:      k.status,
+			Country:     k.country,
+			Count:       v.count,
+			TotalMillis: v.totalMillis,
+			MaxMillis:   v.maxMillis,
+		})
+	}
+
+	if err := store.AddRequestRollups(records); err != nil {
+		c.mu.Lock()
+		for k, v := range rollups {
+			cur := c.rollups[k]
+			cur.count += v.count
+			cur.totalMillis += v.totalMillis
+			if v.maxMillis > cur.maxMillis {
+				cur.maxMillis = v.maxMillis
+			}
+			c.rollups[k] = cur
+		}
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Start flushes the rollups every interval, or sooner when the buffer is
+// full, until Stop is called. It does nothing when the Collector is already
+// started.
+func (c *Collector) Start(store Store, interval time.Duration, log *logger.Logger) {
+	if c.stop != nil {
+		return
+	}
+
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-c.full:
+			case <-c.stop:
+				return
+			}
+
+			if err := c.Flush(store); err != nil {
+				log.Error().Err(err).Msg("error writing the request rollups")
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic flush and writes the remaining rollups
+func (c *Collector) Stop(store Store) error {
+	if c.stop != nil {
+		close(c.stop)
+		<-c.done
+		c.stop = nil
+	}
+	return c.Flush(store)
+}
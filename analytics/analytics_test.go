@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+type memStore struct {
+	records []model.RequestRollup
+	err     error
+}
+
+func (s *memStore) AddRequestRollups(records []model.RequestRollup) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func TestCollectorFlush(t *testing.T) {
+	c := New()
+	c.now = func() time.Time { return time.Date(2001, 2, 3, 4, 5, 0, 0, time.UTC) }
+
+	conf := model.DatabaseConfig{TenantID: "t1", Name: "db1"}
+	c.Record(conf, model.EndpointDatabase, 200, 10*time.Millisecond, "CA")
+	c.Record(conf, model.EndpointDatabase, 201, 30*time.Millisecond, "CA")
+	c.Record(conf, model.EndpointDatabase, 404, 5*time.Millisecond, "CA")
+
+	// a failed write keeps the rollups for the next flush
+	store := &memStore{err: errors.New("unavailable")}
+	if err := c.Flush(store); err == nil {
+		t.Fatal("expected the write error")
+	}
+
+	c.Record(conf, model.EndpointDatabase, 200, 20*time.Millisecond, "CA")
+
+	store.err = nil
+	if err := c.Flush(store); err != nil {
+		t.Fatal(err)
+	} else if len(store.records) != 2 {
+		t.Fatalf("expected 2 rollups got %v", store.records)
+	}
+
+	for _, rr := range store.records {
+		if rr.TenantID != "t1" || rr.DBName != "db1" || rr.Hour != "2001-02-03T04" || rr.Country != "CA" {
+			t.Errorf("unexpected rollup %v", rr)
+		} else if rr.Status == 200 && (rr.Count != 3 || rr.TotalMillis != 60 || rr.MaxMillis != 30) {
+			t.Errorf("expected 3 successful requests taking 60ms got %v", rr)
+		} else if rr.Status == 400 && rr.Count != 1 {
+			t.Errorf("expected 1 client error got %v", rr)
+		}
+	}
+
+	// the buffer is empty once flushed
+	if err := c.Flush(store); err != nil {
+		t.Fatal(err)
+	} else if len(store.records) != 2 {
+		t.Errorf("expected no new rollups got %v", store.records)
+	}
+}
@@ -0,0 +1,85 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/analytics"
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestRequestAnalytics(t *testing.T) {
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fn/exec/missing" {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequestAnalytics(),
+	)
+
+	for _, path := range []string{"/fn/exec/hello", "/fn/exec/hello", "/fn/exec/missing"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+		req.Header.Set("CF-IPCountry", "ca")
+		// the country header is only trusted from a proxy
+		req.RemoteAddr = "127.0.0.1:4321"
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+	}
+
+	if err := analytics.Default.Flush(backend.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := dbReq(t, requestAnalytics, "GET", "/sudo/_/analytics?interval=hour&periods=6&by=endpoint", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var ra model.RequestAnalytics
+	if err := parseBody(resp.Body, &ra); err != nil {
+		t.Fatal(err)
+	} else if len(ra.Series) != 6 {
+		t.Fatalf("expected 6 hours got %v", ra.Series)
+	}
+
+	fn := ra.Breakdown[model.EndpointFunctions]
+	if len(fn) != 6 {
+		t.Fatalf("expected 6 hours of function calls got %v", ra.Breakdown)
+	} else if fn[5].Requests != 3 || fn[5].ClientErrors != 1 || fn[5].Errors != 0 {
+		t.Errorf("expected 3 function calls with 1 client error this hour got %v", fn[5])
+	} else if ra.Series[5].Requests < 3 {
+		t.Errorf("expected at least 3 requests this hour got %v", ra.Series[5])
+	}
+
+	resp = dbReq(t, requestAnalytics, "GET", "/sudo/_/analytics?interval=day&periods=7&by=country", nil, true)
+	defer resp.Body.Close()
+
+	ra = model.RequestAnalytics{}
+	if err := parseBody(resp.Body, &ra); err != nil {
+		t.Fatal(err)
+	} else if len(ra.Series) != 7 {
+		t.Fatalf("expected 7 days got %v", ra.Series)
+	} else if ca := ra.Breakdown["CA"]; len(ca) != 7 || ca[6].Requests < 3 {
+		t.Errorf("expected today's requests from CA got %v", ra.Breakdown)
+	}
+
+	for _, qs := range []string{"interval=week", "periods=0", "interval=hour&periods=1000", "by=user"} {
+		resp := dbReq(t, requestAnalytics, "GET", "/sudo/_/analytics?"+qs, nil, true)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400 got %d", qs, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package antivirus scans uploaded files for malware, either via a clamd
+// daemon or an external scanning API.
+package antivirus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Timeout is the maximum duration of a scan
+var Timeout = 2 * time.Minute
+
+// Result is the outcome of a scan, Threat is the name of the detected
+// signature
+type Result struct {
+	Infected bool   `json:"infected"`
+	Threat   string `json:"threat"`
+}
+
+// Scanner scans a file content
+type Scanner interface {
+	Scan(file io.Reader) (Result, error)
+}
+
+// New returns the scanner for the configured clamd address or scanning API,
+// nil if none are set
+func New(clamdAddress, apiURL, apiKey string) Scanner {
+	if len(clamdAddress) > 0 {
+		return Clamd{Address: clamdAddress}
+	} else if len(apiURL) > 0 {
+		return API{URL: apiURL, Key: apiKey}
+	}
+	return nil
+}
+
+// Clamd scans files with a clamd daemon using the INSTREAM command. The
+// address is a unix socket path or a host:port, optionally prefixed with
+// unix: or tcp:.
+type Clamd struct {
+	Address string
+}
+
+// chunkSize is the size of the chunks streamed to clamd, it must stay below
+// its StreamMaxLength
+const chunkSize = 64 << 10
+
+func (c Clamd) dial() (net.Conn, error) {
+	network, addr := "tcp", c.Address
+	if strings.HasPrefix(addr, "unix:") {
+		network, addr = "unix", strings.TrimPrefix(addr, "unix:")
+	} else if strings.HasPrefix(addr, "tcp:") {
+		addr = strings.TrimPrefix(addr, "tcp:")
+	} else if strings.HasPrefix(addr, "/") {
+		network = "unix"
+	}
+
+	return net.DialTimeout(network, addr, 10*time.Second)
+}
+
+func (c Clamd) Scan(file io.Reader) (res Result, err error) {
+	conn, err := c.dial()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(Timeout)); err != nil {
+		return
+	}
+
+	if _, err = conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return
+	}
+
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, rerr := file.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err = conn.Write(size); err != nil {
+				return
+			}
+			if _, err = conn.Write(buf[:n]); err != nil {
+				return
+			}
+		}
+
+		if errors.Is(rerr, io.EOF) {
+			break
+		} else if rerr != nil {
+			return res, rerr
+		}
+	}
+
+	// a zero length chunk ends the stream
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err = conn.Write(size); err != nil {
+		return
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return
+	}
+
+	return parseClamdReply(string(reply))
+}
+
+// parseClamdReply parses replies like "stream: OK" or
+// "stream: Eicar-Signature FOUND"
+func parseClamdReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return Result{Infected: true, Threat: strings.TrimSuffix(reply, " FOUND")}, nil
+	}
+	return Result{}, fmt.Errorf("clamd error: %s", reply)
+}
+
+// API scans files with an external scanning API. The file content is
+// POSTed to URL with the Key as bearer token and the API responds with a
+// JSON Result, i.e. {"infected": true, "threat": "Eicar-Signature"}.
+type API struct {
+	URL string
+	Key string
+}
+
+func (a API) Scan(file io.Reader) (res Result, err error) {
+	req, err := http.NewRequest(http.MethodPost, a.URL, file)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if len(a.Key) > 0 {
+		req.Header.Set("Authorization", "Bearer "+a.Key)
+	}
+
+	client := &http.Client{Timeout: Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	if resp.StatusCode > 299 {
+		return res, fmt.Errorf("scanning API error %d: %s", resp.StatusCode, bytes.TrimSpace(b))
+	}
+
+	err = json.Unmarshal(b, &res)
+	return
+}
@@ -0,0 +1,124 @@
+package antivirus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd answers INSTREAM commands, flagging streams containing EICAR
+func fakeClamd(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				r := bufio.NewReader(conn)
+				if cmd, err := r.ReadString(0); err != nil || cmd != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+
+				var content bytes.Buffer
+				size := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(r, size); err != nil {
+						return
+					}
+
+					n := binary.BigEndian.Uint32(size)
+					if n == 0 {
+						break
+					}
+
+					if _, err := io.CopyN(&content, r, int64(n)); err != nil {
+						return
+					}
+				}
+
+				if strings.Contains(content.String(), "EICAR") {
+					conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+					return
+				}
+				conn.Write([]byte("stream: OK\x00"))
+			}(conn)
+		}
+	}()
+
+	return "tcp:" + ln.Addr().String()
+}
+
+func TestClamdScan(t *testing.T) {
+	scanner := New(fakeClamd(t), "", "")
+
+	res, err := scanner.Scan(strings.NewReader("clean content"))
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Infected {
+		t.Error("expected clean content not to be infected")
+	}
+
+	// bigger than a chunk to test the streaming
+	content := strings.Repeat("a", chunkSize+10) + eicar
+	res, err = scanner.Scan(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	} else if !res.Infected || res.Threat != "Eicar-Signature" {
+		t.Errorf("expected Eicar-Signature got %v", res)
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	if _, err := parseClamdReply("INSTREAM size limit exceeded. ERROR\x00"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestAPIScan(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		if bytes.Contains(b, []byte("EICAR")) {
+			w.Write([]byte(`{"infected": true, "threat": "EICAR"}`))
+			return
+		}
+		w.Write([]byte(`{"infected": false}`))
+	}))
+	defer ts.Close()
+
+	scanner := New("", ts.URL, "secret")
+
+	res, err := scanner.Scan(strings.NewReader(eicar))
+	if err != nil {
+		t.Fatal(err)
+	} else if !res.Infected || res.Threat != "EICAR" {
+		t.Errorf("expected EICAR got %v", res)
+	}
+
+	if _, err := (API{URL: ts.URL}).Scan(strings.NewReader(eicar)); err == nil {
+		t.Error("expected an error without the API key")
+	}
+}
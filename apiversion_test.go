@@ -0,0 +1,61 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/middleware"
+)
+
+func TestAPIVersions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/db/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "v%d %s", middleware.APIVersion(r), r.URL.Path)
+	})
+
+	v2 := http.NewServeMux()
+	v2.HandleFunc("/db/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "new %s", r.URL.Path)
+	})
+
+	h := middleware.Chain(mux, middleware.APIVersions(map[int]*http.ServeMux{middleware.APIv2: v2}))
+
+	tests := []struct {
+		path    string
+		status  int
+		version string
+		body    string
+	}{
+		{"/db/tasks", http.StatusOK, "1", "v1 /db/tasks"},
+		{"/v1/db/tasks", http.StatusOK, "1", "v1 /db/tasks"},
+		{"/v2/db/tasks", http.StatusOK, "2", "new /db/tasks"},
+		{"/v9/db/tasks", http.StatusNotFound, "", "unsupported API version\n"},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: expected status %d got %d", tc.path, tc.status, w.Code)
+		} else if w.Header().Get(middleware.APIVersionHeader) != tc.version {
+			t.Errorf("%s: expected version %q got %q", tc.path, tc.version, w.Header().Get(middleware.APIVersionHeader))
+		} else if w.Body.String() != tc.body {
+			t.Errorf("%s: expected %q got %q", tc.path, tc.body, w.Body.String())
+		}
+	}
+
+	// the v2 routes without a replacement are served by the v1 handlers
+	v2 = http.NewServeMux()
+	h = middleware.Chain(mux, middleware.APIVersions(map[int]*http.ServeMux{middleware.APIv2: v2}))
+
+	req := httptest.NewRequest("GET", "/v2/db/tasks", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Body.String() != "v2 /db/tasks" {
+		t.Errorf("expected the shared handler got %q", w.Body.String())
+	}
+}
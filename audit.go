@@ -0,0 +1,142 @@
+package staticbackend
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/eventbridge"
+	"github.com/staticbackendhq/core/metering"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// recordAuthEvent adds an entry to the database audit log. Failing to record
+// the event is logged but does not fail the request.
+func recordAuthEvent(r *http.Request, conf model.DatabaseConfig, evt model.AuditEvent) {
+	evt.IP = middleware.ClientIP(r)
+	evt.UserAgent = r.UserAgent()
+	evt.Created = time.Now()
+
+	if err := backend.DB.AddAuditEvent(conf.Name, evt); err != nil {
+		backend.Log.Error().Err(err).Msgf("unable to record %s audit event", evt.Type)
+	}
+
+	if evt.Type == model.AuditLoginFailed {
+		metering.Record(conf, model.MeterLoginFailures, 1)
+	}
+
+	backend.Events.Publish(eventbridge.Event{
+		Type:    eventbridge.EventAuthPrefix + evt.Type,
+		Base:    conf.Name,
+		Created: evt.Created,
+		Data:    evt,
+	})
+}
+
+// recordAdminEvent adds a privileged operation to the platform audit log
+func recordAdminEvent(r *http.Request, evt model.AdminEvent) {
+	evt.IP = middleware.ClientIP(r)
+	evt.UserAgent = r.UserAgent()
+
+	backend.RecordAdminEvent(evt)
+}
+
+// recordRootOperation adds a destructive operation made with the root token
+// of a database to the platform audit log, the operations of other users
+// are not recorded
+func recordRootOperation(r *http.Request, conf model.DatabaseConfig, auth model.Auth, detail string) {
+	if auth.Role < middleware.RootRole {
+		return
+	}
+
+	recordAdminEvent(r, model.AdminEvent{
+		Type:     model.AdminRootOperation,
+		Actor:    auditActor(auth),
+		TenantID: conf.TenantID,
+		DBName:   conf.Name,
+		Target:   r.Method + " " + r.URL.Path,
+		Detail:   detail,
+	})
+}
+
+// recordFunctionDeployed adds a function added or updated to the platform
+// audit log
+func recordFunctionDeployed(r *http.Request, conf model.DatabaseConfig, auth model.Auth, name, detail string) {
+	recordAdminEvent(r, model.AdminEvent{
+		Type:     model.AdminFunctionDeployed,
+		Actor:    auditActor(auth),
+		TenantID: conf.TenantID,
+		DBName:   conf.Name,
+		Target:   name,
+		Detail:   detail,
+	})
+}
+
+// auditActor returns who made a privileged operation, the operator is
+// named when it was made with an impersonation token
+func auditActor(auth model.Auth) string {
+	if len(auth.Impersonator) > 0 {
+		return auth.Impersonator + " as " + auth.Email
+	}
+	return auth.Email
+}
+
+func listAuditEvents(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	filter := model.AuditFilter{
+		AccountID: qs.Get("accountId"),
+		UserID:    qs.Get("userId"),
+		Type:      qs.Get("type"),
+		Limit:     100,
+	}
+
+	if s := qs.Get("limit"); len(s) > 0 {
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if s := qs.Get("since"); len(s) > 0 {
+		filter.Since, err = parseDate(s)
+		if err != nil {
+			http.Error(w, "invalid since date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if s := qs.Get("until"); len(s) > 0 {
+		filter.Until, err = parseDate(s)
+		if err != nil {
+			http.Error(w, "invalid until date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := backend.DB.ListAuditEvents(conf.Name, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, events)
+}
+
+// parseDate accepts RFC3339 or YYYY-MM-DD formatted dates
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
@@ -0,0 +1,25 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// RecordAdminEvent adds a privileged operation to the platform audit log.
+// Failing to record the event is logged but does not fail the operation.
+func RecordAdminEvent(evt model.AdminEvent) {
+	if evt.Created.IsZero() {
+		evt.Created = time.Now()
+	}
+
+	if err := DB.AddAdminEvent(evt); err != nil {
+		Log.Error().Err(err).Msgf("unable to record %s admin event", evt.Type)
+	}
+}
+
+// ListAdminEvents returns the most recent privileged operations matching
+// the filter
+func ListAdminEvents(filter model.AdminEventFilter) ([]model.AdminEvent, error) {
+	return DB.ListAdminEvents(filter)
+}
@@ -0,0 +1,32 @@
+package backend_test
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestChangePlanAudit(t *testing.T) {
+	cus, err := backend.DB.FindTenant(base.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.ChangePlan(cus.ID, cus.Plan)
+
+	plan := model.PlanTraction
+	if cus.Plan == plan {
+		plan = model.PlanGrowth
+	}
+
+	if err := backend.ChangePlan(cus.ID, plan); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := backend.ListAdminEvents(model.AdminEventFilter{TenantID: cus.ID, Type: model.AdminPlanChanged})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) == 0 || events[0].Actor != "billing" || events[0].Created.IsZero() {
+		t.Errorf("expected the plan change in the audit log got %v", events)
+	}
+}
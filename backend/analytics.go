@@ -0,0 +1,166 @@
+package backend
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/staticbackendhq/core/analytics"
+	"github.com/staticbackendhq/core/model"
+)
+
+// Intervals and groupings of the request analytics
+const (
+	AnalyticsHourly = "hour"
+	AnalyticsDaily  = "day"
+
+	AnalyticsByEndpoint = "endpoint"
+	AnalyticsByStatus   = "status"
+	AnalyticsByCountry  = "country"
+)
+
+// Number of hours and days of the request analytics, by default and at
+// most
+const (
+	AnalyticsHours    = 24
+	AnalyticsDays     = 30
+	MaxAnalyticsHours = 744
+	MaxAnalyticsDays  = 366
+)
+
+var (
+	ErrInvalidInterval = errors.New("the interval must be hour or day")
+	ErrInvalidGrouping = errors.New("the requests can only be grouped by endpoint, status or country")
+)
+
+// RequestAnalytics returns the requests of a database over the last periods
+// hours or days, the current one included, with a series for each endpoint
+// class, status class or country when by is set. The periods without
+// requests are included so the series can be charted as is.
+func RequestAnalytics(conf model.DatabaseConfig, interval string, periods int, by string) (model.RequestAnalytics, error) {
+	ra := model.RequestAnalytics{Interval: interval, By: by}
+
+	now := time.Now().UTC()
+
+	var start time.Time
+	var step func(t time.Time) time.Time
+	var format string
+	switch interval {
+	case AnalyticsHourly:
+		start = now.Truncate(time.Hour).Add(-time.Duration(periods-1) * time.Hour)
+		step = func(t time.Time) time.Time { return t.Add(time.Hour) }
+		format = analytics.HourFormat
+	case AnalyticsDaily:
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(periods - 1))
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+		format = "2006-01-02"
+	default:
+		return ra, ErrInvalidInterval
+	}
+
+	switch by {
+	case "", AnalyticsByEndpoint, AnalyticsByStatus, AnalyticsByCountry:
+	default:
+		return ra, ErrInvalidGrouping
+	}
+
+	rollups, err := DB.ListRequestRollups(model.RequestRollupFilter{
+		DBName: conf.Name,
+		Since:  start.Format(analytics.HourFormat),
+		Until:  now.Format(analytics.HourFormat),
+	})
+	if err != nil {
+		return ra, err
+	}
+
+	index := make(map[string]int)
+	for t, i := start, 0; i < periods; t, i = step(t), i+1 {
+		period := t.Format(format)
+		index[period] = i
+		ra.Series = append(ra.Series, model.AnalyticsPoint{Period: period})
+	}
+
+	// the totals are kept apart to compute the averages once summed
+	totals := make(map[string][]int64)
+	seriesTotals := make([]int64, periods)
+
+	if len(by) > 0 {
+		ra.Breakdown = make(map[string][]model.AnalyticsPoint)
+	}
+
+	for _, rr := range rollups {
+		hour, err := time.Parse(analytics.HourFormat, rr.Hour)
+		if err != nil {
+			continue
+		}
+
+		i, ok := index[hour.Format(format)]
+		if !ok {
+			continue
+		}
+
+		addRollup(&ra.Series[i], rr)
+		seriesTotals[i] += rr.TotalMillis
+
+		if len(by) == 0 {
+			continue
+		}
+
+		key := rollupGroup(rr, by)
+		series, ok := ra.Breakdown[key]
+		if !ok {
+			series = make([]model.AnalyticsPoint, periods)
+			for j := range series {
+				series[j].Period = ra.Series[j].Period
+			}
+			ra.Breakdown[key] = series
+			totals[key] = make([]int64, periods)
+		}
+
+		addRollup(&series[i], rr)
+		totals[key][i] += rr.TotalMillis
+	}
+
+	averages(ra.Series, seriesTotals)
+	for key, series := range ra.Breakdown {
+		averages(series, totals[key])
+	}
+	return ra, nil
+}
+
+func addRollup(p *model.AnalyticsPoint, rr model.RequestRollup) {
+	p.Requests += rr.Count
+	switch {
+	case rr.Status >= 500:
+		p.Errors += rr.Count
+	case rr.Status >= 400:
+		p.ClientErrors += rr.Count
+	}
+	if rr.MaxMillis > p.MaxMillis {
+		p.MaxMillis = rr.MaxMillis
+	}
+}
+
+func averages(series []model.AnalyticsPoint, totals []int64) {
+	for i := range series {
+		if series[i].Requests > 0 {
+			series[i].AvgMillis = totals[i] / series[i].Requests
+		}
+	}
+}
+
+// rollupGroup returns the key of the rollup's breakdown series, unknown for
+// the requests without country
+func rollupGroup(rr model.RequestRollup, by string) string {
+	switch by {
+	case AnalyticsByStatus:
+		return strconv.Itoa(rr.Status)
+	case AnalyticsByCountry:
+		if len(rr.Country) == 0 {
+			return "unknown"
+		}
+		return rr.Country
+	default:
+		return rr.Endpoint
+	}
+}
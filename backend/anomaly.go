@@ -0,0 +1,178 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+const (
+	// AnomalyInterval is how often the primary instance analyzes the
+	// metered usage
+	AnomalyInterval = time.Hour
+	// AnomalyFactor is how many times the daily average a metric must
+	// reach to be a spike
+	AnomalyFactor = 10
+	// anomalyBaselineDays is the number of previous days averaged
+	anomalyBaselineDays = 7
+)
+
+// anomalyMinimums are the metrics analyzed with the value they must reach
+// to be a spike, so a few failures on a quiet app are not reported
+var anomalyMinimums = map[string]int64{
+	model.MeterFunctionFailures: 20,
+	model.MeterStorageBytes:     100 << 20,
+	model.MeterLoginFailures:    50,
+}
+
+// DetectAnomalies returns the metrics of the databases which reached
+// AnomalyFactor times their daily average over the previous days on day.
+// The databases without usage before day have no baseline and are skipped.
+func DetectAnomalies(records []model.UsageRecord, day time.Time) []model.Anomaly {
+	today := day.UTC().Format("2006-01-02")
+
+	type dbMetric struct{ dbName, metric string }
+
+	tenants := make(map[string]string)
+	history := make(map[string]bool)
+	current := make(map[dbMetric]int64)
+	previous := make(map[dbMetric]int64)
+	for _, u := range records {
+		key := dbMetric{u.DBName, u.Metric}
+		if u.Day == today {
+			current[key] += u.Value
+			tenants[u.DBName] = u.TenantID
+		} else if u.Day < today {
+			previous[key] += u.Value
+			history[u.DBName] = true
+		}
+	}
+
+	var anomalies []model.Anomaly
+	for key, value := range current {
+		floor, ok := anomalyMinimums[key.metric]
+		if !ok || !history[key.dbName] || value < floor {
+			continue
+		}
+
+		baseline := float64(previous[key]) / anomalyBaselineDays
+		if float64(value) < AnomalyFactor*baseline {
+			continue
+		}
+
+		anomalies = append(anomalies, model.Anomaly{
+			TenantID: tenants[key.dbName],
+			DBName:   key.dbName,
+			Metric:   key.metric,
+			Day:      today,
+			Value:    value,
+			Baseline: baseline,
+		})
+	}
+	return anomalies
+}
+
+// AnalyzeUsage detects the anomalies of the current day for all databases
+// and notifies their owner once per metric and day, it returns the number
+// of new anomalies
+func AnalyzeUsage() (int, error) {
+	bases, err := DB.ListDatabases()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -anomalyBaselineDays).Format("2006-01-02")
+
+	confs := make(map[string]model.DatabaseConfig)
+	tenants := make(map[string]bool)
+	for _, conf := range bases {
+		confs[conf.Name] = conf
+		tenants[conf.TenantID] = true
+	}
+
+	count := 0
+	for tenantID := range tenants {
+		records, err := DB.ListUsage(model.UsageFilter{
+			TenantID: tenantID,
+			Since:    since,
+			Until:    now.Format("2006-01-02"),
+		})
+		if err != nil {
+			return count, err
+		}
+
+		for _, a := range DetectAnomalies(records, now) {
+			conf, ok := confs[a.DBName]
+			if !ok {
+				continue
+			}
+
+			key := fmt.Sprintf("anomaly:%s:%s:%s", a.DBName, a.Metric, a.Day)
+			if ok, err := Cache.SetNX(key, "1", 25*time.Hour); err != nil {
+				return count, err
+			} else if !ok {
+				continue
+			}
+
+			notifyAnomaly(conf, a)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// notifyAnomaly emails the owner of the database and sends the
+// anomaly.detected webhook event
+func notifyAnomaly(conf model.DatabaseConfig, a model.Anomaly) {
+	Log.Warn().Msgf("anomaly on %s: %s reached %d for a daily average of %.1f", a.DBName, a.Metric, a.Value, a.Baseline)
+
+	EmitWebhook(conf, model.WebhookAnomalyDetected, a)
+
+	cus, err := DB.FindTenant(conf.TenantID)
+	if err != nil {
+		Log.Error().Err(err).Msgf("cannot find the tenant of %s", conf.Name)
+		return
+	}
+
+	body := fmt.Sprintf(`
+	<p>Hello,</p>
+	<p>We noticed an unusual spike on your database %s today.</p>
+	<p>The %s metric reached %d while its daily average over the last %d
+	days is %.1f.</p>
+	<p>If this is expected you can ignore this email, otherwise check your
+	functions, uploads and sign-ins.</p>
+	`, conf.Name, a.Metric, a.Value, anomalyBaselineDays, a.Baseline)
+
+	mail := email.SendMailData{
+		From:     Config.FromEmail,
+		FromName: Config.FromName,
+		To:       cus.Email,
+		Subject:  "Unusual activity on your database " + conf.Name,
+		HTMLBody: body,
+		TextBody: email.StripHTML(body),
+	}
+	if err := Emailer.Send(mail); err != nil {
+		Log.Error().Err(err).Msgf("cannot email the anomaly of %s", conf.Name)
+	}
+}
+
+func analyzeUsageEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if _, err := AnalyzeUsage(); err != nil {
+			Log.Error().Err(err).Msg("error analyzing the usage anomalies")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package backend_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	now := time.Now().UTC()
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+
+	records := []model.UsageRecord{
+		{DBName: "steady", Day: yesterday, Metric: model.MeterFunctionFailures, Value: 70},
+		{DBName: "steady", Day: today, Metric: model.MeterFunctionFailures, Value: 30},
+		{DBName: "spiking", TenantID: "t1", Day: yesterday, Metric: model.MeterLoginFailures, Value: 7},
+		{DBName: "spiking", TenantID: "t1", Day: today, Metric: model.MeterLoginFailures, Value: 500},
+		{DBName: "quiet", Day: yesterday, Metric: model.MeterAPICalls, Value: 1},
+		{DBName: "quiet", Day: today, Metric: model.MeterFunctionFailures, Value: 5},
+		{DBName: "new", Day: today, Metric: model.MeterFunctionFailures, Value: 1000},
+		{DBName: "billed", Day: yesterday, Metric: model.MeterAPICalls, Value: 1},
+		{DBName: "billed", Day: today, Metric: model.MeterAPICalls, Value: 100000},
+	}
+
+	anomalies := backend.DetectAnomalies(records, now)
+	if len(anomalies) != 1 {
+		t.Fatalf("expected only the login failures spike got %v", anomalies)
+	}
+
+	a := anomalies[0]
+	if a.DBName != "spiking" || a.TenantID != "t1" || a.Metric != model.MeterLoginFailures || a.Value != 500 || a.Baseline != 1 {
+		t.Errorf("unexpected anomaly %v", a)
+	}
+}
+
+func TestAnalyzeUsage(t *testing.T) {
+	app, _ := newBackupDatabase(t, "anomalyapp", "root@anomalyapp.com")
+
+	now := time.Now().UTC()
+	records := []model.UsageRecord{
+		{TenantID: app.TenantID, DBName: app.Name, Day: now.AddDate(0, 0, -2).Format("2006-01-02"), Metric: model.MeterStorageBytes, Value: 1 << 20},
+		{TenantID: app.TenantID, DBName: app.Name, Day: now.Format("2006-01-02"), Metric: model.MeterStorageBytes, Value: 500 << 20},
+	}
+	if err := backend.DB.AddUsage(records); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := backend.AnalyzeUsage(); err != nil {
+		t.Fatal(err)
+	} else if n < 1 {
+		t.Fatalf("expected the storage growth to be detected got %d", n)
+	}
+
+	// the owner is notified once per metric and day
+	if n, err := backend.AnalyzeUsage(); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Errorf("expected no new anomalies got %d", n)
+	}
+}
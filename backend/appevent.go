@@ -0,0 +1,110 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/staticbackendhq/core/ingest"
+	"github.com/staticbackendhq/core/model"
+)
+
+// EventFlushInterval is how often each instance writes the app events it
+// received
+const EventFlushInterval = 5 * time.Second
+
+// Limits of the app events sent at once
+const (
+	MaxEventsPerBatch  = 100
+	MaxEventProperties = 4096
+)
+
+// the clocks of the clients can be ahead
+const maxEventClockSkew = 5 * time.Minute
+
+var eventName = regexp.MustCompile(`^[A-Za-z0-9_.:\-]{1,64}$`)
+
+var (
+	ErrEventBatchSize = fmt.Errorf("a batch must have between 1 and %d events", MaxEventsPerBatch)
+	ErrEventsDropped  = errors.New("the events are not accepted at the moment, retry later")
+)
+
+// IngestEvents validates the events sent by a client of the app and
+// buffers them, they're written to the database in batches. It returns the
+// number of events accepted, the whole batch is refused when an event is
+// invalid.
+func IngestEvents(conf model.DatabaseConfig, events []model.AppEvent) (int, error) {
+	if len(events) == 0 || len(events) > MaxEventsPerBatch {
+		return 0, ErrEventBatchSize
+	}
+
+	now := time.Now().UTC()
+	for i := range events {
+		evt := &events[i]
+
+		if !eventName.MatchString(evt.Name) {
+			return 0, fmt.Errorf("event %d: the name must be 1 to 64 letters, digits or _.:-", i)
+		}
+
+		b, err := json.Marshal(evt.Properties)
+		if err != nil {
+			return 0, fmt.Errorf("event %d: %w", i, err)
+		} else if len(b) > MaxEventProperties {
+			return 0, fmt.Errorf("event %d: the properties exceed %d bytes", i, MaxEventProperties)
+		}
+
+		evt.ID = ""
+		evt.Received = now
+		if evt.Timestamp.IsZero() || evt.Timestamp.After(now.Add(maxEventClockSkew)) {
+			evt.Timestamp = now
+		}
+	}
+
+	n := ingest.Add(conf.Name, events)
+	if n == 0 {
+		return 0, ErrEventsDropped
+	}
+	return n, nil
+}
+
+// EventSeries returns the number of events received per day over the last
+// days days, the current one included, for each event name or only name
+// when set. The days without events are included.
+func EventSeries(conf model.DatabaseConfig, name string, days int) (map[string][]model.UsagePoint, error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := today.AddDate(0, 0, -(days - 1))
+
+	rollups, err := DB.ListAppEventRollups(conf.Name, model.AppEventRollupFilter{
+		Name:  name,
+		Since: start.Format("2006-01-02"),
+		Until: today.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	for i := 0; i < days; i++ {
+		index[start.AddDate(0, 0, i).Format("2006-01-02")] = i
+	}
+
+	series := make(map[string][]model.UsagePoint)
+	for _, r := range rollups {
+		points, ok := series[r.Name]
+		if !ok {
+			points = make([]model.UsagePoint, days)
+			for i := range points {
+				points[i].Period = start.AddDate(0, 0, i).Format("2006-01-02")
+			}
+			series[r.Name] = points
+		}
+
+		if i, ok := index[r.Day]; ok {
+			points[i].Value += r.Count
+		}
+	}
+	return series, nil
+}
@@ -122,6 +122,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/staticbackendhq/core/analytics"
+	"github.com/staticbackendhq/core/antivirus"
 	"github.com/staticbackendhq/core/cache"
 	"github.com/staticbackendhq/core/config"
 	"github.com/staticbackendhq/core/database"
@@ -130,11 +132,21 @@ import (
 	"github.com/staticbackendhq/core/database/postgresql"
 	"github.com/staticbackendhq/core/database/sqlite"
 	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/eventbridge"
 	"github.com/staticbackendhq/core/function"
+	"github.com/staticbackendhq/core/hotcache"
+	"github.com/staticbackendhq/core/ingest"
+	"github.com/staticbackendhq/core/leader"
 	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/metering"
+	"github.com/staticbackendhq/core/middleware"
 	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/push"
+	"github.com/staticbackendhq/core/quota"
 	"github.com/staticbackendhq/core/search"
 	"github.com/staticbackendhq/core/storage"
+	"github.com/staticbackendhq/core/tracing"
+	"github.com/staticbackendhq/core/webhook"
 	mongodrv "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -168,14 +180,35 @@ var (
 	// storage as well as the database storage.
 	Storage func(model.Auth, model.DatabaseConfig) FileStore
 
-	// Scheduler to execute schedule jobs (only on PrimaryInstance)
+	// Scheduler to execute schedule jobs (only on the leader instance)
 	Scheduler *function.TaskScheduler
+	// Leader elects the instance running the singleton background workers
+	Leader *leader.Elector
+
+	// WebPush sends Web Push notifications, nil if VAPID is not configured
+	WebPush *push.WebPush
+	// Push forwards channel messages to offline users' devices
+	Push *push.Notifier
+	// ChannelHooks posts the channel messages to the configured webhooks
+	ChannelHooks *webhook.ChannelBatcher
+	// Events mirrors system events onto Kafka, nil if not configured
+	Events *eventbridge.Bridge
+	// Antivirus scans the uploaded files, nil if not configured
+	Antivirus antivirus.Scanner
 )
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
+const (
+	// LeaderKey is the cache key of the leadership lease
+	LeaderKey = "sb-leader"
+	// LeaderLease is how long the leadership lasts without being renewed,
+	// another instance takes over at most this long after the leader died
+	LeaderLease = 15 * time.Second
+)
+
 // Setup initializes the core services based on the configuration received.
 func Setup(cfg config.AppConfig) {
 	Config = cfg
@@ -185,23 +218,50 @@ func Setup(cfg config.AppConfig) {
 	if strings.EqualFold(cfg.DatabaseURL, "mem") || strings.EqualFold(cfg.RedisHost, "mem") {
 		Cache = cache.NewDevCache(Log)
 	} else {
-		Cache = cache.NewCache(Log)
+		c := cache.NewCache(Log)
+
+		// the tenant metadata and realtime messages are replicated to the
+		// other regions
+		if len(cfg.RegionPeers) > 0 {
+			peers, err := cache.NewPeers(cfg.RegionPeers, Log)
+			if err != nil {
+				Log.Fatal().Err(err).Msg("invalid REGION_PEERS value")
+			}
+
+			c.SetPeers(peers)
+			Log.Info().Str("region", cfg.Region).Int("peers", len(peers)).Msg("multi-region replication enabled")
+		}
+
+		Cache = c
+	}
+
+	if err := tracing.Setup(cfg); err != nil {
+		Log.Fatal().Err(err).Msg("unable to initialize tracing")
+	}
+
+	if tracing.Enabled() {
+		Cache = tracing.Volatilizer(Cache)
 	}
 
 	persister := config.Current.DataStore
 	if strings.EqualFold(cfg.DatabaseURL, "mem") {
 		DB = memory.New(Cache.PublishDocument)
-	} else if strings.EqualFold(persister, "mongo") {
-		cl, err := openMongoDatabase(cfg.DatabaseURL)
+	} else if strings.EqualFold(persister, database.DataStoreMongoDB) {
+		pool := &mongoPool{maxOpen: cfg.DatabaseMaxOpenConns}
+		cl, err := openMongoDatabase(cfg.DatabaseURL, pool)
 		if err != nil {
 			Log.Fatal().Err(err).Msg("failed to create connection with mongodb")
 		}
+		poolStats = pool.stats
+
 		DB = mongo.New(cl, Cache.PublishDocument, Log)
-	} else if strings.EqualFold(persister, "sqlite") {
+	} else if strings.EqualFold(persister, database.DataStoreSQLite) {
 		cl, err := openSQLite(cfg.DatabaseURL)
 		if err != nil {
 			Log.Fatal().Err(err).Msg("failed to create connection with SQLite")
 		}
+		setPoolLimits(cl, cfg)
+		poolStats = sqlPoolStats(cl)
 
 		DB = sqlite.New(cl, Cache.PublishDocument, Log)
 	} else {
@@ -209,23 +269,38 @@ func Setup(cfg config.AppConfig) {
 		if err != nil {
 			Log.Fatal().Err(err).Msg("failed to create connection with postgres")
 		}
+		setPoolLimits(cl, cfg)
+		poolStats = sqlPoolStats(cl)
 
 		DB = postgresql.New(cl, Cache.PublishDocument, Log)
 	}
 
-	mp := cfg.MailProvider
-	if strings.EqualFold(mp, email.MailProviderSES) {
-		Emailer = email.AWSSES{}
-	} else {
-		Emailer = email.Dev{}
+	if tracing.Enabled() {
+		DB = tracing.Persister(DB)
 	}
 
-	sp := cfg.StorageProvider
-	if strings.EqualFold(sp, storage.StorageProviderS3) {
-		Filestore = storage.S3{}
-	} else {
-		Filestore = storage.Local{}
+	// the functions and root tokens looked up on every request are cached
+	DB = hotcache.Persister(DB, Cache)
+
+	mailer, err := email.NewMailer(model.EmailSettings{
+		Provider: cfg.MailProvider,
+		APIKey:   cfg.MailAPIKey,
+		Region:   cfg.MailRegion,
+		Domain:   cfg.MailgunDomain,
+		Host:     cfg.SMTPHost,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+	})
+	if err != nil {
+		Log.Fatal().Err(err).Msg("unable to initialize the mail provider")
 	}
+	Emailer = mailer
+
+	fs, err := newFilestore(cfg)
+	if err != nil {
+		Log.Fatal().Err(err).Msg("unable to initialize the storage provider")
+	}
+	Filestore = fs
 
 	if !cfg.NoFullTextSearch {
 		ftsFilename := cfg.FullTextIndexFile
@@ -241,63 +316,172 @@ func Setup(cfg config.AppConfig) {
 		Search = src
 	}
 
+	if len(cfg.KafkaRESTURL) > 0 {
+		bridge, err := eventbridge.New(cfg.KafkaRESTURL, cfg.KafkaTopics, cfg.KafkaUsername, cfg.KafkaPassword, Log)
+		if err != nil {
+			Log.Fatal().Err(err).Msg("unable to start the Kafka event bridge")
+		}
+
+		Events = bridge
+	}
+
+	quota.Enforced[quota.Storage] = cfg.StorageQuotas
+	quota.Enforced[quota.Emails] = cfg.EmailQuotas
+	quota.Enforced[quota.FunctionRuns] = cfg.FunctionQuotas
+	quota.Enforced[quota.Connections] = cfg.RateLimit
+	FeatureDefaults = parseFeatureDefaults(cfg.FeatureFlags)
+
+	kr, err := newKeyring(cfg)
+	if err != nil {
+		Log.Fatal().Err(err).Msg("unable to initialize the vault")
+	}
+	Vault = kr
+
+	Antivirus = antivirus.New(cfg.ClamdAddress, cfg.ScanAPIURL, cfg.ScanAPIKey)
+
+	setupPush(cfg)
+	ChannelHooks = webhook.NewChannelBatcher(Log)
+
 	sub := &function.Subscriber{Log: Log}
 	sub.PubSub = Cache
+	sub.Notify = onChannelMessage
 	sub.GetExecEnv = func(msg model.Command) (*function.ExecutionEnvironment, error) {
 		exe := &function.ExecutionEnvironment{
-			Auth:      msg.Auth,
-			BaseName:  msg.Base,
-			DataStore: DB,
-			Volatile:  Cache,
-			Search:    Search,
-			Email:     Emailer,
-			Log:       Log,
+			Auth:       msg.Auth,
+			BaseName:   msg.Base,
+			DataStore:  DB,
+			Volatile:   Cache,
+			Search:     Search,
+			Email:      databaseMailer(msg.Base),
+			Events:     Events,
+			Storage:    Filestore,
+			Scheduler:  Scheduler,
+			Log:        Log,
+			OnComplete: FunctionCompleted,
+			BeforeRun:  BeforeFunctionRun,
+			Flag:       DatabaseFlagEnabled,
+			Secret:     DatabaseSecret,
+			Settings:   FunctionSettings,
 		}
 
 		return exe, nil
 	}
 
-	isPrimary := false
-	if len(cfg.PrimaryInstanceHostname) == 0 {
-		// if no value is provided, like on GH action for tests, we assume primary
-		isPrimary = true
-	} else if hostname, err := os.Hostname(); err != nil {
-		Log.Warn().Err(err).Msg("cannot determine if it's primary instance")
-	} else if strings.EqualFold(hostname, cfg.PrimaryInstanceHostname) {
-		isPrimary = true
+	hostname, err := os.Hostname()
+	if err != nil {
+		Log.Warn().Err(err).Msg("cannot determine the instance hostname")
 	}
 
-	sub.IsPrimaryInstance = isPrimary
+	// all instances campaign for the leadership unless it's pinned to the
+	// primary instance, which then has no failover
+	candidate := len(cfg.PrimaryInstanceHostname) == 0 ||
+		strings.EqualFold(hostname, cfg.PrimaryInstanceHostname)
+
+	instanceID := fmt.Sprintf("%s-%d", hostname, rand.Int63())
+	Leader = leader.New(Cache, LeaderKey, instanceID, LeaderLease, Log)
+
+	sub.IsLeader = Leader.IsLeader
+
+	// the events bridged from the other regions are handled there
+	sub.Handles = func(dbName string) bool {
+		conf, err := findDatabaseByName(dbName)
+		return err != nil || middleware.InRegion(conf)
+	}
 
 	// start system events subscriber
 	go sub.Start()
 
-	// for primary instance, we start the job scheduler
-	if isPrimary {
-		runner := &function.TaskScheduler{
-			Volatile:  Cache,
-			DataStore: DB,
-			Search:    Search,
-			Email:     Emailer,
-			Log:       Log,
-		}
+	// resumable uploads are assembled on the instance receiving the chunks
+	go cleanupUploadsEvery(time.Hour)
+
+	// every instance writes the usage it metered, the requests it served
+	// and the app events it received
+	metering.Default.Start(DB, UsageFlushInterval, Log)
+	analytics.Default.Start(DB, UsageFlushInterval, Log)
+	ingest.Default.Start(DB, EventFlushInterval, Log)
+
+	// the leader instance runs the job scheduler, the email and webhook
+	// queues, the app deletions, the secrets' rewrapping and the usage
+	// anomaly detection
+	Leader.Register("scheduler", func(ctx context.Context) {
+		// a new runner schedules all the tasks again
+		runner := newTaskRunner()
 
 		Scheduler = runner
 		go runner.Start()
-		Log.Info().Msg("job scheduler / runner started on primary instance")
+		Log.Info().Msg("job scheduler / runner started on the leader instance")
+
+		<-ctx.Done()
+		runner.Stop()
+	})
+	Leader.Register("email queue", func(ctx context.Context) {
+		processEmailQueueEvery(ctx, EmailQueueInterval)
+	})
+	Leader.Register("webhook queue", func(ctx context.Context) {
+		processWebhookQueueEvery(ctx, WebhookQueueInterval)
+	})
+	Leader.Register("app deletions", func(ctx context.Context) {
+		processAppDeletionsEvery(ctx, AppDeletionInterval)
+	})
+	Leader.Register("secrets rewrap", func(ctx context.Context) {
+		rewrapSecretsEvery(ctx, SecretRewrapInterval)
+	})
+	Leader.Register("usage anomalies", func(ctx context.Context) {
+		analyzeUsageEvery(ctx, AnomalyInterval)
+	})
+
+	if candidate {
+		Leader.Start()
 	}
 
 	Membership = newUser
 	Storage = newFile
+
+	// the plans' quotas replace the default ones
+	plans := DefaultPlans(cfg)
+	if len(cfg.BillingPlans) > 0 {
+		var err error
+		if plans, err = LoadPlans(cfg.BillingPlans); err != nil {
+			Log.Fatal().Err(err).Msg("unable to load the BILLING_PLANS")
+		}
+	}
+	SetPlans(plans)
+}
+
+// newTaskRunner returns a task runner using the configured services, it
+// schedules the tasks once started
+func newTaskRunner() *function.TaskScheduler {
+	return &function.TaskScheduler{
+		Volatile:   Cache,
+		DataStore:  DB,
+		Search:     Search,
+		Email:      Emailer,
+		MailerFor:  databaseMailer,
+		Events:     Events,
+		Storage:    Filestore,
+		Log:        Log,
+		OnComplete: FunctionCompleted,
+		BeforeRun:  BeforeFunctionRun,
+		BeforeTask: CheckSuspended,
+		Backup:     RunBackupTask,
+		Flag:       DatabaseFlagEnabled,
+		Secret:     DatabaseSecret,
+		Settings:   FunctionSettings,
+	}
 }
 
-func openMongoDatabase(dbHost string) (*mongodrv.Client, error) {
+func openMongoDatabase(dbHost string, pool *mongoPool) (*mongodrv.Client, error) {
 	uri := dbHost
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	cl, err := mongodrv.Connect(ctx, options.Client().ApplyURI(uri))
+	opts := options.Client().ApplyURI(uri).SetPoolMonitor(pool.monitor())
+	if pool.maxOpen > 0 {
+		opts.SetMaxPoolSize(uint64(pool.maxOpen))
+	}
+
+	cl, err := mongodrv.Connect(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("cannot connect to mongo: %v", err)
 	}
@@ -323,6 +507,17 @@ func openPGDatabase(dbHost string) (*sql.DB, error) {
 	return dbConn, nil
 }
 
+// setPoolLimits applies the configured limits to the connections pool, all
+// the apps' databases share it
+func setPoolLimits(dbConn *sql.DB, cfg config.AppConfig) {
+	if cfg.DatabaseMaxOpenConns > 0 {
+		dbConn.SetMaxOpenConns(cfg.DatabaseMaxOpenConns)
+	}
+	if cfg.DatabaseMaxIdleConns > 0 {
+		dbConn.SetMaxIdleConns(cfg.DatabaseMaxIdleConns)
+	}
+}
+
 func openSQLite(url string) (*sql.DB, error) {
 	dbConn, err := sql.Open("sqlite", url)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/staticbackendhq/core/backend"
 	"github.com/staticbackendhq/core/config"
 	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
 )
 
 var (
@@ -40,6 +41,9 @@ func TestMain(t *testing.M) {
 	// initializes all core services basesd on config
 	backend.Setup(config.Current)
 
+	// the webhooks are delivered to test servers on a loopback address
+	webhook.AllowPrivateNetworks = true
+
 	setup()
 
 	os.Exit(t.Run())
@@ -0,0 +1,623 @@
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// backupVersion is the version of the BackupData format
+const backupVersion = 1
+
+// backupPageSize is the number of documents read at once during a backup
+const backupPageSize = 1000
+
+var (
+	// ErrBackupNotFound is returned when restoring or deleting a backup of
+	// another tenant or one that does not exist
+	ErrBackupNotFound = errors.New("backup not found")
+	// ErrInvalidBackup is returned when a backup cannot be decrypted or
+	// read, i.e. the backup key changed
+	ErrInvalidBackup = errors.New("invalid backup")
+)
+
+// BackupDatabase exports all the data of a database, encrypts it and saves
+// it to the file storage. taskID is the backup task making it, empty for
+// an on-demand backup.
+func BackupDatabase(conf model.DatabaseConfig, taskID string) (model.Backup, error) {
+	data, docs, err := exportDatabase(conf.Name)
+	if err != nil {
+		return model.Backup{}, err
+	}
+
+	buf, err := sealBackup(data)
+	if err != nil {
+		return model.Backup{}, err
+	}
+
+	name := make([]byte, 8)
+	if _, err := rand.Read(name); err != nil {
+		return model.Backup{}, err
+	}
+
+	b := model.Backup{
+		TenantID:  conf.TenantID,
+		DBName:    conf.Name,
+		TaskID:    taskID,
+		Key:       fmt.Sprintf("backups/%s/%s.bak", conf.Name, hex.EncodeToString(name)),
+		Size:      int64(len(buf)),
+		Documents: docs,
+		Created:   data.Created,
+	}
+
+	upload := model.UploadFileData{FileKey: b.Key, File: bytes.NewReader(buf)}
+	if _, err := Filestore.Save(upload); err != nil {
+		return b, err
+	}
+
+	b.ID, err = DB.AddBackup(b)
+	if err != nil {
+		return b, err
+	}
+	return b, nil
+}
+
+// ListBackups returns the backups of a database, the newest first
+func ListBackups(conf model.DatabaseConfig) ([]model.Backup, error) {
+	return DB.ListBackups(conf.Name)
+}
+
+// DeleteBackup removes a backup of the database from the file storage
+func DeleteBackup(conf model.DatabaseConfig, id string) error {
+	b, err := findBackup(conf, id)
+	if err != nil {
+		return err
+	}
+
+	return removeBackup(b)
+}
+
+// RestoreBackup restores a backup of the tenant into a database, the one
+// backed up or another one of the same tenant. The collections of the backup
+// are replaced, the users whose email exists are kept and the files,
+// functions, tasks and forms missing in the database are added.
+func RestoreBackup(conf model.DatabaseConfig, id string) (model.RestoreReport, error) {
+	report := model.RestoreReport{Documents: make(map[string]int)}
+
+	b, err := findBackup(conf, id)
+	if err != nil {
+		return report, err
+	}
+
+	rc, err := Filestore.Open(b.Key)
+	if err != nil {
+		return report, err
+	}
+	defer rc.Close()
+
+	buf, err := io.ReadAll(rc)
+	if err != nil {
+		return report, err
+	}
+
+	data, err := openBackup(buf)
+	if err != nil {
+		return report, err
+	}
+
+	root, err := DB.GetRootForBase(conf.Name)
+	if err != nil {
+		return report, err
+	}
+
+	r := &restorer{
+		conf:   conf,
+		root:   userAuth(root),
+		report: &report,
+		owners: make(map[string]model.Auth),
+	}
+
+	r.restoreAccounts(data.Accounts)
+	if err := r.restoreDocuments(data.Documents); err != nil {
+		return report, err
+	}
+	r.restoreForms(data.Forms)
+	r.restoreFiles(data.Files)
+	r.restoreFunctions(data.Functions)
+	r.restoreTasks(data.Tasks)
+
+	return report, nil
+}
+
+// RunBackupTask backs up the database of a backup task and removes its
+// oldest backups over the number kept, the task's value. It's the Backup of
+// the task runner.
+func RunBackupTask(task model.Task) (string, error) {
+	keep, err := strconv.Atoi(task.Value)
+	if err != nil || keep <= 0 {
+		return "", fmt.Errorf("invalid number of backups kept %q", task.Value)
+	}
+
+	conf, err := findDatabaseByName(task.BaseName)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := BackupDatabase(conf, task.ID)
+	if err != nil {
+		return "", err
+	}
+
+	list, err := DB.ListBackups(conf.Name)
+	if err != nil {
+		return "", err
+	}
+
+	// only the backups of this task are rotated
+	kept := 0
+	for _, old := range list {
+		if old.TaskID != task.ID {
+			continue
+		}
+
+		kept++
+		if kept <= keep {
+			continue
+		}
+
+		if err := removeBackup(old); err != nil {
+			Log.Warn().Err(err).Msgf("cannot remove the backup %s", old.ID)
+		}
+	}
+
+	return fmt.Sprintf("backed up %d documents in %d bytes", b.Documents, b.Size), nil
+}
+
+// findBackup returns a backup of the database or of another database of its
+// tenant
+func findBackup(conf model.DatabaseConfig, id string) (model.Backup, error) {
+	b, err := DB.GetBackup(id)
+	if err != nil {
+		return b, ErrBackupNotFound
+	} else if b.DBName != conf.Name && b.TenantID != conf.TenantID {
+		return b, ErrBackupNotFound
+	}
+	return b, nil
+}
+
+func removeBackup(b model.Backup) error {
+	if err := Filestore.Delete(b.Key); err != nil {
+		return err
+	}
+	return DB.DeleteBackup(b.ID)
+}
+
+// exportDatabase reads all the data of a database and returns it with its
+// number of documents
+func exportDatabase(dbName string) (data model.BackupData, docs int64, err error) {
+	data = model.BackupData{
+		Version:   backupVersion,
+		DBName:    dbName,
+		Created:   time.Now(),
+		Documents: make(map[string][]map[string]interface{}),
+		Forms:     make(map[string][]map[string]interface{}),
+	}
+
+	accounts, err := DB.ListAccounts(dbName)
+	if err != nil {
+		return
+	}
+
+	for _, acct := range accounts {
+		users, err := DB.ListUsers(dbName, acct.ID)
+		if err != nil {
+			return data, 0, err
+		}
+
+		ba := model.BackupAccount{ID: acct.ID, Email: acct.Email}
+		for _, u := range users {
+			ba.Users = append(ba.Users, model.BackupUser{
+				ID:       u.ID,
+				Email:    u.Email,
+				Password: u.Password,
+				Token:    u.Token,
+				Role:     u.Role,
+			})
+		}
+		data.Accounts = append(data.Accounts, ba)
+	}
+
+	root, err := DB.GetRootForBase(dbName)
+	if err != nil {
+		return
+	}
+	auth := userAuth(root)
+
+	cols, err := DB.ListCollections(dbName)
+	if err != nil {
+		return
+	}
+
+	for _, col := range cols {
+		if strings.HasPrefix(col, "sb_") {
+			continue
+		}
+
+		params := model.ListParams{Page: 1, Size: backupPageSize}
+		for {
+			res, err := DB.ListDocuments(auth, dbName, col, params)
+			if err != nil {
+				return data, 0, err
+			}
+
+			data.Documents[col] = append(data.Documents[col], res.Results...)
+			docs += int64(len(res.Results))
+
+			if int64(len(res.Results)) < params.Size {
+				break
+			}
+			params.Page++
+		}
+	}
+
+	forms, err := DB.GetForms(dbName)
+	if err != nil {
+		return
+	}
+
+	for _, form := range forms {
+		entries, err := DB.ListFormSubmissions(dbName, form)
+		if err != nil {
+			return data, 0, err
+		}
+		data.Forms[form] = entries
+	}
+
+	if data.Files, err = DB.ListAllFiles(dbName, ""); err != nil {
+		return
+	}
+
+	if data.Functions, err = DB.ListFunctions(dbName); err != nil {
+		return
+	}
+
+	data.Tasks, err = DB.ListTasksByBase(dbName)
+	return
+}
+
+// backupCipher returns the AES-GCM cipher of the backups, its key is
+// derived from the BackupKey or the AppSecret
+func backupCipher() (cipher.AEAD, error) {
+	secret := Config.BackupKey
+	if len(secret) == 0 {
+		secret = Config.AppSecret
+	}
+
+	key := sha256.Sum256([]byte(secret))
+
+	c, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
+
+// sealBackup gzips and encrypts the backup data
+func sealBackup(data model.BackupData) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(data); err != nil {
+		return nil, err
+	} else if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	gcm, err := backupCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, buf.Bytes(), nil), nil
+}
+
+// openBackup decrypts and reads the backup data
+func openBackup(b []byte) (data model.BackupData, err error) {
+	gcm, err := backupCipher()
+	if err != nil {
+		return
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(b) < nonceSize {
+		return data, ErrInvalidBackup
+	}
+
+	plain, err := gcm.Open(nil, b[:nonceSize], b[nonceSize:], nil)
+	if err != nil {
+		return data, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return data, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer zr.Close()
+
+	if err := json.NewDecoder(zr).Decode(&data); err != nil {
+		return data, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	return data, nil
+}
+
+// restorer restores a backup in a database, the documents without a known
+// owner go in the root account
+type restorer struct {
+	conf   model.DatabaseConfig
+	root   model.Auth
+	report *model.RestoreReport
+
+	// owners are the accounts of the restored users by backed up account id
+	owners map[string]model.Auth
+}
+
+// fail records an error of the restore, the restore continues
+func (r *restorer) fail(format string, args ...interface{}) {
+	if len(r.report.Errors) < maxImportErrors {
+		r.report.Errors = append(r.report.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+// restoreAccounts creates the accounts and users of the backup, the users
+// whose email exists are kept and own the documents of their account
+func (r *restorer) restoreAccounts(accounts []model.BackupAccount) {
+	for _, acct := range accounts {
+		var acctID string
+		for _, u := range acct.Users {
+			exists, err := DB.UserEmailExists(r.conf.Name, u.Email)
+			if err != nil {
+				r.fail("user %s: %v", u.Email, err)
+				continue
+			} else if exists {
+				if _, ok := r.owners[acct.ID]; !ok {
+					if tok, err := DB.FindUserByEmail(r.conf.Name, u.Email); err == nil {
+						r.owners[acct.ID] = userAuth(tok)
+					}
+				}
+
+				r.report.UsersSkipped++
+				continue
+			}
+
+			if len(acctID) == 0 {
+				if acctID, err = DB.CreateAccount(r.conf.Name, acct.Email); err != nil {
+					r.fail("account %s: %v", acct.Email, err)
+					break
+				}
+			}
+
+			tok := model.User{
+				AccountID: acctID,
+				Email:     u.Email,
+				Token:     u.Token,
+				Password:  u.Password,
+				Role:      u.Role,
+			}
+
+			tok.ID, err = DB.CreateUser(r.conf.Name, tok)
+			if err != nil {
+				r.fail("user %s: %v", u.Email, err)
+				continue
+			}
+
+			if _, ok := r.owners[acct.ID]; !ok {
+				r.owners[acct.ID] = userAuth(tok)
+			}
+			r.report.Users++
+		}
+	}
+}
+
+// restoreDocuments replaces the documents of the backed up collections, the
+// documents are recreated in the account of their restored owner
+func (r *restorer) restoreDocuments(docs map[string][]map[string]interface{}) error {
+	cols, err := DB.ListCollections(r.conf.Name)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for _, col := range cols {
+		existing[col] = true
+	}
+
+	for col, list := range docs {
+		if existing[col] {
+			if _, err := DB.DeleteDocuments(r.root, r.conf.Name, col, nil); err != nil {
+				return fmt.Errorf("cannot clear the collection %s: %w", col, err)
+			}
+		}
+
+		for _, doc := range list {
+			auth := r.root
+			if owner, ok := r.owners[fmt.Sprint(doc["accountId"])]; ok {
+				auth = owner
+			}
+
+			delete(doc, "id")
+			delete(doc, "accountId")
+
+			if _, err := DB.CreateDocument(auth, r.conf.Name, col, doc); err != nil {
+				r.fail("%s: %v", col, err)
+				continue
+			}
+			r.report.Documents[col]++
+		}
+	}
+	return nil
+}
+
+// restoreForms adds the submissions of the forms without any
+func (r *restorer) restoreForms(forms map[string][]map[string]interface{}) {
+	for form, entries := range forms {
+		cur, err := DB.ListFormSubmissions(r.conf.Name, form)
+		if err != nil {
+			r.fail("form %s: %v", form, err)
+			continue
+		} else if len(cur) > 0 {
+			continue
+		}
+
+		for _, entry := range entries {
+			delete(entry, "id")
+
+			if err := DB.AddFormSubmission(r.conf.Name, form, entry); err != nil {
+				r.fail("form %s: %v", form, err)
+				continue
+			}
+			r.report.Forms++
+		}
+	}
+}
+
+// restoreFiles adds the records of the missing files, their content is
+// still in the file storage
+func (r *restorer) restoreFiles(files []model.File) {
+	cur, err := DB.ListAllFiles(r.conf.Name, "")
+	if err != nil {
+		r.fail("files: %v", err)
+		return
+	}
+
+	keys := make(map[string]bool)
+	for _, f := range cur {
+		keys[f.Key] = true
+	}
+
+	for _, f := range files {
+		if keys[f.Key] {
+			continue
+		}
+
+		if owner, ok := r.owners[f.AccountID]; ok {
+			f.AccountID = owner.AccountID
+		} else {
+			f.AccountID = r.root.AccountID
+		}
+
+		if _, err := DB.AddFile(r.conf.Name, f); err != nil {
+			r.fail("file %s: %v", f.Key, err)
+			continue
+		}
+		r.report.Files++
+	}
+}
+
+// restoreFunctions adds the missing functions and updates the others by
+// name
+func (r *restorer) restoreFunctions(fns []model.ExecData) {
+	cur, err := DB.ListFunctions(r.conf.Name)
+	if err != nil {
+		r.fail("functions: %v", err)
+		return
+	}
+
+	byName := make(map[string]model.ExecData)
+	for _, fn := range cur {
+		byName[fn.FunctionName] = fn
+	}
+
+	for _, fn := range fns {
+		if ex, ok := byName[fn.FunctionName]; ok {
+			err = DB.UpdateFunction(r.conf.Name, ex.ID, fn.Code, fn.TriggerTopic)
+		} else {
+			fn.ID = ""
+			fn.AccountID = r.root.AccountID
+			fn.History = nil
+			_, err = DB.AddFunction(r.conf.Name, fn)
+		}
+
+		if err != nil {
+			r.fail("function %s: %v", fn.FunctionName, err)
+			continue
+		}
+		r.report.Functions++
+	}
+}
+
+// restoreTasks adds the missing tasks by name, the dependent tasks are
+// added once their prerequisite is
+func (r *restorer) restoreTasks(tasks []model.Task) {
+	cur, err := DB.ListTasksByBase(r.conf.Name)
+	if err != nil {
+		r.fail("tasks: %v", err)
+		return
+	}
+
+	// ids maps the backed up tasks' ids to the ones of the database
+	ids := make(map[string]string)
+	byName := make(map[string]string)
+	for _, t := range cur {
+		byName[t.Name] = t.ID
+	}
+
+	pending := tasks
+	for len(pending) > 0 {
+		var next []model.Task
+		for _, task := range pending {
+			if id, ok := byName[task.Name]; ok {
+				ids[task.ID] = id
+				continue
+			}
+
+			if task.IsDependent() {
+				after, ok := ids[task.After]
+				if !ok {
+					next = append(next, task)
+					continue
+				}
+				task.After = after
+			}
+
+			oldID := task.ID
+			task.ID = ""
+
+			added, err := AddTask(r.conf, task)
+			if err != nil {
+				r.fail("task %s: %v", task.Name, err)
+				continue
+			}
+
+			ids[oldID] = added.ID
+			byName[added.Name] = added.ID
+			r.report.Tasks++
+		}
+
+		// the prerequisites of the remaining tasks are missing
+		if len(next) == len(pending) {
+			for _, task := range next {
+				r.fail("task %s: cannot find the task to run after", task.Name)
+			}
+			break
+		}
+		pending = next
+	}
+}
@@ -0,0 +1,134 @@
+package backend_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+// newBackupDatabase creates a database of the test tenant with its root user
+func newBackupDatabase(t *testing.T, name, rootEmail string) (model.DatabaseConfig, model.Auth) {
+	conf, err := backend.DB.CreateDatabase(model.DatabaseConfig{
+		ID:       backend.DB.NewID(),
+		TenantID: base.TenantID,
+		Name:     name,
+		IsActive: true,
+		Created:  time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, tok, err := backend.Membership(conf).CreateAccountAndUser(rootEmail, "backup1234", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conf, model.Auth{AccountID: tok.AccountID, UserID: tok.ID, Email: tok.Email, Role: tok.Role}
+}
+
+func TestBackupRestore(t *testing.T) {
+	src, root := newBackupDatabase(t, "backupsrc", "root@backupsrc.com")
+
+	_, tok, err := backend.Membership(src).CreateAccountAndUser("user@backupsrc.com", "backup1234", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := model.Auth{AccountID: tok.AccountID, UserID: tok.ID, Email: tok.Email, Role: tok.Role}
+
+	if _, err := backend.DB.CreateDocument(root, src.Name, "notes", map[string]interface{}{"title": "root"}); err != nil {
+		t.Fatal(err)
+	} else if _, err := backend.DB.CreateDocument(user, src.Name, "notes", map[string]interface{}{"title": "user"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fn := model.ExecData{FunctionName: "backedup", TriggerTopic: "web", Code: "function handle() {}"}
+	if _, err := backend.DB.AddFunction(src.Name, fn); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := backend.BackupDatabase(src, "")
+	if err != nil {
+		t.Fatal(err)
+	} else if b.Documents != 2 || b.Size == 0 {
+		t.Fatalf("expected a backup of 2 documents got %v", b)
+	}
+
+	dst, _ := newBackupDatabase(t, "backupdst", "root@backupdst.com")
+
+	report, err := backend.RestoreBackup(dst, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if report.Users != 2 || report.Documents["notes"] != 2 || report.Functions != 1 || len(report.Errors) > 0 {
+		t.Fatalf("unexpected restore report %v", report)
+	}
+
+	restored, err := backend.DB.FindUserByEmail(dst.Name, "user@backupsrc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := model.Auth{AccountID: restored.AccountID, UserID: restored.ID, Role: restored.Role}
+	docs, err := backend.DB.ListDocuments(owner, dst.Name, "notes", model.ListParams{Page: 1, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	} else if docs.Total != 1 || docs.Results[0]["title"] != "user" {
+		t.Errorf("expected the user's note in its restored account got %v", docs.Results)
+	}
+
+	// restoring in the backed up database replaces its documents
+	if _, err := backend.DB.CreateDocument(root, src.Name, "notes", map[string]interface{}{"title": "after"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = backend.RestoreBackup(src, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if report.UsersSkipped != 2 || report.Documents["notes"] != 2 {
+		t.Fatalf("unexpected restore report %v", report)
+	}
+
+	n, err := backend.DB.Count(root, src.Name, "notes", nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Errorf("expected the 2 backed up notes got %d", n)
+	}
+
+	other := model.DatabaseConfig{TenantID: "other-tenant", Name: "otherdb"}
+	if _, err := backend.RestoreBackup(other, b.ID); !errors.Is(err, backend.ErrBackupNotFound) {
+		t.Errorf("expected the backup of another tenant to be not found got %v", err)
+	}
+
+	if err := backend.DeleteBackup(src, b.ID); err != nil {
+		t.Fatal(err)
+	} else if _, err := backend.RestoreBackup(src, b.ID); !errors.Is(err, backend.ErrBackupNotFound) {
+		t.Errorf("expected the deleted backup to be not found got %v", err)
+	}
+}
+
+func TestBackupTaskRotation(t *testing.T) {
+	conf, _ := newBackupDatabase(t, "backuptask", "root@backuptask.com")
+
+	task := model.Task{ID: "backup-task", Name: "backup", Type: model.TaskTypeBackup, Value: "2", BaseName: conf.Name}
+	for i := 0; i < 3; i++ {
+		if _, err := backend.RunBackupTask(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := backend.ListBackups(conf)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Errorf("expected the 2 newest backups to be kept got %d", len(list))
+	}
+
+	task.Value = "none"
+	if _, err := backend.RunBackupTask(task); err == nil {
+		t.Error("expected an error for an invalid number of backups kept")
+	}
+}
@@ -0,0 +1,173 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/quota"
+	"github.com/staticbackendhq/core/realtime"
+)
+
+// Plans are the subscription plans, see SetPlans
+var Plans []model.Plan
+
+// DefaultPlans returns the built-in plans with the configured Stripe prices
+// and the default quotas
+func DefaultPlans(cfg config.AppConfig) []model.Plan {
+	plans := []model.Plan{
+		{ID: model.PlanFree, Name: "Free"},
+		{ID: model.PlanIdea, Name: "Idea", PriceID: cfg.StripePriceIDIdea},
+		{ID: model.PleanLaunch, Name: "Launch", PriceID: cfg.StripePriceIDLaunch},
+		{ID: model.PlanTraction, Name: "Traction", PriceID: cfg.StripePriceIDTraction},
+		{ID: model.PlanGrowth, Name: "Growth", PriceID: cfg.StripePriceIDGrowth},
+	}
+
+	for i, p := range plans {
+		plans[i].Quotas = model.PlanQuotas{
+			RequestsPerMinute:         int(middleware.RateLimits[p.ID]),
+			StorageBytes:              quota.Limit(quota.Storage, p.ID),
+			MonthlyEmails:             int(quota.Limit(quota.Emails, p.ID)),
+			MonthlyFunctionRuns:       quota.Limit(quota.FunctionRuns, p.ID),
+			RealtimeConnections:       quota.Limit(quota.Connections, p.ID),
+			RealtimeMessagesPerSecond: realtime.MaxMessagesPerSecond[p.ID],
+		}
+	}
+	return plans
+}
+
+// LoadPlans reads the plans of a JSON file, each plan needs a unique ID
+func LoadPlans(path string) ([]model.Plan, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var plans []model.Plan
+	if err := json.Unmarshal(b, &plans); err != nil {
+		return nil, fmt.Errorf("invalid plans file: %w", err)
+	}
+
+	ids := make(map[int]bool)
+	prices := make(map[string]bool)
+	for _, p := range plans {
+		if ids[p.ID] {
+			return nil, fmt.Errorf("duplicate plan id %d", p.ID)
+		} else if len(p.PriceID) > 0 && prices[p.PriceID] {
+			return nil, fmt.Errorf("duplicate plan price %s", p.PriceID)
+		}
+		ids[p.ID] = true
+		prices[p.PriceID] = true
+	}
+
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("no plans defined in %s", path)
+	}
+	return plans, nil
+}
+
+// SetPlans replaces the plans and applies their quotas to the rate limits,
+// the realtime caps and the resource quotas
+func SetPlans(plans []model.Plan) {
+	Plans = plans
+
+	for _, p := range plans {
+		middleware.RateLimits[p.ID] = int64(p.Quotas.RequestsPerMinute)
+		quota.Limits[quota.Storage][p.ID] = p.Quotas.StorageBytes
+		quota.Limits[quota.Emails][p.ID] = int64(p.Quotas.MonthlyEmails)
+		quota.Limits[quota.FunctionRuns][p.ID] = p.Quotas.MonthlyFunctionRuns
+		quota.Limits[quota.Connections][p.ID] = p.Quotas.RealtimeConnections
+		realtime.MaxMessagesPerSecond[p.ID] = p.Quotas.RealtimeMessagesPerSecond
+	}
+}
+
+// SetRateLimits applies the requests per minute of the plans by name or ID
+// and the factors of the route classes, the configuration file can change
+// them while the server is running
+func SetRateLimits(rpm map[string]int64, factors map[string]float64) error {
+	limits := make(map[int]int64)
+	for name, n := range rpm {
+		p, ok := findPlanByName(name)
+		if !ok {
+			return fmt.Errorf("unknown plan %s in the rate limits", name)
+		}
+		limits[p.ID] = n
+	}
+
+	plans := make([]model.Plan, len(Plans))
+	copy(plans, Plans)
+	for i, p := range plans {
+		if n, ok := limits[p.ID]; ok {
+			plans[i].Quotas.RequestsPerMinute = int(n)
+		}
+	}
+
+	middleware.SetRateLimits(limits, factors)
+	Plans = plans
+	return nil
+}
+
+// findPlanByName returns a plan by its name, case-insensitive, or its ID
+func findPlanByName(name string) (model.Plan, bool) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return FindPlan(id)
+	}
+
+	for _, p := range Plans {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return model.Plan{}, false
+}
+
+// FindPlan returns a plan by its ID
+func FindPlan(id int) (model.Plan, bool) {
+	for _, p := range Plans {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return model.Plan{}, false
+}
+
+// PlanByPrice returns the plan of a Stripe price
+func PlanByPrice(priceID string) (model.Plan, bool) {
+	for _, p := range Plans {
+		if len(p.PriceID) > 0 && p.PriceID == priceID {
+			return p, true
+		}
+	}
+	return model.Plan{}, false
+}
+
+// ChangePlan changes the plan of a tenant, the quotas of the new plan apply
+// to the next requests of its databases on upgrade, downgrade and
+// cancellation
+func ChangePlan(tenantID string, plan int) error {
+	cus, err := DB.FindTenant(tenantID)
+	if err != nil {
+		return err
+	}
+
+	if err := DB.ChangeTenantPlan(tenantID, plan); err != nil {
+		return err
+	}
+
+	if cus.Plan != plan {
+		RecordAdminEvent(model.AdminEvent{
+			Type:     model.AdminPlanChanged,
+			Actor:    "billing",
+			TenantID: tenantID,
+			Target:   cus.Email,
+			Detail:   fmt.Sprintf("plan %d to %d", cus.Plan, plan),
+		})
+	}
+
+	return middleware.CacheTenantPlan(Cache, tenantID, plan)
+}
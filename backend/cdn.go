@@ -0,0 +1,73 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/storage"
+)
+
+// DefaultFileCacheControl is used when neither the database settings nor
+// the config set the Cache-Control of files
+const DefaultFileCacheControl = "public, max-age=86400"
+
+// cacheControl returns the Cache-Control header of the database's files
+func cacheControl(conf model.DatabaseConfig) string {
+	if len(conf.Settings.Files.CacheControl) > 0 {
+		return conf.Settings.Files.CacheControl
+	} else if len(Config.FileCacheControl) > 0 {
+		return Config.FileCacheControl
+	}
+	return DefaultFileCacheControl
+}
+
+// FileCacheControl returns the Cache-Control header of a file from the
+// settings of the database owning it, file keys start with the database name
+func FileCacheControl(fileKey string) string {
+	dbName, _, _ := strings.Cut(strings.TrimPrefix(fileKey, "/"), "/")
+
+	conf, err := findDatabaseByName(dbName)
+	if err != nil {
+		return cacheControl(model.DatabaseConfig{})
+	}
+	return cacheControl(conf)
+}
+
+// FileETag returns the entity tag of a file. Each upload has a unique key,
+// the content of a key never changes.
+func FileETag(fileKey string) string {
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(fileKey, "/")))
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// cdnURL returns the URL of a file key via the database's CDN if set
+func (f FileStore) cdnURL(fileKey, url string) string {
+	cdn := strings.TrimSuffix(f.conf.Settings.Files.CDNURL, "/")
+	if len(cdn) == 0 {
+		return url
+	}
+	return fmt.Sprintf("%s/%s", cdn, fileKey)
+}
+
+// withCDN rewrites the URLs of a file and its variants, private files
+// are not served by the CDN
+func (f FileStore) withCDN(file model.File) model.File {
+	if storage.IsPrivate(file.Key) {
+		return file
+	}
+
+	file.URL = f.cdnURL(file.Key, file.URL)
+
+	if len(file.Variants) > 0 {
+		variants := make([]model.FileVariant, len(file.Variants))
+		for i, v := range file.Variants {
+			v.URL = f.cdnURL(v.Key, v.URL)
+			variants[i] = v
+		}
+		file.Variants = variants
+	}
+	return file
+}
@@ -0,0 +1,41 @@
+package backend_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestFileCDNURL(t *testing.T) {
+	conf := base
+	conf.Settings.Files.CDNURL = "https://cdn.test.com/"
+
+	fs := backend.Storage(adminAuth, conf)
+
+	sf, err := fs.Save("cdn.txt", "", strings.NewReader("via cdn"), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Delete(sf.ID)
+
+	if !strings.HasPrefix(sf.URL, "https://cdn.test.com/"+base.Name+"/") {
+		t.Errorf("expected the CDN URL got %s", sf.URL)
+	}
+
+	files, err := fs.List(model.FileFilter{Name: "cdn.txt"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 || files[0].URL != sf.URL {
+		t.Errorf("expected the listed file to use the CDN URL got %v", files)
+	}
+
+	// the stored URL is kept so the CDN can be changed later
+	file, err := backend.DB.GetFileByID(base.Name, sf.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if strings.HasPrefix(file.URL, "https://cdn.test.com") {
+		t.Errorf("expected the storage URL to be stored got %s", file.URL)
+	}
+}
@@ -0,0 +1,370 @@
+package backend
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+// DeletionGracePeriod is how long a confirmed deletion can be cancelled
+// before the database is purged
+var DeletionGracePeriod = 7 * 24 * time.Hour
+
+const (
+	// DeletionConfirmTTL is how long the confirmation token of a deletion
+	// request is valid
+	DeletionConfirmTTL = time.Hour
+	// AppDeletionInterval is how often the primary instance purges the
+	// databases whose grace period ended
+	AppDeletionInterval = time.Hour
+)
+
+var (
+	// ErrDeletionPending is returned when requesting the deletion of a
+	// database already scheduled for deletion
+	ErrDeletionPending = errors.New("the database is already scheduled for deletion")
+	// ErrNoDeletionPending is returned when cancelling the deletion of a
+	// database not scheduled for deletion
+	ErrNoDeletionPending = errors.New("the database is not scheduled for deletion")
+	// ErrInvalidDeletionToken is returned when confirming a deletion with
+	// a wrong or expired token
+	ErrInvalidDeletionToken = errors.New("invalid or expired confirmation token")
+)
+
+func deletionTokenKey(baseID string) string {
+	return "appdel-" + baseID
+}
+
+// RequestAppDeletion starts the deletion of a database, a confirmation
+// token valid for DeletionConfirmTTL is emailed to its tenant
+func RequestAppDeletion(conf model.DatabaseConfig) error {
+	if pending, err := DB.GetAppDeletion(conf.ID); err != nil {
+		return err
+	} else if len(pending.ID) > 0 {
+		return ErrDeletionPending
+	}
+
+	cus, err := DB.FindTenant(conf.TenantID)
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(b)
+
+	key := deletionTokenKey(conf.ID)
+	if err := Cache.Set(key, token); err != nil {
+		return err
+	} else if err := Cache.Expire(key, DeletionConfirmTTL); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`
+	<p>Hello,</p>
+	<p>We received a request to delete the database %s and all its data.</p>
+	<p>To confirm, use this token within the next hour: <strong>%s</strong></p>
+	<p>The database will be purged %d days after the confirmation, you can
+	cancel the deletion until then.</p>
+	<p>If you did not request this deletion, you can ignore this email.</p>
+	`, conf.Name, token, int(DeletionGracePeriod.Hours()/24))
+
+	mail := email.SendMailData{
+		From:     Config.FromEmail,
+		FromName: Config.FromName,
+		To:       cus.Email,
+		Subject:  "Confirm the deletion of your database",
+		HTMLBody: body,
+		TextBody: email.StripHTML(body),
+	}
+	return Emailer.Send(mail)
+}
+
+// ConfirmAppDeletion validates the confirmation token of a deletion request
+// and schedules the purge of the database once the grace period ends
+func ConfirmAppDeletion(conf model.DatabaseConfig, token string) (model.AppDeletion, error) {
+	key := deletionTokenKey(conf.ID)
+
+	expected, err := Cache.Get(key)
+	if err != nil || len(expected) == 0 || len(token) == 0 ||
+		subtle.ConstantTimeCompare([]byte(expected), []byte(token)) != 1 {
+		return model.AppDeletion{}, ErrInvalidDeletionToken
+	}
+
+	if pending, err := DB.GetAppDeletion(conf.ID); err != nil {
+		return pending, err
+	} else if len(pending.ID) > 0 {
+		return pending, ErrDeletionPending
+	}
+
+	cus, err := DB.FindTenant(conf.TenantID)
+	if err != nil {
+		return model.AppDeletion{}, err
+	}
+
+	now := time.Now()
+	d := model.AppDeletion{
+		BaseID:    conf.ID,
+		TenantID:  conf.TenantID,
+		DBName:    conf.Name,
+		Email:     cus.Email,
+		Status:    model.AppDeletionPending,
+		Requested: now,
+		Scheduled: now.Add(DeletionGracePeriod),
+	}
+
+	d.ID, err = DB.AddAppDeletion(d)
+	if err != nil {
+		return d, err
+	}
+
+	// the token is single use
+	if err := Cache.Expire(key, 0); err != nil {
+		Log.Warn().Err(err).Msgf("cannot expire the deletion token of %s", conf.Name)
+	}
+	return d, nil
+}
+
+// CancelAppDeletion cancels the scheduled deletion of a database during
+// its grace period
+func CancelAppDeletion(conf model.DatabaseConfig) error {
+	pending, err := DB.GetAppDeletion(conf.ID)
+	if err != nil {
+		return err
+	} else if len(pending.ID) == 0 {
+		return ErrNoDeletionPending
+	}
+
+	return DB.CancelAppDeletion(pending.ID)
+}
+
+// ProcessAppDeletions purges the databases whose grace period ended and
+// returns the number of databases purged
+func ProcessAppDeletions() (int, error) {
+	due, err := DB.ListDueAppDeletions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, d := range due {
+		if _, err := PurgeDatabase(d); err != nil {
+			Log.Error().Err(err).Msgf("cannot purge the database %s", d.DBName)
+			continue
+		}
+
+		purged++
+	}
+	return purged, nil
+}
+
+// PurgeDatabase removes all the data of a scheduled deletion: its files,
+// backups, tasks, realtime state and the database itself with its documents,
+// functions and forms. The signed certificate of the deletion is saved
+// and emailed to the tenant.
+func PurgeDatabase(d model.AppDeletion) (model.DeletionCertificate, error) {
+	cert := model.DeletionCertificate{
+		DeletionID: d.ID,
+		BaseID:     d.BaseID,
+		TenantID:   d.TenantID,
+		DBName:     d.DBName,
+		Requested:  d.Requested,
+	}
+
+	cols, err := DB.ListCollections(d.DBName)
+	if err != nil {
+		return cert, err
+	}
+	cert.Collections = len(cols)
+
+	fns, err := DB.ListFunctions(d.DBName)
+	if err != nil {
+		return cert, err
+	}
+	cert.Functions = len(fns)
+
+	forms, err := DB.GetForms(d.DBName)
+	if err != nil {
+		return cert, err
+	}
+	cert.Forms = len(forms)
+
+	tasks, err := DB.ListTasksByBase(d.DBName)
+	if err != nil {
+		return cert, err
+	}
+	cert.Tasks = len(tasks)
+
+	for _, task := range tasks {
+		if Scheduler == nil {
+			break
+		} else if err := Scheduler.CancelTask(task.ID); err != nil {
+			Log.Warn().Err(err).Msgf("error removing the task %s of a deleted database", task.ID)
+		}
+	}
+
+	files, err := DB.ListAllFiles(d.DBName, "")
+	if err != nil {
+		return cert, err
+	}
+
+	for _, f := range files {
+		if err := Filestore.Delete(f.Key); err != nil {
+			return cert, err
+		}
+
+		for _, v := range f.Variants {
+			if err := Filestore.Delete(v.Key); err != nil {
+				return cert, err
+			}
+		}
+
+		cert.Files++
+	}
+
+	backups, err := DB.ListBackups(d.DBName)
+	if err != nil {
+		return cert, err
+	}
+
+	for _, b := range backups {
+		if err := removeBackup(b); err != nil {
+			return cert, err
+		}
+	}
+
+	secrets, err := ListSecrets(model.DatabaseConfig{Name: d.DBName})
+	if err != nil {
+		return cert, err
+	}
+
+	for _, s := range secrets {
+		if err := DB.DeleteSecret(d.DBName, s.Name); err != nil {
+			return cert, err
+		}
+	}
+
+	domains, err := DB.ListDomains(d.DBName)
+	if err != nil {
+		return cert, err
+	}
+
+	for _, domain := range domains {
+		if err := DB.DeleteDomain(d.DBName, domain.Hostname); err != nil {
+			return cert, err
+		}
+
+		Cache.Expire(model.DomainKey(domain.Hostname), 0)
+	}
+
+	if Search != nil {
+		if err := Search.DeleteIndex(d.DBName); err != nil {
+			return cert, err
+		}
+	}
+
+	if err := DB.DeleteDatabase(d.BaseID); err != nil {
+		return cert, err
+	}
+
+	if cert.Channels, err = cache.ClearChannels(Cache, d.DBName); err != nil {
+		return cert, err
+	}
+
+	// the cached config and root token of the database
+	for _, key := range []string{d.BaseID, "dbid:" + d.DBName, "root:" + d.DBName} {
+		if err := Cache.Expire(key, 0); err != nil {
+			return cert, err
+		}
+	}
+
+	cert.Completed = time.Now()
+	cert.Signature = signDeletionCertificate(cert)
+
+	b, err := json.Marshal(cert)
+	if err != nil {
+		return cert, err
+	}
+
+	if err := DB.CompleteAppDeletion(d.ID, cert.Completed, string(b)); err != nil {
+		return cert, err
+	}
+
+	sendDeletionCertificate(d, string(b))
+	return cert, nil
+}
+
+// VerifyDeletionCertificate returns whether a certificate was signed by
+// this instance and was not altered
+func VerifyDeletionCertificate(cert model.DeletionCertificate) bool {
+	return hmac.Equal([]byte(cert.Signature), []byte(signDeletionCertificate(cert)))
+}
+
+// signDeletionCertificate returns the HMAC of the certificate's fields
+func signDeletionCertificate(cert model.DeletionCertificate) string {
+	cert.Signature = ""
+
+	b, err := json.Marshal(cert)
+	if err != nil {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, []byte(Config.AppSecret))
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendDeletionCertificate emails the certificate of a purged database to
+// its tenant
+func sendDeletionCertificate(d model.AppDeletion, cert string) {
+	body := fmt.Sprintf(`
+	<p>Hello,</p>
+	<p>The database %s and all its data were deleted.</p>
+	<p>This is the signed certificate of the deletion, keep it for your
+	records:</p>
+	<pre>%s</pre>
+	`, d.DBName, cert)
+
+	mail := email.SendMailData{
+		From:     Config.FromEmail,
+		FromName: Config.FromName,
+		To:       d.Email,
+		Subject:  "Your database was deleted",
+		HTMLBody: body,
+		TextBody: email.StripHTML(body),
+	}
+	if err := Emailer.Send(mail); err != nil {
+		Log.Error().Err(err).Msgf("cannot send the deletion certificate of %s", d.DBName)
+	}
+}
+
+// processAppDeletionsEvery purges the due deletions at each interval
+func processAppDeletionsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if _, err := ProcessAppDeletions(); err != nil {
+			Log.Error().Err(err).Msg("error processing the app deletions")
+		}
+	}
+}
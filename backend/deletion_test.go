@@ -0,0 +1,96 @@
+package backend_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAppDeletion(t *testing.T) {
+	conf, err := backend.DB.CreateDatabase(model.DatabaseConfig{
+		ID:       backend.DB.NewID(),
+		TenantID: base.TenantID,
+		Name:     "purgetest",
+		IsActive: true,
+		Created:  time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sf, err := backend.Storage(adminAuth, conf).Save("purged.txt", "", strings.NewReader("purged"), 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := backend.DB.GetFileByID(conf.Name, sf.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.RequestAppDeletion(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ConfirmAppDeletion(conf, "wrong"); !errors.Is(err, backend.ErrInvalidDeletionToken) {
+		t.Fatalf("expected an invalid token got %v", err)
+	}
+
+	token, err := backend.Cache.Get("appdel-" + conf.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the database is purged right away
+	grace := backend.DeletionGracePeriod
+	backend.DeletionGracePeriod = 0
+	defer func() { backend.DeletionGracePeriod = grace }()
+
+	d, err := backend.ConfirmAppDeletion(conf, token)
+	if err != nil {
+		t.Fatal(err)
+	} else if d.DBName != conf.Name || d.Status != model.AppDeletionPending {
+		t.Fatalf("expected a pending deletion of %s got %v", conf.Name, d)
+	}
+
+	if err := backend.RequestAppDeletion(conf); !errors.Is(err, backend.ErrDeletionPending) {
+		t.Errorf("expected the deletion to be pending got %v", err)
+	}
+
+	cert, err := backend.PurgeDatabase(d)
+	if err != nil {
+		t.Fatal(err)
+	} else if cert.Files != 1 || cert.DBName != conf.Name {
+		t.Errorf("expected the certificate of 1 file removed got %v", cert)
+	}
+
+	if !backend.VerifyDeletionCertificate(cert) {
+		t.Error("expected the certificate signature to be valid")
+	}
+
+	tampered := cert
+	tampered.Files = 0
+	if backend.VerifyDeletionCertificate(tampered) {
+		t.Error("expected the altered certificate to be invalid")
+	}
+
+	if exists, err := backend.DB.DatabaseExists(conf.Name); err != nil {
+		t.Fatal(err)
+	} else if exists {
+		t.Error("expected the database to be deleted")
+	}
+
+	if _, err := backend.Filestore.Open(file.Key); err == nil {
+		t.Error("expected the file to be deleted")
+	}
+
+	if n, err := backend.ProcessAppDeletions(); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Errorf("expected no more due deletion got %d", n)
+	}
+}
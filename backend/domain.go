@@ -0,0 +1,216 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/model"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+var (
+	// ErrInvalidDomain is returned when adding a custom domain which is not
+	// a valid hostname or is the instance's hostname
+	ErrInvalidDomain = errors.New("invalid domain, use a hostname like api.example.com")
+	// ErrDomainTaken is returned when adding a custom domain already added
+	// by an app
+	ErrDomainTaken = errors.New("this domain has already been added")
+	// ErrDomainNotFound is returned when handling a custom domain the app
+	// did not add
+	ErrDomainNotFound = errors.New("domain not found")
+	// ErrDomainNotVerified is returned when the verification TXT record of
+	// a custom domain does not contain its token
+	ErrDomainNotVerified = errors.New("the verification TXT record was not found")
+)
+
+var hostname = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// LookupTXT returns the TXT records of a hostname, it's replaced in tests
+var LookupTXT = net.LookupTXT
+
+// AppHost returns the hostname of the instance's AppURL
+func AppHost() string {
+	u, err := url.Parse(Config.AppURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// AddDomain adds a custom domain to an app, it's served once verified with
+// a TXT record containing its token
+func AddDomain(conf model.DatabaseConfig, host string) (model.Domain, error) {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if !hostname.MatchString(host) || host == AppHost() {
+		return model.Domain{}, ErrInvalidDomain
+	}
+
+	if _, err := DB.FindDomain(host); err == nil {
+		return model.Domain{}, ErrDomainTaken
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return model.Domain{}, err
+	}
+
+	d := model.Domain{
+		DBName:   conf.Name,
+		BaseID:   conf.ID,
+		Hostname: host,
+		Token:    hex.EncodeToString(b),
+		Created:  time.Now(),
+	}
+
+	id, err := DB.AddDomain(d)
+	if err != nil {
+		return model.Domain{}, err
+	}
+	d.ID = id
+
+	// the host might have been cached as unknown
+	Cache.Expire(model.DomainKey(host), 0)
+	return d, nil
+}
+
+// ListDomains returns the custom domains of an app
+func ListDomains(conf model.DatabaseConfig) ([]model.Domain, error) {
+	return DB.ListDomains(conf.Name)
+}
+
+// VerifyDomain verifies a custom domain of an app when its verification TXT
+// record contains its token
+func VerifyDomain(conf model.DatabaseConfig, host string) (model.Domain, error) {
+	d, err := findDomain(conf, host)
+	if err != nil || d.Verified {
+		return d, err
+	}
+
+	records, err := LookupTXT(d.VerificationRecord())
+	if err != nil {
+		return d, ErrDomainNotVerified
+	}
+
+	for _, record := range records {
+		if strings.TrimSpace(record) != d.Token {
+			continue
+		}
+
+		if err := DB.VerifyDomain(d.ID); err != nil {
+			return d, err
+		}
+
+		d.Verified = true
+
+		Cache.Expire(model.DomainKey(d.Hostname), 0)
+		return d, nil
+	}
+	return d, ErrDomainNotVerified
+}
+
+// DeleteDomain removes a custom domain of an app, it stops being served
+func DeleteDomain(conf model.DatabaseConfig, host string) error {
+	d, err := findDomain(conf, host)
+	if err != nil {
+		return err
+	}
+
+	if err := DB.DeleteDomain(conf.Name, d.Hostname); err != nil {
+		return err
+	}
+
+	Cache.Expire(model.DomainKey(d.Hostname), 0)
+	return nil
+}
+
+// findDomain returns a custom domain of an app
+func findDomain(conf model.DatabaseConfig, host string) (model.Domain, error) {
+	d, err := DB.FindDomain(strings.ToLower(host))
+	if err != nil || d.DBName != conf.Name {
+		return model.Domain{}, ErrDomainNotFound
+	}
+	return d, nil
+}
+
+// NewCertManager returns the manager obtaining the certificates of the
+// verified custom domains via ACME. They're kept in the ACMECacheDir or
+// without expiry in the volatile cache shared by the instances.
+func NewCertManager(cfg config.AppConfig) *autocert.Manager {
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      certCache{},
+		HostPolicy: allowCertificate,
+		Email:      cfg.ACMEEmail,
+	}
+
+	if len(cfg.ACMECacheDir) > 0 {
+		m.Cache = autocert.DirCache(cfg.ACMECacheDir)
+	}
+	if len(cfg.ACMEDirectoryURL) > 0 {
+		m.Client = &acme.Client{DirectoryURL: cfg.ACMEDirectoryURL}
+	}
+	return m
+}
+
+// allowCertificate only lets the manager obtain the certificates of the
+// verified custom domains
+func allowCertificate(ctx context.Context, host string) error {
+	d, err := DB.FindDomain(host)
+	if err != nil || !d.Verified {
+		return fmt.Errorf("%s is not a verified custom domain", host)
+	}
+	return nil
+}
+
+// certCacheKey is the hash holding the ACME account key and certificates,
+// a hash has no expiry unlike the values set via Cache.Set
+const certCacheKey = "acme"
+
+// certCache keeps the ACME account key and certificates in the volatile
+// cache without expiring them
+type certCache struct{}
+
+func (certCache) Get(ctx context.Context, name string) ([]byte, error) {
+	certs, err := Cache.HGetAll(certCacheKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, ok := certs[name]; ok {
+		return []byte(data), nil
+	}
+
+	// the entries stored before they were kept in the hash expire, they're
+	// moved to it to avoid requesting them again
+	s, err := Cache.Get(certCacheKey + ":" + name)
+	if err != nil || len(s) == 0 {
+		return nil, autocert.ErrCacheMiss
+	}
+
+	if err := Cache.HSet(certCacheKey, name, s); err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+func (certCache) Put(ctx context.Context, name string, data []byte) error {
+	return Cache.HSet(certCacheKey, name, string(data))
+}
+
+func (certCache) Delete(ctx context.Context, name string) error {
+	if err := Cache.HDel(certCacheKey, name); err != nil {
+		return err
+	}
+	return Cache.Expire(certCacheKey+":"+name, 0)
+}
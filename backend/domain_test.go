@@ -0,0 +1,74 @@
+package backend_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+)
+
+// ttlCache expires the values set via Set like the Redis cache does
+type ttlCache struct {
+	*cache.CacheDev
+	ttl time.Duration
+}
+
+func (c ttlCache) Set(key, value string) error {
+	if err := c.CacheDev.Set(key, value); err != nil {
+		return err
+	}
+	return c.Expire(key, c.ttl)
+}
+
+func TestCertCacheNoExpiry(t *testing.T) {
+	dev := cache.NewDevCache(backend.Log)
+
+	prev := backend.Cache
+	backend.Cache = ttlCache{CacheDev: dev, ttl: 10 * time.Millisecond}
+	defer func() { backend.Cache = prev }()
+
+	certs := backend.NewCertManager(config.AppConfig{}).Cache
+	ctx := context.Background()
+
+	if err := certs.Put(ctx, "acme_account+key", []byte("account key")); err != nil {
+		t.Fatal(err)
+	} else if err := certs.Put(ctx, "app.example.com", []byte("certificate")); err != nil {
+		t.Fatal(err)
+	}
+
+	// an entry stored before the certificates were kept without expiry
+	if err := dev.Set("acme:legacy.example.com", "legacy certificate"); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := certs.Get(ctx, "legacy.example.com"); err != nil {
+		t.Fatal(err)
+	} else if string(data) != "legacy certificate" {
+		t.Errorf("expected the legacy certificate got %s", data)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	expected := map[string]string{
+		"acme_account+key":   "account key",
+		"app.example.com":    "certificate",
+		"legacy.example.com": "legacy certificate",
+	}
+	for name, want := range expected {
+		data, err := certs.Get(ctx, name)
+		if err != nil {
+			t.Errorf("expected %s to survive the cache TTL: %v", name, err)
+		} else if string(data) != want {
+			t.Errorf("expected %s got %s", want, data)
+		}
+	}
+
+	if err := certs.Delete(ctx, "app.example.com"); err != nil {
+		t.Fatal(err)
+	} else if _, err := certs.Get(ctx, "app.example.com"); err == nil {
+		t.Error("expected the deleted certificate to be missing")
+	}
+}
@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+// resolveAttachments reads the content of the attachments referencing a
+// stored file by its ID and checks the attachments' limits. The file's name
+// and content type are used when not set.
+func resolveAttachments(conf model.DatabaseConfig, attachments []model.EmailAttachment) ([]model.EmailAttachment, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	} else if len(attachments) > email.MaxAttachments {
+		return nil, fmt.Errorf("%w: at most %d attachments", email.ErrInvalidAttachment, email.MaxAttachments)
+	}
+
+	resolved := make([]model.EmailAttachment, len(attachments))
+	for i, a := range attachments {
+		if len(a.FileID) > 0 {
+			file, err := DB.GetFileByID(conf.Name, a.FileID)
+			if err != nil {
+				return nil, fmt.Errorf("%w: file %s not found", email.ErrInvalidAttachment, a.FileID)
+			} else if file.Size > email.MaxAttachmentsSize {
+				return nil, fmt.Errorf("%w: the attachments exceed %d bytes", email.ErrInvalidAttachment, email.MaxAttachmentsSize)
+			}
+
+			content, err := readAttachment(file.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			a.Content = content
+			if len(a.Filename) == 0 {
+				a.Filename = file.Name
+			}
+			if len(a.Filename) == 0 {
+				a.Filename = path.Base(file.Key)
+			}
+			if len(a.ContentType) == 0 {
+				a.ContentType = file.MimeType
+			}
+		}
+
+		resolved[i] = a
+	}
+
+	if err := email.ValidateAttachments(resolved); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// readAttachment returns the content of a stored file, at most one byte
+// over the attachments' size limit
+func readAttachment(fileKey string) ([]byte, error) {
+	r, err := Filestore.Open(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, email.MaxAttachmentsSize+1))
+}
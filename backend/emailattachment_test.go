@@ -0,0 +1,72 @@
+package backend_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestEmailAttachments(t *testing.T) {
+	fs := backend.Storage(adminAuth, base)
+
+	sf, err := fs.Save("report.txt", "", strings.NewReader("monthly report"), 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Delete(sf.ID)
+
+	emailer := backend.Emailer
+	defer func() { backend.Emailer = emailer }()
+
+	mailer := &recordingMailer{}
+	backend.Emailer = mailer
+
+	data := email.SendMailData{
+		From:     "app@domain.com",
+		To:       "attachments@domain.com",
+		Subject:  "Attached",
+		HTMLBody: "<p>Report</p>",
+		Attachments: []model.EmailAttachment{
+			{FileID: sf.ID},
+			{Filename: "inline.csv", ContentType: "text/csv", Content: []byte("a,b")},
+		},
+	}
+
+	if _, err := backend.QueueEmail(base, data); err != nil {
+		t.Fatal(err)
+	} else if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	var sent []model.EmailAttachment
+	for _, m := range mailer.sent {
+		if m.To == data.To {
+			sent = m.Attachments
+		}
+	}
+
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 attachments got %v", sent)
+	} else if sent[0].Filename != "report.txt" || string(sent[0].Content) != "monthly report" {
+		t.Errorf("expected the stored file to be attached got %v", sent[0])
+	} else if sent[1].Filename != "inline.csv" || string(sent[1].Content) != "a,b" {
+		t.Errorf("expected the inline attachment got %v", sent[1])
+	}
+
+	data.Attachments = []model.EmailAttachment{{FileID: "not-a-file"}}
+	if _, err := backend.QueueEmail(base, data); !errors.Is(err, email.ErrInvalidAttachment) {
+		t.Errorf("expected ErrInvalidAttachment for an unknown file got %v", err)
+	}
+
+	data.Attachments = make([]model.EmailAttachment, email.MaxAttachments+1)
+	if _, err := backend.QueueEmail(base, data); !errors.Is(err, email.ErrInvalidAttachment) {
+		t.Errorf("expected ErrInvalidAttachment for too many attachments got %v", err)
+	}
+}
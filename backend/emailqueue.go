@@ -0,0 +1,215 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+const (
+	// EmailMaxAttempts is the number of deliveries of a queued email before
+	// it is marked as failed
+	EmailMaxAttempts = 5
+	// EmailQueueInterval is how often the primary instance sends the due
+	// emails of the queue
+	EmailQueueInterval = 10 * time.Second
+	// emailBatchSize is the maximum number of emails sent per database on
+	// each pass
+	emailBatchSize = 50
+)
+
+var (
+	// emailQueueMu prevents an email from being sent by concurrent passes
+	emailQueueMu sync.Mutex
+	// emailQueued wakes up the queue when an email is queued on this instance
+	emailQueued = make(chan struct{}, 1)
+)
+
+// QueueEmail persists an email sent asynchronously with the database's
+// mailer and returns its ID to follow its delivery status. A failed
+// delivery is retried with an exponential backoff. ErrEmailQuotaExceeded is
+// returned when the tenant sent its monthly emails and
+// email.ErrInvalidAttachment when the attachments are invalid.
+func QueueEmail(conf model.DatabaseConfig, data email.SendMailData) (string, error) {
+	if err := checkEmailQuota(conf); err != nil {
+		return "", err
+	}
+
+	// the attached files are read now, they may be deleted before delivery
+	attachments, err := resolveAttachments(conf, data.Attachments)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data.TextBody) == 0 && len(data.HTMLBody) > 0 {
+		data.TextBody = email.StripHTML(data.HTMLBody)
+	} else if len(data.HTMLBody) == 0 && len(data.TextBody) > 0 {
+		data.HTMLBody = data.TextBody
+	}
+
+	now := time.Now()
+	msg := model.EmailMessage{
+		From:        data.From,
+		FromName:    data.FromName,
+		To:          data.To,
+		ToName:      data.ToName,
+		ReplyTo:     data.ReplyTo,
+		Subject:     data.Subject,
+		Template:    data.Template,
+		HTMLBody:    data.HTMLBody,
+		TextBody:    data.TextBody,
+		Attachments: attachments,
+		Status:      model.EmailStatusQueued,
+		NextAttempt: now,
+		Created:     now,
+		Updated:     now,
+	}
+
+	id, err := DB.QueueEmail(conf.Name, msg)
+	if err != nil {
+		return "", err
+	}
+
+	select {
+	case emailQueued <- struct{}{}:
+	default:
+	}
+	return id, nil
+}
+
+// QueuedMailer returns a mailer queuing the emails of a database instead of
+// sending them directly
+func QueuedMailer(conf model.DatabaseConfig) email.Mailer {
+	return queuedMailer{conf: conf}
+}
+
+type queuedMailer struct {
+	conf model.DatabaseConfig
+}
+
+func (m queuedMailer) Send(data email.SendMailData) error {
+	_, err := QueueEmail(m.conf, data)
+	return err
+}
+
+// ProcessEmailQueue sends the due emails of all databases and returns the
+// number of emails sent
+func ProcessEmailQueue() (int, error) {
+	emailQueueMu.Lock()
+	defer emailQueueMu.Unlock()
+
+	bases, err := DB.ListDatabases()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, conf := range bases {
+		// the queue of a suspended database is kept until it's reactivated
+		if !conf.IsActive || conf.IsSuspended() {
+			continue
+		}
+
+		due, err := DB.ListDueEmails(conf.Name, time.Now(), emailBatchSize)
+		if err != nil {
+			Log.Error().Err(err).Msgf("cannot list the queued emails of %s", conf.Name)
+			continue
+		}
+
+		for _, msg := range due {
+			if deliverEmail(conf, msg) {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// deliverEmail sends a queued email and records the outcome, a failed
+// delivery is retried later unless the provider rejected the email or it
+// was the last attempt. The emails to suppressed addresses fail.
+func deliverEmail(conf model.DatabaseConfig, msg model.EmailMessage) bool {
+	data := email.SendMailData{
+		From:     msg.From,
+		FromName: msg.FromName,
+		To:       msg.To,
+		ToName:   msg.ToName,
+		ReplyTo:  msg.ReplyTo,
+		Subject:  msg.Subject,
+		Template: msg.Template,
+		HTMLBody: msg.HTMLBody,
+		TextBody: msg.TextBody,
+
+		Attachments: msg.Attachments,
+	}
+
+	attempts := msg.Attempts + 1
+	status, lastError, next := model.EmailStatusSent, "", msg.NextAttempt
+
+	var providerID string
+	var err error
+	if suppressed, serr := DB.IsEmailSuppressed(conf.Name, msg.To); serr != nil {
+		Log.Error().Err(serr).Msgf("cannot check if %s is suppressed", msg.To)
+		return false
+	} else if suppressed {
+		err = ErrEmailSuppressed
+	} else {
+		providerID, err = email.Send(Mailer(conf), data)
+	}
+
+	if err != nil {
+		lastError = err.Error()
+		if errors.Is(err, ErrEmailSuppressed) || errors.Is(err, email.ErrRejected) || attempts >= EmailMaxAttempts {
+			status = model.EmailStatusFailed
+		} else {
+			status = model.EmailStatusQueued
+			next = time.Now().Add(EmailRetryDelay(attempts))
+		}
+
+		Log.Warn().Err(err).Msgf("email %s of %s not sent on attempt %d", msg.ID, conf.Name, attempts)
+	}
+
+	if err := DB.UpdateEmailStatus(conf.Name, msg.ID, status, attempts, lastError, providerID, next); err != nil {
+		Log.Error().Err(err).Msgf("cannot update the status of email %s", msg.ID)
+	}
+
+	if status != model.EmailStatusSent {
+		return false
+	}
+
+	countEmailSent(conf)
+	return true
+}
+
+// EmailRetryDelay returns the delay before the next delivery after a
+// number of failed attempts: 1, 4, 16 and 64 minutes
+func EmailRetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return time.Minute << (2 * (attempts - 1))
+}
+
+// processEmailQueueEvery sends the due emails at each interval or as soon
+// as an email is queued on this instance
+func processEmailQueueEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-emailQueued:
+		case <-ctx.Done():
+			return
+		}
+
+		if _, err := ProcessEmailQueue(); err != nil {
+			Log.Error().Err(err).Msg("error processing the email queue")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package backend_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+type failingMailer struct {
+	err error
+}
+
+func (m failingMailer) Send(data email.SendMailData) error {
+	return m.err
+}
+
+func TestEmailQueueRetry(t *testing.T) {
+	emailer := backend.Emailer
+	defer func() { backend.Emailer = emailer }()
+
+	backend.Emailer = failingMailer{err: email.ErrUnavailable}
+
+	data := email.SendMailData{From: "app@domain.com", To: "user@domain.com", Subject: "Queued", HTMLBody: "<p>Hi</p>"}
+	id, err := backend.QueueEmail(base, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := backend.DB.GetEmailMessage(base.Name, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if msg.Status != model.EmailStatusQueued || msg.Attempts != 1 || len(msg.LastError) == 0 {
+		t.Fatalf("expected a failed attempt to be retried got %v", msg)
+	} else if msg.NextAttempt.Before(time.Now().Add(backend.EmailRetryDelay(1) / 2)) {
+		t.Errorf("expected the next attempt to be delayed got %v", msg.NextAttempt)
+	}
+
+	// a rejected email is not retried
+	backend.Emailer = failingMailer{err: &email.ProviderError{Provider: "test", Err: email.ErrRejected}}
+	past := time.Now().Add(-time.Second)
+	if err := backend.DB.UpdateEmailStatus(base.Name, id, msg.Status, msg.Attempts, msg.LastError, "", past); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err = backend.DB.GetEmailMessage(base.Name, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if msg.Status != model.EmailStatusFailed || msg.Attempts != 2 {
+		t.Errorf("expected the rejected email to fail got %v", msg)
+	}
+
+	backend.Emailer = emailer
+
+	id, err = backend.QueueEmail(base, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg, err := backend.DB.GetEmailMessage(base.Name, id); err != nil {
+		t.Fatal(err)
+	} else if msg.Status != model.EmailStatusSent {
+		t.Errorf("expected the email to be sent got %v", msg)
+	}
+}
+
+func TestEmailRetryDelay(t *testing.T) {
+	expected := []time.Duration{time.Minute, 4 * time.Minute, 16 * time.Minute, 64 * time.Minute}
+	for i, d := range expected {
+		if got := backend.EmailRetryDelay(i + 1); got != d {
+			t.Errorf("expected %v after %d attempts got %v", d, i+1, got)
+		}
+	}
+}
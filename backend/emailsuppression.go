@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// ErrEmailSuppressed is the error of the queued emails not sent because the
+// address is on the database's suppression list
+var ErrEmailSuppressed = errors.New("the email address is suppressed")
+
+// EmailWebhookToken returns the token authenticating the mail providers'
+// bounce and complaint webhooks of a database
+func EmailWebhookToken(conf model.DatabaseConfig) string {
+	mac := hmac.New(sha256.New, []byte(Config.AppSecret))
+	mac.Write([]byte("email-webhook|" + conf.ID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidEmailWebhookToken returns whether the token is the database's email
+// webhook token
+func ValidEmailWebhookToken(conf model.DatabaseConfig, token string) bool {
+	return hmac.Equal([]byte(token), []byte(EmailWebhookToken(conf)))
+}
+
+// RecordEmailEvents records the bounces and complaints reported by a mail
+// provider, the hard-bounced and complaining addresses are suppressed
+func RecordEmailEvents(conf model.DatabaseConfig, events []model.EmailEvent) error {
+	for _, e := range events {
+		if len(e.Email) == 0 {
+			continue
+		}
+
+		if err := DB.AddEmailEvent(conf.Name, e); err != nil {
+			return err
+		}
+
+		if e.Type == model.EmailEventBounce && !e.Permanent {
+			continue
+		}
+
+		s := model.EmailSuppression{
+			Email:    e.Email,
+			Reason:   e.Type,
+			Provider: e.Provider,
+			Detail:   e.Detail,
+			Created:  e.Created,
+		}
+		if err := DB.SuppressEmail(conf.Name, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+// SaveEmailTemplate validates and adds or replaces an email template of a
+// database
+func SaveEmailTemplate(conf model.DatabaseConfig, tmpl model.EmailTemplate) (model.EmailTemplate, error) {
+	if err := email.ValidateTemplate(tmpl); err != nil {
+		return tmpl, err
+	}
+
+	tmpl.Updated = time.Now()
+	if err := DB.SaveEmailTemplate(conf.Name, tmpl); err != nil {
+		return tmpl, err
+	}
+	return tmpl, nil
+}
+
+// findEmailTemplate returns a database's email template by name and
+// whether it exists
+func findEmailTemplate(conf model.DatabaseConfig, name string) (model.EmailTemplate, bool, error) {
+	list, err := DB.ListEmailTemplates(conf.Name)
+	if err != nil {
+		return model.EmailTemplate{}, false, err
+	}
+
+	for _, tmpl := range list {
+		if tmpl.Name == name {
+			return tmpl, true, nil
+		}
+	}
+	return model.EmailTemplate{}, false, nil
+}
+
+// localizedTemplate returns the system email template to send in the first
+// of the locales having a translation. The database's templates override
+// the built-in translations, first its translations named like
+// password-reset.fr then its template without locale. It also returns
+// whether the database defines the template in any locale.
+func localizedTemplate(conf model.DatabaseConfig, name string, locales []string) (model.EmailTemplate, bool, error) {
+	list, err := DB.ListEmailTemplates(conf.Name)
+	if err != nil {
+		return model.EmailTemplate{}, false, err
+	}
+
+	overridden := false
+	byName := make(map[string]model.EmailTemplate)
+	for _, tmpl := range list {
+		if tmpl.Name == name || strings.HasPrefix(tmpl.Name, name+".") {
+			overridden = true
+			byName[tmpl.Name] = tmpl
+		}
+	}
+
+	candidates := email.LocaleCandidates(locales...)
+	for _, locale := range candidates {
+		if tmpl, ok := byName[email.LocalizedName(name, locale)]; ok {
+			return tmpl, true, nil
+		}
+	}
+
+	if tmpl, ok := byName[name]; ok {
+		return tmpl, true, nil
+	}
+
+	tmpl, ok := email.SystemTemplate(name, candidates)
+	if !ok {
+		return tmpl, overridden, fmt.Errorf("email template %s not found", name)
+	}
+	return tmpl, overridden, nil
+}
+
+// userLocales returns the locales of a user's emails by preference, the
+// user's stored locale followed by the locale hint, usually from the
+// request's Accept-Language header
+func userLocales(conf model.DatabaseConfig, userID, hint string) ([]string, error) {
+	var locales []string
+	if len(userID) > 0 {
+		locale, err := DB.GetUserLocale(conf.Name, userID)
+		if err != nil {
+			return nil, err
+		} else if len(locale) > 0 {
+			locales = append(locales, locale)
+		}
+	}
+
+	if len(hint) > 0 {
+		locales = append(locales, hint)
+	}
+	return locales, nil
+}
+
+// ErrInvalidLocale is returned when setting a locale that is not a language
+// tag like fr or fr-CA
+var ErrInvalidLocale = errors.New("invalid locale, use a language tag like fr or fr-CA")
+
+// SetLocale sets the locale of the system emails sent to a user, an empty
+// locale removes it
+func (u User) SetLocale(userID, locale string) error {
+	if len(locale) > 0 {
+		normalized, ok := email.NormalizeLocale(locale)
+		if !ok {
+			return ErrInvalidLocale
+		}
+		locale = normalized
+	}
+
+	return DB.SetUserLocale(u.conf.Name, userID, locale)
+}
+
+// GetLocale returns the locale of the system emails sent to a user, empty
+// when the user has none
+func (u User) GetLocale(userID string) (string, error) {
+	return DB.GetUserLocale(u.conf.Name, userID)
+}
+
+// SendVerificationEmail sends the "verification" email template to a user
+// in their locale with the app's verification link. The locale hint is
+// used when the user has no stored locale.
+func (u User) SendVerificationEmail(auth model.Auth, link, hint string) error {
+	locales, err := userLocales(u.conf, auth.UserID, hint)
+	if err != nil {
+		return err
+	}
+
+	tmpl, _, err := localizedTemplate(u.conf, model.EmailTemplateVerification, locales)
+	if err != nil {
+		return err
+	}
+
+	vars := map[string]any{"link": link, "email": auth.Email}
+	return sendEmailTemplate(u.conf, tmpl, mailTo(auth.Email), vars)
+}
+
+// SendEmailTemplate renders a database's email template with the variables
+// and sends it to data.To. The sender defaults to the instance's FromEmail
+// and FromName.
+func SendEmailTemplate(conf model.DatabaseConfig, name string, data email.SendMailData, vars map[string]any) error {
+	tmpl, ok, err := findEmailTemplate(conf, name)
+	if err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("email template %s not found", name)
+	}
+
+	return sendEmailTemplate(conf, tmpl, data, vars)
+}
+
+func sendEmailTemplate(conf model.DatabaseConfig, tmpl model.EmailTemplate, data email.SendMailData, vars map[string]any) error {
+	mail, err := email.Render(tmpl, vars)
+	if err != nil {
+		return err
+	}
+
+	mail.From, mail.FromName = data.From, data.FromName
+	mail.To, mail.ToName, mail.ReplyTo = data.To, data.ToName, data.ReplyTo
+
+	if len(mail.From) == 0 {
+		mail.From = Config.FromEmail
+	}
+	if len(mail.FromName) == 0 {
+		mail.FromName = Config.FromName
+	}
+
+	_, err = QueueEmail(conf, mail)
+	return err
+}
+
+// mailTo returns the email data of a recipient sent from the instance's
+// sender
+func mailTo(to string) email.SendMailData {
+	return email.SendMailData{To: to}
+}
@@ -0,0 +1,334 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/staticbackendhq/core/internal"
+	"github.com/staticbackendhq/core/model"
+)
+
+var (
+	// ErrInvalidEnvironment is returned when creating an environment other
+	// than dev or staging or promoting from prod
+	ErrInvalidEnvironment = errors.New("invalid environment, expected dev or staging")
+	// ErrEnvironmentExists is returned when creating an environment the app
+	// already has
+	ErrEnvironmentExists = errors.New("the environment already exists")
+	// ErrEnvironmentNotFound is returned when promoting from or to an
+	// environment the app does not have
+	ErrEnvironmentNotFound = errors.New("environment not found")
+)
+
+// ListEnvironments returns the databases of the app of a database in
+// promotion order, the production database is the last one
+func ListEnvironments(conf model.DatabaseConfig) ([]model.DatabaseConfig, error) {
+	app := conf
+	if len(conf.ParentID) > 0 {
+		parent, err := DB.FindDatabase(conf.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		app = parent
+	}
+
+	envs, err := DB.ListEnvironments(app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := append([]model.DatabaseConfig{app}, envs...)
+	sort.SliceStable(list, func(i, j int) bool {
+		return envIndex(list[i].Env()) < envIndex(list[j].Env())
+	})
+	return list, nil
+}
+
+// CreateEnvironment creates the dev or staging database of the app of a
+// database. It has its own data and is owned by the same tenant, its root
+// user is created with the email and the returned password.
+func CreateEnvironment(conf model.DatabaseConfig, env, email string) (model.DatabaseConfig, model.User, string, error) {
+	if env != model.EnvironmentDev && env != model.EnvironmentStaging {
+		return conf, model.User{}, "", ErrInvalidEnvironment
+	}
+
+	list, err := ListEnvironments(conf)
+	if err != nil {
+		return conf, model.User{}, "", err
+	}
+
+	for _, c := range list {
+		if c.Env() == env {
+			return c, model.User{}, "", ErrEnvironmentExists
+		}
+	}
+
+	// the production database is the last one
+	app := list[len(list)-1]
+
+	dbName, err := newDatabaseName()
+	if err != nil {
+		return conf, model.User{}, "", err
+	}
+
+	base := model.DatabaseConfig{
+		ID:            dbName,
+		TenantID:      app.TenantID,
+		Name:          dbName,
+		AllowedDomain: app.AllowedDomain,
+		IsActive:      app.IsActive,
+		Created:       time.Now(),
+		Environment:   env,
+		ParentID:      app.ID,
+		Region:        app.Region,
+	}
+
+	bc, err := DB.CreateDatabase(base)
+	if err != nil {
+		return base, model.User{}, "", err
+	}
+
+	pw := internal.RandStringRunes(6)
+	_, root, err := Membership(bc).CreateAccountAndUser(email, pw, 100)
+	if err != nil {
+		return bc, model.User{}, "", err
+	}
+	return bc, root, pw, nil
+}
+
+// PromoteEnvironment copies the functions, tasks, collection schemas and
+// forms configuration of an environment of the app of a database to the
+// next environment, dev to staging and staging to prod
+func PromoteEnvironment(conf model.DatabaseConfig, from string) (model.PromotionReport, error) {
+	report := model.PromotionReport{From: from}
+
+	to, ok := model.NextEnvironment(from)
+	if !ok {
+		return report, ErrInvalidEnvironment
+	}
+	report.To = to
+
+	list, err := ListEnvironments(conf)
+	if err != nil {
+		return report, err
+	}
+
+	var src, dst model.DatabaseConfig
+	for _, c := range list {
+		switch c.Env() {
+		case from:
+			src = c
+		case to:
+			dst = c
+		}
+	}
+
+	if len(src.Name) == 0 || len(dst.Name) == 0 {
+		return report, ErrEnvironmentNotFound
+	}
+
+	root, err := DB.GetRootForBase(dst.Name)
+	if err != nil {
+		return report, err
+	}
+
+	p := &promoter{
+		src:    src,
+		dst:    dst,
+		root:   userAuth(root),
+		report: &report,
+	}
+
+	p.promoteFunctions()
+	p.promoteTasks()
+	p.promoteFormDefinitions()
+	if err := p.promoteSettings(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func envIndex(env string) int {
+	for i, e := range model.Environments {
+		if e == env {
+			return i
+		}
+	}
+	return len(model.Environments)
+}
+
+// newDatabaseName returns a random database name not in use
+func newDatabaseName() (string, error) {
+	for retry := 0; retry < 10; retry++ {
+		name := internal.RandStringRunes(12)
+
+		exists, err := DB.DatabaseExists(name)
+		if err != nil {
+			return "", err
+		} else if !exists {
+			return name, nil
+		}
+	}
+	return "", errors.New("unable to find an available database name")
+}
+
+// promoter copies the configuration of an environment to the next one
+type promoter struct {
+	src    model.DatabaseConfig
+	dst    model.DatabaseConfig
+	root   model.Auth
+	report *model.PromotionReport
+}
+
+// fail records an error of the promotion, the promotion continues
+func (p *promoter) fail(format string, args ...interface{}) {
+	if len(p.report.Errors) < maxImportErrors {
+		p.report.Errors = append(p.report.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+// promoteFunctions adds or updates the functions by name, their run history
+// stays in their environment
+func (p *promoter) promoteFunctions() {
+	fns, err := DB.ListFunctions(p.src.Name)
+	if err != nil {
+		p.fail("functions: %v", err)
+		return
+	}
+
+	cur, err := DB.ListFunctions(p.dst.Name)
+	if err != nil {
+		p.fail("functions: %v", err)
+		return
+	}
+
+	byName := make(map[string]model.ExecData)
+	for _, fn := range cur {
+		byName[fn.FunctionName] = fn
+	}
+
+	for _, fn := range fns {
+		if ex, ok := byName[fn.FunctionName]; ok {
+			err = DB.UpdateFunction(p.dst.Name, ex.ID, fn.Code, fn.TriggerTopic)
+		} else {
+			fn.ID = ""
+			fn.AccountID = p.root.AccountID
+			fn.History = nil
+			_, err = DB.AddFunction(p.dst.Name, fn)
+		}
+
+		if err != nil {
+			p.fail("function %s: %v", fn.FunctionName, err)
+			continue
+		}
+		p.report.Functions++
+	}
+}
+
+// promoteTasks adds or updates the tasks by name, the dependent tasks are
+// promoted once their prerequisite is
+func (p *promoter) promoteTasks() {
+	tasks, err := DB.ListTasksByBase(p.src.Name)
+	if err != nil {
+		p.fail("tasks: %v", err)
+		return
+	}
+
+	cur, err := DB.ListTasksByBase(p.dst.Name)
+	if err != nil {
+		p.fail("tasks: %v", err)
+		return
+	}
+
+	// ids maps the promoted tasks' ids to the ones of the next environment
+	ids := make(map[string]string)
+	byName := make(map[string]string)
+	for _, t := range cur {
+		byName[t.Name] = t.ID
+	}
+
+	pending := tasks
+	for len(pending) > 0 {
+		var next []model.Task
+		for _, task := range pending {
+			if task.IsDependent() {
+				after, ok := ids[task.After]
+				if !ok {
+					next = append(next, task)
+					continue
+				}
+				task.After = after
+			}
+
+			srcID := task.ID
+			task.BaseName = p.dst.Name
+
+			var promoted model.Task
+			if id, ok := byName[task.Name]; ok {
+				promoted, err = UpdateTask(p.dst, id, task)
+			} else {
+				task.ID = ""
+				promoted, err = AddTask(p.dst, task)
+			}
+
+			if err != nil {
+				p.fail("task %s: %v", task.Name, err)
+				continue
+			}
+
+			ids[srcID] = promoted.ID
+			byName[promoted.Name] = promoted.ID
+			p.report.Tasks++
+		}
+
+		// the prerequisites of the remaining tasks could not be promoted
+		if len(next) == len(pending) {
+			for _, task := range next {
+				p.fail("task %s: cannot find the task to run after", task.Name)
+			}
+			break
+		}
+		pending = next
+	}
+}
+
+// promoteFormDefinitions saves the field definitions of the forms
+func (p *promoter) promoteFormDefinitions() {
+	defs, err := DB.ListFormDefinitions(p.src.Name)
+	if err != nil {
+		p.fail("forms: %v", err)
+		return
+	}
+
+	for _, def := range defs {
+		def.Updated = time.Now()
+		if err := DB.SaveFormDefinition(p.dst.Name, def); err != nil {
+			p.fail("form %s: %v", def.Name, err)
+			continue
+		}
+		p.report.Forms++
+	}
+}
+
+// promoteSettings replaces the collection schemas and forms settings, the
+// other settings are specific to each environment
+func (p *promoter) promoteSettings() error {
+	s := p.dst.Settings
+	s.Schemas = p.src.Settings.Schemas
+	s.Forms = p.src.Settings.Forms
+
+	if err := DB.UpdateDatabaseSettings(p.dst.ID, s); err != nil {
+		return err
+	}
+
+	// the cached config is used by the WithDB middleware
+	p.dst.Settings = s
+	if err := Cache.SetTyped(p.dst.ID, p.dst); err != nil {
+		return err
+	}
+
+	p.report.Schemas = len(s.Schemas)
+	return nil
+}
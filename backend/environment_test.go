@@ -0,0 +1,125 @@
+package backend_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestEnvironmentPromotion(t *testing.T) {
+	app, _ := newBackupDatabase(t, "envapp", "root@envapp.com")
+
+	dev, root, pw, err := backend.CreateEnvironment(app, model.EnvironmentDev, "root@envapp.com")
+	if err != nil {
+		t.Fatal(err)
+	} else if dev.ParentID != app.ID || dev.TenantID != app.TenantID || len(pw) == 0 || root.Role != 100 {
+		t.Fatalf("unexpected dev environment %v root %v", dev, root)
+	}
+
+	if _, _, _, err := backend.CreateEnvironment(dev, model.EnvironmentDev, "root@envapp.com"); !errors.Is(err, backend.ErrEnvironmentExists) {
+		t.Errorf("expected ErrEnvironmentExists got %v", err)
+	} else if _, _, _, err := backend.CreateEnvironment(app, model.EnvironmentProd, "root@envapp.com"); !errors.Is(err, backend.ErrInvalidEnvironment) {
+		t.Errorf("expected ErrInvalidEnvironment got %v", err)
+	}
+
+	fn := model.ExecData{FunctionName: "promoted", TriggerTopic: "web", Code: "function handle() {}"}
+	if _, err := backend.DB.AddFunction(dev.Name, fn); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the tasks added here are promoted
+	cur, err := backend.DB.ListTasksByBase(dev.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range cur {
+		if err := backend.DB.DeleteTask(dev.Name, task.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	task := model.Task{Name: "nightly", Type: model.TaskTypeFunction, Value: "promoted", Interval: "@daily", BaseName: dev.Name}
+	if _, err := backend.AddTask(dev, task); err != nil {
+		t.Fatal(err)
+	}
+
+	def := model.FormDefinition{Name: "contact", Fields: []model.FormField{{Name: "email", Type: model.FormFieldEmail, Required: true}}, Updated: time.Now()}
+	if err := backend.DB.SaveFormDefinition(dev.Name, def); err != nil {
+		t.Fatal(err)
+	}
+
+	dev.Settings.Schemas = []model.CollectionSchema{{Collection: "notes", Fields: []model.SchemaField{{Name: "title", Type: "string"}}}}
+	dev.Settings.Forms.RateLimit = 3
+	dev.Settings.Files.CDNURL = "https://cdn.dev.test/"
+	if err := backend.DB.UpdateDatabaseSettings(dev.ID, dev.Settings); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.PromoteEnvironment(app, model.EnvironmentDev); !errors.Is(err, backend.ErrEnvironmentNotFound) {
+		t.Fatalf("expected ErrEnvironmentNotFound without a staging environment got %v", err)
+	} else if _, err := backend.PromoteEnvironment(app, model.EnvironmentProd); !errors.Is(err, backend.ErrInvalidEnvironment) {
+		t.Fatalf("expected ErrInvalidEnvironment promoting prod got %v", err)
+	}
+
+	staging, _, _, err := backend.CreateEnvironment(dev, model.EnvironmentStaging, "root@envapp.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := backend.ListEnvironments(staging)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 3 || list[0].ID != dev.ID || list[1].ID != staging.ID || list[2].ID != app.ID {
+		t.Fatalf("expected dev, staging and prod got %v", list)
+	}
+
+	// promoting twice updates what was promoted
+	for i := 0; i < 2; i++ {
+		report, err := backend.PromoteEnvironment(app, model.EnvironmentDev)
+		if err != nil {
+			t.Fatal(err)
+		} else if report.To != model.EnvironmentStaging || report.Functions != 1 || report.Tasks != 1 || report.Schemas != 1 || report.Forms != 1 || len(report.Errors) > 0 {
+			t.Fatalf("unexpected promotion report %v", report)
+		}
+	}
+
+	fns, err := backend.DB.ListFunctions(staging.Name)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(fns) != 1 || fns[0].FunctionName != "promoted" {
+		t.Errorf("expected the promoted function got %v", fns)
+	}
+
+	tasks, err := backend.DB.ListTasksByBase(staging.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := 0
+	for _, task := range tasks {
+		if task.Name == "nightly" {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Errorf("expected the promoted task once got %d", found)
+	}
+
+	if promoted, err := backend.DB.GetFormDefinition(staging.Name, "contact"); err != nil {
+		t.Fatal(err)
+	} else if len(promoted.Fields) != 1 {
+		t.Errorf("expected the promoted form definition got %v", promoted)
+	}
+
+	conf, err := backend.DB.FindDatabase(staging.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(conf.Settings.Schemas) != 1 || conf.Settings.Forms.RateLimit != 3 {
+		t.Errorf("expected the promoted schemas and forms settings got %v", conf.Settings)
+	} else if len(conf.Settings.Files.CDNURL) > 0 {
+		t.Errorf("expected the files settings to stay per environment got %v", conf.Settings.Files)
+	}
+}
@@ -1,13 +1,24 @@
 package backend
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	"github.com/staticbackendhq/core/extra"
 	"github.com/staticbackendhq/core/internal"
+	"github.com/staticbackendhq/core/metering"
 	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/storage"
 )
 
 // FileStore exposes file functions
@@ -25,31 +36,55 @@ func newFile(auth model.Auth, conf model.DatabaseConfig) FileStore {
 
 // SavedFile when a file is saved it has an ID and an URL
 type SavedFile struct {
-	ID  string `json:"id"`
-	URL string `json:"url"`
+	ID       string              `json:"id"`
+	URL      string              `json:"url"`
+	Variants []model.FileVariant `json:"variants,omitempty"`
 }
 
 // Save saves a file content to the file storage (Storer interface) and to the
 // database
-func (f FileStore) Save(filename, name string, file io.ReadSeeker, size int64) (sf SavedFile, err error) {
-	ext := filepath.Ext(filename)
+func (f FileStore) Save(filename, name string, file io.ReadSeeker, size int64) (SavedFile, error) {
+	return f.SaveWithTags(filename, name, file, size, nil)
+}
 
-	if len(name) == 0 {
-		// if no forced name is used, let's use the original file name
-		name = internal.CleanUpFileName(filename)
+// SaveWithTags saves a file like Save with tags used to filter the files
+func (f FileStore) SaveWithTags(filename, name string, file io.ReadSeeker, size int64, tags []string) (SavedFile, error) {
+	return f.save(filename, name, file, size, tags, false)
+}
+
+// SavePrivate saves a file like SaveWithTags without a public URL, the file
+// and its variants are only downloadable via signed URLs and the returned
+// URLs are signed for SignedURLValidity.
+func (f FileStore) SavePrivate(filename, name string, file io.ReadSeeker, size int64, tags []string) (SavedFile, error) {
+	return f.save(filename, name, file, size, tags, true)
+}
+
+func (f FileStore) save(filename, name string, file io.ReadSeeker, size int64, tags []string, private bool) (sf SavedFile, err error) {
+	if err = checkUpload(f.conf.Settings.Uploads, filename, file, size); err != nil {
+		return
 	}
 
-	// add random char to prevent duplicate key
-	name += "_" + internal.RandStringRunes(16)
+	if err = checkQuota(f.conf, size); err != nil {
+		return
+	}
+
+	if err = f.scan(filename, file, size); err != nil {
+		return
+	}
+
+	mimeType, err := detectMimeType(filename, file)
+	if err != nil {
+		return
+	}
 
-	fileKey := fmt.Sprintf("%s/%s/%s%s",
-		f.conf.Name,
-		f.auth.AccountID,
-		name,
-		ext,
-	)
+	name, fileKey := f.newFileKey(filename, name, private)
 
-	upData := model.UploadFileData{FileKey: fileKey, File: file}
+	upData := model.UploadFileData{
+		FileKey:      fileKey,
+		File:         file,
+		CacheControl: cacheControl(f.conf),
+		Private:      private,
+	}
 	url, err := Filestore.Save(upData)
 	if err != nil {
 		return
@@ -61,6 +96,23 @@ func (f FileStore) Save(filename, name string, file io.ReadSeeker, size int64) (
 		URL:       url,
 		Size:      size,
 		Uploaded:  time.Now(),
+		Name:      filepath.Base(filename),
+		MimeType:  mimeType,
+		Tags:      tags,
+	}
+
+	if variants := f.conf.Settings.Images.Variants; len(variants) > 0 {
+		format := extra.ImageFormat(filename)
+		if len(format) > 0 {
+			if _, err = file.Seek(0, io.SeekStart); err != nil {
+				return
+			}
+
+			sbFile.Variants, err = f.saveVariants(filename, format, name, file, variants, private)
+			if err != nil {
+				return
+			}
+		}
 	}
 
 	newID, err := DB.AddFile(f.conf.Name, sbFile)
@@ -68,27 +120,230 @@ func (f FileStore) Save(filename, name string, file io.ReadSeeker, size int64) (
 		return
 	}
 
+	metering.Record(f.conf, model.MeterStorageBytes, sbFile.Size)
+
+	if private {
+		sbFile = signFileURLs(sbFile)
+	} else {
+		sbFile = f.withCDN(sbFile)
+	}
+
 	sf.ID = newID
-	sf.URL = url
+	sf.URL = sbFile.URL
+	sf.Variants = sbFile.Variants
 
 	return
 }
 
+// signFileURLs sets the URLs of a private file and its variants to signed
+// download URLs
+func signFileURLs(file model.File) model.File {
+	expires := time.Now().Add(SignedURLValidity)
+
+	file.URL = signedDownloadURL(file.Key, expires)
+	if len(file.Variants) > 0 {
+		variants := make([]model.FileVariant, len(file.Variants))
+		for i, v := range file.Variants {
+			v.URL = signedDownloadURL(v.Key, expires)
+			variants[i] = v
+		}
+		file.Variants = variants
+	}
+	return file
+}
+
+// newFileKey returns the unique name and storage key of a new file, private
+// files are stored in the storage.PrivateDir directory
+func (f FileStore) newFileKey(filename, name string, private bool) (string, string) {
+	if len(name) == 0 {
+		// if no forced name is used, let's use the original file name
+		name = internal.CleanUpFileName(filename)
+	}
+
+	// add random char to prevent duplicate key
+	name += "_" + internal.RandStringRunes(16)
+
+	return name, f.fileKey(name+filepath.Ext(filename), private)
+}
+
+// fileKey returns the storage key of a file name in the account's directory
+func (f FileStore) fileKey(name string, private bool) string {
+	if private {
+		name = storage.PrivateDir + "/" + name
+	}
+	return storage.StoreKey(Filestore, fmt.Sprintf("%s/%s/%s", f.conf.Name, f.auth.AccountID, name))
+}
+
+// detectMimeType returns the MIME type from the file extension or its content
+func detectMimeType(filename string, file io.ReadSeeker) (string, error) {
+	if mt := mime.TypeByExtension(filepath.Ext(filename)); len(mt) > 0 {
+		return mt, nil
+	}
+	return sniffMimeType(file)
+}
+
+// List returns the files matching the filter, users other than root only
+// see their account's files. URLs are rewritten with the CDN URL if set.
+func (f FileStore) List(filter model.FileFilter) ([]model.File, error) {
+	if f.auth.Role < 100 {
+		filter.AccountID = f.auth.AccountID
+	}
+	files, err := DB.ListFiles(f.conf.Name, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, file := range files {
+		files[i] = f.withCDN(file)
+	}
+	return files, nil
+}
+
+// saveVariants creates and saves the configured variants of an image next to
+// the original file
+func (f FileStore) saveVariants(filename, format, name string, file io.Reader, variants []model.ImageVariant, private bool) ([]model.FileVariant, error) {
+	src, err := extra.DecodeImage(filename, file)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved []model.FileVariant
+	for _, v := range variants {
+		buf := new(bytes.Buffer)
+		ext, err := extra.CreateVariant(src, format, v, buf)
+		if err != nil {
+			return nil, err
+		}
+
+		fileKey := f.fileKey(
+			fmt.Sprintf("%s_%s%s", name, internal.CleanUpFileName(v.Name), ext),
+			private,
+		)
+
+		size := int64(buf.Len())
+
+		upData := model.UploadFileData{
+			FileKey:      fileKey,
+			File:         bytes.NewReader(buf.Bytes()),
+			CacheControl: cacheControl(f.conf),
+			Private:      private,
+		}
+		url, err := Filestore.Save(upData)
+		if err != nil {
+			return nil, err
+		}
+
+		saved = append(saved, model.FileVariant{
+			Name: v.Name,
+			Key:  fileKey,
+			URL:  url,
+			Size: size,
+		})
+	}
+	return saved, nil
+}
+
 // Delete removes a file from storage and database
 func (f FileStore) Delete(fileID string) error {
 	file, err := DB.GetFileByID(f.conf.Name, fileID)
 	if err != nil {
 		return err
+	} else if f.auth.Role < 100 && file.AccountID != f.auth.AccountID {
+		return errors.New("file not found")
 	}
 
-	fileKey := file.Key
+	return removeFile(f.conf.Name, file)
+}
 
-	if err := Filestore.Delete(fileKey); err != nil {
+// removeFile deletes a file and its variants from storage and database
+func removeFile(dbName string, file model.File) error {
+	if err := Filestore.Delete(file.Key); err != nil {
 		return err
 	}
 
-	if err := DB.DeleteFile(f.conf.Name, file.ID); err != nil {
+	for _, v := range file.Variants {
+		if err := Filestore.Delete(v.Key); err != nil {
+			return err
+		}
+	}
+
+	if err := DB.DeleteFile(dbName, file.ID); err != nil {
 		return err
 	}
 	return nil
 }
+
+// Signed download URLs are valid for SignedURLValidity unless another
+// duration is requested, up to MaxSignedURLValidity
+const (
+	SignedURLValidity    = 15 * time.Minute
+	MaxSignedURLValidity = 7 * 24 * time.Hour
+)
+
+// SignedURL returns a temporary download URL for a file or one of its
+// variants, it expires after validity
+func (f FileStore) SignedURL(fileID, variant string, validity time.Duration) (string, time.Time, error) {
+	file, err := DB.GetFileByID(f.conf.Name, fileID)
+	if err != nil {
+		return "", time.Time{}, err
+	} else if f.auth.Role < 100 && file.AccountID != f.auth.AccountID {
+		return "", time.Time{}, errors.New("file not found")
+	}
+
+	fileKey := file.Key
+	if len(variant) > 0 {
+		fileKey = ""
+		for _, v := range file.Variants {
+			if v.Name == variant {
+				fileKey = v.Key
+			}
+		}
+
+		if len(fileKey) == 0 {
+			return "", time.Time{}, errors.New("variant not found")
+		}
+	}
+
+	if validity <= 0 {
+		validity = SignedURLValidity
+	} else if validity > MaxSignedURLValidity {
+		validity = MaxSignedURLValidity
+	}
+
+	expires := time.Now().Add(validity)
+	return signedDownloadURL(fileKey, expires), expires, nil
+}
+
+// signedDownloadURL returns the download URL of a file key valid until
+// expires
+func signedDownloadURL(fileKey string, expires time.Time) string {
+	qs := url.Values{}
+	qs.Set("key", fileKey)
+	qs.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	qs.Set("sig", signFileKey(fileKey, expires.Unix()))
+
+	return fmt.Sprintf("%s/storage/download?%s", Config.AppURL, qs.Encode())
+}
+
+// VerifySignedURL validates the key, expires and sig parameters of a signed
+// download URL
+func VerifySignedURL(fileKey, expires, sig string) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(signFileKey(fileKey, exp))) {
+		return errors.New("invalid signature")
+	} else if time.Now().Unix() > exp {
+		return errors.New("download URL expired")
+	}
+	return nil
+}
+
+// signFileKey returns the HMAC of a file key and its expiration
+func signFileKey(fileKey string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(Config.AppSecret))
+	fmt.Fprintf(mac, "%s|%d", fileKey, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
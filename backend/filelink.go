@@ -0,0 +1,116 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// Link ties a file to a document or a user of the account, the file is
+// deleted with them. An empty link removes the current one.
+func (f FileStore) Link(fileID string, link model.FileLink) error {
+	file, err := DB.GetFileByID(f.conf.Name, fileID)
+	if err != nil {
+		return err
+	} else if f.auth.Role < 100 && file.AccountID != f.auth.AccountID {
+		return errors.New("file not found")
+	}
+
+	if len(link.Collection) > 0 || len(link.DocumentID) > 0 {
+		if len(link.Collection) == 0 || len(link.DocumentID) == 0 {
+			return errors.New("the collection and document id are required")
+		}
+
+		if _, err := DB.GetDocumentByID(f.auth, f.conf.Name, link.Collection, link.DocumentID); err != nil {
+			return errors.New("document not found")
+		}
+
+		link.Collection = model.CleanCollectionName(link.Collection)
+	}
+
+	if len(link.UserID) > 0 {
+		if _, err := DB.GetUserByID(f.conf.Name, file.AccountID, link.UserID); err != nil {
+			return errors.New("user not found")
+		}
+	}
+
+	return DB.LinkFile(f.conf.Name, fileID, link)
+}
+
+// DeleteLinkedFiles removes the files linked to a document or a user from
+// the storage and database and returns how many were removed
+func DeleteLinkedFiles(dbName string, link model.FileLink) (n int, err error) {
+	filter := model.FileFilter{UserID: link.UserID}
+	if len(link.DocumentID) > 0 {
+		filter.Collection = model.CleanCollectionName(link.Collection)
+		filter.DocumentID = link.DocumentID
+	}
+
+	// an empty filter would match all files
+	if len(filter.UserID) == 0 && len(filter.DocumentID) == 0 {
+		return 0, nil
+	}
+
+	files, err := DB.ListFiles(dbName, filter)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if err = removeFile(dbName, file); err != nil {
+			return
+		}
+		n++
+	}
+	return
+}
+
+// ScheduleLinkedFilesDeletion removes the files linked to a document or a
+// user in the background
+func ScheduleLinkedFilesDeletion(dbName string, link model.FileLink) {
+	go func() {
+		n, err := DeleteLinkedFiles(dbName, link)
+		if err != nil {
+			Log.Error().Err(err).Msgf("error deleting linked files in %s", dbName)
+		} else if n > 0 {
+			Log.Info().Msgf("%d linked files deleted in %s", n, dbName)
+		}
+	}()
+}
+
+// deleteDocumentFiles removes the files linked to a deleted document
+func deleteDocumentFiles(msg model.Command) {
+	link := model.FileLink{
+		Collection: strings.TrimPrefix(msg.Channel, "db-"),
+		DocumentID: deletedDocumentID(msg.Data),
+	}
+	if len(link.DocumentID) == 0 {
+		return
+	}
+
+	n, err := DeleteLinkedFiles(msg.Base, link)
+	if err != nil {
+		Log.Error().Err(err).Msgf("error deleting files of %s/%s", link.Collection, link.DocumentID)
+	} else if n > 0 {
+		Log.Info().Msgf("%d files of %s/%s deleted", n, link.Collection, link.DocumentID)
+	}
+}
+
+// deletedDocumentID returns the id of a deleted document event, depending
+// on the database provider it's the id or the deleted document
+func deletedDocumentID(data string) string {
+	var id string
+	if err := json.Unmarshal([]byte(data), &id); err == nil {
+		return id
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return ""
+	}
+
+	id, _ = doc["id"].(string)
+	return id
+}
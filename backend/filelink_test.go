@@ -0,0 +1,54 @@
+package backend_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestDeleteDocumentDeletesLinkedFiles(t *testing.T) {
+	db := backend.Collection[Task](adminAuth, base, "tasks")
+
+	task, err := db.Create(newTask("with attachment", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := backend.Storage(adminAuth, base)
+
+	sf, err := fs.Save("attachment.txt", "", strings.NewReader("attached"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link := model.FileLink{Collection: "tasks", DocumentID: "not-a-task"}
+	if err := fs.Link(sf.ID, link); err == nil {
+		t.Error("expected linking to an unknown document to fail")
+	}
+
+	link.DocumentID = task.ID
+	if err := fs.Link(sf.ID, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Delete(task.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// the files are deleted when the system event is received
+	for i := 0; i < 50; i++ {
+		files, err := fs.List(model.FileFilter{Collection: "tasks", DocumentID: task.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(files) == 0 {
+			return
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Error("expected the linked file to be deleted with the document")
+}
@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// FeatureDefaults are the flags enabled for all the tenants which did not
+// override them, they are set from the FEATURE_FLAGS config on start
+var FeatureDefaults = map[string]bool{}
+
+// ErrInvalidFlag is returned when setting a flag with an invalid name
+var ErrInvalidFlag = errors.New("invalid flag name, use 1 to 50 lowercase letters, digits, - or _")
+
+var flagName = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+func flagsKey(tenantID string) string {
+	return "flags:" + tenantID
+}
+
+// parseFeatureDefaults returns the flags of a comma-separated list
+func parseFeatureDefaults(s string) map[string]bool {
+	defaults := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			defaults[name] = true
+		}
+	}
+	return defaults
+}
+
+// TenantFlags returns the flags of a tenant, the defaults with the tenant's
+// overrides applied. The overrides are cached.
+func TenantFlags(tenantID string) (map[string]bool, error) {
+	var overrides []model.FeatureFlag
+	if err := Cache.GetTyped(flagsKey(tenantID), &overrides); err != nil {
+		if overrides, err = cacheFeatureFlags(tenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	flags := make(map[string]bool)
+	for name, enabled := range FeatureDefaults {
+		flags[name] = enabled
+	}
+	for _, flag := range overrides {
+		flags[flag.Name] = flag.Enabled
+	}
+	return flags, nil
+}
+
+// FlagEnabled returns whether a flag is enabled for a tenant, the unknown
+// flags are disabled
+func FlagEnabled(tenantID, name string) (bool, error) {
+	flags, err := TenantFlags(tenantID)
+	if err != nil {
+		return false, err
+	}
+	return flags[name], nil
+}
+
+// DatabaseFlagEnabled returns whether a flag is enabled for the tenant of a
+// database, it's the Flag hook of the execution environments
+func DatabaseFlagEnabled(dbName, name string) (bool, error) {
+	conf, err := findDatabaseByName(dbName)
+	if err != nil {
+		return false, err
+	}
+	return FlagEnabled(conf.TenantID, name)
+}
+
+// SetFeatureFlag enables or disables a flag for a tenant regardless of its
+// default
+func SetFeatureFlag(tenantID, name string, enabled bool) error {
+	if !flagName.MatchString(name) {
+		return ErrInvalidFlag
+	}
+
+	flag := model.FeatureFlag{
+		TenantID: tenantID,
+		Name:     name,
+		Enabled:  enabled,
+		Updated:  time.Now(),
+	}
+	if err := DB.SetFeatureFlag(flag); err != nil {
+		return err
+	}
+
+	_, err := cacheFeatureFlags(tenantID)
+	return err
+}
+
+// DeleteFeatureFlag removes the override of a flag for a tenant, its
+// default applies
+func DeleteFeatureFlag(tenantID, name string) error {
+	if err := DB.DeleteFeatureFlag(tenantID, name); err != nil {
+		return err
+	}
+
+	_, err := cacheFeatureFlags(tenantID)
+	return err
+}
+
+// cacheFeatureFlags loads the overrides of a tenant and caches them so all
+// instances apply a change on the next check
+func cacheFeatureFlags(tenantID string) ([]model.FeatureFlag, error) {
+	overrides, err := DB.ListFeatureFlags(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if overrides == nil {
+		overrides = make([]model.FeatureFlag, 0)
+	}
+
+	if err := Cache.SetTyped(flagsKey(tenantID), overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
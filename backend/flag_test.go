@@ -0,0 +1,55 @@
+package backend_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	defaults := backend.FeatureDefaults
+	defer func() { backend.FeatureDefaults = defaults }()
+
+	backend.FeatureDefaults = map[string]bool{"graphql": true}
+
+	defer backend.DeleteFeatureFlag(base.TenantID, "graphql")
+	defer backend.DeleteFeatureFlag(base.TenantID, "wasm")
+
+	if ok, err := backend.FlagEnabled(base.TenantID, "graphql"); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Errorf("expected graphql to be enabled by default")
+	}
+
+	if err := backend.SetFeatureFlag(base.TenantID, "graphql", false); err != nil {
+		t.Fatal(err)
+	} else if err := backend.SetFeatureFlag(base.TenantID, "wasm", true); err != nil {
+		t.Fatal(err)
+	}
+
+	flags, err := backend.TenantFlags(base.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	} else if flags["graphql"] || !flags["wasm"] {
+		t.Errorf("expected the overrides to apply got %v", flags)
+	}
+
+	if ok, err := backend.DatabaseFlagEnabled(base.Name, "wasm"); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Errorf("expected wasm to be enabled for the database's tenant")
+	}
+
+	if err := backend.DeleteFeatureFlag(base.TenantID, "graphql"); err != nil {
+		t.Fatal(err)
+	} else if ok, err := backend.FlagEnabled(base.TenantID, "graphql"); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Errorf("expected graphql to be back to its default")
+	}
+
+	if err := backend.SetFeatureFlag(base.TenantID, "Not Valid", true); !errors.Is(err, backend.ErrInvalidFlag) {
+		t.Errorf("expected ErrInvalidFlag got %v", err)
+	}
+}
@@ -0,0 +1,595 @@
+package backend
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/extra"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
+)
+
+// Reasons a form submission is flagged as spam
+const (
+	SpamHoneypot        = "honeypot"
+	SpamCaptcha         = "captcha"
+	SpamDisposableEmail = "disposable-email"
+)
+
+// DefaultFormRateLimit is the number of submissions per minute allowed for
+// an IP when the database settings do not set one
+const DefaultFormRateLimit = 10
+
+// DefaultFormMaxAttachments is the number of files a submission can attach
+// when the database settings do not set one
+const DefaultFormMaxAttachments = 5
+
+var (
+	// ErrFormRateLimited is returned when an IP submits too many forms
+	ErrFormRateLimited = errors.New("too many form submissions, try again later")
+	// ErrTooManyAttachments is returned when a submission attaches more
+	// files than allowed
+	ErrTooManyAttachments = errors.New("too many files attached to the form")
+)
+
+// FormAttachment is a file attached to a form submission, listed in its
+// sb_attachments field. The file belongs to the database's root account
+// and is tagged with form:{name}.
+type FormAttachment struct {
+	Field    string `json:"field"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// formHoneypotField must stay empty, bots fill every field
+const formHoneypotField = "_hp_"
+
+// formCaptchaFields are the fields captcha widgets add to forms
+var formCaptchaFields = []string{"h-captcha-response", "cf-turnstile-response", "sb-captcha-token"}
+
+// SubmitForm saves a form submission and its attached files. Submissions
+// not matching the form's field definitions are rejected with a
+// FormValidationError. Suspicious submissions are saved with the sb_spam
+// flag and the sb_spam_reasons field listing why they were flagged, which
+// are also returned. The files of suspicious submissions are not stored.
+func SubmitForm(conf model.DatabaseConfig, form, ip, captchaToken string, values url.Values, files map[string][]*multipart.FileHeader) (reasons []string, err error) {
+	if err = checkFormRate(conf, ip); err != nil {
+		return
+	}
+
+	def, err := DB.GetFormDefinition(conf.Name, form)
+	if err != nil {
+		return
+	} else if err = validateFormFields(def, values, files); err != nil {
+		return
+	}
+
+	if len(values.Get(formHoneypotField)) > 0 {
+		reasons = append(reasons, SpamHoneypot)
+	}
+
+	if cs := conf.Settings.Captcha; cs.Enabled() && cs.OnForms {
+		// HTML forms cannot send the header, the widgets add a field
+		for _, field := range formCaptchaFields {
+			if len(captchaToken) > 0 {
+				break
+			}
+			captchaToken = values.Get(field)
+		}
+
+		if err := middleware.VerifyCaptcha(Cache, cs, captchaToken, ip); err != nil {
+			reasons = append(reasons, SpamCaptcha)
+		}
+	}
+
+	if settings := conf.Settings.Forms; settings.BlockDisposableEmails && hasDisposableEmail(values, settings.BlockedEmailDomains) {
+		reasons = append(reasons, SpamDisposableEmail)
+	}
+
+	doc := make(map[string]interface{})
+	for k, v := range values {
+		if k == formHoneypotField || contains(formCaptchaFields, k) {
+			continue
+		}
+		doc[k] = strings.Join(v, ", ")
+	}
+
+	if len(reasons) > 0 {
+		doc["sb_spam"] = true
+		doc["sb_spam_reasons"] = reasons
+	} else if len(files) > 0 {
+		var attachments []FormAttachment
+		attachments, err = saveFormAttachments(conf, form, files)
+		if err != nil {
+			return
+		}
+
+		defer func() {
+			// the files would not be reachable without the submission
+			if err != nil {
+				deleteFormAttachments(conf, attachments)
+			}
+		}()
+
+		var list []interface{}
+		if list, err = attachmentValues(attachments); err != nil {
+			return
+		}
+		doc["sb_attachments"] = list
+	}
+
+	if err = DB.AddFormSubmission(conf.Name, form, doc); err != nil {
+		return
+	}
+
+	if len(reasons) == 0 {
+		notifyFormWebhooks(conf, form, doc)
+		sendFormAutoResponses(conf, form, values)
+	}
+	return
+}
+
+// sendFormAutoResponses emails the enabled auto-responders of the form to
+// the submitted address in the background
+func sendFormAutoResponses(conf model.DatabaseConfig, form string, values url.Values) {
+	for _, ar := range conf.Settings.Forms.AutoResponders {
+		if !ar.Enabled || ar.Form != form {
+			continue
+		}
+
+		addr, err := mail.ParseAddress(values.Get(ar.EmailField))
+		if err != nil {
+			continue
+		}
+
+		go func(ar model.FormAutoResponder, to string) {
+			if err := sendFormAutoResponse(conf, ar, to, values); err != nil {
+				Log.Error().Err(err).Msgf("cannot send form %s auto-response", form)
+			}
+		}(ar, addr.Address)
+	}
+}
+
+func sendFormAutoResponse(conf model.DatabaseConfig, ar model.FormAutoResponder, to string, values url.Values) error {
+	if len(ar.FromEmail) == 0 {
+		ar.FromEmail = Config.FromEmail
+	}
+	if len(ar.FromName) == 0 {
+		ar.FromName = Config.FromName
+	}
+
+	body := formTemplate(ar.Body, values, true)
+
+	mail := email.SendMailData{
+		From:     ar.FromEmail,
+		FromName: ar.FromName,
+		To:       to,
+		ReplyTo:  ar.ReplyTo,
+		Subject:  formTemplate(ar.Subject, values, false),
+		HTMLBody: body,
+		TextBody: email.StripHTML(body),
+	}
+	_, err := QueueEmail(conf, mail)
+	return err
+}
+
+// formTemplate replaces the [field] placeholders with the submitted values,
+// escaped when the template is HTML
+func formTemplate(tmpl string, values url.Values, html bool) string {
+	var pairs []string
+	for k, v := range values {
+		val := strings.Join(v, ", ")
+		if html {
+			val = template.HTMLEscapeString(val)
+		}
+		pairs = append(pairs, "["+k+"]", val)
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+// FormSubmissionEvent is the data of the form.submitted webhook event
+type FormSubmissionEvent struct {
+	Form       string                 `json:"form"`
+	Submission map[string]interface{} `json:"submission"`
+}
+
+// notifyFormWebhooks queues the submission for the form's webhooks, each
+// delivery attempt is added to the form's delivery log. The app's webhooks
+// subscribed to form.submitted receive it as well.
+func notifyFormWebhooks(conf model.DatabaseConfig, form string, doc map[string]interface{}) {
+	data := FormSubmissionEvent{Form: form, Submission: doc}
+
+	EmitWebhook(conf, model.WebhookFormSubmitted, data)
+
+	var hooks []model.FormWebhook
+	for _, hook := range conf.Settings.Forms.Webhooks {
+		if hook.Matches(form) {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(webhook.Payload{Event: model.WebhookFormSubmitted, Created: time.Now(), Data: data})
+	if err != nil {
+		Log.Error().Err(err).Msgf("unable to encode form %s webhook payload", form)
+		return
+	}
+
+	for _, hook := range hooks {
+		if _, err := queueWebhook(conf, hook.URL, model.WebhookFormSubmitted, string(body)); err != nil {
+			Log.Error().Err(err).Msgf("cannot queue form %s webhook to %s", form, hook.URL)
+		}
+	}
+}
+
+// logFormDelivery adds a delivery attempt of a form webhook to the form's
+// delivery log
+func logFormDelivery(conf model.DatabaseConfig, d model.WebhookDelivery, attempt, statusCode int, err error) {
+	var pl struct {
+		Data FormSubmissionEvent `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(d.Payload), &pl); err != nil {
+		Log.Error().Err(err).Msgf("cannot decode the form submission of webhook %s", d.ID)
+		return
+	}
+
+	fd := model.FormDelivery{
+		Form:       pl.Data.Form,
+		URL:        d.URL,
+		Attempt:    attempt,
+		StatusCode: statusCode,
+		Success:    err == nil,
+		Created:    time.Now(),
+	}
+	if err != nil {
+		fd.Error = err.Error()
+	}
+
+	if err := DB.AddFormDelivery(conf.Name, fd); err != nil {
+		Log.Error().Err(err).Msgf("cannot log form %s webhook delivery", pl.Data.Form)
+	}
+}
+
+// attachmentValues converts the attachments to plain JSON values like the
+// other fields of the submission
+func attachmentValues(attachments []FormAttachment) (list []interface{}, err error) {
+	b, err := json.Marshal(attachments)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &list)
+	return
+}
+
+// saveFormAttachments saves the files as the database's root account, they
+// follow the upload restrictions and storage quota like other files
+func saveFormAttachments(conf model.DatabaseConfig, form string, files map[string][]*multipart.FileHeader) (attachments []FormAttachment, err error) {
+	max := conf.Settings.Forms.MaxAttachments
+	if max <= 0 {
+		max = DefaultFormMaxAttachments
+	}
+
+	count := 0
+	for _, headers := range files {
+		count += len(headers)
+	}
+	if count > max {
+		return nil, ErrTooManyAttachments
+	}
+
+	root, err := DB.GetRootForBase(conf.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := model.Auth{
+		AccountID: root.AccountID,
+		UserID:    root.ID,
+		Email:     root.Email,
+		Role:      root.Role,
+	}
+	fs := Storage(auth, conf)
+
+	for field, headers := range files {
+		for _, fh := range headers {
+			sf, err := saveFormAttachment(fs, form, fh)
+			if err != nil {
+				deleteFormAttachments(conf, attachments)
+				return nil, err
+			}
+
+			attachments = append(attachments, FormAttachment{
+				Field:    field,
+				ID:       sf.ID,
+				Name:     filepath.Base(fh.Filename),
+				URL:      sf.URL,
+				Size:     fh.Size,
+				MimeType: fh.Header.Get("Content-Type"),
+			})
+		}
+	}
+	return
+}
+
+func saveFormAttachment(fs FileStore, form string, fh *multipart.FileHeader) (SavedFile, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return SavedFile{}, err
+	}
+	defer file.Close()
+
+	return fs.SaveWithTags(fh.Filename, "", file, fh.Size, []string{"form:" + form})
+}
+
+// deleteFormAttachments removes the attached files of a submission
+func deleteFormAttachments(conf model.DatabaseConfig, attachments []FormAttachment) {
+	for _, a := range attachments {
+		file, err := DB.GetFileByID(conf.Name, a.ID)
+		if err != nil {
+			Log.Error().Err(err).Msgf("cannot find form attachment %s", a.ID)
+			continue
+		}
+
+		if err := removeFile(conf.Name, file); err != nil {
+			Log.Error().Err(err).Msgf("cannot delete form attachment %s", a.ID)
+		}
+	}
+}
+
+// checkFormRate limits the submissions per minute of an IP
+func checkFormRate(conf model.DatabaseConfig, ip string) error {
+	limit := conf.Settings.Forms.RateLimit
+	if limit <= 0 {
+		limit = DefaultFormRateLimit
+	}
+
+	window := time.Now().Truncate(time.Minute)
+	key := fmt.Sprintf("form-rl-%s-%s-%d", conf.ID, ip, window.Unix())
+
+	n, err := Cache.Inc(key, 1)
+	if err != nil {
+		// the rate limiter should not prevent submissions
+		return nil
+	} else if n == 1 {
+		if err := Cache.Expire(key, time.Minute); err != nil {
+			return err
+		}
+	}
+
+	if n > int64(limit) {
+		return ErrFormRateLimited
+	}
+	return nil
+}
+
+// hasDisposableEmail returns true if a value is an email address from a
+// disposable email provider or one of the blocked domains
+func hasDisposableEmail(values url.Values, blocked []string) bool {
+	for _, vals := range values {
+		for _, v := range vals {
+			if !strings.Contains(v, "@") {
+				continue
+			}
+
+			addr, err := mail.ParseAddress(v)
+			if err != nil {
+				continue
+			}
+
+			_, domain, _ := strings.Cut(addr.Address, "@")
+			domain = strings.ToLower(domain)
+			if disposableDomains[domain] {
+				return true
+			}
+
+			for _, b := range blocked {
+				if strings.EqualFold(domain, b) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// disposableDomains are common disposable email providers
+var disposableDomains = map[string]bool{
+	"10minutemail.com":       true,
+	"20minutemail.com":       true,
+	"33mail.com":             true,
+	"dispostable.com":        true,
+	"emailondeck.com":        true,
+	"fakeinbox.com":          true,
+	"getairmail.com":         true,
+	"getnada.com":            true,
+	"guerrillamail.biz":      true,
+	"guerrillamail.com":      true,
+	"guerrillamail.de":       true,
+	"guerrillamail.net":      true,
+	"guerrillamail.org":      true,
+	"guerrillamailblock.com": true,
+	"maildrop.cc":            true,
+	"mailinator.com":         true,
+	"mailinator.net":         true,
+	"mailnesia.com":          true,
+	"mintemail.com":          true,
+	"mohmal.com":             true,
+	"mytemp.email":           true,
+	"sharklasers.com":        true,
+	"spamgourmet.com":        true,
+	"temp-mail.org":          true,
+	"tempmail.com":           true,
+	"tempmail.net":           true,
+	"tempmailo.com":          true,
+	"throwawaymail.com":      true,
+	"trashmail.com":          true,
+	"trashmail.de":           true,
+	"yopmail.com":            true,
+	"yopmail.fr":             true,
+	"yopmail.net":            true,
+}
+
+// Form export formats
+const (
+	FormExportCSV  = "csv"
+	FormExportXLSX = "xlsx"
+)
+
+// ErrInvalidExportFormat is returned for export formats other than csv and
+// xlsx
+var ErrInvalidExportFormat = errors.New("the export format must be csv or xlsx")
+
+// formRecordWriter is implemented by csv.Writer and extra.XLSXWriter
+type formRecordWriter interface {
+	Write(record []string) error
+}
+
+// ExportForm writes the submissions of a form created between since and
+// until (zero times are unbounded) as CSV or XLSX. Submissions can have
+// different fields, the columns are the union of all fields.
+func ExportForm(conf model.DatabaseConfig, form, format string, since, until time.Time, w io.Writer) error {
+	var rw formRecordWriter
+	var flush func() error
+
+	switch format {
+	case FormExportCSV, "":
+		cw := csv.NewWriter(w)
+		rw, flush = csvFormulaWriter{cw}, func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case FormExportXLSX:
+		xw := extra.NewXLSXWriter(w)
+		rw, flush = xw, xw.Close
+	default:
+		return ErrInvalidExportFormat
+	}
+
+	// a first pass discovers the columns so rows can be streamed, the
+	// second pass skips the submissions received in between since their
+	// fields might not have a column
+	var last time.Time
+	fields := make(map[string]bool)
+	err := DB.EachFormSubmission(conf.Name, form, since, until, func(doc map[string]interface{}) error {
+		for k := range doc {
+			fields[k] = true
+		}
+		if created, ok := doc["created"].(time.Time); ok && created.After(last) {
+			last = created
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	columns := formColumns(fields)
+	if err := rw.Write(columns); err != nil {
+		return err
+	}
+
+	err = DB.EachFormSubmission(conf.Name, form, since, until, func(doc map[string]interface{}) error {
+		if created, ok := doc["created"].(time.Time); ok && created.After(last) {
+			return nil
+		}
+
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = formCellValue(doc[col])
+		}
+		return rw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// csvFormulaWriter prefixes with a quote the cells spreadsheets would
+// evaluate as formulas
+type csvFormulaWriter struct {
+	*csv.Writer
+}
+
+func (cw csvFormulaWriter) Write(record []string) error {
+	for i, v := range record {
+		if len(v) > 0 && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			record[i] = "'" + v
+		}
+	}
+	return cw.Writer.Write(record)
+}
+
+// formColumns returns the id and created columns followed by the other
+// fields sorted by name
+func formColumns(fields map[string]bool) []string {
+	var others []string
+	for k := range fields {
+		if k != "id" && k != "created" {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+
+	return append([]string{"id", "created"}, others...)
+}
+
+// formCellValue formats a submission value for the export, attachments
+// are exported as their URLs
+func formCellValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case time.Time:
+		return x.UTC().Format(time.RFC3339)
+	case []interface{}:
+		var values []string
+		for _, item := range x {
+			if m, ok := item.(map[string]interface{}); ok && m["url"] != nil {
+				values = append(values, formCellValue(m["url"]))
+				continue
+			}
+			values = append(values, formCellValue(item))
+		}
+		return strings.Join(values, ", ")
+	case []string:
+		return strings.Join(x, ", ")
+	}
+
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", v)
+}
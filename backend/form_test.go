@@ -0,0 +1,504 @@
+package backend_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
+)
+
+func TestSubmitFormSpam(t *testing.T) {
+	conf := base
+	conf.Settings.Forms.BlockDisposableEmails = true
+	conf.Settings.Forms.BlockedEmailDomains = []string{"spam.test"}
+
+	tests := []struct {
+		values   url.Values
+		expected string
+	}{
+		{url.Values{"email": {"real@test.com"}}, ""},
+		{url.Values{"email": {"real@test.com"}, "_hp_": {"filled by a bot"}}, backend.SpamHoneypot},
+		{url.Values{"email": {"Bot <bot@mailinator.com>"}}, backend.SpamDisposableEmail},
+		{url.Values{"contact": {"bot@SPAM.test"}}, backend.SpamDisposableEmail},
+	}
+
+	for _, tc := range tests {
+		reasons, err := backend.SubmitForm(conf, "spamtest", "10.0.0.1", "", tc.values, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(tc.expected) == 0 && len(reasons) > 0 {
+			t.Errorf("expected %v not to be flagged got %v", tc.values, reasons)
+		} else if len(tc.expected) > 0 && (len(reasons) != 1 || reasons[0] != tc.expected) {
+			t.Errorf("expected %v to be flagged %s got %v", tc.values, tc.expected, reasons)
+		}
+	}
+
+	docs, err := backend.DB.ListFormSubmissions(conf.Name, "spamtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var flagged int
+	for _, doc := range docs {
+		if spam, _ := doc["sb_spam"].(bool); spam {
+			flagged++
+		}
+		if _, ok := doc["_hp_"]; ok {
+			t.Error("expected the honeypot field not to be saved")
+		}
+	}
+
+	// suspicious submissions are kept
+	if len(docs) != len(tests) {
+		t.Errorf("expected %d submissions got %d", len(tests), len(docs))
+	} else if flagged != 3 {
+		t.Errorf("expected 3 flagged submissions got %d", flagged)
+	}
+}
+
+func TestSubmitFormRateLimit(t *testing.T) {
+	conf := base
+	conf.Settings.Forms.RateLimit = 2
+
+	values := url.Values{"name": {"flood"}}
+	for i := 0; i < 2; i++ {
+		if _, err := backend.SubmitForm(conf, "ratetest", "10.0.0.2", "", values, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := backend.SubmitForm(conf, "ratetest", "10.0.0.2", "", values, nil); !errors.Is(err, backend.ErrFormRateLimited) {
+		t.Errorf("expected ErrFormRateLimited got %v", err)
+	}
+
+	// the limit is per IP
+	if _, err := backend.SubmitForm(conf, "ratetest", "10.0.0.3", "", values, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSubmitFormCaptcha(t *testing.T) {
+	conf := base
+	conf.Settings.Captcha.Provider = "pow"
+	conf.Settings.Captcha.OnForms = true
+
+	reasons, err := backend.SubmitForm(conf, "captchatest", "10.0.0.4", "", url.Values{"name": {"no token"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(reasons) != 1 || reasons[0] != backend.SpamCaptcha {
+		t.Errorf("expected the submission to be flagged %s got %v", backend.SpamCaptcha, reasons)
+	}
+}
+
+// multipartFiles returns the file headers of a parsed multipart form
+func multipartFiles(t *testing.T, files map[string]string) map[string][]*multipart.FileHeader {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for name, content := range files {
+		part, err := mw.CreateFormFile("attachment", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	mw.Close()
+
+	form, err := multipart.NewReader(&buf, mw.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File
+}
+
+func TestSubmitFormAttachments(t *testing.T) {
+	files := multipartFiles(t, map[string]string{"resume.txt": "my resume"})
+
+	values := url.Values{"name": {"applicant"}}
+	if _, err := backend.SubmitForm(base, "attachtest", "10.0.0.5", "", values, files); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := backend.DB.ListFormSubmissions(base.Name, "attachtest")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(docs) != 1 {
+		t.Fatalf("expected 1 submission got %d", len(docs))
+	}
+
+	b, err := json.Marshal(docs[0]["sb_attachments"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attachments []backend.FormAttachment
+	if err := json.Unmarshal(b, &attachments); err != nil {
+		t.Fatal(err)
+	} else if len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment got %v", attachments)
+	} else if attachments[0].Field != "attachment" || attachments[0].Name != "resume.txt" {
+		t.Errorf("unexpected attachment %v", attachments[0])
+	}
+
+	file, err := backend.DB.GetFileByID(base.Name, attachments[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if file.Size != int64(len("my resume")) {
+		t.Errorf("expected size %d got %d", len("my resume"), file.Size)
+	} else if len(file.Tags) != 1 || file.Tags[0] != "form:attachtest" {
+		t.Errorf("expected the form tag got %v", file.Tags)
+	}
+
+	if err := backend.Storage(adminAuth, base).Delete(file.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitFormTooManyAttachments(t *testing.T) {
+	conf := base
+	conf.Settings.Forms.MaxAttachments = 1
+
+	files := multipartFiles(t, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	values := url.Values{"name": {"too many"}}
+	if _, err := backend.SubmitForm(conf, "attachtest2", "10.0.0.6", "", values, files); !errors.Is(err, backend.ErrTooManyAttachments) {
+		t.Errorf("expected ErrTooManyAttachments got %v", err)
+	}
+}
+
+func TestExportFormXLSX(t *testing.T) {
+	values := url.Values{}
+	values.Add("name", "xlsx export")
+
+	if _, err := backend.SubmitForm(base, "xlsx-export", "10.0.0.9", "", values, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := backend.ExportForm(base, "xlsx-export", backend.FormExportXLSX, time.Time{}, time.Time{}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		} else if !strings.Contains(string(b), "xlsx export") {
+			t.Errorf("expected the sheet to contain the submission got %s", b)
+		}
+		return
+	}
+	t.Error("the workbook has no sheet")
+}
+
+func TestExportFormCSVFormulas(t *testing.T) {
+	values := url.Values{}
+	values.Add("name", "=HYPERLINK(\"http://evil\")")
+	values.Add("amount", "-10")
+	values.Add("note", "safe")
+
+	if _, err := backend.SubmitForm(base, "csv-formulas", "10.0.0.10", "", values, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := backend.ExportForm(base, "csv-formulas", backend.FormExportCSV, time.Time{}, time.Time{}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(records) != 2 {
+		t.Fatalf("expected a header and 1 row got %v", records)
+	}
+
+	row := make(map[string]string)
+	for i, col := range records[0] {
+		row[col] = records[1][i]
+	}
+
+	if row["name"] != "'=HYPERLINK(\"http://evil\")" {
+		t.Errorf("expected the formula to be escaped got %s", row["name"])
+	} else if row["amount"] != "'-10" {
+		t.Errorf("expected the minus sign to be escaped got %s", row["amount"])
+	} else if row["note"] != "safe" {
+		t.Errorf("expected safe got %s", row["note"])
+	}
+}
+
+func TestExportFormInvalidFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := backend.ExportForm(base, "xlsx-export", "pdf", time.Time{}, time.Time{}, &buf)
+	if !errors.Is(err, backend.ErrInvalidExportFormat) {
+		t.Errorf("expected ErrInvalidExportFormat got %v", err)
+	}
+}
+
+func TestSubmitFormWebhooks(t *testing.T) {
+	received := make(chan webhook.Payload, 2)
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if err := webhook.Verify("form-secret", r.Header.Get("SB-Webhook-Signature"), r.Header.Get("SB-Webhook-Timestamp"), body, webhook.SignatureTolerance); err != nil {
+			t.Error(err)
+		}
+
+		var pl webhook.Payload
+		if err := json.Unmarshal(body, &pl); err != nil {
+			t.Error(err)
+		}
+		received <- pl
+	}))
+	defer ts.Close()
+
+	conf := base
+	conf.Settings.Forms.Webhooks = []model.FormWebhook{
+		{Form: "hooked", URL: ts.URL, Secret: "form-secret"},
+		{Form: "other", URL: ts.URL, Secret: "form-secret"},
+	}
+
+	// the queue reads the settings of the databases
+	if err := backend.DB.UpdateDatabaseSettings(conf.ID, conf.Settings); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.UpdateDatabaseSettings(base.ID, base.Settings)
+
+	values := url.Values{}
+	values.Add("name", "webhook")
+
+	if _, err := backend.SubmitForm(conf, "hooked", "10.0.0.10", "", values, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ProcessWebhookQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := backend.DB.ListWebhookDeliveries(conf.Name, model.WebhookDeliveryFilter{URL: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Fatalf("expected 1 queued delivery for the matching form got %d", len(list))
+	}
+
+	// the failed delivery is retried right away
+	d := list[0]
+	past := time.Now().Add(-time.Second)
+	if err := backend.DB.UpdateWebhookStatus(conf.Name, d.ID, d.Status, d.Attempts, d.StatusCode, d.LastError, past); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ProcessWebhookQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case pl := <-received:
+		data, _ := pl.Data.(map[string]interface{})
+		sub, _ := data["submission"].(map[string]interface{})
+		if pl.Event != model.WebhookFormSubmitted {
+			t.Errorf("expected event %s got %s", model.WebhookFormSubmitted, pl.Event)
+		} else if data["form"] != "hooked" || sub["name"] != "webhook" {
+			t.Errorf("unexpected payload data %v", pl.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	deliveries, err := backend.DB.ListFormDeliveries(base.Name, "hooked", 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(deliveries) != 2 {
+		t.Fatalf("expected 2 logged attempts got %d", len(deliveries))
+	}
+
+	var failed, succeeded int
+	for _, d := range deliveries {
+		if d.Success {
+			succeeded++
+		} else if d.StatusCode == http.StatusBadGateway {
+			failed++
+		}
+	}
+	if failed != 1 || succeeded != 1 {
+		t.Errorf("expected a failed and a successful attempt got %v", deliveries)
+	}
+}
+
+func TestSubmitFormValidation(t *testing.T) {
+	def := model.FormDefinition{
+		Name: "validated",
+		Fields: []model.FormField{
+			{Name: "email", Type: model.FormFieldEmail, Required: true},
+			{Name: "age", Type: model.FormFieldNumber},
+			{Name: "site", Type: model.FormFieldURL},
+			{Name: "birthday", Type: model.FormFieldDate},
+			{Name: "zip", Pattern: "^[0-9]{5}$"},
+			{Name: "bio", MaxLength: 10},
+		},
+	}
+	if err := backend.SaveFormDefinition(base, def); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteFormDefinition(base.Name, "validated")
+
+	tests := []struct {
+		values  url.Values
+		invalid []string
+	}{
+		{url.Values{"email": {"ok@test.com"}, "age": {"42"}, "zip": {"12345"}}, nil},
+		{url.Values{"age": {"42"}}, []string{"email"}},
+		{url.Values{"email": {"not-an-email"}}, []string{"email"}},
+		{url.Values{"email": {"ok@test.com"}, "age": {"old"}, "site": {"nope"}}, []string{"age", "site"}},
+		{url.Values{"email": {"ok@test.com"}, "birthday": {"01/02/2000"}, "zip": {"1234"}}, []string{"birthday", "zip"}},
+		{url.Values{"email": {"ok@test.com"}, "bio": {"this is way too long"}}, []string{"bio"}},
+	}
+
+	for i, tc := range tests {
+		_, err := backend.SubmitForm(base, "validated", fmt.Sprintf("10.0.1.%d", i), "", tc.values, nil)
+
+		var verr *backend.FormValidationError
+		if len(tc.invalid) == 0 {
+			if err != nil {
+				t.Errorf("test %d: expected no error got %v", i, err)
+			}
+			continue
+		} else if !errors.As(err, &verr) {
+			t.Errorf("test %d: expected a FormValidationError got %v", i, err)
+			continue
+		}
+
+		if len(verr.Fields) != len(tc.invalid) {
+			t.Errorf("test %d: expected invalid fields %v got %v", i, tc.invalid, verr.Fields)
+		}
+		for _, name := range tc.invalid {
+			if len(verr.Fields[name]) == 0 {
+				t.Errorf("test %d: expected %s to be invalid got %v", i, name, verr.Fields)
+			}
+		}
+	}
+
+	list, err := backend.DB.ListFormSubmissions(base.Name, "validated")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Errorf("expected only the valid submission to be saved got %d", len(list))
+	}
+}
+
+func TestSaveFormDefinitionInvalid(t *testing.T) {
+	def := model.FormDefinition{
+		Name: "invalid-definition",
+		Fields: []model.FormField{
+			{Name: "a", Type: "color"},
+			{Name: "b", Pattern: "[unclosed"},
+		},
+	}
+
+	var verr *backend.FormValidationError
+	if err := backend.SaveFormDefinition(base, def); !errors.As(err, &verr) {
+		t.Fatalf("expected a FormValidationError got %v", err)
+	} else if len(verr.Fields) != 2 {
+		t.Errorf("expected 2 invalid fields got %v", verr.Fields)
+	}
+}
+
+type capturedMailer chan email.SendMailData
+
+func (c capturedMailer) Send(data email.SendMailData) error {
+	c <- data
+	return nil
+}
+
+func TestSubmitFormAutoResponder(t *testing.T) {
+	sent := make(capturedMailer, 2)
+
+	prev := backend.Emailer
+	backend.Emailer = sent
+	defer func() { backend.Emailer = prev }()
+
+	conf := base
+	conf.Settings.Forms.AutoResponders = []model.FormAutoResponder{
+		{
+			Form:       "responder",
+			Enabled:    true,
+			EmailField: "email",
+			FromEmail:  "noreply@test.com",
+			Subject:    "Thanks [name]",
+			Body:       "<p>Hi [name], we received: [message]</p>",
+		},
+		{Form: "responder", EmailField: "email", Subject: "disabled"},
+	}
+
+	values := url.Values{}
+	values.Add("name", "Dominic")
+	values.Add("email", "dominic@test.com")
+	values.Add("message", "<b>hello</b>")
+
+	if _, err := backend.SubmitForm(conf, "responder", "10.0.2.1", "", values, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case mail := <-sent:
+		if mail.To != "dominic@test.com" {
+			t.Errorf("expected the email to be sent to the submitter got %s", mail.To)
+		} else if mail.Subject != "Thanks Dominic" {
+			t.Errorf("unexpected subject %s", mail.Subject)
+		} else if !strings.Contains(mail.HTMLBody, "&lt;b&gt;hello&lt;/b&gt;") {
+			t.Errorf("expected the submitted values to be escaped got %s", mail.HTMLBody)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the auto-response was not sent")
+	}
+
+	select {
+	case mail := <-sent:
+		t.Errorf("expected the disabled auto-responder to be skipped got %v", mail)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
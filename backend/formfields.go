@@ -0,0 +1,137 @@
+package backend
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// FormValidationError lists the invalid fields of a submission with the
+// reason each one was rejected
+type FormValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *FormValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return "invalid form fields: " + strings.Join(names, ", ")
+}
+
+// SaveFormDefinition validates and saves the field definitions of a form,
+// the submissions are validated against them from then on
+func SaveFormDefinition(conf model.DatabaseConfig, def model.FormDefinition) error {
+	errs := make(map[string]string)
+	seen := make(map[string]bool)
+	for i, f := range def.Fields {
+		if len(f.Name) == 0 {
+			errs[fmt.Sprintf("fields[%d]", i)] = "the name is required"
+			continue
+		} else if seen[f.Name] {
+			errs[f.Name] = "the field is defined more than once"
+			continue
+		}
+		seen[f.Name] = true
+
+		switch f.Type {
+		case "", model.FormFieldText, model.FormFieldEmail, model.FormFieldNumber, model.FormFieldURL, model.FormFieldDate:
+		default:
+			errs[f.Name] = fmt.Sprintf("unknown type %q", f.Type)
+			continue
+		}
+
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			errs[f.Name] = "invalid pattern: " + err.Error()
+		} else if f.MaxLength < 0 {
+			errs[f.Name] = "the max length cannot be negative"
+		}
+	}
+
+	if len(errs) > 0 {
+		return &FormValidationError{Fields: errs}
+	}
+
+	def.Updated = time.Now()
+	return DB.SaveFormDefinition(conf.Name, def)
+}
+
+// validateFormFields checks the submitted values against the form's field
+// definitions. A required field can be satisfied by an attached file.
+func validateFormFields(def model.FormDefinition, values url.Values, files map[string][]*multipart.FileHeader) error {
+	errs := make(map[string]string)
+	for _, f := range def.Fields {
+		var vals []string
+		for _, v := range values[f.Name] {
+			if len(strings.TrimSpace(v)) > 0 {
+				vals = append(vals, v)
+			}
+		}
+
+		if len(vals) == 0 {
+			if f.Required && len(files[f.Name]) == 0 {
+				errs[f.Name] = "is required"
+			}
+			continue
+		}
+
+		for _, v := range vals {
+			if msg := validateFormValue(f, v); len(msg) > 0 {
+				errs[f.Name] = msg
+				break
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &FormValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// validateFormValue returns why the value is invalid for the field, or an
+// empty string
+func validateFormValue(f model.FormField, v string) string {
+	if f.MaxLength > 0 && utf8.RuneCountInString(v) > f.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", f.MaxLength)
+	}
+
+	switch f.Type {
+	case model.FormFieldEmail:
+		if addr, err := mail.ParseAddress(v); err != nil || addr.Address != strings.TrimSpace(v) {
+			return "must be a valid email address"
+		}
+	case model.FormFieldNumber:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+			return "must be a number"
+		}
+	case model.FormFieldURL:
+		if u, err := url.Parse(strings.TrimSpace(v)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return "must be a valid URL"
+		}
+	case model.FormFieldDate:
+		if _, err := time.Parse("2006-01-02", strings.TrimSpace(v)); err != nil {
+			return "must be a date formatted as YYYY-MM-DD"
+		}
+	}
+
+	if len(f.Pattern) > 0 {
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil || !re.MatchString(v) {
+			return "does not match the expected format"
+		}
+	}
+	return ""
+}
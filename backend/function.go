@@ -0,0 +1,13 @@
+package backend
+
+import "github.com/staticbackendhq/core/model"
+
+// FunctionSettings returns the function settings of a database, the limits
+// of the runs and the hosts fetch() can call
+func FunctionSettings(dbName string) (model.FunctionSettings, error) {
+	conf, err := findDatabaseByName(dbName)
+	if err != nil {
+		return model.FunctionSettings{}, err
+	}
+	return conf.Settings.Functions, nil
+}
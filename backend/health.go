@@ -0,0 +1,148 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+// HealthCheckTimeout is the maximum duration of a dependency check
+var HealthCheckTimeout = 5 * time.Second
+
+var (
+	// errHealthSkipped marks a dependency that cannot be checked
+	errHealthSkipped = errors.New("skipped")
+	// errHealthTimeout is returned when a check exceeds HealthCheckTimeout
+	errHealthTimeout = errors.New("check timed out")
+)
+
+// healthProbeKey is the key of the cache value and file written by the
+// readiness checks
+const healthProbeKey = "sb_health/probe"
+
+// CheckReadiness checks the database, the volatile store, the storage and
+// the mailer concurrently and returns their status and latency
+func CheckReadiness() model.HealthReport {
+	checks := map[string]func() error{
+		"database": DB.Ping,
+		"cache":    checkCache,
+		"storage":  checkStorage,
+		"mailer":   checkMailer,
+	}
+
+	report := model.HealthReport{
+		Status: model.HealthStatusOK,
+		Checks: make(map[string]model.HealthCheck),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func() error) {
+			defer wg.Done()
+
+			hc := runHealthCheck(check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = hc
+			if hc.Status == model.HealthStatusError {
+				report.Status = model.HealthStatusError
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	if poolStats != nil {
+		pool := poolStats()
+		report.Pool = &pool
+	}
+
+	return report
+}
+
+func runHealthCheck(check func() error) model.HealthCheck {
+	start := time.Now()
+
+	// the check keeps running in the background once timed out
+	done := make(chan error, 1)
+	go func() {
+		done <- check()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(HealthCheckTimeout):
+		err = errHealthTimeout
+	}
+
+	hc := model.HealthCheck{
+		Status:    model.HealthStatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if errors.Is(err, errHealthSkipped) {
+		hc.Status = model.HealthStatusSkipped
+	} else if err != nil {
+		hc.Status = model.HealthStatusError
+		hc.Error = err.Error()
+	}
+	return hc
+}
+
+func checkCache() error {
+	now := time.Now().String()
+	if err := Cache.Set(healthProbeKey, now); err != nil {
+		return err
+	}
+
+	v, err := Cache.Get(healthProbeKey)
+	if err != nil {
+		return err
+	} else if v != now {
+		return errors.New("the cache returned a stale value")
+	}
+	return nil
+}
+
+// checkStorage saves, reads and deletes a probe file
+func checkStorage() error {
+	data := model.UploadFileData{
+		FileKey: healthProbeKey,
+		File:    bytes.NewReader([]byte("ok")),
+	}
+	if _, err := Filestore.Save(data); err != nil {
+		return err
+	}
+
+	rc, err := Filestore.Open(healthProbeKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(io.Discard, rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	return Filestore.Delete(healthProbeKey)
+}
+
+// checkMailer pings the mailers able to check their provider, the others
+// would have to send an email
+func checkMailer() error {
+	p, ok := Emailer.(email.Pinger)
+	if !ok {
+		return errHealthSkipped
+	}
+	return p.Ping()
+}
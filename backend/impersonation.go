@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+const (
+	// ImpersonationTTL is how long an impersonation token is valid when
+	// the operator does not ask for a duration
+	ImpersonationTTL = time.Hour
+	// ImpersonationMaxTTL is the longest an impersonation token can be
+	// valid
+	ImpersonationMaxTTL = 4 * time.Hour
+)
+
+// ErrInvalidImpersonation is returned when minting an impersonation token
+// without an operator or a reason or for too long
+var ErrInvalidImpersonation = errors.New("an operator, a reason and a duration up to 4 hours are required")
+
+// Impersonate mints a root token of a database for an operator, it's valid
+// for ttl or ImpersonationTTL when ttl is 0. The token is not stored in the
+// database, it expires from the cache.
+func Impersonate(baseID, operator, reason string, ttl time.Duration) (string, model.Impersonation, error) {
+	if ttl == 0 {
+		ttl = ImpersonationTTL
+	}
+
+	if len(operator) == 0 || len(reason) == 0 || ttl < 0 || ttl > ImpersonationMaxTTL {
+		return "", model.Impersonation{}, ErrInvalidImpersonation
+	}
+
+	conf, err := DB.FindDatabase(baseID)
+	if err != nil {
+		return "", model.Impersonation{}, err
+	}
+
+	// the token acts as the database's root user
+	if _, err := DB.GetRootForBase(conf.Name); err != nil {
+		return "", model.Impersonation{}, err
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", model.Impersonation{}, err
+	}
+	token := model.ImpersonationTokenPrefix + hex.EncodeToString(b)
+
+	imp := model.Impersonation{
+		DBName:   conf.Name,
+		TenantID: conf.TenantID,
+		Operator: operator,
+		Reason:   reason,
+		Expires:  time.Now().Add(ttl),
+	}
+
+	key := model.ImpersonationKey(token)
+	if err := Cache.SetTyped(key, imp); err != nil {
+		return "", imp, err
+	} else if err := Cache.Expire(key, ttl); err != nil {
+		return "", imp, err
+	}
+	return token, imp, nil
+}
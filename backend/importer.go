@@ -0,0 +1,332 @@
+package backend
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Import stages reported by the progress callback
+const (
+	ImportStageUsers     = "users"
+	ImportStageDocuments = "documents"
+	ImportStageFiles     = "files"
+)
+
+// importProgressEvery is the number of items between two progress reports
+const importProgressEvery = 100
+
+// maxImportErrors is the number of errors kept in the report, the items in
+// error are skipped and the import continues
+const maxImportErrors = 100
+
+// importSet is an export parsed into the users, documents and storage
+// records to create
+type importSet struct {
+	users []importUser
+	docs  []importDoc
+	files []importFile
+}
+
+type importUser struct {
+	sourceID string
+	email    string
+	// hash is a bcrypt hash, empty when the source one cannot be verified
+	hash string
+}
+
+type importDoc struct {
+	collection string
+	sourceID   string
+	fields     map[string]interface{}
+}
+
+type importFile struct {
+	name     string
+	url      string
+	mimeType string
+	size     int64
+	created  time.Time
+	// owner is the source id of the user who uploaded the file
+	owner string
+}
+
+// importer creates an importSet in a database, the documents and files
+// without an owner go in the account of auth
+type importer struct {
+	auth     model.Auth
+	conf     model.DatabaseConfig
+	mapping  model.ImportMapping
+	progress func(model.ImportProgress)
+	report   model.ImportReport
+
+	// owners are the accounts of the imported users by source id
+	owners map[string]model.Auth
+}
+
+func runImport(auth model.Auth, conf model.DatabaseConfig, set importSet, mapping model.ImportMapping, progress func(model.ImportProgress)) model.ImportReport {
+	if progress == nil {
+		progress = func(model.ImportProgress) {}
+	}
+
+	imp := &importer{
+		auth:     auth,
+		conf:     conf,
+		mapping:  mapping,
+		progress: progress,
+		report:   model.ImportReport{Documents: make(map[string]int)},
+		owners:   make(map[string]model.Auth),
+	}
+
+	imp.each(ImportStageUsers, len(set.users), func(i int) error {
+		return imp.createUser(set.users[i])
+	})
+
+	skip := make(map[string]bool)
+	for _, col := range mapping.Skip {
+		skip[col] = true
+	}
+
+	var docs []importDoc
+	for _, doc := range set.docs {
+		if !skip[doc.collection] {
+			docs = append(docs, doc)
+		}
+	}
+
+	imp.each(ImportStageDocuments, len(docs), func(i int) error {
+		return imp.createDocument(docs[i])
+	})
+
+	imp.each(ImportStageFiles, len(set.files), func(i int) error {
+		return imp.createFile(set.files[i])
+	})
+
+	return imp.report
+}
+
+// each calls fn for the total items of a stage and reports the progress
+func (imp *importer) each(stage string, total int, fn func(i int) error) {
+	p := model.ImportProgress{Stage: stage, Total: total}
+	for i := 0; i < total; i++ {
+		if err := fn(i); err != nil {
+			p.Errors++
+			if len(imp.report.Errors) < maxImportErrors {
+				imp.report.Errors = append(imp.report.Errors, fmt.Sprintf("%s %d: %v", stage, i+1, err))
+			}
+		}
+
+		p.Done = i + 1
+		if p.Done%importProgressEvery == 0 && p.Done < total {
+			imp.progress(p)
+		}
+	}
+	imp.progress(p)
+}
+
+// createUser creates the user in its own account, users whose email exists
+// are kept and own the documents of their source id
+func (imp *importer) createUser(u importUser) error {
+	email := strings.ToLower(strings.TrimSpace(u.email))
+	if len(email) == 0 {
+		return fmt.Errorf("user %s has no email", u.sourceID)
+	}
+
+	exists, err := DB.UserEmailExists(imp.conf.Name, email)
+	if err != nil {
+		return err
+	} else if exists {
+		tok, err := DB.FindUserByEmail(imp.conf.Name, email)
+		if err != nil {
+			return err
+		}
+
+		imp.owners[u.sourceID] = userAuth(tok)
+		imp.report.UsersSkipped++
+		return nil
+	}
+
+	hash := u.hash
+	if len(hash) == 0 {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+
+		h, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(b)), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+
+		hash = string(h)
+		imp.report.PasswordResets++
+	}
+
+	acctID, err := DB.CreateAccount(imp.conf.Name, email)
+	if err != nil {
+		return err
+	}
+
+	// the users are account admins like the ones who register
+	tok := model.User{
+		AccountID: acctID,
+		Email:     email,
+		Token:     DB.NewID(),
+		Password:  hash,
+		Role:      50,
+	}
+
+	tok.ID, err = DB.CreateUser(imp.conf.Name, tok)
+	if err != nil {
+		return err
+	}
+
+	imp.owners[u.sourceID] = userAuth(tok)
+	imp.report.Users++
+	return nil
+}
+
+func userAuth(tok model.User) model.Auth {
+	return model.Auth{
+		AccountID: tok.AccountID,
+		UserID:    tok.ID,
+		Email:     tok.Email,
+		Role:      tok.Role,
+		Token:     tok.Token,
+	}
+}
+
+func (imp *importer) createDocument(doc importDoc) error {
+	mc := imp.mapping.Collections[doc.collection]
+
+	col := doc.collection
+	if len(mc.Name) > 0 {
+		col = mc.Name
+	}
+
+	if strings.HasPrefix(col, "sb_") {
+		return fmt.Errorf("collection %s is reserved", col)
+	}
+
+	auth := imp.auth
+	if len(mc.Owner) > 0 {
+		if owner, ok := imp.owners[fmt.Sprint(doc.fields[mc.Owner])]; ok {
+			auth = owner
+		}
+	}
+
+	fields := make(map[string]interface{})
+	for k, v := range doc.fields {
+		if t, ok := mc.Types[k]; ok {
+			cv, err := convertImportValue(v, t)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", doc.collection, k, err)
+			}
+			v = cv
+		}
+
+		if name, ok := mc.Fields[k]; ok {
+			if len(name) == 0 {
+				continue
+			}
+			k = name
+		}
+
+		// the id and account are assigned by the database
+		if k == "id" || k == "accountId" {
+			continue
+		}
+
+		fields[k] = v
+	}
+
+	if len(imp.mapping.SourceIDField) > 0 {
+		fields[imp.mapping.SourceIDField] = doc.sourceID
+	}
+
+	if _, err := DB.CreateDocument(auth, imp.conf.Name, col, fields); err != nil {
+		return err
+	}
+
+	imp.report.Documents[col]++
+	return nil
+}
+
+// createFile records a file stored by the source, its content is not copied
+func (imp *importer) createFile(f importFile) error {
+	url := f.url
+	if len(url) == 0 {
+		if len(imp.mapping.StorageURL) == 0 {
+			return fmt.Errorf("file %s has no URL and the mapping has no storageUrl", f.name)
+		}
+		url = strings.TrimSuffix(imp.mapping.StorageURL, "/") + "/" + f.name
+	}
+
+	created := f.created
+	if created.IsZero() {
+		created = time.Now()
+	}
+
+	acctID := imp.auth.AccountID
+	if owner, ok := imp.owners[f.owner]; ok {
+		acctID = owner.AccountID
+	}
+
+	file := model.File{
+		AccountID: acctID,
+		Key:       f.name,
+		URL:       url,
+		Size:      f.size,
+		Uploaded:  created,
+		Name:      path.Base(f.name),
+		MimeType:  f.mimeType,
+	}
+
+	if _, err := DB.AddFile(imp.conf.Name, file); err != nil {
+		return err
+	}
+
+	imp.report.Files++
+	return nil
+}
+
+// convertImportValue converts the text values to typ, the values already
+// typed by the source are kept
+func convertImportValue(v interface{}, typ string) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+
+	switch typ {
+	case model.ImportTypeNumber:
+		return strconv.ParseFloat(s, 64)
+	case model.ImportTypeBool:
+		return strconv.ParseBool(s)
+	case model.ImportTypeJSON:
+		var out interface{}
+		err := json.Unmarshal([]byte(s), &out)
+		return out, err
+	case model.ImportTypeTime:
+		return parseImportTime(s)
+	}
+	return nil, fmt.Errorf("unknown type %s", typ)
+}
+
+// parseImportTime parses RFC 3339 and PostgreSQL timestamps
+func parseImportTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999-07", "2006-01-02 15:04:05.999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %s", s)
+}
@@ -0,0 +1,163 @@
+package backend_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+const supabaseDump = `--
+-- Data for Name: users; Type: TABLE DATA; Schema: auth; Owner: supabase_auth_admin
+--
+
+COPY "auth"."users" ("id", "email", "encrypted_password", "created_at") FROM stdin;
+u-1	sb-one@import.com	$2a$10$3Kz8z0yq5KMu2z0b7OQpUOcLQ0uLwGVr1T8jdbFUbvqyZ5vTQ1rXy	2023-01-05 10:11:12.123456+00
+u-2	sb-two@import.com	\N	2023-01-06 10:11:12+00
+\.
+
+COPY "public"."todos" ("id", "title", "done", "user_id", "notes") FROM stdin;
+1	first\ttodo	t	u-1	\N
+2	second	f	u-2	line\nbreak
+\.
+
+COPY "storage"."objects" ("id", "bucket_id", "name", "owner", "created_at", "metadata") FROM stdin;
+o-1	avatars	u-1/me.png	u-1	2023-01-07 10:11:12+00	{"size": 1234, "mimetype": "image/png"}
+\.
+`
+
+func TestImportSupabase(t *testing.T) {
+	mapping := model.ImportMapping{
+		Collections: map[string]model.ImportCollection{
+			"todos": {
+				Name:   "imported_todos",
+				Fields: map[string]string{"notes": "", "user_id": "owner"},
+				Types:  map[string]string{"done": model.ImportTypeBool},
+				Owner:  "user_id",
+			},
+		},
+		SourceIDField: "sourceId",
+		StorageURL:    "https://example.supabase.co/storage/v1/object/public",
+	}
+
+	var progress []model.ImportProgress
+	report, err := backend.ImportSupabase(adminAuth, base, strings.NewReader(supabaseDump), mapping, func(p model.ImportProgress) {
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(report.Errors) > 0 {
+		t.Fatal(report.Errors)
+	}
+
+	if report.Users != 2 || report.PasswordResets != 1 {
+		t.Errorf("expected 2 users with 1 password reset got %v", report)
+	} else if report.Documents["imported_todos"] != 2 || report.Files != 1 {
+		t.Errorf("expected 2 todos and 1 file got %v", report)
+	} else if len(progress) != 3 || progress[1].Stage != backend.ImportStageDocuments || progress[1].Done != 2 {
+		t.Errorf("expected a progress report per stage got %v", progress)
+	}
+
+	tok, err := backend.DB.FindUserByEmail(base.Name, "sb-one@import.com")
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.HasPrefix(tok.Password, "$2a$10$3Kz8") {
+		t.Error("expected the bcrypt password to be kept")
+	}
+
+	owner := model.Auth{AccountID: tok.AccountID, UserID: tok.ID, Role: tok.Role}
+	lp := model.ListParams{Page: 1, Size: 10}
+	docs, err := backend.DB.ListDocuments(owner, base.Name, "imported_todos", lp)
+	if err != nil {
+		t.Fatal(err)
+	} else if docs.Total != 1 {
+		t.Fatalf("expected 1 todo owned by the user got %d", docs.Total)
+	}
+
+	todo := docs.Results[0]
+	if todo["title"] != "first\ttodo" || todo["done"] != true || todo["owner"] != "u-1" || todo["sourceId"] != "1" {
+		t.Errorf("unexpected imported todo %v", todo)
+	} else if _, ok := todo["notes"]; ok {
+		t.Error("expected the notes field to be dropped")
+	}
+
+	files, err := backend.DB.ListFiles(base.Name, model.FileFilter{AccountID: tok.AccountID})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 || files[0].Size != 1234 || files[0].URL != mapping.StorageURL+"/avatars/u-1/me.png" {
+		t.Errorf("unexpected imported files %v", files)
+	}
+
+	if _, err := backend.ImportSupabase(adminAuth, base, strings.NewReader("INSERT INTO todos VALUES (1);"), mapping, nil); err == nil {
+		t.Error("expected an error for a dump without COPY statements")
+	}
+}
+
+const firestoreExport = `{"__collections__": {
+	"posts": {
+		"p1": {
+			"title": "hello",
+			"author": "fb-1",
+			"published": {"__datatype__": "timestamp", "value": {"_seconds": 1672531200, "_nanoseconds": 0}},
+			"__collections__": {"comments": {"c1": {"text": "nice"}}}
+		}
+	},
+	"secrets": {"s1": {"key": "value"}}
+}}`
+
+func TestImportFirebase(t *testing.T) {
+	if _, _, err := backend.Membership(base).CreateAccountAndUser("fb-two@import.com", "fb-two1234", 50); err != nil {
+		t.Fatal(err)
+	}
+
+	users := `{"users": [
+		{"localId": "fb-1", "email": "fb-one@import.com", "passwordHash": "abc=="},
+		{"localId": "fb-2", "email": "FB-Two@import.com"},
+		{"localId": "fb-3"}
+	]}`
+
+	mapping := model.ImportMapping{
+		Collections: map[string]model.ImportCollection{
+			"posts": {Name: "fb_posts", Owner: "author"},
+		},
+		Skip: []string{"secrets"},
+	}
+
+	report, err := backend.ImportFirebase(adminAuth, base, strings.NewReader(users), strings.NewReader(firestoreExport), nil, mapping, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Users != 1 || report.UsersSkipped != 1 || report.PasswordResets != 1 {
+		t.Errorf("expected 1 user created and 1 skipped got %v", report)
+	} else if len(report.Errors) != 1 {
+		t.Errorf("expected an error for the user without email got %v", report.Errors)
+	} else if report.Documents["fb_posts"] != 1 || report.Documents["posts_comments"] != 1 {
+		t.Errorf("expected the post and its comment got %v", report.Documents)
+	} else if _, ok := report.Documents["secrets"]; ok {
+		t.Error("expected the skipped collection to not be imported")
+	}
+
+	tok, err := backend.DB.FindUserByEmail(base.Name, "fb-one@import.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := model.Auth{AccountID: tok.AccountID, UserID: tok.ID, Role: tok.Role}
+	docs, err := backend.DB.ListDocuments(owner, base.Name, "fb_posts", model.ListParams{Page: 1, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	} else if docs.Total != 1 {
+		t.Fatalf("expected the post to be owned by its author got %d", docs.Total)
+	} else if _, ok := docs.Results[0]["published"]; !ok {
+		t.Error("expected the timestamp to be imported")
+	}
+
+	comments, err := backend.DB.ListDocuments(adminAuth, base.Name, "posts_comments", model.ListParams{Page: 1, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	} else if comments.Total != 1 || comments.Results[0]["parentId"] != "p1" {
+		t.Errorf("expected the comment to reference its post got %v", comments.Results)
+	}
+}
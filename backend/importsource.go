@@ -0,0 +1,334 @@
+package backend
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// ImportFirebase imports a Firebase project in a database. Each reader is
+// optional:
+//
+//   - users is a Firebase Auth export in JSON (firebase auth:export)
+//   - firestore is a Firestore export in JSON where collections are under
+//     "__collections__" keyed by document id, subcollections are imported
+//     in parent_sub collections with a parentId field
+//   - storage is the JSON array of the Cloud Storage objects of the bucket
+//     (name, size, contentType, timeCreated, mediaLink)
+//
+// Firebase password hashes cannot be verified, the users must reset their
+// password. Documents and files without an owner go in auth's account.
+func ImportFirebase(auth model.Auth, conf model.DatabaseConfig, users, firestore, storage io.Reader, mapping model.ImportMapping, progress func(model.ImportProgress)) (model.ImportReport, error) {
+	var set importSet
+
+	if users != nil {
+		var export struct {
+			Users []struct {
+				LocalID string `json:"localId"`
+				Email   string `json:"email"`
+			} `json:"users"`
+		}
+		if err := json.NewDecoder(users).Decode(&export); err != nil {
+			return model.ImportReport{}, fmt.Errorf("invalid Firebase Auth export: %w", err)
+		}
+
+		for _, u := range export.Users {
+			set.users = append(set.users, importUser{sourceID: u.LocalID, email: u.Email})
+		}
+	}
+
+	if firestore != nil {
+		var export struct {
+			Collections map[string]map[string]map[string]interface{} `json:"__collections__"`
+		}
+		if err := json.NewDecoder(firestore).Decode(&export); err != nil {
+			return model.ImportReport{}, fmt.Errorf("invalid Firestore export: %w", err)
+		}
+
+		set.docs = firestoreDocs("", "", export.Collections)
+	}
+
+	if storage != nil {
+		var objects []struct {
+			Name        string      `json:"name"`
+			Size        json.Number `json:"size"`
+			ContentType string      `json:"contentType"`
+			TimeCreated time.Time   `json:"timeCreated"`
+			MediaLink   string      `json:"mediaLink"`
+		}
+		if err := json.NewDecoder(storage).Decode(&objects); err != nil {
+			return model.ImportReport{}, fmt.Errorf("invalid Cloud Storage listing: %w", err)
+		}
+
+		for _, o := range objects {
+			size, _ := o.Size.Int64()
+			set.files = append(set.files, importFile{
+				name:     o.Name,
+				url:      o.MediaLink,
+				mimeType: o.ContentType,
+				size:     size,
+				created:  o.TimeCreated,
+			})
+		}
+	}
+
+	return runImport(auth, conf, set, mapping, progress), nil
+}
+
+// firestoreDocs flattens the collections and their subcollections, parent
+// is the collection and parentID the document owning them
+func firestoreDocs(parent, parentID string, cols map[string]map[string]map[string]interface{}) []importDoc {
+	var docs []importDoc
+	for name, col := range cols {
+		if len(parent) > 0 {
+			name = parent + "_" + name
+		}
+
+		for id, fields := range col {
+			doc := importDoc{collection: name, sourceID: id, fields: make(map[string]interface{})}
+			for k, v := range fields {
+				if k == "__collections__" {
+					continue
+				}
+				doc.fields[k] = firestoreValue(v)
+			}
+
+			if len(parentID) > 0 {
+				if _, ok := doc.fields["parentId"]; !ok {
+					doc.fields["parentId"] = parentID
+				}
+			}
+			docs = append(docs, doc)
+
+			if sub, ok := fields["__collections__"]; ok {
+				b, err := json.Marshal(sub)
+				if err != nil {
+					continue
+				}
+
+				var subCols map[string]map[string]map[string]interface{}
+				if err := json.Unmarshal(b, &subCols); err != nil {
+					continue
+				}
+				docs = append(docs, firestoreDocs(name, id, subCols)...)
+			}
+		}
+	}
+	return docs
+}
+
+// firestoreValue converts the Firestore types of the export, timestamps,
+// geopoints and document references, to plain values
+func firestoreValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		dt, ok := val["__datatype__"].(string)
+		if !ok {
+			for k, item := range val {
+				val[k] = firestoreValue(item)
+			}
+			return val
+		}
+
+		inner, _ := val["value"].(map[string]interface{})
+		switch dt {
+		case "timestamp":
+			secs, _ := inner["_seconds"].(float64)
+			nanos, _ := inner["_nanoseconds"].(float64)
+			return time.Unix(int64(secs), int64(nanos)).UTC()
+		case "geopoint":
+			return map[string]interface{}{"lat": inner["_latitude"], "lng": inner["_longitude"]}
+		}
+		return val["value"]
+	case []interface{}:
+		for i, item := range val {
+			val[i] = firestoreValue(item)
+		}
+	}
+	return v
+}
+
+// ImportSupabase imports a Supabase database dumped with COPY statements
+// (supabase db dump --data-only --use-copy). The auth.users table is
+// imported as users, keeping their bcrypt password, the tables of the
+// public schema as collections and storage.objects as files named
+// bucket/name. The values are text, the mapping types convert them.
+func ImportSupabase(auth model.Auth, conf model.DatabaseConfig, dump io.Reader, mapping model.ImportMapping, progress func(model.ImportProgress)) (model.ImportReport, error) {
+	var set importSet
+
+	found := false
+	err := eachCopyRow(dump, func(schema, table string, row map[string]interface{}) {
+		found = true
+
+		switch {
+		case schema == "auth" && table == "users":
+			email, _ := row["email"].(string)
+			hash, _ := row["encrypted_password"].(string)
+			if !strings.HasPrefix(hash, "$2") {
+				hash = ""
+			}
+			set.users = append(set.users, importUser{sourceID: copyText(row["id"]), email: email, hash: hash})
+		case schema == "storage" && table == "objects":
+			set.files = append(set.files, supabaseFile(row))
+		case schema == "public":
+			set.docs = append(set.docs, importDoc{collection: table, sourceID: copyText(row["id"]), fields: row})
+		}
+	})
+	if err != nil {
+		return model.ImportReport{}, err
+	} else if !found {
+		return model.ImportReport{}, errors.New("no COPY statement found, dump the data with --use-copy")
+	}
+
+	return runImport(auth, conf, set, mapping, progress), nil
+}
+
+func supabaseFile(row map[string]interface{}) importFile {
+	f := importFile{
+		name:  path.Join(copyText(row["bucket_id"]), copyText(row["name"])),
+		owner: copyText(row["owner"]),
+	}
+
+	if t, err := parseImportTime(copyText(row["created_at"])); err == nil {
+		f.created = t
+	}
+
+	var meta struct {
+		Size     int64  `json:"size"`
+		MimeType string `json:"mimetype"`
+	}
+	if err := json.Unmarshal([]byte(copyText(row["metadata"])), &meta); err == nil {
+		f.size, f.mimeType = meta.Size, meta.MimeType
+	}
+	return f
+}
+
+func copyText(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// eachCopyRow calls fn for the rows of the COPY ... FROM stdin blocks of a
+// PostgreSQL dump, NULL values are nil and the others text
+func eachCopyRow(r io.Reader, fn func(schema, table string, row map[string]interface{})) error {
+	br := bufio.NewReader(r)
+
+	var (
+		schema, table string
+		columns       []string
+		inCopy        bool
+	)
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case inCopy && line == `\.`:
+			inCopy = false
+		case inCopy:
+			values := strings.Split(line, "\t")
+			if len(values) != len(columns) {
+				return fmt.Errorf("%s.%s: expected %d values got %d", schema, table, len(columns), len(values))
+			}
+
+			row := make(map[string]interface{})
+			for i, col := range columns {
+				if values[i] == `\N` {
+					row[col] = nil
+				} else {
+					row[col] = unescapeCopy(values[i])
+				}
+			}
+			fn(schema, table, row)
+		case strings.HasPrefix(line, "COPY ") && strings.HasSuffix(line, "FROM stdin;"):
+			schema, table, columns = parseCopyHeader(line)
+			inCopy = true
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// parseCopyHeader parses COPY "schema"."table" ("col", ...) FROM stdin;
+func parseCopyHeader(line string) (schema, table string, columns []string) {
+	line = strings.TrimPrefix(line, "COPY ")
+
+	open, end := strings.Index(line, "("), strings.LastIndex(line, ")")
+	if open < 0 || end < open {
+		return
+	}
+
+	name := strings.Split(strings.TrimSpace(line[:open]), ".")
+	if len(name) == 2 {
+		schema, table = unquoteIdent(name[0]), unquoteIdent(name[1])
+	} else {
+		schema, table = "public", unquoteIdent(name[0])
+	}
+
+	for _, col := range strings.Split(line[open+1:end], ",") {
+		columns = append(columns, unquoteIdent(strings.TrimSpace(col)))
+	}
+	return
+}
+
+func unquoteIdent(s string) string {
+	return strings.ReplaceAll(strings.Trim(s, `"`), `""`, `"`)
+}
+
+// unescapeCopy decodes the backslash escapes of the COPY text format
+func unescapeCopy(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; c {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		default:
+			if c >= '0' && c <= '7' {
+				j := i
+				for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+					j++
+				}
+				n, _ := strconv.ParseUint(s[i:j], 8, 8)
+				sb.WriteByte(byte(n))
+				i = j - 1
+				continue
+			}
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
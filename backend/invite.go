@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// InviteData invitation to join an account sent by email
+type InviteData struct {
+	Email string `json:"email"`
+	Role  int    `json:"role"`
+	// Note is a short message from the inviter, it is HTML escaped
+	Note string `json:"note"`
+	Link string `json:"link"`
+	// Locale of the email, like fr-CA
+	Locale string `json:"locale"`
+}
+
+const (
+	// InviteValidity is how long an invitation can be accepted
+	InviteValidity = 7 * 24 * time.Hour
+	// InviteNoteMaxLength is the maximum length of an invitation's note
+	InviteNoteMaxLength = 500
+)
+
+// Invite creates a pending invitation for the inviter's account and emails a
+// signed link to the invitee. The "invite" email template is rendered in the
+// data's locale with the link, email, role and note variables and sent from
+// the instance's sender.
+func (u User) Invite(auth model.Auth, data InviteData) (model.Invite, error) {
+	data.Email = strings.ToLower(data.Email)
+
+	if data.Role < 0 || data.Role >= 100 || data.Role > auth.Role {
+		return model.Invite{}, errors.New("invalid role")
+	} else if len([]rune(data.Note)) > InviteNoteMaxLength {
+		return model.Invite{}, fmt.Errorf("note cannot exceed %d characters", InviteNoteMaxLength)
+	}
+
+	exists, err := DB.UserEmailExists(u.conf.Name, data.Email)
+	if err != nil {
+		return model.Invite{}, err
+	} else if exists {
+		return model.Invite{}, errors.New("email already in use")
+	}
+
+	now := time.Now()
+	inv := model.Invite{
+		AccountID: auth.AccountID,
+		Email:     data.Email,
+		Role:      data.Role,
+		InvitedBy: auth.UserID,
+		Created:   now,
+		Expires:   now.Add(InviteValidity),
+	}
+
+	id, err := DB.AddInvite(u.conf.Name, inv)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	inv.ID = id
+
+	v := url.Values{}
+	v.Set("id", id)
+	v.Set("token", signInvite(inv))
+	link := data.Link + "?" + v.Encode()
+
+	// the invitee has no stored locale yet
+	tmpl, _, err := localizedTemplate(u.conf, model.EmailTemplateInvite, []string{data.Locale})
+	if err != nil {
+		return inv, err
+	}
+
+	vars := map[string]any{"link": link, "email": data.Email, "role": data.Role, "note": data.Note}
+	return inv, sendEmailTemplate(u.conf, tmpl, mailTo(data.Email), vars)
+}
+
+// AcceptInvite validates an invitation token and creates the user in the
+// inviter's account with the invited role. It returns a session token.
+func (u User) AcceptInvite(id, token, password string) (string, error) {
+	inv, err := DB.GetInvite(u.conf.Name, id)
+	if err != nil {
+		return "", errors.New("invalid invitation")
+	}
+
+	if !hmac.Equal([]byte(token), []byte(signInvite(inv))) {
+		return "", errors.New("invalid invitation")
+	} else if time.Now().After(inv.Expires) {
+		return "", errors.New("invitation expired")
+	}
+
+	jwtBytes, _, err := u.CreateUser(inv.AccountID, inv.Email, password, inv.Role)
+	if err != nil {
+		return "", err
+	}
+
+	if err := DB.DeleteInvite(u.conf.Name, inv.ID); err != nil {
+		return "", err
+	}
+
+	return string(jwtBytes), nil
+}
+
+// signInvite returns the HMAC of the invitation fields that cannot be
+// changed by the invitee
+func signInvite(inv model.Invite) string {
+	mac := hmac.New(sha256.New, []byte(Config.AppSecret))
+	fmt.Fprintf(mac, "%s|%s|%s|%d|%d", inv.ID, inv.AccountID, inv.Email, inv.Role, inv.Expires.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"sync"
+
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+// databaseMailers holds the mailers of the databases sending emails with
+// their own credentials by database ID, a SMTP connection is reused
+// between emails
+var databaseMailers sync.Map
+
+type databaseMailerEntry struct {
+	settings model.EmailSettings
+	mailer   email.Mailer
+}
+
+// Mailer returns the mailer sending the emails of a database, the
+// instance's Emailer unless the database's settings select its own mail
+// provider
+func Mailer(conf model.DatabaseConfig) email.Mailer {
+	s := conf.Settings.Email
+	if len(s.Provider) == 0 {
+		return Emailer
+	}
+
+	// the credentials can reference secrets of the app's vault
+	s.APIKey = ResolveSecret(conf, s.APIKey)
+	s.APISecret = ResolveSecret(conf, s.APISecret)
+	s.Password = ResolveSecret(conf, s.Password)
+
+	if v, ok := databaseMailers.Load(conf.ID); ok {
+		if entry := v.(databaseMailerEntry); entry.settings == s {
+			return entry.mailer
+		}
+	}
+
+	mailer, err := email.NewMailer(s)
+	if err != nil {
+		Log.Warn().Err(err).Msgf("invalid mail provider for database %s", conf.Name)
+		return Emailer
+	}
+
+	databaseMailers.Store(conf.ID, databaseMailerEntry{settings: s, mailer: mailer})
+	return mailer
+}
+
+// databaseMailer returns the mailer queuing the emails of a database by its
+// name
+func databaseMailer(dbName string) email.Mailer {
+	conf, err := findDatabaseByName(dbName)
+	if err != nil {
+		return Emailer
+	}
+	return QueuedMailer(conf)
+}
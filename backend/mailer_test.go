@@ -0,0 +1,31 @@
+package backend_test
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestMailerPerDatabase(t *testing.T) {
+	if m := backend.Mailer(base); m != backend.Emailer {
+		t.Errorf("expected the instance mailer got %T", m)
+	}
+
+	conf := base
+	conf.Settings.Email = model.EmailSettings{Provider: email.MailProviderPostmark, APIKey: "token"}
+
+	pm, ok := backend.Mailer(conf).(email.Postmark)
+	if !ok {
+		t.Fatalf("expected the Postmark mailer got %T", backend.Mailer(conf))
+	} else if pm.ServerToken != "token" {
+		t.Errorf("unexpected server token %s", pm.ServerToken)
+	}
+
+	// an invalid provider falls back to the instance mailer
+	conf.Settings.Email = model.EmailSettings{Provider: email.MailProviderSendGrid}
+	if m := backend.Mailer(conf); m != backend.Emailer {
+		t.Errorf("expected the instance mailer got %T", m)
+	}
+}
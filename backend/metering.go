@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// UsageFlushInterval is how often each instance writes the usage it
+// metered, the stats and billing usage lag by up to this interval
+const UsageFlushInterval = time.Minute
+
+// DailyUsage returns the metered usage of a database per metric over the
+// last days days, the current one included
+func DailyUsage(conf model.DatabaseConfig, days int) (map[string][]model.UsagePoint, error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	first := today.AddDate(0, 0, -(days - 1))
+
+	records, err := DB.ListUsage(model.UsageFilter{
+		TenantID: conf.TenantID,
+		DBName:   conf.Name,
+		Since:    first.Format("2006-01-02"),
+		Until:    today.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int64)
+	for _, u := range records {
+		values[u.Metric+":"+u.Day] += u.Value
+	}
+
+	usage := make(map[string][]model.UsagePoint)
+	for _, metric := range model.Meters {
+		for i := 0; i < days; i++ {
+			day := first.AddDate(0, 0, i).Format("2006-01-02")
+			usage[metric] = append(usage[metric], model.UsagePoint{
+				Period: day,
+				Value:  values[metric+":"+day],
+			})
+		}
+	}
+	return usage, nil
+}
+
+// TenantUsage returns the metered usage of all the tenant's databases per
+// metric for the month of t
+func TenantUsage(tenantID string, t time.Time) (map[string]int64, error) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+
+	records, err := DB.ListUsage(model.UsageFilter{
+		TenantID: tenantID,
+		Since:    start.Format("2006-01-02"),
+		Until:    end.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]int64)
+	for _, metric := range model.Meters {
+		usage[metric] = 0
+	}
+	for _, u := range records {
+		usage[u.Metric] += u.Value
+	}
+	return usage, nil
+}
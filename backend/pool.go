@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// defaultMongoPoolSize is the MongoDB driver's default maximum pool size
+const defaultMongoPoolSize = 100
+
+// poolStats returns the state of the database connections pool, it's nil
+// for the memory database
+var poolStats func() model.DatabasePool
+
+// sqlPoolStats returns the state of a PostgreSQL or SQLite pool
+func sqlPoolStats(dbConn *sql.DB) func() model.DatabasePool {
+	return func() model.DatabasePool {
+		s := dbConn.Stats()
+		return model.DatabasePool{
+			MaxOpen:   s.MaxOpenConnections,
+			Open:      s.OpenConnections,
+			InUse:     s.InUse,
+			Idle:      s.Idle,
+			WaitCount: s.WaitCount,
+			WaitMS:    float64(s.WaitDuration.Microseconds()) / 1000,
+		}
+	}
+}
+
+// mongoPool counts the connections of the MongoDB pool from its events,
+// the driver does not expose its pool
+type mongoPool struct {
+	maxOpen int
+	open    int64
+	inUse   int64
+}
+
+func (p *mongoPool) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				atomic.AddInt64(&p.open, 1)
+			case event.ConnectionClosed:
+				atomic.AddInt64(&p.open, -1)
+			case event.GetSucceeded:
+				atomic.AddInt64(&p.inUse, 1)
+			case event.ConnectionReturned:
+				atomic.AddInt64(&p.inUse, -1)
+			}
+		},
+	}
+}
+
+func (p *mongoPool) stats() model.DatabasePool {
+	maxOpen := p.maxOpen
+	if maxOpen <= 0 {
+		maxOpen = defaultMongoPoolSize
+	}
+
+	open, inUse := int(atomic.LoadInt64(&p.open)), int(atomic.LoadInt64(&p.inUse))
+	return model.DatabasePool{
+		MaxOpen: maxOpen,
+		Open:    open,
+		InUse:   inUse,
+		Idle:    open - inUse,
+	}
+}
@@ -0,0 +1,215 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/extra"
+	"github.com/staticbackendhq/core/metering"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/storage"
+)
+
+var (
+	// ErrPresignNotSupported is returned when the storage provider does not
+	// accept direct uploads, i.e. the local storage
+	ErrPresignNotSupported = storage.ErrPresignNotSupported
+	// ErrInvalidUploadSignature is returned when confirming an upload with a
+	// key that was not presigned for the user
+	ErrInvalidUploadSignature = errors.New("invalid upload signature")
+)
+
+// PresignedUpload is an upload sent by the client directly to the storage
+// provider. Once the content is sent, ConfirmUpload records the file with
+// the key and signature.
+type PresignedUpload struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	Key       string            `json:"key"`
+	Signature string            `json:"signature"`
+	Expires   time.Time         `json:"expires"`
+}
+
+// PresignUpload returns a presigned upload for a file of size bytes. The
+// upload restrictions are checked from the size and the file extension
+// and once more from the content when confirming the upload.
+func (f FileStore) PresignUpload(filename, name string, size int64, validity time.Duration) (pu PresignedUpload, err error) {
+	presigner, ok := Filestore.(storage.Presigner)
+	if !ok {
+		return pu, ErrPresignNotSupported
+	}
+
+	settings := f.conf.Settings.Uploads
+	if settings.MaxFileSize > 0 && size > settings.MaxFileSize {
+		return pu, &UploadError{
+			Code:        UploadErrFileTooLarge,
+			Message:     fmt.Sprintf("file size exceeds the %d bytes limit", settings.MaxFileSize),
+			MaxFileSize: settings.MaxFileSize,
+		}
+	}
+
+	mimeType := baseMimeType(mime.TypeByExtension(filepath.Ext(filename)))
+	if len(settings.AllowedTypes) > 0 && !typeAllowed(settings.AllowedTypes, mimeType) {
+		return pu, &UploadError{
+			Code:         UploadErrFileTypeNotAllowed,
+			Message:      fmt.Sprintf("file type %s is not allowed", mimeType),
+			MimeType:     mimeType,
+			AllowedTypes: settings.AllowedTypes,
+		}
+	}
+
+	if err = checkQuota(f.conf, size); err != nil {
+		return
+	}
+
+	if validity <= 0 {
+		validity = SignedURLValidity
+	} else if validity > MaxSignedURLValidity {
+		validity = MaxSignedURLValidity
+	}
+
+	_, fileKey := f.newFileKey(filename, name, false)
+
+	url, headers, err := presigner.PresignUpload(fileKey, mimeType, validity)
+	if err != nil {
+		return
+	}
+
+	pu.URL = url
+	pu.Method = "PUT"
+	pu.Headers = make(map[string]string)
+	// S3 returns the signed headers in lower case, not canonical keys
+	for k, v := range headers {
+		pu.Headers[k] = strings.Join(v, ",")
+	}
+	pu.Key = fileKey
+	pu.Signature = signUploadKey(fileKey)
+	pu.Expires = time.Now().Add(validity)
+	return
+}
+
+// ConfirmUpload records a file uploaded via PresignUpload. A file not
+// respecting the upload restrictions is removed from the storage.
+func (f FileStore) ConfirmUpload(fileKey, signature, filename string, tags []string) (sf SavedFile, err error) {
+	presigner, ok := Filestore.(storage.Presigner)
+	if !ok {
+		return sf, ErrPresignNotSupported
+	}
+
+	prefix := f.fileKey("", false)
+	if !strings.HasPrefix(fileKey, prefix) ||
+		!hmac.Equal([]byte(signature), []byte(signUploadKey(fileKey))) {
+		return sf, ErrInvalidUploadSignature
+	}
+
+	if len(filename) == 0 {
+		filename = path.Base(fileKey)
+	}
+
+	size, url, err := presigner.Stat(fileKey)
+	if err != nil {
+		return
+	}
+
+	head, err := readHead(fileKey)
+	if err != nil {
+		return
+	}
+
+	if err = checkUpload(f.conf.Settings.Uploads, filename, bytes.NewReader(head), size); err != nil {
+		Filestore.Delete(fileKey)
+		return
+	}
+
+	// the size was declared when presigning the upload
+	if err = checkQuota(f.conf, size); err != nil {
+		Filestore.Delete(fileKey)
+		return
+	}
+
+	if err = f.scanStored(filename, fileKey, size); err != nil {
+		Filestore.Delete(fileKey)
+		return
+	}
+
+	mimeType, err := detectMimeType(filename, bytes.NewReader(head))
+	if err != nil {
+		return
+	}
+
+	sbFile := model.File{
+		AccountID: f.auth.AccountID,
+		Key:       fileKey,
+		URL:       url,
+		Size:      size,
+		Uploaded:  time.Now(),
+		Name:      filepath.Base(filename),
+		MimeType:  mimeType,
+		Tags:      tags,
+	}
+
+	if variants := f.conf.Settings.Images.Variants; len(variants) > 0 {
+		if format := extra.ImageFormat(fileKey); len(format) > 0 {
+			name := strings.TrimSuffix(path.Base(fileKey), path.Ext(fileKey))
+
+			sbFile.Variants, err = f.saveUploadedVariants(fileKey, format, name, variants)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	newID, err := DB.AddFile(f.conf.Name, sbFile)
+	if err != nil {
+		return
+	}
+
+	metering.Record(f.conf, model.MeterStorageBytes, sbFile.Size)
+
+	sbFile = f.withCDN(sbFile)
+
+	sf.ID = newID
+	sf.URL = sbFile.URL
+	sf.Variants = sbFile.Variants
+	return
+}
+
+func (f FileStore) saveUploadedVariants(fileKey, format, name string, variants []model.ImageVariant) ([]model.FileVariant, error) {
+	r, err := Filestore.Open(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return f.saveVariants(fileKey, format, name, r, variants, false)
+}
+
+// readHead returns the first 512 bytes of a stored file, enough to detect
+// its content type
+func readHead(fileKey string) ([]byte, error) {
+	r, err := Filestore.Open(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, 512))
+}
+
+// signUploadKey returns the HMAC of a presigned upload's file key
+func signUploadKey(fileKey string) string {
+	mac := hmac.New(sha256.New, []byte(Config.AppSecret))
+	fmt.Fprintf(mac, "upload|%s", fileKey)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,93 @@
+package backend_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/storage"
+)
+
+// presignedLocal simulates a storage provider accepting direct uploads
+type presignedLocal struct {
+	storage.Local
+}
+
+func (presignedLocal) PresignUpload(fileKey, contentType string, validity time.Duration) (string, http.Header, error) {
+	h := http.Header{}
+	h.Set("Content-Type", contentType)
+	return "https://bucket.test/" + fileKey, h, nil
+}
+
+func (p presignedLocal) Stat(fileKey string) (int64, string, error) {
+	r, err := p.Open(fileKey)
+	if err != nil {
+		return 0, "", err
+	}
+	defer r.Close()
+
+	buf := new(strings.Builder)
+	n, err := io.Copy(buf, r)
+	return n, "https://bucket.test/" + fileKey, err
+}
+
+func TestPresignedUpload(t *testing.T) {
+	if _, err := backend.Storage(adminAuth, base).PresignUpload("a.txt", "", 10, 0); !errors.Is(err, backend.ErrPresignNotSupported) {
+		t.Fatalf("expected ErrPresignNotSupported got %v", err)
+	}
+
+	fs := backend.Filestore
+	defer func() { backend.Filestore = fs }()
+
+	backend.Filestore = presignedLocal{}
+
+	conf := base
+	conf.Settings.Uploads = model.UploadSettings{AllowedTypes: []string{"text/plain"}}
+
+	files := backend.Storage(adminAuth, conf)
+
+	if _, err := files.PresignUpload("doc.pdf", "", 10, 0); err == nil {
+		t.Error("expected the pdf type to be refused")
+	}
+
+	pu, err := files.PresignUpload("notes.txt", "", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	} else if pu.Headers["Content-Type"] != "text/plain" {
+		t.Errorf("expected Content-Type header got %v", pu.Headers)
+	}
+
+	if _, err := files.ConfirmUpload(pu.Key, "bad", "notes.txt", nil); !errors.Is(err, backend.ErrInvalidUploadSignature) {
+		t.Errorf("expected ErrInvalidUploadSignature got %v", err)
+	}
+
+	// the client sends the content directly to the storage provider
+	content := "some notes"
+	data := model.UploadFileData{FileKey: pu.Key, File: strings.NewReader(content)}
+	if _, err := backend.Filestore.Save(data); err != nil {
+		t.Fatal(err)
+	}
+
+	sf, err := files.ConfirmUpload(pu.Key, pu.Signature, "notes.txt", []string{"direct"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := backend.DB.GetFileByID(base.Name, sf.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if file.Size != int64(len(content)) {
+		t.Errorf("expected size %d got %d", len(content), file.Size)
+	} else if file.URL != "https://bucket.test/"+pu.Key {
+		t.Errorf("unexpected URL %s", file.URL)
+	}
+
+	if err := files.Delete(sf.ID); err != nil {
+		t.Fatal(err)
+	}
+}
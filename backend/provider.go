@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/storage"
+)
+
+// newStorer returns the storage provider matching name from the config
+// credentials, the local storage is the default
+func newStorer(cfg config.AppConfig, name string) (storage.Storer, error) {
+	switch strings.ToLower(name) {
+	case storage.StorageProviderS3:
+		return storage.S3{}, nil
+	case storage.StorageProviderAzure:
+		return storage.NewAzure(cfg.AzureStorageAccount, cfg.AzureStorageKey, cfg.AzureStorageContainer, cfg.AzureStorageEndpoint)
+	case storage.StorageProviderGCS:
+		return storage.NewGCS(cfg.GCSBucket, cfg.GCSCredentials, cfg.GCSEndpoint)
+	}
+	return storage.NewLocal(cfg.LocalStoragePath)
+}
+
+// storerName returns the name of the provider newStorer returns for name
+func storerName(name string) string {
+	switch name = strings.ToLower(name); name {
+	case storage.StorageProviderS3, storage.StorageProviderAzure, storage.StorageProviderGCS:
+		return name
+	}
+	return storage.StorageProviderLocal
+}
+
+// newFilestore returns the default storage provider, routing files to the
+// provider selected by their database when StorageProviders is set
+func newFilestore(cfg config.AppConfig) (storage.Storer, error) {
+	def, err := newStorer(cfg, cfg.StorageProvider)
+	if err != nil {
+		return nil, err
+	} else if len(cfg.StorageProviders) == 0 {
+		return def, nil
+	}
+
+	router := storage.Router{
+		Default:     def,
+		DefaultName: storerName(cfg.StorageProvider),
+		Providers:   make(map[string]storage.Storer),
+		Provider:    databaseStorageProvider,
+	}
+
+	for _, name := range strings.Split(cfg.StorageProviders, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+			continue
+		case storage.StorageProviderLocal, storage.StorageProviderS3,
+			storage.StorageProviderAzure, storage.StorageProviderGCS:
+		default:
+			return nil, fmt.Errorf("unknown storage provider %s", name)
+		}
+
+		s, err := newStorer(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		router.Providers[name] = s
+	}
+	return router, nil
+}
+
+// databaseStorageProvider returns the storage provider selected in the
+// settings of a database
+func databaseStorageProvider(dbName string) string {
+	conf, err := findDatabaseByName(dbName)
+	if err != nil {
+		return ""
+	}
+	return conf.Settings.Files.Provider
+}
+
+// StorageProviderEnabled returns whether databases can select the storage
+// provider in their settings
+func StorageProviderEnabled(name string) bool {
+	router, ok := Filestore.(storage.Router)
+	if !ok {
+		return false
+	}
+
+	_, ok = router.Providers[strings.ToLower(name)]
+	return ok
+}
+
+// LocalStorage returns the local storage provider if it is the default or
+// one databases can select
+func LocalStorage() (storage.Local, bool) {
+	if router, ok := Filestore.(storage.Router); ok {
+		if local, ok := router.Default.(storage.Local); ok {
+			return local, true
+		}
+
+		local, ok := router.Providers[storage.StorageProviderLocal].(storage.Local)
+		return local, ok
+	}
+
+	local, ok := Filestore.(storage.Local)
+	return local, ok
+}
@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/eventbridge"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/push"
+)
+
+func setupPush(cfg config.AppConfig) {
+	senders := make(map[string]push.Sender)
+
+	if len(cfg.VAPIDPrivateKey) > 0 {
+		wp, err := push.NewWebPush(cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+		if err != nil {
+			Log.Fatal().Err(err).Msg("unable to initialize Web Push")
+		}
+
+		WebPush = wp
+		senders[model.PushKindWebPush] = wp
+	}
+
+	if len(cfg.FCMCredentials) > 0 {
+		fcm, err := push.NewFCM(cfg.FCMCredentials)
+		if err != nil {
+			Log.Fatal().Err(err).Msg("unable to initialize FCM")
+		}
+
+		senders[model.PushKindFCM] = fcm
+	}
+
+	Push = &push.Notifier{
+		DataStore: DB,
+		Volatile:  Cache,
+		Log:       Log,
+		Senders:   senders,
+	}
+}
+
+// onChannelMessage sends the channel messages as push notifications and to
+// the channel webhooks, the document changes are also indexed for search
+func onChannelMessage(msg model.Command) {
+	if len(msg.Base) == 0 {
+		return
+	} else if msg.IsDBEvent() {
+		publishDocumentEvent(msg)
+		indexDocumentEvent(msg)
+
+		if msg.Type == model.MsgTypeDBDeleted {
+			deleteDocumentFiles(msg)
+		}
+		return
+	} else if strings.HasPrefix(msg.Channel, model.BroadcastChannelPrefix) {
+		return
+	}
+
+	conf, err := findDatabaseByName(msg.Base)
+	if err != nil {
+		Log.Error().Err(err).Msgf("cannot find database %s", msg.Base)
+		return
+	}
+
+	if len(Push.Senders) > 0 {
+		Push.Notify(conf, msg)
+	}
+
+	hooks := make([]model.ChannelWebhook, len(conf.Settings.Realtime.Webhooks))
+	for i, hook := range conf.Settings.Realtime.Webhooks {
+		hook.Secret = ResolveSecret(conf, hook.Secret)
+		hooks[i] = hook
+	}
+
+	ChannelHooks.Add(conf.Name, hooks, msg)
+}
+
+// publishDocumentEvent mirrors the document changes onto the event bridge
+// and the app's webhooks
+func publishDocumentEvent(msg model.Command) {
+	types := map[string]string{
+		model.MsgTypeDBCreated: eventbridge.EventDocumentCreated,
+		model.MsgTypeDBUpdated: eventbridge.EventDocumentUpdated,
+		model.MsgTypeDBDeleted: eventbridge.EventDocumentDeleted,
+	}
+
+	data := map[string]interface{}{
+		"collection": strings.TrimPrefix(msg.Channel, "db-"),
+		"document":   json.RawMessage(msg.Data),
+	}
+	if !json.Valid([]byte(msg.Data)) {
+		data["document"] = msg.Data
+	}
+
+	Events.Publish(eventbridge.Event{
+		Type: types[msg.Type],
+		Base: msg.Base,
+		Data: data,
+	})
+
+	conf, err := findDatabaseByName(msg.Base)
+	if err != nil {
+		Log.Error().Err(err).Msgf("cannot find database %s", msg.Base)
+		return
+	}
+
+	// the webhook events are named like the event bridge ones
+	EmitWebhook(conf, types[msg.Type], data)
+}
+
+// findDatabaseByName returns the config of a database from its name, the
+// name to ID mapping is cached
+func findDatabaseByName(name string) (conf model.DatabaseConfig, err error) {
+	if id, err := Cache.Get("dbid:" + name); err == nil {
+		if err := Cache.GetTyped(id, &conf); err == nil {
+			return conf, nil
+		}
+
+		return DB.FindDatabase(id)
+	}
+
+	bases, err := DB.ListDatabases()
+	if err != nil {
+		return
+	}
+
+	for _, base := range bases {
+		if base.Name == name {
+			if err := Cache.Set("dbid:"+name, base.ID); err != nil {
+				return base, err
+			}
+			return base, nil
+		}
+	}
+
+	err = errors.New("database not found")
+	return
+}
@@ -0,0 +1,174 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/staticbackendhq/core/email"
+
+	"github.com/staticbackendhq/core/metering"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/quota"
+)
+
+// StorageUsage returns the files and bytes stored by a database with its
+// plan quota
+func StorageUsage(conf model.DatabaseConfig) (model.StorageUsage, error) {
+	usage, err := DB.StorageUsage(conf.Name)
+	if err != nil {
+		return usage, err
+	}
+
+	plan, err := middleware.TenantPlan(DB, Cache, conf.TenantID)
+	if err != nil {
+		return usage, err
+	}
+
+	usage.MaxBytes = quota.Limit(quota.Storage, plan)
+	return usage, nil
+}
+
+// checkQuota returns a quota.Error if storing size more bytes would exceed
+// the database's quota
+func checkQuota(conf model.DatabaseConfig, size int64) error {
+	if !quota.Enforced[quota.Storage] {
+		return nil
+	}
+
+	usage, err := DB.StorageUsage(conf.Name)
+	if err != nil {
+		return err
+	}
+
+	return quota.Check(DB, Cache, conf, quota.Storage, usage.Bytes, size)
+}
+
+// EmailQuotaWarning is the percentage of the monthly email quota at which
+// the tenant's owner is warned
+const EmailQuotaWarning = 80
+
+// emailMonth returns the month of the email usage, in UTC
+func emailMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// EmailUsage returns the emails sent this month by the tenant of a database
+// with its plan quota
+func EmailUsage(conf model.DatabaseConfig) (model.EmailUsage, error) {
+	usage := model.EmailUsage{Month: emailMonth(time.Now())}
+
+	sent, err := DB.GetEmailUsage(conf.TenantID, usage.Month)
+	if err != nil {
+		return usage, err
+	}
+	usage.Sent = sent
+
+	plan, err := middleware.TenantPlan(DB, Cache, conf.TenantID)
+	if err != nil {
+		return usage, err
+	}
+
+	usage.MaxEmails = int(quota.Limit(quota.Emails, plan))
+	return usage, nil
+}
+
+// checkEmailQuota returns a quota.Error if the tenant of the database sent
+// all of its monthly emails
+func checkEmailQuota(conf model.DatabaseConfig) error {
+	if !quota.Enforced[quota.Emails] {
+		return nil
+	}
+
+	sent, err := DB.GetEmailUsage(conf.TenantID, emailMonth(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	return quota.Check(DB, Cache, conf, quota.Emails, int64(sent), 1)
+}
+
+// CheckFunctionQuota returns a quota.Error if the database ran all of its
+// monthly function runs
+func CheckFunctionQuota(dbName string) error {
+	if !quota.Enforced[quota.FunctionRuns] {
+		return nil
+	}
+
+	conf, err := findDatabaseByName(dbName)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	runs, err := DB.CountFunctionRuns(conf.Name, quota.PeriodStart(now), now)
+	if err != nil {
+		return err
+	}
+
+	return quota.Check(DB, Cache, conf, quota.FunctionRuns, runs, 1)
+}
+
+// countEmailSent records an email sent by the tenant of a database, its
+// owner is warned when the usage reaches EmailQuotaWarning percent and all
+// of the monthly quota
+func countEmailSent(conf model.DatabaseConfig) {
+	metering.Record(conf, model.MeterEmails, 1)
+
+	if err := DB.IncrementMonthlyEmailSent(conf.ID); err != nil {
+		Log.Error().Err(err).Msgf("cannot increment the emails sent by %s", conf.Name)
+	}
+
+	month := emailMonth(time.Now())
+	sent, err := DB.IncrementEmailUsage(conf.TenantID, month)
+	if err != nil {
+		Log.Error().Err(err).Msgf("cannot increment the email usage of tenant %s", conf.TenantID)
+		return
+	} else if !quota.Enforced[quota.Emails] {
+		return
+	}
+
+	plan, err := middleware.TenantPlan(DB, Cache, conf.TenantID)
+	if err != nil {
+		Log.Error().Err(err).Msgf("cannot find the plan of tenant %s", conf.TenantID)
+		return
+	}
+
+	max := int(quota.Limit(quota.Emails, plan))
+	if max == 0 || (sent != max*EmailQuotaWarning/100 && sent != max) {
+		return
+	}
+
+	if err := warnEmailQuota(conf.TenantID, month, sent, max); err != nil {
+		Log.Error().Err(err).Msgf("cannot warn tenant %s of its email usage", conf.TenantID)
+	}
+}
+
+// warnEmailQuota emails the tenant's owner its email usage
+func warnEmailQuota(tenantID, month string, sent, max int) error {
+	cus, err := DB.FindTenant(tenantID)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("You've sent %d%% of your monthly emails", sent*100/max)
+	body := fmt.Sprintf(
+		"<p>Your applications sent %d of the %d emails included in your plan for %s.</p>",
+		sent, max, month,
+	)
+	if sent >= max {
+		subject = "Your monthly email quota is reached"
+		body += "<p>No more emails will be sent this month unless you upgrade your plan.</p>"
+	}
+
+	// the warnings are sent by the instance and do not count in the usage
+	mail := email.SendMailData{
+		From:     Config.FromEmail,
+		FromName: Config.FromName,
+		To:       cus.Email,
+		Subject:  subject,
+		HTMLBody: body,
+		TextBody: email.StripHTML(body),
+	}
+	return Emailer.Send(mail)
+}
@@ -0,0 +1,142 @@
+package backend_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/quota"
+)
+
+func TestStorageQuota(t *testing.T) {
+	usage, err := backend.StorageUsage(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quotas, enforced := quota.Limits[quota.Storage], quota.Enforced[quota.Storage]
+	defer func() {
+		quota.Limits[quota.Storage] = quotas
+		quota.Enforced[quota.Storage] = enforced
+	}()
+
+	// leaves room for 10 more bytes whatever the plan
+	quota.Enforced[quota.Storage] = true
+	quota.Limits[quota.Storage] = map[int]int64{}
+	for plan := range quotas {
+		quota.Limits[quota.Storage][plan] = usage.Bytes + 10
+	}
+
+	fs := backend.Storage(adminAuth, base)
+
+	sf, err := fs.Save("fits.txt", "", strings.NewReader("ten bytes!"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Delete(sf.ID)
+
+	_, err = fs.Save("over.txt", "", strings.NewReader("x"), 1)
+
+	var quotaErr *quota.Error
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("expected a quota.Error got %v", err)
+	} else if quotaErr.Resource != quota.Storage || quotaErr.Limit != usage.Bytes+10 {
+		t.Errorf("expected the storage quota of %d got %v", usage.Bytes+10, quotaErr)
+	} else if quotaErr.Usage != usage.Bytes+10 {
+		t.Errorf("expected usage of %d got %d", usage.Bytes+10, quotaErr.Usage)
+	} else if quotaErr.Reset != nil {
+		t.Error("expected the storage quota to not reset")
+	}
+}
+
+// recordingMailer records the emails sent, the queue may send them from
+// another goroutine
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []email.SendMailData
+}
+
+func (m *recordingMailer) Send(data email.SendMailData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, data)
+	return nil
+}
+
+func (m *recordingMailer) subjects(to string) (subjects []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, data := range m.sent {
+		if data.To == to {
+			subjects = append(subjects, data.Subject)
+		}
+	}
+	return
+}
+
+func TestEmailQuota(t *testing.T) {
+	// the emails queued by the other tests are sent first
+	if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := backend.EmailUsage(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quotas, enforced, emailer := quota.Limits[quota.Emails], quota.Enforced[quota.Emails], backend.Emailer
+	defer func() {
+		quota.Limits[quota.Emails] = quotas
+		quota.Enforced[quota.Emails] = enforced
+		backend.Emailer = emailer
+	}()
+
+	mailer := &recordingMailer{}
+	backend.Emailer = mailer
+
+	// leaves room for one more email whatever the plan
+	quota.Enforced[quota.Emails] = true
+	quota.Limits[quota.Emails] = map[int]int64{}
+	for plan := range quotas {
+		quota.Limits[quota.Emails][plan] = int64(usage.Sent + 1)
+	}
+
+	data := email.SendMailData{From: "app@domain.com", To: "user@domain.com", Subject: "last one", TextBody: "hi"}
+	if _, err := backend.QueueEmail(base, data); err != nil {
+		t.Fatal(err)
+	} else if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := backend.EmailUsage(base)
+	if err != nil {
+		t.Fatal(err)
+	} else if after.Sent != usage.Sent+1 || after.MaxEmails != usage.Sent+1 {
+		t.Errorf("expected %d of %d emails sent got %v", usage.Sent+1, usage.Sent+1, after)
+	}
+
+	cus, err := backend.DB.FindTenant(base.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if warnings := mailer.subjects(cus.Email); len(warnings) != 1 {
+		t.Errorf("expected the owner to be warned got %v", warnings)
+	}
+
+	_, err = backend.QueueEmail(base, data)
+
+	var quotaErr *quota.Error
+	if !errors.As(err, &quotaErr) || quotaErr.Resource != quota.Emails {
+		t.Fatalf("expected the email quota error got %v", err)
+	} else if quotaErr.Reset == nil || quotaErr.Reset.Day() != 1 || !quotaErr.Reset.After(time.Now()) {
+		t.Errorf("expected the quota to reset next month got %v", quotaErr.Reset)
+	}
+}
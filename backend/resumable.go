@@ -0,0 +1,265 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/internal"
+)
+
+// Resumable uploads are assembled in UploadsDir on the instance receiving
+// the chunks. Sessions without activity for UploadSessionTTL are removed.
+var (
+	UploadsDir       = filepath.Join(os.TempDir(), "sb-uploads")
+	UploadSessionTTL = 24 * time.Hour
+)
+
+var (
+	// ErrUploadNotFound is returned for unknown or expired upload sessions
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrOffsetMismatch is returned when a chunk does not start at the
+	// current offset of the upload
+	ErrOffsetMismatch = errors.New("chunk offset does not match the upload offset")
+	// ErrUploadIncomplete is returned when completing an upload missing chunks
+	ErrUploadIncomplete = errors.New("upload is incomplete")
+	// ErrUploadSizeRequired is returned when starting an upload without size
+	ErrUploadSizeRequired = errors.New("the upload size is required")
+	// ErrChunkTooLarge is returned when a chunk exceeds the upload size
+	ErrChunkTooLarge = errors.New("chunk exceeds the upload size")
+)
+
+// uploadsMx serializes the chunk writes
+var uploadsMx sync.Mutex
+
+// ResumableUpload is a file uploaded in multiple chunks
+type ResumableUpload struct {
+	ID        string    `json:"id"`
+	DBName    string    `json:"dbName"`
+	AccountID string    `json:"accountId"`
+	Filename  string    `json:"filename"`
+	Name      string    `json:"name"`
+	Tags      []string  `json:"tags"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	Created   time.Time `json:"created"`
+}
+
+// StartUpload creates a resumable upload session for a file of size bytes
+func (f FileStore) StartUpload(filename, name string, size int64, tags []string) (ResumableUpload, error) {
+	if size <= 0 {
+		return ResumableUpload{}, ErrUploadSizeRequired
+	}
+
+	if max := f.conf.Settings.Uploads.MaxFileSize; max > 0 && size > max {
+		return ResumableUpload{}, &UploadError{
+			Code:        UploadErrFileTooLarge,
+			Message:     fmt.Sprintf("file size exceeds the %d bytes limit", max),
+			MaxFileSize: max,
+		}
+	}
+
+	if err := checkQuota(f.conf, size); err != nil {
+		return ResumableUpload{}, err
+	}
+
+	if err := os.MkdirAll(UploadsDir, 0700); err != nil {
+		return ResumableUpload{}, err
+	}
+
+	up := ResumableUpload{
+		ID:        internal.RandStringRunes(32),
+		DBName:    f.conf.Name,
+		AccountID: f.auth.AccountID,
+		Filename:  filepath.Base(filename),
+		Name:      name,
+		Tags:      tags,
+		Size:      size,
+		Created:   time.Now(),
+	}
+
+	if err := os.WriteFile(uploadPath(up.ID, ".part"), nil, 0600); err != nil {
+		return up, err
+	}
+
+	if err := writeUploadInfo(up); err != nil {
+		return up, err
+	}
+	return up, nil
+}
+
+// GetUpload returns an upload session with its current offset
+func (f FileStore) GetUpload(id string) (up ResumableUpload, err error) {
+	if len(id) == 0 || strings.ContainsAny(id, `/\.`) {
+		return up, ErrUploadNotFound
+	}
+
+	b, err := os.ReadFile(uploadPath(id, ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return up, ErrUploadNotFound
+	} else if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(b, &up); err != nil {
+		return
+	}
+
+	if up.DBName != f.conf.Name || up.AccountID != f.auth.AccountID {
+		return ResumableUpload{}, ErrUploadNotFound
+	}
+
+	fi, err := os.Stat(uploadPath(id, ".part"))
+	if errors.Is(err, os.ErrNotExist) {
+		return up, ErrUploadNotFound
+	} else if err != nil {
+		return
+	}
+
+	up.Offset = fi.Size()
+	return
+}
+
+// WriteChunk appends a chunk starting at offset to the upload and returns
+// the new offset
+func (f FileStore) WriteChunk(id string, offset int64, chunk io.Reader) (int64, error) {
+	uploadsMx.Lock()
+	defer uploadsMx.Unlock()
+
+	up, err := f.GetUpload(id)
+	if err != nil {
+		return 0, err
+	} else if offset != up.Offset {
+		return up.Offset, ErrOffsetMismatch
+	}
+
+	part, err := os.OpenFile(uploadPath(id, ".part"), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return up.Offset, err
+	}
+	defer part.Close()
+
+	// reading one more byte than the remaining size detects oversized chunks
+	remaining := up.Size - up.Offset
+	n, err := io.Copy(part, io.LimitReader(chunk, remaining+1))
+	if n > remaining {
+		if err := part.Truncate(up.Size); err != nil {
+			return up.Offset, err
+		}
+		return up.Size, ErrChunkTooLarge
+	}
+
+	// what was written is kept so the client can resume from there
+	return up.Offset + n, err
+}
+
+// CompleteUpload saves the assembled file like Save and removes the session
+func (f FileStore) CompleteUpload(id string) (sf SavedFile, err error) {
+	uploadsMx.Lock()
+	up, err := f.GetUpload(id)
+	if err == nil && up.Offset != up.Size {
+		err = ErrUploadIncomplete
+	}
+	if err == nil {
+		// moving the part file prevents other writes and completions
+		err = os.Rename(uploadPath(id, ".part"), uploadPath(id, ".assembling"))
+	}
+	uploadsMx.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	part, err := os.Open(uploadPath(id, ".assembling"))
+	if err != nil {
+		return
+	}
+	defer part.Close()
+
+	sf, err = f.SaveWithTags(up.Filename, up.Name, part, up.Size, up.Tags)
+	if err != nil {
+		// the client can retry completing the upload
+		os.Rename(uploadPath(id, ".assembling"), uploadPath(id, ".part"))
+		return
+	}
+
+	removeUpload(id)
+	return
+}
+
+// AbortUpload cancels an upload and removes its chunks
+func (f FileStore) AbortUpload(id string) error {
+	if _, err := f.GetUpload(id); err != nil {
+		return err
+	}
+
+	removeUpload(id)
+	return nil
+}
+
+// CleanupUploads removes the upload sessions without activity for
+// UploadSessionTTL and returns how many were removed
+func CleanupUploads() (n int, err error) {
+	uploadsMx.Lock()
+	defer uploadsMx.Unlock()
+
+	entries, err := os.ReadDir(UploadsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		id := strings.TrimSuffix(e.Name(), ".json")
+
+		// the part file is modified with each chunk
+		fi, err := os.Stat(uploadPath(id, ".part"))
+		if err == nil && time.Since(fi.ModTime()) < UploadSessionTTL {
+			continue
+		}
+
+		removeUpload(id)
+		n++
+	}
+	return
+}
+
+func cleanupUploadsEvery(interval time.Duration) {
+	for range time.Tick(interval) {
+		n, err := CleanupUploads()
+		if err != nil {
+			Log.Error().Err(err).Msg("error cleaning up abandoned uploads")
+		} else if n > 0 {
+			Log.Info().Msgf("%d abandoned uploads removed", n)
+		}
+	}
+}
+
+func uploadPath(id, ext string) string {
+	return filepath.Join(UploadsDir, id+ext)
+}
+
+func writeUploadInfo(up ResumableUpload) error {
+	b, err := json.Marshal(up)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(uploadPath(up.ID, ".json"), b, 0600)
+}
+
+func removeUpload(id string) {
+	os.Remove(uploadPath(id, ".part"))
+	os.Remove(uploadPath(id, ".assembling"))
+	os.Remove(uploadPath(id, ".json"))
+}
@@ -0,0 +1,107 @@
+package backend_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+)
+
+func TestResumableUpload(t *testing.T) {
+	fs := backend.Storage(adminAuth, base)
+
+	content := "first chunk,second chunk"
+
+	up, err := fs.StartUpload("chunks.txt", "chunks", int64(len(content)), []string{"resumable"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offset, err := fs.WriteChunk(up.ID, 0, strings.NewReader(content[:12]))
+	if err != nil {
+		t.Fatal(err)
+	} else if offset != 12 {
+		t.Fatalf("expected offset 12 got %d", offset)
+	}
+
+	// a retried chunk must not be appended twice
+	if _, err := fs.WriteChunk(up.ID, 0, strings.NewReader(content[:12])); !errors.Is(err, backend.ErrOffsetMismatch) {
+		t.Errorf("expected ErrOffsetMismatch got %v", err)
+	}
+
+	if _, err := fs.CompleteUpload(up.ID); !errors.Is(err, backend.ErrUploadIncomplete) {
+		t.Errorf("expected ErrUploadIncomplete got %v", err)
+	}
+
+	if _, err := fs.WriteChunk(up.ID, offset, strings.NewReader(content[12:])); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := fs.GetUpload(up.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if current.Offset != current.Size {
+		t.Errorf("expected offset %d got %d", current.Size, current.Offset)
+	}
+
+	sf, err := fs.CompleteUpload(up.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := backend.DB.GetFileByID(base.Name, sf.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if file.Size != int64(len(content)) {
+		t.Errorf("expected size %d got %d", len(content), file.Size)
+	}
+
+	r, err := backend.Filestore.Open(file.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(b) != content {
+		t.Errorf("expected %s got %s", content, b)
+	}
+
+	if _, err := fs.GetUpload(up.ID); !errors.Is(err, backend.ErrUploadNotFound) {
+		t.Errorf("expected ErrUploadNotFound got %v", err)
+	}
+
+	if err := fs.Delete(sf.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCleanupUploads(t *testing.T) {
+	fs := backend.Storage(adminAuth, base)
+
+	up, err := fs.StartUpload("abandoned.txt", "", 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ttl := backend.UploadSessionTTL
+	defer func() { backend.UploadSessionTTL = ttl }()
+
+	backend.UploadSessionTTL = time.Millisecond
+	time.Sleep(5 * time.Millisecond)
+
+	if n, err := backend.CleanupUploads(); err != nil {
+		t.Fatal(err)
+	} else if n == 0 {
+		t.Errorf("expected abandoned uploads to be removed")
+	}
+
+	if _, err := fs.GetUpload(up.ID); !errors.Is(err, backend.ErrUploadNotFound) {
+		t.Errorf("expected ErrUploadNotFound got %v", err)
+	}
+}
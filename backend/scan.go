@@ -0,0 +1,149 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/staticbackendhq/core/antivirus"
+	"github.com/staticbackendhq/core/eventbridge"
+	"github.com/staticbackendhq/core/internal"
+	"github.com/staticbackendhq/core/model"
+)
+
+// QuarantinedFile describes an infected upload, it's sent to the webhooks
+// and event bridge and kept next to the file in the quarantine directory
+type QuarantinedFile struct {
+	AccountID string    `json:"accountId"`
+	UserID    string    `json:"userId"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	Threat    string    `json:"threat"`
+	Key       string    `json:"key,omitempty"`
+	Detected  time.Time `json:"detected"`
+}
+
+// scan checks the file with the configured antivirus before it's saved.
+// Infected files are quarantined and an UploadError is returned. The file
+// is rewound.
+func (f FileStore) scan(filename string, file io.ReadSeeker, size int64) error {
+	if Antivirus == nil {
+		return nil
+	}
+
+	res, err := Antivirus.Scan(file)
+	if err != nil {
+		return fmt.Errorf("unable to scan the file: %w", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if !res.Infected {
+		return nil
+	}
+
+	return f.quarantine(filename, file, size, res)
+}
+
+// scanStored checks a file uploaded directly to the storage provider
+func (f FileStore) scanStored(filename, fileKey string, size int64) error {
+	if Antivirus == nil {
+		return nil
+	}
+
+	res, err := scanKey(fileKey)
+	if err != nil {
+		return fmt.Errorf("unable to scan the file: %w", err)
+	} else if !res.Infected {
+		return nil
+	}
+
+	file, err := Filestore.Open(fileKey)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return f.quarantine(filename, file, size, res)
+}
+
+func scanKey(fileKey string) (antivirus.Result, error) {
+	file, err := Filestore.Open(fileKey)
+	if err != nil {
+		return antivirus.Result{}, err
+	}
+	defer file.Close()
+
+	return Antivirus.Scan(file)
+}
+
+// quarantine keeps the infected file in the quarantine directory if set,
+// notifies the database owner and returns the matching UploadError
+func (f FileStore) quarantine(filename string, file io.Reader, size int64, res antivirus.Result) error {
+	qf := QuarantinedFile{
+		AccountID: f.auth.AccountID,
+		UserID:    f.auth.UserID,
+		Filename:  filepath.Base(filename),
+		Size:      size,
+		Threat:    res.Threat,
+		Detected:  time.Now(),
+	}
+
+	if len(Config.QuarantinePath) > 0 {
+		key, err := saveQuarantined(f.conf.Name, qf, file)
+		if err != nil {
+			Log.Error().Err(err).Msgf("unable to quarantine %s", qf.Filename)
+		}
+		qf.Key = key
+	}
+
+	Log.Warn().Msgf("infected upload %s (%s) in %s", qf.Filename, qf.Threat, f.conf.Name)
+
+	EmitWebhook(f.conf, model.WebhookFileQuarantined, qf)
+	Events.Publish(eventbridge.Event{
+		Type: eventbridge.EventFileQuarantined,
+		Base: f.conf.Name,
+		Data: qf,
+	})
+
+	return &UploadError{
+		Code:    UploadErrFileInfected,
+		Message: "the file is infected: " + res.Threat,
+		Threat:  res.Threat,
+	}
+}
+
+// saveQuarantined writes the infected file and its description in the
+// database's quarantine directory and returns its key
+func saveQuarantined(dbName string, qf QuarantinedFile, file io.Reader) (string, error) {
+	dir := filepath.Join(Config.QuarantinePath, dbName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	// the extension is dropped so the file cannot be opened by mistake
+	name := fmt.Sprintf("%d_%s", qf.Detected.Unix(), internal.RandStringRunes(16))
+	key := dbName + "/" + name
+
+	out, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, file); err != nil {
+		return "", err
+	}
+
+	qf.Key = key
+	b, err := json.Marshal(qf)
+	if err != nil {
+		return "", err
+	}
+	return key, os.WriteFile(filepath.Join(dir, name+".json"), b, 0600)
+}
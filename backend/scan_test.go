@@ -0,0 +1,65 @@
+package backend_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/antivirus"
+	"github.com/staticbackendhq/core/backend"
+)
+
+// fakeScanner flags files containing EICAR
+type fakeScanner struct{}
+
+func (fakeScanner) Scan(file io.Reader) (antivirus.Result, error) {
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return antivirus.Result{}, err
+	}
+
+	if strings.Contains(string(b), "EICAR") {
+		return antivirus.Result{Infected: true, Threat: "Eicar-Signature"}, nil
+	}
+	return antivirus.Result{}, nil
+}
+
+func TestUploadAntivirusScan(t *testing.T) {
+	av, path := backend.Antivirus, backend.Config.QuarantinePath
+	defer func() {
+		backend.Antivirus = av
+		backend.Config.QuarantinePath = path
+	}()
+
+	backend.Antivirus = fakeScanner{}
+	backend.Config.QuarantinePath = t.TempDir()
+
+	fs := backend.Storage(adminAuth, base)
+
+	content := "a clean file"
+	sf, err := fs.Save("clean.txt", "", strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Delete(sf.ID)
+
+	content = "X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*"
+	_, err = fs.Save("virus.txt", "", strings.NewReader(content), int64(len(content)))
+
+	var uploadErr *backend.UploadError
+	if !errors.As(err, &uploadErr) {
+		t.Fatalf("expected an UploadError got %v", err)
+	} else if uploadErr.Code != backend.UploadErrFileInfected || uploadErr.Threat != "Eicar-Signature" {
+		t.Errorf("expected the infected code and threat got %v", uploadErr)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(backend.Config.QuarantinePath, base.Name))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 2 {
+		t.Errorf("expected the quarantined file and its description got %d files", len(entries))
+	}
+}
@@ -0,0 +1,114 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/search"
+)
+
+// Number of documents listed at once when reindexing a collection
+const reindexPageSize = 500
+
+var (
+	ErrSearchDisabled       = errors.New("the full-text search is disabled")
+	ErrCollectionNotIndexed = errors.New("the collection is not in the search settings")
+)
+
+// indexDocumentEvent keeps the full-text index of the collections in the
+// search settings of the database in sync with the document changes
+func indexDocumentEvent(msg model.Command) {
+	if Search == nil {
+		return
+	}
+
+	conf, err := findDatabaseByName(msg.Base)
+	if err != nil {
+		Log.Error().Err(err).Msgf("cannot find database %s", msg.Base)
+		return
+	}
+
+	col := strings.TrimPrefix(msg.Channel, "db-")
+
+	sc, ok := conf.Settings.Search.Find(col)
+	if !ok {
+		return
+	}
+
+	if msg.Type == model.MsgTypeDBDeleted {
+		if id := deletedDocumentID(msg.Data); len(id) > 0 {
+			if err := Search.Remove(msg.Base, col, id); err != nil {
+				Log.Error().Err(err).Msgf("error removing %s/%s from the search index", col, id)
+			}
+		}
+		return
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(msg.Data), &doc); err != nil {
+		Log.Error().Err(err).Msg("invalid document event")
+		return
+	}
+
+	if err := indexDocument(msg.Base, col, sc, doc); err != nil {
+		Log.Error().Err(err).Msgf("error indexing a document of %s", col)
+	}
+}
+
+// indexDocument indexes the text of a document, removing it from the index
+// when it has no text
+func indexDocument(dbName, col string, sc model.SearchCollection, doc map[string]any) error {
+	id, _ := doc["id"].(string)
+	if len(id) == 0 {
+		return nil
+	}
+
+	text := search.DocumentText(doc, sc.Fields)
+	if len(text) == 0 {
+		return Search.Remove(dbName, col, id)
+	}
+	return Search.Index(dbName, col, id, text)
+}
+
+// ReindexCollection indexes all the documents of a collection in the
+// search settings of the database, the existing ones are not indexed by
+// the document changes. It returns the number of documents indexed.
+func ReindexCollection(conf model.DatabaseConfig, col string) (n int64, err error) {
+	if Search == nil {
+		return 0, ErrSearchDisabled
+	}
+
+	sc, ok := conf.Settings.Search.Find(col)
+	if !ok {
+		return 0, ErrCollectionNotIndexed
+	}
+
+	root, err := DB.GetRootForBase(conf.Name)
+	if err != nil {
+		return
+	}
+	auth := userAuth(root)
+
+	params := model.ListParams{Page: 1, Size: reindexPageSize}
+	for {
+		res, err := DB.ListDocuments(auth, conf.Name, col, params)
+		if err != nil {
+			return n, err
+		}
+
+		for _, doc := range res.Results {
+			if err := indexDocument(conf.Name, col, sc, doc); err != nil {
+				return n, err
+			}
+			n++
+		}
+
+		if int64(len(res.Results)) < params.Size {
+			break
+		}
+		params.Page++
+	}
+	return
+}
@@ -0,0 +1,61 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/staticbackendhq/core/analytics"
+	"github.com/staticbackendhq/core/function"
+	"github.com/staticbackendhq/core/ingest"
+	"github.com/staticbackendhq/core/metering"
+)
+
+// Drain prepares the instance to exit once it stopped accepting requests.
+// The scheduler stops, the running functions complete and their history is
+// saved then, on the leader instance, the due emails of the queue are sent
+// and the leadership is released. The metered usage, the request rollups and
+// the app events are written last. It returns the context's error when its
+// deadline is reached first.
+func Drain(ctx context.Context) error {
+	defer func() {
+		if err := metering.Default.Stop(DB); err != nil {
+			Log.Error().Err(err).Msg("error writing the usage records")
+		}
+		if err := analytics.Default.Stop(DB); err != nil {
+			Log.Error().Err(err).Msg("error writing the request rollups")
+		}
+		if err := ingest.Default.Stop(DB); err != nil {
+			Log.Error().Err(err).Msg("error writing the app events")
+		}
+	}()
+
+	if Leader != nil {
+		// another instance takes the leadership over once drained
+		defer Leader.Stop()
+	}
+
+	if Scheduler != nil {
+		Scheduler.Stop()
+	}
+
+	if err := function.Wait(ctx); err != nil {
+		return err
+	}
+
+	// the email queue is processed by the leader instance only
+	if Leader == nil || !Leader.IsLeader() {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := ProcessEmailQueue()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/model"
+)
+
+// Number of months of function runs and emails sent and of days of active
+// users of the stats, by default and at most
+const (
+	StatsMonths    = 6
+	StatsDays      = 30
+	MaxStatsMonths = 24
+	MaxStatsDays   = 366
+)
+
+// AppStats returns the usage overview of a database over the last months
+// months and days days, the current ones included. The documents are
+// counted with the root user's auth.
+func AppStats(auth model.Auth, conf model.DatabaseConfig, months, days int) (stats model.AppStats, err error) {
+	stats.Documents, stats.TotalDocuments, err = documentCounts(auth, conf.Name)
+	if err != nil {
+		return
+	}
+
+	stats.Storage, err = StorageUsage(conf)
+	if err != nil {
+		return
+	}
+
+	rt, err := cache.RealtimeUsage(Cache, conf.Name)
+	if err != nil {
+		return
+	}
+	stats.RealtimeConnections = rt.Connections
+
+	now := time.Now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := months - 1; i >= 0; i-- {
+		start := thisMonth.AddDate(0, -i, 0)
+		end := start.AddDate(0, 1, 0)
+		period := start.Format("2006-01")
+
+		runs, err := DB.CountFunctionRuns(conf.Name, start, end)
+		if err != nil {
+			return stats, err
+		}
+
+		sent, err := DB.CountEmails(conf.Name, model.EmailLogFilter{
+			Status: model.EmailStatusSent,
+			Since:  start,
+			Until:  end.Add(-time.Nanosecond),
+		})
+		if err != nil {
+			return stats, err
+		}
+
+		stats.FunctionRuns = append(stats.FunctionRuns, model.UsagePoint{Period: period, Value: runs})
+		stats.EmailsSent = append(stats.EmailsSent, model.UsagePoint{Period: period, Value: sent})
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := days - 1; i >= 0; i-- {
+		start := today.AddDate(0, 0, -i)
+
+		users, err := DB.CountAuditUsers(conf.Name, model.AuditFilter{
+			Type:  model.AuditLogin,
+			Since: start,
+			Until: start.AddDate(0, 0, 1).Add(-time.Nanosecond),
+		})
+		if err != nil {
+			return stats, err
+		}
+
+		stats.ActiveUsers = append(stats.ActiveUsers, model.UsagePoint{
+			Period: start.Format("2006-01-02"),
+			Value:  users,
+		})
+	}
+
+	stats.Usage, err = DailyUsage(conf, days)
+	return
+}
+
+// documentCounts returns the number of documents of each collection of a
+// database, the system collections excluded
+func documentCounts(auth model.Auth, dbName string) (counts map[string]int64, total int64, err error) {
+	names, err := DB.ListCollections(dbName)
+	if err != nil {
+		return
+	}
+
+	counts = make(map[string]int64)
+	for _, col := range names {
+		if strings.HasPrefix(col, "sb_") {
+			continue
+		}
+
+		n, err := DB.Count(auth, dbName, col, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		counts[col] = n
+		total += n
+	}
+	return
+}
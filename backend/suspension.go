@@ -0,0 +1,74 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// ErrDatabaseSuspended is returned when running a function or a task of a
+// suspended database
+var ErrDatabaseSuspended = errors.New("database is suspended")
+
+// ErrInvalidSuspension is returned when suspending a database for an
+// unknown reason
+var ErrInvalidSuspension = errors.New("invalid suspension reason")
+
+// SuspendDatabase suspends a database for non-payment or abuse, its API
+// calls are refused and its functions and tasks do not run. Its data is
+// kept until it's reactivated.
+func SuspendDatabase(baseID, reason string) (model.DatabaseConfig, error) {
+	if reason != model.SuspendedNonPayment && reason != model.SuspendedAbuse {
+		return model.DatabaseConfig{}, fmt.Errorf("%w %q", ErrInvalidSuspension, reason)
+	}
+
+	return setSuspension(baseID, reason)
+}
+
+// ReactivateDatabase lifts the suspension of a database
+func ReactivateDatabase(baseID string) (model.DatabaseConfig, error) {
+	return setSuspension(baseID, "")
+}
+
+// setSuspension saves the suspension reason of a database and refreshes
+// its cached config so all instances apply it on the next request
+func setSuspension(baseID, reason string) (model.DatabaseConfig, error) {
+	conf, err := DB.FindDatabase(baseID)
+	if err != nil {
+		return conf, err
+	}
+
+	if err := DB.SuspendDatabase(conf.ID, reason); err != nil {
+		return conf, err
+	}
+
+	conf.SuspendedReason = reason
+	if err := Cache.SetTyped(conf.ID, conf); err != nil {
+		return conf, err
+	}
+
+	return conf, nil
+}
+
+// CheckSuspended returns ErrDatabaseSuspended if the database is suspended,
+// it's the BeforeTask of the task runner
+func CheckSuspended(dbName string) error {
+	conf, err := findDatabaseByName(dbName)
+	if err != nil {
+		return err
+	} else if conf.IsSuspended() {
+		return fmt.Errorf("%w: %s", ErrDatabaseSuspended, conf.SuspendedReason)
+	}
+	return nil
+}
+
+// BeforeFunctionRun prevents the functions of a suspended database or one
+// over its monthly function runs from running, it's the BeforeRun of the
+// execution environments
+func BeforeFunctionRun(dbName string) error {
+	if err := CheckSuspended(dbName); err != nil {
+		return err
+	}
+	return CheckFunctionQuota(dbName)
+}
@@ -0,0 +1,37 @@
+package backend_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestSuspendDatabase(t *testing.T) {
+	if _, err := backend.SuspendDatabase(base.ID, "unknown"); !errors.Is(err, backend.ErrInvalidSuspension) {
+		t.Fatalf("expected an invalid suspension reason got %v", err)
+	}
+
+	conf, err := backend.SuspendDatabase(base.ID, model.SuspendedNonPayment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.ReactivateDatabase(base.ID)
+
+	if !conf.IsSuspended() {
+		t.Error("expected the database to be suspended")
+	}
+
+	if err := backend.BeforeFunctionRun(base.Name); !errors.Is(err, backend.ErrDatabaseSuspended) {
+		t.Errorf("expected the functions to not run got %v", err)
+	}
+
+	if _, err := backend.ReactivateDatabase(base.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.BeforeFunctionRun(base.Name); err != nil {
+		t.Errorf("expected the functions to run once reactivated got %v", err)
+	}
+}
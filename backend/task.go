@@ -0,0 +1,184 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/staticbackendhq/core/function"
+	"github.com/staticbackendhq/core/model"
+)
+
+// ErrInvalidTask is returned when a task's type, target or schedule is
+// invalid
+var ErrInvalidTask = errors.New("invalid task")
+
+// AddTask validates and saves a task, it is scheduled right away on the
+// primary instance. The other instances' tasks are picked up by the
+// primary's scheduler within a minute.
+func AddTask(conf model.DatabaseConfig, task model.Task) (model.Task, error) {
+	if err := validateTask(conf, task); err != nil {
+		return task, err
+	}
+
+	// the outcome of the runs is recorded by the scheduler
+	task.LastStatus = ""
+	task.LastError = ""
+	task.LastRun = time.Time{}
+
+	id, err := DB.AddTask(conf.Name, task)
+	if err != nil {
+		return task, err
+	}
+
+	task.ID = id
+	task.BaseName = conf.Name
+
+	if Scheduler != nil {
+		Scheduler.AddOnTheFly(task)
+	}
+	return task, nil
+}
+
+// SetTaskEnabled pauses or resumes a task. A paused task keeps its
+// definition, it is removed from the scheduler until resumed.
+func SetTaskEnabled(conf model.DatabaseConfig, id string, enabled bool) (model.Task, error) {
+	task, err := DB.GetTaskByID(conf.Name, id)
+	if err != nil {
+		return task, err
+	} else if task.Enabled == enabled {
+		return task, nil
+	}
+
+	if err := DB.SetTaskEnabled(conf.Name, id, enabled); err != nil {
+		return task, err
+	}
+
+	task.Enabled = enabled
+
+	if Scheduler == nil {
+		return task, nil
+	}
+
+	if enabled {
+		Scheduler.AddOnTheFly(task)
+	} else if err := Scheduler.CancelTask(id); err != nil {
+		Log.Warn().Err(err).Msgf("task %s was not scheduled", id)
+	}
+	return task, nil
+}
+
+// UpdateTask validates and replaces the definition of a task, it is
+// rescheduled right away on the primary instance. A one-off task which ran
+// already runs again when its time to run at changes.
+func UpdateTask(conf model.DatabaseConfig, id string, task model.Task) (model.Task, error) {
+	cur, err := DB.GetTaskByID(conf.Name, id)
+	if err != nil {
+		return task, err
+	}
+
+	task.ID = id
+	if err := validateTask(conf, task); err != nil {
+		return task, err
+	}
+
+	if err := DB.UpdateTask(conf.Name, id, task); err != nil {
+		return task, err
+	}
+
+	if !cur.RunAt.Equal(task.RunAt) && len(cur.LastStatus) > 0 {
+		if err := DB.UpdateTaskRun(conf.Name, id, time.Time{}, "", ""); err != nil {
+			return task, err
+		}
+	}
+
+	task, err = DB.GetTaskByID(conf.Name, id)
+	if err != nil {
+		return task, err
+	}
+
+	if Scheduler != nil {
+		if err := Scheduler.CancelTask(id); err != nil {
+			Log.Warn().Err(err).Msgf("error removing the updated task %s", id)
+		}
+		Scheduler.AddOnTheFly(task)
+	}
+	return task, nil
+}
+
+// DeleteTask removes a task and its runs, a task other tasks run after
+// cannot be deleted
+func DeleteTask(conf model.DatabaseConfig, id string) error {
+	tasks, err := DB.ListTasksByBase(conf.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		if t.After == id {
+			return fmt.Errorf("%w: the task %s runs after this task", ErrInvalidTask, t.ID)
+		}
+	}
+
+	if err := DB.DeleteTask(conf.Name, id); err != nil {
+		return err
+	}
+
+	if Scheduler != nil {
+		if err := Scheduler.CancelTask(id); err != nil {
+			Log.Warn().Err(err).Msgf("error removing the deleted task %s", id)
+		}
+	}
+	return nil
+}
+
+// RunTask executes a task immediately regardless of its schedule and returns
+// the ID of its first run. Instances other than the primary one execute the
+// run themselves, function.ErrTaskRunning is returned when the task is
+// running on any instance.
+func RunTask(conf model.DatabaseConfig, id string) (string, error) {
+	task, err := DB.GetTaskByID(conf.Name, id)
+	if err != nil {
+		return "", err
+	}
+
+	runner := Scheduler
+	if runner == nil {
+		runner = newTaskRunner()
+	}
+	return runner.RunNow(task)
+}
+
+// validateTask ensures a task's type, target and schedule are valid
+func validateTask(conf model.DatabaseConfig, task model.Task) error {
+	if err := function.ValidateTask(task); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+
+	if task.Type == model.TaskTypeFunction {
+		if _, err := DB.GetFunctionByName(conf.Name, task.Value); err != nil {
+			return fmt.Errorf("%w: cannot find the function %s", ErrInvalidTask, task.Value)
+		}
+	}
+
+	return validateTaskDependency(conf, task)
+}
+
+// validateTaskDependency ensures the prerequisite of a dependent task exists
+// and that following the prerequisites does not lead back to the task
+func validateTaskDependency(conf model.DatabaseConfig, task model.Task) error {
+	seen := map[string]bool{task.ID: true}
+	for id := task.After; len(id) > 0; {
+		if seen[id] {
+			return fmt.Errorf("%w: the tasks cannot run after each other", ErrInvalidTask)
+		}
+		seen[id] = true
+
+		prerequisite, err := DB.GetTaskByID(conf.Name, id)
+		if err != nil {
+			return fmt.Errorf("%w: cannot find the task %s to run after", ErrInvalidTask, id)
+		}
+		id = prerequisite.After
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package backend
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/internal"
+	"github.com/staticbackendhq/core/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TransferValidity is how long an ownership transfer can be accepted
+const TransferValidity = 7 * 24 * time.Hour
+
+var (
+	// ErrTransferRecipient is returned when transferring to an email that
+	// is not a customer's or to the database's own tenant
+	ErrTransferRecipient = errors.New("the recipient must be another customer")
+	// ErrTransferPending is returned when a transfer of the database is
+	// already pending
+	ErrTransferPending = errors.New("a transfer of this database is already pending")
+	// ErrInvalidTransfer is returned when accepting or cancelling a transfer
+	// with a wrong token, an expired one or by another tenant
+	ErrInvalidTransfer = errors.New("invalid or expired transfer")
+)
+
+// RequestOwnershipTransfer invites the customer with the email to take over
+// the app of the database with its environments, or all the databases of
+// the tenant when wholeAccount is set. The transfer id and token are
+// emailed to the recipient.
+func RequestOwnershipTransfer(conf model.DatabaseConfig, to string, wholeAccount bool) (model.OwnershipTransfer, error) {
+	recipient, err := DB.GetTenantByEmail(strings.ToLower(to))
+	if err != nil || recipient.ID == conf.TenantID {
+		return model.OwnershipTransfer{}, ErrTransferRecipient
+	}
+
+	bases, err := transferredDatabases(conf.TenantID, conf, wholeAccount)
+	if err != nil {
+		return model.OwnershipTransfer{}, err
+	}
+
+	// the app is identified by its production database
+	app := bases[len(bases)-1]
+
+	now := time.Now()
+
+	list, err := DB.ListOwnershipTransfers(conf.TenantID)
+	if err != nil {
+		return model.OwnershipTransfer{}, err
+	}
+
+	for _, t := range list {
+		if t.Status != model.TransferPending || now.After(t.Expires) || t.FromTenantID != conf.TenantID {
+			continue
+		} else if wholeAccount || t.WholeAccount || t.BaseID == app.ID {
+			return t, ErrTransferPending
+		}
+	}
+
+	t := model.OwnershipTransfer{
+		BaseID:       app.ID,
+		FromTenantID: conf.TenantID,
+		ToTenantID:   recipient.ID,
+		ToEmail:      recipient.Email,
+		WholeAccount: wholeAccount,
+		Status:       model.TransferPending,
+		Created:      now,
+		Expires:      now.Add(TransferValidity),
+	}
+
+	t.ID, err = DB.AddOwnershipTransfer(t)
+	if err != nil {
+		return t, err
+	}
+
+	what := fmt.Sprintf("the app %s", app.Name)
+	if wholeAccount {
+		what = fmt.Sprintf("all the apps of an account, %d databases", len(bases))
+	}
+
+	body := fmt.Sprintf(`
+	<p>Hello,</p>
+	<p>You are invited to take over the ownership of %s.</p>
+	<p>To accept, use this transfer id and token with the root token of one
+	of your databases within %d days:</p>
+	<p>Transfer id: <strong>%s</strong><br />Token: <strong>%s</strong></p>
+	<p>New root credentials are generated for the transferred databases once
+	you accept.</p>
+	<p>If you do not expect this transfer, you can ignore this email.</p>
+	`, what, int(TransferValidity.Hours()/24), t.ID, signTransfer(t))
+
+	mail := email.SendMailData{
+		From:     Config.FromEmail,
+		FromName: Config.FromName,
+		To:       recipient.Email,
+		Subject:  "You are invited to take over an app",
+		HTMLBody: body,
+		TextBody: email.StripHTML(body),
+	}
+	return t, Emailer.Send(mail)
+}
+
+// AcceptOwnershipTransfer moves the databases of a transfer to the tenant of
+// the database, the recipient. Their root users get the recipient's email
+// with a new token and password so the previous owner loses access. The
+// subscriptions of the tenants are not changed.
+func AcceptOwnershipTransfer(conf model.DatabaseConfig, id, token string) (model.OwnershipTransfer, []model.TransferredDatabase, error) {
+	t, err := DB.GetOwnershipTransfer(id)
+	if err != nil || len(t.ID) == 0 {
+		return t, nil, ErrInvalidTransfer
+	}
+
+	if !hmac.Equal([]byte(token), []byte(signTransfer(t))) ||
+		t.Status != model.TransferPending ||
+		time.Now().After(t.Expires) ||
+		t.ToTenantID != conf.TenantID {
+		return t, nil, ErrInvalidTransfer
+	}
+
+	app, err := DB.FindDatabase(t.BaseID)
+	if err != nil {
+		return t, nil, err
+	}
+
+	bases, err := transferredDatabases(t.FromTenantID, app, t.WholeAccount)
+	if err != nil {
+		return t, nil, err
+	}
+
+	recipient, err := DB.FindTenant(t.ToTenantID)
+	if err != nil {
+		return t, nil, err
+	}
+
+	// the root users take the recipient's email, it cannot be another user's
+	for _, base := range bases {
+		if user, err := DB.FindUserByEmail(base.Name, recipient.Email); err == nil && user.Role < 100 {
+			return t, nil, fmt.Errorf("%s is already a user of the database %s", recipient.Email, base.Name)
+		}
+	}
+
+	// completing first prevents the transfer from being accepted twice
+	t.Status = model.TransferAccepted
+	t.Completed = time.Now()
+	if err := DB.CompleteOwnershipTransfer(t.ID, t.Status, t.Completed); err != nil {
+		return t, nil, ErrInvalidTransfer
+	}
+
+	var transferred []model.TransferredDatabase
+	for _, base := range bases {
+		db, err := transferDatabase(base, recipient)
+		if err != nil {
+			return t, transferred, fmt.Errorf("unable to transfer %s: %w", base.Name, err)
+		}
+
+		transferred = append(transferred, db)
+	}
+	return t, transferred, nil
+}
+
+// CancelOwnershipTransfer cancels a pending transfer, by its sender or
+// declined by its recipient
+func CancelOwnershipTransfer(conf model.DatabaseConfig, id string) error {
+	t, err := DB.GetOwnershipTransfer(id)
+	if err != nil || len(t.ID) == 0 || t.Status != model.TransferPending {
+		return ErrInvalidTransfer
+	} else if t.FromTenantID != conf.TenantID && t.ToTenantID != conf.TenantID {
+		return ErrInvalidTransfer
+	}
+
+	return DB.CompleteOwnershipTransfer(t.ID, model.TransferCancelled, time.Now())
+}
+
+// ListOwnershipTransfers returns the transfers sent and received by the
+// tenant of the database, newest first
+func ListOwnershipTransfers(conf model.DatabaseConfig) ([]model.OwnershipTransfer, error) {
+	return DB.ListOwnershipTransfers(conf.TenantID)
+}
+
+// transferredDatabases returns the databases of a tenant a transfer moves,
+// the app of the database with its environments or all of them for the
+// whole account. The app's production database is the last one.
+func transferredDatabases(tenantID string, conf model.DatabaseConfig, wholeAccount bool) ([]model.DatabaseConfig, error) {
+	if conf.TenantID != tenantID {
+		return nil, ErrInvalidTransfer
+	}
+
+	if !wholeAccount {
+		return ListEnvironments(conf)
+	}
+
+	all, err := DB.ListDatabases()
+	if err != nil {
+		return nil, err
+	}
+
+	var bases []model.DatabaseConfig
+	for _, base := range all {
+		if base.TenantID == tenantID && base.ID != conf.ID {
+			bases = append(bases, base)
+		}
+	}
+	return append(bases, conf), nil
+}
+
+// transferDatabase gives a database to the recipient and replaces the
+// credentials of its root user
+func transferDatabase(base model.DatabaseConfig, recipient model.Tenant) (model.TransferredDatabase, error) {
+	if err := DB.SetDatabaseTenant(base.ID, recipient.ID); err != nil {
+		return model.TransferredDatabase{}, err
+	}
+
+	base.TenantID = recipient.ID
+	if err := Cache.SetTyped(base.ID, base); err != nil {
+		return model.TransferredDatabase{}, err
+	}
+
+	root, err := DB.GetRootForBase(base.Name)
+	if err != nil {
+		return model.TransferredDatabase{}, err
+	}
+
+	pw := internal.RandStringRunes(6)
+	b, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	if err != nil {
+		return model.TransferredDatabase{}, err
+	}
+
+	token := DB.NewID()
+	if err := DB.ResetUserCredentials(base.Name, root.ID, recipient.Email, string(b), token); err != nil {
+		return model.TransferredDatabase{}, err
+	}
+
+	// the sessions of the previous owner are not valid anymore
+	if err := Cache.Expire(fmt.Sprintf("%s|%s", root.ID, root.Token), 0); err != nil {
+		Log.Warn().Err(err).Msgf("cannot expire the root session of %s", base.Name)
+	}
+
+	return model.TransferredDatabase{
+		ID:            base.ID,
+		Name:          base.Name,
+		RootToken:     fmt.Sprintf("%s|%s|%s", root.ID, root.AccountID, token),
+		AdminPassword: pw,
+	}, nil
+}
+
+func signTransfer(t model.OwnershipTransfer) string {
+	mac := hmac.New(sha256.New, []byte(Config.AppSecret))
+	fmt.Fprintf(mac, "%s|%s|%s|%s|%t|%d", t.ID, t.BaseID, t.FromTenantID, t.ToTenantID, t.WholeAccount, t.Expires.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// Upload error codes
+const (
+	UploadErrFileTooLarge       = "file_too_large"
+	UploadErrFileTypeNotAllowed = "file_type_not_allowed"
+	UploadErrFileInfected       = "file_infected"
+)
+
+// UploadError is returned when a file does not respect the upload
+// restrictions of the database
+type UploadError struct {
+	Code         string   `json:"code"`
+	Message      string   `json:"message"`
+	MaxFileSize  int64    `json:"maxFileSize,omitempty"`
+	MimeType     string   `json:"mimeType,omitempty"`
+	AllowedTypes []string `json:"allowedTypes,omitempty"`
+	Threat       string   `json:"threat,omitempty"`
+}
+
+func (e *UploadError) Error() string {
+	return e.Message
+}
+
+// checkUpload enforces the database's max file size and allowed types. The
+// type is sniffed from the content, the extension is only used to refine
+// generic text and zip contents (i.e. CSV or docx files).
+func checkUpload(settings model.UploadSettings, filename string, file io.ReadSeeker, size int64) error {
+	if settings.MaxFileSize > 0 && size > settings.MaxFileSize {
+		return &UploadError{
+			Code:        UploadErrFileTooLarge,
+			Message:     fmt.Sprintf("file size exceeds the %d bytes limit", settings.MaxFileSize),
+			MaxFileSize: settings.MaxFileSize,
+		}
+	}
+
+	if len(settings.AllowedTypes) == 0 {
+		return nil
+	}
+
+	sniffed, err := sniffMimeType(file)
+	if err != nil {
+		return err
+	}
+
+	if typeAllowed(settings.AllowedTypes, sniffed) {
+		return nil
+	}
+
+	byExt := baseMimeType(mime.TypeByExtension(filepath.Ext(filename)))
+	if refines(sniffed, byExt) && typeAllowed(settings.AllowedTypes, byExt) {
+		return nil
+	}
+
+	return &UploadError{
+		Code:         UploadErrFileTypeNotAllowed,
+		Message:      fmt.Sprintf("file type %s is not allowed", sniffed),
+		MimeType:     sniffed,
+		AllowedTypes: settings.AllowedTypes,
+	}
+}
+
+// sniffMimeType returns the MIME type detected from the first 512 bytes and
+// rewinds the file
+func sniffMimeType(file io.ReadSeeker) (string, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return baseMimeType(http.DetectContentType(buf[:n])), nil
+}
+
+// baseMimeType removes the parameters of a MIME type, i.e. "; charset=utf-8"
+func baseMimeType(mt string) string {
+	if i := strings.Index(mt, ";"); i >= 0 {
+		mt = mt[:i]
+	}
+	return strings.TrimSpace(strings.ToLower(mt))
+}
+
+// refines returns true if the type from the extension is a more specific
+// type of the sniffed content
+func refines(sniffed, byExt string) bool {
+	switch sniffed {
+	case "text/plain":
+		return strings.HasPrefix(byExt, "text/") || byExt == "application/json"
+	case "application/zip":
+		return strings.HasSuffix(byExt, "+zip") ||
+			strings.HasPrefix(byExt, "application/vnd.openxmlformats-") ||
+			strings.HasPrefix(byExt, "application/vnd.oasis.opendocument.")
+	}
+	return false
+}
+
+// typeAllowed returns true if the type matches one of the allowed types,
+// which can end with /* to allow a whole group, i.e. image/*
+func typeAllowed(allowed []string, mt string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == mt || a == "*/*" {
+			return true
+		} else if strings.HasSuffix(a, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(a, "*")) {
+			return true
+		}
+	}
+	return false
+}
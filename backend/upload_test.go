@@ -0,0 +1,48 @@
+package backend_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestUploadRestrictions(t *testing.T) {
+	conf := base
+	conf.Settings.Uploads = model.UploadSettings{
+		MaxFileSize:  100,
+		AllowedTypes: []string{"image/*", "text/csv"},
+	}
+
+	fs := backend.Storage(adminAuth, conf)
+
+	tests := []struct {
+		filename string
+		content  string
+		code     string
+	}{
+		{"big.csv", strings.Repeat("a,b\n", 50), backend.UploadErrFileTooLarge},
+		// the extension alone does not allow the file
+		{"fake.png", "not really an image", backend.UploadErrFileTypeNotAllowed},
+		{"doc.pdf", "%PDF-1.4 content", backend.UploadErrFileTypeNotAllowed},
+		{"data.csv", "a,b\n1,2\n", ""},
+		{"pixel.gif", "GIF89a\x01\x00\x01\x00", ""},
+	}
+
+	for _, tc := range tests {
+		_, err := fs.Save(tc.filename, "", strings.NewReader(tc.content), int64(len(tc.content)))
+
+		var uploadErr *backend.UploadError
+		if len(tc.code) == 0 {
+			if err != nil {
+				t.Errorf("%s: expected no error got %v", tc.filename, err)
+			}
+		} else if !errors.As(err, &uploadErr) {
+			t.Errorf("%s: expected an UploadError got %v", tc.filename, err)
+		} else if uploadErr.Code != tc.code {
+			t.Errorf("%s: expected %s got %s", tc.filename, tc.code, uploadErr.Code)
+		}
+	}
+}
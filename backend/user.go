@@ -158,8 +158,11 @@ func (u User) CreateUser(accountID, email, password string, role int) ([]byte, m
 	return jwtBytes, tok, nil
 }
 
-// SetPasswordResetCode sets the password forget code for a user
-func (u User) SetPasswordResetCode(email, code string) error {
+// SetPasswordResetCode sets the password forget code for a user, the code is
+// emailed with the "password-reset" email template when the database has one
+// in any locale. The email is sent in the user's locale, or the locale hint
+// when the user has none.
+func (u User) SetPasswordResetCode(email, code, hint string) error {
 	email = strings.ToLower(email)
 
 	tok, err := DB.FindUserByEmail(u.conf.Name, email)
@@ -170,7 +173,20 @@ func (u User) SetPasswordResetCode(email, code string) error {
 	if err := DB.SetPasswordResetCode(u.conf.Name, tok.ID, code); err != nil {
 		return err
 	}
-	return nil
+
+	locales, err := userLocales(u.conf, tok.ID, hint)
+	if err != nil {
+		return err
+	}
+
+	// the code is emailed when the database has a password reset template
+	tmpl, ok, err := localizedTemplate(u.conf, model.EmailTemplatePasswordReset, locales)
+	if err != nil || !ok {
+		return err
+	}
+
+	vars := map[string]any{"code": code, "email": email}
+	return sendEmailTemplate(u.conf, tmpl, mailTo(email), vars)
 }
 
 // ResetPassword resets the password of a matching email/code for a user
@@ -260,6 +276,24 @@ func GetJWT(token string) ([]byte, error) {
 
 }
 
+// GetScopedJWT returns a session token restricted by scope and valid for ttl
+func GetScopedJWT(token string, scope model.TokenScope, ttl time.Duration) ([]byte, error) {
+	now := time.Now()
+	pl := model.JWTPayload{
+		Payload: jwt.Payload{
+			Issuer:         "StaticBackend",
+			ExpirationTime: jwt.NumericDate(now.Add(ttl)),
+			NotBefore:      jwt.NumericDate(now),
+			IssuedAt:       jwt.NumericDate(now),
+			JWTID:          internal.RandStringRunes(32),
+		},
+		Token: token,
+		Scope: &scope,
+	}
+
+	return jwt.Sign(pl, model.HashSecret)
+}
+
 // MagicLinkData magic links for no-password sign-in
 type MagicLinkData struct {
 	FromEmail string `json:"fromEmail"`
@@ -268,9 +302,13 @@ type MagicLinkData struct {
 	Subject   string `json:"subject"`
 	Body      string `json:"body"`
 	MagicLink string `json:"link"`
+	// Locale of the email when the user has none, like fr-CA
+	Locale string `json:"locale"`
 }
 
-// SetupMagicLink initialize a magic link and send the email to the user
+// SetupMagicLink initialize a magic link and send the email to the user. The
+// "magic-link" email template is used in the user's locale when the data has
+// no body.
 func (u User) SetupMagicLink(data MagicLinkData) error {
 	data.Email = strings.ToLower(data.Email)
 
@@ -293,7 +331,30 @@ func (u User) SetupMagicLink(data MagicLinkData) error {
 		Subject:  data.Subject,
 		HTMLBody: strings.Replace(data.Body, "[link]", data.MagicLink, -1),
 	}
-	if err := Emailer.Send(mail); err != nil {
+
+	// without a body the magic link template is used
+	if len(data.Body) == 0 {
+		// the user might not exist yet
+		var userID string
+		if tok, err := DB.FindUserByEmail(u.conf.Name, data.Email); err == nil {
+			userID = tok.ID
+		}
+
+		locales, err := userLocales(u.conf, userID, data.Locale)
+		if err != nil {
+			return err
+		}
+
+		tmpl, _, err := localizedTemplate(u.conf, model.EmailTemplateMagicLink, locales)
+		if err != nil {
+			return err
+		}
+
+		vars := map[string]any{"link": data.MagicLink, "email": data.Email}
+		return sendEmailTemplate(u.conf, tmpl, mail, vars)
+	}
+
+	if _, err := QueueEmail(u.conf, mail); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,261 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/vault"
+)
+
+// Vault seals the apps' secrets, it's set from the VAULT_* config on start
+var Vault *vault.Keyring
+
+// SecretRewrapInterval is how often the primary instance rewraps the
+// secrets of the previous master keys with the current one
+const SecretRewrapInterval = time.Hour
+
+// secretRewrapBatchSize is the number of secrets rewrapped per query
+const secretRewrapBatchSize = 100
+
+var (
+	// ErrSecretNotFound is returned when reading a secret or version which
+	// does not exist
+	ErrSecretNotFound = errors.New("secret not found")
+	// ErrInvalidSecret is returned when setting a secret with an invalid
+	// name
+	ErrInvalidSecret = errors.New("invalid secret name, use 1 to 100 letters, digits, - or _")
+)
+
+var secretName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,100}$`)
+
+// newKeyring returns the vault's keyring. The current master key is the
+// KMS key, the VaultMasterKey or the AppSecret, the other ones can still
+// open the secrets they wrapped.
+func newKeyring(cfg config.AppConfig) (*vault.Keyring, error) {
+	var previous []vault.MasterKey
+	for _, secret := range strings.Split(cfg.VaultPreviousKeys, ",") {
+		if secret = strings.TrimSpace(secret); len(secret) > 0 {
+			previous = append(previous, vault.NewLocalKey(secret))
+		}
+	}
+
+	current := vault.NewLocalKey(cfg.AppSecret)
+	if len(cfg.VaultMasterKey) > 0 {
+		previous = append(previous, current)
+		current = vault.NewLocalKey(cfg.VaultMasterKey)
+	}
+
+	if len(cfg.VaultKMSKeyID) > 0 {
+		key, err := vault.NewKMSKey(cfg.VaultKMSKeyID, cfg.AWSRegion)
+		if err != nil {
+			return nil, err
+		}
+
+		previous = append(previous, current)
+		current = key
+	}
+
+	return vault.NewKeyring(current, previous...), nil
+}
+
+// SetSecret adds a version of a secret, the first version creates it
+func SetSecret(conf model.DatabaseConfig, name, value string) (model.Secret, error) {
+	if !secretName.MatchString(name) {
+		return model.Secret{}, ErrInvalidSecret
+	}
+
+	version := 1
+	if cur, err := DB.GetSecret(conf.Name, name, 0); err == nil {
+		version = cur.Version + 1
+	}
+
+	e, err := Vault.Seal([]byte(value))
+	if err != nil {
+		return model.Secret{}, err
+	}
+
+	s := model.Secret{
+		DBName:  conf.Name,
+		Name:    name,
+		Version: version,
+		Value:   e.Ciphertext,
+		DataKey: e.DataKey,
+		KeyID:   e.KeyID,
+		Created: time.Now(),
+	}
+
+	s.ID, err = DB.AddSecret(s)
+	return s, err
+}
+
+// GetSecret returns the value of a version of a secret, the latest one when
+// version is 0
+func GetSecret(conf model.DatabaseConfig, name string, version int) (string, error) {
+	s, err := DB.GetSecret(conf.Name, name, version)
+	if err != nil {
+		return "", ErrSecretNotFound
+	}
+
+	b, err := Vault.Open(envelope(s))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ListSecrets returns the secrets of a database without their values
+func ListSecrets(conf model.DatabaseConfig) ([]model.SecretInfo, error) {
+	versions, err := DB.ListSecrets(conf.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]model.SecretInfo, 0)
+	for _, s := range versions {
+		// the versions are by name, newest first
+		if n := len(list); n > 0 && list[n-1].Name == s.Name {
+			list[n-1].Versions++
+			continue
+		}
+
+		list = append(list, model.SecretInfo{
+			Name:     s.Name,
+			Version:  s.Version,
+			Versions: 1,
+			Updated:  s.Created,
+		})
+	}
+	return list, nil
+}
+
+// DeleteSecret removes all the versions of a secret
+func DeleteSecret(conf model.DatabaseConfig, name string) error {
+	if _, err := DB.GetSecret(conf.Name, name, 0); err != nil {
+		return ErrSecretNotFound
+	}
+	return DB.DeleteSecret(conf.Name, name)
+}
+
+// RotateSecrets encrypts all the versions of the secrets of a database
+// again with new data keys wrapped by the current master key
+func RotateSecrets(conf model.DatabaseConfig) (int, error) {
+	versions, err := DB.ListSecrets(conf.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, s := range versions {
+		b, err := Vault.Open(envelope(s))
+		if err != nil {
+			return rotated, err
+		}
+
+		e, err := Vault.Seal(b)
+		if err != nil {
+			return rotated, err
+		}
+
+		s.Value, s.DataKey, s.KeyID = e.Ciphertext, e.DataKey, e.KeyID
+		if err := DB.UpdateSecret(s); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+	return rotated, nil
+}
+
+// RewrapSecrets wraps the data keys of the secrets wrapped by a previous
+// master key with the current one. The secrets which cannot be unwrapped
+// are logged and skipped.
+func RewrapSecrets() (int, error) {
+	rewrapped := 0
+	failed := make(map[string]bool)
+	for {
+		list, err := DB.ListSecretsToRewrap(Vault.KeyID(), secretRewrapBatchSize)
+		if err != nil {
+			return rewrapped, err
+		}
+
+		progress := false
+		for _, s := range list {
+			if failed[s.ID] {
+				continue
+			}
+
+			e, err := Vault.Rewrap(envelope(s))
+			if err == nil {
+				s.DataKey, s.KeyID = e.DataKey, e.KeyID
+				err = DB.UpdateSecret(s)
+			}
+
+			if err != nil {
+				Log.Warn().Err(err).Msgf("cannot rewrap version %d of the secret %s of %s", s.Version, s.Name, s.DBName)
+				failed[s.ID] = true
+				continue
+			}
+
+			rewrapped++
+			progress = true
+		}
+
+		if !progress || len(list) < secretRewrapBatchSize {
+			return rewrapped, nil
+		}
+	}
+}
+
+// ResolveSecret returns the latest value of the secret referenced by a
+// settings value, the value as is when it's not a reference. A missing
+// secret resolves to an empty value.
+func ResolveSecret(conf model.DatabaseConfig, value string) string {
+	name, ok := model.SecretRef(value)
+	if !ok {
+		return value
+	}
+
+	v, err := GetSecret(conf, name, 0)
+	if err != nil {
+		Log.Warn().Err(err).Msgf("cannot resolve the secret %s of %s", name, conf.Name)
+		return ""
+	}
+	return v
+}
+
+// DatabaseSecret returns the latest value of a secret of a database by its
+// name, it's the Secret hook of the execution environments
+func DatabaseSecret(dbName, name string) (string, error) {
+	conf, err := findDatabaseByName(dbName)
+	if err != nil {
+		return "", err
+	}
+	return GetSecret(conf, name, 0)
+}
+
+func envelope(s model.Secret) vault.Envelope {
+	return vault.Envelope{Ciphertext: s.Value, DataKey: s.DataKey, KeyID: s.KeyID}
+}
+
+func rewrapSecretsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := RewrapSecrets(); err != nil {
+			Log.Error().Err(err).Msg("error rewrapping the secrets")
+		} else if n > 0 {
+			Log.Info().Msgf("%d secrets rewrapped with the current master key", n)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package backend_test
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/vault"
+)
+
+func TestVaultRewrap(t *testing.T) {
+	app, _ := newBackupDatabase(t, "vaultapp", "root@vaultapp.com")
+
+	cur := backend.Vault
+	defer func() { backend.Vault = cur }()
+
+	old := vault.NewLocalKey("old-master-key")
+	backend.Vault = vault.NewKeyring(old)
+
+	if _, err := backend.SetSecret(app, "smtp-password", "p4ss"); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DeleteSecret(app, "smtp-password")
+
+	backend.Vault = vault.NewKeyring(vault.NewLocalKey("new-master-key"), old)
+
+	n, err := backend.RewrapSecrets()
+	if err != nil {
+		t.Fatal(err)
+	} else if n < 1 {
+		t.Errorf("expected the secret to be rewrapped got %d", n)
+	}
+
+	// the previous master key is not needed anymore
+	backend.Vault = vault.NewKeyring(vault.NewLocalKey("new-master-key"))
+
+	if v, err := backend.GetSecret(app, "smtp-password", 0); err != nil {
+		t.Fatal(err)
+	} else if v != "p4ss" {
+		t.Errorf("expected p4ss got %s", v)
+	}
+
+	if v := backend.ResolveSecret(app, model.SecretRefPrefix+"smtp-password"); v != "p4ss" {
+		t.Errorf("expected the reference to resolve to p4ss got %s", v)
+	} else if v := backend.ResolveSecret(app, "plain"); v != "plain" {
+		t.Errorf("expected a plain value as is got %s", v)
+	} else if v := backend.ResolveSecret(app, model.SecretRefPrefix+"missing"); v != "" {
+		t.Errorf("expected a missing secret to resolve to empty got %s", v)
+	}
+}
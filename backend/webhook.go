@@ -0,0 +1,245 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/eventbridge"
+	"github.com/staticbackendhq/core/metering"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
+)
+
+const (
+	// WebhookMaxAttempts is the number of deliveries of a webhook event
+	// before it is marked as failed
+	WebhookMaxAttempts = 6
+	// WebhookQueueInterval is how often the primary instance delivers the
+	// due webhook events
+	WebhookQueueInterval = 5 * time.Second
+	// webhookBatchSize is the maximum number of events delivered per
+	// database on each pass
+	webhookBatchSize = 50
+)
+
+var (
+	// ErrWebhookNotFound is returned when a delivery's URL is no longer one
+	// of the app's webhooks
+	ErrWebhookNotFound = errors.New("webhook not found in the app's settings")
+
+	// webhookQueueMu prevents an event from being delivered by concurrent
+	// passes
+	webhookQueueMu sync.Mutex
+	// webhookQueued wakes up the queue when an event is queued on this
+	// instance
+	webhookQueued = make(chan struct{}, 1)
+)
+
+// EmitWebhook queues the event for the app's webhooks subscribed to it. The
+// deliveries are signed with the webhook's secret and retried with an
+// exponential backoff, they're kept as the app's delivery log.
+func EmitWebhook(conf model.DatabaseConfig, event string, data any) {
+	var hooks []model.WebhookSettings
+	for _, wh := range conf.Settings.Webhooks {
+		if wh.Subscribed(event) {
+			hooks = append(hooks, wh)
+		}
+	}
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(webhook.Payload{Event: event, Created: time.Now(), Data: data})
+	if err != nil {
+		Log.Error().Err(err).Msgf("unable to encode %s webhook payload", event)
+		return
+	}
+
+	for _, wh := range hooks {
+		if _, err := queueWebhook(conf, wh.URL, event, string(body)); err != nil {
+			Log.Error().Err(err).Msgf("cannot queue %s webhook to %s", event, wh.URL)
+		}
+	}
+}
+
+// FunctionCompleted meters the execution time and sends the
+// function.completed webhook event of a function execution, it's the
+// OnComplete of the execution environments
+func FunctionCompleted(evt eventbridge.Event) {
+	conf, err := findDatabaseByName(evt.Base)
+	if err != nil {
+		Log.Error().Err(err).Msgf("cannot find database %s", evt.Base)
+		return
+	}
+
+	if data, ok := evt.Data.(map[string]interface{}); ok {
+		if run, ok := data["run"].(model.ExecHistory); ok {
+			metering.Record(conf, model.MeterFunctionMillis, run.Completed.Sub(run.Started).Milliseconds())
+			if !run.Success {
+				metering.Record(conf, model.MeterFunctionFailures, 1)
+			}
+		}
+	}
+
+	EmitWebhook(conf, model.WebhookFunctionCompleted, evt.Data)
+}
+
+func queueWebhook(conf model.DatabaseConfig, url, event, payload string) (string, error) {
+	now := time.Now()
+	d := model.WebhookDelivery{
+		URL:         url,
+		Event:       event,
+		Payload:     payload,
+		Status:      model.WebhookStatusPending,
+		NextAttempt: now,
+		Created:     now,
+		Updated:     now,
+	}
+
+	id, err := DB.QueueWebhook(conf.Name, d)
+	if err != nil {
+		return "", err
+	}
+
+	select {
+	case webhookQueued <- struct{}{}:
+	default:
+	}
+	return id, nil
+}
+
+// RedeliverWebhook queues a new delivery of a logged event with the same
+// payload and returns its ID. The original delivery is left as-is.
+func RedeliverWebhook(conf model.DatabaseConfig, id string) (string, error) {
+	d, err := DB.GetWebhookDelivery(conf.Name, id)
+	if err != nil {
+		return "", err
+	}
+
+	return queueWebhook(conf, d.URL, d.Event, d.Payload)
+}
+
+// ProcessWebhookQueue delivers the due webhook events of all databases and
+// returns the number of events delivered
+func ProcessWebhookQueue() (int, error) {
+	webhookQueueMu.Lock()
+	defer webhookQueueMu.Unlock()
+
+	bases, err := DB.ListDatabases()
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, conf := range bases {
+		// the queue of a suspended database is kept until it's reactivated
+		if !conf.IsActive || conf.IsSuspended() {
+			continue
+		}
+
+		due, err := DB.ListDueWebhooks(conf.Name, time.Now(), webhookBatchSize)
+		if err != nil {
+			Log.Error().Err(err).Msgf("cannot list the queued webhooks of %s", conf.Name)
+			continue
+		}
+
+		for _, d := range due {
+			if deliverWebhook(conf, d) {
+				delivered++
+			}
+		}
+	}
+	return delivered, nil
+}
+
+// deliverWebhook posts a queued event and records the outcome, a failed
+// delivery is retried later unless it was the last attempt or the webhook
+// was removed from the app's settings.
+func deliverWebhook(conf model.DatabaseConfig, d model.WebhookDelivery) bool {
+	attempts := d.Attempts + 1
+	status, lastError, next := model.WebhookStatusDelivered, "", d.NextAttempt
+
+	var statusCode int
+	var err error
+
+	wh, form, ok := findWebhook(conf, d.URL)
+	if !ok {
+		err = ErrWebhookNotFound
+	} else {
+		statusCode, err = webhook.Send(wh, d.Event, d.ID, []byte(d.Payload))
+	}
+
+	if form && d.Event == model.WebhookFormSubmitted {
+		logFormDelivery(conf, d, attempts, statusCode, err)
+	}
+
+	if err != nil {
+		lastError = err.Error()
+		if errors.Is(err, ErrWebhookNotFound) || attempts >= WebhookMaxAttempts {
+			status = model.WebhookStatusFailed
+		} else {
+			status = model.WebhookStatusPending
+			next = time.Now().Add(WebhookRetryDelay(attempts))
+		}
+
+		Log.Warn().Err(err).Msgf("webhook %s of %s not delivered on attempt %d", d.ID, conf.Name, attempts)
+	}
+
+	if err := DB.UpdateWebhookStatus(conf.Name, d.ID, status, attempts, statusCode, lastError, next); err != nil {
+		Log.Error().Err(err).Msgf("cannot update the status of webhook %s", d.ID)
+	}
+
+	return status == model.WebhookStatusDelivered
+}
+
+// findWebhook returns the app's or form's webhook for the URL, its secret
+// signs the deliveries, and whether it's a form's webhook
+func findWebhook(conf model.DatabaseConfig, url string) (model.WebhookSettings, bool, bool) {
+	for _, wh := range conf.Settings.Webhooks {
+		if wh.URL == url {
+			wh.Secret = ResolveSecret(conf, wh.Secret)
+			return wh, false, true
+		}
+	}
+
+	for _, hook := range conf.Settings.Forms.Webhooks {
+		if hook.URL == url {
+			wh := model.WebhookSettings{URL: hook.URL, Secret: ResolveSecret(conf, hook.Secret)}
+			return wh, true, true
+		}
+	}
+	return model.WebhookSettings{}, false, false
+}
+
+// WebhookRetryDelay returns the delay before the next delivery after a
+// number of failed attempts: 30 seconds, 2, 8, 32 and 128 minutes
+func WebhookRetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return 30 * time.Second << (2 * (attempts - 1))
+}
+
+// processWebhookQueueEvery delivers the due events at each interval or as
+// soon as an event is queued on this instance
+func processWebhookQueueEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-webhookQueued:
+		case <-ctx.Done():
+			return
+		}
+
+		if _, err := ProcessWebhookQueue(); err != nil {
+			Log.Error().Err(err).Msg("error processing the webhook queue")
+		}
+	}
+}
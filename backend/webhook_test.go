@@ -0,0 +1,112 @@
+package backend_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
+)
+
+func TestWebhookQueueRetry(t *testing.T) {
+	var calls int32
+	signed := make(chan bool, 2)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		signed <- webhook.Verify("unit-test", r.Header.Get("SB-Webhook-Signature"), r.Header.Get("SB-Webhook-Timestamp"), body, webhook.SignatureTolerance) == nil &&
+			len(r.Header.Get("SB-Webhook-Delivery")) > 0
+
+		// the first delivery fails
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	conf := base
+	conf.Settings.Webhooks = []model.WebhookSettings{
+		{URL: ts.URL, Secret: "unit-test", Events: []string{"document.*"}},
+	}
+
+	// the queue reads the settings of the databases
+	if err := backend.DB.UpdateDatabaseSettings(conf.ID, conf.Settings); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.UpdateDatabaseSettings(base.ID, base.Settings)
+
+	backend.EmitWebhook(conf, model.WebhookUserCreated, "not subscribed")
+	backend.EmitWebhook(conf, model.WebhookDocumentCreated, map[string]string{"id": "doc-id"})
+
+	if _, err := backend.ProcessWebhookQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	filter := model.WebhookDeliveryFilter{URL: ts.URL}
+	list, err := backend.DB.ListWebhookDeliveries(conf.Name, filter)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Fatalf("expected 1 delivery for the subscribed event got %d", len(list))
+	}
+
+	d := list[0]
+	if d.Status != model.WebhookStatusPending || d.Attempts != 1 || d.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a failed attempt to be retried got %v", d)
+	} else if d.NextAttempt.Before(time.Now().Add(backend.WebhookRetryDelay(1) / 2)) {
+		t.Errorf("expected the next attempt to be delayed got %v", d.NextAttempt)
+	}
+
+	past := time.Now().Add(-time.Second)
+	if err := backend.DB.UpdateWebhookStatus(conf.Name, d.ID, d.Status, d.Attempts, d.StatusCode, d.LastError, past); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ProcessWebhookQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err = backend.DB.GetWebhookDelivery(conf.Name, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if d.Status != model.WebhookStatusDelivered || d.Attempts != 2 {
+		t.Errorf("expected the retried delivery to succeed got %v", d)
+	}
+
+	for i := 0; i < 2; i++ {
+		if ok := <-signed; !ok {
+			t.Error("expected the delivery to be signed with its ID")
+		}
+	}
+
+	id, err := backend.RedeliverWebhook(conf, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := backend.DB.GetWebhookDelivery(conf.Name, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if again.Payload != d.Payload || again.Event != d.Event || again.Attempts != 0 {
+		t.Errorf("expected a new delivery of the same payload got %v", again)
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	if d := backend.WebhookRetryDelay(1); d != 30*time.Second {
+		t.Errorf("expected 30s after the first attempt got %v", d)
+	}
+
+	if d := backend.WebhookRetryDelay(3); d != 8*time.Minute {
+		t.Errorf("expected 8m after the third attempt got %v", d)
+	}
+}
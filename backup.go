@@ -0,0 +1,99 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// backups lists the backups of the database or backs it up right away from
+// /backup. The scheduled backups are tasks of type backup.
+func backups(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := backend.ListBackups(conf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if list == nil {
+			list = make([]model.Backup, 0)
+		}
+
+		respond(w, http.StatusOK, list)
+	case http.MethodPost:
+		b, err := backend.BackupDatabase(conf, "")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusCreated, b)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// backupActions deletes a backup from /backup/{id} or restores it into the
+// database from /backup/{id}/restore. A backup can be restored into any
+// database of its tenant.
+func backupActions(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 2)
+	if len(id) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch action := getURLPart(r.URL.Path, 3); {
+	case len(action) == 0 && r.Method == http.MethodDelete:
+		if err := backend.DeleteBackup(conf, id); err != nil {
+			http.Error(w, err.Error(), backupStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "deleted the backup "+id)
+
+		respond(w, http.StatusOK, true)
+	case action == "restore" && r.Method == http.MethodPost:
+		report, err := backend.RestoreBackup(conf, id)
+		if err != nil {
+			http.Error(w, err.Error(), backupStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "restored the backup "+id)
+
+		respond(w, http.StatusOK, report)
+	case len(action) == 0 || action == "restore":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// backupStatus returns the status of a failed backup request
+func backupStatus(err error) int {
+	switch {
+	case errors.Is(err, backend.ErrBackupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, backend.ErrInvalidBackup):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,56 @@
+package staticbackend
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	resp := dbReq(t, backups, "POST", "/backup", nil, true)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var b model.Backup
+	if err := parseBody(resp.Body, &b); err != nil {
+		t.Fatal(err)
+	} else if len(b.ID) == 0 || b.Size == 0 {
+		t.Fatalf("expected a saved backup got %v", b)
+	}
+
+	resp = dbReq(t, backups, "GET", "/backup", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var list []model.Backup
+	if err := parseBody(resp.Body, &list); err != nil {
+		t.Fatal(err)
+	} else if len(list) == 0 || list[0].ID != b.ID {
+		t.Fatalf("expected the newest backup first got %v", list)
+	}
+
+	resp = dbReq(t, backupActions, "POST", "/backup/"+b.ID+"/restore", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var report model.RestoreReport
+	if err := parseBody(resp.Body, &report); err != nil {
+		t.Fatal(err)
+	} else if report.Users != 0 || len(report.Errors) > 0 {
+		t.Errorf("expected the existing users to be kept got %v", report)
+	}
+
+	resp = dbReq(t, backupActions, "DELETE", "/backup/"+b.ID, nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	resp = dbReq(t, backupActions, "POST", "/backup/"+b.ID+"/restore", nil, true)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for a deleted backup got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,147 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+
+	"github.com/stripe/stripe-go/v72"
+	checkout "github.com/stripe/stripe-go/v72/checkout/session"
+	"github.com/stripe/stripe-go/v72/sub"
+)
+
+// listPlans returns the subscription plans with the tenant's current plan
+// and its metered usage of the month
+func listPlans(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cus, err := backend.DB.FindTenant(conf.TenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	usage, err := backend.TenantUsage(conf.TenantID, time.Now().UTC())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := new(struct {
+		Current int              `json:"current"`
+		Plans   []model.Plan     `json:"plans"`
+		Usage   map[string]int64 `json:"usage"`
+	})
+	data.Current = cus.Plan
+	data.Plans = backend.Plans
+	data.Usage = usage
+
+	respond(w, http.StatusOK, data)
+}
+
+// subscribePlan changes the plan of the tenant. An existing subscription is
+// updated to the plan's price, the other tenants are redirected to a Stripe
+// checkout session. The plan is changed once Stripe confirms it via the
+// webhook.
+func subscribePlan(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data := new(struct {
+		Plan       int    `json:"plan"`
+		SuccessURL string `json:"successUrl"`
+		CancelURL  string `json:"cancelUrl"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, ok := backend.FindPlan(data.Plan)
+	if !ok || len(plan.PriceID) == 0 {
+		http.Error(w, "this plan cannot be subscribed to", http.StatusBadRequest)
+		return
+	} else if len(config.Current.StripeKey) == 0 {
+		http.Error(w, "billing is not configured on this instance", http.StatusNotImplemented)
+		return
+	}
+
+	cus, err := backend.DB.FindTenant(conf.TenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(cus.SubscriptionID) > 0 {
+		if err := changeSubscriptionPrice(cus.SubscriptionID, plan.PriceID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		respond(w, http.StatusOK, map[string]bool{"updated": true})
+		return
+	}
+
+	if len(data.SuccessURL) == 0 {
+		data.SuccessURL = config.Current.AppURL
+	}
+	if len(data.CancelURL) == 0 {
+		data.CancelURL = config.Current.AppURL
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		Mode:              stripe.String(string(stripe.CheckoutSessionModeSubscription)),
+		ClientReferenceID: stripe.String(conf.TenantID),
+		SuccessURL:        stripe.String(data.SuccessURL),
+		CancelURL:         stripe.String(data.CancelURL),
+		LineItems: []*stripe.CheckoutSessionLineItemParams{
+			{Price: stripe.String(plan.PriceID), Quantity: stripe.Int64(1)},
+		},
+	}
+	if len(cus.StripeID) > 0 {
+		params.Customer = stripe.String(cus.StripeID)
+	}
+	params.AddMetadata("plan", strconv.Itoa(plan.ID))
+
+	s, err := checkout.New(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	respond(w, http.StatusOK, map[string]string{"url": s.URL})
+}
+
+// changeSubscriptionPrice replaces the price of a subscription, prorated,
+// its quantity of databases is kept
+func changeSubscriptionPrice(subID, priceID string) error {
+	cur, err := sub.Get(subID, nil)
+	if err != nil {
+		return err
+	} else if cur.Items == nil || len(cur.Items.Data) == 0 {
+		return fmt.Errorf("the subscription %s has no items", subID)
+	}
+
+	item := cur.Items.Data[0]
+	params := &stripe.SubscriptionParams{
+		ProrationBehavior: stripe.String(string(stripe.SubscriptionProrationBehaviorCreateProrations)),
+		Items: []*stripe.SubscriptionItemsParams{
+			{ID: stripe.String(item.ID), Price: stripe.String(priceID), Quantity: stripe.Int64(item.Quantity)},
+		},
+	}
+	_, err = sub.Update(subID, params)
+	return err
+}
@@ -0,0 +1,124 @@
+package staticbackend
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/quota"
+	"github.com/stripe/stripe-go/v72/webhook"
+)
+
+func TestListPlans(t *testing.T) {
+	resp := dbReq(t, listPlans, "GET", "/billing/plans", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var data struct {
+		Current int              `json:"current"`
+		Plans   []model.Plan     `json:"plans"`
+		Usage   map[string]int64 `json:"usage"`
+	}
+	if err := parseBody(resp.Body, &data); err != nil {
+		t.Fatal(err)
+	} else if len(data.Plans) != len(backend.Plans) {
+		t.Errorf("expected %d plans got %d", len(backend.Plans), len(data.Plans))
+	} else if len(data.Usage) != len(model.Meters) {
+		t.Errorf("expected the month's usage of every metric got %v", data.Usage)
+	}
+
+	resp = dbReq(t, subscribePlan, "POST", "/billing/subscribe", map[string]int{"plan": 42}, true)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 got %s", GetResponseBody(t, resp))
+	}
+}
+
+func TestSetPlansQuotas(t *testing.T) {
+	prev := backend.Plans
+	defer backend.SetPlans(prev)
+
+	backend.SetPlans([]model.Plan{{
+		ID:      model.PlanGrowth,
+		PriceID: "price_growth",
+		Quotas:  model.PlanQuotas{RequestsPerMinute: 42, StorageBytes: 1 << 20, MonthlyEmails: 7},
+	}})
+
+	if middleware.RateLimits[model.PlanGrowth] != 42 || quota.Limit(quota.Storage, model.PlanGrowth) != 1<<20 {
+		t.Error("expected the plan's quotas to be applied")
+	} else if p, ok := backend.PlanByPrice("price_growth"); !ok || p.ID != model.PlanGrowth {
+		t.Errorf("expected the plan of the price got %v", p)
+	}
+}
+
+func TestStripeCheckoutCompleted(t *testing.T) {
+	cus, err := backend.DB.CreateTenant(model.Tenant{ID: "checkout-tenant", Email: "checkout@test.com", Plan: model.PlanIdea})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := config.Current.StripeWebhookSecret
+	config.Current.StripeWebhookSecret = "whsec_test"
+	defer func() {
+		config.Current.StripeWebhookSecret = secret
+	}()
+
+	session := map[string]interface{}{
+		"id":                  "cs_test",
+		"object":              "checkout.session",
+		"client_reference_id": cus.ID,
+		"subscription":        "sub_checkout",
+		"metadata":            map[string]string{"plan": fmt.Sprint(model.PlanTraction)},
+	}
+	raw, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":          "evt_test",
+		"object":      "event",
+		"type":        "checkout.session.completed",
+		"api_version": "2020-08-27",
+		"data":        map[string]json.RawMessage{"object": raw},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	sig := hex.EncodeToString(webhook.ComputeSignature(now, payload, "whsec_test"))
+
+	req := httptest.NewRequest("POST", "/stripe", bytes.NewReader(payload))
+	req.Header.Set("Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", now.Unix(), sig))
+	w := httptest.NewRecorder()
+
+	wh := stripeWebhook{log: backend.Log}
+	wh.process(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d %s", w.Code, w.Body.String())
+	}
+
+	var updated model.Tenant
+	for i := 0; i < 50; i++ {
+		if updated, err = backend.DB.FindTenant(cus.ID); err == nil && updated.Plan == model.PlanTraction {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if updated.Plan != model.PlanTraction || updated.SubscriptionID != "sub_checkout" {
+		t.Errorf("expected the plan and subscription to be updated got %d %s", updated.Plan, updated.SubscriptionID)
+	} else if plan, err := middleware.TenantPlan(backend.DB, backend.Cache, cus.ID); err != nil || plan != model.PlanTraction {
+		t.Errorf("expected the cached plan to be refreshed got %d %v", plan, err)
+	}
+}
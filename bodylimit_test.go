@@ -0,0 +1,64 @@
+package staticbackend
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/middleware"
+)
+
+func TestLimitBody(t *testing.T) {
+	limit := middleware.BodyLimits[middleware.BodyDocuments]
+	middleware.BodyLimits[middleware.BodyDocuments] = 64
+	defer func() {
+		middleware.BodyLimits[middleware.BodyDocuments] = limit
+	}()
+
+	h := middleware.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]interface{}
+		if err := parseBody(r.Body, &v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respond(w, http.StatusOK, true)
+	}), middleware.LimitBody())
+
+	large := `{"text": "` + strings.Repeat("a", 100) + `"}`
+
+	tests := []struct {
+		path    string
+		body    io.Reader
+		chunked bool
+		status  int
+	}{
+		{"/db/notes", strings.NewReader(`{"text": "ok"}`), false, http.StatusOK},
+		{"/db/notes", strings.NewReader(large), false, http.StatusRequestEntityTooLarge},
+		{"/db/notes", strings.NewReader(large), true, http.StatusBadRequest},
+		{"/fn/exec/report", strings.NewReader(large), false, http.StatusOK},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest("POST", tc.path, tc.body)
+		if tc.chunked {
+			// the size is unknown until the body is read
+			req.ContentLength = -1
+			req.Body = io.NopCloser(bytes.NewReader([]byte(large)))
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s (chunked %t): expected status %d got %d", tc.path, tc.chunked, tc.status, w.Code)
+		}
+	}
+
+	if c := middleware.BodyClass("/storage/upload"); c != middleware.BodyUploads {
+		t.Errorf("expected the uploads class got %s", c)
+	} else if c := middleware.BodyClass("/postform/contact"); c != middleware.BodyForms {
+		t.Errorf("expected the forms class got %s", c)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/staticbackendhq/core/config"
@@ -17,29 +18,46 @@ import (
 
 // Cache uses Redis to implement the Volatilizer interface
 type Cache struct {
-	Rdb *redis.Client
+	Rdb redis.UniversalClient
 	Ctx context.Context
 	log *logger.Logger
+
+	// peers are the caches of the other regions, see SetPeers
+	peers []*Cache
 }
 
-// NewCache returns an initiated Redis client
+// NewCache returns an initiated Redis client. A Sentinel client is used when
+// REDIS_SENTINEL_MASTER is set and a Cluster client when REDIS_ADDRS lists
+// multiple nodes.
 func NewCache(log *logger.Logger) *Cache {
-	var err error
-	var opt *redis.Options
+	var rdb redis.UniversalClient
 
 	if uri := config.Current.RedisURL; len(uri) > 0 {
-		opt, err = redis.ParseURL(uri)
+		opt, err := redis.ParseURL(uri)
 		if err != nil {
 			log.Fatal().Err(err).Msg("invalid REDIS_URL value")
 		}
+
+		rdb = redis.NewClient(opt)
+	} else if addrs := config.Current.RedisAddrs; len(addrs) > 0 {
+		opt := &redis.UniversalOptions{
+			Addrs:      strings.Split(addrs, ","),
+			MasterName: config.Current.RedisSentinelMaster,
+			Password:   config.Current.RedisPassword,
+		}
+
+		// with a single address and no master name, NewUniversalClient
+		// returns a single node client
+		rdb = redis.NewUniversalClient(opt)
 	} else {
-		opt = &redis.Options{
+		opt := &redis.Options{
 			Addr:     config.Current.RedisHost,
 			Password: config.Current.RedisPassword,
 			DB:       0, // use default DB
 		}
+
+		rdb = redis.NewClient(opt)
 	}
-	rdb := redis.NewClient(opt)
 
 	return &Cache{
 		Rdb: rdb,
@@ -58,6 +76,8 @@ func (c *Cache) Set(key string, value string) error {
 	if _, err := c.Rdb.Set(c.Ctx, key, value, 12*time.Hour).Result(); err != nil {
 		return err
 	}
+
+	c.replicate(func(peer *Cache) error { return peer.Set(key, value) })
 	return nil
 }
 
@@ -91,6 +111,21 @@ func (c *Cache) Dec(key string, by int64) (int64, error) {
 	return c.Rdb.DecrBy(c.Ctx, key, by).Result()
 }
 
+// Expire sets a time-to-live on a key
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	if err := c.Rdb.Expire(c.Ctx, key, ttl).Err(); err != nil {
+		return err
+	}
+
+	c.replicate(func(peer *Cache) error { return peer.Expire(key, ttl) })
+	return nil
+}
+
+// SetNX sets a value only if the key does not exist (atomic per Redis)
+func (c *Cache) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	return c.Rdb.SetNX(c.Ctx, key, value, ttl).Result()
+}
+
 // Subscribe subscribes to a topic to receive messages on system/user events
 func (c *Cache) Subscribe(send chan model.Command, token, channel string, close chan bool) {
 	pubsub := c.Rdb.Subscribe(c.Ctx, channel)
@@ -135,12 +170,9 @@ func (c *Cache) Publish(msg model.Command) error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
-	defer cancel()
-
 	// Publish the event to system so server-side function can trigger
-	// but only for non system msg
-	if !msg.IsSystemEvent && msg.Channel != "sbsys" {
+	// but only for non system and non ephemeral msg
+	if !msg.IsSystemEvent && msg.Channel != "sbsys" && msg.Type != model.MsgTypeEphemeral {
 		go func(sysmsg model.Command) {
 			sysmsg.IsSystemEvent = true
 			b, err := json.Marshal(sysmsg)
@@ -157,21 +189,35 @@ func (c *Cache) Publish(msg model.Command) error {
 		}(msg)
 	}
 
-	subs, err := c.Rdb.PubSubNumSub(c.Ctx, msg.Channel).Result()
+	// the subscribers of the other regions receive the message, the
+	// system events are handled by the region publishing them
+	if msg.Channel != "sbsys" {
+		c.replicate(func(peer *Cache) error { return peer.publishChannel(msg.Channel, b) })
+	}
+
+	return c.publishChannel(msg.Channel, b)
+}
+
+// publishChannel publishes a message to the subscribers of a channel
+func (c *Cache) publishChannel(channel string, b []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	subs, err := c.Rdb.PubSubNumSub(c.Ctx, channel).Result()
 	if err != nil {
-		c.log.Error().Err(err).Msgf("error getting db subscribers for %s", msg.Channel)
+		c.log.Error().Err(err).Msgf("error getting db subscribers for %s", channel)
 		return err
 	}
 
-	count, ok := subs[msg.Channel]
+	count, ok := subs[channel]
 	if !ok {
-		c.log.Warn().Msgf("cannot find channel in subs: %s", msg.Channel)
+		c.log.Warn().Msgf("cannot find channel in subs: %s", channel)
 		return nil
 	} else if count == 0 {
 		return nil
 	}
 
-	return c.Rdb.Publish(ctx, msg.Channel, string(b)).Err()
+	return c.Rdb.Publish(ctx, channel, string(b)).Err()
 }
 
 // PublishDocument publishes a database update message (created, updated, deleted)
@@ -196,6 +242,51 @@ func (c *Cache) PublishDocument(auth model.Auth, dbName, channel, typ string, v
 	}
 }
 
+// PublishAt uses a Redis sorted set scored by the publishing time
+func (c *Cache) PublishAt(msg model.Command, at time.Time) error {
+	dm := delayedMsg{ID: internal.RandStringRunes(16), At: at, Msg: msg}
+	b, err := json.Marshal(dm)
+	if err != nil {
+		return err
+	}
+
+	z := &redis.Z{Score: float64(at.Unix()), Member: string(b)}
+	return c.Rdb.ZAdd(c.Ctx, DelayedKey, z).Err()
+}
+
+// PublishDue publishes the scheduled messages that are due. Removing the
+// message from the sorted set is atomic, so only one instance publishes it.
+func (c *Cache) PublishDue() (int, error) {
+	by := &redis.ZRangeBy{Min: "-inf", Max: fmt.Sprintf("%d", time.Now().Unix())}
+	members, err := c.Rdb.ZRangeByScore(c.Ctx, DelayedKey, by).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range members {
+		n, err := c.Rdb.ZRem(c.Ctx, DelayedKey, m).Result()
+		if err != nil {
+			return count, err
+		} else if n == 0 {
+			// another instance published it
+			continue
+		}
+
+		var dm delayedMsg
+		if err := json.Unmarshal([]byte(m), &dm); err != nil {
+			c.log.Error().Err(err).Msg("error parsing delayed message")
+			continue
+		}
+
+		if err := c.Publish(dm.Msg); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
 // HasPermission determines if a session token has permission to a collection
 func (c *Cache) HasPermission(token, repo, payload string) bool {
 	// sbsys is a reserved channel used internally, no need to check for
@@ -209,6 +300,12 @@ func (c *Cache) HasPermission(token, repo, payload string) bool {
 		return false
 	}
 
+	// deleted events only carry the document id
+	var id string
+	if err := json.Unmarshal([]byte(payload), &id); err == nil {
+		return true
+	}
+
 	docs := make(map[string]interface{})
 	if err := json.Unmarshal([]byte(payload), &docs); err != nil {
 		c.log.Error().Err(err).Msg("error decoding docs for permissions check")
@@ -236,6 +333,21 @@ func (c *Cache) HasPermission(token, repo, payload string) bool {
 	}
 }
 
+// HSet sets a field of a Redis HASH (atomic per Redis)
+func (c *Cache) HSet(key, field, value string) error {
+	return c.Rdb.HSet(c.Ctx, key, field, value).Err()
+}
+
+// HDel removes fields of a Redis HASH (atomic per Redis)
+func (c *Cache) HDel(key string, fields ...string) error {
+	return c.Rdb.HDel(c.Ctx, key, fields...).Err()
+}
+
+// HGetAll returns the fields and values of a Redis HASH
+func (c *Cache) HGetAll(key string) (map[string]string, error) {
+	return c.Rdb.HGetAll(c.Ctx, key).Result()
+}
+
 // QueueWork uses Redis's LIST (atomic) as a work queue
 func (c *Cache) QueueWork(key, value string) error {
 	return c.Rdb.RPush(c.Ctx, key, value).Err()
@@ -217,3 +217,38 @@ func TestCachePublishDocument(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheSetNX(t *testing.T) {
+	tests := []suite{
+		{name: "set nx with redis cache", cache: redisCache},
+		{name: "set nx with dev mem cache", cache: devCache},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			key := "setnx-unit-test"
+
+			ok, err := tc.cache.SetNX(key, "first", 100*time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			} else if !ok {
+				t.Fatal("expected the key to be set")
+			}
+
+			ok, err = tc.cache.SetNX(key, "second", 100*time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			} else if ok {
+				t.Fatal("expected the existing key not to be set")
+			}
+
+			time.Sleep(200 * time.Millisecond)
+
+			ok, err = tc.cache.SetNX(key, "third", 100*time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			} else if !ok {
+				t.Error("expected the key to be set once expired")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// ChannelTTL is how long a channel's subscribers count is kept without a
+// join or leave, it clears the counts of servers which stopped abruptly
+var ChannelTTL = 24 * time.Hour
+
+func channelsKey(base string) string {
+	return "chanset-" + base
+}
+
+func channelSubsKey(base, channel string) string {
+	return fmt.Sprintf("chansubs-%s-%s", base, channel)
+}
+
+func channelRateKey(base, channel string, minute time.Time) string {
+	return fmt.Sprintf("chanrate-%s-%s-%d", base, channel, minute.Unix())
+}
+
+// JoinChannel counts a new subscriber on a database channel
+func JoinChannel(v Volatilizer, base, channel string) error {
+	if _, err := v.Inc(channelSubsKey(base, channel), 1); err != nil {
+		return err
+	} else if err := v.Expire(channelSubsKey(base, channel), ChannelTTL); err != nil {
+		return err
+	}
+
+	key := channelsKey(base)
+	if err := v.HSet(key, channel, "1"); err != nil {
+		return err
+	}
+	return v.Expire(key, ChannelTTL)
+}
+
+// LeaveChannel removes a subscriber from a database channel
+func LeaveChannel(v Volatilizer, base, channel string) error {
+	if _, err := v.Dec(channelSubsKey(base, channel), 1); err != nil {
+		return err
+	}
+	return v.Expire(channelSubsKey(base, channel), ChannelTTL)
+}
+
+// CountChannelMessage increments the messages counter of the current minute
+func CountChannelMessage(v Volatilizer, base, channel string) error {
+	key := channelRateKey(base, channel, time.Now().Truncate(time.Minute))
+
+	n, err := v.Inc(key, 1)
+	if err != nil {
+		return err
+	} else if n == 1 {
+		return v.Expire(key, 2*time.Minute)
+	}
+	return nil
+}
+
+// ChannelStats returns the active channels of a database with their
+// subscribers count and the number of messages published the last minute.
+// Channels without subscribers are removed from the list.
+func ChannelStats(v Volatilizer, base string) ([]model.ChannelStats, error) {
+	stats := make([]model.ChannelStats, 0)
+
+	channels, err := v.HGetAll(channelsKey(base))
+	if err != nil {
+		return nil, err
+	}
+
+	lastMinute := time.Now().Truncate(time.Minute).Add(-1 * time.Minute)
+
+	var inactive []string
+	for channel := range channels {
+		var subs int64
+		if err := v.GetTyped(channelSubsKey(base, channel), &subs); err != nil || subs <= 0 {
+			inactive = append(inactive, channel)
+			continue
+		}
+
+		var msgs int64
+		if err := v.GetTyped(channelRateKey(base, channel, lastMinute), &msgs); err != nil {
+			msgs = 0
+		}
+
+		stats = append(stats, model.ChannelStats{
+			Channel:           channel,
+			Subscribers:       subs,
+			MessagesPerMinute: msgs,
+		})
+	}
+
+	if len(inactive) > 0 {
+		if err := v.HDel(channelsKey(base), inactive...); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Subscribers > stats[j].Subscribers
+	})
+	return stats, nil
+}
+
+// ClearChannels removes the realtime state of a database's channels, their
+// subscribers count and history, and returns the number of channels
+func ClearChannels(v Volatilizer, base string) (int, error) {
+	channels, err := v.HGetAll(channelsKey(base))
+	if err != nil {
+		return 0, err
+	}
+
+	for channel := range channels {
+		if err := v.Expire(channelSubsKey(base, channel), 0); err != nil {
+			return 0, err
+		} else if err := v.Expire(historyKey(base, channel), 0); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := v.Expire(channelsKey(base), 0); err != nil {
+		return 0, err
+	}
+	return len(channels), nil
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestChannelStats(t *testing.T) {
+	base := "stats-unit-test"
+
+	for i := 0; i < 2; i++ {
+		if err := JoinChannel(devCache, base, "chat"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := JoinChannel(devCache, base, "quiet"); err != nil {
+		t.Fatal(err)
+	} else if err := LeaveChannel(devCache, base, "quiet"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CountChannelMessage(devCache, base, "chat"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := ChannelStats(devCache, base)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(stats) != 1 {
+		t.Fatalf("expected 1 active channel got %d", len(stats))
+	} else if stats[0].Channel != "chat" {
+		t.Errorf("expected channel to be chat got %s", stats[0].Channel)
+	} else if stats[0].Subscribers != 2 {
+		t.Errorf("expected 2 subscribers got %d", stats[0].Subscribers)
+	}
+}
+
+func TestClearChannels(t *testing.T) {
+	base := "clear-unit-test"
+
+	if err := JoinChannel(devCache, base, "chat"); err != nil {
+		t.Fatal(err)
+	} else if err := AppendHistory(devCache, base, "chat", model.Command{Data: "hello"}, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := ClearChannels(devCache, base)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Errorf("expected 1 channel cleared got %d", n)
+	}
+
+	// the dev cache expires the keys asynchronously
+	time.Sleep(50 * time.Millisecond)
+
+	if stats, err := ChannelStats(devCache, base); err != nil {
+		t.Fatal(err)
+	} else if len(stats) != 0 {
+		t.Errorf("expected no channel got %v", stats)
+	}
+
+	if msgs, err := History(devCache, base, "chat"); err != nil {
+		t.Fatal(err)
+	} else if len(msgs) != 0 {
+		t.Errorf("expected no history got %v", msgs)
+	}
+}
+
+func TestChannelExpires(t *testing.T) {
+	base := "expire-unit-test"
+
+	ttl := ChannelTTL
+	defer func() { ChannelTTL = ttl }()
+
+	ChannelTTL = 20 * time.Millisecond
+
+	if err := JoinChannel(devCache, base, "chat"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	var subs int64
+	if err := devCache.GetTyped(channelSubsKey(base, "chat"), &subs); err == nil {
+		t.Errorf("expected the subscribers count to expire got %d", subs)
+	}
+
+	if stats, err := ChannelStats(devCache, base); err != nil {
+		t.Fatal(err)
+	} else if len(stats) != 0 {
+		t.Errorf("expected no active channel got %v", stats)
+	}
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestPublishAt(t *testing.T) {
+	receiver := make(chan model.Command)
+	closeCn := make(chan bool)
+	defer close(closeCn)
+
+	channel := "delayed-unit-test"
+
+	go devCache.Subscribe(receiver, "", channel, closeCn)
+	time.Sleep(10 * time.Millisecond)
+
+	due := model.Command{Type: "reminder", Data: "now", Channel: channel}
+	if err := devCache.PublishAt(due, time.Now().Add(-1*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	later := model.Command{Type: "reminder", Data: "later", Channel: channel}
+	if err := devCache.PublishAt(later, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := devCache.PublishDue()
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("expected 1 message published got %d", n)
+	}
+
+	select {
+	case msg := <-receiver:
+		if msg.Data != due.Data {
+			t.Errorf("expected data to be %s got %s", due.Data, msg.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("delayed message was not received")
+	}
+
+	if n, err := devCache.PublishDue(); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Errorf("expected no message to be due got %d", n)
+	}
+}
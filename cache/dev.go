@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/staticbackendhq/core/cache/observer"
 	"github.com/staticbackendhq/core/internal"
@@ -15,6 +16,9 @@ import (
 // CacheDev used in local dev mode and is memory-based
 type CacheDev struct {
 	data     map[string]string
+	hashes   map[string]map[string]string
+	expiries map[string]*time.Timer
+	delayed  []delayedMsg
 	log      *logger.Logger
 	observer observer.Observer
 	m        *sync.RWMutex
@@ -24,6 +28,8 @@ type CacheDev struct {
 func NewDevCache(log *logger.Logger) *CacheDev {
 	return &CacheDev{
 		data:     make(map[string]string),
+		hashes:   make(map[string]map[string]string),
+		expiries: make(map[string]*time.Timer),
 		observer: observer.NewObserver(log),
 		log:      log,
 		m:        &sync.RWMutex{},
@@ -92,6 +98,62 @@ func (d *CacheDev) Dec(key string, by int64) (int64, error) {
 	return d.Inc(key, -1*by)
 }
 
+// Expire removes the key once ttl has elapsed, it replaces the previous
+// time-to-live of the key
+func (d *CacheDev) Expire(key string, ttl time.Duration) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	d.expire(key, ttl)
+	return nil
+}
+
+// expire schedules the removal of a key, the lock must be held
+func (d *CacheDev) expire(key string, ttl time.Duration) {
+	if t, ok := d.expiries[key]; ok {
+		t.Stop()
+		delete(d.expiries, key)
+	}
+
+	// like Redis, a key is removed right away without a positive ttl
+	if ttl <= 0 {
+		delete(d.data, key)
+		delete(d.hashes, key)
+		return
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(ttl, func() {
+		d.m.Lock()
+		defer d.m.Unlock()
+
+		// a later Expire replaced this one
+		if d.expiries[key] != t {
+			return
+		}
+
+		delete(d.data, key)
+		delete(d.hashes, key)
+		delete(d.expiries, key)
+	})
+	d.expiries[key] = t
+}
+
+// SetNX sets a value only if the key does not exist, the key is removed
+// once ttl has elapsed
+func (d *CacheDev) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if _, ok := d.data[key]; ok {
+		return false, nil
+	}
+
+	d.data[key] = value
+	d.expire(key, ttl)
+	return true, nil
+}
+
 // Subscribe subscribes to a topic to receive messages on system/user events
 func (d *CacheDev) Subscribe(send chan model.Command, token, channel string, close chan bool) {
 	pubsub := d.observer.Subscribe(channel)
@@ -135,8 +197,8 @@ func (d *CacheDev) Publish(msg model.Command) error {
 	}
 
 	// Publish the event to system so server-side function can trigger
-	// but only for non system msg
-	if !msg.IsSystemEvent && msg.Channel != "sbsys" {
+	// but only for non system and non ephemeral msg
+	if !msg.IsSystemEvent && msg.Channel != "sbsys" && msg.Type != model.MsgTypeEphemeral {
 		go func(sysmsg model.Command) {
 			sysmsg.IsSystemEvent = true
 			b, err := json.Marshal(sysmsg)
@@ -184,6 +246,43 @@ func (d *CacheDev) PublishDocument(auth model.Auth, dbName, channel, typ string,
 	}
 }
 
+// PublishAt keeps the scheduled message in memory
+func (d *CacheDev) PublishAt(msg model.Command, at time.Time) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	dm := delayedMsg{ID: internal.RandStringRunes(16), At: at, Msg: msg}
+	d.delayed = append(d.delayed, dm)
+	return nil
+}
+
+// PublishDue publishes the scheduled messages that are due
+func (d *CacheDev) PublishDue() (int, error) {
+	d.m.Lock()
+
+	var due []delayedMsg
+	pending := make([]delayedMsg, 0, len(d.delayed))
+
+	now := time.Now()
+	for _, dm := range d.delayed {
+		if dm.At.After(now) {
+			pending = append(pending, dm)
+			continue
+		}
+		due = append(due, dm)
+	}
+	d.delayed = pending
+
+	d.m.Unlock()
+
+	for i, dm := range due {
+		if err := d.Publish(dm.Msg); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
+
 // HasPermission determines if a session token has permission to a collection
 func (d *CacheDev) HasPermission(token, repo, payload string) bool {
 	if repo == "sbsys" {
@@ -195,6 +294,12 @@ func (d *CacheDev) HasPermission(token, repo, payload string) bool {
 		return false
 	}
 
+	// deleted events only carry the document id
+	var id string
+	if err := json.Unmarshal([]byte(payload), &id); err == nil {
+		return true
+	}
+
 	docs := make(map[string]interface{})
 	if err := json.Unmarshal([]byte(payload), &docs); err != nil {
 		d.log.Error().Err(err).Msg("error decoding docs for permissions check")
@@ -222,6 +327,54 @@ func (d *CacheDev) HasPermission(token, repo, payload string) bool {
 	}
 }
 
+// HSet sets the value of a field in a hash
+func (d *CacheDev) HSet(key, field, value string) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	h, ok := d.hashes[key]
+	if !ok {
+		h = make(map[string]string)
+		d.hashes[key] = h
+	}
+
+	h[field] = value
+	return nil
+}
+
+// HDel removes fields from a hash
+func (d *CacheDev) HDel(key string, fields ...string) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	h, ok := d.hashes[key]
+	if !ok {
+		return nil
+	}
+
+	for _, f := range fields {
+		delete(h, f)
+	}
+
+	// like Redis, an empty hash does not exist
+	if len(h) == 0 {
+		delete(d.hashes, key)
+	}
+	return nil
+}
+
+// HGetAll returns a copy of the fields and values of a hash
+func (d *CacheDev) HGetAll(key string) (map[string]string, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+
+	h := make(map[string]string)
+	for k, v := range d.hashes[key] {
+		h[k] = v
+	}
+	return h, nil
+}
+
 // QueueWork uses a slice to replicate a work queue (non-atomic)
 func (d *CacheDev) QueueWork(key, value string) error {
 	var queue []string
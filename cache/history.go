@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func historyKey(base, channel string) string {
+	return "history-" + base + "-" + channel
+}
+
+// AppendHistory keeps the last size messages published to a channel
+func AppendHistory(v Volatilizer, base, channel string, msg model.Command, size int) error {
+	var msgs []model.Command
+	if err := v.GetTyped(historyKey(base, channel), &msgs); err != nil {
+		msgs = make([]model.Command, 0)
+	}
+
+	// the sender's session token must not be replayed to other users
+	msg.Token = ""
+
+	msgs = append(msgs, msg)
+	if len(msgs) > size {
+		msgs = msgs[len(msgs)-size:]
+	}
+
+	return v.SetTyped(historyKey(base, channel), msgs)
+}
+
+// History returns the last messages published to a channel, oldest first
+func History(v Volatilizer, base, channel string) ([]model.Command, error) {
+	var msgs []model.Command
+	if err := v.GetTyped(historyKey(base, channel), &msgs); err != nil {
+		// nothing was published on this channel yet
+		return []model.Command{}, nil
+	}
+	return msgs, nil
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestHistory(t *testing.T) {
+	base, channel := "unittest", "history-unit-test"
+
+	for i := 0; i < 5; i++ {
+		msg := model.Command{
+			Type:    model.MsgTypeChanOut,
+			Data:    fmt.Sprintf("msg %d", i),
+			Channel: channel,
+			Token:   "secret-token",
+		}
+
+		if err := AppendHistory(devCache, base, channel, msg, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgs, err := History(devCache, base, channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages got %d", len(msgs))
+	} else if msgs[0].Data != "msg 2" {
+		t.Errorf("expected oldest message to be msg 2 got %s", msgs[0].Data)
+	} else if len(msgs[2].Token) > 0 {
+		t.Error("expected token to be removed from history")
+	}
+
+	msgs, err = History(devCache, "other-base", channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(msgs) != 0 {
+		t.Errorf("expected no messages for another database got %d", len(msgs))
+	}
+}
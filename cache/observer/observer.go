@@ -23,11 +23,12 @@ type Subscriber interface {
 type memSubscriber struct {
 	closed bool
 	msgCh  chan interface{}
+	done   chan struct{}
 }
 
 func NewSubscriber() *memSubscriber {
 	ch := make(chan interface{})
-	sub := &memSubscriber{closed: false, msgCh: ch}
+	sub := &memSubscriber{closed: false, msgCh: ch, done: make(chan struct{})}
 	return sub
 }
 
@@ -39,7 +40,8 @@ func (ps *memSubscriber) Close() error {
 	if ps.closed {
 		return errors.New("channel is already closed")
 	}
-	close(ps.msgCh)
+	// msgCh is not closed since a publish might still be in-flight
+	close(ps.done)
 	ps.closed = true
 	return nil
 }
@@ -84,6 +86,8 @@ func (o *memObserver) Publish(channel string, msg interface{}) error {
 				if !timer.Stop() {
 					<-timer.C
 				}
+			case <-msub.done:
+				timer.Stop()
 			case <-timer.C:
 				o.log.Error().Msg("the previous message is not read; dropping this message")
 				timer.Stop()
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// PresenceTTL is how long a member stays in a channel's presence without
+// being refreshed, the members of a crashed server expire after it
+var PresenceTTL = 90 * time.Second
+
+// presenceEntry is a member of a channel with the time it expires
+type presenceEntry struct {
+	Member  model.PresenceMember `json:"member"`
+	Expires time.Time            `json:"expires"`
+}
+
+func presenceKey(base, channel string) string {
+	return fmt.Sprintf("presence-%s-%s", base, channel)
+}
+
+// AddPresence records a connection as subscribed to a database's channel
+// for PresenceTTL, adding it again refreshes its expiration
+func AddPresence(v Volatilizer, base, channel string, m model.PresenceMember) error {
+	b, err := json.Marshal(presenceEntry{Member: m, Expires: time.Now().Add(PresenceTTL)})
+	if err != nil {
+		return err
+	}
+
+	key := presenceKey(base, channel)
+	if err := v.HSet(key, m.SID, string(b)); err != nil {
+		return err
+	}
+
+	// the channel is removed once none of its members are refreshed
+	return v.Expire(key, PresenceTTL)
+}
+
+// RemovePresence removes a connection from a database channel's subscribers
+func RemovePresence(v Volatilizer, base, channel, sid string) error {
+	return v.HDel(presenceKey(base, channel), sid)
+}
+
+// Presence returns the connections subscribed to a database's channel
+// ordered by the time they joined. Expired members are removed.
+func Presence(v Volatilizer, base, channel string) ([]model.PresenceMember, error) {
+	key := presenceKey(base, channel)
+
+	entries, err := v.HGetAll(key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var expired []string
+	list := make([]model.PresenceMember, 0, len(entries))
+	for sid, val := range entries {
+		var e presenceEntry
+		if err := json.Unmarshal([]byte(val), &e); err != nil || now.After(e.Expires) {
+			expired = append(expired, sid)
+			continue
+		}
+
+		list = append(list, e.Member)
+	}
+
+	if len(expired) > 0 {
+		if err := v.HDel(key, expired...); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Joined.Before(list[j].Joined)
+	})
+	return list, nil
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestPresence(t *testing.T) {
+	base, channel := "presence-db", "presence-unit-test"
+
+	members, err := Presence(devCache, base, channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(members) != 0 {
+		t.Fatalf("expected no members got %d", len(members))
+	}
+
+	first := model.PresenceMember{SID: "sid-1", UserID: "user-1", Joined: time.Now()}
+	second := model.PresenceMember{SID: "sid-2", UserID: "user-2", Joined: time.Now().Add(time.Second)}
+
+	for _, m := range []model.PresenceMember{second, first} {
+		if err := AddPresence(devCache, base, channel, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	members, err = Presence(devCache, base, channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(members) != 2 {
+		t.Fatalf("expected 2 members got %d", len(members))
+	} else if members[0].SID != first.SID {
+		t.Errorf("expected first member to be %s got %s", first.SID, members[0].SID)
+	}
+
+	// the same channel name of another database has its own members
+	others, err := Presence(devCache, "other-db", channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(others) != 0 {
+		t.Errorf("expected no members in another database got %d", len(others))
+	}
+
+	if err := RemovePresence(devCache, base, channel, first.SID); err != nil {
+		t.Fatal(err)
+	}
+
+	members, err = Presence(devCache, base, channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(members) != 1 {
+		t.Fatalf("expected 1 member got %d", len(members))
+	} else if members[0].UserID != second.UserID {
+		t.Errorf("expected remaining member to be %s got %s", second.UserID, members[0].UserID)
+	}
+}
+
+func TestPresenceExpires(t *testing.T) {
+	ttl := PresenceTTL
+	PresenceTTL = 50 * time.Millisecond
+	defer func() { PresenceTTL = ttl }()
+
+	base, channel := "presence-db", "presence-expiry"
+
+	stale := model.PresenceMember{SID: "stale", Joined: time.Now()}
+	if err := AddPresence(devCache, base, channel, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	// a refreshed member outlives the one that was not
+	active := model.PresenceMember{SID: "active", Joined: time.Now()}
+	if err := AddPresence(devCache, base, channel, active); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	members, err := Presence(devCache, base, channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(members) != 1 || members[0].SID != active.SID {
+		t.Errorf("expected only the active member got %v", members)
+	}
+}
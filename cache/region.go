@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"strings"
+
+	"github.com/staticbackendhq/core/logger"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// NewPeers returns the caches of the other regions from their
+// comma-separated Redis URLs
+func NewPeers(uris string, log *logger.Logger) ([]*Cache, error) {
+	var peers []*Cache
+	for _, uri := range strings.Split(uris, ",") {
+		uri = strings.TrimSpace(uri)
+		if len(uri) == 0 {
+			continue
+		}
+
+		opt, err := redis.ParseURL(uri)
+		if err != nil {
+			return nil, err
+		}
+
+		peers = append(peers, &Cache{
+			Rdb: redis.NewClient(opt),
+			Ctx: context.Background(),
+			log: log,
+		})
+	}
+	return peers, nil
+}
+
+// SetPeers replicates the values set and expired, the tenant metadata and
+// the tokens, and bridges the messages published to the caches of the other
+// regions. The counters, locks and queues remain regional. Replication is
+// asynchronous, a region that cannot be reached is logged and its values
+// expire or are set again.
+func (c *Cache) SetPeers(peers []*Cache) {
+	c.peers = peers
+}
+
+func (c *Cache) replicate(fn func(peer *Cache) error) {
+	for _, peer := range c.peers {
+		go func(peer *Cache) {
+			if err := fn(peer); err != nil {
+				c.log.Error().Err(err).Msg("error replicating to region peer")
+			}
+		}(peer)
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/logger"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestRegionReplication(t *testing.T) {
+	log := logger.Get(config.Current)
+
+	local := NewCache(log)
+	// the peer region is simulated by another database of the same Redis
+	peer := &Cache{
+		Rdb: redis.NewClient(&redis.Options{Addr: config.Current.RedisHost, Password: config.Current.RedisPassword, DB: 1}),
+		Ctx: context.Background(),
+		log: log,
+	}
+	local.SetPeers([]*Cache{peer})
+
+	if err := local.Set("region-conf", "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor := func(cond func() bool) bool {
+		for i := 0; i < 50; i++ {
+			if cond() {
+				return true
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		return false
+	}
+
+	if !waitFor(func() bool { v, _ := peer.Get("region-conf"); return v == "v1" }) {
+		t.Fatal("expected the value to be replicated to the peer")
+	}
+
+	// counters remain regional
+	if _, err := local.Inc("region-counter", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := local.Expire("region-conf", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if !waitFor(func() bool { _, err := peer.Get("region-conf"); return err == redis.Nil }) {
+		t.Error("expected the value to expire on the peer")
+	} else if _, err := peer.Get("region-counter"); err != redis.Nil {
+		t.Errorf("expected the counter to not be replicated got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func connectionsKey(base string) string {
+	return "rtconns-" + base
+}
+
+func messageRateKey(base string, second int64) string {
+	return fmt.Sprintf("rtmsgs-%s-%d", base, second)
+}
+
+func bandwidthKey(base string, minute time.Time) string {
+	return fmt.Sprintf("rtbytes-%s-%d", base, minute.Unix())
+}
+
+// OpenConnection counts a new realtime connection for a database and returns
+// the number of concurrent connections across all servers
+func OpenConnection(v Volatilizer, base string) (int64, error) {
+	return v.Inc(connectionsKey(base), 1)
+}
+
+// CloseConnection removes a realtime connection from the database count
+func CloseConnection(v Volatilizer, base string) error {
+	_, err := v.Dec(connectionsKey(base), 1)
+	return err
+}
+
+// CountMessage increments the database messages counter of the current
+// second and returns its value
+func CountMessage(v Volatilizer, base string) (int64, error) {
+	key := messageRateKey(base, time.Now().Unix())
+
+	n, err := v.Inc(key, 1)
+	if err != nil {
+		return 0, err
+	} else if n == 1 {
+		if err := v.Expire(key, 2*time.Second); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// CountBandwidth adds the bytes sent to realtime connections of a database
+// to the current minute
+func CountBandwidth(v Volatilizer, base string, bytes int) error {
+	key := bandwidthKey(base, time.Now().Truncate(time.Minute))
+
+	n, err := v.Inc(key, int64(bytes))
+	if err != nil {
+		return err
+	} else if n == int64(bytes) {
+		return v.Expire(key, 2*time.Minute)
+	}
+	return nil
+}
+
+// RealtimeUsage returns the concurrent connections, the messages of the last
+// second and the bytes sent the last minute of a database
+func RealtimeUsage(v Volatilizer, base string) (usage model.RealtimeUsage, err error) {
+	if err := v.GetTyped(connectionsKey(base), &usage.Connections); err != nil {
+		usage.Connections = 0
+	}
+
+	lastSecond := time.Now().Unix() - 1
+	if err := v.GetTyped(messageRateKey(base, lastSecond), &usage.MessagesPerSecond); err != nil {
+		usage.MessagesPerSecond = 0
+	}
+
+	lastMinute := time.Now().Truncate(time.Minute).Add(-1 * time.Minute)
+	if err := v.GetTyped(bandwidthKey(base, lastMinute), &usage.BytesPerMinute); err != nil {
+		usage.BytesPerMinute = 0
+	}
+
+	return
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestRealtimeUsage(t *testing.T) {
+	base := "usage-unit-test"
+
+	for i := 0; i < 3; i++ {
+		if _, err := OpenConnection(devCache, base); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := CloseConnection(devCache, base); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := CountMessage(devCache, base)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Errorf("expected 1 message this second got %d", n)
+	}
+
+	if err := CountBandwidth(devCache, base, 128); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := RealtimeUsage(devCache, base)
+	if err != nil {
+		t.Fatal(err)
+	} else if usage.Connections != 2 {
+		t.Errorf("expected 2 connections got %d", usage.Connections)
+	}
+}
@@ -1,12 +1,18 @@
 package cache
 
-import "github.com/staticbackendhq/core/model"
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
 
 // PublishDocumentEvent used to publish database events
 type PublishDocumentEvent func(auth model.Auth, dbName, channel, typ string, v interface{})
 
 // Volatilizer is the cache and pub/sub interface
 type Volatilizer interface {
+	PubSuber
+
 	// Get returns a string value from a key
 	Get(key string) (string, error)
 	// Set sets a string value
@@ -19,14 +25,53 @@ type Volatilizer interface {
 	Inc(key string, by int64) (int64, error)
 	// Dec decrements a value for a key
 	Dec(key string, by int64) (int64, error)
+	// Expire removes a key once the duration has elapsed
+	Expire(key string, ttl time.Duration) error
+	// SetNX sets a value expiring after ttl only if the key does not exist
+	// and returns whether it was set, used as a lock across instances
+	SetNX(key, value string, ttl time.Duration) (bool, error)
+	// HSet sets the value of a field in a hash
+	HSet(key, field, value string) error
+	// HDel removes fields from a hash
+	HDel(key string, fields ...string) error
+	// HGetAll returns all fields and values of a hash, empty when the key
+	// does not exist
+	HGetAll(key string) (map[string]string, error)
+	// QueueWork add a work queue item
+	QueueWork(key, value string) error
+	// DequeueWork dequeue work item (if available)
+	DequeueWork(key string) (string, error)
+}
+
+// PubSuber is the pub/sub part of the Volatilizer. The Redis-based Cache
+// implements it for production and the memory-based CacheDev for single-node
+// and test deployments.
+type PubSuber interface {
 	// Subscribe subscribes to a pub/sub channel
 	Subscribe(send chan model.Command, token, channel string, close chan bool)
 	// Publish publishes a message to a channel
 	Publish(msg model.Command) error
 	// PublishDocument publish a database message to a channel
 	PublishDocument(auth model.Auth, dbname, channel, typ string, v any)
-	// QueueWork add a work queue item
-	QueueWork(key, value string) error
-	// DequeueWork dequeue work item (if available)
-	DequeueWork(key string) (string, error)
+	// PublishAt schedules a message to be published at a specific time
+	PublishAt(msg model.Command, at time.Time) error
+	// PublishDue publishes the scheduled messages that are due and returns
+	// how many were published
+	PublishDue() (int, error)
 }
+
+// DelayedKey is the key holding the messages scheduled via PublishAt
+const DelayedKey = "sb-delayed-msgs"
+
+// delayedMsg wraps a scheduled message with a unique ID so two identical
+// messages can be scheduled
+type delayedMsg struct {
+	ID  string        `json:"id"`
+	At  time.Time     `json:"at"`
+	Msg model.Command `json:"msg"`
+}
+
+var (
+	_ Volatilizer = (*Cache)(nil)
+	_ Volatilizer = (*CacheDev)(nil)
+)
@@ -0,0 +1,57 @@
+package staticbackend
+
+import (
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/captcha"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// captchaChallenge issues a proof-of-work challenge. The client must find a
+// nonce where sha256(challenge + nonce) starts with "difficulty" zero bits
+// and send "challenge:nonce" in the SB-CAPTCHA-TOKEN header.
+func captchaChallenge(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cs := conf.Settings.Captcha
+	if cs.Provider != model.CaptchaPoW {
+		http.Error(w, "proof-of-work is not enabled for this app", http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := captcha.NewChallenge()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// unsolved challenges expire so they cannot pile up in the cache
+	ok, err := backend.Cache.SetNX("pow-"+challenge, "pending", captcha.ChallengeTTL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, "unable to issue a challenge, please retry", http.StatusInternalServerError)
+		return
+	}
+
+	difficulty := cs.Difficulty
+	if difficulty <= 0 {
+		difficulty = captcha.DefaultDifficulty
+	}
+
+	data := new(struct {
+		Challenge  string `json:"challenge"`
+		Difficulty int    `json:"difficulty"`
+	})
+	data.Challenge = challenge
+	data.Difficulty = difficulty
+
+	respond(w, http.StatusOK, data)
+}
@@ -0,0 +1,52 @@
+package captcha
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestValidProof(t *testing.T) {
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := ""
+	for i := 0; i < 1000000; i++ {
+		if ValidProof(challenge, fmt.Sprintf("%d", i), 12) {
+			nonce = fmt.Sprintf("%d", i)
+			break
+		}
+	}
+
+	if len(nonce) == 0 {
+		t.Fatal("unable to find a valid nonce")
+	} else if ValidProof(challenge, nonce, 256) {
+		t.Error("expected proof to be invalid for 256 bits difficulty")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("secret") == "secret" && r.FormValue("response") == "valid" {
+			fmt.Fprint(w, `{"success": true}`)
+			return
+		}
+		fmt.Fprint(w, `{"success": false, "error-codes": ["invalid-input-response"]}`)
+	}))
+	defer ts.Close()
+
+	HCaptchaURL = ts.URL
+
+	if err := Verify(model.CaptchaHCaptcha, "secret", "valid", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(model.CaptchaHCaptcha, "secret", "invalid", ""); err == nil {
+		t.Error("expected an error for an invalid token")
+	}
+}
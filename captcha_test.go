@@ -0,0 +1,128 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/captcha"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestCaptchaPoWOnLogin(t *testing.T) {
+	s := model.AppSettings{
+		Captcha: model.CaptchaSettings{
+			Provider:   model.CaptchaPoW,
+			Difficulty: 8,
+			OnLogin:    true,
+		},
+	}
+
+	resp := dbReq(t, settings, "POST", "/account/settings", s, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	defer func() {
+		resp := dbReq(t, settings, "POST", "/account/settings", model.AppSettings{}, true)
+		defer resp.Body.Close()
+	}()
+
+	resp2 := dbReq(t, captchaChallenge, "GET", "/captcha/challenge", nil)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp2))
+	}
+
+	var c struct {
+		Challenge  string `json:"challenge"`
+		Difficulty int    `json:"difficulty"`
+	}
+	if err := parseBody(resp2.Body, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := 0
+	for !captcha.ValidProof(c.Challenge, fmt.Sprintf("%d", nonce), c.Difficulty) {
+		nonce++
+	}
+
+	login := func(token string) int {
+		body := fmt.Sprintf(`{"email": "%s", "password": "%s"}`, userEmail, userPassword)
+		req := httptest.NewRequest("POST", "/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+		if len(token) > 0 {
+			req.Header.Set("SB-CAPTCHA-TOKEN", token)
+		}
+
+		w := httptest.NewRecorder()
+
+		h := middleware.Chain(
+			http.HandlerFunc(mship.login),
+			middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+			middleware.RequireCaptcha(backend.Cache, middleware.CaptchaOnLogin),
+		)
+		h.ServeHTTP(w, req)
+		return w.Result().StatusCode
+	}
+
+	if status := login(""); status != http.StatusForbidden {
+		t.Errorf("expected status 403 without token got %d", status)
+	}
+
+	token := fmt.Sprintf("%s:%d", c.Challenge, nonce)
+	if status := login(token); status != http.StatusOK {
+		t.Errorf("expected status 200 with a valid token got %d", status)
+	}
+
+	// a challenge cannot be re-used
+	if status := login(token); status != http.StatusForbidden {
+		t.Errorf("expected status 403 on re-used token got %d", status)
+	}
+}
+
+func TestCaptchaPoWConcurrentReplay(t *testing.T) {
+	cs := model.CaptchaSettings{Provider: model.CaptchaPoW, Difficulty: 8}
+
+	challenge, err := captcha.NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.Cache.SetNX("pow-"+challenge, "pending", captcha.ChallengeTTL); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := 0
+	for !captcha.ValidProof(challenge, fmt.Sprintf("%d", nonce), cs.Difficulty) {
+		nonce++
+	}
+	token := fmt.Sprintf("%s:%d", challenge, nonce)
+
+	var wg sync.WaitGroup
+	var valid int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := middleware.VerifyCaptcha(backend.Cache, cs, token, ""); err == nil {
+				atomic.AddInt32(&valid, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if valid != 1 {
+		t.Errorf("expected the challenge to be accepted once got %d", valid)
+	}
+}
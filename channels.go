@@ -0,0 +1,64 @@
+package staticbackend
+
+import (
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// listChannels returns the active realtime channels of the database with
+// their subscribers and message rate
+func listChannels(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := cache.ChannelStats(backend.Cache, conf.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, stats)
+}
+
+// broadcast sends a message to every active connection of the database
+func broadcast(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data := new(struct {
+		Type string `json:"type"`
+		Data string `json:"data"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if len(data.Type) == 0 {
+		http.Error(w, "a message type is required", http.StatusBadRequest)
+		return
+	}
+
+	msg := model.Command{
+		SID:     model.SystemID,
+		Type:    data.Type,
+		Data:    data.Data,
+		Channel: model.BroadcastChannel(conf.Name),
+		Base:    conf.Name,
+	}
+
+	if err := backend.Cache.Publish(msg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
@@ -0,0 +1,74 @@
+package staticbackend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestListChannels(t *testing.T) {
+	if err := cache.JoinChannel(backend.Cache, dbName, "stats-channel"); err != nil {
+		t.Fatal(err)
+	}
+	defer cache.LeaveChannel(backend.Cache, dbName, "stats-channel")
+
+	resp := dbReq(t, listChannels, "GET", "/sudo/_/channels", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var stats []model.ChannelStats
+	if err := parseBody(resp.Body, &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, s := range stats {
+		if s.Channel == "stats-channel" {
+			found = s.Subscribers == 1
+		}
+	}
+
+	if !found {
+		t.Errorf("expected stats-channel with 1 subscriber got %v", stats)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	receiver := make(chan model.Command)
+	closeSub := make(chan bool)
+	defer close(closeSub)
+
+	go backend.Cache.Subscribe(receiver, "", model.BroadcastChannel(dbName), closeSub)
+	time.Sleep(10 * time.Millisecond)
+
+	data := new(struct {
+		Type string `json:"type"`
+		Data string `json:"data"`
+	})
+	data.Type = "maintenance"
+	data.Data = "back in 5 minutes"
+
+	resp := dbReq(t, broadcast, "POST", "/sudo/_/broadcast", data, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	select {
+	case msg := <-receiver:
+		if msg.Type != data.Type {
+			t.Errorf("expected type to be %s got %s", data.Type, msg.Type)
+		} else if msg.Data != data.Data {
+			t.Errorf("expected data to be %s got %s", data.Data, msg.Data)
+		}
+	case <-time.After(time.Second):
+		t.Error("broadcast message was not received")
+	}
+}
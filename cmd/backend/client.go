@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// client calls the API of a database with its root token
+type client struct {
+	host   string
+	pubKey string
+	token  string
+	http   *http.Client
+}
+
+func newClient(host, pubKey, token string) *client {
+	return &client{
+		host:   strings.TrimSuffix(host, "/"),
+		pubKey: pubKey,
+		token:  token,
+		http:   &http.Client{},
+	}
+}
+
+// apiError is a non 2xx response of the API
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Status, e.Message)
+}
+
+// do sends body as JSON and decodes the JSON response in v when not nil
+func (c *client) do(method, path string, body, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	resp, err := c.send(method, path, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// send returns the response of a JSON request, an apiError for the non 2xx
+// responses
+func (c *client) send(method, path string, body io.Reader) (*http.Response, error) {
+	return c.sendAs(method, path, "application/json", body)
+}
+
+// sendAs is send with another content type
+func (c *client) sendAs(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.host+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("SB-PUBLIC-KEY", c.pubKey)
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode > 299 {
+		defer resp.Body.Close()
+
+		b, _ := io.ReadAll(resp.Body)
+		return nil, &apiError{Status: resp.StatusCode, Message: strings.TrimSpace(string(b))}
+	}
+	return resp, nil
+}
+
+// accountID returns the account of the root token, id|accountId|token
+func (c *client) accountID() string {
+	parts := strings.Split(c.token, "|")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
+}
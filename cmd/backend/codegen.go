@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"net/url"
+	"os"
+)
+
+// codegen writes the typed client of the database's collections
+func codegen(c *client, args []string) error {
+	fs := flag.NewFlagSet("codegen", flag.ExitOnError)
+	pkg := fs.String("package", "", "package of the Go client")
+	out := fs.String("o", "", "output file, stdout when empty")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errUsage("codegen")
+	}
+
+	qs := url.Values{}
+	qs.Set("lang", fs.Arg(0))
+	if len(*pkg) > 0 {
+		qs.Set("package", *pkg)
+	}
+
+	resp, err := c.send("GET", "/codegen?"+qs.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var w io.Writer = os.Stdout
+	if len(*out) > 0 {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// pageSize is the number of documents fetched or created per request by
+// export and import
+const pageSize = 100
+
+// query prints the documents of a collection matching the -filter flag
+func query(c *client, args []string) error {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	filter := fs.String("filter", "", `filter of the documents, i.e. "done == true"`)
+	sort := fs.String("sort", "", "field to sort by, prefixed with - for descending")
+	page := fs.Int("page", 1, "page of the results")
+	size := fs.Int("size", 25, "number of documents per page")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errUsage("query")
+	}
+
+	qs := url.Values{}
+	qs.Set("page", strconv.Itoa(*page))
+	qs.Set("size", strconv.Itoa(*size))
+	if len(*filter) > 0 {
+		qs.Set("filter", *filter)
+	}
+	if len(*sort) > 0 {
+		qs.Set("sort", *sort)
+	}
+
+	var result model.PagedResult
+	if err := c.do("GET", "/sudo/"+fs.Arg(0)+"?"+qs.Encode(), nil, &result); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// export writes every document of a collection as one JSON object per line
+func export(c *client, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errUsage("export")
+	}
+
+	var w io.Writer = os.Stdout
+	if len(args) == 2 {
+		f, err := os.Create(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	var count int64
+	for page := 1; ; page++ {
+		var result model.PagedResult
+		path := fmt.Sprintf("/sudo/%s?page=%d&size=%d", args[0], page, pageSize)
+		if err := c.do("GET", path, nil, &result); err != nil {
+			return err
+		}
+
+		for _, doc := range result.Results {
+			if err := enc.Encode(doc); err != nil {
+				return err
+			}
+		}
+
+		count += int64(len(result.Results))
+		if len(result.Results) == 0 || count >= result.Total {
+			break
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%d documents exported\n", count)
+	return nil
+}
+
+// importDocs creates the documents of a JSON lines file in a collection,
+// their id and account are assigned by the database
+func importDocs(c *client, args []string) error {
+	if len(args) != 2 {
+		return errUsage("import")
+	}
+
+	f, err := os.Open(args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		batch []map[string]interface{}
+		count int
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := c.do("POST", "/sudo/"+args[0]+"?bulk=1", batch, nil); err != nil {
+			return err
+		}
+
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	dec := json.NewDecoder(f)
+	for {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("document %d: %w", count+len(batch)+1, err)
+		}
+
+		delete(doc, "id")
+		delete(doc, "accountId")
+
+		batch = append(batch, doc)
+		if len(batch) == pageSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%d documents imported\n", count)
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func functions(c *client, args []string) error {
+	if len(args) == 0 {
+		return errUsage("fn")
+	}
+
+	switch {
+	case args[0] == "list":
+		return listFunctions(c)
+	case args[0] == "push" && len(args) >= 3:
+		trigger := "web"
+		if len(args) > 3 {
+			trigger = args[3]
+		}
+		return pushFunction(c, args[1], args[2], trigger)
+	case args[0] == "pull" && len(args) >= 2:
+		file := args[1] + ".js"
+		if len(args) > 2 {
+			file = args[2]
+		}
+		return pullFunction(c, args[1], file)
+	case args[0] == "delete" && len(args) == 2:
+		return c.do("GET", "/fn/del/"+args[1], nil, nil)
+	case args[0] == "logs":
+		var name string
+		if len(args) > 1 {
+			name = args[1]
+		}
+		return tailFunctions(c, name)
+	}
+	return errUsage("fn")
+}
+
+func listFunctions(c *client) error {
+	var list []model.ExecData
+	if err := c.do("GET", "/fn", nil, &list); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTRIGGER\tVERSION\tLAST RUN")
+	for _, fn := range list {
+		lastRun := "never"
+		if !fn.LastRun.IsZero() {
+			lastRun = fn.LastRun.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", fn.FunctionName, fn.TriggerTopic, fn.Version, lastRun)
+	}
+	return w.Flush()
+}
+
+// findFunction returns the function named name, ok is false if it does not
+// exist
+func findFunction(c *client, name string) (fn model.ExecData, ok bool, err error) {
+	var list []model.ExecData
+	if err = c.do("GET", "/fn", nil, &list); err != nil {
+		return
+	}
+
+	for _, fn := range list {
+		if fn.FunctionName == name {
+			return fn, true, nil
+		}
+	}
+	return
+}
+
+// pushFunction creates the function or updates its code and trigger
+func pushFunction(c *client, name, file, trigger string) error {
+	code, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	fn, exists, err := findFunction(c, name)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		data := model.ExecData{FunctionName: name, TriggerTopic: trigger, Code: string(code)}
+		if err := c.do("POST", "/fn/add", data, nil); err != nil {
+			return err
+		}
+
+		fmt.Printf("function %s created\n", name)
+		return nil
+	}
+
+	data := map[string]string{"id": fn.ID, "code": string(code), "trigger": trigger}
+	if err := c.do("POST", "/fn/update", data, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("function %s updated to version %d\n", name, fn.Version+1)
+	return nil
+}
+
+func pullFunction(c *client, name, file string) error {
+	var fn model.ExecData
+	if err := c.do("GET", "/fn/info/"+name, nil, &fn); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, []byte(fn.Code), 0644)
+}
+
+// tailFunctions prints the runs of the functions, only those of name when
+// set, as they complete
+func tailFunctions(c *client, name string) error {
+	fmt.Fprintln(os.Stderr, "waiting for function runs, ctrl+c to stop")
+
+	return c.subscribe(model.FunctionLogChannelPrefix, func(msg model.Command) {
+		if msg.Type != model.MsgTypeFunctionLog {
+			return
+		}
+
+		var data struct {
+			FunctionName string            `json:"functionName"`
+			Run          model.ExecHistory `json:"run"`
+		}
+		if err := json.Unmarshal([]byte(msg.Data), &data); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid function run: %v\n", err)
+			return
+		} else if len(name) > 0 && data.FunctionName != name {
+			return
+		}
+
+		status := "ok"
+		if !data.Run.Success {
+			status = "failed"
+		}
+
+		fmt.Printf("%s %s v%d %s (%s)\n",
+			data.Run.Started.Format(time.RFC3339),
+			data.FunctionName,
+			data.Run.Version,
+			status,
+			data.Run.Completed.Sub(data.Run.Started).Round(time.Millisecond),
+		)
+		for _, line := range data.Run.Output {
+			fmt.Println("  " + strings.ReplaceAll(line, "\n", "\n  "))
+		}
+	})
+}
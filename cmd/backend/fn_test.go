@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestPushFunction(t *testing.T) {
+	var added, updated []map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("SB-PUBLIC-KEY") != "pk" || r.Header.Get("Authorization") != "Bearer id|acct|tok" {
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			return
+		}
+
+		var v map[string]interface{}
+		switch r.URL.Path {
+		case "/fn":
+			json.NewEncoder(w).Encode([]model.ExecData{{ID: "fn1", FunctionName: "existing", Version: 2}})
+			return
+		case "/fn/add":
+			json.NewDecoder(r.Body).Decode(&v)
+			added = append(added, v)
+		case "/fn/update":
+			json.NewDecoder(r.Body).Decode(&v)
+			updated = append(updated, v)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(true)
+	}))
+	defer ts.Close()
+
+	file := filepath.Join(t.TempDir(), "fn.js")
+	if err := os.WriteFile(file, []byte("function handle() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newClient(ts.URL, "pk", "id|acct|tok")
+	if err := pushFunction(c, "new", file, "web"); err != nil {
+		t.Fatal(err)
+	} else if err := pushFunction(c, "existing", file, "db_created"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(added) != 1 || added[0]["name"] != "new" {
+		t.Errorf("expected the new function to be added got %v", added)
+	} else if len(updated) != 1 || updated[0]["id"] != "fn1" || updated[0]["trigger"] != "db_created" {
+		t.Errorf("expected the existing function to be updated got %v", updated)
+	} else if c.accountID() != "acct" {
+		t.Errorf("expected the account acct got %s", c.accountID())
+	}
+
+	bad := newClient(ts.URL, "pk", "wrong")
+	if err := pushFunction(bad, "new", file, "web"); err == nil {
+		t.Error("expected an error with invalid credentials")
+	} else if apiErr, ok := err.(*apiError); !ok || apiErr.Status != http.StatusUnauthorized {
+		t.Errorf("expected a 401 api error got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Command backend manages the functions, data and tasks of a database on
+// any StaticBackend instance.
+//
+// The instance, the database public key and its root token are set with
+// the -host, -pk and -token flags or the SB_HOST, SB_PUBLIC_KEY and
+// SB_ROOT_TOKEN environment variables.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const usage = `Usage: backend [flags] <command> [arguments]
+
+Commands:
+  fn list                          list the functions
+  fn push <name> <file> [trigger]  create or update a function from a file
+  fn pull <name> [file]            write the code of a function to a file
+  fn delete <name>                 delete a function
+  fn logs [name]                   tail the runs of the functions
+  query [flags] <collection>       list the documents matching a filter
+  export <collection> [file]       write a collection as JSON lines
+  import <collection> <file>       create the documents of a JSON lines file
+  codegen [flags] <ts|go>          generate the typed client of the collections
+  migrate <source> [flags]         import a firebase or supabase export
+  task list                        list the scheduled tasks
+  task add <file>                  create a task from a JSON file
+  task delete|pause|resume|run <id>
+  task runs <id>                   list the recent runs of a task
+
+Flags:
+`
+
+func main() {
+	fs := flag.NewFlagSet("backend", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	host := fs.String("host", env("SB_HOST", "http://localhost:8099"), "StaticBackend instance URL")
+	pk := fs.String("pk", os.Getenv("SB_PUBLIC_KEY"), "public key of the database")
+	token := fs.String("token", os.Getenv("SB_ROOT_TOKEN"), "root token of the database")
+	fs.Parse(os.Args[1:])
+
+	args := fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	} else if len(*pk) == 0 || len(*token) == 0 {
+		fatal("the public key and root token are required, see backend -h")
+	}
+
+	c := newClient(*host, *pk, *token)
+
+	var err error
+	switch args[0] {
+	case "fn":
+		err = functions(c, args[1:])
+	case "query":
+		err = query(c, args[1:])
+	case "export":
+		err = export(c, args[1:])
+	case "import":
+		err = importDocs(c, args[1:])
+	case "codegen":
+		err = codegen(c, args[1:])
+	case "migrate":
+		err = migrate(c, args[1:])
+	case "task":
+		err = tasks(c, args[1:])
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fatal(err.Error())
+	}
+}
+
+func env(key, def string) string {
+	if v := os.Getenv(key); len(v) > 0 {
+		return strings.TrimSuffix(v, "/")
+	}
+	return def
+}
+
+func fatal(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
+
+// errUsage is returned for missing or unknown arguments
+func errUsage(cmd string) error {
+	return fmt.Errorf("invalid arguments for %s, see backend -h", cmd)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// migrate sends a Firebase or Supabase export to the import endpoint and
+// prints its progress
+func migrate(c *client, args []string) error {
+	if len(args) == 0 {
+		return errUsage("migrate")
+	}
+
+	source := args[0]
+
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	mapping := fs.String("mapping", "", "JSON file of the field mapping")
+	files := make(map[string]*string)
+	switch source {
+	case model.ImportFirebase:
+		files["users"] = fs.String("users", "", "Firebase Auth export (firebase auth:export)")
+		files["firestore"] = fs.String("firestore", "", "Firestore export in JSON")
+		files["storage"] = fs.String("storage", "", "JSON listing of the Cloud Storage objects")
+	case model.ImportSupabase:
+		files["dump"] = fs.String("dump", "", "data dump (supabase db dump --data-only --use-copy)")
+	default:
+		return errUsage("migrate")
+	}
+	fs.Parse(args[1:])
+
+	fields := map[string]string{"source": source}
+	if len(*mapping) > 0 {
+		b, err := os.ReadFile(*mapping)
+		if err != nil {
+			return err
+		}
+		fields["mapping"] = string(b)
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeImportForm(mw, fields, files))
+	}()
+
+	resp, err := c.sendAs("POST", "/sudo/_/import", mw.FormDataContentType(), pr)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		var evt struct {
+			Progress *model.ImportProgress `json:"progress"`
+			Report   *model.ImportReport   `json:"report"`
+			Error    string                `json:"error"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &evt); err != nil {
+			return err
+		}
+
+		switch {
+		case len(evt.Error) > 0:
+			return errors.New(evt.Error)
+		case evt.Progress != nil:
+			p := evt.Progress
+			fmt.Fprintf(os.Stderr, "%s: %d/%d (%d errors)\n", p.Stage, p.Done, p.Total, p.Errors)
+		case evt.Report != nil:
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(evt.Report)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return errors.New("the import ended without a report")
+}
+
+func writeImportForm(mw *multipart.Writer, fields map[string]string, files map[string]*string) error {
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+
+	for name, file := range files {
+		if len(*file) == 0 {
+			continue
+		}
+
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+
+		part, err := mw.CreateFormFile(name, *file)
+		if err == nil {
+			_, err = io.Copy(part, f)
+		}
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
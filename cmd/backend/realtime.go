@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// keepAliveInterval is how often an echo is sent on the realtime
+// connection so proxies do not close it
+const keepAliveInterval = 30 * time.Second
+
+// subscribe joins channel on the realtime connection as the root user and
+// calls fn for every message received until the connection is closed
+func (c *client) subscribe(channel string, fn func(model.Command)) error {
+	var jwt string
+	if err := c.do("GET", "/sudogettoken/"+c.accountID(), nil, &jwt); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", c.host+"/sse/connect", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("SB-PUBLIC-KEY", c.pubKey)
+
+	// the stream stays open, the client's timeout must not apply
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		return &apiError{Status: resp.StatusCode, Message: resp.Status}
+	}
+
+	var sid string
+
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var msg model.Command
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg); err != nil {
+			return err
+		}
+
+		switch msg.Type {
+		case model.MsgTypeInit:
+			sid = msg.Data
+			if err := c.publish(model.Command{SID: sid, Type: model.MsgTypeAuth, Data: jwt}); err != nil {
+				return err
+			}
+			go c.keepAlive(sid)
+		case model.MsgTypeToken:
+			join := model.Command{SID: sid, Type: model.MsgTypeJoin, Data: channel, Token: msg.Data}
+			if err := c.publish(join); err != nil {
+				return err
+			}
+		case model.MsgTypeError:
+			return fmt.Errorf("realtime: %s", msg.Data)
+		}
+
+		fn(msg)
+	}
+	return sc.Err()
+}
+
+// publish sends a message on the realtime connection sid
+func (c *client) publish(msg model.Command) error {
+	return c.do("POST", "/sse/msg", msg, nil)
+}
+
+func (c *client) keepAlive(sid string) {
+	for range time.Tick(keepAliveInterval) {
+		if err := c.publish(model.Command{SID: sid, Type: model.MsgTypeEcho}); err != nil {
+			return
+		}
+	}
+}
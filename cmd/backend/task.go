@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func tasks(c *client, args []string) error {
+	if len(args) == 0 {
+		return errUsage("task")
+	}
+
+	switch {
+	case args[0] == "list":
+		return listTasks(c)
+	case args[0] == "add" && len(args) == 2:
+		return addTask(c, args[1])
+	case args[0] == "delete" && len(args) == 2:
+		return c.do("DELETE", "/task/"+args[1], nil, nil)
+	case (args[0] == "pause" || args[0] == "resume") && len(args) == 2:
+		return c.do("POST", "/task/"+args[1]+"/"+args[0], nil, nil)
+	case args[0] == "run" && len(args) == 2:
+		var v struct {
+			RunID string `json:"runId"`
+		}
+		if err := c.do("POST", "/task/"+args[1]+"/run", nil, &v); err != nil {
+			return err
+		}
+
+		fmt.Printf("task %s started, run %s\n", args[1], v.RunID)
+		return nil
+	case args[0] == "runs" && len(args) == 2:
+		return listTaskRuns(c, args[1])
+	}
+	return errUsage("task")
+}
+
+func listTasks(c *client) error {
+	var list []model.Task
+	if err := c.do("GET", "/task", nil, &list); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTYPE\tVALUE\tINTERVAL\tENABLED\tNEXT RUN\tLAST STATUS")
+	for _, t := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\t%s\n",
+			t.ID, t.Name, t.Type, t.Value, t.Interval, t.Enabled, formatTime(t.NextRun), t.LastStatus)
+	}
+	return w.Flush()
+}
+
+// addTask creates the task described by the JSON file
+func addTask(c *client, file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var t model.Task
+	if err := c.do("POST", "/task", json.RawMessage(b), &t); err != nil {
+		return err
+	}
+
+	fmt.Printf("task %s created with id %s\n", t.Name, t.ID)
+	return nil
+}
+
+func listTaskRuns(c *client, id string) error {
+	var runs []model.TaskRun
+	if err := c.do("GET", "/task/"+id+"/runs", nil, &runs); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tATTEMPT\tDURATION\tSTATUS\tERROR")
+	for _, run := range runs {
+		status := "ok"
+		if !run.Success {
+			status = "failed"
+		}
+
+		var duration time.Duration
+		if !run.Completed.IsZero() {
+			duration = run.Completed.Sub(run.Started).Round(time.Millisecond)
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", formatTime(run.Started), run.Attempt, duration, status, run.Error)
+	}
+	return w.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -11,12 +11,10 @@ import (
 )
 
 func main() {
-	c := config.LoadConfig()
-
-	log := logger.Get(c)
-
 	var v bool
+	var path string
 	flag.BoolVar(&v, "v", false, "Display the version and build info")
+	flag.StringVar(&path, "config", "", "Path of the YAML or TOML configuration file, CONFIG_FILE by default")
 	flag.Parse()
 	if v {
 		fmt.Printf("StaticBackend version %s | %s (%s)\n\n",
@@ -27,6 +25,13 @@ func main() {
 		os.Exit(0)
 	}
 
+	c, err := config.Load(path)
+
+	log := logger.Get(c)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid configuration")
+	}
+
 	if len(c.Port) == 0 {
 		c.Port = "8099"
 	}
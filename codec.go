@@ -0,0 +1,122 @@
+package staticbackend
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/staticbackendhq/core/backend"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Binary formats the document and query endpoints can use instead of JSON
+const (
+	mimeMsgPack = "application/msgpack"
+	mimeCBOR    = "application/cbor"
+)
+
+var (
+	cborEnc, _ = cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	cborDec, _ = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+)
+
+// mediaType returns the binary format of an Accept or Content-Type header
+// value, an empty string is JSON.
+func mediaType(header string) string {
+	for _, v := range strings.Split(header, ",") {
+		t, _, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+
+		switch t {
+		case mimeMsgPack, "application/x-msgpack":
+			return mimeMsgPack
+		case mimeCBOR:
+			return mimeCBOR
+		case "application/json", "*/*":
+			return ""
+		}
+	}
+	return ""
+}
+
+// respondAs writes v in the format requested by the Accept header,
+// MessagePack, CBOR or JSON which is the default.
+func respondAs(w http.ResponseWriter, r *http.Request, code int, v interface{}) {
+	w.Header().Add("Vary", "Accept")
+
+	var b []byte
+	var err error
+
+	format := mediaType(r.Header.Get("Accept"))
+	switch format {
+	case mimeMsgPack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		// documents and results use their JSON field names
+		enc.SetCustomStructTag("json")
+		// JSON numbers are float64, whole numbers are sent as integers
+		enc.UseCompactFloats(true)
+		err = enc.Encode(v)
+		b = buf.Bytes()
+	case mimeCBOR:
+		b, err = cborEnc.Marshal(v)
+	default:
+		respond(w, code, v)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format)
+	w.WriteHeader(code)
+	if _, err := w.Write(b); err != nil {
+		backend.Log.Error().Err(err)
+	}
+}
+
+// decodeBody decodes the request body based on its Content-Type. Binary
+// bodies are converted to JSON values first so documents are stored the
+// same way regardless of the format they were sent with.
+func decodeBody(r *http.Request, v interface{}) error {
+	defer r.Body.Close()
+
+	format := mediaType(r.Header.Get("Content-Type"))
+	if len(format) == 0 {
+		return json.NewDecoder(r.Body).Decode(v)
+	}
+
+	var raw interface{}
+	switch format {
+	case mimeMsgPack:
+		if err := msgpack.NewDecoder(r.Body).Decode(&raw); err != nil {
+			return err
+		}
+	case mimeCBOR:
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		if err := cborDec.Unmarshal(b, &raw); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
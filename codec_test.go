@@ -0,0 +1,95 @@
+package staticbackend
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestDBMsgPack(t *testing.T) {
+	body, err := msgpack.Marshal(map[string]interface{}{"title": "msgpack", "count": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headers := map[string]string{"Content-Type": mimeMsgPack, "Accept": mimeMsgPack}
+
+	resp := rawReq(db.add, "POST", "/db/tasks", body, headers)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if ct := resp.Header.Get("Content-Type"); ct != mimeMsgPack {
+		t.Fatalf("expected content type %s got %s", mimeMsgPack, ct)
+	}
+
+	var saved map[string]interface{}
+	if err := msgpack.NewDecoder(resp.Body).Decode(&saved); err != nil {
+		t.Fatal(err)
+	} else if saved["title"] != "msgpack" {
+		t.Errorf("expected title to be msgpack got %v", saved["title"])
+	}
+
+	id, _ := saved["id"].(string)
+
+	resp = rawReq(db.get, "GET", "/db/tasks/"+id, nil, headers)
+	defer resp.Body.Close()
+
+	dec := msgpack.NewDecoder(resp.Body)
+	dec.SetCustomStructTag("json")
+
+	var task Task
+	if err := dec.Decode(&task); err != nil {
+		t.Fatal(err)
+	} else if task.Count != 3 {
+		t.Errorf("expected count to be 3 got %d", task.Count)
+	}
+}
+
+func TestDBQueryCBOR(t *testing.T) {
+	body, err := cbor.Marshal([][]interface{}{{"title", "=", "cbor"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create, err := cbor.Marshal(map[string]interface{}{"title": "cbor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headers := map[string]string{"Content-Type": mimeCBOR, "Accept": mimeCBOR}
+
+	resp := rawReq(db.add, "POST", "/db/tasks", create, headers)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	resp = rawReq(db.query, "POST", "/query/tasks", body, headers)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		Total   int64                    `json:"total"`
+		Results []map[string]interface{} `json:"results"`
+	}
+	if err := cbor.Unmarshal(b, &result); err != nil {
+		t.Fatal(err)
+	} else if result.Total == 0 || len(result.Results) == 0 {
+		t.Fatal("expected the created document in the results")
+	} else if result.Results[0]["title"] != "cbor" {
+		t.Errorf("expected title to be cbor got %v", result.Results[0]["title"])
+	}
+}
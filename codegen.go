@@ -0,0 +1,44 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/codegen"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+// generateClient returns the typed client models and CRUD wrappers of the
+// database's collections from GET /codegen?lang=ts|go, the package query
+// string parameter names the Go package
+func generateClient(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cols, err := backend.DB.ListCollections(conf.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	app := codegen.App{
+		Collections: cols,
+		Schemas:     conf.Settings.Schemas,
+		Package:     r.URL.Query().Get("package"),
+	}
+
+	code, err := codegen.Generate(lang, app)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="client.%s"`, lang))
+	w.Write(code)
+}
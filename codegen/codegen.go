@@ -0,0 +1,107 @@
+// Package codegen generates the typed client models of an application's
+// collections, from their schemas, with CRUD wrappers of the database API
+// in TypeScript and Go.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"strings"
+	"text/template"
+
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/openapi"
+)
+
+// Generated client languages
+const (
+	LangTypeScript = "ts"
+	LangGo         = "go"
+)
+
+// DefaultPackage is the package of the generated Go code
+const DefaultPackage = "sbclient"
+
+// App is what is generated for an application. The collections without a
+// schema are free-form documents.
+type App struct {
+	Collections []string
+	Schemas     []model.CollectionSchema
+	// Package is the package of the Go code, DefaultPackage when empty
+	Package string
+}
+
+// collection is a collection as rendered by the templates
+type collection struct {
+	Name string
+	// Type is the model's type and Prop the client's property
+	Type   string
+	Prop   string
+	Fields []field
+}
+
+type field struct {
+	Name     string
+	Ident    string
+	Type     string
+	Optional bool
+}
+
+// Generate returns the client code of an application in lang
+func Generate(lang string, app App) ([]byte, error) {
+	switch lang {
+	case LangTypeScript:
+		return render(tsTemplate, app, tsType)
+	case LangGo:
+		if len(app.Package) == 0 {
+			app.Package = DefaultPackage
+		} else if !token.IsIdentifier(app.Package) {
+			return nil, fmt.Errorf("invalid package name %q", app.Package)
+		}
+		return renderGo(app)
+	}
+	return nil, fmt.Errorf("the language must be %s or %s", LangTypeScript, LangGo)
+}
+
+// render executes tmpl with the collections, typeOf returns the type of
+// a schema field in the language
+func render(tmpl *template.Template, app App, typeOf func(model.SchemaField) string) ([]byte, error) {
+	var cols []collection
+	for _, cs := range openapi.CollectionSchemas(app.Collections, app.Schemas) {
+		col := collection{Name: cs.Collection, Type: typeName(cs.Collection)}
+		col.Prop = strings.ToLower(col.Type[:1]) + col.Type[1:]
+
+		for _, f := range cs.Fields {
+			// the id and accountId are part of every model
+			if f.Name == "id" || f.Name == "accountId" {
+				continue
+			}
+
+			col.Fields = append(col.Fields, field{
+				Name:     f.Name,
+				Ident:    typeName(f.Name),
+				Type:     typeOf(f),
+				Optional: !f.Required,
+			})
+		}
+		cols = append(cols, col)
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, struct {
+		Package     string
+		Collections []collection
+	}{app.Package, cols})
+	return buf.Bytes(), err
+}
+
+// typeName returns an exported identifier of a name, identifiers cannot
+// start with a digit
+func typeName(name string) string {
+	id := openapi.Identifier(name)
+	if len(id) == 0 || (id[0] >= '0' && id[0] <= '9') {
+		id = "X" + id
+	}
+	return id
+}
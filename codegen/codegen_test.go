@@ -0,0 +1,110 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+var app = App{
+	Collections: []string{"tasks", "sb_forms", "user_notes"},
+	Schemas: []model.CollectionSchema{
+		{
+			Collection: "tasks",
+			Fields: []model.SchemaField{
+				{Name: "title", Type: "string", Required: true},
+				{Name: "due", Type: "string", Format: "date-time"},
+				{Name: "done", Type: "boolean"},
+				{Name: "tags", Type: "array"},
+				{Name: "id", Type: "string"},
+			},
+		},
+	},
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	b, err := Generate(LangTypeScript, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := string(b)
+	for _, s := range []string{
+		"export interface Tasks {",
+		"  title: string;",
+		"  due?: string;",
+		"  done?: boolean;",
+		"  tags?: unknown[];",
+		"export interface UserNotes {",
+		"  [field: string]: unknown;",
+		"readonly userNotes: Collection<UserNotes>;",
+		`this.tasks = new Collection<Tasks>(this, "tasks");`,
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("expected %q in the generated code", s)
+		}
+	}
+
+	if strings.Contains(code, "SbForms") {
+		t.Error("expected the system collections to be excluded")
+	} else if strings.Count(code, "  id: string;") != 2 {
+		t.Error("expected the schema's id field to not be duplicated")
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	b, err := Generate(LangGo, App{Collections: app.Collections, Schemas: app.Schemas, Package: "models"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("models", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("the generated code does not compile: %v\n%s", err, b)
+	}
+
+	tasks, ok := pkg.Scope().Lookup("Tasks").Type().Underlying().(*types.Struct)
+	if !ok {
+		t.Fatal("expected Tasks to be a struct")
+	}
+
+	fields := make(map[string]string)
+	for i := 0; i < tasks.NumFields(); i++ {
+		fields[tasks.Field(i).Name()] = tasks.Field(i).Type().String()
+	}
+
+	if fields["Title"] != "string" || fields["Due"] != "*time.Time" || fields["Done"] != "*bool" {
+		t.Errorf("unexpected Tasks fields %v", fields)
+	} else if _, ok := pkg.Scope().Lookup("UserNotes").Type().Underlying().(*types.Map); !ok {
+		t.Error("expected UserNotes to be a map")
+	}
+
+	// without date-time fields the time package is not imported
+	b, err = Generate(LangGo, App{Collections: []string{"notes"}})
+	if err != nil {
+		t.Fatal(err)
+	} else if strings.Contains(string(b), `"time"`) {
+		t.Error("expected the time package to not be imported")
+	} else if !strings.Contains(string(b), "package "+DefaultPackage) {
+		t.Error("expected the default package")
+	}
+
+	if _, err := Generate("rust", app); err == nil {
+		t.Error("expected an error for an unknown language")
+	} else if _, err := Generate(LangGo, App{Package: "my-pkg"}); err == nil {
+		t.Error("expected an error for an invalid package name")
+	}
+}
@@ -0,0 +1,235 @@
+package codegen
+
+import (
+	"bytes"
+	"go/format"
+	"text/template"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func goType(f model.SchemaField) string {
+	var t string
+	switch f.Type {
+	case "string":
+		t = "string"
+		if f.Format == "date-time" {
+			t = "time.Time"
+		}
+	case "number":
+		t = "float64"
+	case "integer":
+		t = "int64"
+	case "boolean":
+		t = "bool"
+	case "object":
+		return "map[string]interface{}"
+	case "array":
+		return "[]interface{}"
+	default:
+		return "interface{}"
+	}
+
+	// optional scalars are pointers so their zero value is sent
+	if !f.Required {
+		t = "*" + t
+	}
+	return t
+}
+
+// renderGo renders and formats the Go client, the time package is only
+// imported when a model has a date-time field
+func renderGo(app App) ([]byte, error) {
+	b, err := render(goTemplate, app, goType)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Contains(b, []byte("time.Time")) {
+		b = bytes.Replace(b, []byte("\t\"time\"\n"), nil, 1)
+	}
+	return format.Source(b)
+}
+
+var goTemplate = template.Must(template.New("go").Parse(`// Code generated by StaticBackend. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+{{range .Collections}}
+// {{.Type}} is a document of the {{.Name}} collection
+{{- if .Fields}}
+type {{.Type}} struct {
+	ID        string ` + "`json:\"id,omitempty\"`" + `
+	AccountID string ` + "`json:\"accountId,omitempty\"`" + `
+{{- range .Fields}}
+	{{.Ident}} {{.Type}} ` + "`json:\"{{.Name}}{{if .Optional}},omitempty{{end}}\"`" + `
+{{- end}}
+}
+{{- else}}, its fields are free-form
+type {{.Type}} map[string]interface{}
+{{- end}}
+{{end}}
+// Page is a page of documents
+type Page[T any] struct {
+	Page    int64  ` + "`json:\"page\"`" + `
+	Size    int64  ` + "`json:\"size\"`" + `
+	Total   int64  ` + "`json:\"total\"`" + `
+	Next    string ` + "`json:\"next,omitempty\"`" + `
+	Prev    string ` + "`json:\"prev,omitempty\"`" + `
+	Results []T    ` + "`json:\"results\"`" + `
+}
+
+// ListParams are the pagination, sort and filter of a list, zero values
+// are the server defaults. The Cursor of a page's Next or Prev takes
+// precedence over the Page.
+type ListParams struct {
+	Page   int64
+	Cursor string
+	Size   int64
+	Sort   string
+	Desc   bool
+	Filter string
+}
+
+func (p ListParams) query() string {
+	qs := url.Values{}
+	if p.Page > 0 {
+		qs.Set("page", strconv.FormatInt(p.Page, 10))
+	}
+	if len(p.Cursor) > 0 {
+		qs.Set("cursor", p.Cursor)
+	}
+	if p.Size > 0 {
+		qs.Set("size", strconv.FormatInt(p.Size, 10))
+	}
+	if len(p.Sort) > 0 {
+		qs.Set("sort", p.Sort)
+	}
+	if p.Desc {
+		qs.Set("desc", "1")
+	}
+	if len(p.Filter) > 0 {
+		qs.Set("filter", p.Filter)
+	}
+	return qs.Encode()
+}
+
+// Client calls the database API with the public key and the user's session
+// token
+type Client struct {
+	BaseURL   string
+	PublicKey string
+	Token     string
+	HTTP      *http.Client
+{{range .Collections}}
+	{{.Type}} *Collection[{{.Type}}]
+{{- end}}
+}
+
+// NewClient returns a client of the API at baseURL
+func NewClient(baseURL, publicKey, token string) *Client {
+	c := &Client{BaseURL: baseURL, PublicKey: publicKey, Token: token, HTTP: http.DefaultClient}
+{{- range .Collections}}
+	c.{{.Type}} = &Collection[{{.Type}}]{client: c, name: "{{.Name}}"}
+{{- end}}
+	return c
+}
+
+// Error is a non 2xx response of the API
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d: %s", e.Status, e.Message)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, r)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("SB-PUBLIC-KEY", c.PublicKey)
+	if len(c.Token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		b, _ := io.ReadAll(resp.Body)
+		return &Error{Status: resp.StatusCode, Message: string(bytes.TrimSpace(b))}
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Collection wraps the database API of a collection
+type Collection[T any] struct {
+	client *Client
+	name   string
+}
+
+// List returns a page of the documents
+func (col *Collection[T]) List(ctx context.Context, params ListParams) (page Page[T], err error) {
+	err = col.client.do(ctx, "GET", "/db/"+col.name+"?"+params.query(), nil, &page)
+	return
+}
+
+// Query returns a page of the documents matching the clauses, [field,
+// operator, value] arrays
+func (col *Collection[T]) Query(ctx context.Context, clauses [][]interface{}, params ListParams) (page Page[T], err error) {
+	err = col.client.do(ctx, "POST", "/query/"+col.name+"?"+params.query(), clauses, &page)
+	return
+}
+
+// Get returns a document by id
+func (col *Collection[T]) Get(ctx context.Context, id string) (doc T, err error) {
+	err = col.client.do(ctx, "GET", "/db/"+col.name+"/"+id, nil, &doc)
+	return
+}
+
+// Create creates a document and returns it
+func (col *Collection[T]) Create(ctx context.Context, doc T) (created T, err error) {
+	err = col.client.do(ctx, "POST", "/db/"+col.name, doc, &created)
+	return
+}
+
+// Update updates the fields of a document and returns it
+func (col *Collection[T]) Update(ctx context.Context, id string, fields interface{}) (updated T, err error) {
+	err = col.client.do(ctx, "PUT", "/db/"+col.name+"/"+id, fields, &updated)
+	return
+}
+
+// Delete deletes a document and returns the number of deleted documents
+func (col *Collection[T]) Delete(ctx context.Context, id string) (n int64, err error) {
+	err = col.client.do(ctx, "DELETE", "/db/"+col.name+"/"+id, nil, &n)
+	return
+}
+`))
@@ -0,0 +1,144 @@
+package codegen
+
+import (
+	"text/template"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func tsType(f model.SchemaField) string {
+	switch f.Type {
+	case "string":
+		return "string"
+	case "number", "integer":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "object":
+		return "Record<string, unknown>"
+	case "array":
+		return "unknown[]"
+	}
+	return "unknown"
+}
+
+var tsTemplate = template.Must(template.New("ts").Parse(`// Code generated by StaticBackend. DO NOT EDIT.
+{{range .Collections}}
+/** A document of the {{.Name}} collection{{if not .Fields}}, its fields are free-form{{end}} */
+export interface {{.Type}} {
+  id: string;
+  accountId: string;
+{{- range .Fields}}
+  {{.Name}}{{if .Optional}}?{{end}}: {{.Type}};
+{{- end}}
+{{- if not .Fields}}
+  [field: string]: unknown;
+{{- end}}
+}
+{{end}}
+/** The fields of a document set by the server */
+export type NewDocument<T> = Omit<T, "id" | "accountId">;
+
+/** A page of documents */
+export interface Page<T> {
+  page: number;
+  size: number;
+  total: number;
+  next?: string;
+  prev?: string;
+  results: T[];
+}
+
+/** The pagination, sort and filter of a list */
+export interface ListParams {
+  page?: number;
+  cursor?: string;
+  size?: number;
+  sort?: string;
+  desc?: boolean;
+  filter?: string;
+}
+
+/** A query clause: field, operator and value */
+export type QueryClause = [string, string, unknown];
+
+/** A non 2xx response of the API */
+export class APIError extends Error {
+  constructor(public status: number, message: string) {
+    super(message);
+  }
+}
+
+function query(params: ListParams): string {
+  const qs = new URLSearchParams();
+  if (params.page) qs.set("page", String(params.page));
+  if (params.cursor) qs.set("cursor", params.cursor);
+  if (params.size) qs.set("size", String(params.size));
+  if (params.sort) qs.set("sort", params.sort);
+  if (params.desc) qs.set("desc", "1");
+  if (params.filter) qs.set("filter", params.filter);
+  return qs.toString();
+}
+
+/** Wraps the database API of a collection */
+export class Collection<T> {
+  constructor(private client: Client, private name: string) {}
+
+  list(params: ListParams = {}): Promise<Page<T>> {
+    return this.client.request("GET", ` + "`/db/${this.name}?${query(params)}`" + `);
+  }
+
+  query(clauses: QueryClause[], params: ListParams = {}): Promise<Page<T>> {
+    return this.client.request("POST", ` + "`/query/${this.name}?${query(params)}`" + `, clauses);
+  }
+
+  get(id: string): Promise<T> {
+    return this.client.request("GET", ` + "`/db/${this.name}/${id}`" + `);
+  }
+
+  create(doc: NewDocument<T>): Promise<T> {
+    return this.client.request("POST", ` + "`/db/${this.name}`" + `, doc);
+  }
+
+  update(id: string, fields: Partial<NewDocument<T>>): Promise<T> {
+    return this.client.request("PUT", ` + "`/db/${this.name}/${id}`" + `, fields);
+  }
+
+  delete(id: string): Promise<number> {
+    return this.client.request("DELETE", ` + "`/db/${this.name}/${id}`" + `);
+  }
+}
+
+/** Calls the database API with the public key and the user's session token */
+export class Client {
+{{- range .Collections}}
+  readonly {{.Prop}}: Collection<{{.Type}}>;
+{{- end}}
+
+  constructor(private baseURL: string, private publicKey: string, public token?: string) {
+{{- range .Collections}}
+    this.{{.Prop}} = new Collection<{{.Type}}>(this, "{{.Name}}");
+{{- end}}
+  }
+
+  async request<R>(method: string, path: string, body?: unknown): Promise<R> {
+    const headers: Record<string, string> = {
+      "Content-Type": "application/json",
+      "SB-PUBLIC-KEY": this.publicKey,
+    };
+    if (this.token) {
+      headers["Authorization"] = ` + "`Bearer ${this.token}`" + `;
+    }
+
+    const resp = await fetch(this.baseURL + path, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (!resp.ok) {
+      throw new APIError(resp.status, (await resp.text()).trim());
+    }
+    return resp.json();
+  }
+}
+`))
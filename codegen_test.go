@@ -0,0 +1,33 @@
+package staticbackend
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGenerateClient(t *testing.T) {
+	resp := dbReq(t, generateClient, "GET", "/codegen?lang=go&package=models", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(string(b), "package models") {
+		t.Errorf("expected the models package got %s", b)
+	} else if strings.Contains(string(b), "SbAccounts") {
+		t.Error("expected the system collections to be excluded")
+	}
+
+	resp = dbReq(t, generateClient, "GET", "/codegen?lang=cobol", nil, true)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown language got %d", resp.StatusCode)
+	}
+}
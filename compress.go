@@ -0,0 +1,191 @@
+package staticbackend
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// compressibleTypes are the content types worth compressing, media files
+// are already compressed
+var compressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/wasm",
+	"application/x-javascript",
+	"image/svg+xml",
+	"font/ttf",
+	"font/otf",
+}
+
+// compressible returns whether a content type is worth compressing
+func compressible(contentType string) bool {
+	ct, _, _ := strings.Cut(contentType, ";")
+	ct = strings.ToLower(strings.TrimSpace(ct))
+	for _, t := range compressibleTypes {
+		if strings.HasPrefix(ct, t) {
+			return true
+		}
+	}
+	return strings.HasSuffix(ct, "+json") || strings.HasSuffix(ct, "+xml")
+}
+
+// acceptsEncoding returns whether the Accept-Encoding header of a request
+// accepts the content coding, ignoring codings with q=0
+func acceptsEncoding(r *http.Request, coding string) bool {
+	for _, v := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(v), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), coding) {
+			continue
+		}
+
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
+
+// encodedETag returns the entity tag of an encoded representation
+func encodedETag(etag, coding string) string {
+	if len(etag) == 0 {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + coding + `"`
+}
+
+// compressFiles gzips the compressible files of complete responses. Range
+// requests are served uncompressed since ranges apply to the encoded
+// content.
+func compressFiles(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if r.Method != http.MethodGet || len(r.Header.Get("Range")) > 0 || !acceptsEncoding(r, "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the gzip ETag matches the file's one once its suffix is removed
+		if inm := r.Header.Get("If-None-Match"); len(inm) > 0 {
+			r.Header.Set("If-None-Match", strings.ReplaceAll(inm, `-gzip"`, `"`))
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.Close()
+
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// gzipResponseWriter compresses the body of 200 responses with a
+// compressible Content-Type
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (gw *gzipResponseWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+
+	h := gw.Header()
+	if code == http.StatusOK && len(h.Get("Content-Encoding")) == 0 && compressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		if etag := h.Get("ETag"); len(etag) > 0 {
+			h.Set("ETag", encodedETag(etag, "gzip"))
+		}
+
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+
+	gw.ResponseWriter.WriteHeader(code)
+}
+
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		if len(gw.Header().Get("Content-Type")) == 0 {
+			gw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		gw.WriteHeader(http.StatusOK)
+	}
+
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	return gw.ResponseWriter.Write(b)
+}
+
+// Close flushes the compressed content
+func (gw *gzipResponseWriter) Close() error {
+	if gw.gz == nil {
+		return nil
+	}
+	return gw.gz.Close()
+}
+
+// precompressedFiles serves the brotli (.br) or gzip (.gz) copy of a local
+// file when it exists and the client accepts the encoding, i.e. assets
+// compressed at build time
+func precompressedFiles(dir string, next http.Handler) http.Handler {
+	encodings := []struct {
+		coding string
+		ext    string
+	}{
+		{"br", ".br"},
+		{"gzip", ".gz"},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+		for _, enc := range encodings {
+			if !acceptsEncoding(r, enc.coding) {
+				continue
+			}
+
+			f, err := os.Open(name + enc.ext)
+			if err != nil {
+				continue
+			}
+			defer f.Close()
+
+			fi, err := f.Stat()
+			if err != nil || fi.IsDir() {
+				continue
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Accept-Encoding")
+			h.Set("Content-Encoding", enc.coding)
+			// the content type cannot be sniffed from the encoded content
+			ct := mime.TypeByExtension(path.Ext(name))
+			if len(ct) == 0 {
+				ct = "application/octet-stream"
+			}
+			h.Set("Content-Type", ct)
+			if etag := h.Get("ETag"); len(etag) > 0 {
+				h.Set("ETag", encodedETag(etag, enc.coding))
+			}
+
+			http.ServeContent(w, r, path.Base(name), fi.ModTime(), f)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,111 @@
+package staticbackend
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompressFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	content := strings.Repeat("compressible content ", 100)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(dir, "a.png"), []byte("\x89PNG\r\n\x1a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := compressFiles(http.FileServer(http.Dir(dir)))
+
+	req := httptest.NewRequest("GET", "/a.txt", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding got %v", w.Header())
+	}
+
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(b) != content {
+		t.Errorf("unexpected decompressed content")
+	}
+
+	// media files are already compressed
+	req = httptest.NewRequest("GET", "/a.png", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if enc := w.Header().Get("Content-Encoding"); len(enc) > 0 {
+		t.Errorf("expected no encoding for images got %s", enc)
+	}
+
+	// ranges are served from the identity content
+	req = httptest.NewRequest("GET", "/a.txt", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Range", "bytes=0-12")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent {
+		t.Errorf("expected 206 got %d", w.Code)
+	} else if w.Body.String() != "compressible " {
+		t.Errorf("expected the range got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/a.txt", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if enc := w.Header().Get("Content-Encoding"); len(enc) > 0 {
+		t.Errorf("expected no encoding when refused got %s", enc)
+	}
+}
+
+func TestPrecompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0644); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(dir, "app.js.br"), []byte("brotli"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := precompressedFiles(dir, http.FileServer(http.Dir(dir)))
+
+	req := httptest.NewRequest("GET", "/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("expected br encoding got %v", w.Header())
+	} else if w.Body.String() != "brotli" {
+		t.Errorf("expected the precompressed content got %s", w.Body.String())
+	} else if !strings.Contains(w.Header().Get("Content-Type"), "javascript") {
+		t.Errorf("expected a javascript Content-Type got %s", w.Header().Get("Content-Type"))
+	}
+
+	req = httptest.NewRequest("GET", "/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Body.String() != "console.log(1)" {
+		t.Errorf("expected the original content got %s", w.Body.String())
+	}
+}
@@ -1,6 +1,11 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
 
 var Current AppConfig
 
@@ -25,11 +30,20 @@ type AppConfig struct {
 	DataStore string
 	// DatabaseURL is the database URL
 	DatabaseURL string
+	// DatabaseMaxOpenConns and DatabaseMaxIdleConns limit the connections
+	// pool shared by all the apps' databases, 0 keeps the driver's default
+	DatabaseMaxOpenConns int
+	DatabaseMaxIdleConns int
 
 	// StorageProvider used as the file storage implementation
 	StorageProvider string
 	// LocalStorageURLURL for files when using local storage provider
 	LocalStorageURL string
+	// LocalStoragePath directory where the local storage provider saves
+	// files, defaults to the OS temp directory
+	LocalStoragePath string
+	// FileCacheControl default Cache-Control header of the served files
+	FileCacheControl string
 
 	// MailProvider used as the sending mails implementeation
 	MailProvider string
@@ -37,6 +51,21 @@ type AppConfig struct {
 	FromEmail string
 	// FromName used when SB sends email
 	FromName string
+	// SMTPHost host:port of the SMTP server when MailProvider is smtp, port
+	// 587 is used when not specified
+	SMTPHost string
+	// SMTPUsername if the SMTP server requires authentication
+	SMTPUsername string
+	// SMTPPassword if the SMTP server requires authentication
+	SMTPPassword string
+	// MailAPIKey API key of the sendgrid and mailgun mail providers or the
+	// server token of postmark
+	MailAPIKey string
+	// MailgunDomain sending domain of the mailgun mail provider
+	MailgunDomain string
+	// MailRegion region of the ses mail provider, AWSRegion by default, or
+	// "eu" for the EU region of the mailgun mail provider
+	MailRegion string
 
 	// StripeKey used for Stripe communication
 	StripeKey string
@@ -50,6 +79,9 @@ type AppConfig struct {
 	StripePriceIDGrowth string
 	// StripeWebhookSecret used when Stripe sends a webhook
 	StripeWebhookSecret string
+	// BillingPlans path of a JSON file defining the plans, their Stripe
+	// price and quotas, replacing the default plans
+	BillingPlans string
 
 	// TwilioAccountID used when sending SMS text messages via Twilio API
 	TwilioAccountID string
@@ -66,6 +98,22 @@ type AppConfig struct {
 	RedisHost string
 	// RedisPassword if RedisURL is not used, password for Redis
 	RedisPassword string
+	// RedisAddrs if RedisURL is not used, comma-separated list of Redis
+	// Cluster nodes or Sentinel addresses (host:port)
+	RedisAddrs string
+	// RedisSentinelMaster name of the master when using Redis Sentinel
+	RedisSentinelMaster string
+	// Region name of this deployment's region when running in multiple
+	// regions
+	Region string
+	// RegionURLs comma-separated name=URL of the regions' deployments,
+	// i.e. "ca=https://ca.example.com,eu=https://eu.example.com", the
+	// tenants choose the region keeping their data when they create a
+	// database
+	RegionURLs string
+	// RegionPeers comma-separated Redis URLs of the other regions, the
+	// tenant metadata and realtime messages are replicated to them
+	RegionPeers string
 
 	// AWSRegion region for AWS
 	AWSRegion string
@@ -73,6 +121,42 @@ type AppConfig struct {
 	AWSS3Bucket string
 	// AWSCDNURL CDN URL
 	AWSCDNURL string
+	// AWSS3Endpoint custom endpoint for S3-compatible services like MinIO,
+	// Cloudflare R2 or Backblaze B2
+	AWSS3Endpoint string
+	// AWSS3Region overrides AWSRegion for the S3 bucket
+	AWSS3Region string
+	// AWSS3ForcePathStyle uses bucket in path URLs (endpoint/bucket/key),
+	// required by MinIO
+	AWSS3ForcePathStyle bool
+	// AWSS3NoACL does not send the public-read ACL, for services that do
+	// not support object ACLs like R2 and B2
+	AWSS3NoACL bool
+
+	// AzureStorageAccount Azure storage account name
+	AzureStorageAccount string
+	// AzureStorageKey Azure storage account access key (base64)
+	AzureStorageKey string
+	// AzureStorageContainer blob container, it must allow public read
+	// access to blobs for file URLs to be reachable
+	AzureStorageContainer string
+	// AzureStorageEndpoint custom blob endpoint, i.e. Azurite or sovereign
+	// clouds, defaults to https://{account}.blob.core.windows.net
+	AzureStorageEndpoint string
+
+	// GCSBucket Google Cloud Storage bucket, it must allow public read
+	// access to objects for file URLs to be reachable
+	GCSBucket string
+	// GCSCredentials path of the service account JSON file used to access
+	// the bucket
+	GCSCredentials string
+	// GCSEndpoint custom endpoint, i.e. for an emulator
+	GCSEndpoint string
+
+	// StorageProviders comma separated list of additional storage providers
+	// databases can select in their settings, StorageProvider remains the
+	// default
+	StorageProviders string
 
 	// KeepPermissionInName if "yes" will keep the repo permission in repo name
 	KeepPermissionInName bool
@@ -82,13 +166,145 @@ type AppConfig struct {
 
 	// LogFilename if set, write logs to console and this file.
 	LogFilename string
+	// LogFormat "json" writes the console logs as JSON lines instead of
+	// human readable ones
+	LogFormat string
+	// LogSinkURL if set, the JSON logs are also POSTed in batches of
+	// newline-delimited JSON to this URL
+	LogSinkURL string
 	// NoFullTextSearch prevents full-text search index from initializing
 	NoFullTextSearch bool
-	// FullTextIndexFile fully qualify file path for the search index
+	// FullTextIndexFile fully qualify path of the directory holding the
+	// search index of each database
 	// Hint: this is usually on a disk that do not vanish on each deployment.
 	FullTextIndexFile string
 	// ActivateFlag when set, the /account/init can bypass Stripe if matching val
 	ActivateFlag string
+	// AuditRetentionDays number of days the auth audit events are kept (0 keeps
+	// them forever)
+	AuditRetentionDays int
+	// EventRetentionDays number of days the app events are kept, their daily
+	// rollups are kept forever (0 keeps them forever)
+	EventRetentionDays int
+	// RateLimit when set, limits the requests per minute of each public key
+	// by route class (auth, write, read) and the realtime connections and
+	// messages based on the tenant's plan
+	RateLimit bool
+	// StorageQuotas when set, limits the bytes stored by each database
+	// based on the tenant's plan
+	StorageQuotas bool
+	// EmailQuotas when set, limits the emails sent each month by each
+	// tenant based on its plan
+	EmailQuotas bool
+	// FunctionQuotas when set, limits the function runs each month of each
+	// database based on the tenant's plan
+	FunctionQuotas bool
+	// FunctionTimeout seconds a function can run before being killed (60
+	// when 0), FunctionMaxOutput the bytes of log output kept per run and
+	// FunctionMaxDBCalls the database calls a run can make (unlimited when
+	// 0). The databases' settings can lower them.
+	FunctionTimeout    int
+	FunctionMaxOutput  int
+	FunctionMaxDBCalls int
+	// RealtimeKeepAlive seconds between keep-alive pings on realtime
+	// connections (-1 disables them)
+	RealtimeKeepAlive int
+	// RealtimeIdleTimeout seconds after which a realtime connection that did
+	// not send any message is closed (0 keeps them open)
+	RealtimeIdleTimeout int
+	// RealtimeMessageRate maximum messages per second a realtime connection
+	// can send (0 is unlimited)
+	RealtimeMessageRate int
+	// RealtimeMaxMessageSize maximum size in bytes of a realtime message
+	RealtimeMaxMessageSize int
+	// RealtimeCompression when set, gzips the realtime event stream
+	RealtimeCompression bool
+	// MaxDocumentSize, MaxFormSize, MaxFunctionSize and MaxUploadSize are
+	// the maximum request body sizes in bytes of the data, form, function
+	// and upload endpoints (0 keeps the defaults)
+	MaxDocumentSize int
+	MaxFormSize     int
+	MaxFunctionSize int
+	MaxUploadSize   int
+	// TrustedProxies comma-separated CIDR ranges of the proxies whose
+	// X-Forwarded-For header is trusted, the loopback and private ranges
+	// when empty
+	TrustedProxies string
+	// ShutdownTimeout seconds the in-flight requests and running functions
+	// have to complete on SIGTERM before the server exits (30 when 0)
+	ShutdownTimeout int
+	// VAPIDPrivateKey base64url encoded P-256 private key used to send Web
+	// Push notifications
+	VAPIDPrivateKey string
+	// VAPIDSubject contact (mailto: or https: URL) sent to the push services
+	VAPIDSubject string
+	// FCMCredentials path of the Firebase service account JSON file used to
+	// send FCM notifications
+	FCMCredentials string
+	// KafkaRESTURL when set, system events are mirrored onto Kafka topics
+	// through this Kafka REST Proxy
+	KafkaRESTURL string
+	// KafkaTopics comma-separated event=topic mapping, i.e.
+	// "document.*=sb-documents,function.run=sb-functions,auth.*=sb-auth"
+	KafkaTopics string
+	// KafkaUsername and KafkaPassword optional REST Proxy basic auth
+	KafkaUsername string
+	KafkaPassword string
+
+	// ClamdAddress when set, uploaded files are scanned by this clamd
+	// daemon, i.e. "unix:/var/run/clamav/clamd.ctl" or "tcp:127.0.0.1:3310"
+	ClamdAddress string
+	// ScanAPIURL when set (and no clamd address), uploaded files are POSTed
+	// to this scanning API with ScanAPIKey as bearer token
+	ScanAPIURL string
+	ScanAPIKey string
+	// QuarantinePath directory where infected files are kept, they're
+	// discarded when empty
+	QuarantinePath string
+	// FeatureFlags comma-separated flags enabled for all the tenants, they
+	// can be disabled per tenant from the admin endpoints
+	FeatureFlags string
+	// BackupKey when set, the database backups are encrypted with this key
+	// instead of the AppSecret
+	BackupKey string
+	// VaultMasterKey when set, the data keys of the apps' secrets are
+	// wrapped with this key instead of the AppSecret. VaultKMSKeyID
+	// replaces it by an AWS KMS key. The VaultPreviousKeys are the
+	// comma-separated previous master keys, the secrets they wrapped are
+	// rewrapped with the current one.
+	VaultMasterKey    string
+	VaultKMSKeyID     string
+	VaultPreviousKeys string
+
+	// ACMEEmail when set, the verified custom domains of the apps are
+	// served over HTTPS on TLSPort (443 when empty) with certificates
+	// obtained via ACME, Let's Encrypt unless ACMEDirectoryURL is set. The
+	// certificates are kept in ACMECacheDir or in the volatile cache when
+	// empty.
+	ACMEEmail        string
+	ACMEDirectoryURL string
+	ACMECacheDir     string
+	TLSPort          string
+
+	// AdminToken when set, enables the instance's admin endpoints which
+	// require it as bearer token, i.e. to suspend a database
+	AdminToken string
+
+	// TracingExporter when set, OpenTelemetry spans are exported with
+	// "otlp" (configured by the OTEL_EXPORTER_OTLP_* variables) or "stdout"
+	TracingExporter string
+
+	// ConfigFile path of the YAML or TOML configuration file overriding the
+	// environment variables, see File
+	ConfigFile string
+	// CORSOrigins instance-wide origins allowed to call the API from a
+	// browser, any origin when empty. The databases' CORS settings can
+	// restrict them further.
+	CORSOrigins []string
+	// RequestsPerMinute overrides the rate limits of the plans by name or
+	// ID, RouteFactors the factors of the route classes
+	RequestsPerMinute map[string]int64
+	RouteFactors      map[string]float64
 }
 
 func LoadConfig() AppConfig {
@@ -101,20 +317,36 @@ func LoadConfig() AppConfig {
 		FromCLI:                 os.Getenv("SB_FROM_CLI"),
 		DataStore:               os.Getenv("DATA_STORE"),
 		DatabaseURL:             os.Getenv("DATABASE_URL"),
+		DatabaseMaxOpenConns:    atoi(os.Getenv("DATABASE_MAX_OPEN_CONNS")),
+		DatabaseMaxIdleConns:    atoi(os.Getenv("DATABASE_MAX_IDLE_CONNS")),
 		MailProvider:            os.Getenv("MAIL_PROVIDER"),
 		FromEmail:               os.Getenv("FROM_EMAIL"),
 		FromName:                os.Getenv("FROM_NAME"),
+		SMTPHost:                os.Getenv("SMTP_HOST"),
+		SMTPUsername:            os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:            os.Getenv("SMTP_PASSWORD"),
+		MailAPIKey:              os.Getenv("MAIL_API_KEY"),
+		MailgunDomain:           os.Getenv("MAILGUN_DOMAIN"),
+		MailRegion:              os.Getenv("MAIL_REGION"),
 		StorageProvider:         os.Getenv("STORAGE_PROVIDER"),
 		LocalStorageURL:         os.Getenv("LOCAL_STORAGE_URL"),
+		LocalStoragePath:        os.Getenv("LOCAL_STORAGE_PATH"),
+		FileCacheControl:        os.Getenv("FILE_CACHE_CONTROL"),
 		RedisURL:                os.Getenv("REDIS_URL"),
 		RedisHost:               os.Getenv("REDIS_HOST"),
 		RedisPassword:           os.Getenv("REDIS_PASSWORD"),
+		RedisAddrs:              os.Getenv("REDIS_ADDRS"),
+		RedisSentinelMaster:     os.Getenv("REDIS_SENTINEL_MASTER"),
+		Region:                  os.Getenv("REGION"),
+		RegionURLs:              os.Getenv("REGION_URLS"),
+		RegionPeers:             os.Getenv("REGION_PEERS"),
 		StripeKey:               os.Getenv("STRIPE_KEY"),
 		StripePriceIDIdea:       os.Getenv("STRIPE_PRICEID_IDEA"),
 		StripePriceIDLaunch:     os.Getenv("STRIPE_PRICEID_LAUNCH"),
 		StripePriceIDTraction:   os.Getenv("STRIPE_PRICEID_TRACTION"),
 		StripePriceIDGrowth:     os.Getenv("STRIPE_PRICEID_GROWTH"),
 		StripeWebhookSecret:     os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		BillingPlans:            os.Getenv("BILLING_PLANS"),
 		TwilioAccountID:         os.Getenv("TWILIO_ACCOUNTSID"),
 		TwilioAuthToken:         os.Getenv("TWILIO_AUTHTOKEN"),
 		TwilioTestCellNumber:    os.Getenv("MY_CELL"),
@@ -122,10 +354,198 @@ func LoadConfig() AppConfig {
 		AWSRegion:               os.Getenv("AWS_REGION"),
 		AWSCDNURL:               os.Getenv("AWS_CDN_URL"),
 		AWSS3Bucket:             os.Getenv("AWS_S3_BUCKET"),
+		AWSS3Endpoint:           os.Getenv("AWS_S3_ENDPOINT"),
+		AWSS3Region:             os.Getenv("AWS_S3_REGION"),
+		AWSS3ForcePathStyle:     len(os.Getenv("AWS_S3_FORCE_PATH_STYLE")) > 0,
+		AWSS3NoACL:              len(os.Getenv("AWS_S3_NO_ACL")) > 0,
+		AzureStorageAccount:     os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		AzureStorageKey:         os.Getenv("AZURE_STORAGE_KEY"),
+		AzureStorageContainer:   os.Getenv("AZURE_STORAGE_CONTAINER"),
+		AzureStorageEndpoint:    os.Getenv("AZURE_STORAGE_ENDPOINT"),
+		GCSBucket:               os.Getenv("GCS_BUCKET"),
+		GCSCredentials:          os.Getenv("GCS_CREDENTIALS"),
+		GCSEndpoint:             os.Getenv("GCS_ENDPOINT"),
+		StorageProviders:        os.Getenv("STORAGE_PROVIDERS"),
 		KeepPermissionInName:    os.Getenv("KEEP_PERM_COL_NAME") == "",
 		LogConsoleLevel:         os.Getenv("LOG_CONSOLE_LEVEL"),
 		LogFilename:             os.Getenv("LOG_FILENAME"),
+		LogFormat:               os.Getenv("LOG_FORMAT"),
+		LogSinkURL:              os.Getenv("LOG_SINK_URL"),
 		FullTextIndexFile:       os.Getenv("FTS_INDEX_FILE"),
 		ActivateFlag:            os.Getenv("ACTIVATE_FLAG"),
+		AuditRetentionDays:      atoi(os.Getenv("AUDIT_RETENTION_DAYS")),
+		EventRetentionDays:      atoi(os.Getenv("EVENT_RETENTION_DAYS")),
+		RateLimit:               len(os.Getenv("RATE_LIMIT")) > 0,
+		StorageQuotas:           len(os.Getenv("STORAGE_QUOTAS")) > 0,
+		EmailQuotas:             len(os.Getenv("EMAIL_QUOTAS")) > 0,
+		FunctionQuotas:          len(os.Getenv("FUNCTION_QUOTAS")) > 0,
+		FunctionTimeout:         atoi(os.Getenv("FUNCTION_TIMEOUT")),
+		FunctionMaxOutput:       atoi(os.Getenv("FUNCTION_MAX_OUTPUT")),
+		FunctionMaxDBCalls:      atoi(os.Getenv("FUNCTION_MAX_DB_CALLS")),
+		RealtimeKeepAlive:       atoi(os.Getenv("REALTIME_KEEPALIVE")),
+		RealtimeIdleTimeout:     atoi(os.Getenv("REALTIME_IDLE_TIMEOUT")),
+		RealtimeMessageRate:     atoi(os.Getenv("REALTIME_MESSAGE_RATE")),
+		RealtimeMaxMessageSize:  atoi(os.Getenv("REALTIME_MAX_MESSAGE_SIZE")),
+		RealtimeCompression:     len(os.Getenv("REALTIME_COMPRESSION")) > 0,
+		MaxDocumentSize:         atoi(os.Getenv("MAX_DOCUMENT_SIZE")),
+		MaxFormSize:             atoi(os.Getenv("MAX_FORM_SIZE")),
+		MaxFunctionSize:         atoi(os.Getenv("MAX_FUNCTION_SIZE")),
+		MaxUploadSize:           atoi(os.Getenv("MAX_UPLOAD_SIZE")),
+		TrustedProxies:          os.Getenv("TRUSTED_PROXIES"),
+		ShutdownTimeout:         atoi(os.Getenv("SHUTDOWN_TIMEOUT")),
+		VAPIDPrivateKey:         os.Getenv("VAPID_PRIVATE_KEY"),
+		VAPIDSubject:            os.Getenv("VAPID_SUBJECT"),
+		FCMCredentials:          os.Getenv("FCM_CREDENTIALS"),
+		KafkaRESTURL:            os.Getenv("KAFKA_REST_URL"),
+		KafkaTopics:             os.Getenv("KAFKA_TOPICS"),
+		KafkaUsername:           os.Getenv("KAFKA_USERNAME"),
+		KafkaPassword:           os.Getenv("KAFKA_PASSWORD"),
+		ClamdAddress:            os.Getenv("CLAMD_ADDRESS"),
+		ScanAPIURL:              os.Getenv("SCAN_API_URL"),
+		ScanAPIKey:              os.Getenv("SCAN_API_KEY"),
+		AdminToken:              os.Getenv("ADMIN_TOKEN"),
+		QuarantinePath:          os.Getenv("QUARANTINE_PATH"),
+		BackupKey:               os.Getenv("BACKUP_KEY"),
+		FeatureFlags:            os.Getenv("FEATURE_FLAGS"),
+		VaultMasterKey:          os.Getenv("VAULT_MASTER_KEY"),
+		VaultKMSKeyID:           os.Getenv("VAULT_KMS_KEY_ID"),
+		VaultPreviousKeys:       os.Getenv("VAULT_PREVIOUS_KEYS"),
+		TracingExporter:         os.Getenv("TRACING_EXPORTER"),
+		ACMEEmail:               os.Getenv("ACME_EMAIL"),
+		ACMEDirectoryURL:        os.Getenv("ACME_DIRECTORY_URL"),
+		ACMECacheDir:            os.Getenv("ACME_CACHE_DIR"),
+		TLSPort:                 os.Getenv("TLS_PORT"),
+		ConfigFile:              os.Getenv("CONFIG_FILE"),
+		CORSOrigins:             split(os.Getenv("CORS_ORIGINS")),
+	}
+}
+
+// Load returns the configuration of the environment variables overridden
+// by the configuration file, the path or the CONFIG_FILE variable when
+// empty. The configuration is validated.
+func Load(path string) (AppConfig, error) {
+	c := LoadConfig()
+	if len(path) > 0 {
+		c.ConfigFile = path
+	}
+
+	if len(c.ConfigFile) > 0 {
+		f, err := ReadFile(c.ConfigFile)
+		if err != nil {
+			return c, err
+		}
+		f.Apply(&c)
+	}
+	return c, c.Validate()
+}
+
+// Reload reads the configuration file again and returns the configuration
+// with its hot-reloadable values replaced: the rate limits and the CORS
+// origins. The configuration is unchanged when the file is invalid.
+func Reload(c AppConfig) (AppConfig, error) {
+	next, err := Load(c.ConfigFile)
+	if err != nil {
+		return c, err
+	}
+
+	c.RequestsPerMinute = next.RequestsPerMinute
+	c.RouteFactors = next.RouteFactors
+	c.CORSOrigins = next.CORSOrigins
+	return c, nil
+}
+
+// Validate returns an error for the first invalid value of the
+// configuration
+func (c AppConfig) Validate() error {
+	if !oneOf(c.DataStore, "", "mem", "memory", "mongo", "sqlite", "pg", "postgres", "postgresql") {
+		return fmt.Errorf("invalid data store %q", c.DataStore)
+	}
+
+	storageProviders := []string{"", "local", "s3", "azure", "gcs"}
+	if !oneOf(c.StorageProvider, storageProviders...) {
+		return fmt.Errorf("invalid storage provider %q", c.StorageProvider)
+	}
+	for _, name := range split(c.StorageProviders) {
+		if !oneOf(name, storageProviders...) {
+			return fmt.Errorf("invalid storage provider %q", name)
+		}
+	}
+
+	if !oneOf(c.MailProvider, "", "dev", "ses", "smtp", "sendgrid", "mailgun", "postmark") {
+		return fmt.Errorf("invalid mail provider %q", c.MailProvider)
+	}
+
+	ints := map[string]int{
+		"database max open conns":   c.DatabaseMaxOpenConns,
+		"database max idle conns":   c.DatabaseMaxIdleConns,
+		"audit retention days":      c.AuditRetentionDays,
+		"event retention days":      c.EventRetentionDays,
+		"realtime idle timeout":     c.RealtimeIdleTimeout,
+		"realtime message rate":     c.RealtimeMessageRate,
+		"realtime max message size": c.RealtimeMaxMessageSize,
+		"max document size":         c.MaxDocumentSize,
+		"max form size":             c.MaxFormSize,
+		"max function size":         c.MaxFunctionSize,
+		"max upload size":           c.MaxUploadSize,
+		"shutdown timeout":          c.ShutdownTimeout,
+		"function timeout":          c.FunctionTimeout,
+		"function max output":       c.FunctionMaxOutput,
+		"function max db calls":     c.FunctionMaxDBCalls,
+	}
+	for name, v := range ints {
+		if v < 0 {
+			return fmt.Errorf("invalid %s %d, it cannot be negative", name, v)
+		}
+	}
+
+	for plan, rpm := range c.RequestsPerMinute {
+		if rpm <= 0 {
+			return fmt.Errorf("invalid requests per minute %d of plan %s", rpm, plan)
+		}
+	}
+	for class, factor := range c.RouteFactors {
+		if !oneOf(class, "auth", "write", "read") {
+			return fmt.Errorf("invalid route class %q, use auth, write or read", class)
+		} else if factor <= 0 {
+			return fmt.Errorf("invalid factor %v of route class %s", factor, class)
+		}
+	}
+
+	for _, origin := range c.CORSOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return fmt.Errorf("invalid CORS origin %q, use scheme://host or *", origin)
+		}
+	}
+	return nil
+}
+
+// oneOf returns true if the lowercase value is one of the values
+func oneOf(v string, values ...string) bool {
+	v = strings.ToLower(strings.TrimSpace(v))
+	for _, x := range values {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+// split returns the trimmed values of a comma-separated list
+func split(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// atoi returns the integer value of s or 0 if it's not a valid number
+func atoi(s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
 	}
+	return i
 }
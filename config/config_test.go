@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestLoadConfigPoolLimits(t *testing.T) {
+	t.Setenv("DATABASE_MAX_OPEN_CONNS", "25")
+	t.Setenv("DATABASE_MAX_IDLE_CONNS", "5")
+
+	c := LoadConfig()
+	if c.DatabaseMaxOpenConns != 25 || c.DatabaseMaxIdleConns != 5 {
+		t.Errorf("expected the pool limits 25 and 5 got %d and %d", c.DatabaseMaxOpenConns, c.DatabaseMaxIdleConns)
+	}
+
+	// unset and invalid values keep the drivers' defaults
+	t.Setenv("DATABASE_MAX_OPEN_CONNS", "")
+	t.Setenv("DATABASE_MAX_IDLE_CONNS", "many")
+
+	c = LoadConfig()
+	if c.DatabaseMaxOpenConns != 0 || c.DatabaseMaxIdleConns != 0 {
+		t.Errorf("expected no pool limits got %d and %d", c.DatabaseMaxOpenConns, c.DatabaseMaxIdleConns)
+	}
+
+	// the config file overrides the environment
+	f, err := ReadFile(writeFile(t, "sb.yaml", "persister:\n  maxOpenConns: 50\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c = AppConfig{DatabaseMaxOpenConns: 25, DatabaseMaxIdleConns: 5}
+	f.Apply(&c)
+	if c.DatabaseMaxOpenConns != 50 || c.DatabaseMaxIdleConns != 5 {
+		t.Errorf("expected the pool limits 50 and 5 got %d and %d", c.DatabaseMaxOpenConns, c.DatabaseMaxIdleConns)
+	}
+}
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the structured configuration file, YAML or TOML based on its
+// extension. The values it sets override the environment variables, the
+// Limits and CORS sections are reloaded on SIGHUP while the others
+// require a restart.
+type File struct {
+	App       FileApp       `yaml:"app" toml:"app"`
+	Persister FilePersister `yaml:"persister" toml:"persister"`
+	Storage   FileStorage   `yaml:"storage" toml:"storage"`
+	Mailer    FileMailer    `yaml:"mailer" toml:"mailer"`
+	Limits    FileLimits    `yaml:"limits" toml:"limits"`
+	CORS      FileCORS      `yaml:"cors" toml:"cors"`
+	Features  FileFeatures  `yaml:"features" toml:"features"`
+}
+
+// FileApp is the server section of the configuration file
+type FileApp struct {
+	Env            string   `yaml:"env" toml:"env"`
+	Secret         string   `yaml:"secret" toml:"secret"`
+	URL            string   `yaml:"url" toml:"url"`
+	Port           string   `yaml:"port" toml:"port"`
+	Region         string   `yaml:"region" toml:"region"`
+	TrustedProxies []string `yaml:"trustedProxies" toml:"trustedProxies"`
+	AdminToken     string   `yaml:"adminToken" toml:"adminToken"`
+	LogLevel       string   `yaml:"logLevel" toml:"logLevel"`
+	LogFormat      string   `yaml:"logFormat" toml:"logFormat"`
+	LogFilename    string   `yaml:"logFilename" toml:"logFilename"`
+}
+
+// FilePersister is the database section of the configuration file
+type FilePersister struct {
+	DataStore    string `yaml:"datastore" toml:"datastore"`
+	URL          string `yaml:"url" toml:"url"`
+	MaxOpenConns int    `yaml:"maxOpenConns" toml:"maxOpenConns"`
+	MaxIdleConns int    `yaml:"maxIdleConns" toml:"maxIdleConns"`
+}
+
+// FileStorage is the file storage section of the configuration file
+type FileStorage struct {
+	Provider     string   `yaml:"provider" toml:"provider"`
+	Providers    []string `yaml:"providers" toml:"providers"`
+	LocalURL     string   `yaml:"localURL" toml:"localURL"`
+	LocalPath    string   `yaml:"localPath" toml:"localPath"`
+	CacheControl string   `yaml:"cacheControl" toml:"cacheControl"`
+	S3           struct {
+		Region         string `yaml:"region" toml:"region"`
+		Bucket         string `yaml:"bucket" toml:"bucket"`
+		CDNURL         string `yaml:"cdnURL" toml:"cdnURL"`
+		Endpoint       string `yaml:"endpoint" toml:"endpoint"`
+		ForcePathStyle *bool  `yaml:"forcePathStyle" toml:"forcePathStyle"`
+		NoACL          *bool  `yaml:"noACL" toml:"noACL"`
+	} `yaml:"s3" toml:"s3"`
+	Azure struct {
+		Account   string `yaml:"account" toml:"account"`
+		Key       string `yaml:"key" toml:"key"`
+		Container string `yaml:"container" toml:"container"`
+		Endpoint  string `yaml:"endpoint" toml:"endpoint"`
+	} `yaml:"azure" toml:"azure"`
+	GCS struct {
+		Bucket      string `yaml:"bucket" toml:"bucket"`
+		Credentials string `yaml:"credentials" toml:"credentials"`
+		Endpoint    string `yaml:"endpoint" toml:"endpoint"`
+	} `yaml:"gcs" toml:"gcs"`
+}
+
+// FileMailer is the email sending section of the configuration file
+type FileMailer struct {
+	Provider  string `yaml:"provider" toml:"provider"`
+	FromEmail string `yaml:"fromEmail" toml:"fromEmail"`
+	FromName  string `yaml:"fromName" toml:"fromName"`
+	SMTP      struct {
+		Host     string `yaml:"host" toml:"host"`
+		Username string `yaml:"username" toml:"username"`
+		Password string `yaml:"password" toml:"password"`
+	} `yaml:"smtp" toml:"smtp"`
+	APIKey        string `yaml:"apiKey" toml:"apiKey"`
+	MailgunDomain string `yaml:"mailgunDomain" toml:"mailgunDomain"`
+	Region        string `yaml:"region" toml:"region"`
+}
+
+// FileLimits is the limits section of the configuration file.
+// RequestsPerMinute are the rate limits of the plans by name or ID and
+// RouteFactors the factors of the route classes (auth, write and read),
+// they are reloaded on SIGHUP.
+type FileLimits struct {
+	RateLimit         *bool              `yaml:"rateLimit" toml:"rateLimit"`
+	RequestsPerMinute map[string]int64   `yaml:"requestsPerMinute" toml:"requestsPerMinute"`
+	RouteFactors      map[string]float64 `yaml:"routeFactors" toml:"routeFactors"`
+	StorageQuotas     *bool              `yaml:"storageQuotas" toml:"storageQuotas"`
+	EmailQuotas       *bool              `yaml:"emailQuotas" toml:"emailQuotas"`
+	FunctionQuotas    *bool              `yaml:"functionQuotas" toml:"functionQuotas"`
+	MaxDocumentSize   int                `yaml:"maxDocumentSize" toml:"maxDocumentSize"`
+	MaxFormSize       int                `yaml:"maxFormSize" toml:"maxFormSize"`
+	MaxFunctionSize   int                `yaml:"maxFunctionSize" toml:"maxFunctionSize"`
+	MaxUploadSize     int                `yaml:"maxUploadSize" toml:"maxUploadSize"`
+	Functions         struct {
+		Timeout    int `yaml:"timeout" toml:"timeout"`
+		MaxOutput  int `yaml:"maxOutput" toml:"maxOutput"`
+		MaxDBCalls int `yaml:"maxDbCalls" toml:"maxDbCalls"`
+	} `yaml:"functions" toml:"functions"`
+	Realtime struct {
+		KeepAlive      int   `yaml:"keepAlive" toml:"keepAlive"`
+		IdleTimeout    int   `yaml:"idleTimeout" toml:"idleTimeout"`
+		MessageRate    int   `yaml:"messageRate" toml:"messageRate"`
+		MaxMessageSize int   `yaml:"maxMessageSize" toml:"maxMessageSize"`
+		Compression    *bool `yaml:"compression" toml:"compression"`
+	} `yaml:"realtime" toml:"realtime"`
+}
+
+// FileCORS is the instance-wide CORS section of the configuration file, it
+// is reloaded on SIGHUP
+type FileCORS struct {
+	AllowedOrigins []string `yaml:"allowedOrigins" toml:"allowedOrigins"`
+}
+
+// FileFeatures is the feature defaults section of the configuration file
+type FileFeatures struct {
+	Flags              []string `yaml:"flags" toml:"flags"`
+	FullTextSearch     *bool    `yaml:"fullTextSearch" toml:"fullTextSearch"`
+	FullTextIndexPath  string   `yaml:"fullTextIndexPath" toml:"fullTextIndexPath"`
+	AuditRetentionDays int      `yaml:"auditRetentionDays" toml:"auditRetentionDays"`
+	EventRetentionDays int      `yaml:"eventRetentionDays" toml:"eventRetentionDays"`
+}
+
+// ReadFile parses a .yaml, .yml or .toml configuration file, the unknown
+// keys are refused
+func ReadFile(path string) (File, error) {
+	var f File
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return f, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+			return f, fmt.Errorf("invalid configuration file %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(b), &f)
+		if err != nil {
+			return f, fmt.Errorf("invalid configuration file %s: %w", path, err)
+		} else if keys := md.Undecoded(); len(keys) > 0 {
+			return f, fmt.Errorf("invalid configuration file %s: unknown key %s", path, keys[0])
+		}
+	default:
+		return f, fmt.Errorf("unsupported configuration file %s, use .yaml or .toml", path)
+	}
+	return f, nil
+}
+
+// Apply overrides the values of the configuration set in the file
+func (f File) Apply(c *AppConfig) {
+	setString(&c.AppEnv, f.App.Env)
+	setString(&c.AppSecret, f.App.Secret)
+	setString(&c.AppURL, f.App.URL)
+	setString(&c.Port, f.App.Port)
+	setString(&c.Region, f.App.Region)
+	setList(&c.TrustedProxies, f.App.TrustedProxies)
+	setString(&c.AdminToken, f.App.AdminToken)
+	setString(&c.LogConsoleLevel, f.App.LogLevel)
+	setString(&c.LogFormat, f.App.LogFormat)
+	setString(&c.LogFilename, f.App.LogFilename)
+
+	setString(&c.DataStore, f.Persister.DataStore)
+	setString(&c.DatabaseURL, f.Persister.URL)
+	setInt(&c.DatabaseMaxOpenConns, f.Persister.MaxOpenConns)
+	setInt(&c.DatabaseMaxIdleConns, f.Persister.MaxIdleConns)
+
+	setString(&c.StorageProvider, f.Storage.Provider)
+	setList(&c.StorageProviders, f.Storage.Providers)
+	setString(&c.LocalStorageURL, f.Storage.LocalURL)
+	setString(&c.LocalStoragePath, f.Storage.LocalPath)
+	setString(&c.FileCacheControl, f.Storage.CacheControl)
+	setString(&c.AWSRegion, f.Storage.S3.Region)
+	setString(&c.AWSS3Bucket, f.Storage.S3.Bucket)
+	setString(&c.AWSCDNURL, f.Storage.S3.CDNURL)
+	setString(&c.AWSS3Endpoint, f.Storage.S3.Endpoint)
+	setBool(&c.AWSS3ForcePathStyle, f.Storage.S3.ForcePathStyle)
+	setBool(&c.AWSS3NoACL, f.Storage.S3.NoACL)
+	setString(&c.AzureStorageAccount, f.Storage.Azure.Account)
+	setString(&c.AzureStorageKey, f.Storage.Azure.Key)
+	setString(&c.AzureStorageContainer, f.Storage.Azure.Container)
+	setString(&c.AzureStorageEndpoint, f.Storage.Azure.Endpoint)
+	setString(&c.GCSBucket, f.Storage.GCS.Bucket)
+	setString(&c.GCSCredentials, f.Storage.GCS.Credentials)
+	setString(&c.GCSEndpoint, f.Storage.GCS.Endpoint)
+
+	setString(&c.MailProvider, f.Mailer.Provider)
+	setString(&c.FromEmail, f.Mailer.FromEmail)
+	setString(&c.FromName, f.Mailer.FromName)
+	setString(&c.SMTPHost, f.Mailer.SMTP.Host)
+	setString(&c.SMTPUsername, f.Mailer.SMTP.Username)
+	setString(&c.SMTPPassword, f.Mailer.SMTP.Password)
+	setString(&c.MailAPIKey, f.Mailer.APIKey)
+	setString(&c.MailgunDomain, f.Mailer.MailgunDomain)
+	setString(&c.MailRegion, f.Mailer.Region)
+
+	setBool(&c.RateLimit, f.Limits.RateLimit)
+	if len(f.Limits.RequestsPerMinute) > 0 {
+		c.RequestsPerMinute = f.Limits.RequestsPerMinute
+	}
+	if len(f.Limits.RouteFactors) > 0 {
+		c.RouteFactors = f.Limits.RouteFactors
+	}
+	setBool(&c.StorageQuotas, f.Limits.StorageQuotas)
+	setBool(&c.EmailQuotas, f.Limits.EmailQuotas)
+	setBool(&c.FunctionQuotas, f.Limits.FunctionQuotas)
+	setInt(&c.MaxDocumentSize, f.Limits.MaxDocumentSize)
+	setInt(&c.MaxFormSize, f.Limits.MaxFormSize)
+	setInt(&c.MaxFunctionSize, f.Limits.MaxFunctionSize)
+	setInt(&c.MaxUploadSize, f.Limits.MaxUploadSize)
+	setInt(&c.FunctionTimeout, f.Limits.Functions.Timeout)
+	setInt(&c.FunctionMaxOutput, f.Limits.Functions.MaxOutput)
+	setInt(&c.FunctionMaxDBCalls, f.Limits.Functions.MaxDBCalls)
+	setInt(&c.RealtimeKeepAlive, f.Limits.Realtime.KeepAlive)
+	setInt(&c.RealtimeIdleTimeout, f.Limits.Realtime.IdleTimeout)
+	setInt(&c.RealtimeMessageRate, f.Limits.Realtime.MessageRate)
+	setInt(&c.RealtimeMaxMessageSize, f.Limits.Realtime.MaxMessageSize)
+	setBool(&c.RealtimeCompression, f.Limits.Realtime.Compression)
+
+	if len(f.CORS.AllowedOrigins) > 0 {
+		c.CORSOrigins = f.CORS.AllowedOrigins
+	}
+
+	setList(&c.FeatureFlags, f.Features.Flags)
+	if f.Features.FullTextSearch != nil {
+		c.NoFullTextSearch = !*f.Features.FullTextSearch
+	}
+	setString(&c.FullTextIndexFile, f.Features.FullTextIndexPath)
+	setInt(&c.AuditRetentionDays, f.Features.AuditRetentionDays)
+	setInt(&c.EventRetentionDays, f.Features.EventRetentionDays)
+}
+
+func setString(dst *string, v string) {
+	if len(v) > 0 {
+		*dst = v
+	}
+}
+
+// setList sets a comma-separated list value
+func setList(dst *string, v []string) {
+	if len(v) > 0 {
+		*dst = strings.Join(v, ",")
+	}
+}
+
+func setInt(dst *int, v int) {
+	if v != 0 {
+		*dst = v
+	}
+}
+
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFile(t *testing.T) {
+	yml := `
+persister:
+  datastore: sqlite
+  url: sb.db
+storage:
+  provider: s3
+  s3:
+    bucket: files
+    forcePathStyle: true
+mailer:
+  provider: smtp
+  smtp:
+    host: smtp.example.com:587
+limits:
+  rateLimit: false
+  requestsPerMinute:
+    idea: 500
+  routeFactors:
+    auth: 0.5
+cors:
+  allowedOrigins: ["https://app.example.com"]
+features:
+  flags: [beta, search]
+  fullTextSearch: false
+`
+
+	toml := `
+[persister]
+datastore = "sqlite"
+url = "sb.db"
+
+[storage]
+provider = "s3"
+
+[storage.s3]
+bucket = "files"
+forcePathStyle = true
+
+[mailer]
+provider = "smtp"
+
+[mailer.smtp]
+host = "smtp.example.com:587"
+
+[limits]
+rateLimit = false
+
+[limits.requestsPerMinute]
+idea = 500
+
+[limits.routeFactors]
+auth = 0.5
+
+[cors]
+allowedOrigins = ["https://app.example.com"]
+
+[features]
+flags = ["beta", "search"]
+fullTextSearch = false
+`
+
+	files := map[string]string{"sb.yaml": yml, "sb.toml": toml}
+	for name, content := range files {
+		f, err := ReadFile(writeFile(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		c := AppConfig{DataStore: "pg", MailProvider: "dev", RateLimit: true, FromName: "env"}
+		f.Apply(&c)
+
+		if c.DataStore != "sqlite" || c.DatabaseURL != "sb.db" {
+			t.Errorf("%s: expected the persister to be overridden got %s %s", name, c.DataStore, c.DatabaseURL)
+		} else if c.StorageProvider != "s3" || c.AWSS3Bucket != "files" || !c.AWSS3ForcePathStyle {
+			t.Errorf("%s: expected the S3 storage got %s %s", name, c.StorageProvider, c.AWSS3Bucket)
+		} else if c.MailProvider != "smtp" || c.SMTPHost != "smtp.example.com:587" {
+			t.Errorf("%s: expected the SMTP mailer got %s %s", name, c.MailProvider, c.SMTPHost)
+		} else if c.FromName != "env" {
+			t.Errorf("%s: expected the unset values to be kept got %s", name, c.FromName)
+		} else if c.RateLimit || c.RequestsPerMinute["idea"] != 500 || c.RouteFactors["auth"] != 0.5 {
+			t.Errorf("%s: expected the limits to be overridden got %v %v %v", name, c.RateLimit, c.RequestsPerMinute, c.RouteFactors)
+		} else if len(c.CORSOrigins) != 1 || c.CORSOrigins[0] != "https://app.example.com" {
+			t.Errorf("%s: expected the CORS origins got %v", name, c.CORSOrigins)
+		} else if c.FeatureFlags != "beta,search" || !c.NoFullTextSearch {
+			t.Errorf("%s: expected the features got %s %v", name, c.FeatureFlags, c.NoFullTextSearch)
+		}
+	}
+}
+
+func TestReadFileRefusesUnknownKeys(t *testing.T) {
+	files := map[string]string{
+		"sb.yaml": "persister:\n  database: sqlite\n",
+		"sb.toml": "[persister]\ndatabase = \"sqlite\"\n",
+		"sb.json": "{}",
+	}
+	for name, content := range files {
+		if _, err := ReadFile(writeFile(t, name, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		c    AppConfig
+		err  string
+	}{
+		{"valid", AppConfig{DataStore: "pg", StorageProvider: "local", MailProvider: "dev"}, ""},
+		{"data store", AppConfig{DataStore: "oracle"}, "data store"},
+		{"storage", AppConfig{StorageProviders: "s3,ftp"}, "storage provider"},
+		{"mailer", AppConfig{MailProvider: "pigeon"}, "mail provider"},
+		{"size", AppConfig{MaxUploadSize: -1}, "max upload size"},
+		{"rate", AppConfig{RequestsPerMinute: map[string]int64{"idea": 0}}, "requests per minute"},
+		{"class", AppConfig{RouteFactors: map[string]float64{"delete": 1}}, "route class"},
+		{"origin", AppConfig{CORSOrigins: []string{"example.com"}}, "CORS origin"},
+	}
+
+	for _, tc := range tests {
+		err := tc.c.Validate()
+		if len(tc.err) == 0 && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		} else if len(tc.err) > 0 && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s: expected an error about %s got %v", tc.name, tc.err, err)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := writeFile(t, "sb.yaml", "persister:\n  datastore: sqlite\ncors:\n  allowedOrigins: [\"https://a.example.com\"]\n")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "persister:\n  datastore: mongo\ncors:\n  allowedOrigins: [\"https://b.example.com\"]\nlimits:\n  requestsPerMinute:\n    growth: 10000\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := Reload(c)
+	if err != nil {
+		t.Fatal(err)
+	} else if next.DataStore != "sqlite" {
+		t.Errorf("expected the persister to require a restart got %s", next.DataStore)
+	} else if len(next.CORSOrigins) != 1 || next.CORSOrigins[0] != "https://b.example.com" {
+		t.Errorf("expected the CORS origins to be reloaded got %v", next.CORSOrigins)
+	} else if next.RequestsPerMinute["growth"] != 10000 {
+		t.Errorf("expected the rate limits to be reloaded got %v", next.RequestsPerMinute)
+	}
+
+	if err := os.WriteFile(path, []byte("cors:\n  allowedOrigins: [\"example.com\"]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if kept, err := Reload(next); err == nil {
+		t.Error("expected the invalid file to be refused")
+	} else if kept.CORSOrigins[0] != "https://b.example.com" {
+		t.Errorf("expected the configuration to be kept got %v", kept.CORSOrigins)
+	}
+}
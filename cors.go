@@ -0,0 +1,92 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+var corsMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// corsSettings returns or replaces the CORS settings of the database
+func corsSettings(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		respond(w, http.StatusOK, conf.Settings.CORS)
+		return
+	}
+
+	var cs model.CORSSettings
+	if err := parseBody(r.Body, &cs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateCORS(cs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s := conf.Settings
+	s.CORS = cs
+
+	if err := backend.DB.UpdateDatabaseSettings(conf.ID, s); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the cached config is used by the WithDB middleware
+	conf.Settings = s
+	if err := backend.Cache.SetTyped(conf.ID, conf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+func validateCORS(cs model.CORSSettings) error {
+	for _, origin := range cs.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+
+		u, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return fmt.Errorf("invalid origin %s, expected a scheme and host i.e. https://example.com", origin)
+		} else if len(strings.Trim(u.Path, "/")) > 0 || len(u.RawQuery) > 0 {
+			return fmt.Errorf("invalid origin %s, it cannot contain a path", origin)
+		}
+	}
+
+	for _, method := range cs.AllowedMethods {
+		if !corsMethods[strings.ToUpper(method)] {
+			return fmt.Errorf("invalid method %s", method)
+		}
+	}
+
+	for _, header := range cs.AllowedHeaders {
+		if len(header) == 0 || strings.ContainsAny(header, " ,:") {
+			return fmt.Errorf("invalid header %s", header)
+		}
+	}
+	return nil
+}
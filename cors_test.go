@@ -0,0 +1,121 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestTenantCors(t *testing.T) {
+	invalid := model.CORSSettings{AllowedOrigins: []string{"example.com"}}
+
+	resp := dbReq(t, corsSettings, "POST", "/account/cors", invalid, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an origin without scheme got %d", resp.StatusCode)
+	}
+
+	cs := model.CORSSettings{
+		AllowedOrigins: []string{"https://*.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+	}
+
+	resp = dbReq(t, corsSettings, "POST", "/account/cors", cs, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	defer func() {
+		resp := dbReq(t, corsSettings, "POST", "/account/cors", model.CORSSettings{}, true)
+		resp.Body.Close()
+	}()
+
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respond(w, http.StatusOK, true)
+		}),
+		middleware.Cors(),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.TenantCors(),
+	)
+
+	call := func(method, origin string) *http.Response {
+		req := httptest.NewRequest(method, "/db/tasks", nil)
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+		if len(origin) > 0 {
+			req.Header.Set("Origin", origin)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	tests := []struct {
+		method string
+		origin string
+		status int
+	}{
+		{"GET", "https://app.example.com", http.StatusOK},
+		{"GET", "https://evil.com", http.StatusForbidden},
+		{"GET", "http://app.example.com", http.StatusForbidden},
+		{"DELETE", "https://app.example.com", http.StatusForbidden},
+		{"DELETE", "", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		resp := call(tc.method, tc.origin)
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s from %s: expected status %d got %d", tc.method, tc.origin, tc.status, resp.StatusCode)
+		}
+	}
+
+	resp = call("GET", "https://evil.com")
+	if v := resp.Header.Get("Access-Control-Allow-Origin"); len(v) > 0 {
+		t.Errorf("expected no allowed origin for a rejected origin got %s", v)
+	}
+
+	resp = call("POST", "https://app.example.com")
+	if v := resp.Header.Get("Access-Control-Allow-Methods"); v != "GET, POST" {
+		t.Errorf("expected allowed methods to be GET, POST got %s", v)
+	}
+}
+
+func TestInstanceCorsOrigins(t *testing.T) {
+	middleware.SetCorsOrigins([]string{"https://app.example.com"})
+	defer middleware.SetCorsOrigins(nil)
+
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respond(w, http.StatusOK, true)
+		}),
+		middleware.Cors(),
+	)
+
+	call := func(method, origin string) *http.Response {
+		req := httptest.NewRequest(method, "/db/tasks", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "GET")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	if resp := call("OPTIONS", "https://app.example.com"); resp.Header.Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("expected the allowed origin to get CORS headers got %v", resp.Header)
+	}
+
+	if resp := call("OPTIONS", "https://evil.com"); len(resp.Header.Get("Access-Control-Allow-Origin")) > 0 {
+		t.Errorf("expected no CORS headers for another origin got %v", resp.Header)
+	} else if resp := call("GET", "https://evil.com"); len(resp.Header.Get("Access-Control-Allow-Origin")) > 0 {
+		t.Errorf("expected no CORS headers for another origin got %v", resp.Header)
+	}
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddAdminEvent(evt model.AdminEvent) error {
+	evt.ID = m.NewID()
+	return create(m, "sb", "sb_admin_audit", evt.ID, evt)
+}
+
+func (m *Memory) ListAdminEvents(f model.AdminEventFilter) (results []model.AdminEvent, err error) {
+	list, err := all[model.AdminEvent](m, "sb", "sb_admin_audit")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.AdminEvent) bool {
+		if len(f.TenantID) > 0 && x.TenantID != f.TenantID {
+			return false
+		} else if len(f.DBName) > 0 && x.DBName != f.DBName {
+			return false
+		} else if len(f.Type) > 0 && x.Type != f.Type {
+			return false
+		} else if !f.Since.IsZero() && x.Created.Before(f.Since) {
+			return false
+		} else if !f.Until.IsZero() && x.Created.After(f.Until) {
+			return false
+		}
+		return true
+	})
+
+	results = sortSlice(results, func(a, b model.AdminEvent) bool {
+		return a.Created.After(b.Created)
+	})
+
+	if f.Limit > 0 && int64(len(results)) > f.Limit {
+		results = results[:f.Limit]
+	}
+	return
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// rollupsMu makes the read and write of the request rollups atomic
+var rollupsMu sync.Mutex
+
+func requestRollupID(rr model.RequestRollup) string {
+	return fmt.Sprintf("%s_%s_%s_%d_%s", rr.DBName, rr.Hour, rr.Endpoint, rr.Status, rr.Country)
+}
+
+func (m *Memory) AddRequestRollups(records []model.RequestRollup) error {
+	rollupsMu.Lock()
+	defer rollupsMu.Unlock()
+
+	for _, rr := range records {
+		var cur model.RequestRollup
+		if err := getByID(m, "sb", "sb_request_rollups", requestRollupID(rr), &cur); err == nil {
+			rr.Count += cur.Count
+			rr.TotalMillis += cur.TotalMillis
+			if cur.MaxMillis > rr.MaxMillis {
+				rr.MaxMillis = cur.MaxMillis
+			}
+		}
+
+		if err := create(m, "sb", "sb_request_rollups", requestRollupID(rr), rr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) ListRequestRollups(f model.RequestRollupFilter) ([]model.RequestRollup, error) {
+	list, err := all[model.RequestRollup](m, "sb", "sb_request_rollups")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.RequestRollup) bool {
+		return x.DBName == f.DBName &&
+			(len(f.Since) == 0 || x.Hour >= f.Since) &&
+			(len(f.Until) == 0 || x.Hour <= f.Until)
+	})
+
+	list = sortSlice(list, func(a, b model.RequestRollup) bool {
+		return a.Hour < b.Hour
+	})
+	return list, nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestRequestRollups(t *testing.T) {
+	rr := model.RequestRollup{
+		TenantID:    "tenant-rollups",
+		DBName:      "db-rollups",
+		Hour:        "2001-02-03T04",
+		Endpoint:    model.EndpointDatabase,
+		Status:      200,
+		Country:     "CA",
+		Count:       2,
+		TotalMillis: 30,
+		MaxMillis:   20,
+	}
+
+	other := rr
+	other.Hour = "2001-02-03T05"
+	other.Status = 500
+
+	if err := datastore.AddRequestRollups([]model.RequestRollup{rr, other}); err != nil {
+		t.Fatal(err)
+	}
+
+	rr.Count, rr.TotalMillis, rr.MaxMillis = 1, 40, 40
+	if err := datastore.AddRequestRollups([]model.RequestRollup{rr}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := datastore.ListRequestRollups(model.RequestRollupFilter{
+		DBName: "db-rollups",
+		Since:  "2001-02-03T00",
+		Until:  "2001-02-03T04",
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Fatalf("expected 1 rollup got %v", list)
+	} else if got := list[0]; got.Count != 3 || got.TotalMillis != 70 || got.MaxMillis != 40 || got.TenantID != rr.TenantID {
+		t.Errorf("expected the rollups to be added got %v", got)
+	}
+
+	list, err = datastore.ListRequestRollups(model.RequestRollupFilter{DBName: "db-rollups"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 || list[1].Hour != other.Hour || list[1].Status != 500 {
+		t.Errorf("expected the rollups ordered by hour got %v", list)
+	}
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// appEventsMu makes the read and write of the event rollups atomic
+var appEventsMu sync.Mutex
+
+func (m *Memory) AddAppEvents(dbName string, events []model.AppEvent) error {
+	appEventsMu.Lock()
+	defer appEventsMu.Unlock()
+
+	counts := make(map[model.AppEventRollup]int64)
+	for _, evt := range events {
+		evt.ID = m.NewID()
+		if err := create(m, dbName, "sb_events", evt.ID, evt); err != nil {
+			return err
+		}
+
+		counts[model.AppEventRollup{Day: evt.Received.UTC().Format("2006-01-02"), Name: evt.Name}]++
+	}
+
+	for r, n := range counts {
+		id := fmt.Sprintf("%s_%s", r.Day, r.Name)
+
+		var cur model.AppEventRollup
+		if err := getByID(m, dbName, "sb_event_rollups", id, &cur); err == nil {
+			n += cur.Count
+		}
+
+		r.Count = n
+		if err := create(m, dbName, "sb_event_rollups", id, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) ListAppEvents(dbName string, f model.AppEventFilter) ([]model.AppEvent, error) {
+	list, err := all[model.AppEvent](m, dbName, "sb_events")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.AppEvent) bool {
+		if len(f.Name) > 0 && x.Name != f.Name {
+			return false
+		} else if len(f.SessionID) > 0 && x.SessionID != f.SessionID {
+			return false
+		} else if !f.Since.IsZero() && x.Received.Before(f.Since) {
+			return false
+		} else if !f.Until.IsZero() && x.Received.After(f.Until) {
+			return false
+		}
+		return true
+	})
+
+	list = sortSlice(list, func(a, b model.AppEvent) bool {
+		return a.Received.After(b.Received)
+	})
+
+	if f.Limit > 0 && int64(len(list)) > f.Limit {
+		list = list[:f.Limit]
+	}
+	return list, nil
+}
+
+func (m *Memory) ListAppEventRollups(dbName string, f model.AppEventRollupFilter) ([]model.AppEventRollup, error) {
+	list, err := all[model.AppEventRollup](m, dbName, "sb_event_rollups")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.AppEventRollup) bool {
+		return (len(f.Name) == 0 || x.Name == f.Name) &&
+			(len(f.Since) == 0 || x.Day >= f.Since) &&
+			(len(f.Until) == 0 || x.Day <= f.Until)
+	})
+
+	list = sortSlice(list, func(a, b model.AppEventRollup) bool {
+		if a.Day == b.Day {
+			return a.Name < b.Name
+		}
+		return a.Day < b.Day
+	})
+	return list, nil
+}
+
+func (m *Memory) PurgeAppEvents(dbName string, before time.Time) (int64, error) {
+	return removeWhere(m, dbName, "sb_events", func(x model.AppEvent) bool {
+		return x.Received.Before(before)
+	})
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddAuditEvent(dbName string, evt model.AuditEvent) error {
+	evt.ID = m.NewID()
+	return create(m, dbName, "sb_audit", evt.ID, evt)
+}
+
+func (m *Memory) ListAuditEvents(dbName string, f model.AuditFilter) (results []model.AuditEvent, err error) {
+	list, err := all[model.AuditEvent](m, dbName, "sb_audit")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.AuditEvent) bool {
+		if len(f.AccountID) > 0 && x.AccountID != f.AccountID {
+			return false
+		} else if len(f.UserID) > 0 && x.UserID != f.UserID {
+			return false
+		} else if len(f.Type) > 0 && x.Type != f.Type {
+			return false
+		} else if !f.Since.IsZero() && x.Created.Before(f.Since) {
+			return false
+		} else if !f.Until.IsZero() && x.Created.After(f.Until) {
+			return false
+		}
+		return true
+	})
+
+	results = sortSlice(results, func(a, b model.AuditEvent) bool {
+		return a.Created.After(b.Created)
+	})
+
+	if f.Limit > 0 && int64(len(results)) > f.Limit {
+		results = results[:f.Limit]
+	}
+	return
+}
+
+func (m *Memory) CountAuditUsers(dbName string, f model.AuditFilter) (int64, error) {
+	f.Limit = 0
+	list, err := m.ListAuditEvents(dbName, f)
+	if err != nil {
+		return 0, err
+	}
+
+	users := make(map[string]bool)
+	for _, evt := range list {
+		users[evt.UserID] = true
+	}
+	return int64(len(users)), nil
+}
+
+func (m *Memory) PurgeAuditEvents(dbName string, before time.Time) (n int64, err error) {
+	key := fmt.Sprintf("%s_sb_audit", dbName)
+
+	mx.Lock()
+	defer mx.Unlock()
+
+	repo, ok := m.DB[key]
+	if !ok {
+		return
+	}
+
+	for id, b := range repo {
+		var evt model.AuditEvent
+		if err = mustDec(b, &evt); err != nil {
+			return
+		}
+
+		if evt.Created.Before(before) {
+			delete(repo, id)
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddBackup(b model.Backup) (id string, err error) {
+	id = m.NewID()
+	b.ID = id
+
+	err = create(m, "sb", "sb_backups", id, b)
+	return
+}
+
+func (m *Memory) ListBackups(dbName string) ([]model.Backup, error) {
+	list, err := all[model.Backup](m, "sb", "sb_backups")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.Backup) bool {
+		return x.DBName == dbName
+	})
+
+	list = sortSlice(list, func(a, b model.Backup) bool {
+		return a.Created.After(b.Created)
+	})
+	return list, nil
+}
+
+func (m *Memory) GetBackup(id string) (b model.Backup, err error) {
+	err = getByID(m, "sb", "sb_backups", id, &b)
+	return
+}
+
+func (m *Memory) DeleteBackup(id string) error {
+	_, err := removeWhere(m, "sb", "sb_backups", func(x model.Backup) bool {
+		return x.ID == id
+	})
+	return err
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestBackups(t *testing.T) {
+	now := time.Now()
+
+	older := model.Backup{
+		TenantID:  dbTest.TenantID,
+		DBName:    confDBName,
+		Key:       "backups/older.bak",
+		Size:      100,
+		Documents: 2,
+		Created:   now.Add(-1 * time.Hour),
+	}
+	if _, err := datastore.AddBackup(older); err != nil {
+		t.Fatal(err)
+	}
+
+	newer := older
+	newer.Key = "backups/newer.bak"
+	newer.Created = now
+
+	id, err := datastore.AddBackup(newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := datastore.GetBackup(id)
+	if err != nil {
+		t.Fatal(err)
+	} else if b.Key != newer.Key || b.Size != 100 || b.Documents != 2 {
+		t.Errorf("expected the newer backup got %v", b)
+	}
+
+	list, err := datastore.ListBackups(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) < 2 || list[0].ID != id {
+		t.Fatalf("expected the newest backup first got %v", list)
+	}
+
+	if err := datastore.DeleteBackup(id); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = datastore.ListBackups(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range list {
+		if b.ID == id {
+			t.Errorf("expected the backup %s to be deleted", id)
+		}
+	}
+}
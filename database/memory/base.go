@@ -59,11 +59,7 @@ func (m *Memory) ListDocuments(auth model.Auth, dbName, col string, params model
 
 	list = secureRead(auth, col, list)
 
-	if params.SortDescending {
-		list = sortSlice(list, func(a, b map[string]any) bool {
-			return fmt.Sprintf("%v", a[FieldCreated]) > fmt.Sprintf("%v", b[FieldCreated])
-		})
-	}
+	list = sortDocuments(list, params)
 
 	start := (params.Page - 1) * params.Size
 	end := start + params.Size - 1
@@ -91,7 +87,7 @@ func (m *Memory) QueryDocuments(auth model.Auth, dbName, col string, filter map[
 
 	list = secureRead(auth, col, list)
 
-	filtered := filterByClauses(list, filter)
+	filtered := sortDocuments(filterByClauses(list, filter), params)
 
 	start := (params.Page - 1) * params.Size
 	end := start + params.Size - 1
@@ -108,6 +104,34 @@ func (m *Memory) QueryDocuments(auth model.Auth, dbName, col string, filter map[
 	return
 }
 
+// sortDocuments sorts by the SortBy field, numbers are compared as numbers.
+// Without SortBy the documents are sorted by creation when descending.
+func sortDocuments(list []map[string]any, params model.ListParams) []map[string]any {
+	field := params.SortBy
+	if len(field) == 0 {
+		if !params.SortDescending {
+			return list
+		}
+		field = FieldCreated
+	}
+
+	less := func(a, b map[string]any) bool {
+		if x, ok := a[field].(float64); ok {
+			if y, ok := b[field].(float64); ok {
+				return x < y
+			}
+		}
+		return fmt.Sprintf("%v", a[field]) < fmt.Sprintf("%v", b[field])
+	}
+
+	return sortSlice(list, func(a, b map[string]any) bool {
+		if params.SortDescending {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
 func (m *Memory) GetDocumentByID(auth model.Auth, dbName, col, id string) (doc map[string]interface{}, err error) {
 	err = getByID(m, dbName, col, id, &doc)
 
@@ -268,10 +292,11 @@ func (m *Memory) DeleteDocuments(auth model.Auth, dbName, col string, filters ma
 }
 
 func (m *Memory) ListCollections(dbName string) (repos []string, err error) {
+	// the database name can contain underscores
+	prefix := strings.ToLower(dbName) + "_"
 	for key := range m.DB {
-		pairs := strings.Split(key, "_")
-		if strings.EqualFold(pairs[0], dbName) {
-			repos = append(repos, strings.Join(pairs[1:], "_"))
+		if strings.HasPrefix(strings.ToLower(key), prefix) {
+			repos = append(repos, key[len(prefix):])
 		}
 	}
 
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddAppDeletion(d model.AppDeletion) (id string, err error) {
+	id = m.NewID()
+
+	d.ID = id
+	d.Status = model.AppDeletionPending
+	d.Completed = time.Time{}
+	d.Certificate = ""
+
+	err = create(m, "sb", "sb_app_deletions", id, d)
+	return
+}
+
+func (m *Memory) GetAppDeletion(baseID string) (model.AppDeletion, error) {
+	list, err := all[model.AppDeletion](m, "sb", "sb_app_deletions")
+	if err != nil {
+		return model.AppDeletion{}, err
+	}
+
+	for _, d := range list {
+		if d.BaseID == baseID && d.Status == model.AppDeletionPending {
+			return d, nil
+		}
+	}
+	return model.AppDeletion{}, nil
+}
+
+func (m *Memory) ListDueAppDeletions(now time.Time) ([]model.AppDeletion, error) {
+	list, err := all[model.AppDeletion](m, "sb", "sb_app_deletions")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.AppDeletion) bool {
+		return x.Status == model.AppDeletionPending && !x.Scheduled.After(now)
+	})
+
+	list = sortSlice(list, func(a, b model.AppDeletion) bool {
+		return a.Scheduled.Before(b.Scheduled)
+	})
+	return list, nil
+}
+
+func (m *Memory) CompleteAppDeletion(id string, completed time.Time, certificate string) error {
+	var d model.AppDeletion
+	if err := getByID(m, "sb", "sb_app_deletions", id, &d); err != nil {
+		return err
+	}
+
+	d.Status = model.AppDeletionCompleted
+	d.Completed = completed
+	d.Certificate = certificate
+
+	return create(m, "sb", "sb_app_deletions", id, d)
+}
+
+func (m *Memory) CancelAppDeletion(id string) error {
+	_, err := removeWhere(m, "sb", "sb_app_deletions", func(x model.AppDeletion) bool {
+		return x.ID == id && x.Status == model.AppDeletionPending
+	})
+	return err
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"errors"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddDomain(d model.Domain) (id string, err error) {
+	id = m.NewID()
+	d.ID = id
+
+	err = create(m, "sb", "sb_domains", id, d)
+	return
+}
+
+func (m *Memory) ListDomains(dbName string) ([]model.Domain, error) {
+	list, err := all[model.Domain](m, "sb", "sb_domains")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.Domain) bool {
+		return x.DBName == dbName
+	})
+
+	list = sortSlice(list, func(a, b model.Domain) bool {
+		return a.Hostname < b.Hostname
+	})
+	return list, nil
+}
+
+func (m *Memory) FindDomain(hostname string) (d model.Domain, err error) {
+	list, err := all[model.Domain](m, "sb", "sb_domains")
+	if err != nil {
+		return
+	}
+
+	for _, x := range list {
+		if x.Hostname == hostname {
+			return x, nil
+		}
+	}
+	return d, errors.New("domain not found")
+}
+
+func (m *Memory) VerifyDomain(id string) error {
+	var d model.Domain
+	if err := getByID(m, "sb", "sb_domains", id, &d); err != nil {
+		return err
+	}
+
+	d.Verified = true
+	return create(m, "sb", "sb_domains", d.ID, d)
+}
+
+func (m *Memory) DeleteDomain(dbName, hostname string) error {
+	_, err := removeWhere(m, "sb", "sb_domains", func(x model.Domain) bool {
+		return x.DBName == dbName && x.Hostname == hostname
+	})
+	return err
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestDomains(t *testing.T) {
+	d := model.Domain{
+		DBName:   confDBName,
+		BaseID:   "base-id",
+		Hostname: "api.domains-test.com",
+		Token:    "verification-token",
+		Created:  time.Now(),
+	}
+
+	id, err := datastore.AddDomain(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := datastore.FindDomain(d.Hostname)
+	if err != nil {
+		t.Fatal(err)
+	} else if found.ID != id || found.BaseID != d.BaseID || found.Token != d.Token || found.Verified {
+		t.Errorf("expected the unverified domain got %v", found)
+	}
+
+	if _, err := datastore.FindDomain("unknown.domains-test.com"); err == nil {
+		t.Error("expected an error finding an unknown domain")
+	}
+
+	if err := datastore.VerifyDomain(id); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := datastore.ListDomains(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || !list[0].Verified {
+		t.Fatalf("expected the verified domain got %v", list)
+	}
+
+	if err := datastore.DeleteDomain(confDBName, d.Hostname); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := datastore.FindDomain(d.Hostname); err == nil {
+		t.Error("expected the domain to be deleted")
+	}
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) QueueEmail(dbName string, msg model.EmailMessage) (string, error) {
+	msg.ID = m.NewID()
+	if err := create(m, dbName, "sb_email_queue", msg.ID, msg); err != nil {
+		return "", err
+	}
+	return msg.ID, nil
+}
+
+func (m *Memory) GetEmailMessage(dbName, id string) (msg model.EmailMessage, err error) {
+	if err = getByID(m, dbName, "sb_email_queue", id, &msg); err != nil {
+		return
+	} else if len(msg.ID) == 0 {
+		// no email was queued yet
+		err = errors.New("document not found")
+	}
+	return
+}
+
+func (m *Memory) ListDueEmails(dbName string, now time.Time, limit int64) (results []model.EmailMessage, err error) {
+	list, err := all[model.EmailMessage](m, dbName, "sb_email_queue")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.EmailMessage) bool {
+		return x.Status == model.EmailStatusQueued && !x.NextAttempt.After(now)
+	})
+
+	results = sortSlice(results, func(a, b model.EmailMessage) bool {
+		return a.NextAttempt.Before(b.NextAttempt)
+	})
+
+	if limit > 0 && int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return
+}
+
+func (m *Memory) ListEmailLog(dbName string, f model.EmailLogFilter) (results []model.EmailMessage, err error) {
+	list, err := all[model.EmailMessage](m, dbName, "sb_email_queue")
+	if err != nil {
+		return
+	}
+
+	query := strings.ToLower(f.Query)
+	results = filter(list, func(x model.EmailMessage) bool {
+		if len(f.To) > 0 && x.To != f.To {
+			return false
+		} else if len(f.Template) > 0 && x.Template != f.Template {
+			return false
+		} else if len(f.Status) > 0 && x.Status != f.Status {
+			return false
+		} else if len(query) > 0 && !strings.Contains(strings.ToLower(x.To), query) &&
+			!strings.Contains(strings.ToLower(x.Subject), query) {
+			return false
+		} else if !f.Since.IsZero() && x.Created.Before(f.Since) {
+			return false
+		} else if !f.Until.IsZero() && x.Created.After(f.Until) {
+			return false
+		}
+		return true
+	})
+
+	results = sortSlice(results, func(a, b model.EmailMessage) bool {
+		return a.Created.After(b.Created)
+	})
+
+	if f.Limit > 0 && int64(len(results)) > f.Limit {
+		results = results[:f.Limit]
+	}
+	return
+}
+
+func (m *Memory) CountEmails(dbName string, f model.EmailLogFilter) (int64, error) {
+	f.Limit = 0
+	list, err := m.ListEmailLog(dbName, f)
+	return int64(len(list)), err
+}
+
+func (m *Memory) UpdateEmailStatus(dbName, id, status string, attempts int, lastError, providerID string, nextAttempt time.Time) error {
+	var msg model.EmailMessage
+	if err := getByID(m, dbName, "sb_email_queue", id, &msg); err != nil {
+		return err
+	}
+
+	msg.Status = status
+	msg.Attempts = attempts
+	msg.LastError = lastError
+	msg.ProviderID = providerID
+	msg.NextAttempt = nextAttempt
+	msg.Updated = time.Now()
+	return create(m, dbName, "sb_email_queue", id, msg)
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddEmailEvent(dbName string, e model.EmailEvent) error {
+	e.ID = m.NewID()
+	e.Email = strings.ToLower(e.Email)
+	return create(m, dbName, "sb_email_events", e.ID, e)
+}
+
+func (m *Memory) ListEmailEvents(dbName, email string, limit int64) (results []model.EmailEvent, err error) {
+	list, err := all[model.EmailEvent](m, dbName, "sb_email_events")
+	if err != nil {
+		return
+	}
+
+	email = strings.ToLower(email)
+	results = filter(list, func(x model.EmailEvent) bool {
+		return len(email) == 0 || x.Email == email
+	})
+
+	results = sortSlice(results, func(a, b model.EmailEvent) bool {
+		return a.Created.After(b.Created)
+	})
+
+	if limit > 0 && int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return
+}
+
+func (m *Memory) ListEmailSuppressions(dbName string) ([]model.EmailSuppression, error) {
+	list, err := all[model.EmailSuppression](m, dbName, "sb_email_suppressions")
+	if err != nil {
+		return nil, err
+	}
+
+	list = sortSlice(list, func(a, b model.EmailSuppression) bool {
+		return a.Created.After(b.Created)
+	})
+	return list, nil
+}
+
+func (m *Memory) IsEmailSuppressed(dbName, email string) (bool, error) {
+	list, err := all[model.EmailSuppression](m, dbName, "sb_email_suppressions")
+	if err != nil {
+		return false, err
+	}
+
+	email = strings.ToLower(email)
+	matches := filter(list, func(x model.EmailSuppression) bool {
+		return x.Email == email
+	})
+	return len(matches) > 0, nil
+}
+
+// suppressions are keyed by email so an address is only suppressed once
+func (m *Memory) SuppressEmail(dbName string, s model.EmailSuppression) error {
+	suppressed, err := m.IsEmailSuppressed(dbName, s.Email)
+	if err != nil || suppressed {
+		return err
+	}
+
+	s.Email = strings.ToLower(s.Email)
+	return create(m, dbName, "sb_email_suppressions", s.Email, s)
+}
+
+func (m *Memory) RemoveEmailSuppression(dbName, email string) error {
+	email = strings.ToLower(email)
+	_, err := removeWhere(m, dbName, "sb_email_suppressions", func(x model.EmailSuppression) bool {
+		return x.Email == email
+	})
+	return err
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"errors"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) ListEmailTemplates(dbName string) ([]model.EmailTemplate, error) {
+	list, err := all[model.EmailTemplate](m, dbName, "sb_email_templates")
+	if err != nil {
+		return nil, err
+	}
+
+	list = sortSlice(list, func(a, b model.EmailTemplate) bool {
+		return a.Name < b.Name
+	})
+	return list, nil
+}
+
+func (m *Memory) GetEmailTemplate(dbName, name string) (tmpl model.EmailTemplate, err error) {
+	if err = getByID(m, dbName, "sb_email_templates", name, &tmpl); err != nil {
+		return
+	} else if len(tmpl.Name) == 0 {
+		// no template was saved yet
+		err = errors.New("document not found")
+	}
+	return
+}
+
+// email templates are keyed by name so saving replaces them
+func (m *Memory) SaveEmailTemplate(dbName string, tmpl model.EmailTemplate) error {
+	return create(m, dbName, "sb_email_templates", tmpl.Name, tmpl)
+}
+
+func (m *Memory) DeleteEmailTemplate(dbName, name string) error {
+	_, err := removeWhere(m, dbName, "sb_email_templates", func(x model.EmailTemplate) bool {
+		return x.Name == name
+	})
+	return err
+}
+
+// userLocale is the locale of a user's emails
+type userLocale struct {
+	UserID string `json:"userId"`
+	Locale string `json:"locale"`
+}
+
+func (m *Memory) GetUserLocale(dbName, userID string) (string, error) {
+	list, err := all[userLocale](m, dbName, "sb_user_locales")
+	if err != nil {
+		return "", err
+	}
+
+	list = filter(list, func(x userLocale) bool {
+		return x.UserID == userID
+	})
+	if len(list) == 0 {
+		return "", nil
+	}
+	return list[0].Locale, nil
+}
+
+func (m *Memory) SetUserLocale(dbName, userID, locale string) error {
+	if len(locale) == 0 {
+		_, err := removeWhere(m, dbName, "sb_user_locales", func(x userLocale) bool {
+			return x.UserID == userID
+		})
+		return err
+	}
+	return create(m, dbName, "sb_user_locales", userID, userLocale{UserID: userID, Locale: locale})
+}
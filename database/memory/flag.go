@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+// feature flags are keyed by tenant and name so setting replaces them
+func (m *Memory) SetFeatureFlag(flag model.FeatureFlag) error {
+	return create(m, "sb", "sb_feature_flags", flag.TenantID+":"+flag.Name, flag)
+}
+
+func (m *Memory) ListFeatureFlags(tenantID string) ([]model.FeatureFlag, error) {
+	list, err := all[model.FeatureFlag](m, "sb", "sb_feature_flags")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.FeatureFlag) bool {
+		return x.TenantID == tenantID
+	})
+
+	list = sortSlice(list, func(a, b model.FeatureFlag) bool {
+		return a.Name < b.Name
+	})
+	return list, nil
+}
+
+func (m *Memory) DeleteFeatureFlag(tenantID, name string) error {
+	_, err := removeWhere(m, "sb", "sb_feature_flags", func(x model.FeatureFlag) bool {
+		return x.TenantID == tenantID && x.Name == name
+	})
+	return err
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	flag := model.FeatureFlag{TenantID: dbTest.TenantID, Name: "wasm", Enabled: true, Updated: time.Now()}
+	if err := datastore.SetFeatureFlag(flag); err != nil {
+		t.Fatal(err)
+	}
+
+	flag.Name = "graphql"
+	if err := datastore.SetFeatureFlag(flag); err != nil {
+		t.Fatal(err)
+	}
+
+	// setting a flag again replaces it
+	flag.Enabled = false
+	if err := datastore.SetFeatureFlag(flag); err != nil {
+		t.Fatal(err)
+	}
+
+	flags, err := datastore.ListFeatureFlags(dbTest.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(flags) != 2 {
+		t.Fatalf("expected 2 flags got %v", flags)
+	} else if flags[0].Name != "graphql" || flags[0].Enabled || flags[1].Name != "wasm" || !flags[1].Enabled {
+		t.Errorf("unexpected flags %v", flags)
+	}
+
+	if err := datastore.DeleteFeatureFlag(dbTest.TenantID, "graphql"); err != nil {
+		t.Fatal(err)
+	}
+
+	flags, err = datastore.ListFeatureFlags(dbTest.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(flags) != 1 || flags[0].Name != "wasm" {
+		t.Errorf("expected the wasm flag to remain got %v", flags)
+	}
+
+	if err := datastore.DeleteFeatureFlag(dbTest.TenantID, "wasm"); err != nil {
+		t.Fatal(err)
+	}
+}
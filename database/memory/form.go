@@ -1,7 +1,13 @@
 package memory
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/staticbackendhq/core/model"
 )
 
 func (m *Memory) AddFormSubmission(dbName, form string, doc map[string]any) error {
@@ -49,3 +55,131 @@ func (m *Memory) GetForms(dbName string) (names []string, err error) {
 	}
 	return
 }
+
+func (m *Memory) EachFormSubmission(dbName, name string, since, until time.Time, fn func(doc map[string]any) error) error {
+	docs, err := m.formSubmissions(dbName, name, model.FormFilter{Since: since, Until: until})
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range docs {
+		if err := fn(fromFormSubmission(doc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) QueryFormSubmissions(dbName, name string, f model.FormFilter, params model.ListParams) (result model.PagedResult, err error) {
+	docs, err := m.formSubmissions(dbName, name, f)
+	if err != nil {
+		return
+	}
+
+	if params.SortDescending {
+		for i, j := 0, len(docs)-1; i < j; i, j = i+1, j-1 {
+			docs[i], docs[j] = docs[j], docs[i]
+		}
+	}
+
+	result.Page = params.Page
+	result.Size = params.Size
+	result.Total = int64(len(docs))
+	result.Results = make([]map[string]any, 0)
+
+	start := (params.Page - 1) * params.Size
+	end := start + params.Size
+	if end > result.Total {
+		end = result.Total
+	}
+
+	for i := start; i < end; i++ {
+		result.Results = append(result.Results, fromFormSubmission(docs[i]))
+	}
+	return
+}
+
+func (m *Memory) DeleteFormSubmission(dbName, name, id string) error {
+	_, err := removeWhere(m, dbName, "sb_forms", func(doc map[string]any) bool {
+		return doc[FieldID] == id && doc["sb_form"] == name
+	})
+	return err
+}
+
+func (m *Memory) MarkFormSubmissionRead(dbName, name, id string, read bool) error {
+	var doc map[string]any
+	if err := getByID(m, dbName, "sb_forms", id, &doc); err != nil {
+		return err
+	} else if doc["sb_form"] != name {
+		return errors.New("document not found")
+	}
+
+	doc["sb_read"] = read
+	return create(m, dbName, "sb_forms", id, doc)
+}
+
+// formSubmissions returns the submissions of a form matching the filter,
+// oldest first
+func (m *Memory) formSubmissions(dbName, name string, f model.FormFilter) ([]map[string]any, error) {
+	docs, err := all[map[string]any](m, dbName, "sb_forms")
+	if err != nil {
+		return nil, err
+	}
+
+	search := strings.ToLower(f.Search)
+
+	docs = filter(docs, func(doc map[string]any) bool {
+		created, _ := doc[FieldCreated].(time.Time)
+		spam, _ := doc["sb_spam"].(bool)
+		read, _ := doc["sb_read"].(bool)
+
+		if doc["sb_form"] != name {
+			return false
+		} else if !f.Since.IsZero() && created.Before(f.Since) {
+			return false
+		} else if !f.Until.IsZero() && created.After(f.Until) {
+			return false
+		} else if f.Spam != nil && spam != *f.Spam {
+			return false
+		} else if f.Read != nil && read != *f.Read {
+			return false
+		}
+
+		if len(search) == 0 {
+			return true
+		}
+
+		for k, v := range doc {
+			if k == FieldID || k == FieldCreated || k == "sb_form" {
+				continue
+			}
+
+			if strings.Contains(strings.ToLower(fmt.Sprintf("%v", v)), search) {
+				return true
+			}
+		}
+		return false
+	})
+
+	sort.Slice(docs, func(i, j int) bool {
+		a, _ := docs[i][FieldCreated].(time.Time)
+		b, _ := docs[j][FieldCreated].(time.Time)
+		return a.Before(b)
+	})
+
+	return docs, nil
+}
+
+// fromFormSubmission returns a copy of the submission with its created
+// field instead of the internal fields
+func fromFormSubmission(doc map[string]any) map[string]any {
+	sub := make(map[string]any)
+	for k, v := range doc {
+		sub[k] = v
+	}
+
+	sub["created"] = sub[FieldCreated]
+	delete(sub, FieldCreated)
+	delete(sub, "sb_form")
+	return sub
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+// form definitions are keyed by form name so saving replaces them
+func (m *Memory) SaveFormDefinition(dbName string, def model.FormDefinition) error {
+	return create(m, dbName, "sb_form_definitions", def.Name, def)
+}
+
+func (m *Memory) GetFormDefinition(dbName, form string) (def model.FormDefinition, err error) {
+	list, err := all[model.FormDefinition](m, dbName, "sb_form_definitions")
+	if err != nil {
+		return
+	}
+
+	list = filter(list, func(x model.FormDefinition) bool {
+		return x.Name == form
+	})
+
+	if len(list) == 0 {
+		return model.FormDefinition{Name: form}, nil
+	}
+	return list[0], nil
+}
+
+func (m *Memory) DeleteFormDefinition(dbName, form string) error {
+	_, err := removeWhere(m, dbName, "sb_form_definitions", func(x model.FormDefinition) bool {
+		return x.Name == form
+	})
+	return err
+}
+
+func (m *Memory) ListFormDefinitions(dbName string) ([]model.FormDefinition, error) {
+	list, err := all[model.FormDefinition](m, dbName, "sb_form_definitions")
+	if err != nil {
+		return nil, err
+	}
+
+	return sortSlice(list, func(a, b model.FormDefinition) bool {
+		return a.Name < b.Name
+	}), nil
+}
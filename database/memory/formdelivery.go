@@ -0,0 +1,30 @@
+package memory
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddFormDelivery(dbName string, d model.FormDelivery) error {
+	d.ID = m.NewID()
+	return create(m, dbName, "sb_form_deliveries", d.ID, d)
+}
+
+func (m *Memory) ListFormDeliveries(dbName, form string, limit int64) (results []model.FormDelivery, err error) {
+	list, err := all[model.FormDelivery](m, dbName, "sb_form_deliveries")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.FormDelivery) bool {
+		return x.Form == form
+	})
+
+	results = sortSlice(results, func(a, b model.FormDelivery) bool {
+		return a.Created.After(b.Created)
+	})
+
+	if limit > 0 && int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return
+}
@@ -65,6 +65,22 @@ func (m *Memory) ListFunctions(dbName string) (list []model.ExecData, err error)
 
 }
 
+func (m *Memory) CountFunctionRuns(dbName string, since, until time.Time) (count int64, err error) {
+	list, err := m.ListFunctions(dbName)
+	if err != nil {
+		return
+	}
+
+	for _, fn := range list {
+		for _, h := range fn.History {
+			if !h.Started.Before(since) && h.Started.Before(until) {
+				count++
+			}
+		}
+	}
+	return
+}
+
 func (m *Memory) ListFunctionsByTrigger(dbName, trigger string) (list []model.ExecData, err error) {
 	list, err = m.ListFunctions(dbName)
 	if err != nil {
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) PurgeUser(dbName string, tok model.User) (report model.DeletionReport, err error) {
+	report = model.NewDeletionReport(tok)
+
+	cols, err := m.ListCollections(dbName)
+	if err != nil {
+		return
+	}
+
+	for _, col := range cols {
+		if strings.HasPrefix(col, "sb_") {
+			continue
+		}
+
+		n, err := removeWhere(m, dbName, col, func(doc map[string]any) bool {
+			return doc[FieldOwnerID] == tok.ID
+		})
+		if err != nil {
+			return report, err
+		} else if n > 0 {
+			report.Documents[col] = n
+		}
+	}
+
+	report.FormSubmissions, err = removeWhere(m, dbName, "sb_forms", func(doc map[string]any) bool {
+		email, ok := doc["email"].(string)
+		return ok && strings.EqualFold(email, tok.Email)
+	})
+	if err != nil {
+		return
+	}
+
+	if _, err = removeWhere(m, dbName, "sb_tokens", func(x model.User) bool {
+		return x.ID == tok.ID
+	}); err != nil {
+		return
+	}
+
+	if _, err = removeWhere(m, dbName, "sb_push", func(x model.PushSubscription) bool {
+		return x.UserID == tok.ID
+	}); err != nil {
+		return
+	}
+
+	if _, err = removeWhere(m, dbName, "sb_user_locales", func(x userLocale) bool {
+		return x.UserID == tok.ID
+	}); err != nil {
+		return
+	}
+
+	users, err := m.ListUsers(dbName, tok.AccountID)
+	if err != nil {
+		return
+	}
+
+	if len(users) == 0 {
+		if _, err = removeWhere(m, dbName, "sb_accounts", func(x model.Account) bool {
+			return x.ID == tok.AccountID
+		}); err != nil {
+			return
+		}
+
+		if _, err = removeWhere(m, dbName, "sb_files", func(x model.File) bool {
+			return x.AccountID == tok.AccountID
+		}); err != nil {
+			return
+		}
+
+		if _, err = removeWhere(m, dbName, "sb_invites", func(x model.Invite) bool {
+			return x.AccountID == tok.AccountID
+		}); err != nil {
+			return
+		}
+
+		report.AccountDeleted = true
+	}
+
+	report.Completed = time.Now()
+	return
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddInvite(dbName string, inv model.Invite) (id string, err error) {
+	id = m.NewID()
+	inv.ID = id
+
+	err = create(m, dbName, "sb_invites", id, inv)
+	return
+}
+
+func (m *Memory) GetInvite(dbName, id string) (inv model.Invite, err error) {
+	err = getByID(m, dbName, "sb_invites", id, &inv)
+	return
+}
+
+func (m *Memory) ListInvites(dbName, accountID string) (results []model.Invite, err error) {
+	list, err := all[model.Invite](m, dbName, "sb_invites")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.Invite) bool {
+		return x.AccountID == accountID
+	})
+
+	results = sortSlice(results, func(a, b model.Invite) bool {
+		return a.Created.Before(b.Created)
+	})
+	return
+}
+
+func (m *Memory) DeleteInvite(dbName, id string) error {
+	key := fmt.Sprintf("%s_sb_invites", dbName)
+
+	mx.Lock()
+	defer mx.Unlock()
+
+	invites, ok := m.DB[key]
+	if !ok {
+		return errors.New("cannot find repo")
+	}
+
+	delete(invites, id)
+	return nil
+}
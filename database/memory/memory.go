@@ -142,6 +142,33 @@ func all[T any](m *Memory, dbName, col string) (list []T, err error) {
 	return
 }
 
+// removeWhere deletes all entries of a collection matching fn and returns
+// the number of entries removed
+func removeWhere[T any](m *Memory, dbName, col string, fn func(x T) bool) (n int64, err error) {
+	key := fmt.Sprintf("%s_%s", dbName, col)
+
+	mx.Lock()
+	defer mx.Unlock()
+
+	repo, ok := m.DB[key]
+	if !ok {
+		return
+	}
+
+	for id, b := range repo {
+		var v T
+		if err = mustDec(b, &v); err != nil {
+			return
+		}
+
+		if fn(v) {
+			delete(repo, id)
+			n++
+		}
+	}
+	return
+}
+
 func filter[T any](list []T, fn func(x T) bool) []T {
 	var results []T
 	for _, item := range list {
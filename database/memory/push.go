@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddPushSubscription(dbName string, sub model.PushSubscription) (id string, err error) {
+	id = m.NewID()
+	sub.ID = id
+
+	err = create(m, dbName, "sb_push", id, sub)
+	return
+}
+
+func (m *Memory) ListPushSubscriptions(dbName, channel string) (results []model.PushSubscription, err error) {
+	list, err := all[model.PushSubscription](m, dbName, "sb_push")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.PushSubscription) bool {
+		return x.Channel == channel
+	})
+	return
+}
+
+func (m *Memory) DeletePushSubscription(dbName, accountID, id string) error {
+	_, err := removeWhere(m, dbName, "sb_push", func(x model.PushSubscription) bool {
+		return x.ID == id && x.AccountID == accountID
+	})
+	return err
+}
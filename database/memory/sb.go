@@ -3,6 +3,7 @@ package memory
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/staticbackendhq/core/model"
 )
@@ -86,6 +87,70 @@ func (m *Memory) IncrementMonthlyEmailSent(baseID string) error {
 	return create(m, "sb", "apps", baseID, base)
 }
 
+// emailUsageMu makes the read and write of the email usage atomic
+var emailUsageMu sync.Mutex
+
+func (m *Memory) IncrementEmailUsage(tenantID, month string) (int, error) {
+	emailUsageMu.Lock()
+	defer emailUsageMu.Unlock()
+
+	sent, err := m.GetEmailUsage(tenantID, month)
+	if err != nil {
+		return 0, err
+	}
+
+	usage := model.EmailUsage{Month: month, Sent: sent + 1}
+	if err := create(m, "sb", "email_usage", tenantID+"_"+month, usage); err != nil {
+		return 0, err
+	}
+	return usage.Sent, nil
+}
+
+func (m *Memory) GetEmailUsage(tenantID, month string) (int, error) {
+	var usage model.EmailUsage
+	if err := getByID(m, "sb", "email_usage", tenantID+"_"+month, &usage); err != nil {
+		// nothing was sent this month
+		return 0, nil
+	}
+	return usage.Sent, nil
+}
+
+func (m *Memory) UpdateDatabaseSettings(baseID string, settings model.AppSettings) error {
+	base, err := m.FindDatabase(baseID)
+	if err != nil {
+		return err
+	}
+
+	base.Settings = settings
+
+	return create(m, "sb", "apps", baseID, base)
+}
+
+func (m *Memory) SuspendDatabase(baseID, reason string) error {
+	base, err := m.FindDatabase(baseID)
+	if err != nil {
+		return err
+	}
+
+	base.SuspendedReason = reason
+
+	return create(m, "sb", "apps", baseID, base)
+}
+
+func (m *Memory) ListEnvironments(parentID string) ([]model.DatabaseConfig, error) {
+	list, err := all[model.DatabaseConfig](m, "sb", "apps")
+	if err != nil {
+		return nil, err
+	}
+
+	results := filter(list, func(x model.DatabaseConfig) bool {
+		return x.ParentID == parentID
+	})
+	return sortSlice(results, func(a, b model.DatabaseConfig) bool {
+		return a.Created.Before(b.Created)
+	}), nil
+}
+
 func (m *Memory) GetTenantByStripeID(stripeID string) (cus model.Tenant, err error) {
 	list, err := all[model.Tenant](m, "sb", "customers")
 	if err != nil {
@@ -149,6 +214,16 @@ func (m *Memory) ChangeTenantPlan(tenantID string, plan int) error {
 	return create(m, "sb", "customers", tenantID, cus)
 }
 
+func (m *Memory) SetTenantSubscription(tenantID, subscriptionID string) error {
+	cus, err := m.FindTenant(tenantID)
+	if err != nil {
+		return err
+	}
+
+	cus.SubscriptionID = subscriptionID
+	return create(m, "sb", "customers", tenantID, cus)
+}
+
 func (m *Memory) EnableExternalLogin(tenantID string, config map[string]model.OAuthConfig) error {
 	b, err := model.EncryptExternalLogins(config)
 	if err != nil {
@@ -167,3 +242,25 @@ func (m *Memory) EnableExternalLogin(tenantID string, config map[string]model.OA
 func (m *Memory) DeleteTenant(dbName, email string) error {
 	return nil
 }
+
+func (m *Memory) DeleteDatabase(baseID string) error {
+	base, err := m.FindDatabase(baseID)
+	if err != nil {
+		return err
+	}
+
+	prefix := base.Name + "_"
+
+	mx.Lock()
+	for key := range m.DB {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.DB, key)
+		}
+	}
+	mx.Unlock()
+
+	_, err = removeWhere(m, "sb", "apps", func(x model.DatabaseConfig) bool {
+		return x.ID == baseID
+	})
+	return err
+}
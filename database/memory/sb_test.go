@@ -2,6 +2,7 @@ package memory
 
 import (
 	"testing"
+	"time"
 
 	"github.com/staticbackendhq/core/model"
 )
@@ -67,6 +68,107 @@ func TestIncrementMonthlyEmailSent(t *testing.T) {
 	}
 }
 
+func TestEmailUsage(t *testing.T) {
+	if sent, err := datastore.GetEmailUsage(dbTest.TenantID, "2001-01"); err != nil {
+		t.Fatal(err)
+	} else if sent != 0 {
+		t.Errorf("expected no email sent got %d", sent)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if sent, err := datastore.IncrementEmailUsage(dbTest.TenantID, "2001-02"); err != nil {
+			t.Fatal(err)
+		} else if sent != i {
+			t.Errorf("expected %d emails sent got %d", i, sent)
+		}
+	}
+
+	if sent, err := datastore.GetEmailUsage(dbTest.TenantID, "2001-02"); err != nil {
+		t.Fatal(err)
+	} else if sent != 2 {
+		t.Errorf("expected 2 emails sent got %d", sent)
+	}
+}
+
+func TestUpdateDatabaseSettings(t *testing.T) {
+	settings := model.AppSettings{
+		Captcha: model.CaptchaSettings{
+			Provider:   model.CaptchaPoW,
+			Difficulty: 12,
+			OnRegister: true,
+		},
+	}
+
+	if err := datastore.UpdateDatabaseSettings(dbTest.ID, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := datastore.FindDatabase(dbTest.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if b.Settings.Captcha != settings.Captcha {
+		t.Errorf("expected settings to be %v got %v", settings, b.Settings)
+	}
+}
+
+func TestSuspendDatabase(t *testing.T) {
+	if err := datastore.SuspendDatabase(dbTest.ID, model.SuspendedAbuse); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := datastore.FindDatabase(dbTest.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if !b.IsSuspended() || b.SuspendedReason != model.SuspendedAbuse {
+		t.Errorf("expected the database to be suspended for abuse got %q", b.SuspendedReason)
+	}
+
+	if err := datastore.SuspendDatabase(dbTest.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = datastore.FindDatabase(dbTest.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if b.IsSuspended() {
+		t.Error("expected the database to be reactivated")
+	}
+}
+
+func TestListEnvironments(t *testing.T) {
+	parentID := datastore.NewID()
+
+	env, err := datastore.CreateDatabase(model.DatabaseConfig{
+		ID:          datastore.NewID(),
+		TenantID:    dbTest.TenantID,
+		Name:        "testenvdev",
+		IsActive:    true,
+		Created:     time.Now(),
+		Environment: model.EnvironmentDev,
+		ParentID:    parentID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteDatabase(env.ID)
+
+	list, err := datastore.ListEnvironments(parentID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Fatalf("expected 1 environment got %d", len(list))
+	} else if list[0].ID != env.ID || list[0].Env() != model.EnvironmentDev || list[0].AppID() != parentID {
+		t.Errorf("unexpected environment %v", list[0])
+	}
+
+	list, err = datastore.ListEnvironments(env.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 0 {
+		t.Errorf("expected no environments for the dev database got %d", len(list))
+	}
+}
+
 func TestGetCustomerByStripeID(t *testing.T) {
 	cus, err := datastore.GetTenantByStripeID(adminEmail)
 	if err != nil {
@@ -102,6 +204,19 @@ func TestChangeCustomerPlan(t *testing.T) {
 	}
 }
 
+func TestSetCustomerSubscription(t *testing.T) {
+	if err := datastore.SetTenantSubscription(dbTest.TenantID, "sub_new"); err != nil {
+		t.Fatal(err)
+	}
+
+	cus, err := datastore.FindTenant(dbTest.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	} else if cus.SubscriptionID != "sub_new" {
+		t.Errorf("expected cus subscription to be sub_new got %s", cus.SubscriptionID)
+	}
+}
+
 func TestNewID(t *testing.T) {
 	id1 := datastore.NewID()
 	id2 := datastore.NewID()
@@ -3,6 +3,7 @@ package memory
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/staticbackendhq/core/model"
 )
@@ -16,7 +17,9 @@ func (m *Memory) ListTasks() (results []model.Task, err error) {
 	for _, base := range bases {
 		tasks, err := m.ListTasksByBase(base.Name)
 		if err != nil {
-			return nil, err
+			// one database's tasks failing to load should not stop the
+			// others from being scheduled
+			continue
 		}
 
 		results = append(results, tasks...)
@@ -30,9 +33,22 @@ func (m *Memory) ListTasksByBase(dbName string) ([]model.Task, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	for i := range tasks {
+		tasks[i].BaseName = dbName
+	}
 	return tasks, nil
 }
 
+func (m *Memory) GetTaskByID(dbName, id string) (task model.Task, err error) {
+	if err = getByID(m, dbName, "sb_tasks", id, &task); err != nil {
+		return
+	}
+
+	task.BaseName = dbName
+	return
+}
+
 func (m *Memory) AddTask(dbName string, task model.Task) (id string, err error) {
 	id = m.NewID()
 	task.ID = id
@@ -41,6 +57,21 @@ func (m *Memory) AddTask(dbName string, task model.Task) (id string, err error)
 	return
 }
 
+func (m *Memory) UpdateTask(dbName, id string, task model.Task) error {
+	var cur model.Task
+	if err := getByID(m, dbName, "sb_tasks", id, &cur); err != nil {
+		return err
+	}
+
+	// the outcome of the last run is kept
+	task.ID = id
+	task.LastRun = cur.LastRun
+	task.LastStatus = cur.LastStatus
+	task.LastError = cur.LastError
+	task.BaseName = ""
+	return create(m, dbName, "sb_tasks", id, task)
+}
+
 func (m *Memory) DeleteTask(dbName, id string) error {
 	key := fmt.Sprintf("%s_sb_tasks", dbName)
 	tasks, ok := m.DB[key]
@@ -53,5 +84,31 @@ func (m *Memory) DeleteTask(dbName, id string) error {
 	mx.Lock()
 	m.DB[key] = tasks
 	mx.Unlock()
-	return nil
+
+	_, err := removeWhere(m, dbName, "sb_task_runs", func(run model.TaskRun) bool {
+		return run.TaskID == id
+	})
+	return err
+}
+
+func (m *Memory) UpdateTaskRun(dbName, id string, lastRun time.Time, status, lastError string) error {
+	var task model.Task
+	if err := getByID(m, dbName, "sb_tasks", id, &task); err != nil {
+		return err
+	}
+
+	task.LastRun = lastRun
+	task.LastStatus = status
+	task.LastError = lastError
+	return create(m, dbName, "sb_tasks", id, task)
+}
+
+func (m *Memory) SetTaskEnabled(dbName, id string, enabled bool) error {
+	var task model.Task
+	if err := getByID(m, dbName, "sb_tasks", id, &task); err != nil {
+		return err
+	}
+
+	task.Enabled = enabled
+	return create(m, dbName, "sb_tasks", id, task)
 }
@@ -2,6 +2,9 @@ package memory
 
 import (
 	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
 )
 
 func TestListTasks(t *testing.T) {
@@ -10,3 +13,219 @@ func TestListTasks(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestUpdateTaskRun(t *testing.T) {
+	task := model.Task{
+		Name:     "run-status",
+		Type:     model.TaskTypeMessage,
+		Value:    "test",
+		Interval: "@hourly",
+		LastRun:  time.Now(),
+	}
+
+	id, err := datastore.AddTask(confDBName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteTask(confDBName, id)
+
+	ran := time.Now().Add(time.Minute).Truncate(time.Second)
+	if err := datastore.UpdateTaskRun(confDBName, id, ran, model.TaskStatusFailed, "boom"); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := datastore.ListTasksByBase(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tk := range tasks {
+		if tk.ID != id {
+			continue
+		}
+
+		if tk.Name != "run-status" || tk.BaseName != confDBName {
+			t.Errorf("unexpected task %v", tk)
+		} else if tk.LastStatus != model.TaskStatusFailed || tk.LastError != "boom" {
+			t.Errorf("expected the failed status got %s %s", tk.LastStatus, tk.LastError)
+		} else if !tk.LastRun.Equal(ran) {
+			t.Errorf("expected last run %v got %v", ran, tk.LastRun)
+		}
+		return
+	}
+	t.Errorf("cannot find task %s", id)
+}
+
+func TestAddOneOffTask(t *testing.T) {
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	task := model.Task{
+		Name:  "one-off",
+		Type:  model.TaskTypeMessage,
+		Value: "test",
+		RunAt: at,
+		Retry: model.TaskRetry{MaxAttempts: 3, Backoff: 5, Jitter: 0.2},
+	}
+
+	id, err := datastore.AddTask(confDBName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteTask(confDBName, id)
+
+	tasks, err := datastore.ListTasksByBase(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tk := range tasks {
+		if tk.ID != id {
+			continue
+		}
+
+		if !tk.IsOneOff() {
+			t.Errorf("expected a one-off task got %v", tk)
+		} else if !tk.RunAt.Equal(at) {
+			t.Errorf("expected run at %v got %v", at, tk.RunAt)
+		} else if tk.Retry != task.Retry {
+			t.Errorf("expected retry policy %v got %v", task.Retry, tk.Retry)
+		}
+		return
+	}
+	t.Errorf("cannot find task %s", id)
+}
+
+func TestTaskRuns(t *testing.T) {
+	task := model.Task{
+		Name:     "with-runs",
+		Type:     model.TaskTypeMessage,
+		Value:    "test",
+		Interval: "@hourly",
+	}
+
+	taskID, err := datastore.AddTask(confDBName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteTask(confDBName, taskID)
+
+	run := model.TaskRun{
+		TaskID:    taskID,
+		Attempt:   1,
+		Started:   time.Now().Add(-time.Minute),
+		Completed: time.Now().Add(-time.Minute),
+		Error:     "boom",
+	}
+	if _, err := datastore.AddTaskRun(confDBName, run); err != nil {
+		t.Fatal(err)
+	}
+
+	run.Attempt = 2
+	run.Started = time.Now()
+	run.Completed = time.Now()
+	run.Success = true
+	run.Output = "published test to"
+	run.Error = ""
+	id, err := datastore.AddTaskRun(confDBName, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := datastore.ListTaskRuns(confDBName, taskID, 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(runs) != 2 {
+		t.Fatalf("expected 2 runs got %d", len(runs))
+	} else if runs[0].ID != id || !runs[0].Success || runs[0].Attempt != 2 {
+		t.Errorf("expected the successful run first got %v", runs[0])
+	} else if runs[1].Error != "boom" {
+		t.Errorf("expected the failed run error got %v", runs[1])
+	}
+
+	runs, err = datastore.ListTaskRuns(confDBName, taskID, 1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(runs) != 1 {
+		t.Errorf("expected the limit to be applied got %d", len(runs))
+	}
+}
+
+func TestSetTaskEnabled(t *testing.T) {
+	task := model.Task{
+		Name:     "pausable",
+		Type:     model.TaskTypeMessage,
+		Value:    "test",
+		Interval: "@hourly",
+		Enabled:  true,
+	}
+
+	id, err := datastore.AddTask(confDBName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteTask(confDBName, id)
+
+	if err := datastore.SetTaskEnabled(confDBName, id, false); err != nil {
+		t.Fatal(err)
+	}
+
+	paused, err := datastore.GetTaskByID(confDBName, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if paused.Enabled {
+		t.Error("expected the task to be paused")
+	} else if paused.Name != task.Name || paused.BaseName != confDBName {
+		t.Errorf("unexpected task %v", paused)
+	}
+
+	if err := datastore.SetTaskEnabled(confDBName, id, true); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := datastore.GetTaskByID(confDBName, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if !resumed.Enabled {
+		t.Error("expected the task to be resumed")
+	}
+}
+
+func TestUpdateTask(t *testing.T) {
+	task := model.Task{
+		Name:     "updatable",
+		Type:     model.TaskTypeMessage,
+		Value:    "test",
+		Interval: "@hourly",
+		Enabled:  true,
+	}
+
+	id, err := datastore.AddTask(confDBName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteTask(confDBName, id)
+
+	lastRun := time.Now().UTC().Truncate(time.Second)
+	if err := datastore.UpdateTaskRun(confDBName, id, lastRun, model.TaskStatusSuccess, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	task.Name = "updated"
+	task.Type = model.TaskTypeHTTP
+	task.Value = "https://example.com/hook"
+	task.Interval = "@daily"
+	task.Retry = model.TaskRetry{MaxAttempts: 2, Backoff: 30}
+	if err := datastore.UpdateTask(confDBName, id, task); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := datastore.GetTaskByID(confDBName, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if updated.Name != task.Name || updated.Type != task.Type || updated.Value != task.Value {
+		t.Errorf("unexpected task %v", updated)
+	} else if updated.Interval != task.Interval || updated.Retry != task.Retry || !updated.Enabled {
+		t.Errorf("unexpected schedule %v", updated)
+	} else if updated.LastStatus != model.TaskStatusSuccess || !updated.LastRun.Equal(lastRun) {
+		t.Errorf("expected the last run to be kept, got %v", updated)
+	}
+}
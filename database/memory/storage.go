@@ -3,6 +3,7 @@ package memory
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/staticbackendhq/core/model"
 )
@@ -41,9 +42,90 @@ func (m *Memory) ListAllFiles(dbName, accountID string) (results []model.File, e
 		return
 	}
 
+	// all files are listed without an account
 	results = filter(files, func(x model.File) bool {
-		return x.AccountID == accountID
+		return len(accountID) == 0 || x.AccountID == accountID
 	})
 
 	return
 }
+
+func (m *Memory) ListFiles(dbName string, f model.FileFilter) (results []model.File, err error) {
+	files, err := all[model.File](m, dbName, "sb_files")
+	if err != nil {
+		return
+	}
+
+	name := strings.ToLower(f.Name)
+
+	results = filter(files, func(x model.File) bool {
+		if len(f.AccountID) > 0 && x.AccountID != f.AccountID {
+			return false
+		} else if len(name) > 0 && !strings.Contains(strings.ToLower(x.Name), name) {
+			return false
+		} else if len(f.MimeType) > 0 && !strings.HasPrefix(x.MimeType, f.MimeType) {
+			return false
+		} else if len(f.Tag) > 0 && !hasTag(x.Tags, f.Tag) {
+			return false
+		} else if len(f.Collection) > 0 && x.Collection != f.Collection {
+			return false
+		} else if len(f.DocumentID) > 0 && x.DocumentID != f.DocumentID {
+			return false
+		} else if len(f.UserID) > 0 && x.UserID != f.UserID {
+			return false
+		}
+		return true
+	})
+
+	results = sortSlice(results, func(a, b model.File) bool {
+		return a.Uploaded.After(b.Uploaded)
+	})
+
+	if f.Limit > 0 {
+		if f.Offset >= int64(len(results)) {
+			return nil, nil
+		}
+
+		results = results[f.Offset:]
+		if int64(len(results)) > f.Limit {
+			results = results[:f.Limit]
+		}
+	}
+	return
+}
+
+func (m *Memory) LinkFile(dbName, fileID string, link model.FileLink) error {
+	var f model.File
+	if err := getByID(m, dbName, "sb_files", fileID, &f); err != nil {
+		return err
+	}
+
+	f.FileLink = link
+	return create(m, dbName, "sb_files", fileID, f)
+}
+
+func (m *Memory) StorageUsage(dbName string) (usage model.StorageUsage, err error) {
+	files, err := all[model.File](m, dbName, "sb_files")
+	if err != nil {
+		return
+	}
+
+	for _, f := range files {
+		usage.Files++
+		usage.Bytes += f.Size
+
+		for _, v := range f.Variants {
+			usage.Bytes += v.Size
+		}
+	}
+	return
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -14,6 +15,9 @@ func TestFileStorage(t *testing.T) {
 		URL:       "https://test",
 		Size:      123456,
 		Uploaded:  time.Now(),
+		Variants: []model.FileVariant{
+			{Name: "thumb", Key: "key_thumb", URL: "https://test/thumb", Size: 1234},
+		},
 	}
 
 	f1 := model.File{
@@ -46,6 +50,8 @@ func TestFileStorage(t *testing.T) {
 		t.Fatal(err)
 	} else if f2.Key != f.Key {
 		t.Errorf("expected key to be %s got %s", f.Key, f2.Key)
+	} else if len(f2.Variants) != 1 || f2.Variants[0].URL != "https://test/thumb" {
+		t.Errorf("expected thumb variant got %v", f2.Variants)
 	}
 
 	if err := datastore.DeleteFile(confDBName, id); err != nil {
@@ -59,3 +65,130 @@ func TestFileStorage(t *testing.T) {
 		t.Errorf("deleted file id returned? %v", check)
 	}
 }
+
+func TestListFiles(t *testing.T) {
+	files := []model.File{
+		{Name: "Holidays.jpg", MimeType: "image/jpeg", Tags: []string{"photo", "2023"}},
+		{Name: "report.pdf", MimeType: "application/pdf", Tags: []string{"work"}},
+		{Name: "holidays-map.png", MimeType: "image/png"},
+	}
+
+	var ids []string
+	for i, f := range files {
+		f.AccountID = adminAccount.ID
+		f.Key = fmt.Sprintf("list-files-%d", i)
+		f.URL = "https://test/" + f.Key
+		f.Uploaded = time.Now().Add(time.Duration(i) * time.Second)
+
+		id, err := datastore.AddFile(confDBName, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	defer func() {
+		for _, id := range ids {
+			datastore.DeleteFile(confDBName, id)
+		}
+	}()
+
+	list, err := datastore.ListFiles(confDBName, model.FileFilter{AccountID: adminAccount.ID, Name: "holidays"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Fatalf("expected 2 files got %d", len(list))
+	} else if list[0].Name != "holidays-map.png" {
+		t.Errorf("expected most recent file first got %s", list[0].Name)
+	}
+
+	list, err = datastore.ListFiles(confDBName, model.FileFilter{AccountID: adminAccount.ID, MimeType: "image/"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Errorf("expected 2 images got %d", len(list))
+	}
+
+	list, err = datastore.ListFiles(confDBName, model.FileFilter{AccountID: adminAccount.ID, Tag: "photo"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Name != "Holidays.jpg" {
+		t.Errorf("expected Holidays.jpg got %v", list)
+	} else if len(list[0].Tags) != 2 {
+		t.Errorf("expected 2 tags got %v", list[0].Tags)
+	}
+
+	list, err = datastore.ListFiles(confDBName, model.FileFilter{AccountID: adminAccount.ID, Name: "holidays", Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Name != "Holidays.jpg" {
+		t.Errorf("expected the 2nd page to be Holidays.jpg got %v", list)
+	}
+}
+
+func TestLinkFile(t *testing.T) {
+	f := model.File{
+		AccountID: adminAccount.ID,
+		Key:       "link-file",
+		URL:       "https://test/link-file",
+		Uploaded:  time.Now(),
+	}
+
+	id, err := datastore.AddFile(confDBName, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteFile(confDBName, id)
+
+	link := model.FileLink{Collection: "tasks", DocumentID: "task-1"}
+	if err := datastore.LinkFile(confDBName, id, link); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := datastore.ListFiles(confDBName, model.FileFilter{Collection: "tasks", DocumentID: "task-1"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].ID != id {
+		t.Fatalf("expected the linked file got %v", list)
+	} else if list[0].DocumentID != "task-1" {
+		t.Errorf("expected document id task-1 got %s", list[0].DocumentID)
+	}
+
+	list, err = datastore.ListFiles(confDBName, model.FileFilter{Collection: "tasks", DocumentID: "task-2"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 0 {
+		t.Errorf("expected no files got %v", list)
+	}
+}
+
+func TestStorageUsage(t *testing.T) {
+	before, err := datastore.StorageUsage(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := model.File{
+		AccountID: adminAccount.ID,
+		Key:       "usage-file",
+		URL:       "https://test/usage-file",
+		Size:      1000,
+		Uploaded:  time.Now(),
+		Variants:  []model.FileVariant{{Name: "thumb", Key: "usage-file-thumb", Size: 100}},
+	}
+
+	id, err := datastore.AddFile(confDBName, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteFile(confDBName, id)
+
+	after, err := datastore.StorageUsage(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	} else if after.Files != before.Files+1 {
+		t.Errorf("expected %d files got %d", before.Files+1, after.Files)
+	} else if after.Bytes != before.Bytes+1100 {
+		t.Errorf("expected %d bytes got %d", before.Bytes+1100, after.Bytes)
+	}
+}
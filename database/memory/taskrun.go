@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddTaskRun(dbName string, run model.TaskRun) (string, error) {
+	run.ID = m.NewID()
+	if err := create(m, dbName, "sb_task_runs", run.ID, run); err != nil {
+		return "", err
+	}
+	return run.ID, nil
+}
+
+func (m *Memory) ListTaskRuns(dbName, taskID string, limit int64) (results []model.TaskRun, err error) {
+	list, err := all[model.TaskRun](m, dbName, "sb_task_runs")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.TaskRun) bool {
+		return x.TaskID == taskID
+	})
+
+	results = sortSlice(results, func(a, b model.TaskRun) bool {
+		return a.Started.After(b.Started)
+	})
+
+	if limit > 0 && int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddOwnershipTransfer(t model.OwnershipTransfer) (id string, err error) {
+	id = m.NewID()
+
+	t.ID = id
+	t.Status = model.TransferPending
+	t.Completed = time.Time{}
+
+	err = create(m, "sb", "sb_ownership_transfers", id, t)
+	return
+}
+
+func (m *Memory) GetOwnershipTransfer(id string) (t model.OwnershipTransfer, err error) {
+	if err = getByID(m, "sb", "sb_ownership_transfers", id, &t); err != nil {
+		return
+	} else if len(t.ID) == 0 {
+		err = errors.New("document not found")
+	}
+	return
+}
+
+func (m *Memory) ListOwnershipTransfers(tenantID string) ([]model.OwnershipTransfer, error) {
+	list, err := all[model.OwnershipTransfer](m, "sb", "sb_ownership_transfers")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.OwnershipTransfer) bool {
+		return x.FromTenantID == tenantID || x.ToTenantID == tenantID
+	})
+
+	list = sortSlice(list, func(a, b model.OwnershipTransfer) bool {
+		return a.Created.After(b.Created)
+	})
+	return list, nil
+}
+
+func (m *Memory) CompleteOwnershipTransfer(id, status string, completed time.Time) error {
+	var t model.OwnershipTransfer
+	if err := getByID(m, "sb", "sb_ownership_transfers", id, &t); err != nil {
+		return err
+	} else if t.Status != model.TransferPending {
+		return errors.New("the transfer is not pending")
+	}
+
+	t.Status = status
+	t.Completed = completed
+
+	return create(m, "sb", "sb_ownership_transfers", id, t)
+}
+
+func (m *Memory) SetDatabaseTenant(baseID, tenantID string) error {
+	base, err := m.FindDatabase(baseID)
+	if err != nil {
+		return err
+	}
+
+	base.TenantID = tenantID
+
+	return create(m, "sb", "apps", baseID, base)
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestOwnershipTransfers(t *testing.T) {
+	to, err := datastore.CreateTenant(model.Tenant{Email: "transferto@test.com", Created: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := datastore.CreateDatabase(model.DatabaseConfig{
+		ID:       datastore.NewID(),
+		TenantID: dbTest.TenantID,
+		Name:     "transfertest",
+		IsActive: true,
+		Created:  time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	tr := model.OwnershipTransfer{
+		BaseID:       base.ID,
+		FromTenantID: base.TenantID,
+		ToTenantID:   to.ID,
+		ToEmail:      to.Email,
+		Created:      now,
+		Expires:      now.Add(time.Hour),
+	}
+
+	id, err := datastore.AddOwnershipTransfer(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := datastore.GetOwnershipTransfer(id)
+	if err != nil {
+		t.Fatal(err)
+	} else if saved.ID != id || saved.Status != model.TransferPending || saved.ToTenantID != to.ID {
+		t.Fatalf("expected the pending transfer %s got %v", id, saved)
+	}
+
+	for _, tenantID := range []string{base.TenantID, to.ID} {
+		list, err := datastore.ListOwnershipTransfers(tenantID)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(list) != 1 || list[0].ID != id {
+			t.Errorf("expected the transfer for tenant %s got %v", tenantID, list)
+		}
+	}
+
+	if err := datastore.CompleteOwnershipTransfer(id, model.TransferAccepted, now); err != nil {
+		t.Fatal(err)
+	} else if err := datastore.CompleteOwnershipTransfer(id, model.TransferCancelled, now); err == nil {
+		t.Error("expected an error completing a transfer not pending")
+	}
+
+	if saved, err := datastore.GetOwnershipTransfer(id); err != nil {
+		t.Fatal(err)
+	} else if saved.Status != model.TransferAccepted || saved.Completed.IsZero() {
+		t.Errorf("expected the transfer to be accepted got %v", saved)
+	}
+
+	if err := datastore.SetDatabaseTenant(base.ID, to.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if moved, err := datastore.FindDatabase(base.ID); err != nil {
+		t.Fatal(err)
+	} else if moved.TenantID != to.ID {
+		t.Errorf("expected the database to belong to %s got %s", to.ID, moved.TenantID)
+	}
+}
+
+func TestResetUserCredentials(t *testing.T) {
+	tok := model.User{
+		AccountID: adminAccount.ID,
+		Token:     "before-reset",
+		Email:     "reset-credentials@test.com",
+		Password:  "before",
+		Role:      100,
+		Created:   time.Now(),
+	}
+
+	id, err := datastore.CreateUser(confDBName, tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := datastore.ResetUserCredentials(confDBName, id, "new-owner@test.com", "after", "after-reset"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := datastore.FindRootUser(confDBName, id, adminAccount.ID, "before-reset"); err == nil {
+		t.Error("expected the previous token to be refused")
+	}
+
+	user, err := datastore.FindRootUser(confDBName, id, adminAccount.ID, "after-reset")
+	if err != nil {
+		t.Fatal(err)
+	} else if user.Email != "new-owner@test.com" || user.Password != "after" {
+		t.Errorf("expected the new credentials got %v", user)
+	}
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// usageMu makes the read and write of the usage records atomic
+var usageMu sync.Mutex
+
+func usageID(u model.UsageRecord) string {
+	return u.TenantID + "_" + u.DBName + "_" + u.Day + "_" + u.Metric
+}
+
+func (m *Memory) AddUsage(records []model.UsageRecord) error {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	for _, u := range records {
+		var cur model.UsageRecord
+		if err := getByID(m, "sb", "sb_usage", usageID(u), &cur); err == nil {
+			u.Value += cur.Value
+		}
+
+		if err := create(m, "sb", "sb_usage", usageID(u), u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) ListUsage(f model.UsageFilter) ([]model.UsageRecord, error) {
+	list, err := all[model.UsageRecord](m, "sb", "sb_usage")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.UsageRecord) bool {
+		return x.TenantID == f.TenantID &&
+			(len(f.DBName) == 0 || x.DBName == f.DBName) &&
+			(len(f.Since) == 0 || x.Day >= f.Since) &&
+			(len(f.Until) == 0 || x.Day <= f.Until)
+	})
+
+	list = sortSlice(list, func(a, b model.UsageRecord) bool {
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		} else if a.DBName != b.DBName {
+			return a.DBName < b.DBName
+		}
+		return a.Metric < b.Metric
+	})
+	return list, nil
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"errors"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) AddSecret(s model.Secret) (id string, err error) {
+	id = m.NewID()
+	s.ID = id
+
+	err = create(m, "sb", "sb_secrets", id, s)
+	return
+}
+
+func (m *Memory) ListSecrets(dbName string) ([]model.Secret, error) {
+	list, err := all[model.Secret](m, "sb", "sb_secrets")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.Secret) bool {
+		return x.DBName == dbName
+	})
+
+	list = sortSlice(list, func(a, b model.Secret) bool {
+		if a.Name == b.Name {
+			return a.Version > b.Version
+		}
+		return a.Name < b.Name
+	})
+	return list, nil
+}
+
+func (m *Memory) GetSecret(dbName, name string, version int) (s model.Secret, err error) {
+	list, err := m.ListSecrets(dbName)
+	if err != nil {
+		return
+	}
+
+	for _, x := range list {
+		if x.Name == name && (version == 0 || x.Version == version) {
+			return x, nil
+		}
+	}
+	return s, errors.New("secret not found")
+}
+
+func (m *Memory) UpdateSecret(s model.Secret) error {
+	var cur model.Secret
+	if err := getByID(m, "sb", "sb_secrets", s.ID, &cur); err != nil {
+		return err
+	}
+
+	cur.Value = s.Value
+	cur.DataKey = s.DataKey
+	cur.KeyID = s.KeyID
+	return create(m, "sb", "sb_secrets", cur.ID, cur)
+}
+
+func (m *Memory) DeleteSecret(dbName, name string) error {
+	_, err := removeWhere(m, "sb", "sb_secrets", func(x model.Secret) bool {
+		return x.DBName == dbName && x.Name == name
+	})
+	return err
+}
+
+func (m *Memory) ListSecretsToRewrap(keyID string, limit int64) ([]model.Secret, error) {
+	list, err := all[model.Secret](m, "sb", "sb_secrets")
+	if err != nil {
+		return nil, err
+	}
+
+	list = filter(list, func(x model.Secret) bool {
+		return x.KeyID != keyID
+	})
+
+	if int64(len(list)) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
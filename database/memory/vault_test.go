@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestSecrets(t *testing.T) {
+	v1 := model.Secret{
+		DBName:  confDBName,
+		Name:    "API_KEY",
+		Version: 1,
+		Value:   []byte("sealed-1"),
+		DataKey: []byte("wrapped-1"),
+		KeyID:   "old-key",
+		Created: time.Now(),
+	}
+	if _, err := datastore.AddSecret(v1); err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := v1
+	v2.Version = 2
+	v2.Value = []byte("sealed-2")
+	v2.KeyID = "current-key"
+
+	id, err := datastore.AddSecret(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := datastore.GetSecret(confDBName, "API_KEY", 0)
+	if err != nil {
+		t.Fatal(err)
+	} else if latest.ID != id || latest.Version != 2 || !bytes.Equal(latest.Value, v2.Value) {
+		t.Errorf("expected the latest version got %v", latest)
+	}
+
+	first, err := datastore.GetSecret(confDBName, "API_KEY", 1)
+	if err != nil {
+		t.Fatal(err)
+	} else if first.Version != 1 || !bytes.Equal(first.DataKey, v1.DataKey) {
+		t.Errorf("expected the first version got %v", first)
+	}
+
+	if _, err := datastore.GetSecret(confDBName, "API_KEY", 3); err == nil {
+		t.Error("expected an error getting a missing version")
+	}
+
+	list, err := datastore.ListSecrets(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 || list[0].Version != 2 || list[1].Version != 1 {
+		t.Fatalf("expected the newest version first got %v", list)
+	}
+
+	stale, err := datastore.ListSecretsToRewrap("current-key", 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(stale) != 1 || stale[0].ID != first.ID {
+		t.Fatalf("expected the first version to be rewrapped got %v", stale)
+	}
+
+	first.DataKey = []byte("rewrapped-1")
+	first.KeyID = "current-key"
+	if err := datastore.UpdateSecret(first); err != nil {
+		t.Fatal(err)
+	}
+
+	if stale, err := datastore.ListSecretsToRewrap("current-key", 10); err != nil {
+		t.Fatal(err)
+	} else if len(stale) != 0 {
+		t.Errorf("expected no secrets to rewrap got %v", stale)
+	}
+
+	if err := datastore.DeleteSecret(confDBName, "API_KEY"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = datastore.ListSecrets(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 0 {
+		t.Errorf("expected the secret to be deleted got %v", list)
+	}
+}
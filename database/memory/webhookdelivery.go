@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (m *Memory) QueueWebhook(dbName string, d model.WebhookDelivery) (string, error) {
+	d.ID = m.NewID()
+	if err := create(m, dbName, "sb_webhook_deliveries", d.ID, d); err != nil {
+		return "", err
+	}
+	return d.ID, nil
+}
+
+func (m *Memory) GetWebhookDelivery(dbName, id string) (d model.WebhookDelivery, err error) {
+	if err = getByID(m, dbName, "sb_webhook_deliveries", id, &d); err != nil {
+		return
+	} else if len(d.ID) == 0 {
+		// no delivery was queued yet
+		err = errors.New("document not found")
+	}
+	return
+}
+
+func (m *Memory) ListDueWebhooks(dbName string, now time.Time, limit int64) (results []model.WebhookDelivery, err error) {
+	list, err := all[model.WebhookDelivery](m, dbName, "sb_webhook_deliveries")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.WebhookDelivery) bool {
+		return x.Status == model.WebhookStatusPending && !x.NextAttempt.After(now)
+	})
+
+	results = sortSlice(results, func(a, b model.WebhookDelivery) bool {
+		return a.NextAttempt.Before(b.NextAttempt)
+	})
+
+	if limit > 0 && int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return
+}
+
+func (m *Memory) UpdateWebhookStatus(dbName, id, status string, attempts, statusCode int, lastError string, nextAttempt time.Time) error {
+	var d model.WebhookDelivery
+	if err := getByID(m, dbName, "sb_webhook_deliveries", id, &d); err != nil {
+		return err
+	}
+
+	d.Status = status
+	d.Attempts = attempts
+	d.StatusCode = statusCode
+	d.LastError = lastError
+	d.NextAttempt = nextAttempt
+	d.Updated = time.Now()
+	return create(m, dbName, "sb_webhook_deliveries", id, d)
+}
+
+func (m *Memory) ListWebhookDeliveries(dbName string, f model.WebhookDeliveryFilter) (results []model.WebhookDelivery, err error) {
+	list, err := all[model.WebhookDelivery](m, dbName, "sb_webhook_deliveries")
+	if err != nil {
+		return
+	}
+
+	results = filter(list, func(x model.WebhookDelivery) bool {
+		if len(f.URL) > 0 && x.URL != f.URL {
+			return false
+		} else if len(f.Event) > 0 && x.Event != f.Event {
+			return false
+		} else if len(f.Status) > 0 && x.Status != f.Status {
+			return false
+		}
+		return true
+	})
+
+	results = sortSlice(results, func(a, b model.WebhookDelivery) bool {
+		return a.Created.After(b.Created)
+	})
+
+	if f.Limit > 0 && int64(len(results)) > f.Limit {
+		results = results[:f.Limit]
+	}
+	return
+}
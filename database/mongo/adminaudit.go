@@ -0,0 +1,114 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalAdminEvent struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	Type      string             `bson:"type" json:"type"`
+	Actor     string             `bson:"actor" json:"actor"`
+	TenantID  string             `bson:"tenantId" json:"tenantId"`
+	DBName    string             `bson:"dbName" json:"dbName"`
+	Target    string             `bson:"target" json:"target"`
+	Detail    string             `bson:"detail" json:"detail"`
+	IP        string             `bson:"ip" json:"ip"`
+	UserAgent string             `bson:"ua" json:"userAgent"`
+	Created   time.Time          `bson:"created" json:"created"`
+}
+
+func fromLocalAdminEvent(le LocalAdminEvent) model.AdminEvent {
+	return model.AdminEvent{
+		ID:        le.ID.Hex(),
+		Type:      le.Type,
+		Actor:     le.Actor,
+		TenantID:  le.TenantID,
+		DBName:    le.DBName,
+		Target:    le.Target,
+		Detail:    le.Detail,
+		IP:        le.IP,
+		UserAgent: le.UserAgent,
+		Created:   le.Created,
+	}
+}
+
+func (mg *Mongo) AddAdminEvent(evt model.AdminEvent) error {
+	db := mg.Client.Database("sbsys")
+
+	le := LocalAdminEvent{
+		ID:        primitive.NewObjectID(),
+		Type:      evt.Type,
+		Actor:     evt.Actor,
+		TenantID:  evt.TenantID,
+		DBName:    evt.DBName,
+		Target:    evt.Target,
+		Detail:    evt.Detail,
+		IP:        evt.IP,
+		UserAgent: evt.UserAgent,
+		Created:   evt.Created,
+	}
+
+	_, err := db.Collection("admin_audit").InsertOne(mg.Ctx, le)
+	return err
+}
+
+func (mg *Mongo) ListAdminEvents(f model.AdminEventFilter) ([]model.AdminEvent, error) {
+	db := mg.Client.Database("sbsys")
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"created": -1})
+	if f.Limit > 0 {
+		opts.SetLimit(f.Limit)
+	}
+
+	cur, err := db.Collection("admin_audit").Find(mg.Ctx, adminEventFilter(f), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.AdminEvent
+	for cur.Next(mg.Ctx) {
+		var le LocalAdminEvent
+		if err := cur.Decode(&le); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalAdminEvent(le))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func adminEventFilter(f model.AdminEventFilter) bson.M {
+	filter := bson.M{}
+	if len(f.TenantID) > 0 {
+		filter["tenantId"] = f.TenantID
+	}
+	if len(f.DBName) > 0 {
+		filter["dbName"] = f.DBName
+	}
+	if len(f.Type) > 0 {
+		filter["type"] = f.Type
+	}
+
+	created := bson.M{}
+	if !f.Since.IsZero() {
+		created["$gte"] = f.Since
+	}
+	if !f.Until.IsZero() {
+		created["$lte"] = f.Until
+	}
+	if len(created) > 0 {
+		filter["created"] = created
+	}
+	return filter
+}
@@ -0,0 +1,56 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAdminEvents(t *testing.T) {
+	evt := model.AdminEvent{
+		Type:     model.AdminDatabaseSuspended,
+		Actor:    "admin",
+		TenantID: dbTest.TenantID,
+		DBName:   "adminaudit",
+		Detail:   model.SuspendedAbuse,
+		Created:  time.Now().Add(-48 * time.Hour),
+	}
+
+	if err := datastore.AddAdminEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+
+	evt.Type = model.AdminDatabaseReactivated
+	evt.Detail = ""
+	evt.Created = time.Now()
+	if err := datastore.AddAdminEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := datastore.ListAdminEvents(model.AdminEventFilter{DBName: "adminaudit"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Fatalf("expected 2 admin events got %d", len(list))
+	} else if list[0].Type != model.AdminDatabaseReactivated || list[1].Detail != model.SuspendedAbuse {
+		t.Errorf("expected most recent event first, got %v", list)
+	}
+
+	list, err = datastore.ListAdminEvents(model.AdminEventFilter{
+		DBName: "adminaudit",
+		Since:  time.Now().Add(-24 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Type != model.AdminDatabaseReactivated {
+		t.Errorf("expected the event of the last day got %v", list)
+	}
+
+	list, err = datastore.ListAdminEvents(model.AdminEventFilter{DBName: "adminaudit", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Errorf("expected the limit to apply got %d events", len(list))
+	}
+}
@@ -0,0 +1,85 @@
+package mongo
+
+import (
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalRequestRollup struct {
+	TenantID    string `bson:"tenantId" json:"tenantId"`
+	DBName      string `bson:"dbName" json:"dbName"`
+	Hour        string `bson:"hour" json:"hour"`
+	Endpoint    string `bson:"endpoint" json:"endpoint"`
+	Status      int    `bson:"status" json:"status"`
+	Country     string `bson:"country" json:"country"`
+	Count       int64  `bson:"count" json:"count"`
+	TotalMillis int64  `bson:"totalMs" json:"totalMs"`
+	MaxMillis   int64  `bson:"maxMs" json:"maxMs"`
+}
+
+func (mg *Mongo) AddRequestRollups(records []model.RequestRollup) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	db := mg.Client.Database("sbsys")
+
+	var updates []mongo.WriteModel
+	for _, rr := range records {
+		filter := bson.M{
+			"dbName":   rr.DBName,
+			"hour":     rr.Hour,
+			"endpoint": rr.Endpoint,
+			"status":   rr.Status,
+			"country":  rr.Country,
+		}
+		update := bson.M{
+			"$set": bson.M{"tenantId": rr.TenantID},
+			"$inc": bson.M{"count": rr.Count, "totalMs": rr.TotalMillis},
+			"$max": bson.M{"maxMs": rr.MaxMillis},
+		}
+
+		updates = append(updates, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	_, err := db.Collection("request_rollups").BulkWrite(mg.Ctx, updates)
+	return err
+}
+
+func (mg *Mongo) ListRequestRollups(filter model.RequestRollupFilter) ([]model.RequestRollup, error) {
+	db := mg.Client.Database("sbsys")
+
+	f := bson.M{"dbName": filter.DBName}
+
+	hour := bson.M{}
+	if len(filter.Since) > 0 {
+		hour["$gte"] = filter.Since
+	}
+	if len(filter.Until) > 0 {
+		hour["$lte"] = filter.Until
+	}
+	if len(hour) > 0 {
+		f["hour"] = hour
+	}
+
+	opts := options.Find().SetSort(bson.M{"hour": 1})
+	cur, err := db.Collection("request_rollups").Find(mg.Ctx, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.RequestRollup
+	for cur.Next(mg.Ctx) {
+		var lr LocalRequestRollup
+		if err := cur.Decode(&lr); err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.RequestRollup(lr))
+	}
+
+	return results, cur.Err()
+}
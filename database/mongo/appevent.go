@@ -0,0 +1,180 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalAppEvent struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Name       string             `bson:"name" json:"name"`
+	SessionID  string             `bson:"sessionId" json:"sessionId"`
+	Properties map[string]any     `bson:"props" json:"properties"`
+	Timestamp  time.Time          `bson:"ts" json:"timestamp"`
+	Received   time.Time          `bson:"received" json:"received"`
+}
+
+type LocalAppEventRollup struct {
+	Day   string `bson:"day" json:"day"`
+	Name  string `bson:"name" json:"name"`
+	Count int64  `bson:"count" json:"count"`
+}
+
+func fromLocalAppEvent(le LocalAppEvent) model.AppEvent {
+	return model.AppEvent{
+		ID:         le.ID.Hex(),
+		Name:       le.Name,
+		SessionID:  le.SessionID,
+		Properties: le.Properties,
+		Timestamp:  le.Timestamp,
+		Received:   le.Received,
+	}
+}
+
+func (mg *Mongo) AddAppEvents(dbName string, events []model.AppEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	db := mg.Client.Database(dbName)
+
+	docs := make([]interface{}, 0, len(events))
+	counts := make(map[model.AppEventRollup]int64)
+	for _, evt := range events {
+		docs = append(docs, LocalAppEvent{
+			ID:         primitive.NewObjectID(),
+			Name:       evt.Name,
+			SessionID:  evt.SessionID,
+			Properties: evt.Properties,
+			Timestamp:  evt.Timestamp,
+			Received:   evt.Received,
+		})
+
+		counts[model.AppEventRollup{Day: evt.Received.UTC().Format("2006-01-02"), Name: evt.Name}]++
+	}
+
+	if _, err := db.Collection("sb_events").InsertMany(mg.Ctx, docs); err != nil {
+		return err
+	}
+
+	var updates []mongo.WriteModel
+	for r, n := range counts {
+		filter := bson.M{"day": r.Day, "name": r.Name}
+		update := bson.M{"$inc": bson.M{"count": n}}
+
+		updates = append(updates, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	_, err := db.Collection("sb_event_rollups").BulkWrite(mg.Ctx, updates)
+	return err
+}
+
+func (mg *Mongo) ListAppEvents(dbName string, f model.AppEventFilter) ([]model.AppEvent, error) {
+	db := mg.Client.Database(dbName)
+
+	filter := bson.M{}
+	if len(f.Name) > 0 {
+		filter["name"] = f.Name
+	}
+	if len(f.SessionID) > 0 {
+		filter["sessionId"] = f.SessionID
+	}
+
+	received := bson.M{}
+	if !f.Since.IsZero() {
+		received["$gte"] = f.Since
+	}
+	if !f.Until.IsZero() {
+		received["$lte"] = f.Until
+	}
+	if len(received) > 0 {
+		filter["received"] = received
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"received": -1})
+	if f.Limit > 0 {
+		opts.SetLimit(f.Limit)
+	}
+
+	cur, err := db.Collection("sb_events").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.AppEvent
+	for cur.Next(mg.Ctx) {
+		var le LocalAppEvent
+		if err := cur.Decode(&le); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalAppEvent(le))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (mg *Mongo) ListAppEventRollups(dbName string, f model.AppEventRollupFilter) ([]model.AppEventRollup, error) {
+	db := mg.Client.Database(dbName)
+
+	filter := bson.M{}
+	if len(f.Name) > 0 {
+		filter["name"] = f.Name
+	}
+
+	day := bson.M{}
+	if len(f.Since) > 0 {
+		day["$gte"] = f.Since
+	}
+	if len(f.Until) > 0 {
+		day["$lte"] = f.Until
+	}
+	if len(day) > 0 {
+		filter["day"] = day
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: "day", Value: 1}, {Key: "name", Value: 1}})
+
+	cur, err := db.Collection("sb_event_rollups").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.AppEventRollup
+	for cur.Next(mg.Ctx) {
+		var lr LocalAppEventRollup
+		if err := cur.Decode(&lr); err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.AppEventRollup{Day: lr.Day, Name: lr.Name, Count: lr.Count})
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (mg *Mongo) PurgeAppEvents(dbName string, before time.Time) (int64, error) {
+	db := mg.Client.Database(dbName)
+
+	filter := bson.M{"received": bson.M{"$lt": before}}
+	res, err := db.Collection("sb_events").DeleteMany(mg.Ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,138 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalAuditEvent struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	AccountID string             `bson:"accountId" json:"accountId"`
+	UserID    string             `bson:"userId" json:"userId"`
+	Email     string             `bson:"email" json:"email"`
+	Type      string             `bson:"type" json:"type"`
+	Detail    string             `bson:"detail" json:"detail"`
+	IP        string             `bson:"ip" json:"ip"`
+	UserAgent string             `bson:"ua" json:"userAgent"`
+	Created   time.Time          `bson:"created" json:"created"`
+}
+
+func toLocalAuditEvent(evt model.AuditEvent) LocalAuditEvent {
+	return LocalAuditEvent{
+		ID:        primitive.NewObjectID(),
+		AccountID: evt.AccountID,
+		UserID:    evt.UserID,
+		Email:     evt.Email,
+		Type:      evt.Type,
+		Detail:    evt.Detail,
+		IP:        evt.IP,
+		UserAgent: evt.UserAgent,
+		Created:   evt.Created,
+	}
+}
+
+func fromLocalAuditEvent(le LocalAuditEvent) model.AuditEvent {
+	return model.AuditEvent{
+		ID:        le.ID.Hex(),
+		AccountID: le.AccountID,
+		UserID:    le.UserID,
+		Email:     le.Email,
+		Type:      le.Type,
+		Detail:    le.Detail,
+		IP:        le.IP,
+		UserAgent: le.UserAgent,
+		Created:   le.Created,
+	}
+}
+
+func (mg *Mongo) AddAuditEvent(dbName string, evt model.AuditEvent) error {
+	db := mg.Client.Database(dbName)
+
+	if _, err := db.Collection("sb_audit").InsertOne(mg.Ctx, toLocalAuditEvent(evt)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) ListAuditEvents(dbName string, f model.AuditFilter) ([]model.AuditEvent, error) {
+	db := mg.Client.Database(dbName)
+
+	filter := auditFilter(f)
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"created": -1})
+	if f.Limit > 0 {
+		opts.SetLimit(f.Limit)
+	}
+
+	cur, err := db.Collection("sb_audit").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.AuditEvent
+	for cur.Next(mg.Ctx) {
+		var le LocalAuditEvent
+		if err := cur.Decode(&le); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalAuditEvent(le))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (mg *Mongo) PurgeAuditEvents(dbName string, before time.Time) (int64, error) {
+	db := mg.Client.Database(dbName)
+
+	filter := bson.M{"created": bson.M{"$lt": before}}
+	res, err := db.Collection("sb_audit").DeleteMany(mg.Ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (mg *Mongo) CountAuditUsers(dbName string, f model.AuditFilter) (int64, error) {
+	db := mg.Client.Database(dbName)
+
+	users, err := db.Collection("sb_audit").Distinct(mg.Ctx, "userId", auditFilter(f))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(users)), nil
+}
+
+func auditFilter(f model.AuditFilter) bson.M {
+	filter := bson.M{}
+	if len(f.AccountID) > 0 {
+		filter["accountId"] = f.AccountID
+	}
+	if len(f.UserID) > 0 {
+		filter["userId"] = f.UserID
+	}
+	if len(f.Type) > 0 {
+		filter["type"] = f.Type
+	}
+
+	created := bson.M{}
+	if !f.Since.IsZero() {
+		created["$gte"] = f.Since
+	}
+	if !f.Until.IsZero() {
+		created["$lte"] = f.Until
+	}
+	if len(created) > 0 {
+		filter["created"] = created
+	}
+	return filter
+}
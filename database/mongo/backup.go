@@ -0,0 +1,108 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalBackup struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	TenantID  string             `bson:"tenantId" json:"tenantId"`
+	DBName    string             `bson:"dbName" json:"dbName"`
+	TaskID    string             `bson:"taskId" json:"taskId"`
+	Key       string             `bson:"key" json:"key"`
+	Size      int64              `bson:"size" json:"size"`
+	Documents int64              `bson:"documents" json:"documents"`
+	Created   time.Time          `bson:"created" json:"created"`
+}
+
+func fromLocalBackup(lb LocalBackup) model.Backup {
+	return model.Backup{
+		ID:        lb.ID.Hex(),
+		TenantID:  lb.TenantID,
+		DBName:    lb.DBName,
+		TaskID:    lb.TaskID,
+		Key:       lb.Key,
+		Size:      lb.Size,
+		Documents: lb.Documents,
+		Created:   lb.Created,
+	}
+}
+
+func (mg *Mongo) AddBackup(b model.Backup) (id string, err error) {
+	db := mg.Client.Database("sbsys")
+
+	lb := LocalBackup{
+		ID:        primitive.NewObjectID(),
+		TenantID:  b.TenantID,
+		DBName:    b.DBName,
+		TaskID:    b.TaskID,
+		Key:       b.Key,
+		Size:      b.Size,
+		Documents: b.Documents,
+		Created:   b.Created,
+	}
+
+	if _, err = db.Collection("backups").InsertOne(mg.Ctx, lb); err != nil {
+		return
+	}
+
+	id = lb.ID.Hex()
+	return
+}
+
+func (mg *Mongo) ListBackups(dbName string) ([]model.Backup, error) {
+	db := mg.Client.Database("sbsys")
+
+	opts := options.Find().SetSort(bson.M{"created": -1})
+	cur, err := db.Collection("backups").Find(mg.Ctx, bson.M{"dbName": dbName}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.Backup
+	for cur.Next(mg.Ctx) {
+		var lb LocalBackup
+		if err := cur.Decode(&lb); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalBackup(lb))
+	}
+	return results, cur.Err()
+}
+
+func (mg *Mongo) GetBackup(id string) (b model.Backup, err error) {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return
+	}
+
+	var lb LocalBackup
+	sr := db.Collection("backups").FindOne(mg.Ctx, bson.M{FieldID: oid})
+	if err = sr.Decode(&lb); err != nil {
+		return
+	}
+
+	b = fromLocalBackup(lb)
+	return
+}
+
+func (mg *Mongo) DeleteBackup(id string) error {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("backups").DeleteOne(mg.Ctx, bson.M{FieldID: oid})
+	return err
+}
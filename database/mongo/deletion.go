@@ -0,0 +1,131 @@
+package mongo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalAppDeletion struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	BaseID      string             `bson:"baseId" json:"baseId"`
+	TenantID    string             `bson:"tenantId" json:"tenantId"`
+	DBName      string             `bson:"dbName" json:"dbName"`
+	Email       string             `bson:"email" json:"email"`
+	Status      string             `bson:"status" json:"status"`
+	Requested   time.Time          `bson:"requested" json:"requested"`
+	Scheduled   time.Time          `bson:"scheduled" json:"scheduled"`
+	Completed   time.Time          `bson:"completed" json:"completed"`
+	Certificate string             `bson:"certificate" json:"certificate"`
+}
+
+func fromLocalAppDeletion(ld LocalAppDeletion) model.AppDeletion {
+	return model.AppDeletion{
+		ID:          ld.ID.Hex(),
+		BaseID:      ld.BaseID,
+		TenantID:    ld.TenantID,
+		DBName:      ld.DBName,
+		Email:       ld.Email,
+		Status:      ld.Status,
+		Requested:   ld.Requested,
+		Scheduled:   ld.Scheduled,
+		Completed:   ld.Completed,
+		Certificate: ld.Certificate,
+	}
+}
+
+func (mg *Mongo) AddAppDeletion(d model.AppDeletion) (id string, err error) {
+	db := mg.Client.Database("sbsys")
+
+	ld := LocalAppDeletion{
+		ID:        primitive.NewObjectID(),
+		BaseID:    d.BaseID,
+		TenantID:  d.TenantID,
+		DBName:    d.DBName,
+		Email:     d.Email,
+		Status:    model.AppDeletionPending,
+		Requested: d.Requested,
+		Scheduled: d.Scheduled,
+	}
+
+	if _, err = db.Collection("app_deletions").InsertOne(mg.Ctx, ld); err != nil {
+		return
+	}
+
+	id = ld.ID.Hex()
+	return
+}
+
+func (mg *Mongo) GetAppDeletion(baseID string) (model.AppDeletion, error) {
+	db := mg.Client.Database("sbsys")
+
+	filter := bson.M{"baseId": baseID, "status": model.AppDeletionPending}
+
+	var ld LocalAppDeletion
+	sr := db.Collection("app_deletions").FindOne(mg.Ctx, filter)
+	if err := sr.Decode(&ld); errors.Is(err, mongo.ErrNoDocuments) {
+		return model.AppDeletion{}, nil
+	} else if err != nil {
+		return model.AppDeletion{}, err
+	}
+	return fromLocalAppDeletion(ld), nil
+}
+
+func (mg *Mongo) ListDueAppDeletions(now time.Time) ([]model.AppDeletion, error) {
+	db := mg.Client.Database("sbsys")
+
+	filter := bson.M{"status": model.AppDeletionPending, "scheduled": bson.M{"$lte": now}}
+	opts := options.Find().SetSort(bson.M{"scheduled": 1})
+
+	cur, err := db.Collection("app_deletions").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.AppDeletion
+	for cur.Next(mg.Ctx) {
+		var ld LocalAppDeletion
+		if err := cur.Decode(&ld); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalAppDeletion(ld))
+	}
+	return results, cur.Err()
+}
+
+func (mg *Mongo) CompleteAppDeletion(id string, completed time.Time, certificate string) error {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"status":      model.AppDeletionCompleted,
+		"completed":   completed,
+		"certificate": certificate,
+	}}
+	_, err = db.Collection("app_deletions").UpdateOne(mg.Ctx, bson.M{FieldID: oid}, update)
+	return err
+}
+
+func (mg *Mongo) CancelAppDeletion(id string) error {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid, "status": model.AppDeletionPending}
+	_, err = db.Collection("app_deletions").DeleteOne(mg.Ctx, filter)
+	return err
+}
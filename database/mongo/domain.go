@@ -0,0 +1,109 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalDomain struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	DBName   string             `bson:"dbName" json:"dbName"`
+	BaseID   string             `bson:"baseId" json:"baseId"`
+	Hostname string             `bson:"hostname" json:"hostname"`
+	Token    string             `bson:"token" json:"token"`
+	Verified bool               `bson:"verified" json:"verified"`
+	Created  time.Time          `bson:"created" json:"created"`
+}
+
+func fromLocalDomain(ld LocalDomain) model.Domain {
+	return model.Domain{
+		ID:       ld.ID.Hex(),
+		DBName:   ld.DBName,
+		BaseID:   ld.BaseID,
+		Hostname: ld.Hostname,
+		Token:    ld.Token,
+		Verified: ld.Verified,
+		Created:  ld.Created,
+	}
+}
+
+func (mg *Mongo) AddDomain(d model.Domain) (id string, err error) {
+	db := mg.Client.Database("sbsys")
+
+	ld := LocalDomain{
+		ID:       primitive.NewObjectID(),
+		DBName:   d.DBName,
+		BaseID:   d.BaseID,
+		Hostname: d.Hostname,
+		Token:    d.Token,
+		Verified: d.Verified,
+		Created:  d.Created,
+	}
+
+	if _, err = db.Collection("domains").InsertOne(mg.Ctx, ld); err != nil {
+		return
+	}
+
+	id = ld.ID.Hex()
+	return
+}
+
+func (mg *Mongo) ListDomains(dbName string) ([]model.Domain, error) {
+	db := mg.Client.Database("sbsys")
+
+	opts := options.Find().SetSort(bson.M{"hostname": 1})
+
+	cur, err := db.Collection("domains").Find(mg.Ctx, bson.M{"dbName": dbName}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.Domain
+	for cur.Next(mg.Ctx) {
+		var ld LocalDomain
+		if err := cur.Decode(&ld); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalDomain(ld))
+	}
+	return results, cur.Err()
+}
+
+func (mg *Mongo) FindDomain(hostname string) (d model.Domain, err error) {
+	db := mg.Client.Database("sbsys")
+
+	var ld LocalDomain
+	sr := db.Collection("domains").FindOne(mg.Ctx, bson.M{"hostname": hostname})
+	if err = sr.Decode(&ld); err != nil {
+		return
+	}
+
+	d = fromLocalDomain(ld)
+	return
+}
+
+func (mg *Mongo) VerifyDomain(id string) error {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"verified": true}}
+	_, err = db.Collection("domains").UpdateOne(mg.Ctx, bson.M{FieldID: oid}, update)
+	return err
+}
+
+func (mg *Mongo) DeleteDomain(dbName, hostname string) error {
+	db := mg.Client.Database("sbsys")
+
+	_, err := db.Collection("domains").DeleteOne(mg.Ctx, bson.M{"dbName": dbName, "hostname": hostname})
+	return err
+}
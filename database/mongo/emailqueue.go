@@ -0,0 +1,217 @@
+package mongo
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalEmailMessage struct {
+	ID          primitive.ObjectID      `bson:"_id" json:"id"`
+	From        string                  `bson:"from" json:"from"`
+	FromName    string                  `bson:"fromName" json:"fromName"`
+	To          string                  `bson:"to" json:"to"`
+	ToName      string                  `bson:"toName" json:"toName"`
+	ReplyTo     string                  `bson:"replyTo" json:"replyTo"`
+	Subject     string                  `bson:"subject" json:"subject"`
+	Template    string                  `bson:"template" json:"template"`
+	HTMLBody    string                  `bson:"htmlBody" json:"htmlBody"`
+	TextBody    string                  `bson:"textBody" json:"textBody"`
+	Attachments []model.EmailAttachment `bson:"attachments" json:"attachments"`
+	Status      string                  `bson:"status" json:"status"`
+	Attempts    int                     `bson:"attempts" json:"attempts"`
+	LastError   string                  `bson:"lastError" json:"lastError"`
+	ProviderID  string                  `bson:"providerId" json:"providerId"`
+	NextAttempt time.Time               `bson:"nextAttempt" json:"nextAttempt"`
+	Created     time.Time               `bson:"created" json:"created"`
+	Updated     time.Time               `bson:"updated" json:"updated"`
+}
+
+func fromLocalEmailMessage(lm LocalEmailMessage) model.EmailMessage {
+	return model.EmailMessage{
+		ID:          lm.ID.Hex(),
+		From:        lm.From,
+		FromName:    lm.FromName,
+		To:          lm.To,
+		ToName:      lm.ToName,
+		ReplyTo:     lm.ReplyTo,
+		Subject:     lm.Subject,
+		Template:    lm.Template,
+		HTMLBody:    lm.HTMLBody,
+		TextBody:    lm.TextBody,
+		Attachments: lm.Attachments,
+		Status:      lm.Status,
+		Attempts:    lm.Attempts,
+		LastError:   lm.LastError,
+		ProviderID:  lm.ProviderID,
+		NextAttempt: lm.NextAttempt,
+		Created:     lm.Created,
+		Updated:     lm.Updated,
+	}
+}
+
+func (mg *Mongo) QueueEmail(dbName string, msg model.EmailMessage) (string, error) {
+	db := mg.Client.Database(dbName)
+
+	lm := LocalEmailMessage{
+		ID:          primitive.NewObjectID(),
+		From:        msg.From,
+		FromName:    msg.FromName,
+		To:          msg.To,
+		ToName:      msg.ToName,
+		ReplyTo:     msg.ReplyTo,
+		Subject:     msg.Subject,
+		Template:    msg.Template,
+		HTMLBody:    msg.HTMLBody,
+		TextBody:    msg.TextBody,
+		Attachments: msg.Attachments,
+		Status:      msg.Status,
+		Attempts:    msg.Attempts,
+		LastError:   msg.LastError,
+		ProviderID:  msg.ProviderID,
+		NextAttempt: msg.NextAttempt,
+		Created:     msg.Created,
+		Updated:     msg.Updated,
+	}
+
+	if _, err := db.Collection("sb_email_queue").InsertOne(mg.Ctx, lm); err != nil {
+		return "", err
+	}
+	return lm.ID.Hex(), nil
+}
+
+func (mg *Mongo) GetEmailMessage(dbName, id string) (model.EmailMessage, error) {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return model.EmailMessage{}, err
+	}
+
+	var lm LocalEmailMessage
+	sr := db.Collection("sb_email_queue").FindOne(mg.Ctx, bson.M{FieldID: oid})
+	if err := sr.Decode(&lm); err != nil {
+		return model.EmailMessage{}, err
+	}
+	return fromLocalEmailMessage(lm), nil
+}
+
+func (mg *Mongo) ListDueEmails(dbName string, now time.Time, limit int64) ([]model.EmailMessage, error) {
+	db := mg.Client.Database(dbName)
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"nextAttempt": 1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	filter := bson.M{"status": model.EmailStatusQueued, "nextAttempt": bson.M{"$lte": now}}
+	cur, err := db.Collection("sb_email_queue").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.EmailMessage
+	for cur.Next(mg.Ctx) {
+		var lm LocalEmailMessage
+		if err := cur.Decode(&lm); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalEmailMessage(lm))
+	}
+
+	return results, cur.Err()
+}
+
+func (mg *Mongo) UpdateEmailStatus(dbName, id, status string, attempts int, lastError, providerID string, nextAttempt time.Time) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"attempts":    attempts,
+		"lastError":   lastError,
+		"providerId":  providerID,
+		"nextAttempt": nextAttempt,
+		"updated":     time.Now(),
+	}}
+	if _, err := db.Collection("sb_email_queue").UpdateOne(mg.Ctx, bson.M{FieldID: oid}, update); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) ListEmailLog(dbName string, f model.EmailLogFilter) ([]model.EmailMessage, error) {
+	db := mg.Client.Database(dbName)
+
+	filter := emailLogFilter(f)
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"created": -1})
+	if f.Limit > 0 {
+		opts.SetLimit(f.Limit)
+	}
+
+	cur, err := db.Collection("sb_email_queue").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.EmailMessage
+	for cur.Next(mg.Ctx) {
+		var lm LocalEmailMessage
+		if err := cur.Decode(&lm); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalEmailMessage(lm))
+	}
+
+	return results, cur.Err()
+}
+
+func (mg *Mongo) CountEmails(dbName string, f model.EmailLogFilter) (int64, error) {
+	db := mg.Client.Database(dbName)
+
+	return db.Collection("sb_email_queue").CountDocuments(mg.Ctx, emailLogFilter(f))
+}
+
+func emailLogFilter(f model.EmailLogFilter) bson.M {
+	filter := bson.M{}
+	if len(f.To) > 0 {
+		filter["to"] = f.To
+	}
+	if len(f.Template) > 0 {
+		filter["template"] = f.Template
+	}
+	if len(f.Status) > 0 {
+		filter["status"] = f.Status
+	}
+	if len(f.Query) > 0 {
+		rx := primitive.Regex{Pattern: regexp.QuoteMeta(f.Query), Options: "i"}
+		filter["$or"] = bson.A{bson.M{"to": rx}, bson.M{"subject": rx}}
+	}
+
+	created := bson.M{}
+	if !f.Since.IsZero() {
+		created["$gte"] = f.Since
+	}
+	if !f.Until.IsZero() {
+		created["$lte"] = f.Until
+	}
+	if len(created) > 0 {
+		filter["created"] = created
+	}
+	return filter
+}
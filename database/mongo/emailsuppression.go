@@ -0,0 +1,141 @@
+package mongo
+
+import (
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalEmailEvent struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	Email     string             `bson:"email" json:"email"`
+	Type      string             `bson:"type" json:"type"`
+	Permanent bool               `bson:"permanent" json:"permanent"`
+	Provider  string             `bson:"provider" json:"provider"`
+	Detail    string             `bson:"detail" json:"detail"`
+	Created   time.Time          `bson:"created" json:"created"`
+}
+
+type LocalEmailSuppression struct {
+	Email    string    `bson:"email" json:"email"`
+	Reason   string    `bson:"reason" json:"reason"`
+	Provider string    `bson:"provider" json:"provider"`
+	Detail   string    `bson:"detail" json:"detail"`
+	Created  time.Time `bson:"created" json:"created"`
+}
+
+func (mg *Mongo) AddEmailEvent(dbName string, e model.EmailEvent) error {
+	db := mg.Client.Database(dbName)
+
+	le := LocalEmailEvent{
+		ID:        primitive.NewObjectID(),
+		Email:     strings.ToLower(e.Email),
+		Type:      e.Type,
+		Permanent: e.Permanent,
+		Provider:  e.Provider,
+		Detail:    e.Detail,
+		Created:   e.Created,
+	}
+
+	_, err := db.Collection("sb_email_events").InsertOne(mg.Ctx, le)
+	return err
+}
+
+func (mg *Mongo) ListEmailEvents(dbName, email string, limit int64) ([]model.EmailEvent, error) {
+	db := mg.Client.Database(dbName)
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"created": -1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	filter := bson.M{}
+	if len(email) > 0 {
+		filter["email"] = strings.ToLower(email)
+	}
+
+	cur, err := db.Collection("sb_email_events").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.EmailEvent
+	for cur.Next(mg.Ctx) {
+		var le LocalEmailEvent
+		if err := cur.Decode(&le); err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.EmailEvent{
+			ID:        le.ID.Hex(),
+			Email:     le.Email,
+			Type:      le.Type,
+			Permanent: le.Permanent,
+			Provider:  le.Provider,
+			Detail:    le.Detail,
+			Created:   le.Created,
+		})
+	}
+
+	return results, cur.Err()
+}
+
+func (mg *Mongo) ListEmailSuppressions(dbName string) ([]model.EmailSuppression, error) {
+	db := mg.Client.Database(dbName)
+
+	opts := options.Find().SetSort(bson.M{"created": -1})
+	cur, err := db.Collection("sb_email_suppressions").Find(mg.Ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.EmailSuppression
+	for cur.Next(mg.Ctx) {
+		var ls LocalEmailSuppression
+		if err := cur.Decode(&ls); err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.EmailSuppression(ls))
+	}
+
+	return results, cur.Err()
+}
+
+func (mg *Mongo) IsEmailSuppressed(dbName, email string) (bool, error) {
+	db := mg.Client.Database(dbName)
+
+	count, err := db.Collection("sb_email_suppressions").CountDocuments(mg.Ctx, bson.M{"email": strings.ToLower(email)})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (mg *Mongo) SuppressEmail(dbName string, s model.EmailSuppression) error {
+	db := mg.Client.Database(dbName)
+
+	ls := LocalEmailSuppression(s)
+	ls.Email = strings.ToLower(s.Email)
+
+	// an address already suppressed keeps its reason
+	filter := bson.M{"email": ls.Email}
+	update := bson.M{"$setOnInsert": ls}
+	opts := options.Update().SetUpsert(true)
+	_, err := db.Collection("sb_email_suppressions").UpdateOne(mg.Ctx, filter, update, opts)
+	return err
+}
+
+func (mg *Mongo) RemoveEmailSuppression(dbName, email string) error {
+	db := mg.Client.Database(dbName)
+
+	_, err := db.Collection("sb_email_suppressions").DeleteOne(mg.Ctx, bson.M{"email": strings.ToLower(email)})
+	return err
+}
@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestEmailSuppressions(t *testing.T) {
+	bounce := model.EmailEvent{
+		Email:     "Bounced@Domain.com",
+		Type:      model.EmailEventBounce,
+		Permanent: true,
+		Provider:  "ses",
+		Detail:    "550 mailbox does not exist",
+		Created:   time.Now(),
+	}
+	if err := datastore.AddEmailEvent(confDBName, bounce); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := datastore.ListEmailEvents(confDBName, "bounced@domain.com", 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 1 || !events[0].Permanent || events[0].Email != "bounced@domain.com" {
+		t.Fatalf("expected the bounce got %v", events)
+	}
+
+	s := model.EmailSuppression{
+		Email:    bounce.Email,
+		Reason:   model.EmailEventBounce,
+		Provider: bounce.Provider,
+		Detail:   bounce.Detail,
+		Created:  time.Now(),
+	}
+	if err := datastore.SuppressEmail(confDBName, s); err != nil {
+		t.Fatal(err)
+	}
+
+	// suppressing again keeps the first reason
+	s.Reason = model.EmailEventComplaint
+	if err := datastore.SuppressEmail(confDBName, s); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := datastore.IsEmailSuppressed(confDBName, "bounced@domain.com"); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Error("expected the address to be suppressed")
+	}
+
+	list, err := datastore.ListEmailSuppressions(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Reason != model.EmailEventBounce {
+		t.Fatalf("expected one bounce suppression got %v", list)
+	}
+
+	if err := datastore.RemoveEmailSuppression(confDBName, bounce.Email); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := datastore.IsEmailSuppressed(confDBName, bounce.Email); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Error("expected the suppression to be removed")
+	}
+}
@@ -0,0 +1,140 @@
+package mongo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalEmailTemplate struct {
+	Name      string    `bson:"name" json:"name"`
+	Subject   string    `bson:"subject" json:"subject"`
+	HTMLBody  string    `bson:"htmlBody" json:"htmlBody"`
+	TextBody  string    `bson:"textBody" json:"textBody"`
+	Variables []string  `bson:"variables" json:"variables"`
+	Updated   time.Time `bson:"updated" json:"updated"`
+}
+
+func fromLocalEmailTemplate(lt LocalEmailTemplate) model.EmailTemplate {
+	return model.EmailTemplate{
+		Name:      lt.Name,
+		Subject:   lt.Subject,
+		HTMLBody:  lt.HTMLBody,
+		TextBody:  lt.TextBody,
+		Variables: lt.Variables,
+		Updated:   lt.Updated,
+	}
+}
+
+func (mg *Mongo) ListEmailTemplates(dbName string) ([]model.EmailTemplate, error) {
+	db := mg.Client.Database(dbName)
+
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cur, err := db.Collection("sb_email_templates").Find(mg.Ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.EmailTemplate
+	for cur.Next(mg.Ctx) {
+		var lt LocalEmailTemplate
+		if err := cur.Decode(&lt); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalEmailTemplate(lt))
+	}
+
+	return results, cur.Err()
+}
+
+func (mg *Mongo) GetEmailTemplate(dbName, name string) (model.EmailTemplate, error) {
+	db := mg.Client.Database(dbName)
+
+	var lt LocalEmailTemplate
+	sr := db.Collection("sb_email_templates").FindOne(mg.Ctx, bson.M{"name": name})
+	if err := sr.Decode(&lt); err != nil {
+		return model.EmailTemplate{}, err
+	}
+
+	return fromLocalEmailTemplate(lt), nil
+}
+
+func (mg *Mongo) SaveEmailTemplate(dbName string, tmpl model.EmailTemplate) error {
+	db := mg.Client.Database(dbName)
+
+	lt := LocalEmailTemplate{
+		Name:      tmpl.Name,
+		Subject:   tmpl.Subject,
+		HTMLBody:  tmpl.HTMLBody,
+		TextBody:  tmpl.TextBody,
+		Variables: tmpl.Variables,
+		Updated:   tmpl.Updated,
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := db.Collection("sb_email_templates").ReplaceOne(mg.Ctx, bson.M{"name": tmpl.Name}, lt, opts); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) DeleteEmailTemplate(dbName, name string) error {
+	db := mg.Client.Database(dbName)
+
+	if _, err := db.Collection("sb_email_templates").DeleteOne(mg.Ctx, bson.M{"name": name}); err != nil {
+		return err
+	}
+	return nil
+}
+
+type LocalUserLocale struct {
+	UserID primitive.ObjectID `bson:"userId" json:"userId"`
+	Locale string             `bson:"locale" json:"locale"`
+}
+
+func (mg *Mongo) GetUserLocale(dbName, userID string) (string, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", err
+	}
+
+	db := mg.Client.Database(dbName)
+
+	var ul LocalUserLocale
+	sr := db.Collection("sb_user_locales").FindOne(mg.Ctx, bson.M{"userId": id})
+	if err := sr.Decode(&ul); errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return ul.Locale, nil
+}
+
+func (mg *Mongo) SetUserLocale(dbName, userID, locale string) error {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	db := mg.Client.Database(dbName)
+
+	if len(locale) == 0 {
+		_, err := db.Collection("sb_user_locales").DeleteOne(mg.Ctx, bson.M{"userId": id})
+		return err
+	}
+
+	ul := LocalUserLocale{UserID: id, Locale: locale}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := db.Collection("sb_user_locales").ReplaceOne(mg.Ctx, bson.M{"userId": id}, ul, opts); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestEmailTemplates(t *testing.T) {
+	if _, err := datastore.GetEmailTemplate(confDBName, "welcome"); err == nil {
+		t.Fatal("expected an error for a missing template")
+	}
+
+	tmpl := model.EmailTemplate{
+		Name:      "welcome",
+		Subject:   "Welcome [name]",
+		HTMLBody:  "<p>Hi [name]</p>",
+		Variables: []string{"name"},
+		Updated:   time.Now(),
+	}
+	if err := datastore.SaveEmailTemplate(confDBName, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.DeleteEmailTemplate(confDBName, tmpl.Name)
+
+	// saving again replaces the template
+	tmpl.TextBody = "Hi [name]"
+	tmpl.Variables = append(tmpl.Variables, "plan")
+	if err := datastore.SaveEmailTemplate(confDBName, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := datastore.GetEmailTemplate(confDBName, "welcome")
+	if err != nil {
+		t.Fatal(err)
+	} else if saved.Subject != tmpl.Subject || saved.HTMLBody != tmpl.HTMLBody || saved.TextBody != tmpl.TextBody {
+		t.Errorf("unexpected template %v", saved)
+	} else if len(saved.Variables) != 2 || saved.Variables[1] != "plan" {
+		t.Errorf("unexpected variables %v", saved.Variables)
+	}
+
+	list, err := datastore.ListEmailTemplates(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Name != "welcome" {
+		t.Fatalf("expected the welcome template got %v", list)
+	}
+
+	if err := datastore.DeleteEmailTemplate(confDBName, "welcome"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := datastore.GetEmailTemplate(confDBName, "welcome"); err == nil {
+		t.Error("expected the template to be deleted")
+	}
+}
+
+func TestUserLocales(t *testing.T) {
+	locale, err := datastore.GetUserLocale(confDBName, adminToken.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(locale) > 0 {
+		t.Fatalf("expected no locale got %s", locale)
+	}
+
+	if err := datastore.SetUserLocale(confDBName, adminToken.ID, "fr"); err != nil {
+		t.Fatal(err)
+	}
+	defer datastore.SetUserLocale(confDBName, adminToken.ID, "")
+
+	// setting the locale again replaces it
+	if err := datastore.SetUserLocale(confDBName, adminToken.ID, "fr-ca"); err != nil {
+		t.Fatal(err)
+	}
+
+	if locale, err := datastore.GetUserLocale(confDBName, adminToken.ID); err != nil {
+		t.Fatal(err)
+	} else if locale != "fr-ca" {
+		t.Errorf("expected fr-ca got %s", locale)
+	}
+
+	if err := datastore.SetUserLocale(confDBName, adminToken.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if locale, err := datastore.GetUserLocale(confDBName, adminToken.ID); err != nil {
+		t.Fatal(err)
+	} else if len(locale) > 0 {
+		t.Errorf("expected the locale to be removed got %s", locale)
+	}
+}
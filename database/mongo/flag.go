@@ -0,0 +1,66 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalFeatureFlag struct {
+	TenantID string    `bson:"tenantId" json:"tenantId"`
+	Name     string    `bson:"name" json:"name"`
+	Enabled  bool      `bson:"enabled" json:"enabled"`
+	Updated  time.Time `bson:"updated" json:"updated"`
+}
+
+func (mg *Mongo) SetFeatureFlag(flag model.FeatureFlag) error {
+	db := mg.Client.Database("sbsys")
+
+	lf := LocalFeatureFlag{
+		TenantID: flag.TenantID,
+		Name:     flag.Name,
+		Enabled:  flag.Enabled,
+		Updated:  flag.Updated,
+	}
+
+	filter := bson.M{"tenantId": flag.TenantID, "name": flag.Name}
+	opts := options.Replace().SetUpsert(true)
+	_, err := db.Collection("feature_flags").ReplaceOne(mg.Ctx, filter, lf, opts)
+	return err
+}
+
+func (mg *Mongo) ListFeatureFlags(tenantID string) ([]model.FeatureFlag, error) {
+	db := mg.Client.Database("sbsys")
+
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cur, err := db.Collection("feature_flags").Find(mg.Ctx, bson.M{"tenantId": tenantID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.FeatureFlag
+	for cur.Next(mg.Ctx) {
+		var lf LocalFeatureFlag
+		if err := cur.Decode(&lf); err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.FeatureFlag{
+			TenantID: lf.TenantID,
+			Name:     lf.Name,
+			Enabled:  lf.Enabled,
+			Updated:  lf.Updated,
+		})
+	}
+	return results, cur.Err()
+}
+
+func (mg *Mongo) DeleteFeatureFlag(tenantID, name string) error {
+	db := mg.Client.Database("sbsys")
+
+	_, err := db.Collection("feature_flags").DeleteOne(mg.Ctx, bson.M{"tenantId": tenantID, "name": name})
+	return err
+}
@@ -2,8 +2,10 @@ package mongo
 
 import (
 	"fmt"
+	"regexp"
 	"time"
 
+	"github.com/staticbackendhq/core/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -97,3 +99,169 @@ func (mg *Mongo) GetForms(dbName string) ([]string, error) {
 
 	return names, nil
 }
+
+func (mg *Mongo) EachFormSubmission(dbName, name string, since, until time.Time, fn func(doc map[string]interface{}) error) error {
+	db := mg.Client.Database(dbName)
+
+	filter := formFilter(name, model.FormFilter{Since: since, Until: until})
+
+	opt := options.Find()
+	opt.SetSort(bson.M{"sb_posted": 1})
+
+	cur, err := db.Collection("sb_forms").Find(mg.Ctx, filter, opt)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(mg.Ctx)
+
+	for cur.Next(mg.Ctx) {
+		var doc bson.M
+		if err := cur.Decode(&doc); err != nil {
+			return err
+		}
+
+		if err := fn(fromFormSubmission(doc)); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+func (mg *Mongo) QueryFormSubmissions(dbName, name string, f model.FormFilter, params model.ListParams) (result model.PagedResult, err error) {
+	db := mg.Client.Database(dbName)
+
+	result.Page = params.Page
+	result.Size = params.Size
+
+	filter := formFilter(name, f)
+
+	result.Total, err = db.Collection("sb_forms").CountDocuments(mg.Ctx, filter)
+	if err != nil {
+		return
+	}
+
+	sort := 1
+	if params.SortDescending {
+		sort = -1
+	}
+
+	opt := options.Find()
+	opt.SetSort(bson.M{"sb_posted": sort})
+	opt.SetSkip((params.Page - 1) * params.Size)
+	opt.SetLimit(params.Size)
+
+	cur, err := db.Collection("sb_forms").Find(mg.Ctx, filter, opt)
+	if err != nil {
+		return
+	}
+	defer cur.Close(mg.Ctx)
+
+	result.Results = make([]map[string]interface{}, 0)
+	for cur.Next(mg.Ctx) {
+		var doc bson.M
+		if err = cur.Decode(&doc); err != nil {
+			return
+		}
+
+		result.Results = append(result.Results, fromFormSubmission(doc))
+	}
+
+	err = cur.Err()
+	return
+}
+
+func (mg *Mongo) DeleteFormSubmission(dbName, name, id string) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid, FieldFormName: name}
+	if _, err := db.Collection("sb_forms").DeleteOne(mg.Ctx, filter); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) MarkFormSubmissionRead(dbName, name, id string, read bool) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid, FieldFormName: name}
+	update := bson.M{"$set": bson.M{"sb_read": read}}
+	if _, err := db.Collection("sb_forms").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
+// formFilter returns the filter of the submissions of a form matching f
+func formFilter(name string, f model.FormFilter) bson.M {
+	filter := bson.M{FieldFormName: name}
+
+	posted := bson.M{}
+	if !f.Since.IsZero() {
+		posted["$gte"] = f.Since
+	}
+	if !f.Until.IsZero() {
+		posted["$lte"] = f.Until
+	}
+	if len(posted) > 0 {
+		filter["sb_posted"] = posted
+	}
+
+	// the flags are missing on submissions which were never flagged
+	flag := func(field string, v *bool) {
+		if v == nil {
+			return
+		} else if *v {
+			filter[field] = true
+		} else {
+			filter[field] = bson.M{"$ne": true}
+		}
+	}
+	flag("sb_spam", f.Spam)
+	flag("sb_read", f.Read)
+
+	if len(f.Search) > 0 {
+		// matches the documents having a field value containing the term
+		filter["$expr"] = bson.M{
+			"$gt": bson.A{
+				bson.M{"$size": bson.M{"$filter": bson.M{
+					"input": bson.M{"$objectToArray": "$$ROOT"},
+					"as":    "f",
+					"cond": bson.M{"$regexMatch": bson.M{
+						"input":   bson.M{"$convert": bson.M{"input": "$$f.v", "to": "string", "onError": "", "onNull": ""}},
+						"regex":   regexp.QuoteMeta(f.Search),
+						"options": "i",
+					}},
+				}}},
+				0,
+			},
+		}
+	}
+
+	return filter
+}
+
+// fromFormSubmission replaces the internal fields of a submission with its
+// id and created fields
+func fromFormSubmission(doc bson.M) map[string]interface{} {
+	if oid, ok := doc[FieldID].(primitive.ObjectID); ok {
+		doc["id"] = oid.Hex()
+	}
+	if p, ok := doc["sb_posted"].(primitive.DateTime); ok {
+		doc["created"] = p.Time()
+	}
+	delete(doc, FieldID)
+	delete(doc, FieldFormName)
+	delete(doc, "sb_posted")
+	return doc
+}
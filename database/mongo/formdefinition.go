@@ -0,0 +1,87 @@
+package mongo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalFormDefinition struct {
+	Name    string            `bson:"name" json:"name"`
+	Fields  []model.FormField `bson:"fields" json:"fields"`
+	Updated time.Time         `bson:"updated" json:"updated"`
+}
+
+func (mg *Mongo) SaveFormDefinition(dbName string, def model.FormDefinition) error {
+	db := mg.Client.Database(dbName)
+
+	ld := LocalFormDefinition{
+		Name:    def.Name,
+		Fields:  def.Fields,
+		Updated: def.Updated,
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := db.Collection("sb_form_definitions").ReplaceOne(mg.Ctx, bson.M{"name": def.Name}, ld, opts); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) GetFormDefinition(dbName, form string) (model.FormDefinition, error) {
+	db := mg.Client.Database(dbName)
+
+	var ld LocalFormDefinition
+	sr := db.Collection("sb_form_definitions").FindOne(mg.Ctx, bson.M{"name": form})
+	if err := sr.Decode(&ld); errors.Is(err, mongo.ErrNoDocuments) {
+		return model.FormDefinition{Name: form}, nil
+	} else if err != nil {
+		return model.FormDefinition{}, err
+	}
+
+	return model.FormDefinition{
+		Name:    ld.Name,
+		Fields:  ld.Fields,
+		Updated: ld.Updated,
+	}, nil
+}
+
+func (mg *Mongo) DeleteFormDefinition(dbName, form string) error {
+	db := mg.Client.Database(dbName)
+
+	if _, err := db.Collection("sb_form_definitions").DeleteOne(mg.Ctx, bson.M{"name": form}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) ListFormDefinitions(dbName string) (results []model.FormDefinition, err error) {
+	db := mg.Client.Database(dbName)
+
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cur, err := db.Collection("sb_form_definitions").Find(mg.Ctx, bson.M{}, opts)
+	if err != nil {
+		return
+	}
+	defer cur.Close(mg.Ctx)
+
+	for cur.Next(mg.Ctx) {
+		var ld LocalFormDefinition
+		if err = cur.Decode(&ld); err != nil {
+			return
+		}
+
+		results = append(results, model.FormDefinition{
+			Name:    ld.Name,
+			Fields:  ld.Fields,
+			Updated: ld.Updated,
+		})
+	}
+
+	err = cur.Err()
+	return
+}
@@ -0,0 +1,81 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalFormDelivery struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Form       string             `bson:"form" json:"form"`
+	URL        string             `bson:"url" json:"url"`
+	Attempt    int                `bson:"attempt" json:"attempt"`
+	StatusCode int                `bson:"status" json:"statusCode"`
+	Success    bool               `bson:"success" json:"success"`
+	Error      string             `bson:"error" json:"error"`
+	Created    time.Time          `bson:"created" json:"created"`
+}
+
+func (mg *Mongo) AddFormDelivery(dbName string, d model.FormDelivery) error {
+	db := mg.Client.Database(dbName)
+
+	ld := LocalFormDelivery{
+		ID:         primitive.NewObjectID(),
+		Form:       d.Form,
+		URL:        d.URL,
+		Attempt:    d.Attempt,
+		StatusCode: d.StatusCode,
+		Success:    d.Success,
+		Error:      d.Error,
+		Created:    d.Created,
+	}
+
+	if _, err := db.Collection("sb_form_deliveries").InsertOne(mg.Ctx, ld); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) ListFormDeliveries(dbName, form string, limit int64) ([]model.FormDelivery, error) {
+	db := mg.Client.Database(dbName)
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"created": -1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cur, err := db.Collection("sb_form_deliveries").Find(mg.Ctx, bson.M{"form": form}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.FormDelivery
+	for cur.Next(mg.Ctx) {
+		var ld LocalFormDelivery
+		if err := cur.Decode(&ld); err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.FormDelivery{
+			ID:         ld.ID.Hex(),
+			Form:       ld.Form,
+			URL:        ld.URL,
+			Attempt:    ld.Attempt,
+			StatusCode: ld.StatusCode,
+			Success:    ld.Success,
+			Error:      ld.Error,
+			Created:    ld.Created,
+		})
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -281,3 +281,33 @@ func (mg *Mongo) RanFunction(dbName, id string, rh model.ExecHistory) error {
 	}
 	return nil
 }
+
+func (mg *Mongo) CountFunctionRuns(dbName string, since, until time.Time) (int64, error) {
+	db := mg.Client.Database(dbName)
+
+	// the runs are in the functions' history, the first match skips the
+	// functions without runs in the period
+	runs := bson.M{"h.s": bson.M{"$gte": since, "$lt": until}}
+	pipeline := bson.A{
+		bson.M{"$match": runs},
+		bson.M{"$unwind": "$h"},
+		bson.M{"$match": runs},
+		bson.M{"$count": "runs"},
+	}
+
+	cur, err := db.Collection("sb_functions").Aggregate(mg.Ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var result struct {
+		Runs int64 `bson:"runs"`
+	}
+	if cur.Next(mg.Ctx) {
+		if err := cur.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Runs, cur.Err()
+}
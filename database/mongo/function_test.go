@@ -197,3 +197,33 @@ func TestRanFunction(t *testing.T) {
 		t.Errorf("expected history[0] to have succeeded and version at 1 got %v", fn.History[0])
 	}
 }
+
+func TestCountFunctionRuns(t *testing.T) {
+	id, err := createFunction("count-runs", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	month := time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, started := range []time.Time{month, month.Add(24 * time.Hour), month.AddDate(0, 1, 0)} {
+		rh := model.ExecHistory{
+			FunctionID: id,
+			Version:    1,
+			Started:    started,
+			Completed:  started.Add(time.Second),
+			Success:    true,
+			Output:     []string{"started", "completed"},
+		}
+
+		if err := datastore.RanFunction(confDBName, id, rh); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := datastore.CountFunctionRuns(confDBName, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Errorf("expected 2 runs in January got %d", n)
+	}
+}
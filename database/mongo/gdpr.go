@@ -0,0 +1,87 @@
+package mongo
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (mg *Mongo) PurgeUser(dbName string, tok model.User) (report model.DeletionReport, err error) {
+	report = model.NewDeletionReport(tok)
+
+	db := mg.Client.Database(dbName)
+
+	acctID, err := primitive.ObjectIDFromHex(tok.AccountID)
+	if err != nil {
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(tok.ID)
+	if err != nil {
+		return
+	}
+
+	cols, err := mg.ListCollections(dbName)
+	if err != nil {
+		return
+	}
+
+	for _, col := range cols {
+		if strings.HasPrefix(col, "sb_") {
+			continue
+		}
+
+		res, err := db.Collection(col).DeleteMany(mg.Ctx, bson.M{FieldOwnerID: userID})
+		if err != nil {
+			return report, err
+		} else if res.DeletedCount > 0 {
+			report.Documents[col] = res.DeletedCount
+		}
+	}
+
+	email := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(tok.Email) + "$", Options: "i"}
+	res, err := db.Collection("sb_forms").DeleteMany(mg.Ctx, bson.M{"email": email})
+	if err != nil {
+		return
+	}
+
+	report.FormSubmissions = res.DeletedCount
+
+	if _, err = db.Collection("sb_tokens").DeleteOne(mg.Ctx, bson.M{FieldID: userID}); err != nil {
+		return
+	}
+
+	if _, err = db.Collection("sb_push").DeleteMany(mg.Ctx, bson.M{"userId": userID}); err != nil {
+		return
+	}
+
+	if _, err = db.Collection("sb_user_locales").DeleteMany(mg.Ctx, bson.M{"userId": userID}); err != nil {
+		return
+	}
+
+	count, err := db.Collection("sb_tokens").CountDocuments(mg.Ctx, bson.M{FieldAccountID: acctID})
+	if err != nil {
+		return
+	}
+
+	if count == 0 {
+		if _, err = db.Collection("sb_accounts").DeleteOne(mg.Ctx, bson.M{FieldID: acctID}); err != nil {
+			return
+		}
+
+		for _, col := range []string{"sb_files", "sb_invites"} {
+			if _, err = db.Collection(col).DeleteMany(mg.Ctx, bson.M{FieldAccountID: acctID}); err != nil {
+				return
+			}
+		}
+
+		report.AccountDeleted = true
+	}
+
+	report.Completed = time.Now()
+	return
+}
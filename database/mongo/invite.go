@@ -0,0 +1,125 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalInvite struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	AccountID primitive.ObjectID `bson:"accountId" json:"accountId"`
+	Email     string             `bson:"email" json:"email"`
+	Role      int                `bson:"role" json:"role"`
+	InvitedBy string             `bson:"by" json:"invitedBy"`
+	Created   time.Time          `bson:"created" json:"created"`
+	Expires   time.Time          `bson:"expires" json:"expires"`
+}
+
+func toLocalInvite(inv model.Invite) LocalInvite {
+	acctID, err := primitive.ObjectIDFromHex(inv.AccountID)
+	if err != nil {
+		return LocalInvite{}
+	}
+
+	return LocalInvite{
+		AccountID: acctID,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		InvitedBy: inv.InvitedBy,
+		Created:   inv.Created,
+		Expires:   inv.Expires,
+	}
+}
+
+func fromLocalInvite(li LocalInvite) model.Invite {
+	return model.Invite{
+		ID:        li.ID.Hex(),
+		AccountID: li.AccountID.Hex(),
+		Email:     li.Email,
+		Role:      li.Role,
+		InvitedBy: li.InvitedBy,
+		Created:   li.Created,
+		Expires:   li.Expires,
+	}
+}
+
+func (mg *Mongo) AddInvite(dbName string, inv model.Invite) (id string, err error) {
+	db := mg.Client.Database(dbName)
+
+	li := toLocalInvite(inv)
+	li.ID = primitive.NewObjectID()
+
+	if _, err = db.Collection("sb_invites").InsertOne(mg.Ctx, li); err != nil {
+		return
+	}
+
+	id = li.ID.Hex()
+	return
+}
+
+func (mg *Mongo) GetInvite(dbName, id string) (inv model.Invite, err error) {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return
+	}
+
+	var li LocalInvite
+
+	sr := db.Collection("sb_invites").FindOne(mg.Ctx, bson.M{FieldID: oid})
+	if err = sr.Decode(&li); err != nil {
+		return
+	}
+
+	inv = fromLocalInvite(li)
+	return
+}
+
+func (mg *Mongo) ListInvites(dbName, accountID string) ([]model.Invite, error) {
+	db := mg.Client.Database(dbName)
+
+	acctID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := options.Find()
+	opt.SetSort(bson.M{"created": 1})
+
+	cur, err := db.Collection("sb_invites").Find(mg.Ctx, bson.M{FieldAccountID: acctID}, opt)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.Invite
+	for cur.Next(mg.Ctx) {
+		var li LocalInvite
+		if err := cur.Decode(&li); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalInvite(li))
+	}
+
+	return results, cur.Err()
+}
+
+func (mg *Mongo) DeleteInvite(dbName, id string) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Collection("sb_invites").DeleteOne(mg.Ctx, bson.M{FieldID: oid}); err != nil {
+		return err
+	}
+	return nil
+}
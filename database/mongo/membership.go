@@ -98,6 +98,22 @@ func (mg *Mongo) UserSetPassword(dbName, tokenID, password string) error {
 	return nil
 }
 
+func (mg *Mongo) ResetUserCredentials(dbName, userID, email, password, token string) error {
+	db := mg.Client.Database(dbName)
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"email": email, "pw": password, "token": token}}
+	if _, err := db.Collection("sb_tokens").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (mg *Mongo) GetFirstUserFromAccountID(dbName, accountID string) (tok model.User, err error) {
 	db := mg.Client.Database(dbName)
 
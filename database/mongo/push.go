@@ -0,0 +1,114 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LocalPushSubscription struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	AccountID primitive.ObjectID `bson:"accountId" json:"accountId"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Channel   string             `bson:"channel" json:"channel"`
+	Kind      string             `bson:"kind" json:"kind"`
+	Endpoint  string             `bson:"endpoint" json:"endpoint"`
+	P256dh    string             `bson:"p256dh" json:"p256dh"`
+	Auth      string             `bson:"auth" json:"auth"`
+	Created   time.Time          `bson:"created" json:"created"`
+}
+
+func toLocalPushSubscription(sub model.PushSubscription) LocalPushSubscription {
+	acctID, err := primitive.ObjectIDFromHex(sub.AccountID)
+	if err != nil {
+		return LocalPushSubscription{}
+	}
+
+	userID, err := primitive.ObjectIDFromHex(sub.UserID)
+	if err != nil {
+		return LocalPushSubscription{}
+	}
+
+	return LocalPushSubscription{
+		AccountID: acctID,
+		UserID:    userID,
+		Channel:   sub.Channel,
+		Kind:      sub.Kind,
+		Endpoint:  sub.Endpoint,
+		P256dh:    sub.P256dh,
+		Auth:      sub.Auth,
+		Created:   sub.Created,
+	}
+}
+
+func fromLocalPushSubscription(lp LocalPushSubscription) model.PushSubscription {
+	return model.PushSubscription{
+		ID:        lp.ID.Hex(),
+		AccountID: lp.AccountID.Hex(),
+		UserID:    lp.UserID.Hex(),
+		Channel:   lp.Channel,
+		Kind:      lp.Kind,
+		Endpoint:  lp.Endpoint,
+		P256dh:    lp.P256dh,
+		Auth:      lp.Auth,
+		Created:   lp.Created,
+	}
+}
+
+func (mg *Mongo) AddPushSubscription(dbName string, sub model.PushSubscription) (id string, err error) {
+	db := mg.Client.Database(dbName)
+
+	lp := toLocalPushSubscription(sub)
+	lp.ID = primitive.NewObjectID()
+
+	if _, err = db.Collection("sb_push").InsertOne(mg.Ctx, lp); err != nil {
+		return
+	}
+
+	id = lp.ID.Hex()
+	return
+}
+
+func (mg *Mongo) ListPushSubscriptions(dbName, channel string) ([]model.PushSubscription, error) {
+	db := mg.Client.Database(dbName)
+
+	cur, err := db.Collection("sb_push").Find(mg.Ctx, bson.M{"channel": channel})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.PushSubscription
+	for cur.Next(mg.Ctx) {
+		var lp LocalPushSubscription
+		if err := cur.Decode(&lp); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalPushSubscription(lp))
+	}
+
+	return results, cur.Err()
+}
+
+func (mg *Mongo) DeletePushSubscription(dbName, accountID, id string) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	acctID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid, FieldAccountID: acctID}
+	if _, err := db.Collection("sb_push").DeleteOne(mg.Ctx, filter); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestPushSubscriptions(t *testing.T) {
+	sub := model.PushSubscription{
+		AccountID: adminAccount.ID,
+		UserID:    adminToken.ID,
+		Channel:   "push-test",
+		Kind:      model.PushKindWebPush,
+		Endpoint:  "https://push.example.com/abc",
+		P256dh:    "key",
+		Auth:      "secret",
+		Created:   time.Now(),
+	}
+
+	id, err := datastore.AddPushSubscription(confDBName, sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subs, err := datastore.ListPushSubscriptions(confDBName, "push-test")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(subs) != 1 {
+		t.Fatalf("expected 1 subscription got %d", len(subs))
+	} else if subs[0].ID != id {
+		t.Errorf("expected id to be %s got %s", id, subs[0].ID)
+	} else if subs[0].Endpoint != sub.Endpoint {
+		t.Errorf("expected endpoint to be %s got %s", sub.Endpoint, subs[0].Endpoint)
+	}
+
+	if err := datastore.DeletePushSubscription(confDBName, adminAccount.ID, id); err != nil {
+		t.Fatal(err)
+	}
+
+	subs, err = datastore.ListPushSubscriptions(confDBName, "push-test")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(subs) != 0 {
+		t.Errorf("expected no subscription got %d", len(subs))
+	}
+}
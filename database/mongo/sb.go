@@ -1,11 +1,14 @@
 package mongo
 
 import (
+	"errors"
 	"time"
 
 	"github.com/staticbackendhq/core/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type LocalCustomer struct {
@@ -63,6 +66,11 @@ type LocalBase struct {
 	Whitelist        []string           `bson:"whitelist" json:"whitelist"`
 	IsActive         bool               `bson:"active" json:"-"`
 	MonthlyEmailSent int                `bson:"mes" json:"-"`
+	Settings         model.AppSettings  `bson:"settings" json:"-"`
+	SuspendedReason  string             `bson:"suspended" json:"-"`
+	Environment      string             `bson:"env" json:"-"`
+	ParentID         string             `bson:"parent" json:"-"`
+	Region           string             `bson:"region" json:"-"`
 }
 
 func toLocalBase(b model.DatabaseConfig) LocalBase {
@@ -77,6 +85,11 @@ func toLocalBase(b model.DatabaseConfig) LocalBase {
 		Whitelist:        b.AllowedDomain,
 		IsActive:         b.IsActive,
 		MonthlyEmailSent: b.MonthlySentEmail,
+		Settings:         b.Settings,
+		SuspendedReason:  b.SuspendedReason,
+		Environment:      b.Environment,
+		ParentID:         b.ParentID,
+		Region:           b.Region,
 	}
 }
 
@@ -88,6 +101,11 @@ func fromLocalBase(b LocalBase) model.DatabaseConfig {
 		AllowedDomain:    b.Whitelist,
 		IsActive:         b.IsActive,
 		MonthlySentEmail: b.MonthlyEmailSent,
+		Settings:         b.Settings,
+		SuspendedReason:  b.SuspendedReason,
+		Environment:      b.Environment,
+		ParentID:         b.ParentID,
+		Region:           b.Region,
 	}
 }
 
@@ -181,6 +199,45 @@ func (mg *Mongo) ListDatabases() (results []model.DatabaseConfig, err error) {
 	return
 }
 
+func (mg *Mongo) SuspendDatabase(baseID, reason string) error {
+	db := mg.Client.Database("sbsys")
+
+	id, err := primitive.ObjectIDFromHex(baseID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: id}
+	update := bson.M{"$set": bson.M{"suspended": reason}}
+	if _, err := db.Collection("bases").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) ListEnvironments(parentID string) (results []model.DatabaseConfig, err error) {
+	db := mg.Client.Database("sbsys")
+
+	opts := options.Find().SetSort(bson.M{FieldID: 1})
+	cur, err := db.Collection("bases").Find(mg.Ctx, bson.M{"parent": parentID}, opts)
+	if err != nil {
+		return
+	}
+	defer cur.Close(mg.Ctx)
+
+	for cur.Next(mg.Ctx) {
+		var lb LocalBase
+		if err = cur.Decode(&lb); err != nil {
+			return
+		}
+
+		results = append(results, fromLocalBase(lb))
+	}
+
+	err = cur.Err()
+	return
+}
+
 func (mg *Mongo) GetTenantByStripeID(stripeID string) (cus model.Tenant, err error) {
 	db := mg.Client.Database("sbsys")
 
@@ -227,6 +284,56 @@ func (mg *Mongo) IncrementMonthlyEmailSent(baseID string) error {
 	return nil
 }
 
+type LocalEmailUsage struct {
+	TenantID string `bson:"tenantId" json:"tenantId"`
+	Month    string `bson:"month" json:"month"`
+	Sent     int    `bson:"sent" json:"sent"`
+}
+
+func (mg *Mongo) IncrementEmailUsage(tenantID, month string) (int, error) {
+	db := mg.Client.Database("sbsys")
+
+	filter := bson.M{"tenantId": tenantID, "month": month}
+	update := bson.M{"$inc": bson.M{"sent": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var usage LocalEmailUsage
+	sr := db.Collection("email_usage").FindOneAndUpdate(mg.Ctx, filter, update, opts)
+	if err := sr.Decode(&usage); err != nil {
+		return 0, err
+	}
+	return usage.Sent, nil
+}
+
+func (mg *Mongo) GetEmailUsage(tenantID, month string) (int, error) {
+	db := mg.Client.Database("sbsys")
+
+	var usage LocalEmailUsage
+	sr := db.Collection("email_usage").FindOne(mg.Ctx, bson.M{"tenantId": tenantID, "month": month})
+	if err := sr.Decode(&usage); errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return usage.Sent, nil
+}
+
+func (mg *Mongo) UpdateDatabaseSettings(baseID string, settings model.AppSettings) error {
+	db := mg.Client.Database("sbsys")
+
+	id, err := primitive.ObjectIDFromHex(baseID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: id}
+	update := bson.M{"$set": bson.M{"settings": settings}}
+	if _, err := db.Collection("bases").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (mg *Mongo) ActivateTenant(tenantID string, active bool) error {
 	db := mg.Client.Database("sbsys")
 
@@ -266,6 +373,24 @@ func (mg *Mongo) ChangeTenantPlan(tenantID string, plan int) error {
 	return nil
 }
 
+func (mg *Mongo) SetTenantSubscription(tenantID, subscriptionID string) error {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(tenantID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid}
+	update := bson.M{"$set": bson.M{"subId": subscriptionID}}
+
+	res := db.Collection("accounts").FindOneAndUpdate(mg.Ctx, filter, update)
+	if err := res.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (mg *Mongo) EnableExternalLogin(tenantID string, config map[string]model.OAuthConfig) error {
 	b, err := model.EncryptExternalLogins(config)
 	if err != nil {
@@ -314,3 +439,25 @@ func (mg *Mongo) DeleteTenant(dbName, email string) error {
 
 	return nil
 }
+
+func (mg *Mongo) DeleteDatabase(baseID string) error {
+	base, err := mg.FindDatabase(baseID)
+	if err != nil {
+		return err
+	}
+
+	if err := mg.Client.Database(base.Name).Drop(mg.Ctx); err != nil {
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(baseID)
+	if err != nil {
+		return err
+	}
+
+	db := mg.Client.Database("sbsys")
+	if _, err := db.Collection("bases").DeleteOne(mg.Ctx, bson.M{FieldID: id}); err != nil {
+		return err
+	}
+	return nil
+}
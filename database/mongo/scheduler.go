@@ -16,11 +16,24 @@ type LocalTask struct {
 	Value    string             `bson:"value" json:"value"`
 	Meta     string             `bson:"meta" json:"meta"`
 	Interval string             `bson:"invertal" json:"interval"`
+	RunAt    time.Time          `bson:"runAt" json:"runAt"`
+	Retry    LocalTaskRetry     `bson:"retry" json:"retry"`
+	Disabled bool               `bson:"disabled" json:"disabled"`
+	After    string             `bson:"after" json:"after"`
 	LastRun  time.Time          `bson:"last" json:"last"`
+	Status   string             `bson:"status" json:"lastStatus"`
+	Error    string             `bson:"error" json:"lastError"`
 
 	BaseName string `bson:"-" json:"base"`
 }
 
+type LocalTaskRetry struct {
+	MaxAttempts int     `bson:"maxAttempts" json:"maxAttempts"`
+	Backoff     int     `bson:"backoff" json:"backoff"`
+	MaxBackoff  int     `bson:"maxBackoff" json:"maxBackoff"`
+	Jitter      float64 `bson:"jitter" json:"jitter"`
+}
+
 func toLocalTask(t model.Task) LocalTask {
 	id, err := primitive.ObjectIDFromHex(t.ID)
 	if err != nil {
@@ -34,20 +47,32 @@ func toLocalTask(t model.Task) LocalTask {
 		Value:    t.Value,
 		Meta:     t.Meta,
 		Interval: t.Interval,
+		RunAt:    t.RunAt,
+		Retry:    LocalTaskRetry(t.Retry),
+		Disabled: !t.Enabled,
+		After:    t.After,
 		LastRun:  t.LastRun,
+		Status:   t.LastStatus,
+		Error:    t.LastError,
 	}
 }
 
 func fromLocalTask(lt LocalTask) model.Task {
 	return model.Task{
-		ID:       lt.ID.Hex(),
-		Name:     lt.Name,
-		Type:     lt.Type,
-		Value:    lt.Value,
-		Meta:     lt.Meta,
-		Interval: lt.Interval,
-		LastRun:  lt.LastRun,
-		BaseName: lt.BaseName,
+		ID:         lt.ID.Hex(),
+		Name:       lt.Name,
+		Type:       lt.Type,
+		Value:      lt.Value,
+		Meta:       lt.Meta,
+		Interval:   lt.Interval,
+		RunAt:      lt.RunAt,
+		Retry:      model.TaskRetry(lt.Retry),
+		Enabled:    !lt.Disabled,
+		After:      lt.After,
+		LastRun:    lt.LastRun,
+		LastStatus: lt.Status,
+		LastError:  lt.Error,
+		BaseName:   lt.BaseName,
 	}
 }
 
@@ -68,7 +93,10 @@ func (mg *Mongo) ListTasks() ([]model.Task, error) {
 	for _, base := range bases {
 		tasks, err := mg.ListTasksByBase(base.Name)
 		if err != nil {
-			return nil, err
+			// one database's tasks failing to load should not stop the
+			// others from being scheduled
+			mg.log.Error().Err(err).Msgf("unable to list the tasks of %s", base.Name)
+			continue
 		}
 
 		results = append(results, tasks...)
@@ -105,6 +133,24 @@ func (mg *Mongo) ListTasksByBase(dbName string) ([]model.Task, error) {
 	return tasks, nil
 }
 
+func (mg *Mongo) GetTaskByID(dbName, id string) (model.Task, error) {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return model.Task{}, err
+	}
+
+	var t LocalTask
+	sr := db.Collection("sb_tasks").FindOne(mg.Ctx, bson.M{FieldID: oid})
+	if err := sr.Decode(&t); err != nil {
+		return model.Task{}, err
+	}
+
+	t.BaseName = dbName
+	return fromLocalTask(t), nil
+}
+
 func (mg *Mongo) AddTask(dbName string, task model.Task) (string, error) {
 	db := mg.Client.Database(dbName)
 
@@ -129,5 +175,67 @@ func (mg *Mongo) DeleteTask(dbName, id string) error {
 	if _, err := db.Collection("sb_tasks").DeleteOne(mg.Ctx, filter); err != nil {
 		return err
 	}
+
+	if _, err := db.Collection("sb_task_runs").DeleteMany(mg.Ctx, bson.M{"taskId": id}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) UpdateTaskRun(dbName, id string, lastRun time.Time, status, lastError string) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid}
+	update := bson.M{"$set": bson.M{"last": lastRun, "status": status, "error": lastError}}
+	if _, err := db.Collection("sb_tasks").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) UpdateTask(dbName, id string, task model.Task) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid}
+	update := bson.M{"$set": bson.M{
+		"name":     task.Name,
+		"type":     task.Type,
+		"value":    task.Value,
+		"meta":     task.Meta,
+		"invertal": task.Interval,
+		"runAt":    task.RunAt,
+		"retry":    LocalTaskRetry(task.Retry),
+		"disabled": !task.Enabled,
+		"after":    task.After,
+	}}
+	if _, err := db.Collection("sb_tasks").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) SetTaskEnabled(dbName, id string, enabled bool) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid}
+	update := bson.M{"$set": bson.M{"disabled": !enabled}}
+	if _, err := db.Collection("sb_tasks").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
 	return nil
 }
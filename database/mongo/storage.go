@@ -2,21 +2,31 @@ package mongo
 
 import (
 	"errors"
+	"regexp"
 	"time"
 
 	"github.com/staticbackendhq/core/model"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type LocalFile struct {
-	ID        primitive.ObjectID `bson:"_id" json:"id"`
-	AccountID primitive.ObjectID `bson:"accountId" json:"accountId"`
-	Key       string             `bson:"key" json:"key"`
-	URL       string             `bson:"url" json:"url"`
-	Size      int64              `bson:"size" json:"size"`
-	Uploaded  time.Time          `bson:"on" json:"uploaded"`
+	ID        primitive.ObjectID  `bson:"_id" json:"id"`
+	AccountID primitive.ObjectID  `bson:"accountId" json:"accountId"`
+	Key       string              `bson:"key" json:"key"`
+	URL       string              `bson:"url" json:"url"`
+	Size      int64               `bson:"size" json:"size"`
+	Uploaded  time.Time           `bson:"on" json:"uploaded"`
+	Variants  []model.FileVariant `bson:"variants,omitempty" json:"variants"`
+	Name      string              `bson:"name" json:"name"`
+	MimeType  string              `bson:"mimeType" json:"mimeType"`
+	Tags      []string            `bson:"tags" json:"tags"`
+
+	Collection string `bson:"col,omitempty" json:"collection"`
+	DocumentID string `bson:"docId,omitempty" json:"documentId"`
+	UserID     string `bson:"userId,omitempty" json:"userId"`
 }
 
 func toLocalFile(f model.File) LocalFile {
@@ -37,6 +47,14 @@ func toLocalFile(f model.File) LocalFile {
 		URL:       f.URL,
 		Size:      f.Size,
 		Uploaded:  f.Uploaded,
+		Variants:  f.Variants,
+		Name:      f.Name,
+		MimeType:  f.MimeType,
+		Tags:      f.Tags,
+
+		Collection: f.Collection,
+		DocumentID: f.DocumentID,
+		UserID:     f.UserID,
 	}
 }
 
@@ -48,6 +66,15 @@ func fromLocalFile(lf LocalFile) model.File {
 		URL:       lf.URL,
 		Size:      lf.Size,
 		Uploaded:  lf.Uploaded,
+		Variants:  lf.Variants,
+		Name:      lf.Name,
+		MimeType:  lf.MimeType,
+		Tags:      lf.Tags,
+		FileLink: model.FileLink{
+			Collection: lf.Collection,
+			DocumentID: lf.DocumentID,
+			UserID:     lf.UserID,
+		},
 	}
 }
 
@@ -148,3 +175,112 @@ func (mg *Mongo) ListAllFiles(dbName, accountID string) ([]model.File, error) {
 
 	return results, nil
 }
+
+func (mg *Mongo) ListFiles(dbName string, f model.FileFilter) ([]model.File, error) {
+	db := mg.Client.Database(dbName)
+
+	filter := bson.M{}
+	if len(f.AccountID) > 0 {
+		aid, err := primitive.ObjectIDFromHex(f.AccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		filter[FieldAccountID] = aid
+	}
+	if len(f.Name) > 0 {
+		filter["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(f.Name), Options: "i"}
+	}
+	if len(f.MimeType) > 0 {
+		filter["mimeType"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(f.MimeType)}
+	}
+	if len(f.Tag) > 0 {
+		filter["tags"] = f.Tag
+	}
+	if len(f.Collection) > 0 {
+		filter["col"] = f.Collection
+	}
+	if len(f.DocumentID) > 0 {
+		filter["docId"] = f.DocumentID
+	}
+	if len(f.UserID) > 0 {
+		filter["userId"] = f.UserID
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"on": -1})
+	if f.Limit > 0 {
+		opts.SetLimit(f.Limit)
+		opts.SetSkip(f.Offset)
+	}
+
+	cur, err := db.Collection("sb_files").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.File
+	for cur.Next(mg.Ctx) {
+		var lf LocalFile
+		if err := cur.Decode(&lf); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalFile(lf))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (mg *Mongo) LinkFile(dbName, fileID string, link model.FileLink) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(fileID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid}
+	update := bson.M{"$set": bson.M{
+		"col":    link.Collection,
+		"docId":  link.DocumentID,
+		"userId": link.UserID,
+	}}
+	if _, err := db.Collection("sb_files").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mg *Mongo) StorageUsage(dbName string) (usage model.StorageUsage, err error) {
+	db := mg.Client.Database(dbName)
+
+	opts := options.Find().SetProjection(bson.M{"size": 1, "variants": 1})
+
+	cur, err := db.Collection("sb_files").Find(mg.Ctx, bson.M{}, opts)
+	if err != nil {
+		return
+	}
+	defer cur.Close(mg.Ctx)
+
+	for cur.Next(mg.Ctx) {
+		var lf LocalFile
+		if err = cur.Decode(&lf); err != nil {
+			return
+		}
+
+		usage.Files++
+		usage.Bytes += lf.Size
+
+		for _, v := range lf.Variants {
+			usage.Bytes += v.Size
+		}
+	}
+
+	err = cur.Err()
+	return
+}
@@ -0,0 +1,81 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalTaskRun struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	TaskID    string             `bson:"taskId" json:"taskId"`
+	Attempt   int                `bson:"attempt" json:"attempt"`
+	Started   time.Time          `bson:"started" json:"started"`
+	Completed time.Time          `bson:"completed" json:"completed"`
+	Success   bool               `bson:"success" json:"success"`
+	Output    string             `bson:"output" json:"output"`
+	Error     string             `bson:"error" json:"error"`
+}
+
+func (mg *Mongo) AddTaskRun(dbName string, run model.TaskRun) (string, error) {
+	db := mg.Client.Database(dbName)
+
+	lr := LocalTaskRun{
+		ID:        primitive.NewObjectID(),
+		TaskID:    run.TaskID,
+		Attempt:   run.Attempt,
+		Started:   run.Started,
+		Completed: run.Completed,
+		Success:   run.Success,
+		Output:    run.Output,
+		Error:     run.Error,
+	}
+
+	if _, err := db.Collection("sb_task_runs").InsertOne(mg.Ctx, lr); err != nil {
+		return "", err
+	}
+	return lr.ID.Hex(), nil
+}
+
+func (mg *Mongo) ListTaskRuns(dbName, taskID string, limit int64) ([]model.TaskRun, error) {
+	db := mg.Client.Database(dbName)
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"started": -1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cur, err := db.Collection("sb_task_runs").Find(mg.Ctx, bson.M{"taskId": taskID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.TaskRun
+	for cur.Next(mg.Ctx) {
+		var lr LocalTaskRun
+		if err := cur.Decode(&lr); err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.TaskRun{
+			ID:        lr.ID.Hex(),
+			TaskID:    lr.TaskID,
+			Attempt:   lr.Attempt,
+			Started:   lr.Started,
+			Completed: lr.Completed,
+			Success:   lr.Success,
+			Output:    lr.Output,
+			Error:     lr.Error,
+		})
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,144 @@
+package mongo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalOwnershipTransfer struct {
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	BaseID       string             `bson:"baseId" json:"baseId"`
+	FromTenantID string             `bson:"fromTenantId" json:"fromTenantId"`
+	ToTenantID   string             `bson:"toTenantId" json:"toTenantId"`
+	ToEmail      string             `bson:"toEmail" json:"toEmail"`
+	WholeAccount bool               `bson:"wholeAccount" json:"wholeAccount"`
+	Status       string             `bson:"status" json:"status"`
+	Created      time.Time          `bson:"created" json:"created"`
+	Expires      time.Time          `bson:"expires" json:"expires"`
+	Completed    time.Time          `bson:"completed" json:"completed"`
+}
+
+func fromLocalOwnershipTransfer(lt LocalOwnershipTransfer) model.OwnershipTransfer {
+	return model.OwnershipTransfer{
+		ID:           lt.ID.Hex(),
+		BaseID:       lt.BaseID,
+		FromTenantID: lt.FromTenantID,
+		ToTenantID:   lt.ToTenantID,
+		ToEmail:      lt.ToEmail,
+		WholeAccount: lt.WholeAccount,
+		Status:       lt.Status,
+		Created:      lt.Created,
+		Expires:      lt.Expires,
+		Completed:    lt.Completed,
+	}
+}
+
+func (mg *Mongo) AddOwnershipTransfer(t model.OwnershipTransfer) (id string, err error) {
+	db := mg.Client.Database("sbsys")
+
+	lt := LocalOwnershipTransfer{
+		ID:           primitive.NewObjectID(),
+		BaseID:       t.BaseID,
+		FromTenantID: t.FromTenantID,
+		ToTenantID:   t.ToTenantID,
+		ToEmail:      t.ToEmail,
+		WholeAccount: t.WholeAccount,
+		Status:       model.TransferPending,
+		Created:      t.Created,
+		Expires:      t.Expires,
+	}
+
+	if _, err = db.Collection("ownership_transfers").InsertOne(mg.Ctx, lt); err != nil {
+		return
+	}
+
+	id = lt.ID.Hex()
+	return
+}
+
+func (mg *Mongo) GetOwnershipTransfer(id string) (model.OwnershipTransfer, error) {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return model.OwnershipTransfer{}, err
+	}
+
+	var lt LocalOwnershipTransfer
+	sr := db.Collection("ownership_transfers").FindOne(mg.Ctx, bson.M{FieldID: oid})
+	if err := sr.Decode(&lt); err != nil {
+		return model.OwnershipTransfer{}, err
+	}
+	return fromLocalOwnershipTransfer(lt), nil
+}
+
+func (mg *Mongo) ListOwnershipTransfers(tenantID string) ([]model.OwnershipTransfer, error) {
+	db := mg.Client.Database("sbsys")
+
+	filter := bson.M{"$or": []bson.M{{"fromTenantId": tenantID}, {"toTenantId": tenantID}}}
+
+	opts := options.Find().SetSort(bson.M{"created": -1})
+	cur, err := db.Collection("ownership_transfers").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.OwnershipTransfer
+	for cur.Next(mg.Ctx) {
+		var lt LocalOwnershipTransfer
+		if err := cur.Decode(&lt); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalOwnershipTransfer(lt))
+	}
+
+	return results, cur.Err()
+}
+
+func (mg *Mongo) CompleteOwnershipTransfer(id, status string, completed time.Time) error {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: oid, "status": model.TransferPending}
+	update := bson.M{"$set": bson.M{"status": status, "completed": completed}}
+
+	res, err := db.Collection("ownership_transfers").UpdateOne(mg.Ctx, filter, update)
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return errors.New("the transfer is not pending")
+	}
+	return nil
+}
+
+func (mg *Mongo) SetDatabaseTenant(baseID, tenantID string) error {
+	db := mg.Client.Database("sbsys")
+
+	id, err := primitive.ObjectIDFromHex(baseID)
+	if err != nil {
+		return err
+	}
+
+	tid, err := primitive.ObjectIDFromHex(tenantID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{FieldID: id}
+	update := bson.M{"$set": bson.M{"accountId": tid}}
+	if _, err := db.Collection("bases").UpdateOne(mg.Ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
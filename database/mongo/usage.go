@@ -0,0 +1,74 @@
+package mongo
+
+import (
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalUsageRecord struct {
+	TenantID string `bson:"tenantId" json:"tenantId"`
+	DBName   string `bson:"dbName" json:"dbName"`
+	Day      string `bson:"day" json:"day"`
+	Metric   string `bson:"metric" json:"metric"`
+	Value    int64  `bson:"value" json:"value"`
+}
+
+func (mg *Mongo) AddUsage(records []model.UsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	db := mg.Client.Database("sbsys")
+
+	var updates []mongo.WriteModel
+	for _, u := range records {
+		filter := bson.M{"tenantId": u.TenantID, "dbName": u.DBName, "day": u.Day, "metric": u.Metric}
+		update := bson.M{"$inc": bson.M{"value": u.Value}}
+
+		updates = append(updates, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	_, err := db.Collection("usage").BulkWrite(mg.Ctx, updates)
+	return err
+}
+
+func (mg *Mongo) ListUsage(filter model.UsageFilter) ([]model.UsageRecord, error) {
+	db := mg.Client.Database("sbsys")
+
+	f := bson.M{"tenantId": filter.TenantID}
+	if len(filter.DBName) > 0 {
+		f["dbName"] = filter.DBName
+	}
+
+	day := bson.M{}
+	if len(filter.Since) > 0 {
+		day["$gte"] = filter.Since
+	}
+	if len(filter.Until) > 0 {
+		day["$lte"] = filter.Until
+	}
+	if len(day) > 0 {
+		f["day"] = day
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "day", Value: 1}, {Key: "dbName", Value: 1}, {Key: "metric", Value: 1}})
+	cur, err := db.Collection("usage").Find(mg.Ctx, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.UsageRecord
+	for cur.Next(mg.Ctx) {
+		var lu LocalUsageRecord
+		if err := cur.Decode(&lu); err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.UsageRecord(lu))
+	}
+
+	return results, cur.Err()
+}
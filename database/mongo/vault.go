@@ -0,0 +1,127 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalSecret struct {
+	ID      primitive.ObjectID `bson:"_id" json:"id"`
+	DBName  string             `bson:"dbName" json:"dbName"`
+	Name    string             `bson:"name" json:"name"`
+	Version int                `bson:"version" json:"version"`
+	Value   []byte             `bson:"value" json:"-"`
+	DataKey []byte             `bson:"dataKey" json:"-"`
+	KeyID   string             `bson:"keyId" json:"keyId"`
+	Created time.Time          `bson:"created" json:"created"`
+}
+
+func fromLocalSecret(ls LocalSecret) model.Secret {
+	return model.Secret{
+		ID:      ls.ID.Hex(),
+		DBName:  ls.DBName,
+		Name:    ls.Name,
+		Version: ls.Version,
+		Value:   ls.Value,
+		DataKey: ls.DataKey,
+		KeyID:   ls.KeyID,
+		Created: ls.Created,
+	}
+}
+
+func (mg *Mongo) AddSecret(s model.Secret) (id string, err error) {
+	db := mg.Client.Database("sbsys")
+
+	ls := LocalSecret{
+		ID:      primitive.NewObjectID(),
+		DBName:  s.DBName,
+		Name:    s.Name,
+		Version: s.Version,
+		Value:   s.Value,
+		DataKey: s.DataKey,
+		KeyID:   s.KeyID,
+		Created: s.Created,
+	}
+
+	if _, err = db.Collection("secrets").InsertOne(mg.Ctx, ls); err != nil {
+		return
+	}
+
+	id = ls.ID.Hex()
+	return
+}
+
+func (mg *Mongo) ListSecrets(dbName string) ([]model.Secret, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "version", Value: -1}})
+	return mg.findSecrets(bson.M{"dbName": dbName}, opts)
+}
+
+func (mg *Mongo) GetSecret(dbName, name string, version int) (s model.Secret, err error) {
+	db := mg.Client.Database("sbsys")
+
+	filter := bson.M{"dbName": dbName, "name": name}
+	if version > 0 {
+		filter["version"] = version
+	}
+
+	opts := options.FindOne().SetSort(bson.M{"version": -1})
+
+	var ls LocalSecret
+	sr := db.Collection("secrets").FindOne(mg.Ctx, filter, opts)
+	if err = sr.Decode(&ls); err != nil {
+		return
+	}
+
+	s = fromLocalSecret(ls)
+	return
+}
+
+func (mg *Mongo) UpdateSecret(s model.Secret) error {
+	db := mg.Client.Database("sbsys")
+
+	oid, err := primitive.ObjectIDFromHex(s.ID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"value": s.Value, "dataKey": s.DataKey, "keyId": s.KeyID}}
+	_, err = db.Collection("secrets").UpdateOne(mg.Ctx, bson.M{FieldID: oid}, update)
+	return err
+}
+
+func (mg *Mongo) DeleteSecret(dbName, name string) error {
+	db := mg.Client.Database("sbsys")
+
+	_, err := db.Collection("secrets").DeleteMany(mg.Ctx, bson.M{"dbName": dbName, "name": name})
+	return err
+}
+
+func (mg *Mongo) ListSecretsToRewrap(keyID string, limit int64) ([]model.Secret, error) {
+	opts := options.Find().SetLimit(limit)
+	return mg.findSecrets(bson.M{"keyId": bson.M{"$ne": keyID}}, opts)
+}
+
+func (mg *Mongo) findSecrets(filter bson.M, opts *options.FindOptions) ([]model.Secret, error) {
+	db := mg.Client.Database("sbsys")
+
+	cur, err := db.Collection("secrets").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.Secret
+	for cur.Next(mg.Ctx) {
+		var ls LocalSecret
+		if err := cur.Decode(&ls); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalSecret(ls))
+	}
+	return results, cur.Err()
+}
@@ -0,0 +1,153 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LocalWebhookDelivery struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	URL         string             `bson:"url" json:"url"`
+	Event       string             `bson:"event" json:"event"`
+	Payload     string             `bson:"payload" json:"payload"`
+	Status      string             `bson:"status" json:"status"`
+	Attempts    int                `bson:"attempts" json:"attempts"`
+	StatusCode  int                `bson:"statusCode" json:"statusCode"`
+	LastError   string             `bson:"lastError" json:"lastError"`
+	NextAttempt time.Time          `bson:"nextAttempt" json:"nextAttempt"`
+	Created     time.Time          `bson:"created" json:"created"`
+	Updated     time.Time          `bson:"updated" json:"updated"`
+}
+
+func fromLocalWebhookDelivery(ld LocalWebhookDelivery) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:          ld.ID.Hex(),
+		URL:         ld.URL,
+		Event:       ld.Event,
+		Payload:     ld.Payload,
+		Status:      ld.Status,
+		Attempts:    ld.Attempts,
+		StatusCode:  ld.StatusCode,
+		LastError:   ld.LastError,
+		NextAttempt: ld.NextAttempt,
+		Created:     ld.Created,
+		Updated:     ld.Updated,
+	}
+}
+
+func (mg *Mongo) QueueWebhook(dbName string, d model.WebhookDelivery) (string, error) {
+	db := mg.Client.Database(dbName)
+
+	ld := LocalWebhookDelivery{
+		ID:          primitive.NewObjectID(),
+		URL:         d.URL,
+		Event:       d.Event,
+		Payload:     d.Payload,
+		Status:      d.Status,
+		Attempts:    d.Attempts,
+		StatusCode:  d.StatusCode,
+		LastError:   d.LastError,
+		NextAttempt: d.NextAttempt,
+		Created:     d.Created,
+		Updated:     d.Updated,
+	}
+
+	if _, err := db.Collection("sb_webhook_deliveries").InsertOne(mg.Ctx, ld); err != nil {
+		return "", err
+	}
+	return ld.ID.Hex(), nil
+}
+
+func (mg *Mongo) GetWebhookDelivery(dbName, id string) (model.WebhookDelivery, error) {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	var ld LocalWebhookDelivery
+	sr := db.Collection("sb_webhook_deliveries").FindOne(mg.Ctx, bson.M{FieldID: oid})
+	if err := sr.Decode(&ld); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return fromLocalWebhookDelivery(ld), nil
+}
+
+func (mg *Mongo) ListDueWebhooks(dbName string, now time.Time, limit int64) ([]model.WebhookDelivery, error) {
+	opts := options.Find()
+	opts.SetSort(bson.M{"nextAttempt": 1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	filter := bson.M{"status": model.WebhookStatusPending, "nextAttempt": bson.M{"$lte": now}}
+	return mg.findWebhookDeliveries(dbName, filter, opts)
+}
+
+func (mg *Mongo) UpdateWebhookStatus(dbName, id, status string, attempts, statusCode int, lastError string, nextAttempt time.Time) error {
+	db := mg.Client.Database(dbName)
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"attempts":    attempts,
+		"statusCode":  statusCode,
+		"lastError":   lastError,
+		"nextAttempt": nextAttempt,
+		"updated":     time.Now(),
+	}}
+	_, err = db.Collection("sb_webhook_deliveries").UpdateOne(mg.Ctx, bson.M{FieldID: oid}, update)
+	return err
+}
+
+func (mg *Mongo) ListWebhookDeliveries(dbName string, f model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	filter := bson.M{}
+	if len(f.URL) > 0 {
+		filter["url"] = f.URL
+	}
+	if len(f.Event) > 0 {
+		filter["event"] = f.Event
+	}
+	if len(f.Status) > 0 {
+		filter["status"] = f.Status
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"created": -1})
+	if f.Limit > 0 {
+		opts.SetLimit(f.Limit)
+	}
+
+	return mg.findWebhookDeliveries(dbName, filter, opts)
+}
+
+func (mg *Mongo) findWebhookDeliveries(dbName string, filter bson.M, opts *options.FindOptions) ([]model.WebhookDelivery, error) {
+	db := mg.Client.Database(dbName)
+
+	cur, err := db.Collection("sb_webhook_deliveries").Find(mg.Ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mg.Ctx)
+
+	var results []model.WebhookDelivery
+	for cur.Next(mg.Ctx) {
+		var ld LocalWebhookDelivery
+		if err := cur.Decode(&ld); err != nil {
+			return nil, err
+		}
+
+		results = append(results, fromLocalWebhookDelivery(ld))
+	}
+
+	return results, cur.Err()
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"github.com/staticbackendhq/core/model"
 )
 
@@ -8,6 +10,7 @@ const (
 	DataStorePostgreSQL = "postgresql"
 	DataStoreMongoDB    = "mongo"
 	DataStoreMemory     = "memory"
+	DataStoreSQLite     = "sqlite"
 )
 
 // Persister used for anything that persists to the database
@@ -34,6 +37,24 @@ type Persister interface {
 	ListDatabases() ([]model.DatabaseConfig, error)
 	// IncrementMonthlyEmailSent increments the monthly email sending counter
 	IncrementMonthlyEmailSent(baseID string) error
+	// IncrementEmailUsage increments the emails sent by a tenant in a month,
+	// formatted as 2006-01, and returns the new count
+	IncrementEmailUsage(tenantID, month string) (int, error)
+	// GetEmailUsage returns the emails sent by a tenant in a month
+	GetEmailUsage(tenantID, month string) (int, error)
+	// AddUsage adds the values of the usage records to the stored ones of
+	// the same tenant, database, day and metric
+	AddUsage(records []model.UsageRecord) error
+	// ListUsage returns the usage records matching the filter ordered by day
+	ListUsage(filter model.UsageFilter) ([]model.UsageRecord, error)
+	// UpdateDatabaseSettings saves the configurable settings of a database
+	UpdateDatabaseSettings(baseID string, settings model.AppSettings) error
+	// SuspendDatabase suspends a database for a reason, an empty reason
+	// reactivates it
+	SuspendDatabase(baseID, reason string) error
+	// ListEnvironments returns the environment databases linked to an
+	// app's production database
+	ListEnvironments(parentID string) ([]model.DatabaseConfig, error)
 	// GetTenantByEmail finds a tenant by its main account email
 	GetTenantByEmail(email string) (cus model.Tenant, err error)
 	// GetTenantByStripeID finds a tenant by its Stripe customer ID
@@ -42,6 +63,8 @@ type Persister interface {
 	ActivateTenant(tenantID string, active bool) error
 	// ChangeTenantPlan updates the subscription plan
 	ChangeTenantPlan(tenantID string, plan int) error
+	// SetTenantSubscription updates the Stripe subscription of the tenant
+	SetTenantSubscription(tenantID, subscriptionID string) error
 	// EnableExternalLogin adds or creates a new config for an external login provider
 	EnableExternalLogin(tenantID string, config map[string]model.OAuthConfig) error
 	// NewID generates a unique identifier that can be used in your model
@@ -49,6 +72,99 @@ type Persister interface {
 	// DeleteTenant removes the database and tenant
 	// note: this does not remove all the tenant's data
 	DeleteTenant(dbName, email string) error
+	// DeleteDatabase drops a database with all its data and removes it
+	// from the system, its tenant is kept
+	DeleteDatabase(baseID string) error
+
+	// app deletions
+	// AddAppDeletion schedules the deletion of a database
+	AddAppDeletion(d model.AppDeletion) (id string, err error)
+	// GetAppDeletion returns the pending deletion of a database, without
+	// error and an empty ID when none is pending
+	GetAppDeletion(baseID string) (model.AppDeletion, error)
+	// ListDueAppDeletions returns the pending deletions scheduled before now
+	ListDueAppDeletions(now time.Time) ([]model.AppDeletion, error)
+	// CompleteAppDeletion records the purge of a database with its signed
+	// certificate
+	CompleteAppDeletion(id string, completed time.Time, certificate string) error
+	// CancelAppDeletion removes a pending deletion
+	CancelAppDeletion(id string) error
+
+	// ownership transfers
+	// AddOwnershipTransfer creates a pending transfer of a database
+	AddOwnershipTransfer(t model.OwnershipTransfer) (id string, err error)
+	// GetOwnershipTransfer returns a transfer by its ID
+	GetOwnershipTransfer(id string) (model.OwnershipTransfer, error)
+	// ListOwnershipTransfers returns the transfers from or to a tenant,
+	// newest first
+	ListOwnershipTransfers(tenantID string) ([]model.OwnershipTransfer, error)
+	// CompleteOwnershipTransfer sets the status of a pending transfer, it
+	// returns an error when the transfer is not pending anymore
+	CompleteOwnershipTransfer(id, status string, completed time.Time) error
+	// SetDatabaseTenant changes the tenant owning a database
+	SetDatabaseTenant(baseID, tenantID string) error
+
+	// backups
+	// AddBackup records a backup saved to the object storage
+	AddBackup(b model.Backup) (id string, err error)
+	// ListBackups returns the backups of a database, newest first
+	ListBackups(dbName string) ([]model.Backup, error)
+	// GetBackup returns a backup by its ID
+	GetBackup(id string) (model.Backup, error)
+	// DeleteBackup removes the record of a backup
+	DeleteBackup(id string) error
+
+	// vault
+	// AddSecret adds a version of a secret
+	AddSecret(s model.Secret) (id string, err error)
+	// ListSecrets returns the versions of the secrets of a database ordered
+	// by name, newest version first
+	ListSecrets(dbName string) ([]model.Secret, error)
+	// GetSecret returns a version of a secret, the latest one when version
+	// is 0
+	GetSecret(dbName, name string, version int) (model.Secret, error)
+	// UpdateSecret replaces the encrypted value and data key of a version
+	UpdateSecret(s model.Secret) error
+	// DeleteSecret removes all the versions of a secret
+	DeleteSecret(dbName, name string) error
+	// ListSecretsToRewrap returns up to limit versions whose data key is
+	// wrapped by another master key than keyID
+	ListSecretsToRewrap(keyID string, limit int64) ([]model.Secret, error)
+
+	// request analytics
+	// AddRequestRollups adds the counts and durations of the rollups to the
+	// stored ones
+	AddRequestRollups(records []model.RequestRollup) error
+	// ListRequestRollups returns the request rollups of a database ordered
+	// by hour
+	ListRequestRollups(filter model.RequestRollupFilter) ([]model.RequestRollup, error)
+
+	// custom domains
+	// AddDomain adds a custom domain to a database
+	AddDomain(d model.Domain) (id string, err error)
+	// ListDomains returns the custom domains of a database
+	ListDomains(dbName string) ([]model.Domain, error)
+	// FindDomain returns a custom domain by its hostname
+	FindDomain(hostname string) (model.Domain, error)
+	// VerifyDomain marks a custom domain as verified
+	VerifyDomain(id string) error
+	// DeleteDomain removes a custom domain of a database
+	DeleteDomain(dbName, hostname string) error
+
+	// feature flags
+	// SetFeatureFlag enables or disables a flag for a tenant
+	SetFeatureFlag(flag model.FeatureFlag) error
+	// ListFeatureFlags returns the flags set for a tenant
+	ListFeatureFlags(tenantID string) ([]model.FeatureFlag, error)
+	// DeleteFeatureFlag removes a flag of a tenant, its default applies
+	DeleteFeatureFlag(tenantID, name string) error
+
+	// platform audit log, it's append-only
+	// AddAdminEvent records a privileged operation
+	AddAdminEvent(evt model.AdminEvent) error
+	// ListAdminEvents returns the most recent privileged operations
+	// matching the filter
+	ListAdminEvents(filter model.AdminEventFilter) ([]model.AdminEvent, error)
 
 	// system user account functions
 	// GetUserByID returns a User matching the accountID and userID
@@ -83,8 +199,33 @@ type Persister interface {
 	SetUserRole(dbName, email string, role int) error
 	// UserSetPassword user initiated password reset
 	UserSetPassword(dbName, userID, password string) error
+	// ResetUserCredentials replaces the email, hashed password and token of
+	// a user
+	ResetUserCredentials(dbName, userID, email, password, token string) error
 	// RemoveUser permanently removes a user from an account
 	RemoveUser(auth model.Auth, dbName, userID string) error
+	// PurgeUser removes a user, every document they own and the form
+	// submissions made with their email. The account is also removed when
+	// it has no more users.
+	PurgeUser(dbName string, tok model.User) (model.DeletionReport, error)
+
+	// account invitations
+	// AddInvite creates a pending invitation to join an account
+	AddInvite(dbName string, inv model.Invite) (id string, err error)
+	// GetInvite returns an invitation by its ID
+	GetInvite(dbName, id string) (model.Invite, error)
+	// ListInvites returns the pending invitations of an account
+	ListInvites(dbName, accountID string) ([]model.Invite, error)
+	// DeleteInvite removes an invitation, once accepted or revoked
+	DeleteInvite(dbName, id string) error
+
+	// push notifications
+	// AddPushSubscription registers a device for a channel's push notifications
+	AddPushSubscription(dbName string, sub model.PushSubscription) (id string, err error)
+	// ListPushSubscriptions returns the subscriptions of a channel
+	ListPushSubscriptions(dbName, channel string) ([]model.PushSubscription, error)
+	// DeletePushSubscription removes a subscription of an account
+	DeletePushSubscription(dbName, accountID, id string) error
 
 	// base CRUD
 	// CreateDocument creates a record in a collection
@@ -118,8 +259,97 @@ type Persister interface {
 	AddFormSubmission(dbName, form string, doc map[string]interface{}) error
 	// ListFormSubmissions lists all submissions for a form
 	ListFormSubmissions(dbName, name string) ([]map[string]interface{}, error)
+	// QueryFormSubmissions returns a page of the submissions of a form
+	// matching the filter, sorted by creation date. The submissions include
+	// their id and created fields.
+	QueryFormSubmissions(dbName, name string, f model.FormFilter, params model.ListParams) (model.PagedResult, error)
+	// DeleteFormSubmission removes a submission of a form
+	DeleteFormSubmission(dbName, name, id string) error
+	// MarkFormSubmissionRead sets the sb_read flag of a submission
+	MarkFormSubmissionRead(dbName, name, id string, read bool) error
 	// GetForms returns all forms
 	GetForms(dbName string) ([]string, error)
+	// EachFormSubmission calls fn with every submission of a form, oldest
+	// first, created between since and until (zero times are unbounded).
+	// The submissions include their id and created fields.
+	EachFormSubmission(dbName, name string, since, until time.Time, fn func(doc map[string]interface{}) error) error
+	// AddFormDelivery records a form webhook delivery attempt
+	AddFormDelivery(dbName string, d model.FormDelivery) error
+	// ListFormDeliveries returns the most recent webhook delivery attempts
+	// of a form
+	ListFormDeliveries(dbName, form string, limit int64) ([]model.FormDelivery, error)
+	// SaveFormDefinition adds or replaces the field definitions of a form
+	SaveFormDefinition(dbName string, def model.FormDefinition) error
+	// GetFormDefinition returns the field definitions of a form, without
+	// fields if the form has no definition
+	GetFormDefinition(dbName, form string) (model.FormDefinition, error)
+	// DeleteFormDefinition removes the field definitions of a form
+	DeleteFormDefinition(dbName, form string) error
+	// ListFormDefinitions returns the forms' definitions ordered by name
+	ListFormDefinitions(dbName string) ([]model.FormDefinition, error)
+
+	// email templates
+	// ListEmailTemplates returns the email templates ordered by name
+	ListEmailTemplates(dbName string) ([]model.EmailTemplate, error)
+	// GetEmailTemplate returns an email template by its name
+	GetEmailTemplate(dbName, name string) (model.EmailTemplate, error)
+	// SaveEmailTemplate adds or replaces an email template by its name
+	SaveEmailTemplate(dbName string, tmpl model.EmailTemplate) error
+	// DeleteEmailTemplate removes an email template
+	DeleteEmailTemplate(dbName, name string) error
+	// GetUserLocale returns the locale of a user's emails, empty when the
+	// user has none
+	GetUserLocale(dbName, userID string) (string, error)
+	// SetUserLocale sets the locale of a user's emails, an empty locale
+	// removes it
+	SetUserLocale(dbName, userID, locale string) error
+
+	// email queue
+	// QueueEmail inserts an email to be sent asynchronously and returns its id
+	QueueEmail(dbName string, msg model.EmailMessage) (string, error)
+	// GetEmailMessage returns a queued email and its delivery status
+	GetEmailMessage(dbName, id string) (model.EmailMessage, error)
+	// ListDueEmails returns the queued emails to send at or before now, the
+	// oldest first
+	ListDueEmails(dbName string, now time.Time, limit int64) ([]model.EmailMessage, error)
+	// UpdateEmailStatus records the outcome of a queued email's delivery
+	// and the provider's message ID of a sent email
+	UpdateEmailStatus(dbName, id, status string, attempts int, lastError, providerID string, nextAttempt time.Time) error
+	// ListEmailLog returns the most recent emails matching the filter
+	ListEmailLog(dbName string, filter model.EmailLogFilter) ([]model.EmailMessage, error)
+	// CountEmails returns the number of emails matching the filter, its
+	// limit is ignored
+	CountEmails(dbName string, filter model.EmailLogFilter) (int64, error)
+
+	// email bounces and complaints
+	// AddEmailEvent records a bounce or a complaint
+	AddEmailEvent(dbName string, e model.EmailEvent) error
+	// ListEmailEvents returns the most recent events of an address, of all
+	// addresses when email is empty
+	ListEmailEvents(dbName, email string, limit int64) ([]model.EmailEvent, error)
+	// ListEmailSuppressions returns the suppressed addresses
+	ListEmailSuppressions(dbName string) ([]model.EmailSuppression, error)
+	// IsEmailSuppressed returns whether an address is suppressed
+	IsEmailSuppressed(dbName, email string) (bool, error)
+	// SuppressEmail adds an address to the suppression list, an address
+	// already suppressed keeps its reason
+	SuppressEmail(dbName string, s model.EmailSuppression) error
+	// RemoveEmailSuppression allows sending to an address again
+	RemoveEmailSuppression(dbName, email string) error
+
+	// webhook deliveries
+	// QueueWebhook inserts a webhook delivery and returns its id
+	QueueWebhook(dbName string, d model.WebhookDelivery) (string, error)
+	// GetWebhookDelivery returns a webhook delivery and its status
+	GetWebhookDelivery(dbName, id string) (model.WebhookDelivery, error)
+	// ListDueWebhooks returns the pending deliveries to send at or before
+	// now, the oldest first
+	ListDueWebhooks(dbName string, now time.Time, limit int64) ([]model.WebhookDelivery, error)
+	// UpdateWebhookStatus records the outcome of a delivery attempt
+	UpdateWebhookStatus(dbName, id, status string, attempts, statusCode int, lastError string, nextAttempt time.Time) error
+	// ListWebhookDeliveries returns the most recent deliveries matching the
+	// filter
+	ListWebhookDeliveries(dbName string, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error)
 
 	// Function functions
 	// AddFunction creates a server-side function
@@ -140,16 +370,34 @@ type Persister interface {
 	DeleteFunction(dbName, name string) error
 	// RanFunction records a function execution and its output
 	RanFunction(dbName, id string, rh model.ExecHistory) error
+	// CountFunctionRuns returns the number of function executions started
+	// at or after since and before until
+	CountFunctionRuns(dbName string, since, until time.Time) (int64, error)
 
 	// schedule tasks
-	// ListTasks eturns the list of all tasks across all database
+	// ListTasks returns the list of all tasks across all database, the
+	// databases whose tasks cannot be read are skipped
 	ListTasks() ([]model.Task, error)
 	// ListTasksByBase returns the tasks for a specific database
 	ListTasksByBase(dbName string) ([]model.Task, error)
+	// GetTaskByID returns a task
+	GetTaskByID(dbName, id string) (model.Task, error)
 	// AddTask inserts a new task in the reserved sb_tasks collection
 	AddTask(string, model.Task) (string, error)
+	// UpdateTask replaces the definition of a task, the outcome of its last
+	// run is kept
+	UpdateTask(dbName, id string, task model.Task) error
 	// DeleteTask removes a task from the reserved sb_tasks collection
 	DeleteTask(dbName, id string) error
+	// UpdateTaskRun records the time and outcome of a task's last run
+	UpdateTaskRun(dbName, id string, lastRun time.Time, status, lastError string) error
+	// SetTaskEnabled enables or disables (pauses) a task
+	SetTaskEnabled(dbName, id string, enabled bool) error
+	// AddTaskRun records an attempt of a task's run and returns its id
+	AddTaskRun(dbName string, run model.TaskRun) (string, error)
+	// ListTaskRuns returns the most recent runs of a task, all of them when
+	// limit is 0
+	ListTaskRuns(dbName, taskID string, limit int64) ([]model.TaskRun, error)
 
 	// Files / storage
 	// AddFile adds a new file
@@ -160,6 +408,35 @@ type Persister interface {
 	DeleteFile(dbName, fileID string) error
 	// ListAllFiles lists all file
 	ListAllFiles(dbName, accountID string) ([]model.File, error)
+	// ListFiles returns the most recent files matching the filter
+	ListFiles(dbName string, filter model.FileFilter) ([]model.File, error)
+	// LinkFile sets the document or user owning a file
+	LinkFile(dbName, fileID string, link model.FileLink) error
+	// StorageUsage returns the number of files and bytes stored
+	StorageUsage(dbName string) (model.StorageUsage, error)
 	// Count returns the numbers of entries in a collection based on optional filters
 	Count(auth model.Auth, dbName, col string, filters map[string]interface{}) (int64, error)
+
+	// auth audit log
+	// AddAuditEvent records an authentication event
+	AddAuditEvent(dbName string, evt model.AuditEvent) error
+	// ListAuditEvents returns the most recent audit events matching the filter
+	ListAuditEvents(dbName string, filter model.AuditFilter) ([]model.AuditEvent, error)
+	// CountAuditUsers returns the number of distinct users of the audit
+	// events matching the filter, its limit is ignored
+	CountAuditUsers(dbName string, filter model.AuditFilter) (int64, error)
+	// PurgeAuditEvents removes audit events created before a specific time
+	PurgeAuditEvents(dbName string, before time.Time) (int64, error)
+
+	// client analytics events
+	// AddAppEvents appends the events and adds them to the daily rollups
+	AddAppEvents(dbName string, events []model.AppEvent) error
+	// ListAppEvents returns the most recent events matching the filter
+	ListAppEvents(dbName string, filter model.AppEventFilter) ([]model.AppEvent, error)
+	// ListAppEventRollups returns the daily rollups matching the filter
+	// ordered by day and name
+	ListAppEventRollups(dbName string, filter model.AppEventRollupFilter) ([]model.AppEventRollup, error)
+	// PurgeAppEvents removes the events received before a specific time,
+	// the rollups are kept
+	PurgeAppEvents(dbName string, before time.Time) (int64, error)
 }
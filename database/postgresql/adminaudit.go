@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddAdminEvent(evt model.AdminEvent) error {
+	_, err := pg.DB.Exec(`
+		INSERT INTO sb.admin_audit(type, actor, tenant_id, db_name, target, detail, ip, user_agent, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		evt.Type,
+		evt.Actor,
+		evt.TenantID,
+		evt.DBName,
+		evt.Target,
+		evt.Detail,
+		evt.IP,
+		evt.UserAgent,
+		evt.Created,
+	)
+	return err
+}
+
+func (pg *PostgreSQL) ListAdminEvents(f model.AdminEventFilter) (results []model.AdminEvent, err error) {
+	where, args := adminEventWhere(f)
+
+	limit := ""
+	if f.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", f.Limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM sb.admin_audit 
+		%s
+		ORDER BY created DESC
+		%s
+	`, where, limit)
+
+	rows, err := pg.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var evt model.AdminEvent
+		if err = scanAdminEvent(rows, &evt); err != nil {
+			return
+		}
+
+		results = append(results, evt)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func adminEventWhere(f model.AdminEventFilter) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(f.TenantID) > 0 {
+		add("tenant_id = $%d", f.TenantID)
+	}
+	if len(f.DBName) > 0 {
+		add("db_name = $%d", f.DBName)
+	}
+	if len(f.Type) > 0 {
+		add("type = $%d", f.Type)
+	}
+	if !f.Since.IsZero() {
+		add("created >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created <= $%d", f.Until)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func scanAdminEvent(rows Scanner, evt *model.AdminEvent) error {
+	return rows.Scan(
+		&evt.ID,
+		&evt.Type,
+		&evt.Actor,
+		&evt.TenantID,
+		&evt.DBName,
+		&evt.Target,
+		&evt.Detail,
+		&evt.IP,
+		&evt.UserAgent,
+		&evt.Created,
+	)
+}
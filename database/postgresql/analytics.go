@@ -0,0 +1,85 @@
+package postgresql
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddRequestRollups(records []model.RequestRollup) (err error) {
+	tx, err := pg.DB.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, rr := range records {
+		_, err = tx.Exec(`
+			INSERT INTO sb.request_rollups(tenant_id, db_name, hour, endpoint, status, country, count, total_ms, max_ms)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT(db_name, hour, endpoint, status, country) DO UPDATE SET 
+				count = sb.request_rollups.count + $7,
+				total_ms = sb.request_rollups.total_ms + $8,
+				max_ms = GREATEST(sb.request_rollups.max_ms, $9)
+		`,
+			rr.TenantID,
+			rr.DBName,
+			rr.Hour,
+			rr.Endpoint,
+			rr.Status,
+			rr.Country,
+			rr.Count,
+			rr.TotalMillis,
+			rr.MaxMillis,
+		)
+		if err != nil {
+			return
+		}
+	}
+
+	err = tx.Commit()
+	return
+}
+
+func (pg *PostgreSQL) ListRequestRollups(filter model.RequestRollupFilter) (results []model.RequestRollup, err error) {
+	rows, err := pg.DB.Query(`
+		SELECT * 
+		FROM sb.request_rollups 
+		WHERE db_name = $1 
+		AND ($2 = '' OR hour >= $2) 
+		AND ($3 = '' OR hour <= $3)
+		ORDER BY hour
+	`, filter.DBName, filter.Since, filter.Until)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rr model.RequestRollup
+		if err = scanRequestRollup(rows, &rr); err != nil {
+			return
+		}
+
+		results = append(results, rr)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func scanRequestRollup(rows Scanner, rr *model.RequestRollup) error {
+	return rows.Scan(
+		&rr.TenantID,
+		&rr.DBName,
+		&rr.Hour,
+		&rr.Endpoint,
+		&rr.Status,
+		&rr.Country,
+		&rr.Count,
+		&rr.TotalMillis,
+		&rr.MaxMillis,
+	)
+}
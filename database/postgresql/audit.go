@@ -0,0 +1,135 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddAuditEvent(dbName string, evt model.AuditEvent) error {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s.sb_audit(account_id, user_id, email, type, detail, ip, user_agent, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+	`, dbName)
+
+	_, err := pg.DB.Exec(
+		qry,
+		evt.AccountID,
+		evt.UserID,
+		evt.Email,
+		evt.Type,
+		evt.Detail,
+		evt.IP,
+		evt.UserAgent,
+		evt.Created,
+	)
+	return err
+}
+
+func (pg *PostgreSQL) ListAuditEvents(dbName string, f model.AuditFilter) (results []model.AuditEvent, err error) {
+	where, args := auditWhere(f)
+
+	limit := ""
+	if f.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", f.Limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s.sb_audit 
+		%s
+		ORDER BY created DESC
+		%s
+	`, dbName, where, limit)
+
+	rows, err := pg.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var evt model.AuditEvent
+		if err = scanAuditEvent(rows, &evt); err != nil {
+			return
+		}
+
+		results = append(results, evt)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) PurgeAuditEvents(dbName string, before time.Time) (int64, error) {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s.sb_audit 
+		WHERE created < $1
+	`, dbName)
+
+	res, err := pg.DB.Exec(qry, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (pg *PostgreSQL) CountAuditUsers(dbName string, f model.AuditFilter) (count int64, err error) {
+	where, args := auditWhere(f)
+
+	qry := fmt.Sprintf(`
+		SELECT COUNT(DISTINCT user_id) 
+		FROM %s.sb_audit 
+		%s
+	`, dbName, where)
+
+	err = pg.DB.QueryRow(qry, args...).Scan(&count)
+	return
+}
+
+func auditWhere(f model.AuditFilter) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(f.AccountID) > 0 {
+		add("account_id = $%d", f.AccountID)
+	}
+	if len(f.UserID) > 0 {
+		add("user_id = $%d", f.UserID)
+	}
+	if len(f.Type) > 0 {
+		add("type = $%d", f.Type)
+	}
+	if !f.Since.IsZero() {
+		add("created >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created <= $%d", f.Until)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func scanAuditEvent(rows Scanner, evt *model.AuditEvent) error {
+	return rows.Scan(
+		&evt.ID,
+		&evt.AccountID,
+		&evt.UserID,
+		&evt.Email,
+		&evt.Type,
+		&evt.Detail,
+		&evt.IP,
+		&evt.UserAgent,
+		&evt.Created,
+	)
+}
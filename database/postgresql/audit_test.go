@@ -0,0 +1,83 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAuditEvents(t *testing.T) {
+	evt := model.AuditEvent{
+		AccountID: adminAccount.ID,
+		UserID:    adminToken.ID,
+		Email:     adminEmail,
+		Type:      model.AuditLogin,
+		IP:        "127.0.0.1",
+		UserAgent: "unit-test",
+		Created:   time.Now().Add(-48 * time.Hour),
+	}
+
+	if err := datastore.AddAuditEvent(confDBName, evt); err != nil {
+		t.Fatal(err)
+	}
+
+	evt.Type = model.AuditLoginFailed
+	evt.Created = time.Now()
+	if err := datastore.AddAuditEvent(confDBName, evt); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := datastore.ListAuditEvents(confDBName, model.AuditFilter{UserID: adminToken.ID})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Fatalf("expected 2 audit events got %d", len(list))
+	} else if list[0].Type != model.AuditLoginFailed {
+		t.Errorf("expected most recent event first, got %s", list[0].Type)
+	}
+
+	list, err = datastore.ListAuditEvents(confDBName, model.AuditFilter{Type: model.AuditLogin})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Errorf("expected 1 login event got %d", len(list))
+	}
+
+	n, err := datastore.PurgeAuditEvents(confDBName, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Errorf("expected 1 purged event got %d", n)
+	}
+
+	list, err = datastore.ListAuditEvents(confDBName, model.AuditFilter{UserID: adminToken.ID})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Errorf("expected 1 audit event after purge got %d", len(list))
+	}
+
+	evt.UserID = "another-user"
+	evt.Type = model.AuditLogin
+	for i := 0; i < 2; i++ {
+		if err := datastore.AddAuditEvent(confDBName, evt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	since := time.Now().Add(-time.Hour)
+	users, err := datastore.CountAuditUsers(confDBName, model.AuditFilter{Since: since})
+	if err != nil {
+		t.Fatal(err)
+	} else if users != 2 {
+		t.Errorf("expected 2 distinct users got %d", users)
+	}
+
+	users, err = datastore.CountAuditUsers(confDBName, model.AuditFilter{Type: model.AuditLogin, Since: since})
+	if err != nil {
+		t.Fatal(err)
+	} else if users != 1 {
+		t.Errorf("expected 1 user who logged in got %d", users)
+	}
+}
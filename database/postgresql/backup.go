@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddBackup(b model.Backup) (id string, err error) {
+	err = pg.DB.QueryRow(`
+		INSERT INTO sb.backups(tenant_id, db_name, task_id, file_key, size, documents, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id;
+	`,
+		b.TenantID,
+		b.DBName,
+		b.TaskID,
+		b.Key,
+		b.Size,
+		b.Documents,
+		b.Created,
+	).Scan(&id)
+	return
+}
+
+func (pg *PostgreSQL) ListBackups(dbName string) (results []model.Backup, err error) {
+	rows, err := pg.DB.Query(`
+		SELECT * 
+		FROM sb.backups 
+		WHERE db_name = $1
+		ORDER BY created DESC
+	`, dbName)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b model.Backup
+		if err = scanBackup(rows, &b); err != nil {
+			return
+		}
+
+		results = append(results, b)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) GetBackup(id string) (b model.Backup, err error) {
+	row := pg.DB.QueryRow(`
+		SELECT * 
+		FROM sb.backups 
+		WHERE id = $1
+	`, id)
+
+	err = scanBackup(row, &b)
+	return
+}
+
+func (pg *PostgreSQL) DeleteBackup(id string) error {
+	_, err := pg.DB.Exec(`
+		DELETE FROM sb.backups 
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func scanBackup(rows Scanner, b *model.Backup) error {
+	return rows.Scan(
+		&b.ID,
+		&b.TenantID,
+		&b.DBName,
+		&b.TaskID,
+		&b.Key,
+		&b.Size,
+		&b.Documents,
+		&b.Created,
+	)
+}
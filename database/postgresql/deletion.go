@@ -0,0 +1,101 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddAppDeletion(d model.AppDeletion) (id string, err error) {
+	err = pg.DB.QueryRow(`
+		INSERT INTO sb.app_deletions(base_id, tenant_id, db_name, email, status, requested, scheduled, completed, certificate)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id;
+	`,
+		d.BaseID,
+		d.TenantID,
+		d.DBName,
+		d.Email,
+		model.AppDeletionPending,
+		d.Requested,
+		d.Scheduled,
+		time.Time{},
+		"",
+	).Scan(&id)
+	return
+}
+
+func (pg *PostgreSQL) GetAppDeletion(baseID string) (d model.AppDeletion, err error) {
+	row := pg.DB.QueryRow(`
+		SELECT * 
+		FROM sb.app_deletions 
+		WHERE base_id = $1 AND status = $2
+	`, baseID, model.AppDeletionPending)
+
+	err = scanAppDeletion(row, &d)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AppDeletion{}, nil
+	}
+	return
+}
+
+func (pg *PostgreSQL) ListDueAppDeletions(now time.Time) (results []model.AppDeletion, err error) {
+	rows, err := pg.DB.Query(`
+		SELECT * 
+		FROM sb.app_deletions 
+		WHERE status = $1 AND scheduled <= $2
+		ORDER BY scheduled
+	`, model.AppDeletionPending, now)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.AppDeletion
+		if err = scanAppDeletion(rows, &d); err != nil {
+			return
+		}
+
+		results = append(results, d)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) CompleteAppDeletion(id string, completed time.Time, certificate string) error {
+	_, err := pg.DB.Exec(`
+		UPDATE sb.app_deletions SET
+			status = $2,
+			completed = $3,
+			certificate = $4
+		WHERE id = $1
+	`, id, model.AppDeletionCompleted, completed, certificate)
+	return err
+}
+
+func (pg *PostgreSQL) CancelAppDeletion(id string) error {
+	_, err := pg.DB.Exec(`
+		DELETE FROM sb.app_deletions 
+		WHERE id = $1 AND status = $2
+	`, id, model.AppDeletionPending)
+	return err
+}
+
+func scanAppDeletion(rows Scanner, d *model.AppDeletion) error {
+	return rows.Scan(
+		&d.ID,
+		&d.BaseID,
+		&d.TenantID,
+		&d.DBName,
+		&d.Email,
+		&d.Status,
+		&d.Requested,
+		&d.Scheduled,
+		&d.Completed,
+		&d.Certificate,
+	)
+}
@@ -0,0 +1,86 @@
+package postgresql
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddDomain(d model.Domain) (id string, err error) {
+	err = pg.DB.QueryRow(`
+		INSERT INTO sb.domains(db_name, base_id, hostname, token, verified, created)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`,
+		d.DBName,
+		d.BaseID,
+		d.Hostname,
+		d.Token,
+		d.Verified,
+		d.Created,
+	).Scan(&id)
+	return
+}
+
+func (pg *PostgreSQL) ListDomains(dbName string) (results []model.Domain, err error) {
+	rows, err := pg.DB.Query(`
+		SELECT * 
+		FROM sb.domains 
+		WHERE db_name = $1
+		ORDER BY hostname
+	`, dbName)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.Domain
+		if err = scanDomain(rows, &d); err != nil {
+			return
+		}
+
+		results = append(results, d)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) FindDomain(hostname string) (d model.Domain, err error) {
+	row := pg.DB.QueryRow(`
+		SELECT * 
+		FROM sb.domains 
+		WHERE hostname = $1
+	`, hostname)
+
+	err = scanDomain(row, &d)
+	return
+}
+
+func (pg *PostgreSQL) VerifyDomain(id string) error {
+	_, err := pg.DB.Exec(`
+		UPDATE sb.domains SET
+			verified = true
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func (pg *PostgreSQL) DeleteDomain(dbName, hostname string) error {
+	_, err := pg.DB.Exec(`
+		DELETE FROM sb.domains 
+		WHERE db_name = $1 AND hostname = $2
+	`, dbName, hostname)
+	return err
+}
+
+func scanDomain(rows Scanner, d *model.Domain) error {
+	return rows.Scan(
+		&d.ID,
+		&d.DBName,
+		&d.BaseID,
+		&d.Hostname,
+		&d.Token,
+		&d.Verified,
+		&d.Created,
+	)
+}
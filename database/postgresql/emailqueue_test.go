@@ -0,0 +1,128 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestEmailQueue(t *testing.T) {
+	now := time.Now()
+	msg := model.EmailMessage{
+		From:        "app@domain.com",
+		To:          "user@domain.com",
+		Subject:     "Queued",
+		HTMLBody:    "<p>Queued</p>",
+		TextBody:    "Queued",
+		Attachments: []model.EmailAttachment{{Filename: "a.txt", ContentType: "text/plain", Content: []byte("attached")}},
+		Status:      model.EmailStatusQueued,
+		NextAttempt: now.Add(-time.Second),
+		Created:     now,
+		Updated:     now,
+	}
+
+	id, err := datastore.QueueEmail(confDBName, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	later := msg
+	later.NextAttempt = now.Add(time.Hour)
+	if _, err := datastore.QueueEmail(confDBName, later); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := datastore.ListDueEmails(confDBName, now, 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(due) != 1 || due[0].ID != id {
+		t.Fatalf("expected only the due email got %v", due)
+	}
+
+	retry := now.Add(time.Minute)
+	if err := datastore.UpdateEmailStatus(confDBName, id, model.EmailStatusQueued, 1, "unavailable", "", retry); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err = datastore.ListDueEmails(confDBName, now, 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(due) != 0 {
+		t.Errorf("expected the retried email to not be due got %v", due)
+	}
+
+	if err := datastore.UpdateEmailStatus(confDBName, id, model.EmailStatusSent, 2, "", "provider-id", retry); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := datastore.GetEmailMessage(confDBName, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if sent.Status != model.EmailStatusSent || sent.Attempts != 2 || sent.To != msg.To || sent.Subject != msg.Subject {
+		t.Errorf("unexpected email %v", sent)
+	} else if sent.ProviderID != "provider-id" {
+		t.Errorf("expected the provider message ID got %s", sent.ProviderID)
+	} else if len(sent.Attachments) != 1 || string(sent.Attachments[0].Content) != "attached" {
+		t.Errorf("unexpected attachments %v", sent.Attachments)
+	}
+}
+
+func TestEmailLog(t *testing.T) {
+	now := time.Now()
+	msgs := []model.EmailMessage{
+		{To: "log1@domain.com", Subject: "Reset your password", Template: model.EmailTemplatePasswordReset, Status: model.EmailStatusSent},
+		{To: "log2@domain.com", Subject: "Weekly digest", Status: model.EmailStatusFailed},
+		{To: "log1@domain.com", Subject: "Weekly digest", Status: model.EmailStatusQueued},
+	}
+
+	for i, msg := range msgs {
+		msg.From = "app@domain.com"
+		msg.NextAttempt = now
+		msg.Created = now.Add(time.Duration(i) * time.Second)
+		msg.Updated = msg.Created
+		if _, err := datastore.QueueEmail(confDBName, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter model.EmailLogFilter
+		count  int
+	}{
+		{"recipient", model.EmailLogFilter{To: "log1@domain.com"}, 2},
+		{"template", model.EmailLogFilter{To: "log1@domain.com", Template: model.EmailTemplatePasswordReset}, 1},
+		{"status", model.EmailLogFilter{Query: "log", Status: model.EmailStatusFailed}, 1},
+		{"query", model.EmailLogFilter{Query: "DIGEST", Since: now}, 2},
+		{"limit", model.EmailLogFilter{Query: "log", Limit: 1}, 1},
+	}
+
+	for _, tc := range tests {
+		results, err := datastore.ListEmailLog(confDBName, tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(results) != tc.count {
+			t.Errorf("%s: expected %d emails got %d", tc.name, tc.count, len(results))
+		}
+
+		// the count ignores the limit
+		if tc.filter.Limit > 0 {
+			continue
+		}
+
+		n, err := datastore.CountEmails(confDBName, tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		} else if n != int64(tc.count) {
+			t.Errorf("%s: expected a count of %d emails got %d", tc.name, tc.count, n)
+		}
+	}
+
+	results, err := datastore.ListEmailLog(confDBName, model.EmailLogFilter{To: "log1@domain.com"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(results) > 0 && results[0].Subject != "Weekly digest" {
+		t.Errorf("expected the most recent email first got %v", results[0])
+	}
+}
@@ -0,0 +1,136 @@
+package postgresql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) ListEmailTemplates(dbName string) (results []model.EmailTemplate, err error) {
+	qry := fmt.Sprintf(`
+		SELECT name, subject, html_body, text_body, variables, updated 
+		FROM %s.sb_email_templates 
+		ORDER BY name
+	`, dbName)
+
+	rows, err := pg.DB.Query(qry)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tmpl model.EmailTemplate
+		if err = scanEmailTemplate(rows, &tmpl); err != nil {
+			return
+		}
+
+		results = append(results, tmpl)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) GetEmailTemplate(dbName, name string) (tmpl model.EmailTemplate, err error) {
+	qry := fmt.Sprintf(`
+		SELECT name, subject, html_body, text_body, variables, updated 
+		FROM %s.sb_email_templates 
+		WHERE name = $1
+	`, dbName)
+
+	err = scanEmailTemplate(pg.DB.QueryRow(qry, name), &tmpl)
+	return
+}
+
+func (pg *PostgreSQL) SaveEmailTemplate(dbName string, tmpl model.EmailTemplate) error {
+	vars, err := json.Marshal(tmpl.Variables)
+	if err != nil {
+		return err
+	}
+
+	qry := fmt.Sprintf(`
+		INSERT INTO %s.sb_email_templates(name, subject, html_body, text_body, variables, updated)
+		VALUES($1, $2, $3, $4, $5, $6)
+		ON CONFLICT(name) DO UPDATE SET subject = excluded.subject, html_body = excluded.html_body, 
+			text_body = excluded.text_body, variables = excluded.variables, updated = excluded.updated
+	`, dbName)
+
+	_, err = pg.DB.Exec(
+		qry,
+		tmpl.Name,
+		tmpl.Subject,
+		tmpl.HTMLBody,
+		tmpl.TextBody,
+		string(vars),
+		tmpl.Updated,
+	)
+	return err
+}
+
+func (pg *PostgreSQL) DeleteEmailTemplate(dbName, name string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s.sb_email_templates 
+		WHERE name = $1
+	`, dbName)
+
+	_, err := pg.DB.Exec(qry, name)
+	return err
+}
+
+func (pg *PostgreSQL) GetUserLocale(dbName, userID string) (string, error) {
+	qry := fmt.Sprintf(`
+		SELECT locale 
+		FROM %s.sb_user_locales 
+		WHERE user_id = $1
+	`, dbName)
+
+	var locale string
+	if err := pg.DB.QueryRow(qry, userID).Scan(&locale); errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return locale, nil
+}
+
+func (pg *PostgreSQL) SetUserLocale(dbName, userID, locale string) error {
+	if len(locale) == 0 {
+		qry := fmt.Sprintf(`
+			DELETE FROM %s.sb_user_locales 
+			WHERE user_id = $1
+		`, dbName)
+
+		_, err := pg.DB.Exec(qry, userID)
+		return err
+	}
+
+	qry := fmt.Sprintf(`
+		INSERT INTO %s.sb_user_locales(user_id, locale)
+		VALUES($1, $2)
+		ON CONFLICT(user_id) DO UPDATE SET locale = excluded.locale
+	`, dbName)
+
+	_, err := pg.DB.Exec(qry, userID, locale)
+	return err
+}
+
+func scanEmailTemplate(rows Scanner, tmpl *model.EmailTemplate) error {
+	var vars string
+	err := rows.Scan(
+		&tmpl.Name,
+		&tmpl.Subject,
+		&tmpl.HTMLBody,
+		&tmpl.TextBody,
+		&vars,
+		&tmpl.Updated,
+	)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(vars), &tmpl.Variables)
+}
@@ -0,0 +1,47 @@
+package postgresql
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) SetFeatureFlag(flag model.FeatureFlag) error {
+	_, err := pg.DB.Exec(`
+		INSERT INTO sb.feature_flags(tenant_id, name, enabled, updated)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT(tenant_id, name) DO UPDATE SET enabled = excluded.enabled, updated = excluded.updated
+	`, flag.TenantID, flag.Name, flag.Enabled, flag.Updated)
+	return err
+}
+
+func (pg *PostgreSQL) ListFeatureFlags(tenantID string) (results []model.FeatureFlag, err error) {
+	rows, err := pg.DB.Query(`
+		SELECT tenant_id, name, enabled, updated 
+		FROM sb.feature_flags 
+		WHERE tenant_id = $1
+		ORDER BY name
+	`, tenantID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var flag model.FeatureFlag
+		if err = rows.Scan(&flag.TenantID, &flag.Name, &flag.Enabled, &flag.Updated); err != nil {
+			return
+		}
+
+		results = append(results, flag)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) DeleteFeatureFlag(tenantID, name string) error {
+	_, err := pg.DB.Exec(`
+		DELETE FROM sb.feature_flags 
+		WHERE tenant_id = $1 AND name = $2
+	`, tenantID, name)
+	return err
+}
@@ -2,7 +2,10 @@ package postgresql
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/staticbackendhq/core/model"
 )
 
 type FormData struct {
@@ -84,3 +87,155 @@ func (pg *PostgreSQL) GetForms(dbName string) (results []string, err error) {
 	err = rows.Err()
 	return
 }
+
+func (pg *PostgreSQL) EachFormSubmission(dbName, name string, since, until time.Time, fn func(doc map[string]interface{}) error) error {
+	where, args := formWhere(name, model.FormFilter{Since: since, Until: until})
+
+	qry := fmt.Sprintf(`
+		SELECT id, data, created
+		FROM %s.sb_forms
+		%s
+		ORDER BY created
+	`, dbName, where)
+
+	rows, err := pg.DB.Query(qry, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		doc, err := scanFormSubmission(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (pg *PostgreSQL) QueryFormSubmissions(dbName, name string, f model.FormFilter, params model.ListParams) (result model.PagedResult, err error) {
+	result.Page = params.Page
+	result.Size = params.Size
+
+	where, args := formWhere(name, f)
+
+	qry := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s.sb_forms
+		%s
+	`, dbName, where)
+
+	if err = pg.DB.QueryRow(qry, args...).Scan(&result.Total); err != nil {
+		return
+	}
+
+	sort := "ASC"
+	if params.SortDescending {
+		sort = "DESC"
+	}
+
+	qry = fmt.Sprintf(`
+		SELECT id, data, created
+		FROM %s.sb_forms
+		%s
+		ORDER BY created %s
+		LIMIT %d OFFSET %d
+	`, dbName, where, sort, params.Size, (params.Page-1)*params.Size)
+
+	rows, err := pg.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	result.Results = make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var doc map[string]interface{}
+		if doc, err = scanFormSubmission(rows); err != nil {
+			return
+		}
+
+		result.Results = append(result.Results, doc)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) DeleteFormSubmission(dbName, name, id string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s.sb_forms
+		WHERE name = $1 AND id = $2
+	`, dbName)
+
+	_, err := pg.DB.Exec(qry, name, id)
+	return err
+}
+
+func (pg *PostgreSQL) MarkFormSubmissionRead(dbName, name, id string, read bool) error {
+	qry := fmt.Sprintf(`
+		UPDATE %s.sb_forms
+		SET data = jsonb_set(data, '{sb_read}', to_jsonb($1::boolean))
+		WHERE name = $2 AND id = $3
+	`, dbName)
+
+	_, err := pg.DB.Exec(qry, read, name, id)
+	return err
+}
+
+// formWhere returns the WHERE clause of the submissions of a form matching
+// the filter
+func formWhere(name string, f model.FormFilter) (string, []interface{}) {
+	clauses := []string{"name = $1"}
+	args := []interface{}{name}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if !f.Since.IsZero() {
+		add("created >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created <= $%d", f.Until)
+	}
+	if f.Spam != nil {
+		add("COALESCE((data->>'sb_spam')::boolean, false) = $%d", *f.Spam)
+	}
+	if f.Read != nil {
+		add("COALESCE((data->>'sb_read')::boolean, false) = $%d", *f.Read)
+	}
+	if len(f.Search) > 0 {
+		add(`EXISTS (SELECT 1 FROM jsonb_each_text(data) AS f WHERE f.value ILIKE $%d)`, "%"+escapeLike(f.Search)+"%")
+	}
+
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// escapeLike escapes the LIKE wildcards of a search term
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func scanFormSubmission(rows Scanner) (map[string]interface{}, error) {
+	var id string
+	var data JSONB
+	var created time.Time
+	if err := rows.Scan(&id, &data, &created); err != nil {
+		return nil, err
+	}
+
+	doc := map[string]interface{}(data)
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+	doc["id"] = id
+	doc["created"] = created
+	return doc, nil
+}
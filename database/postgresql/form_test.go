@@ -1,7 +1,12 @@
 package postgresql
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
 )
 
 func TestForm(t *testing.T) {
@@ -31,3 +36,212 @@ func TestForm(t *testing.T) {
 		t.Errorf("expected forms[0] to be test got %s", forms[0])
 	}
 }
+
+func TestEachFormSubmission(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		doc := map[string]interface{}{"name": name}
+		if err := datastore.AddFormSubmission(confDBName, "export", doc); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var names []string
+	err := datastore.EachFormSubmission(confDBName, "export", time.Time{}, time.Time{}, func(doc map[string]interface{}) error {
+		if _, ok := doc["created"].(time.Time); !ok {
+			t.Errorf("expected the created time got %v", doc["created"])
+		}
+
+		names = append(names, fmt.Sprintf("%v", doc["name"]))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if strings.Join(names, ",") != "first,second" {
+		t.Errorf("expected oldest submissions first got %v", names)
+	}
+
+	var count int
+	err = datastore.EachFormSubmission(confDBName, "export", time.Now().Add(time.Hour), time.Time{}, func(doc map[string]interface{}) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if count != 0 {
+		t.Errorf("expected no submissions in the future got %d", count)
+	}
+}
+
+func TestFormDeliveries(t *testing.T) {
+	d := model.FormDelivery{
+		Form:       "deliveries",
+		URL:        "https://example.com/hook",
+		Attempt:    1,
+		StatusCode: 500,
+		Error:      "webhook returned status 500",
+		Created:    time.Now().Add(-time.Minute),
+	}
+
+	if err := datastore.AddFormDelivery(confDBName, d); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Attempt = 2
+	d.StatusCode = 200
+	d.Success = true
+	d.Error = ""
+	d.Created = time.Now()
+	if err := datastore.AddFormDelivery(confDBName, d); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := datastore.ListFormDeliveries(confDBName, "deliveries", 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Fatalf("expected 2 deliveries got %d", len(list))
+	} else if !list[0].Success || list[0].Attempt != 2 {
+		t.Errorf("expected the successful attempt first got %v", list[0])
+	} else if len(list[0].ID) == 0 {
+		t.Error("expected the delivery to have an id")
+	}
+
+	list, err = datastore.ListFormDeliveries(confDBName, "deliveries", 1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Errorf("expected the limit to be applied got %d", len(list))
+	}
+}
+
+func TestFormDefinitions(t *testing.T) {
+	def, err := datastore.GetFormDefinition(confDBName, "defined")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(def.Fields) != 0 {
+		t.Fatalf("expected no fields for an undefined form got %v", def.Fields)
+	}
+
+	def = model.FormDefinition{
+		Name: "defined",
+		Fields: []model.FormField{
+			{Name: "email", Type: model.FormFieldEmail, Required: true},
+		},
+		Updated: time.Now(),
+	}
+	if err := datastore.SaveFormDefinition(confDBName, def); err != nil {
+		t.Fatal(err)
+	}
+
+	// saving again replaces the definition
+	def.Fields = append(def.Fields, model.FormField{Name: "zip", Pattern: "^[0-9]{5}$", MaxLength: 5})
+	if err := datastore.SaveFormDefinition(confDBName, def); err != nil {
+		t.Fatal(err)
+	}
+
+	def, err = datastore.GetFormDefinition(confDBName, "defined")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(def.Fields) != 2 {
+		t.Fatalf("expected 2 fields got %v", def.Fields)
+	} else if f := def.Fields[1]; f.Name != "zip" || f.Pattern != "^[0-9]{5}$" || f.MaxLength != 5 {
+		t.Errorf("unexpected field %v", f)
+	}
+
+	defs, err := datastore.ListFormDefinitions(confDBName)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(defs) != 1 || defs[0].Name != "defined" || len(defs[0].Fields) != 2 {
+		t.Errorf("expected the defined form's definition got %v", defs)
+	}
+
+	if err := datastore.DeleteFormDefinition(confDBName, "defined"); err != nil {
+		t.Fatal(err)
+	}
+
+	def, err = datastore.GetFormDefinition(confDBName, "defined")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(def.Fields) != 0 {
+		t.Errorf("expected the definition to be deleted got %v", def.Fields)
+	}
+}
+
+func TestQueryFormSubmissions(t *testing.T) {
+	form := "queried"
+	for i := 0; i < 5; i++ {
+		doc := map[string]interface{}{"name": fmt.Sprintf("entry %d", i)}
+		if i == 3 {
+			doc["message"] = "Looking for 100% coverage"
+		}
+		if err := datastore.AddFormSubmission(confDBName, form, doc); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	params := model.ListParams{Page: 1, Size: 2, SortDescending: true}
+	res, err := datastore.QueryFormSubmissions(confDBName, form, model.FormFilter{}, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Total != 5 || len(res.Results) != 2 {
+		t.Fatalf("expected 2 of 5 submissions got %d of %d", len(res.Results), res.Total)
+	} else if res.Results[0]["name"] != "entry 4" {
+		t.Errorf("expected the newest submission first got %v", res.Results[0])
+	}
+
+	params.Page = 3
+	res, err = datastore.QueryFormSubmissions(confDBName, form, model.FormFilter{}, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(res.Results) != 1 || res.Results[0]["name"] != "entry 0" {
+		t.Errorf("expected the oldest submission on the last page got %v", res.Results)
+	}
+
+	params.Page = 1
+	res, err = datastore.QueryFormSubmissions(confDBName, form, model.FormFilter{Search: "100% COVERAGE"}, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Total != 1 || res.Results[0]["name"] != "entry 3" {
+		t.Fatalf("expected the search to match entry 3 got %v", res.Results)
+	}
+
+	id, _ := res.Results[0]["id"].(string)
+	if err := datastore.MarkFormSubmissionRead(confDBName, form, id, true); err != nil {
+		t.Fatal(err)
+	}
+
+	read, unread := true, false
+	res, err = datastore.QueryFormSubmissions(confDBName, form, model.FormFilter{Read: &read}, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Total != 1 || res.Results[0]["id"] != id {
+		t.Errorf("expected the read submission got %v", res.Results)
+	}
+
+	res, err = datastore.QueryFormSubmissions(confDBName, form, model.FormFilter{Read: &unread}, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Total != 4 {
+		t.Errorf("expected 4 unread submissions got %d", res.Total)
+	}
+
+	res, err = datastore.QueryFormSubmissions(confDBName, form, model.FormFilter{Since: time.Now().Add(time.Hour)}, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Total != 0 {
+		t.Errorf("expected no submissions in the future got %d", res.Total)
+	}
+
+	if err := datastore.DeleteFormSubmission(confDBName, form, id); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = datastore.QueryFormSubmissions(confDBName, form, model.FormFilter{}, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Total != 4 {
+		t.Errorf("expected 4 submissions after the delete got %d", res.Total)
+	}
+}
@@ -0,0 +1,72 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddFormDelivery(dbName string, d model.FormDelivery) error {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s.sb_form_deliveries(form, url, attempt, status_code, success, error, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+	`, dbName)
+
+	_, err := pg.DB.Exec(
+		qry,
+		d.Form,
+		d.URL,
+		d.Attempt,
+		d.StatusCode,
+		d.Success,
+		d.Error,
+		d.Created,
+	)
+	return err
+}
+
+func (pg *PostgreSQL) ListFormDeliveries(dbName, form string, limit int64) (results []model.FormDelivery, err error) {
+	lim := ""
+	if limit > 0 {
+		lim = fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s.sb_form_deliveries 
+		WHERE form = $1
+		ORDER BY created DESC
+		%s
+	`, dbName, lim)
+
+	rows, err := pg.DB.Query(qry, form)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.FormDelivery
+		if err = scanFormDelivery(rows, &d); err != nil {
+			return
+		}
+
+		results = append(results, d)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func scanFormDelivery(rows Scanner, d *model.FormDelivery) error {
+	return rows.Scan(
+		&d.ID,
+		&d.Form,
+		&d.URL,
+		&d.Attempt,
+		&d.StatusCode,
+		&d.Success,
+		&d.Error,
+		&d.Created,
+	)
+}
@@ -230,6 +230,17 @@ func (pg *PostgreSQL) RanFunction(dbName, id string, rh model.ExecHistory) error
 	return err
 }
 
+func (pg *PostgreSQL) CountFunctionRuns(dbName string, since, until time.Time) (count int64, err error) {
+	qry := fmt.Sprintf(`
+		SELECT COUNT(*) 
+		FROM %s.sb_function_logs 
+		WHERE started >= $1 AND started < $2
+	`, dbName)
+
+	err = pg.DB.QueryRow(qry, since, until).Scan(&count)
+	return
+}
+
 func scanExecData(rows Scanner, ex *model.ExecData) error {
 	return rows.Scan(
 		&ex.ID,
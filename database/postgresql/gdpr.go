@@ -0,0 +1,106 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) PurgeUser(dbName string, tok model.User) (report model.DeletionReport, err error) {
+	report = model.NewDeletionReport(tok)
+
+	cols, err := pg.ListCollections(dbName)
+	if err != nil {
+		return
+	}
+
+	tx, err := pg.DB.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, col := range cols {
+		if strings.HasPrefix(col, "sb_") {
+			continue
+		}
+
+		qry := fmt.Sprintf(`
+			DELETE FROM %s.%s 
+			WHERE owner_id = $1
+		`, dbName, col)
+
+		res, err := tx.Exec(qry, tok.ID)
+		if err != nil {
+			return report, err
+		}
+
+		if n, err := res.RowsAffected(); err != nil {
+			return report, err
+		} else if n > 0 {
+			report.Documents[col] = n
+		}
+	}
+
+	qry := fmt.Sprintf(`
+		DELETE FROM %s.sb_forms 
+		WHERE LOWER(data->>'email') = LOWER($1)
+	`, dbName)
+
+	res, err := tx.Exec(qry, tok.Email)
+	if err != nil {
+		return
+	}
+
+	if report.FormSubmissions, err = res.RowsAffected(); err != nil {
+		return
+	}
+
+	// push subscriptions are removed by the ON DELETE CASCADE
+	qry = fmt.Sprintf(`
+		DELETE FROM %s.sb_tokens 
+		WHERE id = $1
+	`, dbName)
+
+	if _, err = tx.Exec(qry, tok.ID); err != nil {
+		return
+	}
+
+	var count int
+	qry = fmt.Sprintf(`
+		SELECT COUNT(*) 
+		FROM %s.sb_tokens 
+		WHERE account_id = $1
+	`, dbName)
+
+	if err = tx.QueryRow(qry, tok.AccountID).Scan(&count); err != nil {
+		return
+	}
+
+	if count == 0 {
+		// files and invites are removed by the ON DELETE CASCADE
+		qry = fmt.Sprintf(`
+			DELETE FROM %s.sb_accounts 
+			WHERE id = $1
+		`, dbName)
+
+		if _, err = tx.Exec(qry, tok.AccountID); err != nil {
+			return
+		}
+
+		report.AccountDeleted = true
+	}
+
+	if err = tx.Commit(); err != nil {
+		return
+	}
+
+	report.Completed = time.Now()
+	return
+}
@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestPurgeUser(t *testing.T) {
+	acctID, err := datastore.CreateAccount(confDBName, "purge@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tok := model.User{
+		AccountID: acctID,
+		Token:     "purge-token",
+		Email:     "purge@test.com",
+		Password:  "purge",
+		Role:      50,
+		Created:   time.Now(),
+	}
+
+	tok.ID, err = datastore.CreateUser(confDBName, tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := model.Auth{
+		AccountID: tok.AccountID,
+		UserID:    tok.ID,
+		Email:     tok.Email,
+		Role:      tok.Role,
+		Token:     tok.Token,
+	}
+
+	for i := 0; i < 2; i++ {
+		doc := map[string]interface{}{"title": "purge me"}
+		if _, err := datastore.CreateDocument(auth, confDBName, "gdpr_notes", doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kept := map[string]interface{}{"title": "keep me"}
+	if _, err := datastore.CreateDocument(adminAuth, confDBName, "gdpr_notes", kept); err != nil {
+		t.Fatal(err)
+	}
+
+	form := map[string]interface{}{"email": "PURGE@test.com", "msg": "hello"}
+	if err := datastore.AddFormSubmission(confDBName, "gdpr", form); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := datastore.PurgeUser(confDBName, tok)
+	if err != nil {
+		t.Fatal(err)
+	} else if report.Documents["gdpr_notes"] != 2 {
+		t.Errorf("expected 2 documents removed got %d", report.Documents["gdpr_notes"])
+	} else if report.FormSubmissions != 1 {
+		t.Errorf("expected 1 form submission removed got %d", report.FormSubmissions)
+	} else if !report.AccountDeleted {
+		t.Error("expected the account to be deleted")
+	}
+
+	if _, err := datastore.GetUserByID(confDBName, acctID, tok.ID); err == nil {
+		t.Error("expected an error getting a purged user")
+	}
+
+	res, err := datastore.ListDocuments(adminAuth, confDBName, "gdpr_notes", model.ListParams{Page: 1, Size: 50})
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Total != 1 {
+		t.Errorf("expected 1 remaining document got %d", res.Total)
+	}
+}
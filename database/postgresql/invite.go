@@ -0,0 +1,88 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddInvite(dbName string, inv model.Invite) (id string, err error) {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s.sb_invites(account_id, email, role, invited_by, created, expires)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`, dbName)
+
+	err = pg.DB.QueryRow(
+		qry,
+		inv.AccountID,
+		inv.Email,
+		inv.Role,
+		inv.InvitedBy,
+		inv.Created,
+		inv.Expires,
+	).Scan(&id)
+	return
+}
+
+func (pg *PostgreSQL) GetInvite(dbName, id string) (inv model.Invite, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s.sb_invites 
+		WHERE id = $1
+	`, dbName)
+
+	row := pg.DB.QueryRow(qry, id)
+
+	err = scanInvite(row, &inv)
+	return
+}
+
+func (pg *PostgreSQL) ListInvites(dbName, accountID string) (results []model.Invite, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s.sb_invites 
+		WHERE account_id = $1
+		ORDER BY created
+	`, dbName)
+
+	rows, err := pg.DB.Query(qry, accountID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var inv model.Invite
+		if err = scanInvite(rows, &inv); err != nil {
+			return
+		}
+
+		results = append(results, inv)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) DeleteInvite(dbName, id string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s.sb_invites 
+		WHERE id = $1
+	`, dbName)
+
+	_, err := pg.DB.Exec(qry, id)
+	return err
+}
+
+func scanInvite(rows Scanner, inv *model.Invite) error {
+	return rows.Scan(
+		&inv.ID,
+		&inv.AccountID,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Created,
+		&inv.Expires,
+	)
+}
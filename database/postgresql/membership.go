@@ -78,6 +78,18 @@ func (pg *PostgreSQL) UserSetPassword(dbName, tokenID, password string) error {
 	return nil
 }
 
+func (pg *PostgreSQL) ResetUserCredentials(dbName, userID, email, password, token string) error {
+	qry := fmt.Sprintf(`
+		UPDATE %s.sb_tokens SET email = $2, password = $3, token = $4
+		WHERE id = $1;
+	`, dbName)
+
+	if _, err := pg.DB.Exec(qry, userID, email, password, token); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (pg *PostgreSQL) GetFirstUserFromAccountID(dbName, accountID string) (tok model.User, err error) {
 	qry := fmt.Sprintf(`
 		SELECT * 
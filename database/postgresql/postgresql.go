@@ -3,8 +3,6 @@ package postgresql
 import (
 	"database/sql"
 	"embed"
-	"fmt"
-	"os"
 	"strings"
 
 	"github.com/staticbackendhq/core/cache"
@@ -25,13 +23,17 @@ var migrationFS embed.FS
 func New(db *sql.DB, pubdoc cache.PublishDocumentEvent, log *logger.Logger) database.Persister {
 	// run migrations
 	if err := migrate(db); err != nil {
-		fmt.Println("=== MIGRATION FAILED ===")
-		fmt.Println(err)
-		fmt.Println("=== /MIGRATION FAILED ===")
-		os.Exit(1)
+		log.Fatal().Err(err).Msg("migration failed")
 	}
 
-	return &PostgreSQL{DB: db, PublishDocument: pubdoc, log: log}
+	pg := &PostgreSQL{DB: db, PublishDocument: pubdoc, log: log}
+
+	// the databases created before the last system tables changes
+	if err := pg.upgradeTenants(); err != nil {
+		log.Fatal().Err(err).Msg("system tables upgrade failed")
+	}
+
+	return pg
 }
 
 func (pg *PostgreSQL) Ping() error {
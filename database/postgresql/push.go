@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddPushSubscription(dbName string, sub model.PushSubscription) (id string, err error) {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s.sb_push(account_id, user_id, channel, kind, endpoint, p256dh, auth, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id;
+	`, dbName)
+
+	err = pg.DB.QueryRow(
+		qry,
+		sub.AccountID,
+		sub.UserID,
+		sub.Channel,
+		sub.Kind,
+		sub.Endpoint,
+		sub.P256dh,
+		sub.Auth,
+		sub.Created,
+	).Scan(&id)
+	return
+}
+
+func (pg *PostgreSQL) ListPushSubscriptions(dbName, channel string) (results []model.PushSubscription, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s.sb_push 
+		WHERE channel = $1
+	`, dbName)
+
+	rows, err := pg.DB.Query(qry, channel)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sub model.PushSubscription
+		if err = scanPushSubscription(rows, &sub); err != nil {
+			return
+		}
+
+		results = append(results, sub)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) DeletePushSubscription(dbName, accountID, id string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s.sb_push 
+		WHERE id = $1 AND account_id = $2
+	`, dbName)
+
+	_, err := pg.DB.Exec(qry, id, accountID)
+	return err
+}
+
+func scanPushSubscription(rows Scanner, sub *model.PushSubscription) error {
+	return rows.Scan(
+		&sub.ID,
+		&sub.AccountID,
+		&sub.UserID,
+		&sub.Channel,
+		&sub.Kind,
+		&sub.Endpoint,
+		&sub.P256dh,
+		&sub.Auth,
+		&sub.Created,
+	)
+}
@@ -1,6 +1,7 @@
 package postgresql
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -40,8 +41,8 @@ func (pg *PostgreSQL) CreateDatabase(base model.DatabaseConfig) (b model.Databas
 
 	var id string
 	err = pg.DB.QueryRow(`
-	INSERT INTO sb.apps(customer_id, name, allowed_domain, is_active, monthly_email_sent, created)
-	VALUES($1, $2, $3, $4, $5, $6)
+	INSERT INTO sb.apps(customer_id, name, allowed_domain, is_active, monthly_email_sent, created, environment, parent_id, region)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	RETURNING id;
 	`, base.TenantID,
 		base.Name,
@@ -49,6 +50,9 @@ func (pg *PostgreSQL) CreateDatabase(base model.DatabaseConfig) (b model.Databas
 		base.IsActive,
 		base.MonthlySentEmail,
 		base.Created,
+		base.Environment,
+		base.ParentID,
+		base.Region,
 	).Scan(&id)
 	if err != nil {
 		return
@@ -67,7 +71,7 @@ func (pg *PostgreSQL) createSystemTables(schema string) error {
 			email TEXT UNIQUE NOT NULL,
 			created timestamp NOT NULL
 		);
-		
+
 		CREATE TABLE IF NOT EXISTS {schema}.sb_tokens (
 			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
 			account_id uuid REFERENCES {schema}.sb_accounts(id) ON DELETE CASCADE,
@@ -93,7 +97,7 @@ func (pg *PostgreSQL) createSystemTables(schema string) error {
 			key TEXT UNIQUE NOT NULL,
 			url TEXT NOT NULL,
 			size INTEGER NOT NULL,			
-			uploaded timestamp NOT NULL			
+			uploaded timestamp NOT NULL
 		);
 		CREATE INDEX IF NOT EXISTS sb_files_acctid_idx ON {schema}.sb_files (account_id);
 
@@ -133,7 +137,7 @@ func (pg *PostgreSQL) createSystemTables(schema string) error {
 		return err
 	}
 
-	return nil
+	return pg.upgradeSystemTables(schema)
 }
 
 func (pg *PostgreSQL) EmailExists(email string) (bool, error) {
@@ -214,6 +218,74 @@ func (pg *PostgreSQL) IncrementMonthlyEmailSent(baseID string) error {
 	return err
 }
 
+func (pg *PostgreSQL) IncrementEmailUsage(tenantID, month string) (sent int, err error) {
+	err = pg.DB.QueryRow(`
+		INSERT INTO sb.email_usage(customer_id, month, sent)
+		VALUES($1, $2, 1)
+		ON CONFLICT(customer_id, month) DO UPDATE SET sent = sb.email_usage.sent + 1
+		RETURNING sent;
+	`, tenantID, month).Scan(&sent)
+	return
+}
+
+func (pg *PostgreSQL) GetEmailUsage(tenantID, month string) (sent int, err error) {
+	err = pg.DB.QueryRow(`
+		SELECT COALESCE(SUM(sent), 0) 
+		FROM sb.email_usage 
+		WHERE customer_id = $1 AND month = $2
+	`, tenantID, month).Scan(&sent)
+	return
+}
+
+func (pg *PostgreSQL) UpdateDatabaseSettings(baseID string, settings model.AppSettings) error {
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = pg.DB.Exec(`
+		UPDATE sb.apps SET
+			settings = $2
+		WHERE id = $1
+	`, baseID, b)
+
+	return err
+}
+
+func (pg *PostgreSQL) SuspendDatabase(baseID, reason string) error {
+	_, err := pg.DB.Exec(`
+		UPDATE sb.apps SET
+			suspended_reason = $2
+		WHERE id = $1
+	`, baseID, reason)
+	return err
+}
+
+func (pg *PostgreSQL) ListEnvironments(parentID string) (results []model.DatabaseConfig, err error) {
+	rows, err := pg.DB.Query(`
+		SELECT * 
+		FROM sb.apps 
+		WHERE parent_id = $1
+		ORDER BY created
+	`, parentID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var base model.DatabaseConfig
+		if err = scanBase(rows, &base); err != nil {
+			return
+		}
+
+		results = append(results, base)
+	}
+
+	err = rows.Err()
+	return
+}
+
 func (pg *PostgreSQL) GetTenantByStripeID(stripeID string) (cus model.Tenant, err error) {
 	row := pg.DB.QueryRow(`
 		SELECT * 
@@ -260,6 +332,13 @@ func (pg *PostgreSQL) ChangeTenantPlan(tenantID string, plan int) error {
 	return nil
 }
 
+func (pg *PostgreSQL) SetTenantSubscription(tenantID, subscriptionID string) error {
+	if _, err := pg.DB.Exec(`UPDATE sb.customers SET sub_id = $2 WHERE id = $1`, tenantID, subscriptionID); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (pg *PostgreSQL) EnableExternalLogin(tenantID string, config map[string]model.OAuthConfig) error {
 	b, err := model.EncryptExternalLogins(config)
 	if err != nil {
@@ -294,6 +373,22 @@ func (pg *PostgreSQL) DeleteTenant(dbName, email string) error {
 	return err
 }
 
+func (pg *PostgreSQL) DeleteDatabase(baseID string) error {
+	base, err := pg.FindDatabase(baseID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := pg.DB.Exec(fmt.Sprintf(`DROP SCHEMA IF EXISTS %s CASCADE;`, base.Name)); err != nil {
+		return err
+	}
+
+	_, err = pg.DB.Exec(`
+		DELETE FROM sb.apps WHERE id = $1;
+	`, baseID)
+	return err
+}
+
 func scanCustomer(rows Scanner, c *model.Tenant) error {
 	return rows.Scan(
 		&c.ID,
@@ -308,7 +403,8 @@ func scanCustomer(rows Scanner, c *model.Tenant) error {
 }
 
 func scanBase(rows Scanner, b *model.DatabaseConfig) error {
-	return rows.Scan(
+	var settings []byte
+	err := rows.Scan(
 		&b.ID,
 		&b.TenantID,
 		&b.Name,
@@ -316,7 +412,17 @@ func scanBase(rows Scanner, b *model.DatabaseConfig) error {
 		&b.IsActive,
 		&b.MonthlySentEmail,
 		&b.Created,
+		&settings,
+		&b.SuspendedReason,
+		&b.Environment,
+		&b.ParentID,
+		&b.Region,
 	)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(settings, &b.Settings)
 }
 
 func (pg *PostgreSQL) GetAllDatabaseSizes() error {
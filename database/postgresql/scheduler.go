@@ -1,7 +1,9 @@
 package postgresql
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/staticbackendhq/core/model"
 )
@@ -15,7 +17,10 @@ func (pg *PostgreSQL) ListTasks() (results []model.Task, err error) {
 	for _, base := range bases {
 		tasks, err := pg.ListTasksByBase(base.Name)
 		if err != nil {
-			return results, err
+			// one database's tasks failing to load should not stop the
+			// others from being scheduled
+			pg.log.Error().Err(err).Msgf("unable to list the tasks of %s", base.Name)
+			continue
 		}
 
 		results = append(results, tasks...)
@@ -42,6 +47,8 @@ func (pg *PostgreSQL) ListTasksByBase(dbName string) (results []model.Task, err
 			return
 		}
 
+		t.BaseName = dbName
+
 		results = append(results, t)
 	}
 
@@ -49,10 +56,30 @@ func (pg *PostgreSQL) ListTasksByBase(dbName string) (results []model.Task, err
 	return
 }
 
+func (pg *PostgreSQL) GetTaskByID(dbName, id string) (task model.Task, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s.sb_tasks 
+		WHERE id = $1
+	`, dbName)
+
+	if err = scanTask(pg.DB.QueryRow(qry, id), &task); err != nil {
+		return
+	}
+
+	task.BaseName = dbName
+	return
+}
+
 func (pg *PostgreSQL) AddTask(dbName string, task model.Task) (id string, err error) {
+	retry, err := json.Marshal(task.Retry)
+	if err != nil {
+		return
+	}
+
 	qry := fmt.Sprintf(`
-	INSERT INTO %s.sb_tasks(id, name, type, value, meta, interval, last_run)
-	VALUES($1, $2, $3, $4, $5, $6, $7);
+	INSERT INTO %s.sb_tasks(id, name, type, value, meta, interval, last_run, run_at, retry, enabled, after)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
 	`, dbName)
 
 	id = pg.NewID()
@@ -60,16 +87,49 @@ func (pg *PostgreSQL) AddTask(dbName string, task model.Task) (id string, err er
 	_, err = pg.DB.Exec(
 		qry,
 		id,
-		task.ID,
+		task.Name,
 		task.Type,
 		task.Value,
 		task.Meta,
 		task.Interval,
 		task.LastRun,
+		task.RunAt,
+		string(retry),
+		task.Enabled,
+		task.After,
 	)
 	return
 }
 
+func (pg *PostgreSQL) UpdateTask(dbName, id string, task model.Task) error {
+	retry, err := json.Marshal(task.Retry)
+	if err != nil {
+		return err
+	}
+
+	qry := fmt.Sprintf(`
+	UPDATE %s.sb_tasks
+	SET name = $2, type = $3, value = $4, meta = $5, interval = $6,
+		run_at = $7, retry = $8, enabled = $9, after = $10
+	WHERE id = $1;
+	`, dbName)
+
+	_, err = pg.DB.Exec(
+		qry,
+		id,
+		task.Name,
+		task.Type,
+		task.Value,
+		task.Meta,
+		task.Interval,
+		task.RunAt,
+		string(retry),
+		task.Enabled,
+		task.After,
+	)
+	return err
+}
+
 func (sl *PostgreSQL) DeleteTask(dbName, id string) error {
 	qry := fmt.Sprintf(`
 	DELETE FROM %s.sb_tasks
@@ -82,8 +142,35 @@ func (sl *PostgreSQL) DeleteTask(dbName, id string) error {
 	return nil
 }
 
+func (pg *PostgreSQL) UpdateTaskRun(dbName, id string, lastRun time.Time, status, lastError string) error {
+	qry := fmt.Sprintf(`
+	UPDATE %s.sb_tasks
+	SET last_run = $2, last_status = $3, last_error = $4
+	WHERE id = $1;
+	`, dbName)
+
+	if _, err := pg.DB.Exec(qry, id, lastRun, status, lastError); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pg *PostgreSQL) SetTaskEnabled(dbName, id string, enabled bool) error {
+	qry := fmt.Sprintf(`
+	UPDATE %s.sb_tasks
+	SET enabled = $2
+	WHERE id = $1;
+	`, dbName)
+
+	if _, err := pg.DB.Exec(qry, id, enabled); err != nil {
+		return err
+	}
+	return nil
+}
+
 func scanTask(rows Scanner, t *model.Task) error {
-	return rows.Scan(
+	var retry string
+	err := rows.Scan(
 		&t.ID,
 		&t.Name,
 		&t.Type,
@@ -91,5 +178,16 @@ func scanTask(rows Scanner, t *model.Task) error {
 		&t.Meta,
 		&t.Interval,
 		&t.LastRun,
+		&t.LastStatus,
+		&t.LastError,
+		&t.RunAt,
+		&retry,
+		&t.Enabled,
+		&t.After,
 	)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(retry), &t.Retry)
 }
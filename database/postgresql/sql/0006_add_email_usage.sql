@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS sb.email_usage (
+	customer_id uuid REFERENCES sb.customers(id) ON DELETE CASCADE,
+	month TEXT NOT NULL,
+	sent INTEGER NOT NULL,
+	PRIMARY KEY (customer_id, month)
+);
\ No newline at end of file
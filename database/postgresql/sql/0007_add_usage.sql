@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS sb.usage (
+	tenant_id TEXT NOT NULL,
+	db_name TEXT NOT NULL,
+	day TEXT NOT NULL,
+	metric TEXT NOT NULL,
+	value BIGINT NOT NULL,
+	PRIMARY KEY (tenant_id, db_name, day, metric)
+);
\ No newline at end of file
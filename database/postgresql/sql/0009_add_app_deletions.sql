@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS sb.app_deletions (
+	id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+	base_id TEXT NOT NULL,
+	tenant_id TEXT NOT NULL,
+	db_name TEXT NOT NULL,
+	email TEXT NOT NULL,
+	status TEXT NOT NULL,
+	requested timestamp NOT NULL,
+	scheduled timestamp NOT NULL,
+	completed timestamp NOT NULL,
+	certificate TEXT NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS sb.backups (
+	id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+	tenant_id TEXT NOT NULL,
+	db_name TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	file_key TEXT NOT NULL,
+	size BIGINT NOT NULL,
+	documents BIGINT NOT NULL,
+	created timestamp NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS sb.admin_audit (
+	id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+	type TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	tenant_id TEXT NOT NULL,
+	db_name TEXT NOT NULL,
+	target TEXT NOT NULL,
+	detail TEXT NOT NULL,
+	ip TEXT NOT NULL,
+	user_agent TEXT NOT NULL,
+	created timestamp NOT NULL
+);
+
+CREATE OR REPLACE FUNCTION sb.admin_audit_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'the admin audit log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER admin_audit_append_only BEFORE UPDATE OR DELETE ON sb.admin_audit
+FOR EACH ROW EXECUTE PROCEDURE sb.admin_audit_append_only();
\ No newline at end of file
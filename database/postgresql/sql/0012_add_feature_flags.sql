@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS sb.feature_flags (
+	tenant_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL,
+	updated timestamp NOT NULL,
+	PRIMARY KEY (tenant_id, name)
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE sb.apps
+ADD COLUMN environment TEXT NOT NULL DEFAULT '',
+ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS sb.secrets (
+	id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+	db_name TEXT NOT NULL,
+	name TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	value BYTEA NOT NULL,
+	data_key BYTEA NOT NULL,
+	key_id TEXT NOT NULL,
+	created timestamp NOT NULL,
+	UNIQUE (db_name, name, version)
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS sb.domains (
+	id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+	db_name TEXT NOT NULL,
+	base_id TEXT NOT NULL,
+	hostname TEXT NOT NULL UNIQUE,
+	token TEXT NOT NULL,
+	verified BOOLEAN NOT NULL,
+	created timestamp NOT NULL
+);
\ No newline at end of file
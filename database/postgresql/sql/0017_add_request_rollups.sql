@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS sb.request_rollups (
+	tenant_id TEXT NOT NULL,
+	db_name TEXT NOT NULL,
+	hour TEXT NOT NULL,
+	endpoint TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	country TEXT NOT NULL,
+	count INTEGER NOT NULL,
+	total_ms INTEGER NOT NULL,
+	max_ms INTEGER NOT NULL,
+	PRIMARY KEY (db_name, hour, endpoint, status, country)
+);
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS sb.ownership_transfers (
+	id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+	base_id TEXT NOT NULL,
+	from_tenant_id TEXT NOT NULL,
+	to_tenant_id TEXT NOT NULL,
+	to_email TEXT NOT NULL,
+	whole_account boolean NOT NULL,
+	status TEXT NOT NULL,
+	created timestamp NOT NULL,
+	expires timestamp NOT NULL,
+	completed timestamp NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sb_ownership_transfers_from_idx ON sb.ownership_transfers (from_tenant_id);
+CREATE INDEX IF NOT EXISTS sb_ownership_transfers_to_idx ON sb.ownership_transfers (to_tenant_id);
@@ -1,15 +1,23 @@
 package postgresql
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/lib/pq"
 	"github.com/staticbackendhq/core/model"
 )
 
 func (pg *PostgreSQL) AddFile(dbName string, f model.File) (id string, err error) {
+	variants, err := marshalVariants(f.Variants)
+	if err != nil {
+		return
+	}
+
 	qry := fmt.Sprintf(`
-		INSERT INTO %s.sb_files(account_id, key, url, size, uploaded)
-		VALUES($1, $2, $3, $4, $5)
+		INSERT INTO %s.sb_files(account_id, key, url, size, uploaded, variants, name, mime_type, tags, collection, document_id, user_id)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id;
 	`, dbName)
 
@@ -20,6 +28,13 @@ func (pg *PostgreSQL) AddFile(dbName string, f model.File) (id string, err error
 		f.URL,
 		f.Size,
 		f.Uploaded,
+		variants,
+		f.Name,
+		f.MimeType,
+		pq.Array(tagsOrEmpty(f.Tags)),
+		f.Collection,
+		f.DocumentID,
+		f.UserID,
 	).Scan(&id)
 	return
 }
@@ -86,13 +101,136 @@ func (pg *PostgreSQL) ListAllFiles(dbName, accountID string) (results []model.Fi
 	return
 }
 
+func (pg *PostgreSQL) ListFiles(dbName string, f model.FileFilter) (results []model.File, err error) {
+	var clauses []string
+	var args []interface{}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(f.AccountID) > 0 {
+		add("account_id = $%d", f.AccountID)
+	}
+	if len(f.Name) > 0 {
+		add("name ILIKE $%d", "%"+f.Name+"%")
+	}
+	if len(f.MimeType) > 0 {
+		add("mime_type LIKE $%d", f.MimeType+"%")
+	}
+	if len(f.Tag) > 0 {
+		add("$%d = ANY(tags)", f.Tag)
+	}
+	if len(f.Collection) > 0 {
+		add("collection = $%d", f.Collection)
+	}
+	if len(f.DocumentID) > 0 {
+		add("document_id = $%d", f.DocumentID)
+	}
+	if len(f.UserID) > 0 {
+		add("user_id = $%d", f.UserID)
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = "WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	paging := ""
+	if f.Limit > 0 {
+		paging = fmt.Sprintf("LIMIT %d OFFSET %d", f.Limit, f.Offset)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s.sb_files
+		%s
+		ORDER BY uploaded DESC
+		%s
+	`, dbName, where, paging)
+
+	rows, err := pg.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var file model.File
+		if err = scanFile(rows, &file); err != nil {
+			return
+		}
+
+		results = append(results, file)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) LinkFile(dbName, fileID string, link model.FileLink) error {
+	qry := fmt.Sprintf(`
+		UPDATE %s.sb_files SET
+			collection = $2,
+			document_id = $3,
+			user_id = $4
+		WHERE id = $1
+	`, dbName)
+
+	_, err := pg.DB.Exec(qry, fileID, link.Collection, link.DocumentID, link.UserID)
+	return err
+}
+
+func (pg *PostgreSQL) StorageUsage(dbName string) (usage model.StorageUsage, err error) {
+	qry := fmt.Sprintf(`
+		SELECT COUNT(*), 
+			COALESCE(SUM(size), 0) + COALESCE(SUM((
+				SELECT SUM((v->>'size')::bigint) FROM jsonb_array_elements(variants) v
+			)), 0)
+		FROM %s.sb_files
+	`, dbName)
+
+	err = pg.DB.QueryRow(qry).Scan(&usage.Files, &usage.Bytes)
+	return
+}
+
+// tagsOrEmpty prevents storing NULL tags
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// marshalVariants returns the JSON of the variants, an empty array if nil
+func marshalVariants(variants []model.FileVariant) ([]byte, error) {
+	if variants == nil {
+		variants = []model.FileVariant{}
+	}
+	return json.Marshal(variants)
+}
+
 func scanFile(rows Scanner, f *model.File) error {
-	return rows.Scan(
+	var variants []byte
+	err := rows.Scan(
 		&f.ID,
 		&f.AccountID,
 		&f.Key,
 		&f.URL,
 		&f.Size,
 		&f.Uploaded,
+		&variants,
+		&f.Name,
+		&f.MimeType,
+		pq.Array(&f.Tags),
+		&f.Collection,
+		&f.DocumentID,
+		&f.UserID,
 	)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(variants, &f.Variants)
 }
@@ -0,0 +1,75 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddTaskRun(dbName string, run model.TaskRun) (id string, err error) {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s.sb_task_runs(id, task_id, attempt, started, completed, success, output, error)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+	`, dbName)
+
+	id = pg.NewID()
+
+	_, err = pg.DB.Exec(
+		qry,
+		id,
+		run.TaskID,
+		run.Attempt,
+		run.Started,
+		run.Completed,
+		run.Success,
+		run.Output,
+		run.Error,
+	)
+	return
+}
+
+func (pg *PostgreSQL) ListTaskRuns(dbName, taskID string, limit int64) (results []model.TaskRun, err error) {
+	lim := ""
+	if limit > 0 {
+		lim = fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s.sb_task_runs 
+		WHERE task_id = $1
+		ORDER BY started DESC
+		%s
+	`, dbName, lim)
+
+	rows, err := pg.DB.Query(qry, taskID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run model.TaskRun
+		if err = scanTaskRun(rows, &run); err != nil {
+			return
+		}
+
+		results = append(results, run)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func scanTaskRun(rows Scanner, run *model.TaskRun) error {
+	return rows.Scan(
+		&run.ID,
+		&run.TaskID,
+		&run.Attempt,
+		&run.Started,
+		&run.Completed,
+		&run.Success,
+		&run.Output,
+		&run.Error,
+	)
+}
@@ -0,0 +1,106 @@
+package postgresql
+
+import (
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (pg *PostgreSQL) AddOwnershipTransfer(t model.OwnershipTransfer) (id string, err error) {
+	err = pg.DB.QueryRow(`
+		INSERT INTO sb.ownership_transfers(base_id, from_tenant_id, to_tenant_id, to_email, whole_account, status, created, expires, completed)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id;
+	`,
+		t.BaseID,
+		t.FromTenantID,
+		t.ToTenantID,
+		t.ToEmail,
+		t.WholeAccount,
+		model.TransferPending,
+		t.Created,
+		t.Expires,
+		time.Time{},
+	).Scan(&id)
+	return
+}
+
+func (pg *PostgreSQL) GetOwnershipTransfer(id string) (t model.OwnershipTransfer, err error) {
+	row := pg.DB.QueryRow(`
+		SELECT * 
+		FROM sb.ownership_transfers 
+		WHERE id = $1
+	`, id)
+
+	err = scanOwnershipTransfer(row, &t)
+	return
+}
+
+func (pg *PostgreSQL) ListOwnershipTransfers(tenantID string) (results []model.OwnershipTransfer, err error) {
+	rows, err := pg.DB.Query(`
+		SELECT * 
+		FROM sb.ownership_transfers 
+		WHERE from_tenant_id = $1 OR to_tenant_id = $1
+		ORDER BY created DESC
+	`, tenantID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t model.OwnershipTransfer
+		if err = scanOwnershipTransfer(rows, &t); err != nil {
+			return
+		}
+
+		results = append(results, t)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (pg *PostgreSQL) CompleteOwnershipTransfer(id, status string, completed time.Time) error {
+	res, err := pg.DB.Exec(`
+		UPDATE sb.ownership_transfers SET
+			status = $2,
+			completed = $3
+		WHERE id = $1 AND status = $4
+	`, id, status, completed, model.TransferPending)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errors.New("the transfer is not pending")
+	}
+	return nil
+}
+
+func (pg *PostgreSQL) SetDatabaseTenant(baseID, tenantID string) error {
+	_, err := pg.DB.Exec(`
+		UPDATE sb.apps SET
+			customer_id = $2
+		WHERE id = $1
+	`, baseID, tenantID)
+	return err
+}
+
+func scanOwnershipTransfer(rows Scanner, t *model.OwnershipTransfer) error {
+	return rows.Scan(
+		&t.ID,
+		&t.BaseID,
+		&t.FromTenantID,
+		&t.ToTenantID,
+		&t.ToEmail,
+		&t.WholeAccount,
+		&t.Status,
+		&t.Created,
+		&t.Expires,
+		&t.Completed,
+	)
+}
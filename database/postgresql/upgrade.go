@@ -0,0 +1,222 @@
+package postgresql
+
+import "strings"
+
+// upgradeTenants upgrades the system tables of all databases, the ones
+// created before a system table or column was added miss it. A database
+// failing to upgrade is logged and skipped.
+func (pg *PostgreSQL) upgradeTenants() error {
+	rows, err := pg.DB.Query(`
+		SELECT name 
+		FROM sb.apps
+	`)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+
+		names = append(names, name)
+	}
+	rows.Close()
+
+	for _, name := range names {
+		if err := pg.upgradeSystemTables(name); err != nil {
+			pg.log.Error().Err(err).Msgf("unable to upgrade the system tables of %s", name)
+		}
+	}
+	return nil
+}
+
+// upgradeSystemTables adds the system columns and tables missing from a
+// database, the columns are added in order since SELECT * depends on it
+func (pg *PostgreSQL) upgradeSystemTables(schema string) error {
+	qry := strings.Replace(`
+		ALTER TABLE {schema}.sb_files
+			ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]',
+			ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS mime_type TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+			ADD COLUMN IF NOT EXISTS collection TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS document_id TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS user_id TEXT NOT NULL DEFAULT '';
+		CREATE INDEX IF NOT EXISTS sb_files_document_idx ON {schema}.sb_files (collection, document_id);
+
+		ALTER TABLE {schema}.sb_tasks
+			ADD COLUMN IF NOT EXISTS last_status TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS last_error TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS run_at timestamp NOT NULL DEFAULT '0001-01-01 00:00:00',
+			ADD COLUMN IF NOT EXISTS retry TEXT NOT NULL DEFAULT '{}',
+			ADD COLUMN IF NOT EXISTS enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			ADD COLUMN IF NOT EXISTS after TEXT NOT NULL DEFAULT '';
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_form_deliveries (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			form TEXT NOT NULL,
+			url TEXT NOT NULL,
+			attempt INTEGER NOT NULL,
+			status_code INTEGER NOT NULL,
+			success BOOLEAN NOT NULL,
+			error TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_form_deliveries_form_idx ON {schema}.sb_form_deliveries (form, created);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_form_definitions (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			name TEXT UNIQUE NOT NULL,
+			fields TEXT NOT NULL,
+			updated timestamp NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_email_templates (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			name TEXT UNIQUE NOT NULL,
+			subject TEXT NOT NULL,
+			html_body TEXT NOT NULL,
+			text_body TEXT NOT NULL,
+			variables TEXT NOT NULL,
+			updated timestamp NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_user_locales (
+			user_id uuid PRIMARY KEY REFERENCES {schema}.sb_tokens(id) ON DELETE CASCADE,
+			locale TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_email_queue (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			from_email TEXT NOT NULL,
+			from_name TEXT NOT NULL,
+			to_email TEXT NOT NULL,
+			to_name TEXT NOT NULL,
+			reply_to TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			template TEXT NOT NULL,
+			html_body TEXT NOT NULL,
+			text_body TEXT NOT NULL,
+			attachments TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL,
+			last_error TEXT NOT NULL,
+			provider_id TEXT NOT NULL,
+			next_attempt timestamp NOT NULL,
+			created timestamp NOT NULL,
+			updated timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_email_queue_due_idx ON {schema}.sb_email_queue (status, next_attempt);
+		CREATE INDEX IF NOT EXISTS sb_email_queue_to_idx ON {schema}.sb_email_queue (to_email, created);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_email_events (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			email TEXT NOT NULL,
+			type TEXT NOT NULL,
+			permanent BOOLEAN NOT NULL,
+			provider TEXT NOT NULL,
+			detail TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_email_events_email_idx ON {schema}.sb_email_events (email, created);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_email_suppressions (
+			email TEXT PRIMARY KEY,
+			reason TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			detail TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_webhook_deliveries (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			url TEXT NOT NULL,
+			event TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL,
+			status_code INTEGER NOT NULL,
+			last_error TEXT NOT NULL,
+			next_attempt timestamp NOT NULL,
+			created timestamp NOT NULL,
+			updated timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_webhook_deliveries_due_idx ON {schema}.sb_webhook_deliveries (status, next_attempt);
+		CREATE INDEX IF NOT EXISTS sb_webhook_deliveries_created_idx ON {schema}.sb_webhook_deliveries (created);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_task_runs (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			task_id uuid REFERENCES {schema}.sb_tasks(id) ON DELETE CASCADE,
+			attempt INTEGER NOT NULL,
+			started timestamp NOT NULL,
+			completed timestamp NOT NULL,
+			success BOOLEAN NOT NULL,
+			output TEXT NOT NULL,
+			error TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_task_runs_task_idx ON {schema}.sb_task_runs (task_id, started);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_audit (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			account_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			type TEXT NOT NULL,
+			detail TEXT NOT NULL,
+			ip TEXT NOT NULL,
+			user_agent TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_audit_created_idx ON {schema}.sb_audit (created);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_invites (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			account_id uuid REFERENCES {schema}.sb_accounts(id) ON DELETE CASCADE,
+			email TEXT NOT NULL,
+			role INTEGER NOT NULL,
+			invited_by TEXT NOT NULL,
+			created timestamp NOT NULL,
+			expires timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_invites_acctid_idx ON {schema}.sb_invites (account_id);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_push (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			account_id uuid REFERENCES {schema}.sb_accounts(id) ON DELETE CASCADE,
+			user_id uuid REFERENCES {schema}.sb_tokens(id) ON DELETE CASCADE,
+			channel TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			endpoint TEXT NOT NULL,
+			p256dh TEXT NOT NULL,
+			auth TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_push_channel_idx ON {schema}.sb_push (channel);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_events (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+			name TEXT NOT NULL,
+			session_id TEXT NOT NULL,
+			properties JSONB NOT NULL,
+			timestamp timestamp NOT NULL,
+			received timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS sb_events_received_idx ON {schema}.sb_events (received);
+		CREATE INDEX IF NOT EXISTS sb_events_name_idx ON {schema}.sb_events (name, received);
+
+		CREATE TABLE IF NOT EXISTS {schema}.sb_event_rollups (
+			day TEXT NOT NULL,
+			name TEXT NOT NULL,
+			count BIGINT NOT NULL,
+			PRIMARY KEY (day, name)
+		);
+	
+	`, "{schema}", schema, -1)
+
+	_, err := pg.DB.Exec(qry)
+	return err
+}
@@ -0,0 +1,261 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+// legacySystemTables is the system tables DDL databases were created with
+// before the system columns and tables were added
+const legacySystemTables = `
+	CREATE TABLE IF NOT EXISTS {schema}.sb_accounts (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+		email TEXT UNIQUE NOT NULL,
+		created timestamp NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS {schema}.sb_tokens (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+		account_id uuid REFERENCES {schema}.sb_accounts(id) ON DELETE CASCADE,
+		token TEXT UNIQUE NOT NULL,
+		email TEXT UNIQUE NOT NULL,
+		password TEXT NOT NULL,
+		role INTEGER NOT NULL,
+		reset_code TEXT NOT NULL,
+		created timestamp NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS {schema}.sb_forms (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+		name TEXT NOT NULL,
+		data JSONB NOT NULL,
+		created timestamp NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS sb_forms_name_idx ON {schema}.sb_forms (name);
+
+	CREATE TABLE IF NOT EXISTS {schema}.sb_files (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+		account_id uuid REFERENCES {schema}.sb_accounts(id) ON DELETE CASCADE,
+		key TEXT UNIQUE NOT NULL,
+		url TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		uploaded timestamp NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS sb_files_acctid_idx ON {schema}.sb_files (account_id);
+
+	CREATE TABLE IF NOT EXISTS {schema}.sb_functions (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+		function_name TEXT UNIQUE NOT NULL,
+		trigger_topic TEXT NOT NULL,
+		code TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		last_updated timestamp NOT NULL,
+		last_run timestamp NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS sb_functions_trigger_topic_idx ON {schema}.sb_functions (trigger_topic);
+
+	CREATE TABLE IF NOT EXISTS {schema}.sb_function_logs (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+		function_id uuid REFERENCES {schema}.sb_functions(id) ON DELETE CASCADE,
+		version INTEGER NOT NULL,
+		started timestamp NOT NULL,
+		completed timestamp NOT NULL,
+		success BOOLEAN NOT NULL,
+		output TEXT[] NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS {schema}.sb_tasks (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+		name TEXT UNIQUE NOT NULL,
+		type TEXT NOT NULL,
+		value TEXT NOT NULL,
+		meta TEXT NOT NULL,
+		interval TEXT NOT NULL,
+		last_run timestamp NOT NULL
+	);
+`
+
+func createLegacySchema(t *testing.T, schema string) {
+	t.Helper()
+
+	if _, err := datastore.DB.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := datastore.DB.Exec("DROP SCHEMA IF EXISTS " + schema + " CASCADE;"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	qry := strings.Replace(legacySystemTables, "{schema}", schema, -1)
+	if _, err := datastore.DB.Exec(qry); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgradeLegacyFiles(t *testing.T) {
+	const schema = "legacyfiles"
+	createLegacySchema(t, schema)
+
+	var acctID, legacyID string
+	err := datastore.DB.QueryRow(`
+		INSERT INTO legacyfiles.sb_accounts(email, created)
+		VALUES('legacy@test.com', $1)
+		RETURNING id
+	`, time.Now()).Scan(&acctID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = datastore.DB.QueryRow(`
+		INSERT INTO legacyfiles.sb_files(account_id, key, url, size, uploaded)
+		VALUES($1, 'legacy/file.txt', 'https://cdn/file.txt', 10, $2)
+		RETURNING id
+	`, acctID, time.Now()).Scan(&legacyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := datastore.upgradeSystemTables(schema); err != nil {
+		t.Fatal(err)
+	}
+	// the upgrade runs on every start
+	if err := datastore.upgradeSystemTables(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := datastore.GetFileByID(schema, legacyID)
+	if err != nil {
+		t.Fatal(err)
+	} else if legacy.Key != "legacy/file.txt" || len(legacy.Tags) != 0 {
+		t.Errorf("unexpected legacy file %v", legacy)
+	}
+
+	id, err := datastore.AddFile(schema, model.File{
+		AccountID: acctID,
+		Key:       "new/file.txt",
+		URL:       "https://cdn/new.txt",
+		Size:      20,
+		Uploaded:  time.Now(),
+		Name:      "new.txt",
+		Tags:      []string{"a"},
+		FileLink:  model.FileLink{Collection: "tasks", DocumentID: "doc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := datastore.ListFiles(schema, model.FileFilter{Collection: "tasks", DocumentID: "doc"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 || files[0].ID != id {
+		t.Errorf("expected the new file to be listed got %v", files)
+	}
+}
+
+func registerDatabase(t *testing.T, name string) {
+	t.Helper()
+
+	var id string
+	err := datastore.DB.QueryRow(`
+		INSERT INTO sb.apps(customer_id, name, allowed_domain, is_active, monthly_email_sent, created, environment, parent_id, region)
+		VALUES($1, $2, '{localhost}', true, 0, $3, '', '', '')
+		RETURNING id
+	`, dbTest.TenantID, name, time.Now()).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := datastore.DB.Exec("DELETE FROM sb.apps WHERE id = $1", id); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestUpgradeLegacyTasks(t *testing.T) {
+	const schema = "legacytasks"
+	createLegacySchema(t, schema)
+	registerDatabase(t, schema)
+	// a database without system tables fails to upgrade and list its tasks
+	registerDatabase(t, "brokentasks")
+
+	datastore.log = logger.Get(config.Current)
+	defer func() { datastore.log = nil }()
+
+	var legacyID string
+	err := datastore.DB.QueryRow(`
+		INSERT INTO legacytasks.sb_tasks(name, type, value, meta, interval, last_run)
+		VALUES('legacy', 'function', 'fn', '', '@every 1h', $1)
+		RETURNING id
+	`, time.Now()).Scan(&legacyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := datastore.upgradeTenants(); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := datastore.ListTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var legacy *model.Task
+	for i := range tasks {
+		if tasks[i].ID == legacyID {
+			legacy = &tasks[i]
+		}
+	}
+
+	if legacy == nil {
+		t.Fatalf("expected the legacy task to be listed got %v", tasks)
+	} else if !legacy.RunAt.IsZero() || !legacy.Enabled || legacy.IsOneOff() {
+		t.Errorf("expected a recurring enabled task got %v", legacy)
+	}
+}
+
+func TestUpgradeLegacyTables(t *testing.T) {
+	const schema = "legacytables"
+	createLegacySchema(t, schema)
+
+	if err := datastore.upgradeSystemTables(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	tables := []string{
+		"sb_form_deliveries",
+		"sb_form_definitions",
+		"sb_email_templates",
+		"sb_user_locales",
+		"sb_email_queue",
+		"sb_email_events",
+		"sb_email_suppressions",
+		"sb_webhook_deliveries",
+		"sb_task_runs",
+		"sb_audit",
+		"sb_invites",
+		"sb_push",
+		"sb_events",
+		"sb_event_rollups",
+	}
+	for _, table := range tables {
+		var count int
+		qry := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s", schema, table)
+		if err := datastore.DB.QueryRow(qry).Scan(&count); err != nil {
+			t.Errorf("expected %s to be created: %v", table, err)
+		}
+	}
+
+	if err := datastore.AddAuditEvent(schema, model.AuditEvent{Type: model.AuditLogin, Created: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestUsage(t *testing.T) {
+	records := []model.UsageRecord{
+		{TenantID: "usage-tenant", DBName: confDBName, Day: "2001-01-01", Metric: model.MeterAPICalls, Value: 3},
+		{TenantID: "usage-tenant", DBName: confDBName, Day: "2001-01-02", Metric: model.MeterAPICalls, Value: 1},
+		{TenantID: "usage-tenant", DBName: "otherdb", Day: "2001-01-02", Metric: model.MeterEmails, Value: 2},
+	}
+	if err := datastore.AddUsage(records); err != nil {
+		t.Fatal(err)
+	}
+
+	// the values are added to the stored ones
+	if err := datastore.AddUsage(records[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := datastore.ListUsage(model.UsageFilter{TenantID: "usage-tenant"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 3 {
+		t.Fatalf("expected 3 records got %v", list)
+	} else if list[0].Day != "2001-01-01" || list[0].Value != 6 {
+		t.Errorf("expected the first day to be 6 api calls got %v", list[0])
+	}
+
+	list, err = datastore.ListUsage(model.UsageFilter{
+		TenantID: "usage-tenant",
+		DBName:   confDBName,
+		Since:    "2001-01-02",
+		Until:    "2001-01-31",
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Value != 1 || list[0].Metric != model.MeterAPICalls {
+		t.Errorf("expected the second day's api calls got %v", list)
+	}
+}
@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddAppEvents(dbName string, events []model.AppEvent) (err error) {
+	tx, err := sl.DB.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	insert := fmt.Sprintf(`
+		INSERT INTO %s_sb_events(id, name, session_id, properties, timestamp, received)
+		VALUES($1, $2, $3, $4, $5, $6)
+	`, dbName)
+
+	rollup := fmt.Sprintf(`
+		INSERT INTO %s_sb_event_rollups(day, name, count)
+		VALUES($1, $2, 1)
+		ON CONFLICT(day, name) DO UPDATE SET count = count + 1
+	`, dbName)
+
+	for _, evt := range events {
+		var props []byte
+		props, err = json.Marshal(evt.Properties)
+		if err != nil {
+			return
+		}
+
+		if _, err = tx.Exec(insert, sl.NewID(), evt.Name, evt.SessionID, string(props), evt.Timestamp, evt.Received); err != nil {
+			return
+		}
+
+		if _, err = tx.Exec(rollup, evt.Received.UTC().Format("2006-01-02"), evt.Name); err != nil {
+			return
+		}
+	}
+
+	err = tx.Commit()
+	return
+}
+
+func (sl *SQLite) ListAppEvents(dbName string, f model.AppEventFilter) (results []model.AppEvent, err error) {
+	where, args := appEventWhere(f)
+
+	limit := ""
+	if f.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", f.Limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_events 
+		%s
+		ORDER BY received DESC
+		%s
+	`, dbName, where, limit)
+
+	rows, err := sl.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var evt model.AppEvent
+		if err = scanAppEvent(rows, &evt); err != nil {
+			return
+		}
+
+		results = append(results, evt)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) ListAppEventRollups(dbName string, f model.AppEventRollupFilter) (results []model.AppEventRollup, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_event_rollups 
+		WHERE ($1 = '' OR name = $1) 
+		AND ($2 = '' OR day >= $2) 
+		AND ($3 = '' OR day <= $3)
+		ORDER BY day, name
+	`, dbName)
+
+	rows, err := sl.DB.Query(qry, f.Name, f.Since, f.Until)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r model.AppEventRollup
+		if err = rows.Scan(&r.Day, &r.Name, &r.Count); err != nil {
+			return
+		}
+
+		results = append(results, r)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) PurgeAppEvents(dbName string, before time.Time) (int64, error) {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s_sb_events 
+		WHERE received < $1
+	`, dbName)
+
+	res, err := sl.DB.Exec(qry, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func appEventWhere(f model.AppEventFilter) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(f.Name) > 0 {
+		add("name = $%d", f.Name)
+	}
+	if len(f.SessionID) > 0 {
+		add("session_id = $%d", f.SessionID)
+	}
+	if !f.Since.IsZero() {
+		add("received >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("received <= $%d", f.Until)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func scanAppEvent(rows Scanner, evt *model.AppEvent) error {
+	var props string
+	err := rows.Scan(
+		&evt.ID,
+		&evt.Name,
+		&evt.SessionID,
+		&props,
+		&evt.Timestamp,
+		&evt.Received,
+	)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(props), &evt.Properties)
+}
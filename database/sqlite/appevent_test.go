@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAppEvents(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	events := []model.AppEvent{
+		{Name: "signup", SessionID: "s1", Properties: map[string]any{"plan": "free"}, Timestamp: old, Received: old},
+		{Name: "signup", SessionID: "s2", Properties: map[string]any{"plan": "pro"}, Timestamp: now, Received: now},
+		{Name: "checkout", SessionID: "s2", Properties: map[string]any{"total": float64(42)}, Timestamp: now, Received: now},
+	}
+	if err := datastore.AddAppEvents(confDBName, events); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rollups are added to
+	if err := datastore.AddAppEvents(confDBName, events[1:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := datastore.ListAppEvents(confDBName, model.AppEventFilter{Name: "signup"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 3 {
+		t.Fatalf("expected 3 signup events got %d", len(list))
+	} else if list[0].SessionID != "s2" || list[0].Properties["plan"] != "pro" {
+		t.Errorf("expected the most recent event first got %v", list[0])
+	}
+
+	list, err = datastore.ListAppEvents(confDBName, model.AppEventFilter{SessionID: "s2", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Errorf("expected 2 events of session s2 got %d", len(list))
+	}
+
+	today := now.Format("2006-01-02")
+	rollups, err := datastore.ListAppEventRollups(confDBName, model.AppEventRollupFilter{Since: today, Until: today})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(rollups) != 2 {
+		t.Fatalf("expected today's rollups of 2 events got %v", rollups)
+	} else if rollups[0].Name != "checkout" || rollups[0].Count != 1 {
+		t.Errorf("expected 1 checkout got %v", rollups[0])
+	} else if rollups[1].Name != "signup" || rollups[1].Count != 2 {
+		t.Errorf("expected 2 signups got %v", rollups[1])
+	}
+
+	n, err := datastore.PurgeAppEvents(confDBName, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Errorf("expected 1 purged event got %d", n)
+	}
+
+	// the rollups are kept once the events are purged
+	rollups, err = datastore.ListAppEventRollups(confDBName, model.AppEventRollupFilter{Name: "signup"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(rollups) != 2 {
+		t.Errorf("expected 2 days of signups got %v", rollups)
+	}
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddBackup(b model.Backup) (id string, err error) {
+	id = sl.NewID()
+
+	_, err = sl.DB.Exec(`
+		INSERT INTO sb_backups(id, tenant_id, db_name, task_id, file_key, size, documents, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		id,
+		b.TenantID,
+		b.DBName,
+		b.TaskID,
+		b.Key,
+		b.Size,
+		b.Documents,
+		b.Created,
+	)
+	return
+}
+
+func (sl *SQLite) ListBackups(dbName string) (results []model.Backup, err error) {
+	rows, err := sl.DB.Query(`
+		SELECT * 
+		FROM sb_backups 
+		WHERE db_name = $1
+		ORDER BY created DESC
+	`, dbName)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b model.Backup
+		if err = scanBackup(rows, &b); err != nil {
+			return
+		}
+
+		results = append(results, b)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) GetBackup(id string) (b model.Backup, err error) {
+	row := sl.DB.QueryRow(`
+		SELECT * 
+		FROM sb_backups 
+		WHERE id = $1
+	`, id)
+
+	err = scanBackup(row, &b)
+	return
+}
+
+func (sl *SQLite) DeleteBackup(id string) error {
+	_, err := sl.DB.Exec(`
+		DELETE FROM sb_backups 
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func scanBackup(rows Scanner, b *model.Backup) error {
+	return rows.Scan(
+		&b.ID,
+		&b.TenantID,
+		&b.DBName,
+		&b.TaskID,
+		&b.Key,
+		&b.Size,
+		&b.Documents,
+		&b.Created,
+	)
+}
@@ -306,7 +306,7 @@ func (sl *SQLite) UpdateDocument(auth model.Auth, dbName, col, id string, doc ma
 
 	updated, err := sl.GetDocumentByID(auth, dbName, col, id)
 	if err != nil {
-		fmt.Println("DEBUG: in getbyid", err)
+		sl.log.Error().Err(err).Str("id", id).Msg("error fetching the updated document")
 		return nil, err
 	}
 
@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddAppDeletion(d model.AppDeletion) (id string, err error) {
+	id = sl.NewID()
+
+	_, err = sl.DB.Exec(`
+		INSERT INTO sb_app_deletions(id, base_id, tenant_id, db_name, email, status, requested, scheduled, completed, certificate)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		id,
+		d.BaseID,
+		d.TenantID,
+		d.DBName,
+		d.Email,
+		model.AppDeletionPending,
+		d.Requested,
+		d.Scheduled,
+		time.Time{},
+		"",
+	)
+	return
+}
+
+func (sl *SQLite) GetAppDeletion(baseID string) (d model.AppDeletion, err error) {
+	row := sl.DB.QueryRow(`
+		SELECT * 
+		FROM sb_app_deletions 
+		WHERE base_id = $1 AND status = $2
+	`, baseID, model.AppDeletionPending)
+
+	err = scanAppDeletion(row, &d)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AppDeletion{}, nil
+	}
+	return
+}
+
+func (sl *SQLite) ListDueAppDeletions(now time.Time) (results []model.AppDeletion, err error) {
+	rows, err := sl.DB.Query(`
+		SELECT * 
+		FROM sb_app_deletions 
+		WHERE status = $1 AND scheduled <= $2
+		ORDER BY scheduled
+	`, model.AppDeletionPending, now)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.AppDeletion
+		if err = scanAppDeletion(rows, &d); err != nil {
+			return
+		}
+
+		results = append(results, d)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) CompleteAppDeletion(id string, completed time.Time, certificate string) error {
+	_, err := sl.DB.Exec(`
+		UPDATE sb_app_deletions SET
+			status = $2,
+			completed = $3,
+			certificate = $4
+		WHERE id = $1
+	`, id, model.AppDeletionCompleted, completed, certificate)
+	return err
+}
+
+func (sl *SQLite) CancelAppDeletion(id string) error {
+	_, err := sl.DB.Exec(`
+		DELETE FROM sb_app_deletions 
+		WHERE id = $1 AND status = $2
+	`, id, model.AppDeletionPending)
+	return err
+}
+
+func scanAppDeletion(rows Scanner, d *model.AppDeletion) error {
+	return rows.Scan(
+		&d.ID,
+		&d.BaseID,
+		&d.TenantID,
+		&d.DBName,
+		&d.Email,
+		&d.Status,
+		&d.Requested,
+		&d.Scheduled,
+		&d.Completed,
+		&d.Certificate,
+	)
+}
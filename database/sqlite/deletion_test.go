@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAppDeletion(t *testing.T) {
+	base, err := datastore.CreateDatabase(model.DatabaseConfig{
+		ID:       datastore.NewID(),
+		TenantID: dbTest.TenantID,
+		Name:     "deletiontest",
+		IsActive: true,
+		Created:  time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	d := model.AppDeletion{
+		BaseID:    base.ID,
+		TenantID:  base.TenantID,
+		DBName:    base.Name,
+		Email:     "deletion@test.com",
+		Requested: now,
+		Scheduled: now.Add(time.Hour),
+	}
+
+	id, err := datastore.AddAppDeletion(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := datastore.GetAppDeletion(base.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if pending.ID != id || pending.Status != model.AppDeletionPending {
+		t.Fatalf("expected the pending deletion %s got %v", id, pending)
+	}
+
+	due, err := datastore.ListDueAppDeletions(now)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(due) != 0 {
+		t.Errorf("expected no due deletion during the grace period got %v", due)
+	}
+
+	if err := datastore.CancelAppDeletion(id); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err = datastore.GetAppDeletion(base.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(pending.ID) > 0 {
+		t.Fatalf("expected the deletion to be cancelled got %v", pending)
+	}
+
+	id, err = datastore.AddAppDeletion(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	due, err = datastore.ListDueAppDeletions(now.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(due) != 1 || due[0].ID != id {
+		t.Fatalf("expected the deletion %s to be due got %v", id, due)
+	}
+
+	if err := datastore.DeleteDatabase(base.ID); err != nil {
+		t.Fatal(err)
+	} else if err := datastore.CompleteAppDeletion(id, time.Now(), "certificate"); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, err := datastore.DatabaseExists(base.Name); err != nil {
+		t.Fatal(err)
+	} else if exists {
+		t.Error("expected the database to be deleted")
+	}
+
+	due, err = datastore.ListDueAppDeletions(now.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(due) != 0 {
+		t.Errorf("expected the completed deletion to not be due got %v", due)
+	}
+}
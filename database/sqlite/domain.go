@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddDomain(d model.Domain) (id string, err error) {
+	id = sl.NewID()
+
+	_, err = sl.DB.Exec(`
+		INSERT INTO sb_domains(id, db_name, base_id, hostname, token, verified, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+	`,
+		id,
+		d.DBName,
+		d.BaseID,
+		d.Hostname,
+		d.Token,
+		d.Verified,
+		d.Created,
+	)
+	return
+}
+
+func (sl *SQLite) ListDomains(dbName string) (results []model.Domain, err error) {
+	rows, err := sl.DB.Query(`
+		SELECT * 
+		FROM sb_domains 
+		WHERE db_name = $1
+		ORDER BY hostname
+	`, dbName)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.Domain
+		if err = scanDomain(rows, &d); err != nil {
+			return
+		}
+
+		results = append(results, d)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) FindDomain(hostname string) (d model.Domain, err error) {
+	row := sl.DB.QueryRow(`
+		SELECT * 
+		FROM sb_domains 
+		WHERE hostname = $1
+	`, hostname)
+
+	err = scanDomain(row, &d)
+	return
+}
+
+func (sl *SQLite) VerifyDomain(id string) error {
+	_, err := sl.DB.Exec(`
+		UPDATE sb_domains SET
+			verified = true
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func (sl *SQLite) DeleteDomain(dbName, hostname string) error {
+	_, err := sl.DB.Exec(`
+		DELETE FROM sb_domains 
+		WHERE db_name = $1 AND hostname = $2
+	`, dbName, hostname)
+	return err
+}
+
+func scanDomain(rows Scanner, d *model.Domain) error {
+	return rows.Scan(
+		&d.ID,
+		&d.DBName,
+		&d.BaseID,
+		&d.Hostname,
+		&d.Token,
+		&d.Verified,
+		&d.Created,
+	)
+}
@@ -0,0 +1,219 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) QueueEmail(dbName string, msg model.EmailMessage) (id string, err error) {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s_sb_email_queue(id, from_email, from_name, to_email, to_name, reply_to, subject, 
+			template, html_body, text_body, attachments, status, attempts, last_error, provider_id, 
+			next_attempt, created, updated)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	`, dbName)
+
+	attachments, err := json.Marshal(msg.Attachments)
+	if err != nil {
+		return
+	}
+
+	id = sl.NewID()
+	_, err = sl.DB.Exec(
+		qry,
+		id,
+		msg.From,
+		msg.FromName,
+		msg.To,
+		msg.ToName,
+		msg.ReplyTo,
+		msg.Subject,
+		msg.Template,
+		msg.HTMLBody,
+		msg.TextBody,
+		string(attachments),
+		msg.Status,
+		msg.Attempts,
+		msg.LastError,
+		msg.ProviderID,
+		msg.NextAttempt,
+		msg.Created,
+		msg.Updated,
+	)
+	return
+}
+
+func (sl *SQLite) GetEmailMessage(dbName, id string) (msg model.EmailMessage, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_email_queue 
+		WHERE id = $1
+	`, dbName)
+
+	err = scanEmailMessage(sl.DB.QueryRow(qry, id), &msg)
+	return
+}
+
+func (sl *SQLite) ListDueEmails(dbName string, now time.Time, limit int64) (results []model.EmailMessage, err error) {
+	lim := ""
+	if limit > 0 {
+		lim = fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_email_queue 
+		WHERE status = $1 AND next_attempt <= $2
+		ORDER BY next_attempt
+		%s
+	`, dbName, lim)
+
+	rows, err := sl.DB.Query(qry, model.EmailStatusQueued, now)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var msg model.EmailMessage
+		if err = scanEmailMessage(rows, &msg); err != nil {
+			return
+		}
+
+		results = append(results, msg)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) UpdateEmailStatus(dbName, id, status string, attempts int, lastError, providerID string, nextAttempt time.Time) error {
+	qry := fmt.Sprintf(`
+		UPDATE %s_sb_email_queue SET
+			status = $2,
+			attempts = $3,
+			last_error = $4,
+			provider_id = $5,
+			next_attempt = $6,
+			updated = $7
+		WHERE id = $1
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, id, status, attempts, lastError, providerID, nextAttempt, time.Now())
+	return err
+}
+
+func (sl *SQLite) ListEmailLog(dbName string, f model.EmailLogFilter) (results []model.EmailMessage, err error) {
+	where, args := emailLogWhere(f)
+
+	limit := ""
+	if f.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", f.Limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_email_queue 
+		%s
+		ORDER BY created DESC
+		%s
+	`, dbName, where, limit)
+
+	rows, err := sl.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var msg model.EmailMessage
+		if err = scanEmailMessage(rows, &msg); err != nil {
+			return
+		}
+
+		results = append(results, msg)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) CountEmails(dbName string, f model.EmailLogFilter) (count int64, err error) {
+	where, args := emailLogWhere(f)
+
+	qry := fmt.Sprintf(`
+		SELECT COUNT(*) 
+		FROM %s_sb_email_queue 
+		%s
+	`, dbName, where)
+
+	err = sl.DB.QueryRow(qry, args...).Scan(&count)
+	return
+}
+
+func emailLogWhere(f model.EmailLogFilter) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(f.To) > 0 {
+		add("to_email = $%d", f.To)
+	}
+	if len(f.Template) > 0 {
+		add("template = $%d", f.Template)
+	}
+	if len(f.Status) > 0 {
+		add("status = $%d", f.Status)
+	}
+	if len(f.Query) > 0 {
+		add("(to_email LIKE $%[1]d OR subject LIKE $%[1]d)", "%"+f.Query+"%")
+	}
+	if !f.Since.IsZero() {
+		add("created >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created <= $%d", f.Until)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func scanEmailMessage(rows Scanner, msg *model.EmailMessage) error {
+	var attachments string
+	err := rows.Scan(
+		&msg.ID,
+		&msg.From,
+		&msg.FromName,
+		&msg.To,
+		&msg.ToName,
+		&msg.ReplyTo,
+		&msg.Subject,
+		&msg.Template,
+		&msg.HTMLBody,
+		&msg.TextBody,
+		&attachments,
+		&msg.Status,
+		&msg.Attempts,
+		&msg.LastError,
+		&msg.ProviderID,
+		&msg.NextAttempt,
+		&msg.Created,
+		&msg.Updated,
+	)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(attachments), &msg.Attachments)
+}
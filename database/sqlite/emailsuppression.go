@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddEmailEvent(dbName string, e model.EmailEvent) error {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s_sb_email_events(id, email, type, permanent, provider, detail, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+	`, dbName)
+
+	_, err := sl.DB.Exec(
+		qry,
+		sl.NewID(),
+		strings.ToLower(e.Email),
+		e.Type,
+		e.Permanent,
+		e.Provider,
+		e.Detail,
+		e.Created,
+	)
+	return err
+}
+
+func (sl *SQLite) ListEmailEvents(dbName, email string, limit int64) (results []model.EmailEvent, err error) {
+	lim := ""
+	if limit > 0 {
+		lim = fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_email_events 
+		WHERE $1 = '' OR email = $1
+		ORDER BY created DESC
+		%s
+	`, dbName, lim)
+
+	rows, err := sl.DB.Query(qry, strings.ToLower(email))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e model.EmailEvent
+		if err = scanEmailEvent(rows, &e); err != nil {
+			return
+		}
+
+		results = append(results, e)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) ListEmailSuppressions(dbName string) (results []model.EmailSuppression, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_email_suppressions 
+		ORDER BY created DESC
+	`, dbName)
+
+	rows, err := sl.DB.Query(qry)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s model.EmailSuppression
+		if err = scanEmailSuppression(rows, &s); err != nil {
+			return
+		}
+
+		results = append(results, s)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) IsEmailSuppressed(dbName, email string) (bool, error) {
+	qry := fmt.Sprintf(`
+		SELECT COUNT(*) 
+		FROM %s_sb_email_suppressions 
+		WHERE email = $1
+	`, dbName)
+
+	var count int
+	if err := sl.DB.QueryRow(qry, strings.ToLower(email)).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (sl *SQLite) SuppressEmail(dbName string, s model.EmailSuppression) error {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s_sb_email_suppressions(email, reason, provider, detail, created)
+		VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT(email) DO NOTHING
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, strings.ToLower(s.Email), s.Reason, s.Provider, s.Detail, s.Created)
+	return err
+}
+
+func (sl *SQLite) RemoveEmailSuppression(dbName, email string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s_sb_email_suppressions 
+		WHERE email = $1
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, strings.ToLower(email))
+	return err
+}
+
+func scanEmailEvent(rows Scanner, e *model.EmailEvent) error {
+	return rows.Scan(
+		&e.ID,
+		&e.Email,
+		&e.Type,
+		&e.Permanent,
+		&e.Provider,
+		&e.Detail,
+		&e.Created,
+	)
+}
+
+func scanEmailSuppression(rows Scanner, s *model.EmailSuppression) error {
+	return rows.Scan(
+		&s.Email,
+		&s.Reason,
+		&s.Provider,
+		&s.Detail,
+		&s.Created,
+	)
+}
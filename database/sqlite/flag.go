@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) SetFeatureFlag(flag model.FeatureFlag) error {
+	_, err := sl.DB.Exec(`
+		INSERT INTO sb_feature_flags(tenant_id, name, enabled, updated)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT(tenant_id, name) DO UPDATE SET enabled = excluded.enabled, updated = excluded.updated
+	`, flag.TenantID, flag.Name, flag.Enabled, flag.Updated)
+	return err
+}
+
+func (sl *SQLite) ListFeatureFlags(tenantID string) (results []model.FeatureFlag, err error) {
+	rows, err := sl.DB.Query(`
+		SELECT tenant_id, name, enabled, updated 
+		FROM sb_feature_flags 
+		WHERE tenant_id = $1
+		ORDER BY name
+	`, tenantID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var flag model.FeatureFlag
+		if err = rows.Scan(&flag.TenantID, &flag.Name, &flag.Enabled, &flag.Updated); err != nil {
+			return
+		}
+
+		results = append(results, flag)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) DeleteFeatureFlag(tenantID, name string) error {
+	_, err := sl.DB.Exec(`
+		DELETE FROM sb_feature_flags 
+		WHERE tenant_id = $1 AND name = $2
+	`, tenantID, name)
+	return err
+}
@@ -2,7 +2,10 @@ package sqlite
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/staticbackendhq/core/model"
 )
 
 type FormData struct {
@@ -16,11 +19,11 @@ func (sl *SQLite) AddFormSubmission(dbName, form string, doc map[string]interfac
 	var jsonb JSON = doc
 
 	qry := fmt.Sprintf(`
-		INSERT INTO %s_sb_forms(name, data, created)
-		VALUES($1, $2, $3)
+		INSERT INTO %s_sb_forms(id, name, data, created)
+		VALUES($1, $2, $3, $4)
 	`, dbName)
 
-	if _, err := sl.DB.Exec(qry, form, jsonb, time.Now()); err != nil {
+	if _, err := sl.DB.Exec(qry, sl.NewID(), form, jsonb, time.Now()); err != nil {
 		return err
 	}
 	return nil
@@ -84,3 +87,156 @@ func (sl *SQLite) GetForms(dbName string) (results []string, err error) {
 	err = rows.Err()
 	return
 }
+
+func (sl *SQLite) EachFormSubmission(dbName, name string, since, until time.Time, fn func(doc map[string]interface{}) error) error {
+	where, args := formWhere(name, model.FormFilter{Since: since, Until: until})
+
+	qry := fmt.Sprintf(`
+		SELECT COALESCE(id, ''), data, created
+		FROM %s_sb_forms
+		%s
+		ORDER BY created
+	`, dbName, where)
+
+	rows, err := sl.DB.Query(qry, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		doc, err := scanFormSubmission(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (sl *SQLite) QueryFormSubmissions(dbName, name string, f model.FormFilter, params model.ListParams) (result model.PagedResult, err error) {
+	result.Page = params.Page
+	result.Size = params.Size
+
+	where, args := formWhere(name, f)
+
+	qry := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s_sb_forms
+		%s
+	`, dbName, where)
+
+	if err = sl.DB.QueryRow(qry, args...).Scan(&result.Total); err != nil {
+		return
+	}
+
+	sort := "ASC"
+	if params.SortDescending {
+		sort = "DESC"
+	}
+
+	qry = fmt.Sprintf(`
+		SELECT COALESCE(id, ''), data, created
+		FROM %s_sb_forms
+		%s
+		ORDER BY created %s
+		LIMIT %d OFFSET %d
+	`, dbName, where, sort, params.Size, (params.Page-1)*params.Size)
+
+	rows, err := sl.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	result.Results = make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var doc map[string]interface{}
+		if doc, err = scanFormSubmission(rows); err != nil {
+			return
+		}
+
+		result.Results = append(result.Results, doc)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) DeleteFormSubmission(dbName, name, id string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s_sb_forms
+		WHERE name = $1 AND id = $2
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, name, id)
+	return err
+}
+
+func (sl *SQLite) MarkFormSubmissionRead(dbName, name, id string, read bool) error {
+	// json_set returns text, the data is scanned as a blob
+	qry := fmt.Sprintf(`
+		UPDATE %s_sb_forms
+		SET data = CAST(json_set(data, '$.sb_read', json($1)) AS BLOB)
+		WHERE name = $2 AND id = $3
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, fmt.Sprintf("%t", read), name, id)
+	return err
+}
+
+// formWhere returns the WHERE clause of the submissions of a form matching
+// the filter
+func formWhere(name string, f model.FormFilter) (string, []interface{}) {
+	clauses := []string{"name = $1"}
+	args := []interface{}{name}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if !f.Since.IsZero() {
+		add("created >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created <= $%d", f.Until)
+	}
+	if f.Spam != nil {
+		add("COALESCE(json_extract(data, '$.sb_spam'), 0) = $%d", *f.Spam)
+	}
+	if f.Read != nil {
+		add("COALESCE(json_extract(data, '$.sb_read'), 0) = $%d", *f.Read)
+	}
+	if len(f.Search) > 0 {
+		add(`EXISTS (SELECT 1 FROM json_each(data) WHERE json_each.value LIKE $%d ESCAPE '\')`, "%"+escapeLike(f.Search)+"%")
+	}
+
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// escapeLike escapes the LIKE wildcards of a search term
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func scanFormSubmission(rows Scanner) (map[string]interface{}, error) {
+	var id string
+	var data JSON
+	var created time.Time
+	if err := rows.Scan(&id, &data, &created); err != nil {
+		return nil, err
+	}
+
+	doc := map[string]interface{}(data)
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+	doc["id"] = id
+	doc["created"] = created
+	return doc, nil
+}
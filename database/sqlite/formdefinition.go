@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) SaveFormDefinition(dbName string, def model.FormDefinition) error {
+	fields, err := json.Marshal(def.Fields)
+	if err != nil {
+		return err
+	}
+
+	qry := fmt.Sprintf(`
+		INSERT INTO %s_sb_form_definitions(id, name, fields, updated)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT(name) DO UPDATE SET fields = excluded.fields, updated = excluded.updated
+	`, dbName)
+
+	_, err = sl.DB.Exec(qry, sl.NewID(), def.Name, string(fields), def.Updated)
+	return err
+}
+
+func (sl *SQLite) GetFormDefinition(dbName, form string) (def model.FormDefinition, err error) {
+	qry := fmt.Sprintf(`
+		SELECT name, fields, updated 
+		FROM %s_sb_form_definitions 
+		WHERE name = $1
+	`, dbName)
+
+	var fields string
+	err = sl.DB.QueryRow(qry, form).Scan(&def.Name, &fields, &def.Updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.FormDefinition{Name: form}, nil
+	} else if err != nil {
+		return
+	}
+
+	err = json.Unmarshal([]byte(fields), &def.Fields)
+	return
+}
+
+func (sl *SQLite) DeleteFormDefinition(dbName, form string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s_sb_form_definitions 
+		WHERE name = $1
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, form)
+	return err
+}
+
+func (sl *SQLite) ListFormDefinitions(dbName string) (results []model.FormDefinition, err error) {
+	qry := fmt.Sprintf(`
+		SELECT name, fields, updated 
+		FROM %s_sb_form_definitions 
+		ORDER BY name
+	`, dbName)
+
+	rows, err := sl.DB.Query(qry)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var def model.FormDefinition
+		var fields string
+		if err = rows.Scan(&def.Name, &fields, &def.Updated); err != nil {
+			return
+		}
+
+		if err = json.Unmarshal([]byte(fields), &def.Fields); err != nil {
+			return
+		}
+
+		results = append(results, def)
+	}
+
+	err = rows.Err()
+	return
+}
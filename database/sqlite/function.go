@@ -235,6 +235,17 @@ func (sl *SQLite) RanFunction(dbName, id string, rh model.ExecHistory) error {
 	return err
 }
 
+func (sl *SQLite) CountFunctionRuns(dbName string, since, until time.Time) (count int64, err error) {
+	qry := fmt.Sprintf(`
+		SELECT COUNT(*) 
+		FROM %s_sb_function_logs 
+		WHERE started >= $1 AND started < $2
+	`, dbName)
+
+	err = sl.DB.QueryRow(qry, since, until).Scan(&count)
+	return
+}
+
 func scanExecData(rows Scanner, ex *model.ExecData) error {
 	return rows.Scan(
 		&ex.ID,
@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) PurgeUser(dbName string, tok model.User) (report model.DeletionReport, err error) {
+	report = model.NewDeletionReport(tok)
+
+	cols, err := sl.ListCollections(dbName)
+	if err != nil {
+		return
+	}
+
+	tx, err := sl.DB.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, col := range cols {
+		if strings.HasPrefix(col, "sb_") {
+			continue
+		}
+
+		qry := fmt.Sprintf(`
+			DELETE FROM %s_%s 
+			WHERE owner_id = $1
+		`, dbName, col)
+
+		res, err := tx.Exec(qry, tok.ID)
+		if err != nil {
+			return report, err
+		}
+
+		if n, err := res.RowsAffected(); err != nil {
+			return report, err
+		} else if n > 0 {
+			report.Documents[col] = n
+		}
+	}
+
+	qry := fmt.Sprintf(`
+		DELETE FROM %s_sb_forms 
+		WHERE LOWER(json_extract(data, '$.email')) = LOWER($1)
+	`, dbName)
+
+	res, err := tx.Exec(qry, tok.Email)
+	if err != nil {
+		return
+	}
+
+	if report.FormSubmissions, err = res.RowsAffected(); err != nil {
+		return
+	}
+
+	qry = fmt.Sprintf(`
+		DELETE FROM %s_sb_tokens 
+		WHERE id = $1
+	`, dbName)
+
+	if _, err = tx.Exec(qry, tok.ID); err != nil {
+		return
+	}
+
+	qry = fmt.Sprintf(`
+		DELETE FROM %s_sb_push 
+		WHERE user_id = $1
+	`, dbName)
+
+	if _, err = tx.Exec(qry, tok.ID); err != nil {
+		return
+	}
+
+	qry = fmt.Sprintf(`
+		DELETE FROM %s_sb_user_locales 
+		WHERE user_id = $1
+	`, dbName)
+
+	if _, err = tx.Exec(qry, tok.ID); err != nil {
+		return
+	}
+
+	var count int
+	qry = fmt.Sprintf(`
+		SELECT COUNT(*) 
+		FROM %s_sb_tokens 
+		WHERE account_id = $1
+	`, dbName)
+
+	if err = tx.QueryRow(qry, tok.AccountID).Scan(&count); err != nil {
+		return
+	}
+
+	if count == 0 {
+		// foreign keys are not enforced by default in SQLite
+		for _, table := range []string{"sb_files", "sb_invites"} {
+			qry = fmt.Sprintf(`
+				DELETE FROM %s_%s 
+				WHERE account_id = $1
+			`, dbName, table)
+
+			if _, err = tx.Exec(qry, tok.AccountID); err != nil {
+				return
+			}
+		}
+
+		qry = fmt.Sprintf(`
+			DELETE FROM %s_sb_accounts 
+			WHERE id = $1
+		`, dbName)
+
+		if _, err = tx.Exec(qry, tok.AccountID); err != nil {
+			return
+		}
+
+		report.AccountDeleted = true
+	}
+
+	if err = tx.Commit(); err != nil {
+		return
+	}
+
+	report.Completed = time.Now()
+	return
+}
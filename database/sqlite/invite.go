@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddInvite(dbName string, inv model.Invite) (id string, err error) {
+	id = sl.NewID()
+
+	qry := fmt.Sprintf(`
+		INSERT INTO %s_sb_invites(id, account_id, email, role, invited_by, created, expires)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+	`, dbName)
+
+	_, err = sl.DB.Exec(
+		qry,
+		id,
+		inv.AccountID,
+		inv.Email,
+		inv.Role,
+		inv.InvitedBy,
+		inv.Created,
+		inv.Expires,
+	)
+	return
+}
+
+func (sl *SQLite) GetInvite(dbName, id string) (inv model.Invite, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_invites 
+		WHERE id = $1
+	`, dbName)
+
+	row := sl.DB.QueryRow(qry, id)
+
+	err = scanInvite(row, &inv)
+	return
+}
+
+func (sl *SQLite) ListInvites(dbName, accountID string) (results []model.Invite, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_invites 
+		WHERE account_id = $1
+		ORDER BY created
+	`, dbName)
+
+	rows, err := sl.DB.Query(qry, accountID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var inv model.Invite
+		if err = scanInvite(rows, &inv); err != nil {
+			return
+		}
+
+		results = append(results, inv)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) DeleteInvite(dbName, id string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s_sb_invites 
+		WHERE id = $1
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, id)
+	return err
+}
+
+func scanInvite(rows Scanner, inv *model.Invite) error {
+	return rows.Scan(
+		&inv.ID,
+		&inv.AccountID,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Created,
+		&inv.Expires,
+	)
+}
@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestInvites(t *testing.T) {
+	inv := model.Invite{
+		AccountID: adminAccount.ID,
+		Email:     "invited@test.com",
+		Role:      10,
+		InvitedBy: adminToken.ID,
+		Created:   time.Now(),
+		Expires:   time.Now().Add(24 * time.Hour),
+	}
+
+	id, err := datastore.AddInvite(confDBName, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check, err := datastore.GetInvite(confDBName, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if check.Email != inv.Email {
+		t.Errorf("expected email to be %s got %s", inv.Email, check.Email)
+	} else if check.Role != inv.Role {
+		t.Errorf("expected role to be %d got %d", inv.Role, check.Role)
+	}
+
+	invites, err := datastore.ListInvites(confDBName, adminAccount.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(invites) != 1 {
+		t.Fatalf("expected 1 invite got %d", len(invites))
+	}
+
+	if err := datastore.DeleteInvite(confDBName, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := datastore.GetInvite(confDBName, id); err == nil {
+		t.Error("expected an error getting a deleted invite")
+	}
+}
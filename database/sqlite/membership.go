@@ -81,6 +81,18 @@ func (sl *SQLite) UserSetPassword(dbName, tokenID, password string) error {
 	return nil
 }
 
+func (sl *SQLite) ResetUserCredentials(dbName, userID, email, password, token string) error {
+	qry := fmt.Sprintf(`
+		UPDATE %s_sb_tokens SET email = $2, password = $3, token = $4
+		WHERE id = $1;
+	`, dbName)
+
+	if _, err := sl.DB.Exec(qry, userID, email, password, token); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (sl *SQLite) GetFirstUserFromAccountID(dbName, accountID string) (tok model.User, err error) {
 	qry := fmt.Sprintf(`
 		SELECT * 
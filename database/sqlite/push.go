@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddPushSubscription(dbName string, sub model.PushSubscription) (id string, err error) {
+	id = sl.NewID()
+
+	qry := fmt.Sprintf(`
+		INSERT INTO %s_sb_push(id, account_id, user_id, channel, kind, endpoint, p256dh, auth, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, dbName)
+
+	_, err = sl.DB.Exec(
+		qry,
+		id,
+		sub.AccountID,
+		sub.UserID,
+		sub.Channel,
+		sub.Kind,
+		sub.Endpoint,
+		sub.P256dh,
+		sub.Auth,
+		sub.Created,
+	)
+	return
+}
+
+func (sl *SQLite) ListPushSubscriptions(dbName, channel string) (results []model.PushSubscription, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_push 
+		WHERE channel = $1
+	`, dbName)
+
+	rows, err := sl.DB.Query(qry, channel)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sub model.PushSubscription
+		if err = scanPushSubscription(rows, &sub); err != nil {
+			return
+		}
+
+		results = append(results, sub)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) DeletePushSubscription(dbName, accountID, id string) error {
+	qry := fmt.Sprintf(`
+		DELETE FROM %s_sb_push 
+		WHERE id = $1 AND account_id = $2
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, id, accountID)
+	return err
+}
+
+func scanPushSubscription(rows Scanner, sub *model.PushSubscription) error {
+	return rows.Scan(
+		&sub.ID,
+		&sub.AccountID,
+		&sub.UserID,
+		&sub.Channel,
+		&sub.Kind,
+		&sub.Endpoint,
+		&sub.P256dh,
+		&sub.Auth,
+		&sub.Created,
+	)
+}
@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -33,14 +34,17 @@ func (sl *SQLite) CreateDatabase(base model.DatabaseConfig) (b model.DatabaseCon
 	b = base
 
 	_, err = sl.DB.Exec(`
-	INSERT INTO sb_apps(id, customer_id, name, allowed_domain, is_active, monthly_email_sent, created)
-	VALUES($1, $2, $3, $4, $5, $6, $7);
+	INSERT INTO sb_apps(id, customer_id, name, allowed_domain, is_active, monthly_email_sent, created, environment, parent_id, region)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
 	`, base.ID, base.TenantID,
 		base.Name,
 		strings.Join(base.AllowedDomain, "|"),
 		base.IsActive,
 		base.MonthlySentEmail,
 		base.Created,
+		base.Environment,
+		base.ParentID,
+		base.Region,
 	)
 	if err != nil {
 		return
@@ -57,7 +61,7 @@ func (sl *SQLite) createSystemTables(schema string) error {
 			email TEXT UNIQUE NOT NULL,
 			created TIMESTAMP NOT NULL
 		);
-		
+
 		CREATE TABLE IF NOT EXISTS {schema}_sb_tokens (
 			id TEXT PRIMARY KEY,
 			account_id TEXT REFERENCES {schema}_sb_accounts(id) ON DELETE CASCADE,
@@ -83,7 +87,7 @@ func (sl *SQLite) createSystemTables(schema string) error {
 			key TEXT UNIQUE NOT NULL,
 			url TEXT NOT NULL,
 			size INTEGER NOT NULL,			
-			uploaded timestamp NOT NULL			
+			uploaded timestamp NOT NULL
 		);
 		CREATE INDEX IF NOT EXISTS {schema}_sb_files_acctid_idx ON {schema}_sb_files (account_id);
 
@@ -123,7 +127,7 @@ func (sl *SQLite) createSystemTables(schema string) error {
 		return err
 	}
 
-	return nil
+	return sl.upgradeSystemTables(schema)
 }
 
 func (sl *SQLite) EmailExists(email string) (bool, error) {
@@ -204,6 +208,74 @@ func (sl *SQLite) IncrementMonthlyEmailSent(baseID string) error {
 	return err
 }
 
+func (sl *SQLite) IncrementEmailUsage(tenantID, month string) (sent int, err error) {
+	err = sl.DB.QueryRow(`
+		INSERT INTO sb_email_usage(customer_id, month, sent)
+		VALUES($1, $2, 1)
+		ON CONFLICT(customer_id, month) DO UPDATE SET sent = sent + 1
+		RETURNING sent;
+	`, tenantID, month).Scan(&sent)
+	return
+}
+
+func (sl *SQLite) GetEmailUsage(tenantID, month string) (sent int, err error) {
+	err = sl.DB.QueryRow(`
+		SELECT COALESCE(SUM(sent), 0) 
+		FROM sb_email_usage 
+		WHERE customer_id = $1 AND month = $2
+	`, tenantID, month).Scan(&sent)
+	return
+}
+
+func (sl *SQLite) UpdateDatabaseSettings(baseID string, settings model.AppSettings) error {
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = sl.DB.Exec(`
+		UPDATE sb_apps SET
+			settings = $2
+		WHERE id = $1
+	`, baseID, string(b))
+
+	return err
+}
+
+func (sl *SQLite) SuspendDatabase(baseID, reason string) error {
+	_, err := sl.DB.Exec(`
+		UPDATE sb_apps SET
+			suspended_reason = $2
+		WHERE id = $1
+	`, baseID, reason)
+	return err
+}
+
+func (sl *SQLite) ListEnvironments(parentID string) (results []model.DatabaseConfig, err error) {
+	rows, err := sl.DB.Query(`
+		SELECT * 
+		FROM sb_apps 
+		WHERE parent_id = $1
+		ORDER BY created
+	`, parentID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var base model.DatabaseConfig
+		if err = scanBase(rows, &base); err != nil {
+			return
+		}
+
+		results = append(results, base)
+	}
+
+	err = rows.Err()
+	return
+}
+
 func (sl *SQLite) GetTenantByStripeID(stripeID string) (cus model.Tenant, err error) {
 	row := sl.DB.QueryRow(`
 		SELECT * 
@@ -250,6 +322,13 @@ func (sl *SQLite) ChangeTenantPlan(tenantID string, plan int) error {
 	return nil
 }
 
+func (sl *SQLite) SetTenantSubscription(tenantID, subscriptionID string) error {
+	if _, err := sl.DB.Exec(`UPDATE sb_customers SET sub_id = $2 WHERE id = $1`, tenantID, subscriptionID); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (sl *SQLite) EnableExternalLogin(tenantID string, config map[string]model.OAuthConfig) error {
 	b, err := model.EncryptExternalLogins(config)
 	if err != nil {
@@ -284,6 +363,48 @@ func (sl *SQLite) DeleteTenant(dbName, email string) error {
 	return nil
 }
 
+func (sl *SQLite) DeleteDatabase(baseID string) error {
+	base, err := sl.FindDatabase(baseID)
+	if err != nil {
+		return err
+	}
+
+	// the underscores are escaped so only this database's tables match
+	prefix := strings.ReplaceAll(strings.ToLower(base.Name), "_", `\_`) + `\_%`
+
+	rows, err := sl.DB.Query(`
+		SELECT name 
+		FROM sqlite_schema 
+		WHERE type='table' AND name LIKE $1 ESCAPE '\'
+	`, prefix)
+	if err != nil {
+		return err
+	}
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	for _, table := range tables {
+		if _, err := sl.DB.Exec(fmt.Sprintf("DROP TABLE %s", table)); err != nil {
+			return err
+		}
+	}
+
+	_, err = sl.DB.Exec(`
+		DELETE FROM sb_apps WHERE id = $1
+	`, baseID)
+	return err
+}
+
 func scanCustomer(rows Scanner, c *model.Tenant) error {
 	return rows.Scan(
 		&c.ID,
@@ -298,7 +419,7 @@ func scanCustomer(rows Scanner, c *model.Tenant) error {
 }
 
 func scanBase(rows Scanner, b *model.DatabaseConfig) error {
-	var allowedDomain string
+	var allowedDomain, settings string
 	err := rows.Scan(
 		&b.ID,
 		&b.TenantID,
@@ -307,10 +428,18 @@ func scanBase(rows Scanner, b *model.DatabaseConfig) error {
 		&b.IsActive,
 		&b.MonthlySentEmail,
 		&b.Created,
+		&settings,
+		&b.SuspendedReason,
+		&b.Environment,
+		&b.ParentID,
+		&b.Region,
 	)
+	if err != nil {
+		return err
+	}
 
 	b.AllowedDomain = strings.Split(allowedDomain, "|")
-	return err
+	return json.Unmarshal([]byte(settings), &b.Settings)
 }
 
 func (sl *SQLite) GetAllDatabaseSizes() error {
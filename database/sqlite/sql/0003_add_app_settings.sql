@@ -0,0 +1,2 @@
+ALTER TABLE sb_apps
+ADD COLUMN settings TEXT NOT NULL DEFAULT '{}';
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS sb_app_deletions (
+	id TEXT PRIMARY KEY,
+	base_id TEXT NOT NULL,
+	tenant_id TEXT NOT NULL,
+	db_name TEXT NOT NULL,
+	email TEXT NOT NULL,
+	status TEXT NOT NULL,
+	requested TIMESTAMP NOT NULL,
+	scheduled TIMESTAMP NOT NULL,
+	completed TIMESTAMP NOT NULL,
+	certificate TEXT NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS sb_backups (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL,
+	db_name TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	file_key TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	documents INTEGER NOT NULL,
+	created TIMESTAMP NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS sb_admin_audit (
+	id TEXT PRIMARY KEY,
+	type TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	tenant_id TEXT NOT NULL,
+	db_name TEXT NOT NULL,
+	target TEXT NOT NULL,
+	detail TEXT NOT NULL,
+	ip TEXT NOT NULL,
+	user_agent TEXT NOT NULL,
+	created TIMESTAMP NOT NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS sb_admin_audit_no_update BEFORE UPDATE ON sb_admin_audit
+BEGIN
+	SELECT RAISE(ABORT, 'the admin audit log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS sb_admin_audit_no_delete BEFORE DELETE ON sb_admin_audit
+BEGIN
+	SELECT RAISE(ABORT, 'the admin audit log is append-only');
+END;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS sb_feature_flags (
+	tenant_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL,
+	updated TIMESTAMP NOT NULL,
+	PRIMARY KEY (tenant_id, name)
+);
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE sb_apps
+ADD COLUMN environment TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE sb_apps
+ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
\ No newline at end of file
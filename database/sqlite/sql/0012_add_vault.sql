@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS sb_secrets (
+	id TEXT PRIMARY KEY,
+	db_name TEXT NOT NULL,
+	name TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	value BLOB NOT NULL,
+	data_key BLOB NOT NULL,
+	key_id TEXT NOT NULL,
+	created TIMESTAMP NOT NULL,
+	UNIQUE (db_name, name, version)
+);
\ No newline at end of file
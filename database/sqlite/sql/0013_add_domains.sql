@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS sb_domains (
+	id TEXT PRIMARY KEY,
+	db_name TEXT NOT NULL,
+	base_id TEXT NOT NULL,
+	hostname TEXT NOT NULL UNIQUE,
+	token TEXT NOT NULL,
+	verified BOOLEAN NOT NULL,
+	created TIMESTAMP NOT NULL
+);
\ No newline at end of file
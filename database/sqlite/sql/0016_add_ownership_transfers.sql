@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS sb_ownership_transfers (
+	id TEXT PRIMARY KEY,
+	base_id TEXT NOT NULL,
+	from_tenant_id TEXT NOT NULL,
+	to_tenant_id TEXT NOT NULL,
+	to_email TEXT NOT NULL,
+	whole_account BOOLEAN NOT NULL,
+	status TEXT NOT NULL,
+	created TIMESTAMP NOT NULL,
+	expires TIMESTAMP NOT NULL,
+	completed TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sb_ownership_transfers_from_idx ON sb_ownership_transfers (from_tenant_id);
+CREATE INDEX IF NOT EXISTS sb_ownership_transfers_to_idx ON sb_ownership_transfers (to_tenant_id);
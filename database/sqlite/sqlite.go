@@ -3,8 +3,6 @@ package sqlite
 import (
 	"database/sql"
 	"embed"
-	"fmt"
-	"os"
 
 	"github.com/staticbackendhq/core/cache"
 	"github.com/staticbackendhq/core/database"
@@ -25,18 +23,22 @@ type SQLite struct {
 func New(db *sql.DB, pubdoc cache.PublishDocumentEvent, log *logger.Logger) database.Persister {
 	// run migrations
 	if err := migrate(db); err != nil {
-		fmt.Println("=== MIGRATION FAILED ===")
-		fmt.Println(err)
-		fmt.Println("=== /MIGRATION FAILED ===")
-		os.Exit(1)
+		log.Fatal().Err(err).Msg("migration failed")
 	}
 
-	return &SQLite{
+	sl := &SQLite{
 		DB:              db,
 		PublishDocument: pubdoc,
 		collections:     make(map[string]bool),
 		log:             log,
 	}
+
+	// the databases created before the last system tables changes
+	if err := sl.upgradeTenants(); err != nil {
+		log.Fatal().Err(err).Msg("system tables upgrade failed")
+	}
+
+	return sl
 }
 
 func (sl *SQLite) Ping() error {
@@ -1,7 +1,9 @@
 package sqlite
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/staticbackendhq/core/model"
 )
@@ -9,9 +11,23 @@ import (
 func (sl *SQLite) AddFile(dbName string, f model.File) (id string, err error) {
 	id = sl.NewID()
 
+	variants, err := marshalVariants(f.Variants)
+	if err != nil {
+		return
+	}
+
+	if f.Tags == nil {
+		f.Tags = []string{}
+	}
+
+	tags, err := json.Marshal(f.Tags)
+	if err != nil {
+		return
+	}
+
 	qry := fmt.Sprintf(`
-		INSERT INTO %s_sb_files(id, account_id, key, url, size, uploaded)
-		VALUES($1, $2, $3, $4, $5, $6);
+		INSERT INTO %s_sb_files(id, account_id, key, url, size, uploaded, variants, name, mime_type, tags, collection, document_id, user_id)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
 	`, dbName)
 
 	_, err = sl.DB.Exec(
@@ -22,6 +38,13 @@ func (sl *SQLite) AddFile(dbName string, f model.File) (id string, err error) {
 		f.URL,
 		f.Size,
 		f.Uploaded,
+		string(variants),
+		f.Name,
+		f.MimeType,
+		string(tags),
+		f.Collection,
+		f.DocumentID,
+		f.UserID,
 	)
 	return
 }
@@ -86,13 +109,131 @@ func (sl *SQLite) ListAllFiles(dbName, accountID string) (results []model.File,
 	return
 }
 
+func (sl *SQLite) ListFiles(dbName string, f model.FileFilter) (results []model.File, err error) {
+	var clauses []string
+	var args []interface{}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(f.AccountID) > 0 {
+		add("account_id = $%d", f.AccountID)
+	}
+	if len(f.Name) > 0 {
+		add("LOWER(name) LIKE LOWER($%d)", "%"+f.Name+"%")
+	}
+	if len(f.MimeType) > 0 {
+		add("mime_type LIKE $%d", f.MimeType+"%")
+	}
+	if len(f.Tag) > 0 {
+		add("EXISTS (SELECT 1 FROM json_each(tags) WHERE value = $%d)", f.Tag)
+	}
+	if len(f.Collection) > 0 {
+		add("collection = $%d", f.Collection)
+	}
+	if len(f.DocumentID) > 0 {
+		add("document_id = $%d", f.DocumentID)
+	}
+	if len(f.UserID) > 0 {
+		add("user_id = $%d", f.UserID)
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = "WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	paging := ""
+	if f.Limit > 0 {
+		paging = fmt.Sprintf("LIMIT %d OFFSET %d", f.Limit, f.Offset)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_files
+		%s
+		ORDER BY uploaded DESC
+		%s
+	`, dbName, where, paging)
+
+	rows, err := sl.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var file model.File
+		if err = scanFile(rows, &file); err != nil {
+			return
+		}
+
+		results = append(results, file)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) LinkFile(dbName, fileID string, link model.FileLink) error {
+	qry := fmt.Sprintf(`
+		UPDATE %s_sb_files SET
+			collection = $2,
+			document_id = $3,
+			user_id = $4
+		WHERE id = $1
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, fileID, link.Collection, link.DocumentID, link.UserID)
+	return err
+}
+
+func (sl *SQLite) StorageUsage(dbName string) (usage model.StorageUsage, err error) {
+	qry := fmt.Sprintf(`
+		SELECT COUNT(*), 
+			COALESCE(SUM(size), 0) + COALESCE(SUM((
+				SELECT SUM(json_extract(value, '$.size')) FROM json_each(variants)
+			)), 0)
+		FROM %s_sb_files
+	`, dbName)
+
+	err = sl.DB.QueryRow(qry).Scan(&usage.Files, &usage.Bytes)
+	return
+}
+
+// marshalVariants returns the JSON of the variants, an empty array if nil
+func marshalVariants(variants []model.FileVariant) ([]byte, error) {
+	if variants == nil {
+		variants = []model.FileVariant{}
+	}
+	return json.Marshal(variants)
+}
+
 func scanFile(rows Scanner, f *model.File) error {
-	return rows.Scan(
+	var variants, tags []byte
+	err := rows.Scan(
 		&f.ID,
 		&f.AccountID,
 		&f.Key,
 		&f.URL,
 		&f.Size,
 		&f.Uploaded,
+		&variants,
+		&f.Name,
+		&f.MimeType,
+		&tags,
+		&f.Collection,
+		&f.DocumentID,
+		&f.UserID,
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(variants, &f.Variants); err != nil {
+		return err
+	}
+	return json.Unmarshal(tags, &f.Tags)
 }
@@ -0,0 +1,255 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+)
+
+// systemColumn is a column added to a system table after its creation
+type systemColumn struct {
+	table      string
+	name       string
+	definition string
+}
+
+// systemColumns are added in this order to the tables missing them, the
+// order of the columns read with SELECT * depends on it
+var systemColumns = []systemColumn{
+	{"sb_files", "variants", "TEXT NOT NULL DEFAULT '[]'"},
+	{"sb_files", "name", "TEXT NOT NULL DEFAULT ''"},
+	{"sb_files", "mime_type", "TEXT NOT NULL DEFAULT ''"},
+	{"sb_files", "tags", "TEXT NOT NULL DEFAULT '[]'"},
+	{"sb_files", "collection", "TEXT NOT NULL DEFAULT ''"},
+	{"sb_files", "document_id", "TEXT NOT NULL DEFAULT ''"},
+	{"sb_files", "user_id", "TEXT NOT NULL DEFAULT ''"},
+	{"sb_tasks", "last_status", "TEXT NOT NULL DEFAULT ''"},
+	{"sb_tasks", "last_error", "TEXT NOT NULL DEFAULT ''"},
+	{"sb_tasks", "run_at", "timestamp NOT NULL DEFAULT '0001-01-01 00:00:00'"},
+	{"sb_tasks", "retry", "TEXT NOT NULL DEFAULT '{}'"},
+	{"sb_tasks", "enabled", "BOOLEAN NOT NULL DEFAULT TRUE"},
+	{"sb_tasks", "after", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// upgradeTenants upgrades the system tables of all databases, the ones
+// created before a system table or column was added miss it. A database
+// failing to upgrade is logged and skipped.
+func (sl *SQLite) upgradeTenants() error {
+	rows, err := sl.DB.Query(`
+		SELECT name 
+		FROM sb_apps
+	`)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+
+		names = append(names, name)
+	}
+	rows.Close()
+
+	for _, name := range names {
+		if err := sl.upgradeSystemTables(name); err != nil {
+			sl.log.Error().Err(err).Msgf("unable to upgrade the system tables of %s", name)
+		}
+	}
+	return nil
+}
+
+// upgradeSystemTables adds the system columns and tables missing from a
+// database
+func (sl *SQLite) upgradeSystemTables(schema string) error {
+	for _, c := range systemColumns {
+		table := fmt.Sprintf("%s_%s", schema, c.table)
+
+		var count int
+		err := sl.DB.QueryRow(`
+			SELECT COUNT(*) 
+			FROM pragma_table_info($1) 
+			WHERE name = $2
+		`, table, c.name).Scan(&count)
+		if err != nil {
+			return err
+		} else if count > 0 {
+			continue
+		}
+
+		qry := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, c.name, c.definition)
+		if _, err := sl.DB.Exec(qry); err != nil {
+			return err
+		}
+	}
+
+	qry := strings.Replace(`
+		CREATE INDEX IF NOT EXISTS {schema}_sb_files_document_idx ON {schema}_sb_files (collection, document_id);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_form_deliveries (
+			id TEXT PRIMARY KEY,
+			form TEXT NOT NULL,
+			url TEXT NOT NULL,
+			attempt INTEGER NOT NULL,
+			status_code INTEGER NOT NULL,
+			success BOOLEAN NOT NULL,
+			error TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_form_deliveries_form_idx ON {schema}_sb_form_deliveries (form, created);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_form_definitions (
+			id TEXT PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL,
+			fields TEXT NOT NULL,
+			updated timestamp NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_email_templates (
+			id TEXT PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL,
+			subject TEXT NOT NULL,
+			html_body TEXT NOT NULL,
+			text_body TEXT NOT NULL,
+			variables TEXT NOT NULL,
+			updated timestamp NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_user_locales (
+			user_id TEXT PRIMARY KEY REFERENCES {schema}_sb_tokens(id) ON DELETE CASCADE,
+			locale TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_email_queue (
+			id TEXT PRIMARY KEY,
+			from_email TEXT NOT NULL,
+			from_name TEXT NOT NULL,
+			to_email TEXT NOT NULL,
+			to_name TEXT NOT NULL,
+			reply_to TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			template TEXT NOT NULL,
+			html_body TEXT NOT NULL,
+			text_body TEXT NOT NULL,
+			attachments TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL,
+			last_error TEXT NOT NULL,
+			provider_id TEXT NOT NULL,
+			next_attempt timestamp NOT NULL,
+			created timestamp NOT NULL,
+			updated timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_email_queue_due_idx ON {schema}_sb_email_queue (status, next_attempt);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_email_queue_to_idx ON {schema}_sb_email_queue (to_email, created);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_email_events (
+			id TEXT PRIMARY KEY,
+			email TEXT NOT NULL,
+			type TEXT NOT NULL,
+			permanent BOOLEAN NOT NULL,
+			provider TEXT NOT NULL,
+			detail TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_email_events_email_idx ON {schema}_sb_email_events (email, created);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_email_suppressions (
+			email TEXT PRIMARY KEY,
+			reason TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			detail TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_webhook_deliveries (
+			id TEXT PRIMARY KEY,
+			url TEXT NOT NULL,
+			event TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL,
+			status_code INTEGER NOT NULL,
+			last_error TEXT NOT NULL,
+			next_attempt timestamp NOT NULL,
+			created timestamp NOT NULL,
+			updated timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_webhook_deliveries_due_idx ON {schema}_sb_webhook_deliveries (status, next_attempt);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_webhook_deliveries_created_idx ON {schema}_sb_webhook_deliveries (created);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_task_runs (
+			id TEXT PRIMARY KEY,
+			task_id TEXT REFERENCES {schema}_sb_tasks(id) ON DELETE CASCADE,
+			attempt INTEGER NOT NULL,
+			started timestamp NOT NULL,
+			completed timestamp NOT NULL,
+			success BOOLEAN NOT NULL,
+			output TEXT NOT NULL,
+			error TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_task_runs_task_idx ON {schema}_sb_task_runs (task_id, started);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_audit (
+			id TEXT PRIMARY KEY,
+			account_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			type TEXT NOT NULL,
+			detail TEXT NOT NULL,
+			ip TEXT NOT NULL,
+			user_agent TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_audit_created_idx ON {schema}_sb_audit (created);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_invites (
+			id TEXT PRIMARY KEY,
+			account_id TEXT REFERENCES {schema}_sb_accounts(id) ON DELETE CASCADE,
+			email TEXT NOT NULL,
+			role INTEGER NOT NULL,
+			invited_by TEXT NOT NULL,
+			created timestamp NOT NULL,
+			expires timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_invites_acctid_idx ON {schema}_sb_invites (account_id);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_push (
+			id TEXT PRIMARY KEY,
+			account_id TEXT REFERENCES {schema}_sb_accounts(id) ON DELETE CASCADE,
+			user_id TEXT REFERENCES {schema}_sb_tokens(id) ON DELETE CASCADE,
+			channel TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			endpoint TEXT NOT NULL,
+			p256dh TEXT NOT NULL,
+			auth TEXT NOT NULL,
+			created timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_push_channel_idx ON {schema}_sb_push (channel);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_events (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			session_id TEXT NOT NULL,
+			properties TEXT NOT NULL,
+			timestamp timestamp NOT NULL,
+			received timestamp NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_events_received_idx ON {schema}_sb_events (received);
+		CREATE INDEX IF NOT EXISTS {schema}_sb_events_name_idx ON {schema}_sb_events (name, received);
+
+		CREATE TABLE IF NOT EXISTS {schema}_sb_event_rollups (
+			day TEXT NOT NULL,
+			name TEXT NOT NULL,
+			count INTEGER NOT NULL,
+			PRIMARY KEY (day, name)
+		);
+	
+	`, "{schema}", schema, -1)
+
+	_, err := sl.DB.Exec(qry)
+	return err
+}
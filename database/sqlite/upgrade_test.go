@@ -0,0 +1,264 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+// legacySystemTables is the system tables DDL databases were created with
+// before the system columns and tables were added
+const legacySystemTables = `
+	CREATE TABLE IF NOT EXISTS {schema}_sb_accounts (
+		id TEXT PRIMARY KEY,
+		email TEXT UNIQUE NOT NULL,
+		created TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS {schema}_sb_tokens (
+		id TEXT PRIMARY KEY,
+		account_id TEXT REFERENCES {schema}_sb_accounts(id) ON DELETE CASCADE,
+		token TEXT UNIQUE NOT NULL,
+		email TEXT UNIQUE NOT NULL,
+		password TEXT NOT NULL,
+		role INTEGER NOT NULL,
+		reset_code TEXT NOT NULL,
+		created timestamp NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS {schema}_sb_forms (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		data JSON NOT NULL,
+		created timestamp NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS {schema}_sb_forms_name_idx ON {schema}_sb_forms (name);
+
+	CREATE TABLE IF NOT EXISTS {schema}_sb_files (
+		id TEXT PRIMARY KEY,
+		account_id TEXT REFERENCES {schema}_sb_accounts(id) ON DELETE CASCADE,
+		key TEXT UNIQUE NOT NULL,
+		url TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		uploaded timestamp NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS {schema}_sb_files_acctid_idx ON {schema}_sb_files (account_id);
+
+	CREATE TABLE IF NOT EXISTS {schema}_sb_functions (
+		id TEXT PRIMARY KEY,
+		function_name TEXT UNIQUE NOT NULL,
+		trigger_topic TEXT NOT NULL,
+		code TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		last_updated timestamp NOT NULL,
+		last_run timestamp NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS {schema}_sb_functions_trigger_topic_idx ON {schema}_sb_functions (trigger_topic);
+
+	CREATE TABLE IF NOT EXISTS {schema}_sb_function_logs (
+		id TEXT PRIMARY KEY,
+		function_id TEXT REFERENCES {schema}_sb_functions(id) ON DELETE CASCADE,
+		version INTEGER NOT NULL,
+		started timestamp NOT NULL,
+		completed timestamp NOT NULL,
+		success BOOLEAN NOT NULL,
+		output TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS {schema}_sb_tasks (
+		id TEXT PRIMARY KEY,
+		name TEXT UNIQUE NOT NULL,
+		type TEXT NOT NULL,
+		value TEXT NOT NULL,
+		meta TEXT NOT NULL,
+		interval TEXT NOT NULL,
+		last_run timestamp NOT NULL
+	);
+`
+
+func createLegacySchema(t *testing.T, schema string) {
+	t.Helper()
+
+	qry := strings.Replace(legacySystemTables, "{schema}", schema, -1)
+	if _, err := datastore.DB.Exec(qry); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		rows, err := datastore.DB.Query(`
+			SELECT name 
+			FROM sqlite_schema 
+			WHERE type='table' AND name LIKE $1
+		`, schema+"_sb_%")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var tables []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatal(err)
+			}
+			tables = append(tables, name)
+		}
+		rows.Close()
+
+		for _, name := range tables {
+			if _, err := datastore.DB.Exec("DROP TABLE " + name); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+}
+
+func TestUpgradeLegacyFiles(t *testing.T) {
+	const schema = "legacyfiles"
+	createLegacySchema(t, schema)
+
+	_, err := datastore.DB.Exec(`
+		INSERT INTO legacyfiles_sb_files(id, account_id, key, url, size, uploaded)
+		VALUES('legacy', 'acct', 'legacy/file.txt', 'https://cdn/file.txt', 10, $1)
+	`, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := datastore.upgradeSystemTables(schema); err != nil {
+		t.Fatal(err)
+	}
+	// the upgrade runs on every start
+	if err := datastore.upgradeSystemTables(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := datastore.GetFileByID(schema, "legacy")
+	if err != nil {
+		t.Fatal(err)
+	} else if legacy.Key != "legacy/file.txt" || len(legacy.Tags) != 0 {
+		t.Errorf("unexpected legacy file %v", legacy)
+	}
+
+	id, err := datastore.AddFile(schema, model.File{
+		AccountID: "acct",
+		Key:       "new/file.txt",
+		URL:       "https://cdn/new.txt",
+		Size:      20,
+		Uploaded:  time.Now(),
+		Name:      "new.txt",
+		Tags:      []string{"a"},
+		FileLink:  model.FileLink{Collection: "tasks", DocumentID: "doc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := datastore.ListFiles(schema, model.FileFilter{Collection: "tasks", DocumentID: "doc"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 || files[0].ID != id {
+		t.Errorf("expected the new file to be listed got %v", files)
+	}
+}
+
+func registerDatabase(t *testing.T, name string) {
+	t.Helper()
+
+	id := datastore.NewID()
+	_, err := datastore.DB.Exec(`
+		INSERT INTO sb_apps(id, customer_id, name, allowed_domain, is_active, monthly_email_sent, created, environment, parent_id, region)
+		VALUES($1, $2, $3, 'localhost', true, 0, $4, '', '', '')
+	`, id, dbTest.TenantID, name, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := datastore.DB.Exec("DELETE FROM sb_apps WHERE id = $1", id); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestUpgradeLegacyTasks(t *testing.T) {
+	const schema = "legacytasks"
+	createLegacySchema(t, schema)
+	registerDatabase(t, schema)
+	// a database without system tables fails to upgrade and list its tasks
+	registerDatabase(t, "brokentasks")
+
+	datastore.log = logger.Get(config.Current)
+	defer func() { datastore.log = nil }()
+
+	_, err := datastore.DB.Exec(`
+		INSERT INTO legacytasks_sb_tasks(id, name, type, value, meta, interval, last_run)
+		VALUES('legacy', 'legacy', 'function', 'fn', '', '@every 1h', $1)
+	`, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := datastore.upgradeTenants(); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := datastore.ListTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var legacy *model.Task
+	for i := range tasks {
+		if tasks[i].ID == "legacy" {
+			legacy = &tasks[i]
+		}
+	}
+
+	if legacy == nil {
+		t.Fatalf("expected the legacy task to be listed got %v", tasks)
+	} else if !legacy.RunAt.IsZero() || !legacy.Enabled || legacy.IsOneOff() {
+		t.Errorf("expected a recurring enabled task got %v", legacy)
+	}
+}
+
+func TestUpgradeLegacyTables(t *testing.T) {
+	const schema = "legacytables"
+	createLegacySchema(t, schema)
+
+	if err := datastore.upgradeSystemTables(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	tables := []string{
+		"sb_form_deliveries",
+		"sb_form_definitions",
+		"sb_email_templates",
+		"sb_user_locales",
+		"sb_email_queue",
+		"sb_email_events",
+		"sb_email_suppressions",
+		"sb_webhook_deliveries",
+		"sb_task_runs",
+		"sb_audit",
+		"sb_invites",
+		"sb_push",
+		"sb_events",
+		"sb_event_rollups",
+	}
+	for _, table := range tables {
+		var count int
+		qry := fmt.Sprintf("SELECT COUNT(*) FROM %s_%s", schema, table)
+		if err := datastore.DB.QueryRow(qry).Scan(&count); err != nil {
+			t.Errorf("expected %s to be created: %v", table, err)
+		}
+	}
+
+	if err := datastore.AddAuditEvent(schema, model.AuditEvent{Type: model.AuditLogin, Created: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddUsage(records []model.UsageRecord) (err error) {
+	tx, err := sl.DB.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, u := range records {
+		_, err = tx.Exec(`
+			INSERT INTO sb_usage(tenant_id, db_name, day, metric, value)
+			VALUES($1, $2, $3, $4, $5)
+			ON CONFLICT(tenant_id, db_name, day, metric) DO UPDATE SET value = value + $5
+		`, u.TenantID, u.DBName, u.Day, u.Metric, u.Value)
+		if err != nil {
+			return
+		}
+	}
+
+	err = tx.Commit()
+	return
+}
+
+func (sl *SQLite) ListUsage(filter model.UsageFilter) (results []model.UsageRecord, err error) {
+	rows, err := sl.DB.Query(`
+		SELECT * 
+		FROM sb_usage 
+		WHERE tenant_id = $1 
+		AND ($2 = '' OR db_name = $2) 
+		AND ($3 = '' OR day >= $3) 
+		AND ($4 = '' OR day <= $4)
+		ORDER BY day, db_name, metric
+	`, filter.TenantID, filter.DBName, filter.Since, filter.Until)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u model.UsageRecord
+		if err = scanUsage(rows, &u); err != nil {
+			return
+		}
+
+		results = append(results, u)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func scanUsage(rows Scanner, u *model.UsageRecord) error {
+	return rows.Scan(
+		&u.TenantID,
+		&u.DBName,
+		&u.Day,
+		&u.Metric,
+		&u.Value,
+	)
+}
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) AddSecret(s model.Secret) (id string, err error) {
+	id = sl.NewID()
+
+	_, err = sl.DB.Exec(`
+		INSERT INTO sb_secrets(id, db_name, name, version, value, data_key, key_id, created)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		id,
+		s.DBName,
+		s.Name,
+		s.Version,
+		s.Value,
+		s.DataKey,
+		s.KeyID,
+		s.Created,
+	)
+	return
+}
+
+func (sl *SQLite) ListSecrets(dbName string) (results []model.Secret, err error) {
+	rows, err := sl.DB.Query(`
+		SELECT * 
+		FROM sb_secrets 
+		WHERE db_name = $1
+		ORDER BY name, version DESC
+	`, dbName)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s model.Secret
+		if err = scanSecret(rows, &s); err != nil {
+			return
+		}
+
+		results = append(results, s)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) GetSecret(dbName, name string, version int) (s model.Secret, err error) {
+	row := sl.DB.QueryRow(`
+		SELECT * 
+		FROM sb_secrets 
+		WHERE db_name = $1 AND name = $2 AND ($3 = 0 OR version = $3)
+		ORDER BY version DESC
+		LIMIT 1
+	`, dbName, name, version)
+
+	err = scanSecret(row, &s)
+	return
+}
+
+func (sl *SQLite) UpdateSecret(s model.Secret) error {
+	_, err := sl.DB.Exec(`
+		UPDATE sb_secrets SET
+			value = $2,
+			data_key = $3,
+			key_id = $4
+		WHERE id = $1
+	`, s.ID, s.Value, s.DataKey, s.KeyID)
+	return err
+}
+
+func (sl *SQLite) DeleteSecret(dbName, name string) error {
+	_, err := sl.DB.Exec(`
+		DELETE FROM sb_secrets 
+		WHERE db_name = $1 AND name = $2
+	`, dbName, name)
+	return err
+}
+
+func (sl *SQLite) ListSecretsToRewrap(keyID string, limit int64) (results []model.Secret, err error) {
+	rows, err := sl.DB.Query(`
+		SELECT * 
+		FROM sb_secrets 
+		WHERE key_id <> $1
+		LIMIT $2
+	`, keyID, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s model.Secret
+		if err = scanSecret(rows, &s); err != nil {
+			return
+		}
+
+		results = append(results, s)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func scanSecret(rows Scanner, s *model.Secret) error {
+	return rows.Scan(
+		&s.ID,
+		&s.DBName,
+		&s.Name,
+		&s.Version,
+		&s.Value,
+		&s.DataKey,
+		&s.KeyID,
+		&s.Created,
+	)
+}
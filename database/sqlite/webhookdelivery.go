@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (sl *SQLite) QueueWebhook(dbName string, d model.WebhookDelivery) (id string, err error) {
+	qry := fmt.Sprintf(`
+		INSERT INTO %s_sb_webhook_deliveries(id, url, event, payload, status, attempts, 
+			status_code, last_error, next_attempt, created, updated)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, dbName)
+
+	id = sl.NewID()
+	_, err = sl.DB.Exec(
+		qry,
+		id,
+		d.URL,
+		d.Event,
+		d.Payload,
+		d.Status,
+		d.Attempts,
+		d.StatusCode,
+		d.LastError,
+		d.NextAttempt,
+		d.Created,
+		d.Updated,
+	)
+	return
+}
+
+func (sl *SQLite) GetWebhookDelivery(dbName, id string) (d model.WebhookDelivery, err error) {
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_webhook_deliveries 
+		WHERE id = $1
+	`, dbName)
+
+	err = scanWebhookDelivery(sl.DB.QueryRow(qry, id), &d)
+	return
+}
+
+func (sl *SQLite) ListDueWebhooks(dbName string, now time.Time, limit int64) (results []model.WebhookDelivery, err error) {
+	lim := ""
+	if limit > 0 {
+		lim = fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_webhook_deliveries 
+		WHERE status = $1 AND next_attempt <= $2
+		ORDER BY next_attempt
+		%s
+	`, dbName, lim)
+
+	rows, err := sl.DB.Query(qry, model.WebhookStatusPending, now)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.WebhookDelivery
+		if err = scanWebhookDelivery(rows, &d); err != nil {
+			return
+		}
+
+		results = append(results, d)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (sl *SQLite) UpdateWebhookStatus(dbName, id, status string, attempts, statusCode int, lastError string, nextAttempt time.Time) error {
+	qry := fmt.Sprintf(`
+		UPDATE %s_sb_webhook_deliveries SET
+			status = $2,
+			attempts = $3,
+			status_code = $4,
+			last_error = $5,
+			next_attempt = $6,
+			updated = $7
+		WHERE id = $1
+	`, dbName)
+
+	_, err := sl.DB.Exec(qry, id, status, attempts, statusCode, lastError, nextAttempt, time.Now())
+	return err
+}
+
+func (sl *SQLite) ListWebhookDeliveries(dbName string, f model.WebhookDeliveryFilter) (results []model.WebhookDelivery, err error) {
+	where, args := webhookDeliveryWhere(f)
+
+	limit := ""
+	if f.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", f.Limit)
+	}
+
+	qry := fmt.Sprintf(`
+		SELECT * 
+		FROM %s_sb_webhook_deliveries 
+		%s
+		ORDER BY created DESC
+		%s
+	`, dbName, where, limit)
+
+	rows, err := sl.DB.Query(qry, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.WebhookDelivery
+		if err = scanWebhookDelivery(rows, &d); err != nil {
+			return
+		}
+
+		results = append(results, d)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func webhookDeliveryWhere(f model.WebhookDeliveryFilter) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	add := func(clause string, v interface{}) {
+		args = append(args, v)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(f.URL) > 0 {
+		add("url = $%d", f.URL)
+	}
+	if len(f.Event) > 0 {
+		add("event = $%d", f.Event)
+	}
+	if len(f.Status) > 0 {
+		add("status = $%d", f.Status)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func scanWebhookDelivery(rows Scanner, d *model.WebhookDelivery) error {
+	return rows.Scan(
+		&d.ID,
+		&d.URL,
+		&d.Event,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.StatusCode,
+		&d.LastError,
+		&d.NextAttempt,
+		&d.Created,
+		&d.Updated,
+	)
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	now := time.Now()
+	d := model.WebhookDelivery{
+		URL:         "https://hooks.domain.com/deliveries",
+		Event:       model.WebhookUserCreated,
+		Payload:     `{"event":"user.created"}`,
+		Status:      model.WebhookStatusPending,
+		NextAttempt: now.Add(-time.Second),
+		Created:     now,
+		Updated:     now,
+	}
+
+	id, err := datastore.QueueWebhook(confDBName, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	later := d
+	later.Event = model.WebhookUserDeleted
+	later.NextAttempt = now.Add(time.Hour)
+	laterID, err := datastore.QueueWebhook(confDBName, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := datastore.ListDueWebhooks(confDBName, now, 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(due) != 1 || due[0].ID != id {
+		t.Fatalf("expected only the due delivery got %v", due)
+	}
+
+	retry := now.Add(time.Minute)
+	if err := datastore.UpdateWebhookStatus(confDBName, id, model.WebhookStatusPending, 1, 503, "unavailable", retry); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err = datastore.ListDueWebhooks(confDBName, now, 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(due) != 0 {
+		t.Errorf("expected the retried delivery to not be due got %v", due)
+	}
+
+	if err := datastore.UpdateWebhookStatus(confDBName, id, model.WebhookStatusDelivered, 2, 200, "", retry); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered, err := datastore.GetWebhookDelivery(confDBName, id)
+	if err != nil {
+		t.Fatal(err)
+	} else if delivered.Status != model.WebhookStatusDelivered || delivered.Attempts != 2 || delivered.StatusCode != 200 {
+		t.Errorf("unexpected delivery %v", delivered)
+	} else if delivered.URL != d.URL || delivered.Payload != d.Payload {
+		t.Errorf("unexpected delivery %v", delivered)
+	}
+
+	list, err := datastore.ListWebhookDeliveries(confDBName, model.WebhookDeliveryFilter{URL: d.URL, Status: model.WebhookStatusPending})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].ID != laterID {
+		t.Errorf("expected the pending delivery got %v", list)
+	}
+
+	list, err = datastore.ListWebhookDeliveries(confDBName, model.WebhookDeliveryFilter{URL: d.URL, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 {
+		t.Errorf("expected 1 delivery got %d", len(list))
+	}
+}
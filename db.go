@@ -3,6 +3,8 @@ package staticbackend
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -21,6 +23,11 @@ type Database struct {
 }
 
 func (database *Database) dbreq(w http.ResponseWriter, r *http.Request) {
+	write := r.Method != http.MethodGet && !r.URL.Query().Has("ids")
+	if !allowedByScope(w, r, getURLPart(r.URL.Path, 2), write) {
+		return
+	}
+
 	if r.Method == http.MethodPost {
 		if len(r.URL.Query().Get("bulk")) > 0 {
 			database.bulkAdd(w, r)
@@ -69,7 +76,7 @@ func (database *Database) add(w http.ResponseWriter, r *http.Request) {
 	col := getURLPart(r.URL.Path, 2)
 
 	var v interface{}
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+	if err := decodeBody(r, &v); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -86,7 +93,7 @@ func (database *Database) add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusCreated, doc)
+	respondAs(w, r, http.StatusCreated, doc)
 }
 
 func (database *Database) bulkAdd(w http.ResponseWriter, r *http.Request) {
@@ -99,7 +106,7 @@ func (database *Database) bulkAdd(w http.ResponseWriter, r *http.Request) {
 	col := getURLPart(r.URL.Path, 2)
 
 	var v []interface{}
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+	if err := decodeBody(r, &v); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -109,16 +116,32 @@ func (database *Database) bulkAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusCreated, true)
+	respondAs(w, r, http.StatusCreated, true)
 }
 
+// list returns the documents of a collection, the filter and sort query
+// string parameters query the collection without the POST query endpoint
 func (database *Database) list(w http.ResponseWriter, r *http.Request) {
 	page, size := getPagination(r.URL)
 
+	qs := r.URL.Query()
+
 	params := model.ListParams{
 		Page:           page,
 		Size:           size,
-		SortDescending: len(r.URL.Query().Get("desc")) > 0,
+		SortDescending: len(qs.Get("desc")) > 0,
+	}
+
+	if s := qs.Get("sort"); len(s) > 0 {
+		var desc bool
+		params.SortBy, desc = parseSortParam(s)
+		params.SortDescending = params.SortDescending || desc
+	}
+
+	clauses, err := parseFilterParam(qs.Get("filter"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	conf, auth, err := middleware.Extract(r, true)
@@ -129,19 +152,32 @@ func (database *Database) list(w http.ResponseWriter, r *http.Request) {
 
 	col := getURLPart(r.URL.Path, 2)
 
-	result, err := backend.DB.ListDocuments(auth, conf.Name, col, params)
+	var result model.PagedResult
+	if len(clauses) == 0 {
+		result, err = backend.DB.ListDocuments(auth, conf.Name, col, params)
+	} else {
+		var filter map[string]interface{}
+		filter, err = backend.DB.ParseQuery(clauses)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err = backend.DB.QueryDocuments(auth, conf.Name, col, filter, params)
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respond(w, http.StatusOK, result)
+	paginateResult(w, r, &result)
+	respondAs(w, r, http.StatusOK, result)
 }
 
 func (database *Database) count(w http.ResponseWriter, r *http.Request) {
 	var clauses [][]interface{}
 
-	if err := json.NewDecoder(r.Body).Decode(&clauses); err != nil {
+	if err := decodeBody(r, &clauses); err != nil {
 		// Here we don't return an error because filters are optional
 		database.log.Error().Err(err).Msg("error parsing body")
 	}
@@ -162,6 +198,10 @@ func (database *Database) count(w http.ResponseWriter, r *http.Request) {
 
 	col := getURLPart(r.URL.Path, 3)
 
+	if !allowedByScope(w, r, col, false) {
+		return
+	}
+
 	result, err := backend.DB.Count(auth, conf.Name, col, filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -169,7 +209,7 @@ func (database *Database) count(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusOK, map[string]int64{"count": result})
+	respondAs(w, r, http.StatusOK, map[string]int64{"count": result})
 }
 
 func (database *Database) get(w http.ResponseWriter, r *http.Request) {
@@ -188,12 +228,28 @@ func (database *Database) get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusOK, result)
+	etag, err := documentETag(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+
+	if v := r.Header.Get("If-None-Match"); len(v) > 0 && etagMatches(v, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	} else if v := r.Header.Get("If-Match"); len(v) > 0 && !etagMatches(v, etag) {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	respondAs(w, r, http.StatusOK, result)
 }
 
 func (database *Database) query(w http.ResponseWriter, r *http.Request) {
 	var clauses [][]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&clauses); err != nil {
+	if err := decodeBody(r, &clauses); err != nil {
 		database.log.Error().Err(err).Msg("error parsing body")
 
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -226,13 +282,18 @@ func (database *Database) query(w http.ResponseWriter, r *http.Request) {
 
 	col := getURLPart(r.URL.Path, 2)
 
+	if !allowedByScope(w, r, col, false) {
+		return
+	}
+
 	result, err := backend.DB.QueryDocuments(auth, conf.Name, col, filter, params)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respond(w, http.StatusOK, result)
+	paginateResult(w, r, &result)
+	respondAs(w, r, http.StatusOK, result)
 }
 
 func (database *Database) getByIds(w http.ResponseWriter, r *http.Request) {
@@ -245,7 +306,7 @@ func (database *Database) getByIds(w http.ResponseWriter, r *http.Request) {
 	col := getURLPart(r.URL.Path, 2)
 
 	var ids []string
-	if err := parseBody(r.Body, &ids); err != nil {
+	if err := decodeBody(r, &ids); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -261,7 +322,7 @@ func (database *Database) getByIds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusOK, result)
+	respondAs(w, r, http.StatusOK, result)
 }
 
 func (database *Database) update(w http.ResponseWriter, r *http.Request) {
@@ -275,7 +336,7 @@ func (database *Database) update(w http.ResponseWriter, r *http.Request) {
 	id := getURLPart(r.URL.Path, 3)
 
 	var v interface{}
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+	if err := decodeBody(r, &v); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -286,13 +347,21 @@ func (database *Database) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkPreconditions(w, r, conf, auth, col, id) {
+		return
+	}
+
 	result, err := backend.DB.UpdateDocument(auth, conf.Name, col, id, doc)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respond(w, http.StatusOK, result)
+	if etag, err := documentETag(result); err == nil {
+		w.Header().Set("ETag", etag)
+	}
+
+	respondAs(w, r, http.StatusOK, result)
 }
 
 func (database *Database) bulkUpdate(w http.ResponseWriter, r *http.Request) {
@@ -308,7 +377,7 @@ func (database *Database) bulkUpdate(w http.ResponseWriter, r *http.Request) {
 		UpdateFields map[string]any  `json:"update"`
 		Clauses      [][]interface{} `json:"clauses"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+	if err := decodeBody(r, &v); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -325,7 +394,7 @@ func (database *Database) bulkUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusOK, count)
+	respondAs(w, r, http.StatusOK, count)
 }
 
 func (database *Database) increase(w http.ResponseWriter, r *http.Request) {
@@ -339,21 +408,29 @@ func (database *Database) increase(w http.ResponseWriter, r *http.Request) {
 	col := getURLPart(r.URL.Path, 2)
 	id := getURLPart(r.URL.Path, 3)
 
+	if !allowedByScope(w, r, col, true) {
+		return
+	}
+
 	var v = new(struct {
 		Field string `json:"field"`
 		Range int    `json:"range"`
 	})
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+	if err := decodeBody(r, &v); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if !checkPreconditions(w, r, conf, auth, col, id) {
+		return
+	}
+
 	if err := backend.DB.IncrementValue(auth, conf.Name, col, id, v.Field, v.Range); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respond(w, http.StatusOK, true)
+	respondAs(w, r, http.StatusOK, true)
 }
 
 func (database *Database) del(w http.ResponseWriter, r *http.Request) {
@@ -366,13 +443,19 @@ func (database *Database) del(w http.ResponseWriter, r *http.Request) {
 	col := getURLPart(r.URL.Path, 2)
 	id := getURLPart(r.URL.Path, 3)
 
+	if !checkPreconditions(w, r, conf, auth, col, id) {
+		return
+	}
+
 	count, err := backend.DB.DeleteDocument(auth, conf.Name, col, id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respond(w, http.StatusOK, count)
+	recordRootOperation(r, conf, auth, fmt.Sprintf("deleted the document %s of %s", id, col))
+
+	respondAs(w, r, http.StatusOK, count)
 }
 
 func (database *Database) bulkDelete(w http.ResponseWriter, r *http.Request) {
@@ -416,7 +499,9 @@ func (database *Database) bulkDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusOK, count)
+	recordRootOperation(r, conf, auth, fmt.Sprintf("deleted %d documents of %s", count, col))
+
+	respondAs(w, r, http.StatusOK, count)
 }
 
 func (database *Database) newID(w http.ResponseWriter, r *http.Request) {
@@ -463,10 +548,36 @@ func (database *Database) index(w http.ResponseWriter, r *http.Request) {
 	respond(w, http.StatusOK, true)
 }
 
+// allowedByScope writes a 403 and returns false when the request is made
+// with a derived token whose scope does not allow this operation.
+func allowedByScope(w http.ResponseWriter, r *http.Request, col string, write bool) bool {
+	_, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		// the handler reports the missing auth
+		return true
+	}
+
+	allowed := auth.CanRead(col)
+	if write {
+		allowed = auth.CanWrite(col)
+	}
+
+	if !allowed {
+		http.Error(w, "this token's scope does not allow this operation", http.StatusForbidden)
+	}
+	return allowed
+}
+
+// getPagination returns the page and size of a request, the cursor of a
+// paged response takes precedence over the page
 func getPagination(u *url.URL) (page int64, size int64) {
 	var err error
 
-	page, err = strconv.ParseInt(u.Query().Get("page"), 10, 64)
+	if cursor := u.Query().Get("cursor"); len(cursor) > 0 {
+		page, err = parseCursor(cursor)
+	} else {
+		page, err = strconv.ParseInt(u.Query().Get("page"), 10, 64)
+	}
 	if err != nil {
 		page = 1
 	}
@@ -492,11 +603,20 @@ func (database *Database) search(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var data SearchData
-	if err := parseBody(r.Body, &data); err != nil {
+	if err := decodeBody(r, &data); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	if !allowedByScope(w, r, data.Col, false) {
+		return
+	}
+
+	if backend.Search == nil {
+		http.Error(w, backend.ErrSearchDisabled.Error(), http.StatusNotImplemented)
+		return
+	}
+
 	result, err := backend.Search.Search(conf.Name, data.Col, data.Keywords)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -509,5 +629,31 @@ func (database *Database) search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respond(w, http.StatusOK, docs)
+	respondAs(w, r, http.StatusOK, docs)
+}
+
+// reindex indexes the existing documents of a collection in the search
+// settings, the later changes are indexed as they happen
+func (database *Database) reindex(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	col := getURLPart(r.URL.Path, 4)
+
+	n, err := backend.ReindexCollection(conf, col)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, backend.ErrSearchDisabled) {
+			status = http.StatusNotImplemented
+		} else if errors.Is(err, backend.ErrCollectionNotIndexed) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	respond(w, http.StatusOK, n)
 }
@@ -526,3 +526,96 @@ func TestDBSearchIndexAndQuery(t *testing.T) {
 		t.Errorf("expected task id to be %s got %s", createdTask.ID, tasks[0].ID)
 	}
 }
+
+func TestDBSearchDocumentChanges(t *testing.T) {
+	create := func(title string) Task {
+		resp := dbReq(t, db.add, "POST", "/db/search_notes", Task{Title: title, Created: time.Now()})
+		defer resp.Body.Close()
+
+		if resp.StatusCode > 299 {
+			t.Fatal(GetResponseBody(t, resp))
+		}
+
+		var task Task
+		if err := parseBody(resp.Body, &task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+
+	find := func(keywords string) []Task {
+		resp := dbReq(t, db.search, "POST", "/search", SearchData{Col: "search_notes", Keywords: keywords})
+		defer resp.Body.Close()
+
+		if resp.StatusCode > 299 {
+			t.Fatal(GetResponseBody(t, resp))
+		}
+
+		var tasks []Task
+		if err := parseBody(resp.Body, &tasks); err != nil {
+			t.Fatal(err)
+		}
+		return tasks
+	}
+
+	// created before the collection is indexed
+	existing := create("existing waterfall")
+
+	s := model.AppSettings{
+		Search: model.SearchSettings{
+			Collections: []model.SearchCollection{{Name: "search_notes", Fields: []string{"title"}}},
+		},
+	}
+
+	resp := dbReq(t, settings, "POST", "/account/settings", s, true)
+	resp.Body.Close()
+	if resp.StatusCode > 299 {
+		t.Fatalf("expected the settings to be saved got status %d", resp.StatusCode)
+	}
+
+	defer func() {
+		resp := dbReq(t, settings, "POST", "/account/settings", model.AppSettings{}, true)
+		resp.Body.Close()
+	}()
+
+	task := create("hydroelectric dam")
+
+	// wait for the document event and the index event
+	time.Sleep(3 * time.Second)
+
+	if tasks := find("hydroelectric"); len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Errorf("expected the new document to be found got %v", tasks)
+	}
+
+	resp = dbReq(t, db.reindex, "POST", "/sudo/search/reindex/search_notes", nil, true)
+	defer resp.Body.Close()
+
+	var n int64
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &n); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Errorf("expected 2 documents reindexed got %d", n)
+	}
+
+	// the dev pubsub does not keep the order of the messages
+	time.Sleep(time.Second)
+
+	del := dbReq(t, db.del, "DELETE", "/db/search_notes/"+task.ID, nil)
+	del.Body.Close()
+
+	time.Sleep(3 * time.Second)
+
+	if tasks := find("waterfall"); len(tasks) != 1 || tasks[0].ID != existing.ID {
+		t.Errorf("expected the reindexed document to be found got %v", tasks)
+	} else if tasks := find("hydroelectric"); len(tasks) != 0 {
+		t.Errorf("expected the deleted document to be removed got %v", tasks)
+	}
+
+	resp = dbReq(t, db.reindex, "POST", "/sudo/search/reindex/tasks", nil, true)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a collection not indexed got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,91 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+// appDeletion returns, requests or cancels the deletion of the database
+// from /account/delete. The request emails a confirmation token to the
+// tenant.
+func appDeletion(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		pending, err := backend.DB.GetAppDeletion(conf.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		} else if len(pending.ID) == 0 {
+			http.Error(w, backend.ErrNoDeletionPending.Error(), http.StatusNotFound)
+			return
+		}
+
+		respond(w, http.StatusOK, pending)
+	case http.MethodPost:
+		if err := backend.RequestAppDeletion(conf); err != nil {
+			http.Error(w, err.Error(), deletionStatus(err))
+			return
+		}
+
+		respond(w, http.StatusOK, true)
+	case http.MethodDelete:
+		if err := backend.CancelAppDeletion(conf); err != nil {
+			http.Error(w, err.Error(), deletionStatus(err))
+			return
+		}
+
+		respond(w, http.StatusOK, true)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// confirmAppDeletion schedules the deletion of the database with the
+// emailed confirmation token from /account/delete/confirm
+func confirmAppDeletion(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data struct {
+		Token string `json:"token"`
+	}
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	d, err := backend.ConfirmAppDeletion(conf, data.Token)
+	if err != nil {
+		http.Error(w, err.Error(), deletionStatus(err))
+		return
+	}
+
+	recordRootOperation(r, conf, auth, "scheduled the deletion of the database")
+
+	respond(w, http.StatusOK, d)
+}
+
+// deletionStatus returns the status of a failed deletion request
+func deletionStatus(err error) int {
+	switch {
+	case errors.Is(err, backend.ErrInvalidDeletionToken):
+		return http.StatusBadRequest
+	case errors.Is(err, backend.ErrNoDeletionPending):
+		return http.StatusNotFound
+	case errors.Is(err, backend.ErrDeletionPending):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
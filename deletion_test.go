@@ -0,0 +1,61 @@
+package staticbackend
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAppDeletionRequest(t *testing.T) {
+	resp := dbReq(t, appDeletion, "POST", "/account/delete", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	resp = dbReq(t, appDeletion, "GET", "/account/delete", nil, true)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 before the confirmation got %d", resp.StatusCode)
+	}
+
+	data := map[string]string{"token": "wrong"}
+	resp = dbReq(t, confirmAppDeletion, "POST", "/account/delete/confirm", data, true)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 with a wrong token got %d", resp.StatusCode)
+	}
+
+	token, err := backend.Cache.Get("appdel-" + pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data["token"] = token
+	resp = dbReq(t, confirmAppDeletion, "POST", "/account/delete/confirm", data, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+	defer backend.CancelAppDeletion(model.DatabaseConfig{ID: pubKey})
+
+	var d model.AppDeletion
+	if err := parseBody(resp.Body, &d); err != nil {
+		t.Fatal(err)
+	} else if d.Status != model.AppDeletionPending || !d.Scheduled.After(d.Requested) {
+		t.Errorf("expected a pending deletion after the grace period got %v", d)
+	}
+
+	resp = dbReq(t, appDeletion, "GET", "/account/delete", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the pending deletion got %d", resp.StatusCode)
+	}
+
+	resp = dbReq(t, appDeletion, "DELETE", "/account/delete", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	resp = dbReq(t, appDeletion, "DELETE", "/account/delete", nil, true)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 once cancelled got %d", resp.StatusCode)
+	}
+}
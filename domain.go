@@ -0,0 +1,124 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// domainInfo is a custom domain with the DNS records to create, the CNAME
+// pointing to the instance and the TXT record verifying its ownership
+type domainInfo struct {
+	model.Domain
+	Target string `json:"target"`
+	Record string `json:"record"`
+}
+
+func newDomainInfo(d model.Domain) domainInfo {
+	return domainInfo{Domain: d, Target: backend.AppHost(), Record: d.VerificationRecord()}
+}
+
+// domains lists the custom domains of the database with GET and adds one
+// with POST {hostname} from /domain
+func domains(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := backend.ListDomains(conf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		results := make([]domainInfo, 0, len(list))
+		for _, d := range list {
+			results = append(results, newDomainInfo(d))
+		}
+
+		respond(w, http.StatusOK, results)
+	case http.MethodPost:
+		var data struct {
+			Hostname string `json:"hostname"`
+		}
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		d, err := backend.AddDomain(conf, data.Hostname)
+		if err != nil {
+			http.Error(w, err.Error(), domainStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "added the custom domain "+d.Hostname)
+
+		respond(w, http.StatusCreated, newDomainInfo(d))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// domainActions handles a custom domain of the database from
+// /domain/{hostname}: POST /domain/{hostname}/verify looks up its TXT
+// record and DELETE removes it.
+func domainActions(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	host := getURLPart(r.URL.Path, 2)
+	if len(host) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case getURLPart(r.URL.Path, 3) == "verify" && r.Method == http.MethodPost:
+		d, err := backend.VerifyDomain(conf, host)
+		if err != nil {
+			http.Error(w, err.Error(), domainStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "verified the custom domain "+d.Hostname)
+
+		respond(w, http.StatusOK, newDomainInfo(d))
+	case r.Method == http.MethodDelete:
+		if err := backend.DeleteDomain(conf, host); err != nil {
+			http.Error(w, err.Error(), domainStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "removed the custom domain "+host)
+
+		respond(w, http.StatusOK, true)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// domainStatus returns the status of a failed custom domain request
+func domainStatus(err error) int {
+	switch {
+	case errors.Is(err, backend.ErrInvalidDomain):
+		return http.StatusBadRequest
+	case errors.Is(err, backend.ErrDomainTaken):
+		return http.StatusConflict
+	case errors.Is(err, backend.ErrDomainNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, backend.ErrDomainNotVerified):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
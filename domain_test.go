@@ -0,0 +1,115 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+func TestCustomDomains(t *testing.T) {
+	const host = "api.domain-test.com"
+
+	resp := dbReq(t, domains, "POST", "/domain", map[string]string{"hostname": "not a domain"}, true)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 with an invalid hostname got %s", resp.Status)
+	}
+
+	resp = dbReq(t, domains, "POST", "/domain", map[string]string{"hostname": "API.domain-test.com."}, true)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+	defer dbReq(t, domainActions, "DELETE", "/domain/"+host, nil, true)
+
+	var d domainInfo
+	if err := parseBody(resp.Body, &d); err != nil {
+		t.Fatal(err)
+	} else if d.Hostname != host || d.Verified || d.Record != "_sb-verification."+host {
+		t.Fatalf("unexpected domain %v", d)
+	}
+
+	resp = dbReq(t, domains, "POST", "/domain", map[string]string{"hostname": host}, true)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status 409 adding the domain twice got %s", resp.Status)
+	}
+
+	// the requests to the domain are echoed with the public key they got
+	h := middleware.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Header.Get("SB-PUBLIC-KEY"), r.URL.Path)
+	}), middleware.CustomDomains(backend.DB, backend.Cache, "localhost"))
+
+	domainReq := func(host, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Host = host + ":443"
+		req.Header.Set("SB-PUBLIC-KEY", "another-app")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := domainReq(host, "/postform/contact"); w.Code != http.StatusNotFound {
+		t.Errorf("expected an unverified domain to not be served got %d", w.Code)
+	}
+
+	lookup := backend.LookupTXT
+	defer func() { backend.LookupTXT = lookup }()
+
+	var records []string
+	backend.LookupTXT = func(name string) ([]string, error) {
+		if name != d.Record {
+			return nil, fmt.Errorf("no such host %s", name)
+		}
+		return records, nil
+	}
+
+	resp = dbReq(t, domainActions, "POST", "/domain/"+host+"/verify", nil, true)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422 without the TXT record got %s", resp.Status)
+	}
+
+	records = []string{"v=spf1 -all", d.Token}
+
+	resp = dbReq(t, domainActions, "POST", "/domain/"+host+"/verify", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &d); err != nil {
+		t.Fatal(err)
+	} else if !d.Verified {
+		t.Fatalf("expected the domain to be verified got %v", d)
+	}
+
+	tests := []struct {
+		host   string
+		path   string
+		status int
+		body   string
+	}{
+		{host, "/postform/contact", http.StatusOK, pubKey + " /postform/contact"},
+		{host, "/v1/fn/exec/hello", http.StatusOK, pubKey + " /v1/fn/exec/hello"},
+		{host, "/db/tasks", http.StatusNotFound, "404 page not found\n"},
+		{"localhost", "/db/tasks", http.StatusOK, "another-app /db/tasks"},
+		{"unknown.domain-test.com", "/db/tasks", http.StatusOK, "another-app /db/tasks"},
+	}
+
+	for _, tc := range tests {
+		w := domainReq(tc.host, tc.path)
+		if w.Code != tc.status {
+			t.Errorf("%s%s: expected status %d got %d", tc.host, tc.path, tc.status, w.Code)
+		} else if w.Body.String() != tc.body {
+			t.Errorf("%s%s: expected %q got %q", tc.host, tc.path, tc.body, w.Body.String())
+		}
+	}
+
+	resp = dbReq(t, domainActions, "DELETE", "/domain/"+host, nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	if w := domainReq(host, "/postform/contact"); w.Body.String() != "another-app /postform/contact" {
+		t.Errorf("expected a removed domain to not be served got %q", w.Body.String())
+	}
+}
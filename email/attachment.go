@@ -0,0 +1,59 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+const (
+	// MaxAttachments is the maximum number of attachments of an email
+	MaxAttachments = 10
+	// MaxAttachmentsSize is the maximum total size in bytes of the
+	// attachments of an email
+	MaxAttachmentsSize = 10 << 20
+)
+
+// ErrInvalidAttachment is returned when an attachment has no name or
+// content or the limits are exceeded
+var ErrInvalidAttachment = errors.New("invalid email attachment")
+
+// ValidateAttachments checks the attachments to send have a file name and
+// content within MaxAttachments and MaxAttachmentsSize
+func ValidateAttachments(attachments []model.EmailAttachment) error {
+	if len(attachments) > MaxAttachments {
+		return fmt.Errorf("%w: at most %d attachments", ErrInvalidAttachment, MaxAttachments)
+	}
+
+	size := 0
+	for _, a := range attachments {
+		if len(a.Filename) == 0 {
+			return fmt.Errorf("%w: the file name is required", ErrInvalidAttachment)
+		} else if len(a.Content) == 0 {
+			return fmt.Errorf("%w: %s is empty", ErrInvalidAttachment, a.Filename)
+		}
+		size += len(a.Content)
+	}
+
+	if size > MaxAttachmentsSize {
+		return fmt.Errorf("%w: the attachments exceed %d bytes", ErrInvalidAttachment, MaxAttachmentsSize)
+	}
+	return nil
+}
+
+// attachmentType returns the content type of an attachment, detected from
+// its file name or content when not set
+func attachmentType(a model.EmailAttachment) string {
+	if len(a.ContentType) > 0 {
+		return a.ContentType
+	}
+
+	if ct := mime.TypeByExtension(filepath.Ext(a.Filename)); len(ct) > 0 {
+		return ct
+	}
+	return http.DetectContentType(a.Content)
+}
@@ -0,0 +1,128 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+var testAttachment = model.EmailAttachment{
+	Filename: "invoice.pdf",
+	Content:  bytes.Repeat([]byte("%PDF-1.4 "), 20),
+}
+
+func TestValidateAttachments(t *testing.T) {
+	tooMany := make([]model.EmailAttachment, MaxAttachments+1)
+	for i := range tooMany {
+		tooMany[i] = testAttachment
+	}
+
+	tests := []struct {
+		name        string
+		attachments []model.EmailAttachment
+		valid       bool
+	}{
+		{"valid", []model.EmailAttachment{testAttachment}, true},
+		{"no name", []model.EmailAttachment{{Content: []byte("x")}}, false},
+		{"empty", []model.EmailAttachment{{Filename: "a.txt"}}, false},
+		{"too many", tooMany, false},
+		{"too large", []model.EmailAttachment{{Filename: "a.bin", Content: make([]byte, MaxAttachmentsSize+1)}}, false},
+	}
+
+	for _, tc := range tests {
+		err := ValidateAttachments(tc.attachments)
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		} else if !tc.valid && !errors.Is(err, ErrInvalidAttachment) {
+			t.Errorf("%s: expected ErrInvalidAttachment got %v", tc.name, err)
+		}
+	}
+}
+
+func TestBuildMessageAttachments(t *testing.T) {
+	data := testMail
+	data.Attachments = []model.EmailAttachment{testAttachment}
+
+	b, err := buildMessage(data, messageID(data.From))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	} else if mediaType != "multipart/mixed" {
+		t.Fatalf("expected multipart/mixed got %s", mediaType)
+	}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	alt, err := mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.HasPrefix(alt.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("expected the alternatives first got %s", alt.Header.Get("Content-Type"))
+	}
+
+	part, err := mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	} else if part.FileName() != testAttachment.Filename {
+		t.Errorf("expected file name %s got %s", testAttachment.Filename, part.FileName())
+	} else if ct := part.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/pdf") {
+		t.Errorf("expected application/pdf got %s", ct)
+	}
+
+	// the multipart reader only decodes the quoted-printable parts
+	enc, err := io.ReadAll(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(enc), "\r\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(content, testAttachment.Content) {
+		t.Errorf("unexpected attachment content %s", content)
+	}
+}
+
+func TestMailgunMultipart(t *testing.T) {
+	form := url.Values{}
+	form.Set("to", "user@domain.com")
+
+	body, contentType, err := mailgunMultipart(form, []model.EmailAttachment{testAttachment})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := multipart.NewReader(body, params["boundary"]).ReadForm(MaxAttachmentsSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if to := f.Value["to"]; len(to) != 1 || to[0] != "user@domain.com" {
+		t.Errorf("unexpected to %v", to)
+	} else if files := f.File["attachment"]; len(files) != 1 || files[0].Filename != testAttachment.Filename {
+		t.Errorf("unexpected attachments %v", files)
+	}
+}
@@ -13,6 +13,9 @@ func (d Dev) Send(data SendMailData) error {
 	fmt.Println("to: ", data.To)
 	fmt.Println("subject: ", data.Subject)
 	fmt.Printf("body\n%s\n\n", data.TextBody)
+	for _, a := range data.Attachments {
+		fmt.Printf("attachment: %s (%d bytes)\n", a.Filename, len(a.Content))
+	}
 	fmt.Println("====== /SENDING EMAIL ======")
 	return nil
 }
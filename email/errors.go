@@ -0,0 +1,80 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// The errors of the mail providers are mapped to these errors, a
+// ProviderError wrapping one of them is returned by the mailers
+var (
+	// ErrUnauthorized the provider rejected the credentials
+	ErrUnauthorized = errors.New("the mail provider rejected the credentials")
+	// ErrRejected the provider rejected the email, i.e. an invalid or
+	// suppressed recipient or an unverified sender
+	ErrRejected = errors.New("the mail provider rejected the email")
+	// ErrRateLimited the provider's sending rate or quota is exceeded, the
+	// email can be sent again later
+	ErrRateLimited = errors.New("the mail provider's sending limit is exceeded")
+	// ErrUnavailable the provider failed to handle the request, the email can
+	// be sent again later
+	ErrUnavailable = errors.New("the mail provider is unavailable")
+)
+
+// ProviderError is an error returned by a mail provider with its message
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("%s: %v", e.Provider, e.Err)
+	}
+	return fmt.Sprintf("%s: %v: %s", e.Provider, e.Err, e.Message)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// errorFromStatus maps the HTTP status of a provider API's error response
+func errorFromStatus(code int) error {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrUnauthorized
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code >= http.StatusInternalServerError:
+		return ErrUnavailable
+	}
+	return ErrRejected
+}
+
+// postAPI sends a request to a provider's API and returns the headers and
+// body of a successful response. The body of error responses is passed to
+// parseError which returns the provider's message and error.
+func postAPI(client *http.Client, provider string, req *http.Request, parseError func(code int, body []byte) (string, error)) (http.Header, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, &ProviderError{Provider: provider, Message: err.Error(), Err: ErrUnavailable}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.Header, body, nil
+	}
+
+	msg, perr := parseError(resp.StatusCode, body)
+	return nil, nil, &ProviderError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Message:    msg,
+		Err:        perr,
+	}
+}
@@ -0,0 +1,171 @@
+package email
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// snsEnvelope is the message posted by Amazon SNS to an HTTP subscription
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type sesRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	DiagnosticCode string `json:"diagnosticCode"`
+}
+
+// sesNotification is a SES bounce or complaint notification, the event
+// publishing of the configuration sets uses eventType
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string         `json:"bounceType"`
+		BounceSubType     string         `json:"bounceSubType"`
+		BouncedRecipients []sesRecipient `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplaintFeedbackType string         `json:"complaintFeedbackType"`
+		ComplainedRecipients  []sesRecipient `json:"complainedRecipients"`
+	} `json:"complaint"`
+}
+
+// ParseSESNotification returns the bounces and complaints of a SES
+// notification delivered by Amazon SNS. The subscribe URL is returned for
+// a subscription confirmation.
+func ParseSESNotification(body []byte) (events []model.EmailEvent, subscribeURL string, err error) {
+	var env snsEnvelope
+	if err = json.Unmarshal(body, &env); err != nil {
+		return
+	}
+
+	switch env.Type {
+	case "SubscriptionConfirmation":
+		subscribeURL = env.SubscribeURL
+		return
+	case "Notification":
+	default:
+		return
+	}
+
+	var n sesNotification
+	if err = json.Unmarshal([]byte(env.Message), &n); err != nil {
+		return
+	}
+
+	typ := n.NotificationType
+	if len(typ) == 0 {
+		typ = n.EventType
+	}
+
+	now := time.Now()
+	switch typ {
+	case "Bounce":
+		for _, r := range n.Bounce.BouncedRecipients {
+			detail := r.DiagnosticCode
+			if len(detail) == 0 {
+				detail = n.Bounce.BounceType + " " + n.Bounce.BounceSubType
+			}
+
+			events = append(events, model.EmailEvent{
+				Email:     strings.ToLower(r.EmailAddress),
+				Type:      model.EmailEventBounce,
+				Permanent: n.Bounce.BounceType == "Permanent",
+				Provider:  MailProviderSES,
+				Detail:    detail,
+				Created:   now,
+			})
+		}
+	case "Complaint":
+		for _, r := range n.Complaint.ComplainedRecipients {
+			events = append(events, model.EmailEvent{
+				Email:    strings.ToLower(r.EmailAddress),
+				Type:     model.EmailEventComplaint,
+				Provider: MailProviderSES,
+				Detail:   n.Complaint.ComplaintFeedbackType,
+				Created:  now,
+			})
+		}
+	}
+	return
+}
+
+// ConfirmSNSSubscription confirms an Amazon SNS subscription by visiting
+// its subscribe URL, only the SNS endpoints are requested
+func ConfirmSNSSubscription(subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil {
+		return err
+	}
+
+	host := u.Hostname()
+	if u.Scheme != "https" || !strings.HasPrefix(host, "sns.") || !strings.HasSuffix(host, ".amazonaws.com") {
+		return fmt.Errorf("invalid SNS subscribe URL %s", subscribeURL)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	res, err := client.Get(u.String())
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("SNS subscription confirmation returned %s", res.Status)
+	}
+	return nil
+}
+
+// sendGridEvent is an event of the SendGrid event webhook
+type sendGridEvent struct {
+	Email  string `json:"email"`
+	Event  string `json:"event"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+	Status string `json:"status"`
+}
+
+// ParseSendGridEvents returns the bounces and complaints of a SendGrid event
+// webhook batch, a blocked email is a soft bounce
+func ParseSendGridEvents(body []byte) ([]model.EmailEvent, error) {
+	var batch []sendGridEvent
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, errors.New("expected an array of SendGrid events")
+	}
+
+	now := time.Now()
+
+	var events []model.EmailEvent
+	for _, e := range batch {
+		switch e.Event {
+		case "bounce":
+			events = append(events, model.EmailEvent{
+				Email:     strings.ToLower(e.Email),
+				Type:      model.EmailEventBounce,
+				Permanent: e.Type != "blocked",
+				Provider:  MailProviderSendGrid,
+				Detail:    strings.TrimSpace(e.Status + " " + e.Reason),
+				Created:   now,
+			})
+		case "spamreport":
+			events = append(events, model.EmailEvent{
+				Email:    strings.ToLower(e.Email),
+				Type:     model.EmailEventComplaint,
+				Provider: MailProviderSendGrid,
+				Detail:   "spam report",
+				Created:  now,
+			})
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,98 @@
+package email
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func snsNotification(t *testing.T, typ, message string) []byte {
+	b, err := json.Marshal(snsEnvelope{Type: typ, Message: message, SubscribeURL: "https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseSESNotification(t *testing.T) {
+	bounce := `{"notificationType":"Bounce","bounce":{"bounceType":"Permanent","bounceSubType":"General",
+		"bouncedRecipients":[{"emailAddress":"Hard@Domain.com","diagnosticCode":"smtp; 550 5.1.1 user unknown"}]}}`
+
+	events, subscribeURL, err := ParseSESNotification(snsNotification(t, "Notification", bounce))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(subscribeURL) > 0 {
+		t.Errorf("unexpected subscribe URL %s", subscribeURL)
+	} else if len(events) != 1 || events[0].Email != "hard@domain.com" || !events[0].Permanent || events[0].Type != model.EmailEventBounce {
+		t.Fatalf("expected a hard bounce got %v", events)
+	}
+
+	transient := `{"eventType":"Bounce","bounce":{"bounceType":"Transient","bounceSubType":"MailboxFull",
+		"bouncedRecipients":[{"emailAddress":"full@domain.com"}]}}`
+
+	events, _, err = ParseSESNotification(snsNotification(t, "Notification", transient))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 1 || events[0].Permanent || events[0].Detail != "Transient MailboxFull" {
+		t.Fatalf("expected a soft bounce got %v", events)
+	}
+
+	complaint := `{"notificationType":"Complaint","complaint":{"complaintFeedbackType":"abuse",
+		"complainedRecipients":[{"emailAddress":"angry@domain.com"}]}}`
+
+	events, _, err = ParseSESNotification(snsNotification(t, "Notification", complaint))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 1 || events[0].Type != model.EmailEventComplaint || events[0].Detail != "abuse" {
+		t.Fatalf("expected a complaint got %v", events)
+	}
+
+	events, subscribeURL, err = ParseSESNotification(snsNotification(t, "SubscriptionConfirmation", ""))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) > 0 || len(subscribeURL) == 0 {
+		t.Errorf("expected the subscribe URL got %v %s", events, subscribeURL)
+	}
+}
+
+func TestParseSendGridEvents(t *testing.T) {
+	body := []byte(`[
+		{"email":"hard@domain.com","event":"bounce","type":"bounce","status":"5.1.1","reason":"user unknown"},
+		{"email":"blocked@domain.com","event":"bounce","type":"blocked","reason":"blocked"},
+		{"email":"angry@domain.com","event":"spamreport"},
+		{"email":"ok@domain.com","event":"delivered"}
+	]`)
+
+	events, err := ParseSendGridEvents(body)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 3 {
+		t.Fatalf("expected 3 events got %v", events)
+	}
+
+	if !events[0].Permanent || events[0].Detail != "5.1.1 user unknown" {
+		t.Errorf("expected a hard bounce got %v", events[0])
+	} else if events[1].Permanent {
+		t.Errorf("expected a blocked email to be a soft bounce got %v", events[1])
+	} else if events[2].Type != model.EmailEventComplaint {
+		t.Errorf("expected a complaint got %v", events[2])
+	}
+
+	if _, err := ParseSendGridEvents([]byte(`{"event":"bounce"}`)); err == nil {
+		t.Error("expected an error for a payload that is not an array")
+	}
+}
+
+func TestConfirmSNSSubscriptionHost(t *testing.T) {
+	invalid := []string{
+		"http://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription",
+		"https://attacker.com/?Action=ConfirmSubscription",
+		"https://sns.us-east-1.amazonaws.com.attacker.com/",
+	}
+	for _, u := range invalid {
+		if err := ConfirmSNSSubscription(u); err == nil {
+			t.Errorf("expected %s to be rejected", u)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package email
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// DefaultLocale is the locale of the built-in templates used when none of
+// the requested locales has a translation
+const DefaultLocale = "en"
+
+var localeName = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// NormalizeLocale returns a locale in lowercase with its region separated
+// by a dash, fr_CA becomes fr-ca. It returns false when the locale is not
+// a language tag.
+func NormalizeLocale(locale string) (string, bool) {
+	locale = strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+	return locale, localeName.MatchString(locale)
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header by
+// order of preference. The wildcard and the invalid or refused (q=0)
+// languages are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type lang struct {
+		locale string
+		q      float64
+	}
+
+	var langs []lang
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+
+		locale, ok := NormalizeLocale(fields[0])
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				v, err := strconv.ParseFloat(strings.TrimPrefix(f, "q="), 64)
+				if err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		langs = append(langs, lang{locale: locale, q: q})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	locales := make([]string, 0, len(langs))
+	for _, l := range langs {
+		locales = append(locales, l.locale)
+	}
+	return locales
+}
+
+// LocaleCandidates returns the locales to look for in order, each locale
+// with a region is followed by its language: fr-ca, de gives fr-ca, fr, de.
+// The invalid locales are skipped.
+func LocaleCandidates(locales ...string) []string {
+	seen := make(map[string]bool)
+
+	var candidates []string
+	add := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			candidates = append(candidates, locale)
+		}
+	}
+
+	for _, l := range locales {
+		locale, ok := NormalizeLocale(l)
+		if !ok {
+			continue
+		}
+
+		add(locale)
+		if i := strings.Index(locale, "-"); i > 0 {
+			add(locale[:i])
+		}
+	}
+	return candidates
+}
+
+// LocalizedName returns the name of a template's translation, the
+// password-reset template in French is named password-reset.fr
+func LocalizedName(name, locale string) string {
+	return name + "." + locale
+}
+
+// SystemTemplate returns the built-in translation of a system email
+// template in the first of the locales having one, or in the DefaultLocale.
+// It returns false when the template has no built-in translations.
+func SystemTemplate(name string, locales []string) (model.EmailTemplate, bool) {
+	translations, ok := systemTemplates[name]
+	if !ok {
+		return model.EmailTemplate{}, false
+	}
+
+	for _, locale := range LocaleCandidates(locales...) {
+		if tmpl, ok := translations[locale]; ok {
+			return tmpl, true
+		}
+	}
+	return translations[DefaultLocale], true
+}
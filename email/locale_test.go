@@ -0,0 +1,63 @@
+package email
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	locales := ParseAcceptLanguage("de;q=0.7, fr-CA, fr;q=0.9, *;q=0.5, es;q=0, en_US;q=0.8")
+
+	expected := []string{"fr-ca", "fr", "en-us", "de"}
+	if !reflect.DeepEqual(locales, expected) {
+		t.Errorf("expected %v got %v", expected, locales)
+	}
+
+	if locales := ParseAcceptLanguage(""); len(locales) != 0 {
+		t.Errorf("expected no locales got %v", locales)
+	}
+}
+
+func TestLocaleCandidates(t *testing.T) {
+	candidates := LocaleCandidates("fr-CA", "invalid locale", "de", "fr")
+
+	expected := []string{"fr-ca", "fr", "de"}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Errorf("expected %v got %v", expected, candidates)
+	}
+}
+
+func TestSystemTemplate(t *testing.T) {
+	tmpl, ok := SystemTemplate(model.EmailTemplatePasswordReset, []string{"it", "fr-ca"})
+	if !ok {
+		t.Fatal("expected a built-in password reset template")
+	} else if tmpl.Name != model.EmailTemplatePasswordReset || tmpl.Subject != systemTemplates[tmpl.Name]["fr"].Subject {
+		t.Errorf("expected the French translation got %v", tmpl)
+	}
+
+	// without translation the default locale is used
+	tmpl, _ = SystemTemplate(model.EmailTemplateMagicLink, []string{"it"})
+	if tmpl.Subject != systemTemplates[model.EmailTemplateMagicLink][DefaultLocale].Subject {
+		t.Errorf("expected the default translation got %v", tmpl)
+	}
+
+	if _, ok := SystemTemplate("welcome", nil); ok {
+		t.Error("expected no built-in welcome template")
+	}
+
+	// every translation renders with the variables the emails provide
+	for name, translations := range systemTemplates {
+		for locale, tmpl := range translations {
+			if err := ValidateTemplate(tmpl); err != nil {
+				t.Errorf("%s in %s: %v", name, locale, err)
+			}
+
+			vars := map[string]any{"code": "abc", "link": "https://app.com", "email": "a@b.com", "note": "hi"}
+			if _, err := Render(tmpl, vars); err != nil {
+				t.Errorf("%s in %s: %v", name, locale, err)
+			}
+		}
+	}
+}
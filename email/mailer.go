@@ -1,8 +1,19 @@
 package email
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
 const (
-	MailProviderDev = "dev"
-	MailProviderSES = "ses"
+	MailProviderDev      = "dev"
+	MailProviderSES      = "ses"
+	MailProviderSMTP     = "smtp"
+	MailProviderSendGrid = "sendgrid"
+	MailProviderMailgun  = "mailgun"
+	MailProviderPostmark = "postmark"
 )
 
 // SendMailData contains necessary fields to send an email
@@ -15,6 +26,10 @@ type SendMailData struct {
 	HTMLBody string `json:"htmlBody"`
 	TextBody string `json:"textBody"`
 	ReplyTo  string `json:"replyTo"`
+	// Template is the name of the email template rendered, if any
+	Template string `json:"template"`
+
+	Attachments []model.EmailAttachment `json:"attachments"`
 
 	Body string `json:"body"`
 }
@@ -24,3 +39,50 @@ type Mailer interface {
 	// Send sends the email
 	Send(SendMailData) error
 }
+
+// MessageSender is implemented by the mailers returning the provider's ID
+// of the sent email
+type MessageSender interface {
+	// SendMessage sends the email and returns its provider message ID
+	SendMessage(SendMailData) (string, error)
+}
+
+// Pinger is implemented by the mailers able to check their provider is
+// reachable without sending an email
+type Pinger interface {
+	// Ping returns an error when the provider cannot be reached
+	Ping() error
+}
+
+// Send sends the email with the mailer and returns the provider message ID
+// when the mailer is a MessageSender
+func Send(m Mailer, data SendMailData) (string, error) {
+	if ms, ok := m.(MessageSender); ok {
+		return ms.SendMessage(data)
+	}
+	return "", m.Send(data)
+}
+
+// NewMailer returns the mailer of the provider selected in the settings
+// using its credentials, the dev mailer when no provider is selected
+func NewMailer(s model.EmailSettings) (Mailer, error) {
+	switch strings.ToLower(s.Provider) {
+	case "", MailProviderDev:
+		return Dev{}, nil
+	case MailProviderSES:
+		return AWSSES{Region: s.Region, AccessKeyID: s.APIKey, SecretAccessKey: s.APISecret}, nil
+	case MailProviderSMTP:
+		m, err := NewSMTP(s.Host, s.Username, s.Password)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case MailProviderSendGrid:
+		return NewSendGrid(s.APIKey)
+	case MailProviderMailgun:
+		return NewMailgun(s.APIKey, s.Domain, s.Region)
+	case MailProviderPostmark:
+		return NewPostmark(s.APIKey)
+	}
+	return nil, fmt.Errorf("unknown mail provider %s", s.Provider)
+}
@@ -0,0 +1,162 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// Mailgun sends emails with the Mailgun messages API from a sending domain
+type Mailgun struct {
+	APIKey string
+	Domain string
+	// Endpoint is the API URL, https://api.mailgun.net by default or
+	// https://api.eu.mailgun.net for the EU region
+	Endpoint string
+
+	client *http.Client
+}
+
+// NewMailgun returns a Mailgun mailer for the sending domain, the region is
+// empty or "eu"
+func NewMailgun(apiKey, domain, region string) (Mailgun, error) {
+	if len(apiKey) == 0 || len(domain) == 0 {
+		return Mailgun{}, fmt.Errorf("the Mailgun API key and domain are required")
+	}
+
+	endpoint := "https://api.mailgun.net"
+	if strings.EqualFold(region, "eu") {
+		endpoint = "https://api.eu.mailgun.net"
+	}
+
+	return Mailgun{
+		APIKey:   apiKey,
+		Domain:   domain,
+		Endpoint: endpoint,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (mg Mailgun) Send(data SendMailData) error {
+	_, err := mg.SendMessage(data)
+	return err
+}
+
+// SendMessage sends the email and returns the id of the queued message
+func (mg Mailgun) SendMessage(data SendMailData) (string, error) {
+	if len(data.To) == 0 || !strings.Contains(data.To, "@") {
+		return "", fmt.Errorf("empty To email")
+	}
+
+	if len(data.TextBody) == 0 && len(data.HTMLBody) > 0 {
+		data.TextBody = StripHTML(data.HTMLBody)
+	}
+
+	from := mail.Address{Name: data.FromName, Address: data.From}
+	to := mail.Address{Name: data.ToName, Address: data.To}
+
+	form := url.Values{}
+	form.Set("from", from.String())
+	form.Set("to", to.String())
+	form.Set("subject", data.Subject)
+	if len(data.TextBody) > 0 {
+		form.Set("text", data.TextBody)
+	}
+	if len(data.HTMLBody) > 0 {
+		form.Set("html", data.HTMLBody)
+	}
+	if len(data.ReplyTo) > 0 {
+		form.Set("h:Reply-To", data.ReplyTo)
+	}
+
+	body, contentType := io.Reader(strings.NewReader(form.Encode())), "application/x-www-form-urlencoded"
+	if len(data.Attachments) > 0 {
+		b, ct, err := mailgunMultipart(form, data.Attachments)
+		if err != nil {
+			return "", err
+		}
+		body, contentType = b, ct
+	}
+
+	u := fmt.Sprintf("%s/v3/%s/messages", mg.Endpoint, url.PathEscape(mg.Domain))
+	req, err := http.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth("api", mg.APIKey)
+	req.Header.Set("Content-Type", contentType)
+
+	_, b, err := postAPI(mg.client, MailProviderMailgun, req, mailgunError)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+	// the email is sent even if the response has no ID
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return "", nil
+	}
+	return resp.ID, nil
+}
+
+// mailgunMultipart returns the multipart form of an email with attachments
+// and its content type
+func mailgunMultipart(form url.Values, attachments []model.EmailAttachment) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	for k, values := range form {
+		for _, v := range values {
+			if err := mw.WriteField(k, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	for _, a := range attachments {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name":     "attachment",
+			"filename": a.Filename,
+		}))
+		h.Set("Content-Type", attachmentType(a))
+
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := pw.Write(a.Content); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, mw.FormDataContentType(), nil
+}
+
+// mailgunError returns the message of a Mailgun error response, the
+// unauthorized responses are plain text
+func mailgunError(code int, body []byte) (string, error) {
+	var resp struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return strings.TrimSpace(string(body)), errorFromStatus(code)
+	}
+	return resp.Message, errorFromStatus(code)
+}
@@ -0,0 +1,140 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Postmark error codes which are not rejections of the email
+const (
+	postmarkInvalidToken      = 10
+	postmarkNotAllowedToSend  = 405
+	postmarkRateLimitExceeded = 429
+)
+
+// Postmark sends emails with the Postmark email API using a server token
+type Postmark struct {
+	ServerToken string
+	// Endpoint is the API URL, https://api.postmarkapp.com by default
+	Endpoint string
+
+	client *http.Client
+}
+
+// NewPostmark returns a Postmark mailer using the server token
+func NewPostmark(serverToken string) (Postmark, error) {
+	if len(serverToken) == 0 {
+		return Postmark{}, fmt.Errorf("the Postmark server token is required")
+	}
+
+	return Postmark{
+		ServerToken: serverToken,
+		Endpoint:    "https://api.postmarkapp.com",
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type postmarkAttachment struct {
+	Name        string `json:"Name"`
+	Content     []byte `json:"Content"`
+	ContentType string `json:"ContentType"`
+}
+
+type postmarkMessage struct {
+	From        string               `json:"From"`
+	To          string               `json:"To"`
+	ReplyTo     string               `json:"ReplyTo,omitempty"`
+	Subject     string               `json:"Subject"`
+	HTMLBody    string               `json:"HtmlBody,omitempty"`
+	TextBody    string               `json:"TextBody,omitempty"`
+	Attachments []postmarkAttachment `json:"Attachments,omitempty"`
+}
+
+func (pm Postmark) Send(data SendMailData) error {
+	_, err := pm.SendMessage(data)
+	return err
+}
+
+// SendMessage sends the email and returns its Postmark MessageID
+func (pm Postmark) SendMessage(data SendMailData) (string, error) {
+	if len(data.To) == 0 || !strings.Contains(data.To, "@") {
+		return "", fmt.Errorf("empty To email")
+	}
+
+	if len(data.TextBody) == 0 && len(data.HTMLBody) > 0 {
+		data.TextBody = StripHTML(data.HTMLBody)
+	}
+
+	from := mail.Address{Name: data.FromName, Address: data.From}
+	to := mail.Address{Name: data.ToName, Address: data.To}
+
+	msg := postmarkMessage{
+		From:     from.String(),
+		To:       to.String(),
+		ReplyTo:  data.ReplyTo,
+		Subject:  data.Subject,
+		HTMLBody: data.HTMLBody,
+		TextBody: data.TextBody,
+	}
+
+	// the []byte content is base64 encoded by the JSON encoding
+	for _, a := range data.Attachments {
+		msg.Attachments = append(msg.Attachments, postmarkAttachment{
+			Name:        a.Filename,
+			Content:     a.Content,
+			ContentType: attachmentType(a),
+		})
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, pm.Endpoint+"/email", bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Postmark-Server-Token", pm.ServerToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	_, body, err := postAPI(pm.client, MailProviderPostmark, req, postmarkError)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		MessageID string `json:"MessageID"`
+	}
+	// the email is sent even if the response has no ID
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", nil
+	}
+	return resp.MessageID, nil
+}
+
+// postmarkError maps the error code of a Postmark error response, the
+// request errors are all returned with the 422 status
+func postmarkError(code int, body []byte) (string, error) {
+	var resp struct {
+		ErrorCode int    `json:"ErrorCode"`
+		Message   string `json:"Message"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return string(body), errorFromStatus(code)
+	}
+
+	switch resp.ErrorCode {
+	case postmarkInvalidToken, postmarkNotAllowedToSend:
+		return resp.Message, ErrUnauthorized
+	case postmarkRateLimitExceeded:
+		return resp.Message, ErrRateLimited
+	}
+	return resp.Message, errorFromStatus(code)
+}
@@ -0,0 +1,199 @@
+package email
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+var testMail = SendMailData{
+	From:     "app@domain.com",
+	FromName: "My App",
+	To:       "user@domain.com",
+	Subject:  "Welcome",
+	HTMLBody: "<p>Welcome aboard</p>",
+	ReplyTo:  "support@domain.com",
+}
+
+func TestSendGridSend(t *testing.T) {
+	var received sendGridMessage
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/mail/send" || r.Header.Get("Authorization") != "Bearer sg-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors":[{"message":"The provided authorization grant is invalid"}]}`))
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("X-Message-Id", "sg-id")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	sg, err := NewSendGrid("sg-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sg.Endpoint = ts.URL
+
+	if id, err := sg.SendMessage(testMail); err != nil {
+		t.Fatal(err)
+	} else if id != "sg-id" {
+		t.Errorf("expected message ID sg-id got %s", id)
+	}
+
+	if received.Personalizations[0].To[0].Email != testMail.To || received.ReplyTo.Email != testMail.ReplyTo {
+		t.Errorf("unexpected message %v", received)
+	} else if len(received.Content) != 2 || received.Content[0].Type != "text/plain" {
+		t.Errorf("expected the text and HTML content got %v", received.Content)
+	}
+
+	sg.APIKey = "invalid"
+
+	var perr *ProviderError
+	if err := sg.Send(testMail); !errors.Is(err, ErrUnauthorized) || !errors.As(err, &perr) {
+		t.Errorf("expected ErrUnauthorized got %v", err)
+	} else if perr.Message != "The provided authorization grant is invalid" {
+		t.Errorf("unexpected message %s", perr.Message)
+	}
+}
+
+func TestMailgunSend(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "api" || pass != "mg-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Forbidden"))
+			return
+		}
+
+		if r.URL.Path != "/v3/mg.domain.com/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		} else if r.FormValue("to") != "<user@domain.com>" || r.FormValue("h:Reply-To") != testMail.ReplyTo {
+			t.Errorf("unexpected form %v", r.Form)
+		} else if len(r.FormValue("text")) == 0 || len(r.FormValue("html")) == 0 {
+			t.Errorf("expected the text and HTML content got %v", r.Form)
+		}
+
+		w.Write([]byte(`{"id":"<1@mg.domain.com>","message":"Queued. Thank you."}`))
+	}))
+	defer ts.Close()
+
+	mg, err := NewMailgun("mg-key", "mg.domain.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mg.Endpoint = ts.URL
+
+	if id, err := Send(mg, testMail); err != nil {
+		t.Fatal(err)
+	} else if id != "<1@mg.domain.com>" {
+		t.Errorf("unexpected message ID %s", id)
+	}
+
+	mg.APIKey = "invalid"
+	if err := mg.Send(testMail); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized got %v", err)
+	}
+
+	eu, err := NewMailgun("mg-key", "mg.domain.com", "EU")
+	if err != nil {
+		t.Fatal(err)
+	} else if eu.Endpoint != "https://api.eu.mailgun.net" {
+		t.Errorf("expected the EU endpoint got %s", eu.Endpoint)
+	}
+}
+
+func TestPostmarkSend(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg postmarkMessage
+		b, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &msg); err != nil {
+			t.Error(err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Header.Get("X-Postmark-Server-Token") != "pm-token":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"ErrorCode":10,"Message":"No Account or Server API tokens were supplied"}`))
+		case msg.To == "<inactive@domain.com>":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"ErrorCode":406,"Message":"You tried to send to recipient(s) that have been marked as inactive."}`))
+		case msg.To == "<rate@domain.com>":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"ErrorCode":429,"Message":"Rate limit exceeded"}`))
+		default:
+			w.Write([]byte(`{"ErrorCode":0,"Message":"OK","MessageID":"pm-id"}`))
+		}
+	}))
+	defer ts.Close()
+
+	pm, err := NewPostmark("pm-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm.Endpoint = ts.URL
+
+	if id, err := pm.SendMessage(testMail); err != nil {
+		t.Fatal(err)
+	} else if id != "pm-id" {
+		t.Errorf("expected message ID pm-id got %s", id)
+	}
+
+	tests := []struct {
+		to  string
+		err error
+	}{
+		{"inactive@domain.com", ErrRejected},
+		{"rate@domain.com", ErrRateLimited},
+	}
+	for _, tt := range tests {
+		data := testMail
+		data.To = tt.to
+		if err := pm.Send(data); !errors.Is(err, tt.err) {
+			t.Errorf("expected %v for %s got %v", tt.err, tt.to, err)
+		}
+	}
+
+	pm.ServerToken = "invalid"
+	if err := pm.Send(testMail); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized got %v", err)
+	}
+}
+
+func TestNewMailer(t *testing.T) {
+	valid := []model.EmailSettings{
+		{},
+		{Provider: "dev"},
+		{Provider: "ses", Region: "us-east-1"},
+		{Provider: "smtp", Host: "smtp.domain.com"},
+		{Provider: "SendGrid", APIKey: "key"},
+		{Provider: "mailgun", APIKey: "key", Domain: "mg.domain.com"},
+		{Provider: "postmark", APIKey: "token"},
+	}
+	for _, s := range valid {
+		if _, err := NewMailer(s); err != nil {
+			t.Errorf("unexpected error for %s: %v", s.Provider, err)
+		}
+	}
+
+	invalid := []model.EmailSettings{
+		{Provider: "unknown"},
+		{Provider: "smtp"},
+		{Provider: "sendgrid"},
+		{Provider: "mailgun", APIKey: "key"},
+		{Provider: "postmark"},
+	}
+	for _, s := range invalid {
+		if _, err := NewMailer(s); err == nil {
+			t.Errorf("expected an error for %v", s)
+		}
+	}
+}
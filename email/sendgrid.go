@@ -0,0 +1,144 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SendGrid sends emails with the SendGrid v3 Mail Send API
+type SendGrid struct {
+	APIKey string
+	// Endpoint is the API URL, https://api.sendgrid.com by default
+	Endpoint string
+
+	client *http.Client
+}
+
+// NewSendGrid returns a SendGrid mailer using the API key
+func NewSendGrid(apiKey string) (SendGrid, error) {
+	if len(apiKey) == 0 {
+		return SendGrid{}, fmt.Errorf("the SendGrid API key is required")
+	}
+
+	return SendGrid{
+		APIKey:   apiKey,
+		Endpoint: "https://api.sendgrid.com",
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+func (sg SendGrid) Send(data SendMailData) error {
+	_, err := sg.SendMessage(data)
+	return err
+}
+
+// SendMessage sends the email and returns the X-Message-Id of the response
+func (sg SendGrid) SendMessage(data SendMailData) (string, error) {
+	if len(data.To) == 0 || !strings.Contains(data.To, "@") {
+		return "", fmt.Errorf("empty To email")
+	}
+
+	if len(data.TextBody) == 0 && len(data.HTMLBody) > 0 {
+		data.TextBody = StripHTML(data.HTMLBody)
+	}
+
+	msg := sendGridMessage{
+		Personalizations: []sendGridPersonalization{
+			{To: []sendGridAddress{{Email: data.To, Name: data.ToName}}},
+		},
+		From:    sendGridAddress{Email: data.From, Name: data.FromName},
+		Subject: data.Subject,
+	}
+
+	if len(data.ReplyTo) > 0 {
+		msg.ReplyTo = &sendGridAddress{Email: data.ReplyTo}
+	}
+
+	// the plain text content must be first
+	if len(data.TextBody) > 0 {
+		msg.Content = append(msg.Content, sendGridContent{Type: "text/plain", Value: data.TextBody})
+	}
+	if len(data.HTMLBody) > 0 {
+		msg.Content = append(msg.Content, sendGridContent{Type: "text/html", Value: data.HTMLBody})
+	}
+
+	for _, a := range data.Attachments {
+		msg.Attachments = append(msg.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(a.Content),
+			Type:        attachmentType(a),
+			Filename:    a.Filename,
+			Disposition: "attachment",
+		})
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sg.Endpoint+"/v3/mail/send", bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+sg.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	header, _, err := postAPI(sg.client, MailProviderSendGrid, req, sendGridError)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("X-Message-Id"), nil
+}
+
+// sendGridError returns the messages of a SendGrid error response
+func sendGridError(code int, body []byte) (string, error) {
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return string(body), errorFromStatus(code)
+	}
+
+	var msgs []string
+	for _, e := range resp.Errors {
+		msgs = append(msgs, e.Message)
+	}
+	return strings.Join(msgs, "; "), errorFromStatus(code)
+}
@@ -7,15 +7,29 @@ import (
 	"github.com/staticbackendhq/core/config"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ses"
 )
 
-type AWSSES struct{}
+// AWSSES sends emails with Amazon SES. The zero value uses the configured
+// AWS region and the default AWS credentials.
+type AWSSES struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+func (s AWSSES) Send(data SendMailData) error {
+	_, err := s.SendMessage(data)
+	return err
+}
 
-func (AWSSES) Send(data SendMailData) error {
+// SendMessage sends the email and returns its SES MessageId
+func (s AWSSES) SendMessage(data SendMailData) (string, error) {
 	if len(data.To) == 0 || !strings.Contains(data.To, "@") {
-		return fmt.Errorf("empty To email")
+		return "", fmt.Errorf("empty To email")
 	}
 
 	if len(data.ReplyTo) == 0 {
@@ -24,16 +38,42 @@ func (AWSSES) Send(data SendMailData) error {
 
 	charset := "UTF-8"
 
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(config.Current.AWSRegion)},
-	)
+	region := s.Region
+	if len(region) == 0 {
+		region = config.Current.AWSRegion
+	}
+
+	cfg := &aws.Config{Region: aws.String(region)}
+	if len(s.AccessKeyID) > 0 {
+		cfg.Credentials = credentials.NewStaticCredentials(s.AccessKeyID, s.SecretAccessKey, "")
+	}
+
+	sess, err := session.NewSession(cfg)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Create an SES session.
 	svc := ses.New(sess)
 
+	// SendEmail has no attachments, the MIME message is sent instead
+	if len(data.Attachments) > 0 {
+		msg, err := buildMessage(data, messageID(data.From))
+		if err != nil {
+			return "", err
+		}
+
+		input := &ses.SendRawEmailInput{
+			Destinations: aws.StringSlice([]string{data.To}),
+			RawMessage:   &ses.RawMessage{Data: msg},
+		}
+		out, err := svc.SendRawEmail(input)
+		if err != nil {
+			return "", sesError(err)
+		}
+		return aws.StringValue(out.MessageId), nil
+	}
+
 	from := fmt.Sprintf("%s <%s>", data.FromName, data.From)
 
 	// Assemble the email.
@@ -67,9 +107,43 @@ func (AWSSES) Send(data SendMailData) error {
 	}
 
 	// Attempt to send the email.
-	if _, err := svc.SendEmail(input); err != nil {
-		return err
+	out, err := svc.SendEmail(input)
+	if err != nil {
+		return "", sesError(err)
 	}
 
-	return nil
+	return aws.StringValue(out.MessageId), nil
+}
+
+// sesError maps the error codes of SES
+func sesError(err error) error {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return &ProviderError{Provider: MailProviderSES, Message: err.Error(), Err: ErrUnavailable}
+	}
+
+	perr := &ProviderError{Provider: MailProviderSES, Message: aerr.Message()}
+	if rerr, ok := err.(awserr.RequestFailure); ok {
+		perr.StatusCode = rerr.StatusCode()
+	}
+
+	switch aerr.Code() {
+	case "InvalidClientTokenId", "SignatureDoesNotMatch", "AccessDenied",
+		"AccessDeniedException", "UnrecognizedClientException",
+		ses.ErrCodeAccountSendingPausedException:
+		perr.Err = ErrUnauthorized
+	case "Throttling", "ThrottlingException":
+		perr.Err = ErrRateLimited
+	case ses.ErrCodeMessageRejected, ses.ErrCodeMailFromDomainNotVerifiedException,
+		ses.ErrCodeConfigurationSetDoesNotExistException,
+		ses.ErrCodeConfigurationSetSendingPausedException:
+		perr.Err = ErrRejected
+	default:
+		if perr.StatusCode > 0 {
+			perr.Err = errorFromStatus(perr.StatusCode)
+		} else {
+			perr.Err = ErrUnavailable
+		}
+	}
+	return perr
 }
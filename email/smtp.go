@@ -0,0 +1,369 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+const (
+	// smtpIdleTimeout is how long an unused connection is kept open for the
+	// next emails
+	smtpIdleTimeout = 30 * time.Second
+	// smtpDialTimeout is the maximum time to connect to the server
+	smtpDialTimeout = 10 * time.Second
+)
+
+// SMTP sends emails through an SMTP server. The connection is upgraded with
+// STARTTLS when the server supports it, port 465 uses implicit TLS. It is
+// reused by the following emails until idle for smtpIdleTimeout.
+type SMTP struct {
+	// Addr is the host:port of the server
+	Addr     string
+	Username string
+	Password string
+
+	mu     sync.Mutex
+	client *smtp.Client
+	idle   *time.Timer
+}
+
+// NewSMTP returns a mailer for the server at addr, port 587 is used when
+// addr has no port. The credentials are optional.
+func NewSMTP(addr, username, password string) (*SMTP, error) {
+	if len(addr) == 0 {
+		return nil, errors.New("the SMTP host is required")
+	}
+
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "587")
+	}
+
+	return &SMTP{Addr: addr, Username: username, Password: password}, nil
+}
+
+func (s *SMTP) Send(data SendMailData) error {
+	_, err := s.SendMessage(data)
+	return err
+}
+
+// SendMessage sends the email and returns its Message-ID header
+func (s *SMTP) SendMessage(data SendMailData) (string, error) {
+	if len(data.To) == 0 || !strings.Contains(data.To, "@") {
+		return "", fmt.Errorf("empty To email")
+	}
+
+	if len(data.ReplyTo) == 0 {
+		data.ReplyTo = data.From
+	}
+
+	id := messageID(data.From)
+	msg, err := buildMessage(data, id)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the server may have closed the reused connection
+	if s.client != nil && s.client.Reset() != nil {
+		s.close()
+	}
+
+	if s.client == nil {
+		if err := s.connect(); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.send(data.From, data.To, msg); err != nil {
+		// the state of the connection is unknown after an error
+		s.close()
+		return "", err
+	}
+
+	s.closeWhenIdle()
+	return id, nil
+}
+
+// Ping checks the server accepts an authenticated connection, the
+// connection is kept for the next emails
+func (s *SMTP) Ping() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil && s.client.Noop() != nil {
+		s.close()
+	}
+
+	if s.client == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	s.closeWhenIdle()
+	return nil
+}
+
+// connect opens an authenticated connection to the server
+func (s *SMTP) connect() error {
+	host, port, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig := &tls.Config{ServerName: host}
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+
+	var conn net.Conn
+	if port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.Addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", s.Addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			c.Close()
+			return err
+		}
+	}
+
+	if len(s.Username) > 0 {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			c.Close()
+			return err
+		}
+	}
+
+	s.client = c
+	return nil
+}
+
+func (s *SMTP) send(from, to string, msg []byte) error {
+	if err := s.client.Mail(from); err != nil {
+		return err
+	}
+
+	if err := s.client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := s.client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// closeWhenIdle closes the connection if no other email is sent within
+// smtpIdleTimeout
+func (s *SMTP) closeWhenIdle() {
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+
+	s.idle = time.AfterFunc(smtpIdleTimeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.close()
+	})
+}
+
+func (s *SMTP) close() {
+	if s.client == nil {
+		return
+	}
+
+	if err := s.client.Quit(); err != nil {
+		s.client.Close()
+	}
+	s.client = nil
+}
+
+// buildMessage returns the MIME message of an email with its text and HTML
+// alternatives, id is its Message-ID
+func buildMessage(data SendMailData, id string) ([]byte, error) {
+	if len(data.TextBody) == 0 && len(data.HTMLBody) > 0 {
+		data.TextBody = StripHTML(data.HTMLBody)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", data.TextBody},
+		{"text/html; charset=UTF-8", data.HTMLBody},
+	}
+
+	for _, p := range parts {
+		if len(p.content) == 0 {
+			continue
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Type", p.contentType)
+		h.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	contentType := `multipart/alternative; boundary="` + mw.Boundary() + `"`
+	content := body.Bytes()
+	if len(data.Attachments) > 0 {
+		boundary, mixed, err := attachParts(contentType, content, data.Attachments)
+		if err != nil {
+			return nil, err
+		}
+
+		contentType = `multipart/mixed; boundary="` + boundary + `"`
+		content = mixed
+	}
+
+	from := mail.Address{Name: data.FromName, Address: data.From}
+	to := mail.Address{Name: data.ToName, Address: data.To}
+
+	replyTo := ""
+	if len(data.ReplyTo) > 0 {
+		replyTo = (&mail.Address{Address: data.ReplyTo}).String()
+	}
+
+	var msg bytes.Buffer
+	headers := []struct {
+		name  string
+		value string
+	}{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Reply-To", replyTo},
+		{"Subject", mime.QEncoding.Encode("UTF-8", data.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", id},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType},
+	}
+
+	for _, h := range headers {
+		if len(h.value) == 0 {
+			continue
+		}
+		msg.WriteString(h.name + ": " + h.value + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.Write(content)
+
+	return msg.Bytes(), nil
+}
+
+// attachParts returns the boundary and multipart/mixed body with the
+// alternatives of the email followed by the base64 encoded attachments
+func attachParts(altType string, alt []byte, attachments []model.EmailAttachment) (string, []byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", altType)
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := pw.Write(alt); err != nil {
+		return "", nil, err
+	}
+
+	for _, a := range attachments {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Type", mime.FormatMediaType(attachmentType(a), map[string]string{"name": a.Filename}))
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		h.Set("Content-Transfer-Encoding", "base64")
+
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return "", nil, err
+		}
+
+		// base64 lines are limited to 76 characters
+		enc := base64.StdEncoding.EncodeToString(a.Content)
+		for len(enc) > 76 {
+			if _, err := io.WriteString(pw, enc[:76]+"\r\n"); err != nil {
+				return "", nil, err
+			}
+			enc = enc[76:]
+		}
+		if _, err := io.WriteString(pw, enc+"\r\n"); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return mw.Boundary(), body.Bytes(), nil
+}
+
+// messageID returns a unique Message-ID in the domain of the sender
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i > -1 {
+		domain = from[i+1:]
+	}
+
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("<%d@%s>", time.Now().UnixNano(), domain)
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
@@ -0,0 +1,195 @@
+package email
+
+import (
+	"bufio"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSMTP is a plain text SMTP server recording the connections, the
+// authentications and the messages it receives
+type fakeSMTP struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	conns    int
+	auths    []string
+	messages []string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeSMTP{ln: ln}
+	go f.serve()
+	return f
+}
+
+func (f *fakeSMTP) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		f.conns++
+		f.mu.Unlock()
+
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			f.mu.Lock()
+			f.auths = append(f.auths, arg)
+			f.mu.Unlock()
+			tp.PrintfLine("235 authenticated")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			b, err := bufio.NewReader(tp.DotReader()).ReadString(0)
+			if err != nil && len(b) == 0 {
+				return
+			}
+			f.mu.Lock()
+			f.messages = append(f.messages, b)
+			f.mu.Unlock()
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPSend(t *testing.T) {
+	f := newFakeSMTP(t)
+	defer f.ln.Close()
+
+	s, err := NewSMTP(f.ln.Addr().String(), "user", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := SendMailData{
+		From:     "app@domain.com",
+		FromName: "My App",
+		To:       "user@domain.com",
+		Subject:  "Héllo",
+		HTMLBody: "<p>Welcome aboard</p>",
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.Send(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.conns != 1 {
+		t.Errorf("expected the connection to be reused got %d connections", f.conns)
+	} else if len(f.auths) != 1 {
+		t.Errorf("expected one authentication got %v", f.auths)
+	} else if len(f.messages) != 2 {
+		t.Fatalf("expected 2 messages got %d", len(f.messages))
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(f.messages[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	} else if subject != data.Subject {
+		t.Errorf("expected subject %s got %s", data.Subject, subject)
+	}
+
+	if from := msg.Header.Get("From"); from != `"My App" <app@domain.com>` {
+		t.Errorf("unexpected From %s", from)
+	} else if !strings.HasPrefix(msg.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("unexpected Content-Type %s", msg.Header.Get("Content-Type"))
+	}
+}
+
+func TestSMTPPing(t *testing.T) {
+	f := newFakeSMTP(t)
+
+	s, err := NewSMTP(f.ln.Addr().String(), "user", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.Ping(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := SendMailData{From: "app@domain.com", To: "user@domain.com", Subject: "Ping", TextBody: "ok"}
+	if err := s.Send(data); err != nil {
+		t.Fatal(err)
+	}
+
+	f.mu.Lock()
+	if f.conns != 1 {
+		t.Errorf("expected the pinged connection to be reused got %d connections", f.conns)
+	}
+	f.mu.Unlock()
+
+	f.ln.Close()
+
+	down, err := NewSMTP(f.ln.Addr().String(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := down.Ping(); err == nil {
+		t.Error("expected an error when the server is down")
+	}
+}
+
+func TestSMTPSendInvalidTo(t *testing.T) {
+	s, err := NewSMTP("localhost", "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if s.Addr != "localhost:587" {
+		t.Errorf("expected the default port got %s", s.Addr)
+	}
+
+	if err := s.Send(SendMailData{From: "app@domain.com", To: "invalid"}); err == nil {
+		t.Error("expected an error for an invalid To")
+	}
+}
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// ErrMissingVariable is returned when rendering a template without one of
+// its variables
+var ErrMissingVariable = errors.New("missing template variable")
+
+var (
+	// the translations of a template are suffixed by their locale
+	templateName = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-z]{2,3}(-[a-z0-9]{2,8})?)?$`)
+	variableName = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+// ValidateTemplate ensures a template has a name, a subject and a body
+func ValidateTemplate(tmpl model.EmailTemplate) error {
+	if !templateName.MatchString(tmpl.Name) {
+		return fmt.Errorf("invalid template name %q, use letters, digits, - and _ optionally followed by a locale like .fr", tmpl.Name)
+	} else if len(tmpl.Subject) == 0 {
+		return errors.New("the template subject is required")
+	} else if len(tmpl.HTMLBody) == 0 && len(tmpl.TextBody) == 0 {
+		return errors.New("the template needs an HTML or a text body")
+	}
+
+	for _, v := range tmpl.Variables {
+		if !variableName.MatchString(v) {
+			return fmt.Errorf("invalid template variable %q", v)
+		}
+	}
+	return nil
+}
+
+// Render replaces the [variable] placeholders of a template, the values are
+// escaped in the HTML body. The text body is generated from the HTML one
+// when the template has none.
+func Render(tmpl model.EmailTemplate, vars map[string]any) (SendMailData, error) {
+	for _, v := range tmpl.Variables {
+		if _, ok := vars[v]; !ok {
+			return SendMailData{}, fmt.Errorf("%w: %s", ErrMissingVariable, v)
+		}
+	}
+
+	var plain, escaped []string
+	for k, v := range vars {
+		val := fmt.Sprint(v)
+		plain = append(plain, "["+k+"]", val)
+		escaped = append(escaped, "["+k+"]", template.HTMLEscapeString(val))
+	}
+
+	text := strings.NewReplacer(plain...)
+	data := SendMailData{
+		Template: tmpl.Name,
+		Subject:  text.Replace(tmpl.Subject),
+		HTMLBody: strings.NewReplacer(escaped...).Replace(tmpl.HTMLBody),
+		TextBody: text.Replace(tmpl.TextBody),
+	}
+
+	if len(data.TextBody) == 0 {
+		data.TextBody = StripHTML(data.HTMLBody)
+	} else if len(data.HTMLBody) == 0 {
+		data.HTMLBody = data.TextBody
+	}
+	return data, nil
+}
@@ -0,0 +1,57 @@
+package email
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestRender(t *testing.T) {
+	tmpl := model.EmailTemplate{
+		Name:      "welcome",
+		Subject:   "Welcome [name]",
+		HTMLBody:  `<p>Hi [name], <a href="[link]">sign in</a></p>`,
+		Variables: []string{"name", "link"},
+	}
+
+	data, err := Render(tmpl, map[string]any{"name": "Tom & Jerry", "link": "https://app.com/?a=1&b=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Subject != "Welcome Tom & Jerry" {
+		t.Errorf("unexpected subject %s", data.Subject)
+	} else if data.HTMLBody != `<p>Hi Tom &amp; Jerry, <a href="https://app.com/?a=1&amp;b=2">sign in</a></p>` {
+		t.Errorf("expected the variables to be escaped in the HTML body got %s", data.HTMLBody)
+	} else if len(data.TextBody) == 0 {
+		t.Error("expected the text body to be generated")
+	}
+
+	if _, err := Render(tmpl, map[string]any{"name": "Tom"}); !errors.Is(err, ErrMissingVariable) {
+		t.Errorf("expected ErrMissingVariable got %v", err)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	if err := ValidateTemplate(model.EmailTemplate{Name: "magic-link", Subject: "Sign in", TextBody: "[link]"}); err != nil {
+		t.Fatal(err)
+	} else if err := ValidateTemplate(model.EmailTemplate{Name: "magic-link.fr-ca", Subject: "Connexion", TextBody: "[link]"}); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []model.EmailTemplate{
+		{Name: "", Subject: "Sign in", TextBody: "[link]"},
+		{Name: "with space", Subject: "Sign in", TextBody: "[link]"},
+		{Name: "magic-link.", Subject: "Sign in", TextBody: "[link]"},
+		{Name: "magic-link.french", Subject: "Sign in", TextBody: "[link]"},
+		{Name: "magic-link", TextBody: "[link]"},
+		{Name: "magic-link", Subject: "Sign in"},
+		{Name: "magic-link", Subject: "Sign in", TextBody: "[link]", Variables: []string{"[link]"}},
+	}
+	for _, tmpl := range invalid {
+		if err := ValidateTemplate(tmpl); err == nil {
+			t.Errorf("expected %v to be invalid", tmpl)
+		}
+	}
+}
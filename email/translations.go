@@ -0,0 +1,173 @@
+package email
+
+import "github.com/staticbackendhq/core/model"
+
+// systemTemplates are the built-in translations of the system email
+// templates by name and locale
+var systemTemplates = map[string]map[string]model.EmailTemplate{
+	model.EmailTemplatePasswordReset: {
+		"en": resetTemplate(
+			"Your password reset code",
+			"Use this code to reset the password of [email]:",
+			"If you did not ask to reset your password you can ignore this email.",
+		),
+		"fr": resetTemplate(
+			"Votre code de réinitialisation du mot de passe",
+			"Utilisez ce code pour réinitialiser le mot de passe de [email] :",
+			"Si vous n'avez pas demandé à réinitialiser votre mot de passe, vous pouvez ignorer ce courriel.",
+		),
+		"es": resetTemplate(
+			"Tu código para restablecer la contraseña",
+			"Usa este código para restablecer la contraseña de [email]:",
+			"Si no solicitaste restablecer tu contraseña, puedes ignorar este correo.",
+		),
+		"de": resetTemplate(
+			"Ihr Code zum Zurücksetzen des Passworts",
+			"Verwenden Sie diesen Code, um das Passwort von [email] zurückzusetzen:",
+			"Wenn Sie das Zurücksetzen Ihres Passworts nicht angefordert haben, können Sie diese E-Mail ignorieren.",
+		),
+		"pt": resetTemplate(
+			"Seu código de redefinição de senha",
+			"Use este código para redefinir a senha de [email]:",
+			"Se você não pediu para redefinir sua senha, pode ignorar este e-mail.",
+		),
+	},
+	model.EmailTemplateMagicLink: {
+		"en": linkTemplate(
+			model.EmailTemplateMagicLink,
+			"Your sign in link",
+			"Click the link below to sign in as [email].",
+			"Sign in",
+			"If you did not ask to sign in you can ignore this email.",
+		),
+		"fr": linkTemplate(
+			model.EmailTemplateMagicLink,
+			"Votre lien de connexion",
+			"Cliquez sur le lien ci-dessous pour vous connecter en tant que [email].",
+			"Se connecter",
+			"Si vous n'avez pas demandé à vous connecter, vous pouvez ignorer ce courriel.",
+		),
+		"es": linkTemplate(
+			model.EmailTemplateMagicLink,
+			"Tu enlace para iniciar sesión",
+			"Haz clic en el enlace de abajo para iniciar sesión como [email].",
+			"Iniciar sesión",
+			"Si no solicitaste iniciar sesión, puedes ignorar este correo.",
+		),
+		"de": linkTemplate(
+			model.EmailTemplateMagicLink,
+			"Ihr Anmeldelink",
+			"Klicken Sie auf den folgenden Link, um sich als [email] anzumelden.",
+			"Anmelden",
+			"Wenn Sie keine Anmeldung angefordert haben, können Sie diese E-Mail ignorieren.",
+		),
+		"pt": linkTemplate(
+			model.EmailTemplateMagicLink,
+			"Seu link de acesso",
+			"Clique no link abaixo para entrar como [email].",
+			"Entrar",
+			"Se você não pediu para entrar, pode ignorar este e-mail.",
+		),
+	},
+	model.EmailTemplateInvite: {
+		"en": inviteTemplate(
+			"You have been invited",
+			"You have been invited to join an account with [email].",
+			"Accept the invitation",
+			"The invitation expires in 7 days.",
+		),
+		"fr": inviteTemplate(
+			"Vous avez reçu une invitation",
+			"Vous avez été invité à rejoindre un compte avec [email].",
+			"Accepter l'invitation",
+			"L'invitation expire dans 7 jours.",
+		),
+		"es": inviteTemplate(
+			"Has recibido una invitación",
+			"Te han invitado a unirte a una cuenta con [email].",
+			"Aceptar la invitación",
+			"La invitación caduca en 7 días.",
+		),
+		"de": inviteTemplate(
+			"Sie wurden eingeladen",
+			"Sie wurden eingeladen, einem Konto mit [email] beizutreten.",
+			"Einladung annehmen",
+			"Die Einladung läuft in 7 Tagen ab.",
+		),
+		"pt": inviteTemplate(
+			"Você recebeu um convite",
+			"Você foi convidado a participar de uma conta com [email].",
+			"Aceitar o convite",
+			"O convite expira em 7 dias.",
+		),
+	},
+	model.EmailTemplateVerification: {
+		"en": linkTemplate(
+			model.EmailTemplateVerification,
+			"Verify your email address",
+			"Click the link below to verify that [email] is your email address.",
+			"Verify my email",
+			"If you did not create an account you can ignore this email.",
+		),
+		"fr": linkTemplate(
+			model.EmailTemplateVerification,
+			"Vérifiez votre adresse courriel",
+			"Cliquez sur le lien ci-dessous pour confirmer que [email] est votre adresse courriel.",
+			"Vérifier mon adresse",
+			"Si vous n'avez pas créé de compte, vous pouvez ignorer ce courriel.",
+		),
+		"es": linkTemplate(
+			model.EmailTemplateVerification,
+			"Verifica tu dirección de correo",
+			"Haz clic en el enlace de abajo para confirmar que [email] es tu dirección de correo.",
+			"Verificar mi correo",
+			"Si no creaste una cuenta, puedes ignorar este correo.",
+		),
+		"de": linkTemplate(
+			model.EmailTemplateVerification,
+			"Bestätigen Sie Ihre E-Mail-Adresse",
+			"Klicken Sie auf den folgenden Link, um zu bestätigen, dass [email] Ihre E-Mail-Adresse ist.",
+			"E-Mail bestätigen",
+			"Wenn Sie kein Konto erstellt haben, können Sie diese E-Mail ignorieren.",
+		),
+		"pt": linkTemplate(
+			model.EmailTemplateVerification,
+			"Confirme seu endereço de e-mail",
+			"Clique no link abaixo para confirmar que [email] é o seu endereço de e-mail.",
+			"Confirmar meu e-mail",
+			"Se você não criou uma conta, pode ignorar este e-mail.",
+		),
+	},
+}
+
+func resetTemplate(subject, intro, ignore string) model.EmailTemplate {
+	return model.EmailTemplate{
+		Name:      model.EmailTemplatePasswordReset,
+		Subject:   subject,
+		HTMLBody:  "<p>" + intro + "</p><p><strong>[code]</strong></p><p>" + ignore + "</p>",
+		TextBody:  intro + "\n\n[code]\n\n" + ignore,
+		Variables: []string{"code", "email"},
+	}
+}
+
+func linkTemplate(name, subject, intro, action, outro string) model.EmailTemplate {
+	return model.EmailTemplate{
+		Name:      name,
+		Subject:   subject,
+		HTMLBody:  "<p>" + intro + "</p><p><a href=\"[link]\">" + action + "</a></p><p>" + outro + "</p>",
+		TextBody:  intro + "\n\n" + action + ": [link]\n\n" + outro,
+		Variables: []string{"link", "email"},
+	}
+}
+
+// inviteTemplate is a link template with the inviter's note, the note is
+// HTML escaped when rendered
+func inviteTemplate(subject, intro, action, outro string) model.EmailTemplate {
+	return model.EmailTemplate{
+		Name:      model.EmailTemplateInvite,
+		Subject:   subject,
+		HTMLBody:  "<p>" + intro + "</p><p>[note]</p><p><a href=\"[link]\">" + action + "</a></p><p>" + outro + "</p>",
+		TextBody:  intro + "\n\n[note]\n\n" + action + ": [link]\n\n" + outro,
+		Variables: []string{"link", "email", "note"},
+	}
+}
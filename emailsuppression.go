@@ -0,0 +1,184 @@
+package staticbackend
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// emailWebhook records the bounces and complaints posted by the mail
+// providers to POST /email/webhook/{provider}?sbpk={public key}&token={token}
+func emailWebhook(w http.ResponseWriter, r *http.Request) {
+	const maxBodyBytes = int64(1 << 20)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !backend.ValidEmailWebhookToken(conf, r.URL.Query().Get("token")) {
+		http.Error(w, "invalid webhook token", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var events []model.EmailEvent
+	switch provider := getURLPart(r.URL.Path, 3); provider {
+	case email.MailProviderSES:
+		var subscribeURL string
+		events, subscribeURL, err = email.ParseSESNotification(body)
+		if err == nil && len(subscribeURL) > 0 {
+			err = email.ConfirmSNSSubscription(subscribeURL)
+		}
+	case email.MailProviderSendGrid:
+		events, err = email.ParseSendGridEvents(body)
+	default:
+		http.Error(w, "unsupported mail provider "+provider, http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := backend.RecordEmailEvents(conf, events); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+// emailWebhookURLs returns the paths of the webhooks to configure in the
+// mail providers from GET /email/webhook
+func emailWebhookURLs(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	v := url.Values{}
+	v.Set("sbpk", conf.ID)
+	v.Set("token", backend.EmailWebhookToken(conf))
+
+	urls := make(map[string]string)
+	for _, provider := range []string{email.MailProviderSES, email.MailProviderSendGrid} {
+		urls[provider] = "/email/webhook/" + provider + "?" + v.Encode()
+	}
+
+	respond(w, http.StatusOK, urls)
+}
+
+// emailSuppressions handles the suppression list, GET /email/suppression
+// lists the suppressed addresses and POST /email/suppression adds one
+func emailSuppressions(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := backend.DB.ListEmailSuppressions(conf.Name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, list)
+	case http.MethodPost:
+		var s model.EmailSuppression
+		if err := parseBody(r.Body, &s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if !strings.Contains(s.Email, "@") {
+			http.Error(w, "invalid email", http.StatusBadRequest)
+			return
+		}
+
+		s.Reason = model.EmailSuppressionManual
+		s.Created = time.Now()
+		if err := backend.DB.SuppressEmail(conf.Name, s); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, true)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// removeEmailSuppression allows sending to an address again from DELETE
+// /email/suppression/{email}
+func removeEmailSuppression(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	addr, err := url.PathUnescape(getURLPart(r.URL.Path, 3))
+	if err != nil || len(addr) == 0 {
+		http.Error(w, "invalid email", http.StatusBadRequest)
+		return
+	}
+
+	if err := backend.DB.RemoveEmailSuppression(conf.Name, addr); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+// listEmailEvents returns the most recent bounces and complaints from GET
+// /email/events, of an address with the email parameter
+func listEmailEvents(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	limit := int64(100)
+	if v, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64); err == nil && v > 0 {
+		limit = v
+	}
+
+	events, err := backend.DB.ListEmailEvents(conf.Name, r.URL.Query().Get("email"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, events)
+}
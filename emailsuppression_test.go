@@ -0,0 +1,80 @@
+package staticbackend
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestEmailBounceSuppression(t *testing.T) {
+	resp := dbReq(t, emailWebhookURLs, "GET", "/email/webhook", nil, true)
+	defer resp.Body.Close()
+
+	var urls map[string]string
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &urls); err != nil {
+		t.Fatal(err)
+	}
+
+	events := []map[string]string{
+		{"email": "bounced@test.com", "event": "bounce", "type": "bounce", "reason": "user unknown"},
+		{"email": "soft@test.com", "event": "bounce", "type": "blocked", "reason": "blocked"},
+	}
+
+	resp2 := dbReq(t, emailWebhook, "POST", "/email/webhook/sendgrid?token=invalid", events)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for an invalid token got %d", resp2.StatusCode)
+	}
+
+	resp3 := dbReq(t, emailWebhook, "POST", urls[email.MailProviderSendGrid], events)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp3))
+	}
+	defer backend.DB.RemoveEmailSuppression(dbName, "bounced@test.com")
+
+	resp4 := dbReq(t, emailSuppressions, "GET", "/email/suppression", nil, true)
+	defer resp4.Body.Close()
+
+	var list []model.EmailSuppression
+	if err := parseBody(resp4.Body, &list); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Email != "bounced@test.com" {
+		t.Fatalf("expected only the hard bounce to be suppressed got %v", list)
+	}
+
+	// the emails to a suppressed address are not sent
+	conf, err := backend.DB.FindDatabase(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := backend.QueueEmail(conf, email.SendMailData{From: admEmail, To: "bounced@test.com", Subject: "suppressed", TextBody: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg, err := backend.DB.GetEmailMessage(dbName, id); err != nil {
+		t.Fatal(err)
+	} else if msg.Status != model.EmailStatusFailed || msg.LastError != backend.ErrEmailSuppressed.Error() {
+		t.Errorf("expected the email to a suppressed address to fail got %v", msg)
+	}
+
+	resp5 := dbReq(t, removeEmailSuppression, "DELETE", "/email/suppression/bounced@test.com", nil, true)
+	defer resp5.Body.Close()
+
+	if resp5.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp5))
+	} else if ok, err := backend.DB.IsEmailSuppressed(dbName, "bounced@test.com"); err != nil || ok {
+		t.Errorf("expected the suppression to be removed got %v %v", ok, err)
+	}
+}
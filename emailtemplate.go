@@ -0,0 +1,123 @@
+package staticbackend
+
+import (
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// emailTemplates handles the email templates of a database, GET
+// /email/template lists them and POST /email/template adds or replaces one
+// by its name
+func emailTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		listEmailTemplates(w, r)
+	case http.MethodPost:
+		saveEmailTemplate(w, r, "")
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// emailTemplateActions handles GET, PUT and DELETE /email/template/{name}
+func emailTemplateActions(w http.ResponseWriter, r *http.Request) {
+	name := getURLPart(r.URL.Path, 3)
+	if len(name) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getEmailTemplate(w, r, name)
+	case http.MethodPut:
+		saveEmailTemplate(w, r, name)
+	case http.MethodDelete:
+		deleteEmailTemplate(w, r, name)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func listEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	list, err := backend.DB.ListEmailTemplates(conf.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, list)
+}
+
+func getEmailTemplate(w http.ResponseWriter, r *http.Request, name string) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	tmpl, err := backend.DB.GetEmailTemplate(conf.Name, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	respond(w, http.StatusOK, tmpl)
+}
+
+// saveEmailTemplate adds or replaces a template, the name of the URL takes
+// precedence over the one of the body
+func saveEmailTemplate(w http.ResponseWriter, r *http.Request, name string) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var tmpl model.EmailTemplate
+	if err := parseBody(r.Body, &tmpl); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(name) > 0 {
+		tmpl.Name = name
+	}
+
+	tmpl, err = backend.SaveEmailTemplate(conf, tmpl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respond(w, http.StatusOK, tmpl)
+}
+
+func deleteEmailTemplate(w http.ResponseWriter, r *http.Request, name string) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if _, err := backend.DB.GetEmailTemplate(conf.Name, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := backend.DB.DeleteEmailTemplate(conf.Name, name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
@@ -0,0 +1,80 @@
+package staticbackend
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestEmailTemplates(t *testing.T) {
+	tmpl := model.EmailTemplate{
+		Subject:   "Sign in to [app]",
+		HTMLBody:  `<p><a href="[link]">Sign in</a></p>`,
+		Variables: []string{"link"},
+	}
+
+	resp := dbReq(t, emailTemplateActions, "PUT", "/email/template/"+model.EmailTemplateMagicLink, tmpl, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+	defer backend.DB.DeleteEmailTemplate(dbName, model.EmailTemplateMagicLink)
+
+	resp2 := dbReq(t, emailTemplates, "GET", "/email/template", nil, true)
+	defer resp2.Body.Close()
+
+	var list []model.EmailTemplate
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp2))
+	} else if err := parseBody(resp2.Body, &list); err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, x := range list {
+		if x.Name == model.EmailTemplateMagicLink && x.Subject == tmpl.Subject && !x.Updated.IsZero() {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the magic link template in %v", list)
+	}
+
+	// the magic link email without a body uses the template
+	data := backend.MagicLinkData{
+		FromEmail: admEmail,
+		Email:     admEmail,
+		MagicLink: "https://mycustom.link/with-code",
+	}
+	resp6 := dbReq(t, mship.magicLink, "POST", "/login/magic", data)
+	defer resp6.Body.Close()
+
+	if resp6.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp6))
+	}
+
+	invalid := model.EmailTemplate{Name: "no-body", Subject: "Empty"}
+	resp3 := dbReq(t, emailTemplates, "POST", "/email/template", invalid, true)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a template without body got %d", resp3.StatusCode)
+	}
+
+	resp4 := dbReq(t, emailTemplateActions, "DELETE", "/email/template/"+model.EmailTemplateMagicLink, nil, true)
+	defer resp4.Body.Close()
+
+	if resp4.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp4))
+	}
+
+	resp5 := dbReq(t, emailTemplateActions, "GET", "/email/template/"+model.EmailTemplateMagicLink, nil, true)
+	defer resp5.Body.Close()
+
+	if resp5.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 after the delete got %d", resp5.StatusCode)
+	}
+}
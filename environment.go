@@ -0,0 +1,111 @@
+package staticbackend
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+// environments lists the environments of the database's app or creates its
+// dev or staging environment from /environments with {environment}. The
+// environments are databases with their own data and root user.
+func environments(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := backend.ListEnvironments(conf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, list)
+	case http.MethodPost:
+		var data struct {
+			Environment string `json:"environment"`
+		}
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		bc, root, pw, err := backend.CreateEnvironment(conf, data.Environment, auth.Email)
+		if err != nil {
+			http.Error(w, err.Error(), environmentStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "created the "+bc.Environment+" environment "+bc.Name)
+
+		resp := new(struct {
+			Environment   string `json:"environment"`
+			PublicKey     string `json:"pk"`
+			RootToken     string `json:"rootToken"`
+			AdminPassword string `json:"pw"`
+		})
+		resp.Environment = bc.Environment
+		resp.PublicKey = bc.ID
+		resp.RootToken = fmt.Sprintf("%s|%s|%s", root.ID, root.AccountID, root.Token)
+		resp.AdminPassword = pw
+
+		respond(w, http.StatusCreated, resp)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// promoteEnvironment copies the functions, tasks, collection schemas and
+// forms configuration of an environment to the next one from
+// /environments/promote with {from}, dev is promoted to staging and
+// staging to prod
+func promoteEnvironment(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var data struct {
+		From string `json:"from"`
+	}
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := backend.PromoteEnvironment(conf, data.From)
+	if err != nil {
+		http.Error(w, err.Error(), environmentStatus(err))
+		return
+	}
+
+	recordRootOperation(r, conf, auth, "promoted "+report.From+" to "+report.To)
+
+	respond(w, http.StatusOK, report)
+}
+
+// environmentStatus returns the status of a failed environment request
+func environmentStatus(err error) int {
+	switch {
+	case errors.Is(err, backend.ErrInvalidEnvironment):
+		return http.StatusBadRequest
+	case errors.Is(err, backend.ErrEnvironmentExists):
+		return http.StatusConflict
+	case errors.Is(err, backend.ErrEnvironmentNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,55 @@
+package staticbackend
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestEnvironments(t *testing.T) {
+	resp := dbReq(t, environments, "POST", "/environments", map[string]string{"environment": model.EnvironmentDev}, true)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var created struct {
+		Environment string `json:"environment"`
+		PublicKey   string `json:"pk"`
+		RootToken   string `json:"rootToken"`
+	}
+	if err := parseBody(resp.Body, &created); err != nil {
+		t.Fatal(err)
+	} else if created.Environment != model.EnvironmentDev || len(created.PublicKey) == 0 || len(created.RootToken) == 0 {
+		t.Fatalf("unexpected environment %v", created)
+	}
+	defer backend.DB.DeleteDatabase(created.PublicKey)
+
+	resp = dbReq(t, environments, "POST", "/environments", map[string]string{"environment": model.EnvironmentDev}, true)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status 409 creating dev twice got %s", resp.Status)
+	}
+
+	resp = dbReq(t, environments, "GET", "/environments", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var list []model.DatabaseConfig
+	if err := parseBody(resp.Body, &list); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 || list[0].ID != created.PublicKey || list[1].Env() != model.EnvironmentProd {
+		t.Fatalf("expected dev and prod got %v", list)
+	}
+
+	resp = dbReq(t, promoteEnvironment, "POST", "/environments/promote", map[string]string{"from": model.EnvironmentProd}, true)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 promoting prod got %s", resp.Status)
+	}
+
+	resp = dbReq(t, promoteEnvironment, "POST", "/environments/promote", map[string]string{"from": model.EnvironmentDev}, true)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 promoting to a missing staging got %s", resp.Status)
+	}
+}
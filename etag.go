@@ -0,0 +1,72 @@
+package staticbackend
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+// documentETag returns the ETag of a document. Documents do not have a
+// revision field, the ETag is derived from their content and changes on
+// every update.
+func documentETag(doc map[string]interface{}) (string, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf(`"%x"`, sum[:16]), nil
+}
+
+// etagMatches returns true if the If-Match or If-None-Match header value
+// lists the ETag or is the * wildcard
+func etagMatches(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPreconditions enforces the If-Match and If-None-Match headers of a
+// write request against the current document. It returns false once the
+// response has been written.
+func checkPreconditions(w http.ResponseWriter, r *http.Request, conf model.DatabaseConfig, auth model.Auth, col, id string) bool {
+	ifMatch, ifNoneMatch := r.Header.Get("If-Match"), r.Header.Get("If-None-Match")
+	if len(ifMatch) == 0 && len(ifNoneMatch) == 0 {
+		return true
+	}
+
+	doc, err := backend.DB.GetDocumentByID(auth, conf.Name, col, id)
+	if err != nil {
+		// If-Match requires a current document, If-None-Match: * passes
+		if len(ifMatch) > 0 {
+			http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+			return false
+		}
+		return true
+	}
+
+	etag, err := documentETag(doc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if len(ifMatch) > 0 && !etagMatches(ifMatch, etag) {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return false
+	} else if len(ifNoneMatch) > 0 && etagMatches(ifNoneMatch, etag) {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
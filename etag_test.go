@@ -0,0 +1,121 @@
+package staticbackend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+// etagReq is dbReq with the conditional request headers
+func etagReq(t *testing.T, hf func(http.ResponseWriter, *http.Request), method, path string, v interface{}, headers map[string]string) *http.Response {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal("error marshaling post data:", err)
+	}
+
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	if _, ok := headers["Content-Type"]; !ok {
+		headers["Content-Type"] = "application/json"
+	}
+	return rawReq(hf, method, path, b, headers)
+}
+
+// rawReq sends an authenticated request with the body as-is
+func rawReq(hf func(http.ResponseWriter, *http.Request), method, path string, body []byte, headers map[string]string) *http.Response {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	req.Header.Set("SB-PUBLIC-KEY", pubKey)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	h := middleware.Chain(http.HandlerFunc(hf),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequireAuth(backend.DB, backend.Cache),
+	)
+	h.ServeHTTP(w, req)
+
+	return w.Result()
+}
+
+func TestDocumentETag(t *testing.T) {
+	task := Task{Title: "etag", Created: time.Now()}
+
+	resp := dbReq(t, db.add, "POST", "/db/tasks", task)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var saved Task
+	if err := parseBody(resp.Body, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/db/tasks/" + saved.ID
+
+	resp = etagReq(t, db.get, "GET", path, nil, nil)
+	defer resp.Body.Close()
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if len(etag) == 0 {
+		t.Fatal("expected an ETag header")
+	}
+
+	resp = etagReq(t, db.get, "GET", path, nil, map[string]string{"If-None-Match": etag})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected status 304 got %d", resp.StatusCode)
+	}
+
+	update := map[string]interface{}{"done": true}
+
+	resp = etagReq(t, db.update, "PUT", path, update, map[string]string{"If-Match": `"stale"`})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("expected status 412 got %d", resp.StatusCode)
+	}
+
+	resp = etagReq(t, db.update, "PUT", path, update, map[string]string{"If-Match": etag})
+	defer resp.Body.Close()
+
+	updatedETag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if len(updatedETag) == 0 || updatedETag == etag {
+		t.Errorf("expected a new ETag got %s", updatedETag)
+	}
+
+	// the previous ETag is now stale
+	resp = etagReq(t, db.del, "DELETE", path, nil, map[string]string{"If-Match": etag})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("expected status 412 got %d", resp.StatusCode)
+	}
+
+	resp = etagReq(t, db.get, "GET", path, nil, map[string]string{"If-None-Match": etag})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200 got %d", resp.StatusCode)
+	} else if resp.Header.Get("ETag") != updatedETag {
+		t.Errorf("expected ETag %s got %s", updatedETag, resp.Header.Get("ETag"))
+	}
+}
@@ -0,0 +1,224 @@
+// Package eventbridge mirrors system events (document changes, function runs,
+// quarantined files and auth events) onto Kafka topics through a Kafka REST
+// Proxy.
+package eventbridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+// System event types
+const (
+	EventDocumentCreated = "document.created"
+	EventDocumentUpdated = "document.updated"
+	EventDocumentDeleted = "document.deleted"
+	EventFunctionRun     = "function.run"
+	EventFileQuarantined = "file.quarantined"
+	// auth events are prefixed, i.e. auth.login_success
+	EventAuthPrefix = "auth."
+)
+
+// Events are sent in batches of up to BatchSize records per topic, at least
+// every FlushInterval. Failed batches are retried per RetryDelays. When the
+// buffer is full new events are dropped.
+var (
+	BatchSize     = 100
+	FlushInterval = time.Second
+	BufferSize    = 5000
+	RetryDelays   = []time.Duration{time.Second, 5 * time.Second}
+)
+
+// Event is a system event of a database
+type Event struct {
+	Type    string    `json:"type"`
+	Base    string    `json:"base"`
+	Created time.Time `json:"created"`
+	Data    any       `json:"data"`
+}
+
+// route sends the events matching the pattern to a topic
+type route struct {
+	pattern string
+	topic   string
+}
+
+// record is the Kafka REST Proxy v2 JSON record, keyed by database so the
+// events of a database land on the same partition
+type record struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+// Bridge sends system events to their mapped Kafka topic
+type Bridge struct {
+	url      string
+	username string
+	password string
+	routes   []route
+	events   chan Event
+	client   *http.Client
+	log      *logger.Logger
+}
+
+// New returns a started Bridge posting to the Kafka REST Proxy at url.
+//
+// The mapping is a comma-separated list of event=topic, the event can end
+// with a * to match a prefix, i.e.:
+// "document.*=sb-documents,function.run=sb-functions,auth.*=sb-auth".
+// Events without a matching topic are not sent.
+func New(url, mapping, username, password string, log *logger.Logger) (*Bridge, error) {
+	routes, err := parseMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bridge{
+		url:      strings.TrimSuffix(url, "/"),
+		username: username,
+		password: password,
+		routes:   routes,
+		events:   make(chan Event, BufferSize),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		log:      log,
+	}
+
+	go b.run()
+
+	return b, nil
+}
+
+func parseMapping(mapping string) (routes []route, err error) {
+	for _, pair := range strings.Split(mapping, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("invalid event mapping: %s", pair)
+		}
+
+		routes = append(routes, route{pattern: parts[0], topic: parts[1]})
+	}
+
+	if len(routes) == 0 {
+		return nil, errors.New("the event mapping is empty")
+	}
+	return
+}
+
+// topic returns the topic of the first route matching the event type
+func (b *Bridge) topic(eventType string) (string, bool) {
+	for _, r := range b.routes {
+		if model.MatchChannel(r.pattern, eventType) {
+			return r.topic, true
+		}
+	}
+	return "", false
+}
+
+// Publish queues an event, it's safe to call on a nil Bridge
+func (b *Bridge) Publish(evt Event) {
+	if b == nil {
+		return
+	}
+
+	if evt.Created.IsZero() {
+		evt.Created = time.Now()
+	}
+
+	select {
+	case b.events <- evt:
+	default:
+		b.log.Warn().Msgf("event bridge buffer full, dropping %s event", evt.Type)
+	}
+}
+
+func (b *Bridge) run() {
+	pending := make(map[string][]record)
+
+	ticker := time.NewTicker(FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case evt := <-b.events:
+			topic, ok := b.topic(evt.Type)
+			if !ok {
+				continue
+			}
+
+			pending[topic] = append(pending[topic], record{Key: evt.Base, Value: evt})
+			if len(pending[topic]) >= BatchSize {
+				b.send(topic, pending[topic])
+				delete(pending, topic)
+			}
+		case <-ticker.C:
+			for topic, records := range pending {
+				b.send(topic, records)
+			}
+			pending = make(map[string][]record)
+		}
+	}
+}
+
+func (b *Bridge) send(topic string, records []record) {
+	body, err := json.Marshal(map[string][]record{"records": records})
+	if err != nil {
+		b.log.Error().Err(err).Msgf("unable to encode events for topic %s", topic)
+		return
+	}
+
+	for i := 0; i <= len(RetryDelays); i++ {
+		if i > 0 {
+			time.Sleep(RetryDelays[i-1])
+		}
+
+		if err = b.post(topic, body); err == nil {
+			return
+		}
+	}
+
+	b.log.Error().Err(err).Msgf("unable to send %d events to topic %s", len(records), topic)
+}
+
+func (b *Bridge) post(topic string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, b.url+"/topics/"+topic, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	if len(b.username) > 0 {
+		req.SetBasicAuth(b.username, b.password)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("error returned by the Kafka REST Proxy: %s", string(b))
+	}
+	return nil
+}
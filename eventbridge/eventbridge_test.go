@@ -0,0 +1,94 @@
+package eventbridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/logger"
+)
+
+func TestParseMapping(t *testing.T) {
+	routes, err := parseMapping("document.*=sb-documents, function.run=sb-functions")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(routes) != 2 {
+		t.Fatalf("expected 2 routes got %d", len(routes))
+	}
+
+	if _, err := parseMapping("document.*"); err == nil {
+		t.Error("expected an error for a mapping without topic")
+	}
+
+	if _, err := parseMapping(""); err == nil {
+		t.Error("expected an error for an empty mapping")
+	}
+}
+
+func TestPublishBatchesPerTopic(t *testing.T) {
+	type post struct {
+		path        string
+		contentType string
+		username    string
+		records     []record
+	}
+
+	posts := make(chan post, 10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p post
+		p.path = r.URL.Path
+		p.contentType = r.Header.Get("Content-Type")
+		p.username, _, _ = r.BasicAuth()
+
+		var body struct {
+			Records []record `json:"records"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+
+		p.records = body.Records
+		posts <- p
+	}))
+	defer ts.Close()
+
+	FlushInterval = 50 * time.Millisecond
+
+	log := logger.Get(config.AppConfig{AppEnv: "dev"})
+	b, err := New(ts.URL, "document.*=sb-documents,auth.*=sb-auth", "user", "pass", log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.Publish(Event{Type: EventDocumentCreated, Base: "db1", Data: "a"})
+	b.Publish(Event{Type: EventDocumentDeleted, Base: "db1", Data: "b"})
+	// no topic mapped for function runs
+	b.Publish(Event{Type: EventFunctionRun, Base: "db1"})
+
+	select {
+	case p := <-posts:
+		if p.path != "/topics/sb-documents" {
+			t.Errorf("expected path /topics/sb-documents got %s", p.path)
+		} else if p.contentType != "application/vnd.kafka.json.v2+json" {
+			t.Errorf("unexpected content type %s", p.contentType)
+		} else if p.username != "user" {
+			t.Errorf("expected basic auth user got %s", p.username)
+		} else if len(p.records) != 2 {
+			t.Fatalf("expected 2 records got %d", len(p.records))
+		} else if p.records[0].Key != "db1" || p.records[1].Value.Type != EventDocumentDeleted {
+			t.Errorf("unexpected records %v", p.records)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the events")
+	}
+
+	select {
+	case p := <-posts:
+		t.Errorf("unexpected post to %s", p.path)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
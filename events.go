@@ -0,0 +1,117 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// ingestEvents accepts a batch of analytics events from the app's clients.
+// They're written in the background, the response only tells how many were
+// accepted.
+func ingestEvents(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var events []model.AppEvent
+	if err := decodeBody(r, &events); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n, err := backend.IngestEvents(conf, events)
+	if errors.Is(err, backend.ErrEventsDropped) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respond(w, http.StatusAccepted, map[string]int{"accepted": n})
+}
+
+// listEvents returns the most recent app events, filtered by the name,
+// session, since and until query string parameters
+func listEvents(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	filter := model.AppEventFilter{
+		Name:      qs.Get("name"),
+		SessionID: qs.Get("session"),
+		Limit:     100,
+	}
+
+	if s := qs.Get("limit"); len(s) > 0 {
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || limit < 1 || limit > 1000 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if s := qs.Get("since"); len(s) > 0 {
+		filter.Since, err = parseDate(s)
+		if err != nil {
+			http.Error(w, "invalid since date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if s := qs.Get("until"); len(s) > 0 {
+		filter.Until, err = parseDate(s)
+		if err != nil {
+			http.Error(w, "invalid until date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := backend.DB.ListAppEvents(conf.Name, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, events)
+}
+
+// eventSeries returns the daily number of app events per name for the
+// dashboard charts, the days query string parameter sets the length of the
+// series and name the only event returned
+func eventSeries(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	days, ok := statsPeriods(qs.Get("days"), backend.StatsDays, backend.MaxStatsDays)
+	if !ok {
+		http.Error(w, "invalid days", http.StatusBadRequest)
+		return
+	}
+
+	series, err := backend.EventSeries(conf, qs.Get("name"), days)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, series)
+}
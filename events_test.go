@@ -0,0 +1,67 @@
+package staticbackend
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/ingest"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestIngestEvents(t *testing.T) {
+	events := []model.AppEvent{
+		{Name: "ingest_signup", SessionID: "s1", Properties: map[string]any{"plan": "free"}},
+		{Name: "ingest_signup", SessionID: "s2", Properties: map[string]any{"plan": "pro"}},
+		{Name: "ingest_checkout", SessionID: "s2"},
+	}
+
+	resp := dbReq(t, ingestEvents, "POST", "/events", events)
+	defer resp.Body.Close()
+
+	var accepted map[string]int
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &accepted); err != nil {
+		t.Fatal(err)
+	} else if accepted["accepted"] != 3 {
+		t.Errorf("expected 3 events accepted got %v", accepted)
+	}
+
+	if err := ingest.Default.Flush(backend.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	resp = dbReq(t, listEvents, "GET", "/sudo/_/events?name=ingest_signup", nil, true)
+	defer resp.Body.Close()
+
+	var list []model.AppEvent
+	if err := parseBody(resp.Body, &list); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Errorf("expected 2 signup events got %v", list)
+	} else if list[0].Received.IsZero() || list[0].Timestamp.IsZero() {
+		t.Errorf("expected the event times to be set got %v", list[0])
+	}
+
+	resp = dbReq(t, eventSeries, "GET", "/sudo/_/events/series?days=7", nil, true)
+	defer resp.Body.Close()
+
+	var series map[string][]model.UsagePoint
+	if err := parseBody(resp.Body, &series); err != nil {
+		t.Fatal(err)
+	} else if s := series["ingest_signup"]; len(s) != 7 || s[6].Value != 2 {
+		t.Errorf("expected 7 days of signups with 2 today got %v", s)
+	} else if s := series["ingest_checkout"]; len(s) != 7 || s[6].Value != 1 {
+		t.Errorf("expected 7 days of checkouts with 1 today got %v", s)
+	}
+
+	for _, batch := range [][]model.AppEvent{nil, {{Name: "invalid name"}}} {
+		resp := dbReq(t, ingestEvents, "POST", "/events", batch)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %v got %d", batch, resp.StatusCode)
+		}
+	}
+}
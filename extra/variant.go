@@ -0,0 +1,112 @@
+package extra
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Image formats supported for variants
+const (
+	ImageFormatJPEG = "jpeg"
+	ImageFormatPNG  = "png"
+	ImageFormatWebP = "webp"
+)
+
+// ImageFormat returns the image format of a file name or an empty string if
+// it's not a supported image
+func ImageFormat(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return ImageFormatJPEG
+	case ".png":
+		return ImageFormatPNG
+	case ".gif":
+		// GIF variants are saved as PNG, animations are not kept
+		return ImageFormatPNG
+	case ".webp":
+		return ImageFormatWebP
+	}
+	return ""
+}
+
+// DecodeImage decodes a JPEG, PNG, GIF or WebP image
+func DecodeImage(name string, file io.Reader) (image.Image, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return jpeg.Decode(file)
+	case ".png":
+		return png.Decode(file)
+	case ".gif":
+		return gif.Decode(file)
+	case ".webp":
+		return webp.Decode(file)
+	}
+	return nil, fmt.Errorf("invalid image format: %s", path.Ext(name))
+}
+
+// CreateVariant writes the variant of the src image to output and returns
+// the file extension of its format. The image is never enlarged.
+func CreateVariant(src image.Image, srcFormat string, v model.ImageVariant, output io.Writer) (string, error) {
+	format := strings.ToLower(v.Format)
+	if format == "jpg" {
+		format = ImageFormatJPEG
+	} else if len(format) == 0 {
+		format = srcFormat
+	}
+
+	img := src
+	if w, h := fit(src.Bounds(), v.Width, v.Height); w != src.Bounds().Dx() || h != src.Bounds().Dy() {
+		dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+		draw.CatmullRom.Scale(dst, dst.Rect, src, src.Bounds(), draw.Src, nil)
+		img = dst
+	}
+
+	switch format {
+	case ImageFormatJPEG:
+		return ".jpg", jpeg.Encode(output, img, &jpeg.Options{Quality: 85})
+	case ImageFormatPNG:
+		return ".png", png.Encode(output, img)
+	case ImageFormatWebP:
+		return ".webp", EncodeWebP(output, img)
+	}
+	return "", fmt.Errorf("invalid variant format: %s", v.Format)
+}
+
+// fit returns the dimensions of the bounds scaled down to fit within width
+// and height keeping the aspect ratio, a zero value is unconstrained
+func fit(bounds image.Rectangle, width, height int) (int, int) {
+	w, h := bounds.Dx(), bounds.Dy()
+
+	ratio := 1.0
+	if width > 0 && w > width {
+		ratio = float64(width) / float64(w)
+	}
+	if height > 0 && h > height {
+		if r := float64(height) / float64(h); r < ratio {
+			ratio = r
+		}
+	}
+
+	if ratio == 1.0 {
+		return w, h
+	}
+
+	nw, nh := int(float64(w)*ratio+0.5), int(float64(h)*ratio+0.5)
+	if nw < 1 {
+		nw = 1
+	}
+	if nh < 1 {
+		nh = 1
+	}
+	return nw, nh
+}
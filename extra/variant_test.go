@@ -0,0 +1,48 @@
+package extra
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+	"golang.org/x/image/webp"
+)
+
+func TestCreateVariant(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+
+	buf := new(bytes.Buffer)
+	v := model.ImageVariant{Name: "thumb", Width: 100, Height: 100, Format: "webp"}
+	ext, err := CreateVariant(src, ImageFormatPNG, v, buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if ext != ".webp" {
+		t.Errorf("expected .webp got %s", ext)
+	}
+
+	img, err := webp.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if img.Bounds().Dx() != 100 || img.Bounds().Dy() != 50 {
+		t.Errorf("expected 100x50 got %v", img.Bounds())
+	}
+
+	// images are not enlarged and keep their format
+	buf.Reset()
+	v = model.ImageVariant{Name: "large", Width: 1000}
+	ext, err = CreateVariant(src, ImageFormatJPEG, v, buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if ext != ".jpg" {
+		t.Errorf("expected .jpg got %s", ext)
+	}
+
+	img, err = jpeg.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if img.Bounds().Dx() != 400 {
+		t.Errorf("expected 400 wide got %d", img.Bounds().Dx())
+	}
+}
@@ -0,0 +1,399 @@
+package extra
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+// order in which the code length code lengths are written (VP8L spec)
+var codeLengthCodeOrder = [19]int{
+	17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+}
+
+const (
+	transformSubtractGreen = 2
+
+	greenAlphabetSize    = 256 + 24
+	distanceAlphabetSize = 40
+)
+
+// EncodeWebP writes img as a lossless WebP (VP8L) image.
+//
+// The encoder only applies the subtract green transform and uses a single
+// set of Huffman codes without backward references, favoring simplicity over
+// compression ratio.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > 1<<14 || height > 1<<14 {
+		return fmt.Errorf("invalid WebP dimensions: %dx%d", width, height)
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(nrgba, nrgba.Bounds(), img, b.Min, draw.Src)
+
+	pix := nrgba.Pix
+
+	// histograms of green, red, blue and alpha after subtracting green
+	var hist [4][]int
+	hist[0] = make([]int, greenAlphabetSize)
+	for i := 1; i < 4; i++ {
+		hist[i] = make([]int, 256)
+	}
+
+	alphaUsed := uint32(0)
+	for i := 0; i < len(pix); i += 4 {
+		g := pix[i+1]
+		pix[i] -= g
+		pix[i+2] -= g
+
+		hist[0][g]++
+		hist[1][pix[i]]++
+		hist[2][pix[i+2]]++
+		hist[3][pix[i+3]]++
+
+		if pix[i+3] != 0xff {
+			alphaUsed = 1
+		}
+	}
+
+	bw := &bitWriter{}
+
+	// header: signature, size, alpha hint and version
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	bw.write(alphaUsed, 1)
+	bw.write(0, 3)
+
+	// a single subtract green transform
+	bw.write(1, 1)
+	bw.write(transformSubtractGreen, 2)
+	bw.write(0, 1)
+
+	// no color cache and no meta prefix codes
+	bw.write(0, 1)
+	bw.write(0, 1)
+
+	green := writePrefixCode(bw, hist[0])
+	red := writePrefixCode(bw, hist[1])
+	blue := writePrefixCode(bw, hist[2])
+	alpha := writePrefixCode(bw, hist[3])
+	// backward references are not used
+	writePrefixCode(bw, make([]int, distanceAlphabetSize))
+
+	for i := 0; i < len(pix); i += 4 {
+		green.write(bw, int(pix[i+1]))
+		red.write(bw, int(pix[i]))
+		blue.write(bw, int(pix[i+2]))
+		alpha.write(bw, int(pix[i+3]))
+	}
+
+	data := bw.flush()
+
+	pad := len(data) & 1
+
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+len(data)+pad))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if pad > 0 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bitWriter writes bits least significant bit first
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (bw *bitWriter) write(v uint32, nbits uint) {
+	bw.acc |= uint64(v) << bw.nacc
+	bw.nacc += nbits
+	for bw.nacc >= 8 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc >>= 8
+		bw.nacc -= 8
+	}
+}
+
+func (bw *bitWriter) flush() []byte {
+	if bw.nacc > 0 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc, bw.nacc = 0, 0
+	}
+	return bw.buf
+}
+
+// prefixCode holds the bit reversed canonical Huffman codes of an alphabet
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint16
+}
+
+func (pc prefixCode) write(bw *bitWriter, symbol int) {
+	if n := pc.lengths[symbol]; n > 0 {
+		bw.write(uint32(pc.codes[symbol]), uint(n))
+	}
+}
+
+// writePrefixCode writes the prefix code built from the symbol counts and
+// returns it
+func writePrefixCode(bw *bitWriter, counts []int) prefixCode {
+	var symbols []int
+	for s, c := range counts {
+		if c > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+
+	pc := prefixCode{
+		lengths: make([]uint8, len(counts)),
+		codes:   make([]uint16, len(counts)),
+	}
+
+	if len(symbols) == 0 {
+		symbols = []int{0}
+	}
+
+	// up to two 8 bits symbols use the "simple" code
+	if len(symbols) <= 2 && symbols[len(symbols)-1] < 256 {
+		bw.write(1, 1)
+		bw.write(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbols[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbols[0]), 8)
+		}
+
+		if len(symbols) == 2 {
+			bw.write(uint32(symbols[1]), 8)
+			pc.lengths[symbols[0]], pc.codes[symbols[0]] = 1, 0
+			pc.lengths[symbols[1]], pc.codes[symbols[1]] = 1, 1
+		}
+		return pc
+	}
+
+	pc.lengths = huffmanLengths(counts, 15)
+	pc.codes = canonicalCodes(pc.lengths)
+
+	bw.write(0, 1)
+	writeCodeLengths(bw, pc.lengths)
+	return pc
+}
+
+// writeCodeLengths writes the code lengths of a prefix code, runs of zeros
+// are written with the repeat codes 17 and 18
+func writeCodeLengths(bw *bitWriter, lengths []uint8) {
+	type token struct {
+		symbol    int
+		extra     uint32
+		extraBits uint
+	}
+
+	var tokens []token
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, token{symbol: int(lengths[i])})
+			i++
+			continue
+		}
+
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == 0 {
+			run++
+		}
+		i += run
+
+		for run > 0 {
+			switch {
+			case run >= 11:
+				n := run
+				if n > 138 {
+					n = 138
+				}
+				tokens = append(tokens, token{symbol: 18, extra: uint32(n - 11), extraBits: 7})
+				run -= n
+			case run >= 3:
+				tokens = append(tokens, token{symbol: 17, extra: uint32(run - 3), extraBits: 3})
+				run = 0
+			default:
+				tokens = append(tokens, token{symbol: 0})
+				run--
+			}
+		}
+	}
+
+	counts := make([]int, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		counts[t.symbol]++
+	}
+
+	// a code needs at least two symbols to have one bit codes
+	used := 0
+	for _, c := range counts {
+		if c > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		if counts[0] == 0 {
+			counts[0] = 1
+		} else {
+			counts[1] = 1
+		}
+	}
+
+	clLengths := huffmanLengths(counts, 7)
+	clCodes := canonicalCodes(clLengths)
+
+	n := len(codeLengthCodeOrder)
+	for n > 4 && clLengths[codeLengthCodeOrder[n-1]] == 0 {
+		n--
+	}
+
+	bw.write(uint32(n-4), 4)
+	for i := 0; i < n; i++ {
+		bw.write(uint32(clLengths[codeLengthCodeOrder[i]]), 3)
+	}
+
+	// the code lengths cover the whole alphabet
+	bw.write(0, 1)
+
+	for _, t := range tokens {
+		bw.write(uint32(clCodes[t.symbol]), uint(clLengths[t.symbol]))
+		if t.extraBits > 0 {
+			bw.write(t.extra, t.extraBits)
+		}
+	}
+}
+
+// huffmanLengths returns the Huffman code lengths of the symbols, limited
+// to maxLength bits by flattening the counts until the tree fits
+func huffmanLengths(counts []int, maxLength int) []uint8 {
+	type node struct {
+		count       int
+		symbol      int
+		left, right int
+	}
+
+	for shift := 0; ; shift++ {
+		var leaves []node
+		for s, c := range counts {
+			if c > 0 {
+				c >>= shift
+				if c == 0 {
+					c = 1
+				}
+				leaves = append(leaves, node{count: c, symbol: s, left: -1, right: -1})
+			}
+		}
+
+		lengths := make([]uint8, len(counts))
+		if len(leaves) == 1 {
+			lengths[leaves[0].symbol] = 1
+			return lengths
+		}
+
+		sort.SliceStable(leaves, func(i, j int) bool {
+			return leaves[i].count < leaves[j].count
+		})
+
+		// two queues: the sorted leaves and the internal nodes which are
+		// created in increasing count order
+		nodes := append([]node{}, leaves...)
+		li, ii := 0, len(leaves)
+		pick := func() int {
+			if li < len(leaves) && (ii >= len(nodes) || nodes[li].count <= nodes[ii].count) {
+				li++
+				return li - 1
+			}
+			ii++
+			return ii - 1
+		}
+
+		for n := len(leaves); n > 1; n-- {
+			a, b := pick(), pick()
+			nodes = append(nodes, node{
+				count:  nodes[a].count + nodes[b].count,
+				symbol: -1,
+				left:   a,
+				right:  b,
+			})
+		}
+
+		tooLong := false
+		var walk func(i int, depth int)
+		walk = func(i int, depth int) {
+			if nodes[i].symbol >= 0 {
+				if depth > maxLength {
+					tooLong = true
+				}
+				lengths[nodes[i].symbol] = uint8(depth)
+				return
+			}
+			walk(nodes[i].left, depth+1)
+			walk(nodes[i].right, depth+1)
+		}
+		walk(len(nodes)-1, 0)
+
+		if !tooLong {
+			return lengths
+		}
+	}
+}
+
+// canonicalCodes assigns the canonical codes of the lengths, bit reversed
+// since they're written least significant bit first
+func canonicalCodes(lengths []uint8) []uint16 {
+	var count [16]int
+	for _, l := range lengths {
+		if l > 0 {
+			count[l]++
+		}
+	}
+
+	var next [16]int
+	code := 0
+	for bits := 1; bits < 16; bits++ {
+		code = (code + count[bits-1]) << 1
+		next[bits] = code
+	}
+
+	codes := make([]uint16, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+
+		c := next[l]
+		next[l]++
+
+		var rev uint16
+		for i := uint8(0); i < l; i++ {
+			rev = rev<<1 | uint16(c>>i&1)
+		}
+		codes[s] = rev
+	}
+	return codes
+}
@@ -0,0 +1,64 @@
+package extra
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"os"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebP(t *testing.T) {
+	src, err := os.Open("./testdata/src.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	photo, err := DecodeImage("src.png", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noise := image.NewNRGBA(image.Rect(0, 0, 33, 17))
+	for i := range noise.Pix {
+		noise.Pix[i] = byte(rand.Intn(256))
+	}
+
+	solid := image.NewNRGBA(image.Rect(0, 0, 5, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			solid.Set(x, y, color.NRGBA{R: 10, G: 200, B: 30, A: 255})
+		}
+	}
+
+	for name, img := range map[string]image.Image{"photo": photo, "noise": noise, "solid": solid} {
+		buf := new(bytes.Buffer)
+		if err := EncodeWebP(buf, img); err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := webp.Decode(buf)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		b := img.Bounds()
+		if decoded.Bounds().Dx() != b.Dx() || decoded.Bounds().Dy() != b.Dy() {
+			t.Fatalf("%s: expected %v got %v", name, b, decoded.Bounds())
+		}
+
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				want := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y))
+				got := color.NRGBAModel.Convert(decoded.At(x, y))
+				if want != got {
+					t.Fatalf("%s: pixel %d,%d expected %v got %v", name, x, y, want, got)
+				}
+			}
+		}
+	}
+}
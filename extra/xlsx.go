@@ -0,0 +1,125 @@
+package extra
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// XLSXWriter writes rows to a single sheet Excel workbook with the same
+// API as csv.Writer. Cells are written as inline strings as the rows are
+// received, the workbook is complete once Close is called.
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	row   int
+	err   error
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const xlsxSheetEnd = `</sheetData></worksheet>`
+
+// NewXLSXWriter returns a writer of an Excel workbook to w
+func NewXLSXWriter(w io.Writer) *XLSXWriter {
+	xw := &XLSXWriter{zw: zip.NewWriter(w)}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+
+	for _, p := range parts {
+		f, err := xw.zw.Create(p.name)
+		if err != nil {
+			xw.err = err
+			return xw
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			xw.err = err
+			return xw
+		}
+	}
+
+	// the sheet is the last file of the archive, rows are streamed to it
+	xw.sheet, xw.err = xw.zw.Create("xl/worksheets/sheet1.xml")
+	if xw.err == nil {
+		_, xw.err = io.WriteString(xw.sheet, xlsxSheetStart)
+	}
+	return xw
+}
+
+// Write writes a row to the sheet
+func (xw *XLSXWriter) Write(record []string) error {
+	if xw.err != nil {
+		return xw.err
+	}
+
+	xw.row++
+
+	var buf bytes.Buffer
+	buf.WriteString(`<row r="` + strconv.Itoa(xw.row) + `">`)
+	for i, v := range record {
+		buf.WriteString(`<c r="` + xlsxColumn(i) + strconv.Itoa(xw.row) + `" t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&buf, []byte(v)); err != nil {
+			return err
+		}
+		buf.WriteString(`</t></is></c>`)
+	}
+	buf.WriteString(`</row>`)
+
+	_, xw.err = xw.sheet.Write(buf.Bytes())
+	return xw.err
+}
+
+// Close ends the sheet and writes the archive directory
+func (xw *XLSXWriter) Close() error {
+	if xw.err != nil {
+		return xw.err
+	}
+
+	if _, err := io.WriteString(xw.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
+
+// xlsxColumn returns the column name of a zero based index, i.e. A, Z, AA
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
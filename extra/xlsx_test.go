@@ -0,0 +1,57 @@
+package extra
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	xw := NewXLSXWriter(&buf)
+	if err := xw.Write([]string{"name", "message"}); err != nil {
+		t.Fatal(err)
+	} else if err := xw.Write([]string{"unit", "a < b & c"}); err != nil {
+		t.Fatal(err)
+	} else if err := xw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(r)
+		r.Close()
+		sheet = string(b)
+	}
+
+	if !strings.Contains(sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">a &lt; b &amp; c</t></is></c>`) {
+		t.Errorf("expected the escaped B2 cell got %s", sheet)
+	} else if !strings.HasSuffix(sheet, "</sheetData></worksheet>") {
+		t.Errorf("expected a complete sheet got %s", sheet)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, expected := range tests {
+		if col := xlsxColumn(i); col != expected {
+			t.Errorf("expected column %d to be %s got %s", i, expected, col)
+		}
+	}
+}
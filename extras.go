@@ -18,6 +18,7 @@ import (
 	"github.com/staticbackendhq/core/middleware"
 	"github.com/staticbackendhq/core/model"
 	"github.com/staticbackendhq/core/sms"
+	"github.com/staticbackendhq/core/storage"
 )
 
 type extras struct {
@@ -73,12 +74,12 @@ func (ex *extras) resizeImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fileKey := fmt.Sprintf("%s/%s/%s%s",
+	fileKey := storage.StoreKey(backend.Filestore, fmt.Sprintf("%s/%s/%s%s",
 		config.Name,
 		auth.AccountID,
 		name,
 		ext,
-	)
+	))
 
 	resizedBytes := buf.Bytes()
 
@@ -167,12 +168,12 @@ func (ex *extras) htmlToX(w http.ResponseWriter, r *http.Request) {
 		ext = ".pdf"
 	}
 
-	fileKey := fmt.Sprintf("%s/%s/%d%s",
+	fileKey := storage.StoreKey(backend.Filestore, fmt.Sprintf("%s/%s/%d%s",
 		config.Name,
 		auth.AccountID,
 		time.Now().UnixNano(),
 		ext,
-	)
+	))
 
 	ufd := model.UploadFileData{
 		FileKey: fileKey,
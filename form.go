@@ -1,11 +1,17 @@
 package staticbackend
 
 import (
+	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/staticbackendhq/core/backend"
 	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
 )
 
 func submitForm(w http.ResponseWriter, r *http.Request) {
@@ -17,52 +23,350 @@ func submitForm(w http.ResponseWriter, r *http.Request) {
 
 	form := getURLPart(r.URL.Path, 2)
 
-	doc := make(map[string]interface{})
+	var files map[string][]*multipart.FileHeader
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
 
-	//TODO: Why forms would need multiplart/form-data
-	// There's no file upload available via form
-	/*if err := r.ParseMultipartForm(32 << 20); err != nil {
+		files = r.MultipartForm.File
+	} else if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// suspicious submissions are flagged, bots should not notice it
+	ip := middleware.ClientIP(r)
+	token := r.Header.Get("SB-CAPTCHA-TOKEN")
+	_, err = backend.SubmitForm(conf, form, ip, token, r.Form, files)
+
+	var verr *backend.FormValidationError
+	if errors.As(err, &verr) {
+		respond(w, http.StatusBadRequest, verr)
+		return
+	} else if errors.Is(err, backend.ErrFormRateLimited) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	} else if errors.Is(err, backend.ErrTooManyAttachments) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		uploadError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+func listForm(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	formName := r.URL.Query().Get("name")
+
+	results, err := backend.DB.ListFormSubmissions(conf.Name, formName)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
-	}*/
+	}
+
+	// ?spam=false hides the submissions flagged as spam, ?spam=true only
+	// returns them
+	if spam := r.URL.Query().Get("spam"); len(spam) > 0 {
+		flagged := spam == "true"
+
+		filtered := make([]map[string]interface{}, 0)
+		for _, doc := range results {
+			if isSpam, _ := doc["sb_spam"].(bool); isSpam == flagged {
+				filtered = append(filtered, doc)
+			}
+		}
+		results = filtered
+	}
+
+	respond(w, http.StatusOK, results)
+}
+
+// formActions handles the /form/{name}/{action} routes
+func formActions(w http.ResponseWriter, r *http.Request) {
+	if len(getURLPart(r.URL.Path, 2)) == 0 {
+		http.NotFound(w, r)
+		return
+	}
 
-	if err := r.ParseForm(); err != nil {
+	switch getURLPart(r.URL.Path, 3) {
+	case "export":
+		exportForm(w, r)
+	case "deliveries":
+		listFormDeliveries(w, r)
+	case "fields":
+		formFields(w, r)
+	case "submissions":
+		formSubmissions(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// exportForm streams the submissions of a form as CSV or XLSX, i.e.
+// GET /form/contact/export?format=xlsx&since=2022-01-01&until=2022-12-31
+func exportForm(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// if there's something in the _hp_ field, it's a bot
-	if len(r.Form.Get("_hp_")) > 0 {
-		http.Error(w, "invalid form field present", http.StatusBadRequest)
+	form := getURLPart(r.URL.Path, 2)
+
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if len(format) == 0 {
+		format = backend.FormExportCSV
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case backend.FormExportCSV:
+	case backend.FormExportXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		http.Error(w, backend.ErrInvalidExportFormat.Error(), http.StatusBadRequest)
 		return
 	}
 
-	for k, v := range r.Form {
-		doc[k] = strings.Join(v, ", ")
+	since, err := parseDateParam(q.Get("since"), false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	until, err := parseDateParam(q.Get("until"), true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, form, format))
 
-	if err := backend.DB.AddFormSubmission(conf.Name, form, doc); err != nil {
+	// the rows are streamed, the status is already sent on errors
+	if err := backend.ExportForm(conf, form, format, since, until, w); err != nil {
+		backend.Log.Error().Err(err).Msgf("error exporting form %s", form)
+	}
+}
+
+// parseDateParam parses an RFC3339 time or a YYYY-MM-DD date, a date at
+// the end of the range includes the whole day
+func parseDateParam(s string, end bool) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC3339", s)
+	}
+
+	if end {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// listFormDeliveries returns the most recent webhook delivery attempts of a
+// form, GET /form/contact/deliveries?limit=50
+func listFormDeliveries(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	form := getURLPart(r.URL.Path, 2)
+
+	limit := int64(100)
+	if v := r.URL.Query().Get("limit"); len(v) > 0 {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	results, err := backend.DB.ListFormDeliveries(conf.Name, form, limit)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respond(w, http.StatusOK, true)
+	if results == nil {
+		results = make([]model.FormDelivery, 0)
+	}
+
+	respond(w, http.StatusOK, results)
 }
 
-func listForm(w http.ResponseWriter, r *http.Request) {
+// formFields returns, saves or deletes the field definitions validated when
+// the form is submitted
+func formFields(w http.ResponseWriter, r *http.Request) {
 	conf, _, err := middleware.Extract(r, true)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	formName := r.URL.Query().Get("name")
+	form := getURLPart(r.URL.Path, 2)
+
+	switch r.Method {
+	case http.MethodGet:
+		def, err := backend.DB.GetFormDefinition(conf.Name, form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if def.Fields == nil {
+			def.Fields = make([]model.FormField, 0)
+		}
+		respond(w, http.StatusOK, def)
+	case http.MethodPost, http.MethodPut:
+		var fields []model.FormField
+		if err := parseBody(r.Body, &fields); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		def := model.FormDefinition{Name: form, Fields: fields}
+
+		var verr *backend.FormValidationError
+		if err := backend.SaveFormDefinition(conf, def); errors.As(err, &verr) {
+			respond(w, http.StatusBadRequest, verr)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, true)
+	case http.MethodDelete:
+		if err := backend.DB.DeleteFormDefinition(conf.Name, form); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, true)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// formSubmissions lists, deletes or marks as read the submissions of a form
+//
+//	GET /form/contact/submissions?page=1&size=25&q=term&since=&until=&spam=&read=
+//	DELETE /form/contact/submissions/{id}
+//	PUT /form/contact/submissions/{id}/read (DELETE to mark as unread)
+func formSubmissions(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	form := getURLPart(r.URL.Path, 2)
+	id := getURLPart(r.URL.Path, 4)
+
+	if len(id) == 0 {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		listFormSubmissions(w, r, conf.Name, form)
+		return
+	}
+
+	switch {
+	case getURLPart(r.URL.Path, 5) == "read" && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		err = backend.DB.MarkFormSubmissionRead(conf.Name, form, id, true)
+	case getURLPart(r.URL.Path, 5) == "read" && r.Method == http.MethodDelete:
+		err = backend.DB.MarkFormSubmissionRead(conf.Name, form, id, false)
+	case len(getURLPart(r.URL.Path, 5)) == 0 && r.Method == http.MethodDelete:
+		err = backend.DB.DeleteFormSubmission(conf.Name, form, id)
+	default:
+		http.NotFound(w, r)
+		return
+	}
 
-	results, err := backend.DB.ListFormSubmissions(conf.Name, formName)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respond(w, http.StatusOK, results)
+	respond(w, http.StatusOK, true)
+}
+
+func listFormSubmissions(w http.ResponseWriter, r *http.Request, dbName, form string) {
+	page, size := getPagination(r.URL)
+	if page < 1 || size < 1 {
+		http.Error(w, "invalid page or size", http.StatusBadRequest)
+		return
+	}
+
+	params := model.ListParams{
+		Page:           page,
+		Size:           size,
+		SortDescending: r.URL.Query().Get("asc") != "true",
+	}
+
+	q := r.URL.Query()
+
+	f := model.FormFilter{Search: q.Get("q")}
+
+	var err error
+	if f.Since, err = parseDateParam(q.Get("since"), false); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if f.Until, err = parseDateParam(q.Get("until"), true); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if f.Spam, err = parseBoolParam(q.Get("spam")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if f.Read, err = parseBoolParam(q.Get("read")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := backend.DB.QueryFormSubmissions(dbName, form, f, params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	paginateResult(w, r, &result)
+	respond(w, http.StatusOK, result)
+}
+
+// parseBoolParam returns nil for an empty value
+func parseBoolParam(s string) (*bool, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid boolean %q", s)
+	}
+	return &b, nil
 }
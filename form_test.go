@@ -1,8 +1,14 @@
 package staticbackend
 
 import (
+	"encoding/csv"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
 )
 
 func TestFormSubmission(t *testing.T) {
@@ -33,3 +39,215 @@ func TestFormSubmission(t *testing.T) {
 		t.Errorf("expected email to be unit@test.com got %v", results[0]["email"])
 	}
 }
+
+func TestFormSubmissionHoneypot(t *testing.T) {
+	val := url.Values{}
+	val.Add("name", "bot")
+	val.Add("_hp_", "filled")
+
+	// bots are not told their submission is flagged
+	resp := dbReq(t, submitForm, "POST", "/postform/honeypot", val, false, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	resp2 := dbReq(t, listForm, "GET", "/form?name=honeypot&spam=false", nil, true)
+	defer resp2.Body.Close()
+
+	var results []map[string]interface{}
+	if err := parseBody(resp2.Body, &results); err != nil {
+		t.Fatal(err)
+	} else if len(results) != 0 {
+		t.Errorf("expected flagged submissions to be filtered out got %v", results)
+	}
+
+	resp3 := dbReq(t, listForm, "GET", "/form?name=honeypot&spam=true", nil, true)
+	defer resp3.Body.Close()
+
+	if err := parseBody(resp3.Body, &results); err != nil {
+		t.Fatal(err)
+	} else if len(results) != 1 {
+		t.Errorf("expected 1 flagged submission got %d", len(results))
+	}
+}
+
+func TestFormExport(t *testing.T) {
+	val := url.Values{}
+	val.Add("name", "first")
+	val.Add("email", "first@test.com")
+
+	resp := dbReq(t, submitForm, "POST", "/postform/exported", val, false, true)
+	defer resp.Body.Close()
+
+	// the second submission has a different set of fields
+	val = url.Values{}
+	val.Add("name", "second")
+	val.Add("phone", "555-1234")
+
+	resp2 := dbReq(t, submitForm, "POST", "/postform/exported", val, false, true)
+	defer resp2.Body.Close()
+
+	resp3 := dbReq(t, formActions, "GET", "/form/exported/export", nil, true)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp3))
+	} else if ct := resp3.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected a CSV content type got %s", ct)
+	}
+
+	records, err := csv.NewReader(resp3.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows got %v", records)
+	}
+
+	expected := []string{"id", "created", "email", "name", "phone"}
+	if strings.Join(records[0], ",") != strings.Join(expected, ",") {
+		t.Errorf("expected columns %v got %v", expected, records[0])
+	} else if records[1][3] != "first" || records[1][4] != "" {
+		t.Errorf("unexpected first row %v", records[1])
+	} else if records[2][2] != "" || records[2][4] != "555-1234" {
+		t.Errorf("unexpected second row %v", records[2])
+	}
+
+	resp4 := dbReq(t, formActions, "GET", "/form/exported/export?until=2001-01-01", nil, true)
+	defer resp4.Body.Close()
+
+	records, err = csv.NewReader(resp4.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(records) != 1 {
+		t.Errorf("expected only the header got %v", records)
+	}
+
+	resp5 := dbReq(t, formActions, "GET", "/form/exported/export?format=pdf", nil, true)
+	defer resp5.Body.Close()
+
+	if resp5.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown format got %d", resp5.StatusCode)
+	}
+}
+
+func TestFormDeliveriesList(t *testing.T) {
+	resp := dbReq(t, formActions, "GET", "/form/no-hooks/deliveries", nil, true)
+	defer resp.Body.Close()
+
+	var results []interface{}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &results); err != nil {
+		t.Fatal(err)
+	} else if len(results) != 0 {
+		t.Errorf("expected no deliveries got %v", results)
+	}
+
+	resp2 := dbReq(t, formActions, "GET", "/form/no-hooks/unknown", nil, true)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 got %d", resp2.StatusCode)
+	}
+}
+
+func TestFormFieldsValidation(t *testing.T) {
+	fields := []model.FormField{
+		{Name: "email", Type: model.FormFieldEmail, Required: true},
+	}
+
+	resp := dbReq(t, formActions, "PUT", "/form/with-fields/fields", fields, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	val := url.Values{}
+	val.Add("email", "invalid")
+
+	resp2 := dbReq(t, submitForm, "POST", "/postform/with-fields", val, false, true)
+	defer resp2.Body.Close()
+
+	var verr backend.FormValidationError
+	if resp2.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", resp2.StatusCode)
+	} else if err := parseBody(resp2.Body, &verr); err != nil {
+		t.Fatal(err)
+	} else if len(verr.Fields["email"]) == 0 {
+		t.Errorf("expected an error for the email field got %v", verr.Fields)
+	}
+
+	resp3 := dbReq(t, formActions, "DELETE", "/form/with-fields/fields", nil, true)
+	defer resp3.Body.Close()
+
+	resp4 := dbReq(t, submitForm, "POST", "/postform/with-fields", val, false, true)
+	defer resp4.Body.Close()
+
+	if resp4.StatusCode != http.StatusOK {
+		t.Errorf("expected the submission to be accepted once the fields are deleted got %d", resp4.StatusCode)
+	}
+}
+
+func TestFormSubmissionsPaging(t *testing.T) {
+	for _, name := range []string{"alice", "bob", "carol"} {
+		val := url.Values{}
+		val.Add("name", name)
+
+		resp := dbReq(t, submitForm, "POST", "/postform/paged", val, false, true)
+		resp.Body.Close()
+	}
+
+	resp := dbReq(t, formActions, "GET", "/form/paged/submissions?size=2", nil, true)
+	defer resp.Body.Close()
+
+	var res model.PagedResult
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &res); err != nil {
+		t.Fatal(err)
+	} else if res.Total != 3 || len(res.Results) != 2 {
+		t.Fatalf("expected 2 of 3 submissions got %d of %d", len(res.Results), res.Total)
+	}
+
+	resp2 := dbReq(t, formActions, "GET", "/form/paged/submissions?q=CAR", nil, true)
+	defer resp2.Body.Close()
+
+	if err := parseBody(resp2.Body, &res); err != nil {
+		t.Fatal(err)
+	} else if res.Total != 1 || res.Results[0]["name"] != "carol" {
+		t.Fatalf("expected the search to match carol got %v", res.Results)
+	}
+
+	id, _ := res.Results[0]["id"].(string)
+
+	resp3 := dbReq(t, formActions, "PUT", "/form/paged/submissions/"+id+"/read", nil, true)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp3))
+	}
+
+	resp4 := dbReq(t, formActions, "GET", "/form/paged/submissions?read=false", nil, true)
+	defer resp4.Body.Close()
+
+	if err := parseBody(resp4.Body, &res); err != nil {
+		t.Fatal(err)
+	} else if res.Total != 2 {
+		t.Errorf("expected 2 unread submissions got %d", res.Total)
+	}
+
+	resp5 := dbReq(t, formActions, "DELETE", "/form/paged/submissions/"+id, nil, true)
+	defer resp5.Body.Close()
+
+	resp6 := dbReq(t, formActions, "GET", "/form/paged/submissions", nil, true)
+	defer resp6.Body.Close()
+
+	if err := parseBody(resp6.Body, &res); err != nil {
+		t.Fatal(err)
+	} else if res.Total != 2 {
+		t.Errorf("expected 2 submissions after the delete got %d", res.Total)
+	}
+}
@@ -0,0 +1,98 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/staticbackendhq/core/model"
+)
+
+// ParseInterval parses a task's interval, a standard 5 fields cron
+// expression (minute hour day-of-month month day-of-week) like */5 * * * *
+// or a named schedule: @yearly, @monthly, @weekly, @daily, @hourly or
+// @every 1h30m.
+func ParseInterval(interval string) (cron.Schedule, error) {
+	sched, err := cron.ParseStandard(interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid task interval %q: %w", interval, err)
+	}
+	return sched, nil
+}
+
+// ValidateTask validates a task's type, target and schedule, a task either
+// has an Interval, runs once at RunAt or runs after another task
+func ValidateTask(task model.Task) error {
+	if len(task.Name) == 0 {
+		return errors.New("the task name is required")
+	}
+
+	switch task.Type {
+	case model.TaskTypeFunction, model.TaskTypeMessage, model.TaskTypeHTTP, model.TaskTypeBackup:
+	default:
+		return fmt.Errorf("invalid task type %q", task.Type)
+	}
+
+	if len(task.Value) == 0 {
+		return errors.New("the task value is required")
+	} else if task.Type == model.TaskTypeHTTP {
+		u, err := url.Parse(task.Value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return fmt.Errorf("invalid task URL %q", task.Value)
+		}
+	} else if task.Type == model.TaskTypeBackup {
+		if n, err := strconv.Atoi(task.Value); err != nil || n <= 0 {
+			return fmt.Errorf("invalid number of backups kept %q", task.Value)
+		}
+	}
+
+	if err := validateRetry(task.Retry); err != nil {
+		return err
+	}
+
+	if task.IsDependent() {
+		if task.After == task.ID {
+			return errors.New("a task cannot run after itself")
+		} else if len(task.Interval) > 0 || !task.RunAt.IsZero() {
+			return errors.New("a task running after another cannot have an interval or a time to run at")
+		}
+		return nil
+	}
+
+	if len(task.Interval) == 0 {
+		if task.RunAt.IsZero() {
+			return errors.New("a task needs an interval, a time to run at or a task to run after")
+		}
+		return nil
+	} else if !task.RunAt.IsZero() {
+		return errors.New("a task cannot have both an interval and a time to run at")
+	}
+
+	_, err := ParseInterval(task.Interval)
+	return err
+}
+
+// NextRun returns the next time the task runs after t, schedules are in UTC.
+// The next run of a disabled, dependent or completed one-off task is the
+// zero time.
+func NextRun(task model.Task, t time.Time) (time.Time, error) {
+	if !task.Enabled || task.IsDependent() {
+		return time.Time{}, nil
+	}
+
+	if task.IsOneOff() {
+		if task.Completed() {
+			return time.Time{}, nil
+		}
+		return task.RunAt.UTC(), nil
+	}
+
+	sched, err := ParseInterval(task.Interval)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(t.UTC()), nil
+}
@@ -0,0 +1,114 @@
+package function
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestNextRun(t *testing.T) {
+	from := time.Date(2023, 5, 10, 14, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		interval string
+		expected time.Time
+	}{
+		{"*/5 * * * *", time.Date(2023, 5, 10, 14, 10, 0, 0, time.UTC)},
+		{"30 6 * * *", time.Date(2023, 5, 11, 6, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2023, 5, 10, 15, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2023, 5, 11, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h", from.Add(time.Hour).Truncate(time.Second)},
+	}
+
+	for _, tc := range tests {
+		next, err := NextRun(model.Task{Interval: tc.interval, Enabled: true}, from)
+		if err != nil {
+			t.Errorf("%s: %v", tc.interval, err)
+		} else if !next.Equal(tc.expected) {
+			t.Errorf("%s: expected %v got %v", tc.interval, tc.expected, next)
+		}
+	}
+}
+
+func TestParseIntervalInvalid(t *testing.T) {
+	for _, interval := range []string{"", "every hour", "61 * * * *", "* * * *", "@fortnightly"} {
+		if _, err := ParseInterval(interval); err == nil {
+			t.Errorf("expected %q to be invalid", interval)
+		}
+	}
+}
+
+func TestNextRunOneOff(t *testing.T) {
+	at := time.Date(2023, 5, 10, 14, 7, 30, 0, time.UTC)
+	task := model.Task{RunAt: at, Enabled: true}
+
+	if next, err := NextRun(task, at.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	} else if !next.Equal(at) {
+		t.Errorf("expected %v got %v", at, next)
+	}
+
+	task.LastStatus = model.TaskStatusSuccess
+	if next, err := NextRun(task, at.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	} else if !next.IsZero() {
+		t.Errorf("expected no next run for a completed task got %v", next)
+	}
+}
+
+func TestValidateTask(t *testing.T) {
+	valid := model.Task{Name: "job", Type: model.TaskTypeFunction, Value: "fn", Interval: "@daily"}
+	if err := ValidateTask(valid); err != nil {
+		t.Fatal(err)
+	}
+
+	oneOff := valid
+	oneOff.Interval = ""
+	oneOff.RunAt = time.Now().Add(time.Hour)
+	if err := ValidateTask(oneOff); err != nil {
+		t.Fatal(err)
+	}
+
+	dependent := valid
+	dependent.Interval = ""
+	dependent.After = "other"
+	if err := ValidateTask(dependent); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := model.Task{Name: "backup", Type: model.TaskTypeBackup, Value: "7", Interval: "@daily"}
+	if err := ValidateTask(backup); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []model.Task{
+		{Type: model.TaskTypeFunction, Value: "fn", Interval: "@daily"},
+		{Name: "job", Type: "email", Value: "fn", Interval: "@daily"},
+		{Name: "job", Type: model.TaskTypeFunction, Interval: "@daily"},
+		{Name: "job", Type: model.TaskTypeFunction, Value: "fn"},
+		{Name: "job", Type: model.TaskTypeFunction, Value: "fn", Interval: "@daily", RunAt: time.Now()},
+		{Name: "job", Type: model.TaskTypeFunction, Value: "fn", Interval: "every day"},
+		{ID: "job-id", Name: "job", Type: model.TaskTypeFunction, Value: "fn", After: "job-id"},
+		{Name: "job", Type: model.TaskTypeFunction, Value: "fn", After: "other", Interval: "@daily"},
+		{Name: "job", Type: model.TaskTypeHTTP, Value: "example.com/hook", Interval: "@daily"},
+		{Name: "job", Type: model.TaskTypeHTTP, Value: "ftp://example.com/hook", Interval: "@daily"},
+		{Name: "job", Type: model.TaskTypeBackup, Value: "all", Interval: "@daily"},
+		{Name: "job", Type: model.TaskTypeBackup, Value: "0", Interval: "@daily"},
+	}
+	for _, task := range invalid {
+		if err := ValidateTask(task); err == nil {
+			t.Errorf("expected %v to be invalid", task)
+		}
+	}
+}
+
+func TestNextRunDisabled(t *testing.T) {
+	task := model.Task{Interval: "@hourly", Enabled: false}
+
+	if next, err := NextRun(task, time.Now()); err != nil {
+		t.Fatal(err)
+	} else if !next.IsZero() {
+		t.Errorf("expected no next run for a disabled task got %v", next)
+	}
+}
@@ -0,0 +1,27 @@
+package function
+
+import (
+	"context"
+	"sync"
+)
+
+// running tracks the executions and the saving of their history so the
+// server waits for them before exiting
+var running sync.WaitGroup
+
+// Wait waits for the running functions to complete and their history to be
+// saved. It returns the context's error when it's done first.
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,24 @@
+package function
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	running.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded got %v", err)
+	}
+
+	running.Done()
+
+	if err := Wait(context.Background()); err != nil {
+		t.Errorf("expected no running functions got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package function
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/staticbackendhq/core/internal"
+)
+
+var (
+	// FetchTimeout is the timeout of each fetch() request
+	FetchTimeout = 30 * time.Second
+	// FetchMaxResponseSize is the maximum size in bytes of a fetch()
+	// response body
+	FetchMaxResponseSize int64 = 5 << 20
+	// FetchAllowPrivateNetworks lets fetch() connect to private, loopback
+	// and link-local addresses, i.e. to call services of the instance's
+	// network
+	FetchAllowPrivateNetworks = false
+)
+
+// fetchTransport checks the address of each connection once the host is
+// resolved, a public host name could point to an internal address
+var fetchTransport = &http.Transport{
+	DialContext: (&net.Dialer{
+		Timeout: FetchTimeout,
+		Control: checkFetchAddress,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          100,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
+}
+
+// checkFetchAddress refuses the connections to private, loopback and
+// link-local addresses unless FetchAllowPrivateNetworks is set
+func checkFetchAddress(network, address string, c syscall.RawConn) error {
+	if FetchAllowPrivateNetworks {
+		return nil
+	}
+	return internal.CheckPublicAddress(network, address, c)
+}
+
+// fetch is the fetch(url, {method, headers, body}) binding, a body that is
+// not a string is sent as JSON. It returns the status, headers and body of
+// the response, a JSON body is also decoded in json.
+func (env *ExecutionEnvironment) fetch(vm *goja.Runtime) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return goja.Undefined()
+		}
+
+		var rawURL string
+		if err := vm.ExportTo(call.Argument(0), &rawURL); err != nil || len(rawURL) == 0 {
+			return vm.ToValue(Result{Content: "the url should not be blank"})
+		}
+
+		opts := NewJSFetcthOptionArg()
+		if len(call.Arguments) > 1 {
+			if err := vm.ExportTo(call.Argument(1), &opts); err != nil {
+				return vm.ToValue(Result{Content: "the second argument should be an object"})
+			}
+		}
+
+		res, err := env.doFetch(rawURL, opts)
+		if err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error calling fetch(): %v", err)})
+		}
+		return vm.ToValue(Result{OK: true, Content: res})
+	}
+}
+
+func (env *ExecutionEnvironment) doFetch(rawURL string, opts JSFetchOptionsArg) (HTTPResponse, error) {
+	var res HTTPResponse
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return res, err
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return res, fmt.Errorf("unsupported URL scheme %q, use http or https", u.Scheme)
+	} else if !env.settings.Fetch.Allows(u.Hostname()) {
+		return res, fmt.Errorf("the host %s is not allowed", u.Hostname())
+	}
+
+	var body io.Reader
+	switch b := opts.Body.(type) {
+	case nil:
+	case string:
+		body = strings.NewReader(b)
+	default:
+		buf, err := json.Marshal(b)
+		if err != nil {
+			return res, fmt.Errorf("unable to encode the body: %v", err)
+		}
+		body = strings.NewReader(string(buf))
+
+		if _, ok := headerValue(opts.Headers, "Content-Type"); !ok {
+			opts.Headers["Content-Type"] = "application/json"
+		}
+	}
+
+	ctx := env.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, FetchTimeout)
+	defer cancel()
+
+	method := strings.ToUpper(opts.Method)
+	if len(method) == 0 {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return res, err
+	}
+	for k, v := range opts.Headers {
+		if len(k) > 0 && len(v) > 0 {
+			req.Header.Set(k, v)
+		}
+	}
+
+	// the redirects are followed to the allowed hosts only
+	client := &http.Client{
+		Transport: fetchTransport,
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			} else if !env.settings.Fetch.Allows(r.URL.Hostname()) {
+				return fmt.Errorf("redirected to %s which is not allowed", r.URL.Hostname())
+			}
+			return nil
+		},
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, FetchMaxResponseSize+1))
+	if err != nil {
+		return res, err
+	} else if int64(len(b)) > FetchMaxResponseSize {
+		return res, fmt.Errorf("the response exceeds %d bytes", FetchMaxResponseSize)
+	}
+
+	res.Status = resp.StatusCode
+	res.Body = string(b)
+	res.Headers = make(map[string]string)
+	for k, v := range resp.Header {
+		res.Headers[strings.ToLower(k)] = strings.Join(v, ", ")
+	}
+
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == "application/json" || strings.HasSuffix(mt, "+json") {
+		var v interface{}
+		if err := json.Unmarshal(b, &v); err == nil {
+			res.JSON = v
+		}
+	}
+	return res, nil
+}
+
+// headerValue returns the value of a header regardless of its case
+func headerValue(headers map[string]string, name string) (string, bool) {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -1,30 +1,41 @@
 package function
 
+import (
+	"fmt"
+	"time"
+)
+
+// JSFetchOptionsArg is the options argument of fetch(), the runtime maps
+// the fields by their json tag
 type JSFetchOptionsArg struct {
-	Method         string
-	Headers        map[string]string
-	Body           string
-	Mode           string
-	Credentials    string
-	Cache          string
-	Redirect       string
-	Referrer       string
-	ReferrerPolicy string
-	Integrity      string
-	Keepalive      string
-	Signal         string
+	Method         string            `json:"method"`
+	Headers        map[string]string `json:"headers"`
+	Body           interface{}       `json:"body"`
+	Mode           string            `json:"mode"`
+	Credentials    string            `json:"credentials"`
+	Cache          string            `json:"cache"`
+	Redirect       string            `json:"redirect"`
+	Referrer       string            `json:"referrer"`
+	ReferrerPolicy string            `json:"referrerPolicy"`
+	Integrity      string            `json:"integrity"`
+	Keepalive      string            `json:"keepalive"`
+	Signal         string            `json:"signal"`
 }
 
+// HTTPResponse is the response of fetch(), JSON is the decoded body when
+// its content type is JSON
 type HTTPResponse struct {
-	Status int    `json:"status"`
-	Body   string `json:"body"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	JSON    interface{}       `json:"json"`
 }
 
 func NewJSFetcthOptionArg() JSFetchOptionsArg {
 	defaultOptions := JSFetchOptionsArg{
 		Method:         "GET",
 		Headers:        make(map[string]string, 0),
-		Body:           "",
+		Body:           nil,
 		Mode:           "no-cors",
 		Credentials:    "omit",
 		Cache:          "no-cache",
@@ -44,4 +55,35 @@ type JSSendMailArg struct {
 	Subject  string `json:"subject"`
 	HTMLBody string `json:"htmlBody"`
 	TextBody string `json:"textBody"`
+	// Template is the name of an email template rendered with Vars instead
+	// of the subject and bodies
+	Template string                 `json:"template"`
+	Vars     map[string]interface{} `json:"vars"`
+	// Attachments are stored files by their ID or inline base64 content
+	Attachments []JSEmailAttachment `json:"attachments"`
+}
+
+// JSEmailAttachment is an attachment of sendMail, either a stored file
+// referenced by FileID or the base64 encoded Content
+type JSEmailAttachment struct {
+	FileID      string `json:"fileId"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+// exportTime converts a JavaScript Date, an RFC3339 string or a Unix
+// timestamp in milliseconds to a time.Time
+func exportTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		return time.Parse(time.RFC3339, t)
+	case int64:
+		return time.UnixMilli(t), nil
+	case float64:
+		return time.UnixMilli(int64(t)), nil
+	}
+	return time.Time{}, fmt.Errorf("unable to convert %v to a time", v)
 }
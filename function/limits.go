@@ -0,0 +1,116 @@
+package function
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+var (
+	// MaxDuration is the longest a run can last before being killed
+	MaxDuration = 60 * time.Second
+	// MaxOutput is the bytes of log output kept per run, 0 is unlimited
+	MaxOutput = 0
+	// MaxDBCalls is the number of database calls a run can make, 0 is
+	// unlimited
+	MaxDBCalls = 0
+)
+
+// ErrKilled is returned when a run is interrupted before completing
+var ErrKilled = errors.New("killed")
+
+// runLimits are the effective limits of a run
+type runLimits struct {
+	timeout    time.Duration
+	maxOutput  int
+	maxDBCalls int
+}
+
+// limits returns the instance's limits lowered by the ones of the database
+// and function, the instance's limits apply when they cannot be read
+func (env *ExecutionEnvironment) limits() runLimits {
+	rl := runLimits{timeout: MaxDuration, maxOutput: MaxOutput, maxDBCalls: MaxDBCalls}
+	if env.Settings == nil {
+		return rl
+	}
+
+	fs, err := env.Settings(env.BaseName)
+	if err != nil {
+		env.Log.Warn().Err(err).Msgf("cannot read the function settings of %s", env.BaseName)
+		return rl
+	}
+
+	env.settings = fs
+	fl := fs.For(env.Data.FunctionName)
+
+	if t := time.Duration(fl.Timeout) * time.Second; t > 0 && (rl.timeout <= 0 || t < rl.timeout) {
+		rl.timeout = t
+	}
+	rl.maxOutput = lower(rl.maxOutput, fl.MaxOutput)
+	rl.maxDBCalls = lower(rl.maxDBCalls, fl.MaxDBCalls)
+	return rl
+}
+
+// lower returns the limit lowered by override, 0 being unlimited
+func lower(limit, override int) int {
+	if override > 0 && (limit == 0 || override < limit) {
+		return override
+	}
+	return limit
+}
+
+// watch interrupts the VM when the context is done, i.e. the run timed out
+// or the request calling the function was cancelled. The returned function
+// stops watching.
+func watch(ctx context.Context, vm *goja.Runtime) func() {
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			vm.Interrupt(ctx.Err())
+		case <-stop:
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// killedError returns ErrKilled with the reason when the run was
+// interrupted
+func killedError(err error, timeout time.Duration) error {
+	var ie *goja.InterruptedError
+	if !errors.As(err, &ie) {
+		return err
+	}
+
+	if reason, ok := ie.Value().(error); ok && errors.Is(reason, context.Canceled) {
+		return fmt.Errorf("%w: the request was cancelled", ErrKilled)
+	}
+	return fmt.Errorf("%w: exceeded %g seconds", ErrKilled, timeout.Seconds())
+}
+
+// dbCall counts the database calls of the run, it throws once the limit
+// is exceeded
+func (env *ExecutionEnvironment) dbCall(vm *goja.Runtime) {
+	env.dbCalls++
+	if max := env.runLimits.maxDBCalls; max > 0 && env.dbCalls > max {
+		panic(vm.NewGoError(fmt.Errorf("exceeded %d database calls", max)))
+	}
+}
+
+// output adds a line to the run's output, the lines exceeding the output
+// limit are dropped
+func (env *ExecutionEnvironment) output(line string) {
+	max := env.runLimits.maxOutput
+	if max > 0 && env.outputSize >= max {
+		return
+	}
+
+	env.outputSize += len(line)
+	if max > 0 && env.outputSize >= max {
+		line = fmt.Sprintf("output truncated: exceeded %d bytes", max)
+	}
+	env.CurrentRun.Output = append(env.CurrentRun.Output, line)
+}
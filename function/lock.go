@@ -0,0 +1,98 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/staticbackendhq/core/model"
+)
+
+const (
+	// lockSkew is the maximum clock difference between instances firing the
+	// same scheduled run
+	lockSkew = 30 * time.Second
+	// lockTTL is how long a run's lock is held, longer than any skew
+	lockTTL = time.Hour
+	// oneOffLockTTL is how long a one-off task's lock is held, its run is
+	// recorded before the lock expires
+	oneOffLockTTL = 24 * time.Hour
+)
+
+// ErrTaskRunning is returned when running a task which is already running
+var ErrTaskRunning = errors.New("the task is already running")
+
+// runLockKey returns the cache key identifying a scheduled run of a task
+// cluster-wide, all instances firing the same run compute the same key
+func runLockKey(task model.Task, now time.Time) (string, time.Duration, error) {
+	if task.IsOneOff() {
+		return "sb-task-lock:" + task.ID, oneOffLockTTL, nil
+	}
+
+	sched, err := ParseInterval(task.Interval)
+	if err != nil {
+		return "", 0, err
+	}
+
+	now = now.UTC()
+
+	var tick time.Time
+	if every, ok := sched.(cron.ConstantDelaySchedule); ok {
+		// @every schedules start with each instance, the runs are grouped
+		// per period
+		tick = now.Truncate(every.Delay)
+	} else {
+		// the run fired at most lockSkew ago on the other instances
+		tick = sched.Next(now.Add(-lockSkew))
+	}
+
+	return fmt.Sprintf("sb-task-lock:%s:%d", task.ID, tick.Unix()), lockTTL, nil
+}
+
+// acquireRun returns whether this instance executes the scheduled run of a
+// task, the first instance setting the run's lock executes it
+func (ts *TaskScheduler) acquireRun(task model.Task) bool {
+	key, ttl, err := runLockKey(task, time.Now())
+	if err != nil {
+		ts.Log.Error().Err(err).Msgf("error computing the lock of task %s", task.ID)
+		return false
+	}
+
+	ok, err := ts.Volatile.SetNX(key, instanceID(), ttl)
+	if err != nil {
+		ts.Log.Error().Err(err).Msgf("error acquiring the lock of task %s", task.ID)
+		return false
+	}
+	return ok
+}
+
+// acquireRunning returns whether the task is not running on any instance and
+// holds its running lock until released, scheduled and manual runs of a task
+// do not overlap
+func (ts *TaskScheduler) acquireRunning(task model.Task) (bool, error) {
+	return ts.Volatile.SetNX(runningLockKey(task), instanceID(), lockTTL)
+}
+
+// releaseRunning releases the running lock of a task by expiring it
+func (ts *TaskScheduler) releaseRunning(task model.Task) {
+	if err := ts.Volatile.Expire(runningLockKey(task), 0); err != nil {
+		ts.Log.Error().Err(err).Msgf("error releasing the running lock of task %s", task.ID)
+	}
+}
+
+// runningLockKey returns the cache key held while a task runs
+func runningLockKey(task model.Task) string {
+	return "sb-task-running:" + task.ID
+}
+
+// instanceID returns the hostname of this instance, the value of the locks
+// it holds
+func instanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return hostname
+}
@@ -0,0 +1,75 @@
+package function
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/database/memory"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestRunLockKey(t *testing.T) {
+	task := model.Task{ID: "task-1", Interval: "*/5 * * * *"}
+	tick := time.Date(2023, 5, 10, 14, 10, 0, 0, time.UTC)
+
+	first, _, err := runLockKey(task, tick.Add(3*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, _, err := runLockKey(task, tick.Add(2*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	} else if first != second {
+		t.Errorf("expected the same key for instances firing the same run got %s and %s", first, second)
+	}
+
+	next, _, err := runLockKey(task, tick.Add(5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	} else if next == first {
+		t.Errorf("expected a different key for the next run got %s", next)
+	}
+}
+
+func TestRunLockKeyOneOff(t *testing.T) {
+	task := model.Task{ID: "task-2", RunAt: time.Now()}
+
+	first, ttl, err := runLockKey(task, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, _, err := runLockKey(task, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	} else if first != second {
+		t.Errorf("expected a one-off task to have a single key got %s and %s", first, second)
+	} else if ttl != oneOffLockTTL {
+		t.Errorf("expected the one-off ttl got %v", ttl)
+	}
+}
+
+func TestRunNowRunning(t *testing.T) {
+	log := logger.Get(config.Current)
+	ts := &TaskScheduler{
+		Volatile:  cache.NewDevCache(log),
+		DataStore: memory.New(func(model.Auth, string, string, string, any) {}),
+		Log:       log,
+	}
+
+	task := model.Task{ID: "task-3", BaseName: "running", Type: model.TaskTypeMessage, Value: "running"}
+	if ok, err := ts.acquireRunning(task); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("expected to acquire the running lock")
+	}
+
+	if _, err := ts.RunNow(task); !errors.Is(err, ErrTaskRunning) {
+		t.Errorf("expected ErrTaskRunning got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+const (
+	// DefaultRetryBackoff is the delay before the first retry of a task
+	// when its policy does not specify one
+	DefaultRetryBackoff = 10 * time.Second
+	// MaxRetryAttempts is the maximum number of attempts of a task's run
+	MaxRetryAttempts = 10
+)
+
+// validateRetry validates a task's retry policy
+func validateRetry(r model.TaskRetry) error {
+	if r.MaxAttempts < 0 || r.MaxAttempts > MaxRetryAttempts {
+		return fmt.Errorf("the retry max attempts must be between 0 and %d", MaxRetryAttempts)
+	} else if r.Backoff < 0 || r.MaxBackoff < 0 {
+		return errors.New("the retry backoff cannot be negative")
+	} else if r.Jitter < 0 || r.Jitter > 1 {
+		return errors.New("the retry jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// RetryDelay returns the wait before the nth retry (starting at 1) of a
+// task's failed run. The delay doubles on each retry up to the policy's
+// MaxBackoff and is randomly reduced by up to Jitter of its value so
+// tasks failing together do not retry together.
+func RetryDelay(r model.TaskRetry, retry int) time.Duration {
+	d := DefaultRetryBackoff
+	if r.Backoff > 0 {
+		d = time.Duration(r.Backoff) * time.Second
+	}
+
+	max := time.Duration(r.MaxBackoff) * time.Second
+	for i := 1; i < retry; i++ {
+		d *= 2
+		if max > 0 && d >= max {
+			break
+		}
+	}
+
+	if max > 0 && d > max {
+		d = max
+	}
+
+	if r.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * r.Jitter * float64(d))
+	}
+	return d
+}
@@ -0,0 +1,47 @@
+package function
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := model.TaskRetry{MaxAttempts: 5, Backoff: 2, MaxBackoff: 10}
+
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, d := range expected {
+		if got := RetryDelay(policy, i+1); got != d {
+			t.Errorf("retry %d: expected %v got %v", i+1, d, got)
+		}
+	}
+
+	if got := RetryDelay(model.TaskRetry{}, 1); got != DefaultRetryBackoff {
+		t.Errorf("expected the default backoff got %v", got)
+	}
+}
+
+func TestRetryDelayJitter(t *testing.T) {
+	policy := model.TaskRetry{Backoff: 10, Jitter: 0.5}
+
+	for i := 0; i < 20; i++ {
+		if d := RetryDelay(policy, 1); d < 5*time.Second || d > 10*time.Second {
+			t.Fatalf("expected a delay between 5s and 10s got %v", d)
+		}
+	}
+}
+
+func TestValidateRetry(t *testing.T) {
+	invalid := []model.TaskRetry{
+		{MaxAttempts: -1},
+		{MaxAttempts: MaxRetryAttempts + 1},
+		{Backoff: -5},
+		{Jitter: 1.5},
+	}
+	for _, r := range invalid {
+		if err := validateRetry(r); err == nil {
+			t.Errorf("expected %v to be invalid", r)
+		}
+	}
+}
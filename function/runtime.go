@@ -1,10 +1,11 @@
 package function
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -12,11 +13,15 @@ import (
 	"github.com/staticbackendhq/core/cache"
 	"github.com/staticbackendhq/core/database"
 	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/eventbridge"
 	"github.com/staticbackendhq/core/logger"
 	"github.com/staticbackendhq/core/model"
 	"github.com/staticbackendhq/core/search"
+	"github.com/staticbackendhq/core/storage"
+	"github.com/staticbackendhq/core/tracing"
 
 	"github.com/dop251/goja"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ExecutionEnvironment struct {
@@ -26,10 +31,42 @@ type ExecutionEnvironment struct {
 	Volatile  cache.Volatilizer
 	Email     email.Mailer
 	Search    *search.Search
+	Events    *eventbridge.Bridge
+	Storage   storage.Storer
 	Data      model.ExecData
+	// Scheduler is set on the primary instance, tasks added from the
+	// other instances are scheduled on the next tasks synchronization
+	Scheduler *TaskScheduler
+	// OnComplete is called with the function.run event after each
+	// execution when set
+	OnComplete func(eventbridge.Event)
+	// BeforeRun is called with the database name before each execution
+	// when set, the function does not run when it returns an error, i.e.
+	// the database is suspended or over its quota
+	BeforeRun func(dbName string) error
+	// Flag returns whether a feature flag is enabled for the tenant of a
+	// database, it backs the flag(name) binding
+	Flag func(dbName, name string) (bool, error)
+	// Secret returns the latest value of a secret of the database's vault,
+	// it backs the secret(name) binding
+	Secret func(dbName, name string) (string, error)
+	// RequestID correlates the run output and logs with the request
+	// invoking the function, empty for the scheduled and event runs
+	RequestID string
+	// Settings returns the function settings of a database, their limits
+	// lower the instance's MaxDuration, MaxOutput and MaxDBCalls and their
+	// hosts restrict fetch()
+	Settings func(dbName string) (model.FunctionSettings, error)
 
 	CurrentRun model.ExecHistory
 	Log        *logger.Logger
+
+	settings   model.FunctionSettings
+	runLimits  runLimits
+	dbCalls    int
+	outputSize int
+	// ctx is done once the run is killed, it cancels the fetch() requests
+	ctx context.Context
 }
 
 type Result struct {
@@ -37,7 +74,58 @@ type Result struct {
 	Content interface{} `json:"content"`
 }
 
+// Execute runs the function's handle with data, a function called over
+// HTTP is traced as part of the request
 func (env *ExecutionEnvironment) Execute(data interface{}) error {
+	return env.run(data, nil)
+}
+
+// ExecuteWeb runs the function's handle with the HTTP request and writes its
+// response. The last argument of handle is res, its status(), setHeader(),
+// json() and send() methods build the response and return res to chain
+// them. The value returned by handle is the body unless res.json() or
+// res.send() was called: a string is sent as text, an ArrayBuffer as bytes
+// and the other values as JSON. Nothing is written when it returns an
+// error.
+func (env *ExecutionEnvironment) ExecuteWeb(w http.ResponseWriter, r *http.Request) error {
+	res := newWebResponse()
+	if err := env.run(r, res); err != nil {
+		return err
+	}
+
+	res.write(w)
+	return nil
+}
+
+// run executes the function, res is set for the functions called over HTTP
+func (env *ExecutionEnvironment) run(data interface{}, res *webResponse) (err error) {
+	ctx := context.Background()
+	if r, ok := data.(*http.Request); ok {
+		ctx = r.Context()
+	}
+
+	_, span := tracing.Start(ctx, "function.Execute",
+		attribute.String("function.name", env.Data.FunctionName),
+		attribute.String("function.trigger", env.Data.TriggerTopic),
+		attribute.String("db.name", env.BaseName),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if env.BeforeRun != nil {
+		if err := env.BeforeRun(env.BaseName); err != nil {
+			return err
+		}
+	}
+
+	running.Add(1)
+	defer running.Done()
+
+	return env.execute(ctx, data, res)
+}
+
+// execute runs the function until it completes or is killed once its
+// timeout is exceeded or ctx is done
+func (env *ExecutionEnvironment) execute(ctx context.Context, data interface{}, res *webResponse) error {
 	vm := goja.New()
 	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
 
@@ -56,8 +144,42 @@ func (env *ExecutionEnvironment) Execute(data interface{}) error {
 	if err := env.addSendMail(vm); err != nil {
 		return err
 	}
+	if err := env.addStorageFunctions(vm); err != nil {
+		return err
+	}
+	if err := env.addTaskFunctions(vm); err != nil {
+		return err
+	}
+
+	env.runLimits = env.limits()
+
+	var cancel context.CancelFunc
+	if timeout := env.runLimits.timeout; timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	defer watch(ctx, vm)()
+	env.ctx = ctx
+
+	env.CurrentRun = model.ExecHistory{
+		Version: env.Data.Version,
+		Started: time.Now(),
+		Output:  make([]string, 0),
+	}
+
+	started := "Function started"
+	if len(env.RequestID) > 0 {
+		started += " (request " + env.RequestID + ")"
+	}
+	env.CurrentRun.Output = append(env.CurrentRun.Output, started)
 
+	// the killed runs are recorded even when they did not reach handle
 	if _, err := vm.RunString(env.Data.Code); err != nil {
+		if err = killedError(err, env.runLimits.timeout); errors.Is(err, ErrKilled) {
+			env.finish(err)
+		}
 		return err
 	}
 
@@ -71,23 +193,40 @@ func (env *ExecutionEnvironment) Execute(data interface{}) error {
 		return fmt.Errorf("error preparing argument: %v", err)
 	}
 
-	env.CurrentRun = model.ExecHistory{
-		Version: env.Data.Version,
-		Started: time.Now(),
-		Output:  make([]string, 0),
+	if res != nil {
+		obj, err := res.bind(vm)
+		if err != nil {
+			return err
+		}
+		args = append(args, obj)
 	}
 
-	env.CurrentRun.Output = append(env.CurrentRun.Output, "Function started")
-
-	_, err = handler(goja.Undefined(), args...)
-	go env.complete(err)
+	v, err := handler(goja.Undefined(), args...)
 	if err != nil {
+		err = killedError(err, env.runLimits.timeout)
+	} else if res != nil {
+		err = res.setResult(v)
+	}
+
+	env.finish(err)
+	if errors.Is(err, ErrKilled) {
+		return err
+	} else if err != nil {
 		return fmt.Errorf("error executing your function: %v", err)
 	}
 
 	return nil
 }
 
+// finish saves the run's history in the background, tracked until saved
+func (env *ExecutionEnvironment) finish(err error) {
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		env.complete(err)
+	}()
+}
+
 func (env *ExecutionEnvironment) prepareArguments(vm *goja.Runtime, data interface{}) ([]goja.Value, error) {
 	var args []goja.Value
 
@@ -151,76 +290,49 @@ func (env *ExecutionEnvironment) addHelpers(vm *goja.Runtime) error {
 		for _, v := range call.Arguments {
 			params = append(params, v.Export())
 		}
-		env.CurrentRun.Output = append(env.CurrentRun.Output, fmt.Sprint(params...))
+		env.output(fmt.Sprint(params...))
 		return goja.Undefined()
 	})
 	if err != nil {
 		return err
 	}
-	err = vm.Set("fetch", func(call goja.FunctionCall) goja.Value {
-		url := ""
-		fetchOptions := NewJSFetcthOptionArg()
-		if len(call.Arguments) == 0 {
-			return goja.Undefined()
-		} else if len(call.Arguments) == 1 {
-			url = call.Argument(0).Export().(string)
-		} else {
-			url = call.Argument(0).Export().(string)
-			if err := vm.ExportTo(call.Argument(1), &fetchOptions); err != nil {
-				return vm.ToValue(Result{Content: "the second argument should be an object"})
-			}
+	if err := vm.Set("fetch", env.fetch(vm)); err != nil {
+		return err
+	}
+	// flag returns a plain boolean so it can be used as a condition, the
+	// flags which cannot be checked are disabled
+	err = vm.Set("flag", func(call goja.FunctionCall) goja.Value {
+		var name string
+		if len(call.Arguments) != 1 || vm.ExportTo(call.Argument(0), &name) != nil {
+			return vm.ToValue(false)
+		} else if env.Flag == nil {
+			return vm.ToValue(false)
 		}
-		if len(url) == 0 {
-			return vm.ToValue(Result{Content: "the url should not be blank"})
-		}
-
-		responseChan := make(chan interface{})
-		go func() {
-			client := http.Client{Timeout: time.Duration(30) * time.Second}
-			var request *http.Request
-			var err error
-			bodyReader := strings.NewReader(fetchOptions.Body)
-			switch fetchOptions.Method {
-			case "GET":
-				request, err = http.NewRequest(http.MethodGet, url, nil)
-			case "POST":
-				request, err = http.NewRequest(http.MethodPost, url, bodyReader)
-			case "PUT":
-				request, err = http.NewRequest(http.MethodPut, url, bodyReader)
-			case "DELETE":
-				request, err = http.NewRequest(http.MethodDelete, url, bodyReader)
-			case "PATCH":
-				request, err = http.NewRequest(http.MethodPatch, url, bodyReader)
-			}
-			if err != nil {
-				responseChan <- err
-			}
-			for headerKey, headerValue := range fetchOptions.Headers {
-				if len(headerKey) > 0 && len(headerValue) > 0 {
-					request.Header.Set(headerKey, headerValue)
-				}
-			}
-			res, err := client.Do(request)
-			if err != nil {
-				responseChan <- err
-			}
-			responseChan <- res
-		}()
 
-		output := <-responseChan
+		enabled, err := env.Flag(env.BaseName, name)
+		if err != nil {
+			env.Log.Warn().Err(err).Msgf("cannot check the flag %s of %s", name, env.BaseName)
+			return vm.ToValue(false)
+		}
+		return vm.ToValue(enabled)
+	})
+	if err != nil {
+		return err
+	}
 
-		if err, ok := output.(error); ok {
-			return vm.ToValue(Result{OK: false, Content: fmt.Sprintf("error calling fetch(): %s", err.Error())})
-		} else if response, ok := output.(*http.Response); ok {
-			bodyBytes, err := io.ReadAll(response.Body)
-			if err != nil {
-				return vm.ToValue(Result{OK: false, Content: fmt.Sprintf("error calling fetch(): %s", err.Error())})
-			}
-			response.Body.Close()
+	err = vm.Set("secret", func(call goja.FunctionCall) goja.Value {
+		var name string
+		if len(call.Arguments) != 1 || vm.ExportTo(call.Argument(0), &name) != nil {
+			return vm.ToValue(Result{Content: "argument missmatch: you need 1 argument for secret(name)"})
+		} else if env.Secret == nil {
+			return vm.ToValue(Result{Content: "the vault is not available"})
+		}
 
-			return vm.ToValue(Result{OK: true, Content: HTTPResponse{Status: response.StatusCode, Body: string(bodyBytes)}})
+		value, err := env.Secret(env.BaseName, name)
+		if err != nil {
+			return vm.ToValue(Result{Content: err.Error()})
 		}
-		return goja.Undefined()
+		return vm.ToValue(Result{OK: true, Content: value})
 	})
 	if err != nil {
 		return err
@@ -230,6 +342,8 @@ func (env *ExecutionEnvironment) addHelpers(vm *goja.Runtime) error {
 
 func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 	err := vm.Set("create", func(call goja.FunctionCall) goja.Value {
+		env.dbCall(vm)
+
 		if len(call.Arguments) != 2 {
 			return vm.ToValue(Result{Content: "argument missmatch: you need 2 arguments for create(col, doc"})
 		}
@@ -242,6 +356,10 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 			return vm.ToValue(Result{Content: "the second argument should be an object"})
 		}
 
+		if !env.Auth.CanWrite(col) {
+			return vm.ToValue(Result{Content: "the token scope does not allow writing to " + col})
+		}
+
 		doc, err := env.DataStore.CreateDocument(env.Auth, env.BaseName, col, doc)
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error calling create(): %s", err.Error())})
@@ -257,6 +375,8 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 	}
 
 	err = vm.Set("list", func(call goja.FunctionCall) goja.Value {
+		env.dbCall(vm)
+
 		if len(call.Arguments) < 1 {
 			return vm.ToValue(Result{Content: "argument missmatch: your need at least 1 argument for list(col, [params])"})
 		}
@@ -276,6 +396,10 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 			}
 		}
 
+		if !env.Auth.CanRead(col) {
+			return vm.ToValue(Result{Content: "the token scope does not allow reading " + col})
+		}
+
 		result, err := env.DataStore.ListDocuments(env.Auth, env.BaseName, col, params)
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error executing list: %v", err)})
@@ -294,6 +418,8 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 	}
 
 	err = vm.Set("getById", func(call goja.FunctionCall) goja.Value {
+		env.dbCall(vm)
+
 		if len(call.Arguments) != 2 {
 			return vm.ToValue(Result{Content: "argument missmatch: you need 2 arguments for get(col, id)"})
 		}
@@ -305,6 +431,10 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 			return vm.ToValue(Result{Content: "the second argument should be a string"})
 		}
 
+		if !env.Auth.CanRead(col) {
+			return vm.ToValue(Result{Content: "the token scope does not allow reading " + col})
+		}
+
 		doc, err := env.DataStore.GetDocumentByID(env.Auth, env.BaseName, col, id)
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error calling get(): %s", err.Error())})
@@ -321,6 +451,8 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 	}
 
 	err = vm.Set("query", func(call goja.FunctionCall) goja.Value {
+		env.dbCall(vm)
+
 		if len(call.Arguments) < 2 {
 			return vm.ToValue(Result{Content: "argument missmatch: you need at least 2 arguments for query(col, filter, [params])"})
 		}
@@ -333,6 +465,10 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 			return vm.ToValue(Result{Content: "the second argument should be a query filter: [['field', '==', 'value'], ...]"})
 		}
 
+		if !env.Auth.CanRead(col) {
+			return vm.ToValue(Result{Content: "the token scope does not allow reading " + col})
+		}
+
 		filter, err := env.DataStore.ParseQuery(clauses)
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error parsing query filter: %v", err)})
@@ -372,6 +508,8 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 	}
 
 	err = vm.Set("update", func(call goja.FunctionCall) goja.Value {
+		env.dbCall(vm)
+
 		if len(call.Arguments) != 3 {
 			return vm.ToValue(Result{Content: "argument missmatch: you need 3 arguments for update(col, id, doc)"})
 		}
@@ -389,6 +527,10 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error executing update: %v", err)})
 		}
 
+		if !env.Auth.CanWrite(col) {
+			return vm.ToValue(Result{Content: "the token scope does not allow writing to " + col})
+		}
+
 		updated, err := env.DataStore.UpdateDocument(env.Auth, env.BaseName, col, id, doc)
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error executing update: %v", err)})
@@ -405,6 +547,8 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 	}
 
 	err = vm.Set("del", func(call goja.FunctionCall) goja.Value {
+		env.dbCall(vm)
+
 		if len(call.Arguments) != 2 {
 			return vm.ToValue(Result{Content: "argument missmatch: you need 3 arguments for del(col, id)"})
 		}
@@ -417,6 +561,10 @@ func (env *ExecutionEnvironment) addDatabaseFunctions(vm *goja.Runtime) error {
 			return vm.ToValue(Result{Content: "the second argument should be a string"})
 		}
 
+		if !env.Auth.CanWrite(col) {
+			return vm.ToValue(Result{Content: "the token scope does not allow writing to " + col})
+		}
+
 		deleted, err := env.DataStore.DeleteDocument(env.Auth, env.BaseName, col, id)
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error executing del: %v", err)})
@@ -455,6 +603,38 @@ func (env *ExecutionEnvironment) addSendMail(vm *goja.Runtime) error {
 			Body:     "",
 		}
 
+		if len(sma.Template) > 0 {
+			tmpl, err := env.DataStore.GetEmailTemplate(env.BaseName, sma.Template)
+			if err != nil {
+				return vm.ToValue(Result{Content: fmt.Sprintf("email template %s not found: %v", sma.Template, err)})
+			}
+
+			rendered, err := email.Render(tmpl, sma.Vars)
+			if err != nil {
+				return vm.ToValue(Result{Content: fmt.Sprintf("render email template error: %v", err)})
+			}
+
+			rendered.From, rendered.To = data.From, data.To
+			data = rendered
+		}
+
+		if len(sma.Attachments) > email.MaxAttachments {
+			return vm.ToValue(Result{Content: fmt.Sprintf("at most %d attachments can be sent", email.MaxAttachments)})
+		}
+
+		// the stored files are read when the email is queued
+		for _, a := range sma.Attachments {
+			att := model.EmailAttachment{FileID: a.FileID, Filename: a.Filename, ContentType: a.ContentType}
+			if len(a.FileID) == 0 {
+				b, err := base64.StdEncoding.DecodeString(a.Content)
+				if err != nil {
+					return vm.ToValue(Result{Content: fmt.Sprintf("attachment %s content should be base64: %v", a.Filename, err)})
+				}
+				att.Content = b
+			}
+			data.Attachments = append(data.Attachments, att)
+		}
+
 		err := env.Email.Send(data)
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("send mail error: %v", err)})
@@ -469,6 +649,62 @@ func (env *ExecutionEnvironment) addSendMail(vm *goja.Runtime) error {
 	return nil
 }
 
+func (env *ExecutionEnvironment) addTaskFunctions(vm *goja.Runtime) error {
+	err := vm.Set("scheduleTask", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) < 4 {
+			return vm.ToValue(Result{Content: "argument missmatch: you need at least 4 arguments for scheduleTask(name, type, value, time, [meta])"})
+		}
+
+		var name, typ, value string
+		if err := vm.ExportTo(call.Argument(0), &name); err != nil {
+			return vm.ToValue(Result{Content: "the first argument should be a string"})
+		} else if err := vm.ExportTo(call.Argument(1), &typ); err != nil {
+			return vm.ToValue(Result{Content: "the second argument should be a string"})
+		} else if err := vm.ExportTo(call.Argument(2), &value); err != nil {
+			return vm.ToValue(Result{Content: "the third argument should be a string"})
+		}
+
+		at, err := exportTime(call.Argument(3).Export())
+		if err != nil {
+			return vm.ToValue(Result{Content: err.Error()})
+		}
+
+		task := model.Task{
+			Name:     name,
+			Type:     typ,
+			Value:    value,
+			RunAt:    at,
+			Enabled:  true,
+			BaseName: env.BaseName,
+		}
+
+		if meta := call.Argument(4); !goja.IsUndefined(meta) && !goja.IsNull(meta) {
+			b, err := json.Marshal(meta.Export())
+			if err != nil {
+				return vm.ToValue(Result{Content: fmt.Sprintf("error converting your meta data: %v", err)})
+			}
+			task.Meta = string(b)
+		}
+
+		if err := ValidateTask(task); err != nil {
+			return vm.ToValue(Result{Content: err.Error()})
+		}
+
+		id, err := env.DataStore.AddTask(env.BaseName, task)
+		if err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error scheduling your task: %v", err)})
+		}
+
+		task.ID = id
+		if env.Scheduler != nil {
+			env.Scheduler.AddOnTheFly(task)
+		}
+
+		return vm.ToValue(Result{OK: true, Content: id})
+	})
+	return err
+}
+
 func (*ExecutionEnvironment) clean(doc map[string]interface{}) error {
 	//TODONOW: not sure what was the exact used for this clean-up
 	/*
@@ -503,6 +739,56 @@ func (env *ExecutionEnvironment) addVolatileFunctions(vm *goja.Runtime) error {
 			return vm.ToValue(Result{Content: "the third argument should be a string"})
 		}
 
+		msg := model.Command{
+			SID:     env.Data.ID,
+			Type:    typ,
+			Channel: channel,
+			Token:   env.Auth.ReconstructToken(),
+			Auth:    env.Auth,
+			Base:    env.BaseName,
+		}
+
+		// an ArrayBuffer is sent as a binary payload
+		if ab, ok := call.Argument(2).Export().(goja.ArrayBuffer); ok {
+			msg.SetBinary(ab.Bytes())
+			if err := msg.ValidatePayload(); err != nil {
+				return vm.ToValue(Result{Content: err.Error()})
+			}
+		} else {
+			b, err := json.Marshal(call.Argument(2).Export())
+			if err != nil {
+				return vm.ToValue(Result{Content: fmt.Sprintf("error converting your data: %v", err)})
+			}
+			msg.Data = string(b)
+		}
+
+		if err := env.Volatile.Publish(msg); err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error publishing your message: %v", err)})
+		}
+
+		return vm.ToValue(Result{OK: true})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = vm.Set("publishAt", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) != 4 {
+			return vm.ToValue(Result{Content: "argument missmatch: you need 4 arguments for publishAt(channel, type, data, time)"})
+		}
+
+		var typ, channel string
+		if err := vm.ExportTo(call.Argument(0), &channel); err != nil {
+			return vm.ToValue(Result{Content: "the first argument should be a string"})
+		} else if err := vm.ExportTo(call.Argument(1), &typ); err != nil {
+			return vm.ToValue(Result{Content: "the second argument should be a string"})
+		}
+
+		at, err := exportTime(call.Argument(3).Export())
+		if err != nil {
+			return vm.ToValue(Result{Content: err.Error()})
+		}
+
 		b, err := json.Marshal(call.Argument(2).Export())
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error converting your data: %v", err)})
@@ -518,6 +804,43 @@ func (env *ExecutionEnvironment) addVolatileFunctions(vm *goja.Runtime) error {
 			Base:    env.BaseName,
 		}
 
+		if err := env.Volatile.PublishAt(msg, at); err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error scheduling your message: %v", err)})
+		}
+
+		return vm.ToValue(Result{OK: true})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = vm.Set("sendToUser", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) != 3 {
+			return vm.ToValue(Result{Content: "argument missmatch: you need 3 arguments for sendToUser(accountId, type, data)"})
+		}
+
+		var accountID, typ string
+		if err := vm.ExportTo(call.Argument(0), &accountID); err != nil {
+			return vm.ToValue(Result{Content: "the first argument should be a string"})
+		} else if err := vm.ExportTo(call.Argument(1), &typ); err != nil {
+			return vm.ToValue(Result{Content: "the second argument should be a string"})
+		}
+
+		b, err := json.Marshal(call.Argument(2).Export())
+		if err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error converting your data: %v", err)})
+		}
+
+		msg := model.Command{
+			SID:     env.Data.ID,
+			Type:    typ,
+			Data:    string(b),
+			Channel: model.UserChannel(accountID),
+			Token:   env.Auth.ReconstructToken(),
+			Auth:    env.Auth,
+			Base:    env.BaseName,
+		}
+
 		if err := env.Volatile.Publish(msg); err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error publishing your message: %v", err)})
 		}
@@ -528,6 +851,60 @@ func (env *ExecutionEnvironment) addVolatileFunctions(vm *goja.Runtime) error {
 		return err
 	}
 
+	err = vm.Set("broadcast", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) != 2 {
+			return vm.ToValue(Result{Content: "argument missmatch: you need 2 arguments for broadcast(type, data)"})
+		}
+
+		var typ string
+		if err := vm.ExportTo(call.Argument(0), &typ); err != nil {
+			return vm.ToValue(Result{Content: "the first argument should be a string"})
+		}
+
+		b, err := json.Marshal(call.Argument(1).Export())
+		if err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error converting your data: %v", err)})
+		}
+
+		msg := model.Command{
+			SID:     model.SystemID,
+			Type:    typ,
+			Data:    string(b),
+			Channel: model.BroadcastChannel(env.BaseName),
+			Base:    env.BaseName,
+		}
+
+		if err := env.Volatile.Publish(msg); err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error broadcasting your message: %v", err)})
+		}
+
+		return vm.ToValue(Result{OK: true})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = vm.Set("presence", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) != 1 {
+			return vm.ToValue(Result{Content: "argument missmatch: you need 1 argument for presence(channel)"})
+		}
+
+		var channel string
+		if err := vm.ExportTo(call.Argument(0), &channel); err != nil {
+			return vm.ToValue(Result{Content: "the first argument should be a string"})
+		}
+
+		members, err := cache.Presence(env.Volatile, env.BaseName, channel)
+		if err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error getting channel presence: %v", err)})
+		}
+
+		return vm.ToValue(Result{OK: true, Content: members})
+	})
+	if err != nil {
+		return err
+	}
+
 	err = vm.Set("cacheGet", func(call goja.FunctionCall) goja.Value {
 		if len(call.Arguments) != 1 {
 			return vm.ToValue(Result{Content: "argument missmatch: you need 1 argument for cacheGet(key)"})
@@ -631,6 +1008,14 @@ func (env *ExecutionEnvironment) addSearch(vm *goja.Runtime) error {
 			return vm.ToValue(Result{Content: "the second argument should be a string"})
 		}
 
+		if !env.Auth.CanRead(col) {
+			return vm.ToValue(Result{Content: "the token scope does not allow reading " + col})
+		}
+
+		if env.Search == nil {
+			return vm.ToValue(Result{Content: "the full-text search is disabled"})
+		}
+
 		results, err := env.Search.Search(env.BaseName, col, keywords)
 		if err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error while executing search(): %v", err)})
@@ -663,10 +1048,14 @@ func (env *ExecutionEnvironment) addSearch(vm *goja.Runtime) error {
 			return vm.ToValue(Result{Content: "the first argument should be a string"})
 		} else if err := vm.ExportTo(call.Argument(1), &id); err != nil {
 			return vm.ToValue(Result{Content: "the second argument should be a string"})
-		} else if err := vm.ExportTo(call.Argument(1), &text); err != nil {
+		} else if err := vm.ExportTo(call.Argument(2), &text); err != nil {
 			return vm.ToValue(Result{Content: "the third argument should be a string"})
 		}
 
+		if env.Search == nil {
+			return vm.ToValue(Result{Content: "the full-text search is disabled"})
+		}
+
 		if err := env.Search.Index(env.BaseName, col, id, text); err != nil {
 			return vm.ToValue(Result{Content: fmt.Sprintf("error while trying to index the document: %v", err)})
 		}
@@ -679,6 +1068,103 @@ func (env *ExecutionEnvironment) addSearch(vm *goja.Runtime) error {
 	return nil
 }
 
+func (env *ExecutionEnvironment) addStorageFunctions(vm *goja.Runtime) error {
+	err := vm.Set("listFiles", func(call goja.FunctionCall) goja.Value {
+		var filter struct {
+			AccountID string `json:"accountId"`
+			Name      string `json:"name"`
+			MimeType  string `json:"mimeType"`
+			Tag       string `json:"tag"`
+			// the linked document or user
+			Collection string `json:"collection"`
+			DocumentID string `json:"documentId"`
+			UserID     string `json:"userId"`
+			Limit      int64  `json:"limit"`
+			Offset     int64  `json:"offset"`
+		}
+		if len(call.Arguments) >= 1 {
+			v := call.Argument(0)
+			if !goja.IsNull(v) && !goja.IsUndefined(v) {
+				if err := vm.ExportTo(v, &filter); err != nil {
+					return vm.ToValue(Result{Content: "the first argument should be an object"})
+				}
+			}
+		}
+
+		files, err := env.DataStore.ListFiles(env.BaseName, model.FileFilter(filter))
+		if err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error executing listFiles: %v", err)})
+		}
+
+		return vm.ToValue(Result{OK: true, Content: files})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = vm.Set("getFile", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) != 1 {
+			return vm.ToValue(Result{Content: "argument missmatch: you need 1 argument for getFile(id)"})
+		}
+
+		var id string
+		if err := vm.ExportTo(call.Argument(0), &id); err != nil {
+			return vm.ToValue(Result{Content: "the first argument should be a string"})
+		}
+
+		file, err := env.DataStore.GetFileByID(env.BaseName, id)
+		if err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error calling getFile(): %v", err)})
+		}
+
+		return vm.ToValue(Result{OK: true, Content: file})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = vm.Set("delFile", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) != 1 {
+			return vm.ToValue(Result{Content: "argument missmatch: you need 1 argument for delFile(id)"})
+		}
+
+		var id string
+		if err := vm.ExportTo(call.Argument(0), &id); err != nil {
+			return vm.ToValue(Result{Content: "the first argument should be a string"})
+		}
+
+		if env.Auth.Scope != nil && env.Auth.Scope.ReadOnly {
+			return vm.ToValue(Result{Content: "read-only tokens cannot delete files"})
+		}
+
+		file, err := env.DataStore.GetFileByID(env.BaseName, id)
+		if err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error calling delFile(): %v", err)})
+		}
+
+		keys := []string{file.Key}
+		for _, v := range file.Variants {
+			keys = append(keys, v.Key)
+		}
+
+		for _, key := range keys {
+			if err := env.Storage.Delete(key); err != nil {
+				return vm.ToValue(Result{Content: fmt.Sprintf("error deleting file content: %v", err)})
+			}
+		}
+
+		if err := env.DataStore.DeleteFile(env.BaseName, id); err != nil {
+			return vm.ToValue(Result{Content: fmt.Sprintf("error calling delFile(): %v", err)})
+		}
+
+		return vm.ToValue(Result{OK: true})
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (env *ExecutionEnvironment) complete(err error) {
 	env.CurrentRun.Completed = time.Now()
 	env.CurrentRun.Success = err == nil
@@ -692,6 +1178,49 @@ func (env *ExecutionEnvironment) complete(err error) {
 
 	//TODO: this needs to be regrouped and ran un batch
 	if err := env.DataStore.RanFunction(env.BaseName, env.Data.ID, env.CurrentRun); err != nil {
-		env.Log.Error().Err(err).Msg("error logging function complete")
+		env.Log.Error().Err(err).Str("requestId", env.RequestID).Msg("error logging function complete")
+	}
+
+	evt := eventbridge.Event{
+		Type: eventbridge.EventFunctionRun,
+		Base: env.BaseName,
+		Data: map[string]interface{}{
+			"functionId":   env.Data.ID,
+			"functionName": env.Data.FunctionName,
+			"trigger":      env.Data.TriggerTopic,
+			"run":          env.CurrentRun,
+		},
+	}
+
+	env.Events.Publish(evt)
+	env.publishRun(evt)
+
+	if env.OnComplete != nil {
+		env.OnComplete(evt)
+	}
+}
+
+// publishRun streams the run to the root user tailing the function logs
+func (env *ExecutionEnvironment) publishRun(evt eventbridge.Event) {
+	if env.Volatile == nil {
+		return
+	}
+
+	b, err := json.Marshal(evt.Data)
+	if err != nil {
+		env.Log.Error().Err(err).Msg("error encoding the function run")
+		return
+	}
+
+	msg := model.Command{
+		Type:    model.MsgTypeFunctionLog,
+		Channel: model.FunctionLogChannel(env.BaseName),
+		Data:    string(b),
+		Base:    env.BaseName,
+		// the runs do not trigger the functions
+		IsSystemEvent: true,
+	}
+	if err := env.Volatile.Publish(msg); err != nil {
+		env.Log.Error().Err(err).Msg("error publishing the function run")
 	}
 }
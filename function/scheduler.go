@@ -2,19 +2,24 @@ package function
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
 	"github.com/staticbackendhq/core/database"
 	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/eventbridge"
 	"github.com/staticbackendhq/core/logger"
 	"github.com/staticbackendhq/core/model"
 	"github.com/staticbackendhq/core/search"
+	"github.com/staticbackendhq/core/storage"
 
 	"github.com/go-co-op/gocron"
 )
@@ -24,9 +29,33 @@ type TaskScheduler struct {
 	DataStore database.Persister
 	Search    *search.Search
 	Email     email.Mailer
+	// MailerFor returns the mailer of a database, Email is used when nil
+	MailerFor func(dbName string) email.Mailer
+	Events    *eventbridge.Bridge
+	Storage   storage.Storer
 	Log       *logger.Logger
+	// OnComplete and BeforeRun are passed to the execution environment of
+	// the tasks
+	OnComplete func(eventbridge.Event)
+	BeforeRun  func(dbName string) error
+	// BeforeTask is called with the database name before each task run
+	// when set, the run is skipped when it returns an error
+	BeforeTask func(dbName string) error
+	// Backup runs the backup tasks and returns the output of the run
+	Backup func(task model.Task) (string, error)
+	// Flag is passed to the execution environment of the tasks
+	Flag func(dbName, name string) (bool, error)
+	// Secret is passed to the execution environment of the tasks
+	Secret func(dbName, name string) (string, error)
+	// Settings is passed to the execution environment of the tasks
+	Settings func(dbName string) (model.FunctionSettings, error)
 
 	Scheduler *gocron.Scheduler
+
+	// scheduled holds the definition of the tasks scheduled by this
+	// instance by ID, one-off tasks remain once started so they do not run
+	// again
+	scheduled sync.Map
 }
 
 func (ts *TaskScheduler) Start() {
@@ -39,37 +68,298 @@ func (ts *TaskScheduler) Start() {
 	ts.Scheduler.TagsUnique()
 
 	for _, task := range tasks {
-		_, err := ts.Scheduler.Cron(task.Interval).Tag(task.ID).Do(ts.run, task)
-		if err != nil {
-			ts.Log.Error().Err(err).Msgf("error scheduling this task: %s", task.ID)
+		ts.AddOnTheFly(task)
+	}
+
+	if config.Current.AuditRetentionDays > 0 {
+		if _, err := ts.Scheduler.Every(1).Day().Do(ts.purgeAuditEvents); err != nil {
+			ts.Log.Error().Err(err).Msg("error scheduling the audit events purge")
+		}
+	}
+
+	if config.Current.EventRetentionDays > 0 {
+		if _, err := ts.Scheduler.Every(1).Day().Do(ts.purgeAppEvents); err != nil {
+			ts.Log.Error().Err(err).Msg("error scheduling the app events purge")
 		}
 	}
 
+	if _, err := ts.Scheduler.Every(1).Second().Do(ts.publishDelayed); err != nil {
+		ts.Log.Error().Err(err).Msg("error scheduling the delayed messages dispatcher")
+	}
+
+	if _, err := ts.Scheduler.Every(1).Minute().StartAt(time.Now().Add(time.Minute)).Do(ts.syncTasks); err != nil {
+		ts.Log.Error().Err(err).Msg("error scheduling the tasks synchronization")
+	}
+
 	ts.Scheduler.StartBlocking()
 }
 
+// Stop stops scheduling the tasks, the running tasks continue
+func (ts *TaskScheduler) Stop() {
+	if ts.Scheduler != nil {
+		ts.Scheduler.Stop()
+	}
+}
+
 func (ts *TaskScheduler) AddOnTheFly(task model.Task) {
-	_, err := ts.Scheduler.Cron(task.Interval).Tag(task.ID).Do(ts.run, task)
-	if err != nil {
+	if err := ts.schedule(task); err != nil {
 		ts.Log.Error().Err(err).Msgf("error scheduling this task: %s", task.ID)
 	}
 }
 
+// schedule adds an enabled task to the scheduler. A one-off task runs once
+// at its RunAt time, immediately if that time has passed while the server
+// was down, and is not scheduled again once completed. Dependent tasks are
+// not scheduled, they run after their prerequisite.
+func (ts *TaskScheduler) schedule(task model.Task) error {
+	// a runner which is not started only executes manual runs, the primary
+	// instance's scheduler picks the task up
+	if ts.Scheduler == nil {
+		return nil
+	}
+
+	// dependent tasks run after their prerequisite
+	if !task.Enabled || task.IsDependent() || task.Completed() {
+		return nil
+	}
+
+	if _, ok := ts.scheduled.LoadOrStore(task.ID, task); ok {
+		return nil
+	}
+
+	var job *gocron.Scheduler
+	if task.IsOneOff() {
+		job = ts.Scheduler.Every(1).Day()
+		if task.RunAt.After(time.Now()) {
+			job = job.StartAt(task.RunAt)
+		}
+	} else {
+		job = ts.Scheduler.Cron(task.Interval)
+	}
+
+	if _, err := job.Tag(task.ID).Do(ts.run, task); err != nil {
+		ts.scheduled.Delete(task.ID)
+		return err
+	}
+	return nil
+}
+
+// CancelTask removes a task from the scheduler, a one-off task which
+// already started is not scheduled anymore
 func (ts *TaskScheduler) CancelTask(id string) error {
-	return ts.Scheduler.RemoveByTag(id)
+	ts.scheduled.Delete(id)
+
+	// nothing is scheduled by a runner which is not started
+	if ts.Scheduler == nil {
+		return nil
+	}
+
+	err := ts.Scheduler.RemoveByTag(id)
+	if errors.Is(err, gocron.ErrJobNotFoundWithTag) {
+		return nil
+	}
+	return err
+}
+
+// syncTasks schedules the tasks added, updated or resumed from other
+// instances or from the function runtime and removes the deleted and paused
+// ones
+func (ts *TaskScheduler) syncTasks() {
+	tasks, err := ts.DataStore.ListTasks()
+	if err != nil {
+		ts.Log.Error().Err(err).Msg("error loading tasks")
+		return
+	}
+
+	stale := make(map[string]bool)
+	ts.scheduled.Range(func(key, _ any) bool {
+		stale[key.(string)] = true
+		return true
+	})
+
+	for _, task := range tasks {
+		// disabled tasks are removed with the deleted ones
+		if !task.Enabled {
+			continue
+		}
+
+		if v, ok := ts.scheduled.Load(task.ID); ok {
+			if sameDefinition(v.(model.Task), task) {
+				delete(stale, task.ID)
+				continue
+			}
+
+			if err := ts.CancelTask(task.ID); err != nil {
+				ts.Log.Error().Err(err).Msgf("error removing the updated task: %s", task.ID)
+				continue
+			}
+		}
+
+		delete(stale, task.ID)
+		ts.AddOnTheFly(task)
+	}
+
+	for id := range stale {
+		if err := ts.CancelTask(id); err != nil {
+			ts.Log.Error().Err(err).Msgf("error removing the deleted task: %s", id)
+		}
+	}
+}
+
+// sameDefinition returns whether two versions of a task run the same way
+func sameDefinition(a, b model.Task) bool {
+	return a.Name == b.Name &&
+		a.Type == b.Type &&
+		a.Value == b.Value &&
+		a.Meta == b.Meta &&
+		a.Interval == b.Interval &&
+		a.RunAt.Equal(b.RunAt) &&
+		a.Retry == b.Retry &&
+		a.After == b.After
+}
+
+// mailer returns the mailer of a database
+func (ts *TaskScheduler) mailer(dbName string) email.Mailer {
+	if ts.MailerFor == nil {
+		return ts.Email
+	}
+	return ts.MailerFor(dbName)
+}
+
+// purgeAuditEvents removes auth audit events older than the retention setting
+// for all databases
+func (ts *TaskScheduler) purgeAuditEvents() {
+	bases, err := ts.DataStore.ListDatabases()
+	if err != nil {
+		ts.Log.Error().Err(err).Msg("error listing databases for audit purge")
+		return
+	}
+
+	before := time.Now().AddDate(0, 0, -1*config.Current.AuditRetentionDays)
+	for _, base := range bases {
+		n, err := ts.DataStore.PurgeAuditEvents(base.Name, before)
+		if err != nil {
+			ts.Log.Error().Err(err).Msgf("error purging audit events for %s", base.Name)
+			continue
+		}
+
+		ts.Log.Info().Msgf("purged %d audit events for %s", n, base.Name)
+	}
+}
+
+// purgeAppEvents removes the app events older than the retention setting
+// for all databases, their daily rollups are kept
+func (ts *TaskScheduler) purgeAppEvents() {
+	bases, err := ts.DataStore.ListDatabases()
+	if err != nil {
+		ts.Log.Error().Err(err).Msg("error listing databases for app events purge")
+		return
+	}
+
+	before := time.Now().AddDate(0, 0, -1*config.Current.EventRetentionDays)
+	for _, base := range bases {
+		n, err := ts.DataStore.PurgeAppEvents(base.Name, before)
+		if err != nil {
+			ts.Log.Error().Err(err).Msgf("error purging app events for %s", base.Name)
+			continue
+		}
+
+		ts.Log.Info().Msgf("purged %d app events for %s", n, base.Name)
+	}
+}
+
+// publishDelayed publishes the messages scheduled via publishAt
+func (ts *TaskScheduler) publishDelayed() {
+	if _, err := ts.Volatile.PublishDue(); err != nil {
+		ts.Log.Error().Err(err).Msg("error publishing delayed messages")
+	}
 }
 
 func (ts *TaskScheduler) run(task model.Task) {
+	// every instance schedules the tasks, only one executes each run
+	if !ts.acquireRun(task) {
+		ts.Log.Info().Msgf("task %s run is executed by another instance", task.ID)
+		return
+	}
+
+	// a one-off task is removed from the scheduler once started
+	if task.IsOneOff() {
+		if err := ts.Scheduler.RemoveByTag(task.ID); err != nil {
+			ts.Log.Warn().Err(err).Msgf("error removing the one-off task %s", task.ID)
+		}
+	}
+
+	if ok, err := ts.acquireRunning(task); err != nil {
+		ts.Log.Error().Err(err).Msgf("error acquiring the running lock of task %s", task.ID)
+		return
+	} else if !ok {
+		ts.Log.Info().Msgf("task %s is already running", task.ID)
+		return
+	}
+	defer ts.releaseRunning(task)
+
+	err := ts.runTask(task, nil)
+	ts.runDependents(task, err, map[string]bool{task.ID: true})
+}
+
+// RunNow executes a task immediately regardless of its schedule and returns
+// the ID of its first run once completed, the retries and the dependent
+// tasks continue in the background. ErrTaskRunning is returned when the
+// task is running on any instance.
+func (ts *TaskScheduler) RunNow(task model.Task) (string, error) {
+	ok, err := ts.acquireRunning(task)
+	if err != nil {
+		return "", err
+	} else if !ok {
+		return "", ErrTaskRunning
+	}
+
+	runID := make(chan string, 1)
+	go func() {
+		defer ts.releaseRunning(task)
+
+		err := ts.runTask(task, runID)
+		ts.runDependents(task, err, map[string]bool{task.ID: true})
+	}()
+
+	id := <-runID
+	if len(id) == 0 {
+		return "", fmt.Errorf("unable to record the run of task %s", task.ID)
+	}
+	return id, nil
+}
+
+// runTask executes a task as the root user of its database, failed runs are
+// retried per the task's retry policy. It returns the outcome of the last
+// attempt. The ID of the first run is sent to runID when not nil, an empty
+// ID when it was not recorded.
+func (ts *TaskScheduler) runTask(task model.Task, runID chan<- string) error {
 	ts.Log.Info().Msgf("executing job:%s typed:%s value:%s", task.Name, task.Type, task.Value)
 
+	firstRun := func(id string) {
+		if runID != nil {
+			runID <- id
+			runID = nil
+		}
+	}
+	defer firstRun("")
+
+	if ts.BeforeTask != nil {
+		if err := ts.BeforeTask(task.BaseName); err != nil {
+			ts.Log.Info().Err(err).Msgf("task %s run skipped", task.ID)
+			return err
+		}
+	}
+
 	// the task must run as the root base user
 	var auth model.Auth
 	if err := ts.Volatile.GetTyped("root:"+task.BaseName, &auth); err != nil {
 		tok, err := ts.DataStore.GetRootForBase(task.BaseName)
 		if err != nil {
 			ts.Log.Error().Err(err).Msgf("error finding root token for base %s", task.BaseName)
-
-			return
+			firstRun(ts.addRun(task, 1, time.Now(), "", err))
+			ts.recordRun(task, time.Now(), err)
+			return err
 		}
 
 		auth = model.Auth{
@@ -82,44 +372,162 @@ func (ts *TaskScheduler) run(task model.Task) {
 
 		if err := ts.Volatile.SetTyped("root:"+task.BaseName, auth); err != nil {
 			ts.Log.Error().Err(err).Msg("error setting auth inside TaskScheduler.run")
-			return
+			return err
+		}
+	}
+
+	started := time.Now()
+	output, err := ts.execute(auth, task)
+	firstRun(ts.addRun(task, 1, started, output, err))
+
+	// failed runs are retried per the task's retry policy
+	for attempt := 1; err != nil && attempt < task.Retry.MaxAttempts; attempt++ {
+		ts.recordAttempt(task, started, attempt, err)
+
+		time.Sleep(RetryDelay(task.Retry, attempt))
+
+		started = time.Now()
+		output, err = ts.execute(auth, task)
+		ts.addRun(task, attempt+1, started, output, err)
+	}
+
+	ts.recordRun(task, started, err)
+	return err
+}
+
+// runDependents runs the enabled tasks declared to run after a task once it
+// succeeded, in turn followed by their own dependents. They are skipped when
+// the task failed. The seen tasks guard against cycles.
+func (ts *TaskScheduler) runDependents(task model.Task, taskErr error, seen map[string]bool) {
+	tasks, err := ts.DataStore.ListTasksByBase(task.BaseName)
+	if err != nil {
+		ts.Log.Error().Err(err).Msgf("error loading the tasks running after %s", task.ID)
+		return
+	}
+
+	for _, dep := range tasks {
+		if dep.After != task.ID || !dep.Enabled || seen[dep.ID] {
+			continue
 		}
+		seen[dep.ID] = true
+
+		var err error
+		if taskErr != nil {
+			err = ts.skip(dep, task)
+		} else {
+			err = ts.runTask(dep, nil)
+		}
+
+		ts.runDependents(dep, err, seen)
 	}
+}
+
+// skip records a dependent task as skipped since its prerequisite failed
+func (ts *TaskScheduler) skip(task, prerequisite model.Task) error {
+	err := fmt.Errorf("skipped, the prerequisite task %s failed", prerequisite.Name)
+	ts.Log.Warn().Msgf("task %s %v", task.ID, err)
 
+	now := time.Now()
+	ts.addRun(task, 1, now, "", err)
+	if err := ts.DataStore.UpdateTaskRun(task.BaseName, task.ID, now, model.TaskStatusSkipped, err.Error()); err != nil {
+		ts.Log.Error().Err(err).Msgf("error saving the skipped run of task %s", task.ID)
+	}
+	return err
+}
+
+// execute runs the task, the output describes what was executed
+func (ts *TaskScheduler) execute(auth model.Auth, task model.Task) (string, error) {
 	switch task.Type {
 	case model.TaskTypeFunction:
-		ts.execFunction(auth, task)
+		return ts.execFunction(auth, task)
 	case model.TaskTypeMessage:
-		ts.sendMessage(auth, task)
+		return ts.sendMessage(auth, task)
 	case model.TaskTypeHTTP:
-		ts.httpRequest(auth, task)
+		return ts.httpRequest(auth, task)
+	case model.TaskTypeBackup:
+		if ts.Backup == nil {
+			return "", errors.New("backups are not available")
+		}
+		return ts.Backup(task)
+	}
+	return "", fmt.Errorf("unknown task type %s", task.Type)
+}
+
+// addRun records an attempt of the task's run in its history and returns its
+// ID, empty when it could not be saved
+func (ts *TaskScheduler) addRun(task model.Task, attempt int, started time.Time, output string, err error) string {
+	run := model.TaskRun{
+		TaskID:    task.ID,
+		Attempt:   attempt,
+		Started:   started,
+		Completed: time.Now(),
+		Success:   err == nil,
+		Output:    output,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	id, err := ts.DataStore.AddTaskRun(task.BaseName, run)
+	if err != nil {
+		ts.Log.Error().Err(err).Msgf("error saving the run of task %s", task.ID)
+	}
+	return id
+}
+
+// recordAttempt saves a failed attempt of a task's run which will be retried
+func (ts *TaskScheduler) recordAttempt(task model.Task, started time.Time, attempt int, err error) {
+	ts.Log.Warn().Err(err).Msgf("task %s attempt %d of %d failed", task.ID, attempt, task.Retry.MaxAttempts)
+
+	lastError := fmt.Sprintf("attempt %d of %d: %v", attempt, task.Retry.MaxAttempts, err)
+	if err := ts.DataStore.UpdateTaskRun(task.BaseName, task.ID, started, model.TaskStatusRetrying, lastError); err != nil {
+		ts.Log.Error().Err(err).Msgf("error saving the attempt of task %s", task.ID)
 	}
 }
 
-func (ts *TaskScheduler) execFunction(auth model.Auth, task model.Task) {
+// recordRun saves the time and outcome of a task's run
+func (ts *TaskScheduler) recordRun(task model.Task, started time.Time, err error) {
+	status, lastError := model.TaskStatusSuccess, ""
+	if err != nil {
+		status, lastError = model.TaskStatusFailed, err.Error()
+		ts.Log.Error().Err(err).Msgf("task %s failed", task.ID)
+	}
+
+	if err := ts.DataStore.UpdateTaskRun(task.BaseName, task.ID, started, status, lastError); err != nil {
+		ts.Log.Error().Err(err).Msgf("error saving the last run of task %s", task.ID)
+	}
+}
+
+func (ts *TaskScheduler) execFunction(auth model.Auth, task model.Task) (string, error) {
 	fn, err := ts.DataStore.GetFunctionForExecution(task.BaseName, task.Value)
 	if err != nil {
-		ts.Log.Error().Err(err).Msgf("cannot find function %s on task %s", task.Value, task.ID)
-		return
+		return "", fmt.Errorf("cannot find function %s: %w", task.Value, err)
 	}
 
 	exe := &ExecutionEnvironment{
-		Auth:      auth,
-		BaseName:  task.BaseName,
-		DataStore: ts.DataStore,
-		Volatile:  ts.Volatile,
-		Search:    ts.Search,
-		Email:     ts.Email,
-		Data:      fn,
-		Log:       ts.Log,
+		Auth:       auth,
+		BaseName:   task.BaseName,
+		DataStore:  ts.DataStore,
+		Volatile:   ts.Volatile,
+		Search:     ts.Search,
+		Email:      ts.mailer(task.BaseName),
+		Events:     ts.Events,
+		Storage:    ts.Storage,
+		Data:       fn,
+		Scheduler:  ts,
+		Log:        ts.Log,
+		OnComplete: ts.OnComplete,
+		BeforeRun:  ts.BeforeRun,
+		Flag:       ts.Flag,
+		Secret:     ts.Secret,
+		Settings:   ts.Settings,
 	}
 
 	var meta model.MetaMessage
 
 	if len(task.Meta) > 0 {
 		if err := json.Unmarshal([]byte(task.Meta), &meta); err != nil {
-			ts.Log.Warn().Msgf("unable to get meta data for type MetaMessage for task: %s", task.ID)
-			return
+			return "", fmt.Errorf("unable to get meta data for type MetaMessage: %w", err)
 		}
 	}
 
@@ -133,19 +541,19 @@ func (ts *TaskScheduler) execFunction(auth model.Auth, task model.Task) {
 	}
 
 	if err := exe.Execute(msg); err != nil {
-		ts.Log.Error().Err(err).Msgf("error executing function %s", task.Value)
+		return "", fmt.Errorf("error executing function %s: %w", task.Value, err)
 	}
+	return fmt.Sprintf("executed function %s", task.Value), nil
 }
 
-func (ts *TaskScheduler) sendMessage(auth model.Auth, task model.Task) {
+func (ts *TaskScheduler) sendMessage(auth model.Auth, task model.Task) (string, error) {
 	token := auth.ReconstructToken()
 
 	var meta model.MetaMessage
 
 	if len(task.Meta) > 0 {
 		if err := json.Unmarshal([]byte(task.Meta), &meta); err != nil {
-			ts.Log.Warn().Msgf("unable to get meta data for type MetaMessage for task: %s", task.ID)
-			return
+			return "", fmt.Errorf("unable to get meta data for type MetaMessage: %w", err)
 		}
 	}
 
@@ -160,11 +568,12 @@ func (ts *TaskScheduler) sendMessage(auth model.Auth, task model.Task) {
 	}
 
 	if err := ts.Volatile.Publish(msg); err != nil {
-		ts.Log.Error().Err(err).Msgf("error publishing message from task: %s", task.ID)
+		return "", fmt.Errorf("error publishing message: %w", err)
 	}
+	return fmt.Sprintf("published %s to %s", task.Value, meta.Channel), nil
 }
 
-func (ts *TaskScheduler) httpRequest(auth model.Auth, task model.Task) {
+func (ts *TaskScheduler) httpRequest(auth model.Auth, task model.Task) (string, error) {
 	token := auth.ReconstructToken()
 
 	var meta model.MetaMessage
@@ -172,13 +581,11 @@ func (ts *TaskScheduler) httpRequest(auth model.Auth, task model.Task) {
 
 	if len(task.Meta) > 0 {
 		if err := json.Unmarshal([]byte(task.Meta), &meta); err != nil {
-			ts.Log.Warn().Msgf("unable to get meta data for type MetaMessage for task: %s", task.ID)
-			return
+			return "", fmt.Errorf("unable to get meta data for type MetaMessage: %w", err)
 		}
 
 		if err := json.Unmarshal([]byte(meta.HTTPHeaders), &headers); err != nil {
-			ts.Log.Err(err).Msg("unable to parse HTTP headers from meta data")
-			return
+			return "", fmt.Errorf("unable to parse HTTP headers from meta data: %w", err)
 		}
 	}
 
@@ -196,8 +603,7 @@ func (ts *TaskScheduler) httpRequest(auth model.Auth, task model.Task) {
 	} else {
 		var v map[string]any
 		if err := json.Unmarshal([]byte(meta.Data), &v); err != nil {
-			ts.Log.Warn().Err(err).Msg("unable to parse meta data")
-			return
+			return "", fmt.Errorf("unable to parse meta data: %w", err)
 		}
 
 		data := url.Values{}
@@ -210,8 +616,7 @@ func (ts *TaskScheduler) httpRequest(auth model.Auth, task model.Task) {
 
 	req, err := http.NewRequest(meta.HTTPMethod, task.Value, strings.NewReader(body))
 	if err != nil {
-		ts.Log.Err(err).Msg("unable to construct the HTTP request")
-		return
+		return "", fmt.Errorf("unable to construct the HTTP request: %w", err)
 	}
 
 	req.Header.Add("Content-Type", meta.ContentType)
@@ -222,15 +627,13 @@ func (ts *TaskScheduler) httpRequest(auth model.Auth, task model.Task) {
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		ts.Log.Err(err).Msg("error executing HTTP request")
-		return
+		return "", fmt.Errorf("error executing HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		ts.Log.Err(err).Msg("unable to read HTTP response body")
-		return
+		return "", fmt.Errorf("unable to read HTTP response body: %w", err)
 	}
 
 	msg := model.Command{
@@ -244,6 +647,12 @@ func (ts *TaskScheduler) httpRequest(auth model.Auth, task model.Task) {
 	}
 
 	if err := ts.Volatile.Publish(msg); err != nil {
-		ts.Log.Error().Err(err).Msgf("error publishing message from task: %s", task.ID)
+		return "", fmt.Errorf("error publishing the HTTP response: %w", err)
+	}
+
+	output := fmt.Sprintf("%s %s returned %d", meta.HTTPMethod, task.Value, resp.StatusCode)
+	if resp.StatusCode > 299 {
+		return output, fmt.Errorf("HTTP request returned status %d", resp.StatusCode)
 	}
+	return output, nil
 }
@@ -0,0 +1,112 @@
+package function
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/database/memory"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestRunDependentsSkipped(t *testing.T) {
+	dbName := "deps"
+	ts := &TaskScheduler{
+		DataStore: memory.New(func(model.Auth, string, string, string, any) {}),
+		Log:       logger.Get(config.Current),
+	}
+
+	prerequisite := model.Task{Name: "export", Type: model.TaskTypeMessage, Value: "export", Interval: "@daily", Enabled: true}
+	id, err := ts.DataStore.AddTask(dbName, prerequisite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prerequisite.ID = id
+	prerequisite.BaseName = dbName
+
+	dependent := model.Task{Name: "report", Type: model.TaskTypeMessage, Value: "report", After: id, Enabled: true}
+	depID, err := ts.DataStore.AddTask(dbName, dependent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained := model.Task{Name: "notify", Type: model.TaskTypeMessage, Value: "notify", After: depID, Enabled: true}
+	chainedID, err := ts.DataStore.AddTask(dbName, chained)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts.runDependents(prerequisite, errors.New("export failed"), map[string]bool{id: true})
+
+	for _, taskID := range []string{depID, chainedID} {
+		task, err := ts.DataStore.GetTaskByID(dbName, taskID)
+		if err != nil {
+			t.Fatal(err)
+		} else if task.LastStatus != model.TaskStatusSkipped {
+			t.Errorf("expected task %s to be skipped got %s", task.Name, task.LastStatus)
+		}
+
+		runs, err := ts.DataStore.ListTaskRuns(dbName, taskID, 0)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(runs) != 1 || runs[0].Success {
+			t.Errorf("expected a failed run for task %s got %v", task.Name, runs)
+		}
+	}
+}
+
+func TestRescheduleTask(t *testing.T) {
+	ts := &TaskScheduler{
+		Scheduler: gocron.NewScheduler(time.UTC),
+		Log:       logger.Get(config.Current),
+	}
+	ts.Scheduler.TagsUnique()
+
+	task := model.Task{ID: "task-id", Name: "job", Type: model.TaskTypeMessage, Value: "job", Interval: "@daily", Enabled: true}
+	if err := ts.schedule(task); err != nil {
+		t.Fatal(err)
+	}
+
+	// an already scheduled task is not added twice
+	if err := ts.schedule(task); err != nil {
+		t.Fatal(err)
+	} else if n := len(ts.Scheduler.Jobs()); n != 1 {
+		t.Fatalf("expected 1 job got %d", n)
+	}
+
+	if err := ts.CancelTask(task.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	task.Interval = ""
+	task.RunAt = time.Now().Add(time.Hour)
+	if err := ts.schedule(task); err != nil {
+		t.Fatal(err)
+	} else if n := len(ts.Scheduler.Jobs()); n != 1 {
+		t.Fatalf("expected 1 job got %d", n)
+	}
+
+	v, ok := ts.scheduled.Load(task.ID)
+	if !ok || !sameDefinition(v.(model.Task), task) {
+		t.Errorf("expected the updated definition to be scheduled got %v", v)
+	}
+
+	// cancelling a task which is not scheduled is not an error
+	if err := ts.CancelTask("unknown"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCancelTaskNotStarted(t *testing.T) {
+	ts := &TaskScheduler{
+		DataStore: memory.New(func(model.Auth, string, string, string, any) {}),
+		Log:       logger.Get(config.Current),
+	}
+
+	if err := ts.CancelTask("unknown"); err != nil {
+		t.Fatal(err)
+	}
+}
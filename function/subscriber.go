@@ -11,10 +11,18 @@ import (
 )
 
 type Subscriber struct {
-	PubSub            cache.Volatilizer
-	GetExecEnv        func(msg model.Command) (*ExecutionEnvironment, error)
-	Log               *logger.Logger
-	IsPrimaryInstance bool
+	PubSub     cache.Volatilizer
+	GetExecEnv func(msg model.Command) (*ExecutionEnvironment, error)
+	Log        *logger.Logger
+	// IsLeader returns true on the instance executing the functions,
+	// otherwise each instance would execute them
+	IsLeader func() bool
+	// Handles returns whether the events of a database are handled by this
+	// instance, all are when it's nil
+	Handles func(dbName string) bool
+	// Notify is called for each published message, used to send push
+	// notifications and channel webhooks
+	Notify func(msg model.Command)
 
 	relax sync.Map
 }
@@ -31,10 +39,14 @@ func (sub *Subscriber) Start() {
 	for {
 		select {
 		case msg := <-receiver:
-			// only handle function execution on the primary instance
+			// only handle function execution on the leader instance
 			// otherwise it would cause duplication work.
-			if sub.IsPrimaryInstance {
+			if sub.IsLeader() && (sub.Handles == nil || sub.Handles(msg.Base)) {
 				go sub.process(msg)
+
+				if sub.Notify != nil {
+					go sub.Notify(msg)
+				}
 			}
 		case <-close:
 			sub.Log.Info().Msg("system event channel closed?!?")
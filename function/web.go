@@ -0,0 +1,144 @@
+package function
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/dop251/goja"
+)
+
+// webResponse is the HTTP response of a function called over HTTP, built
+// with the res argument of handle or from its return value
+type webResponse struct {
+	status int
+	header http.Header
+	body   []byte
+	// contentType is the type of the body when the function did not set
+	// the Content-Type header
+	contentType string
+	// sent is true once res.json() or res.send() set the body
+	sent bool
+	// object is the res argument passed to handle
+	object *goja.Object
+}
+
+func newWebResponse() *webResponse {
+	return &webResponse{status: http.StatusOK, header: make(http.Header)}
+}
+
+// bind returns the res argument with its status(), setHeader(), json() and
+// send() methods, each returning res to chain them
+func (res *webResponse) bind(vm *goja.Runtime) (*goja.Object, error) {
+	obj := vm.NewObject()
+
+	err := obj.Set("status", func(call goja.FunctionCall) goja.Value {
+		code := int(call.Argument(0).ToInteger())
+		if code < 100 || code > 599 {
+			panic(vm.NewTypeError("invalid HTTP status code %v", call.Argument(0)))
+		}
+
+		res.status = code
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.Set("setHeader", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) != 2 {
+			panic(vm.NewTypeError("argument missmatch: you need 2 arguments for setHeader(name, value)"))
+		}
+
+		res.header.Set(call.Argument(0).String(), call.Argument(1).String())
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.Set("json", func(call goja.FunctionCall) goja.Value {
+		b, err := json.Marshal(call.Argument(0).Export())
+		if err != nil {
+			panic(vm.NewTypeError("error calling json(): %v", err))
+		}
+
+		res.setBody(b, "application/json")
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.Set("send", func(call goja.FunctionCall) goja.Value {
+		if err := res.setValue(call.Argument(0)); err != nil {
+			panic(vm.NewTypeError("error calling send(): %v", err))
+		}
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.object = obj
+	return obj, nil
+}
+
+// setResult uses the value returned by handle as the body unless res.json()
+// or res.send() was called
+func (res *webResponse) setResult(v goja.Value) error {
+	if res.sent || v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil
+	} else if res.object != nil && v.SameAs(res.object) {
+		return nil
+	}
+
+	if err := res.setValue(v); err != nil {
+		return fmt.Errorf("error encoding the returned value: %v", err)
+	}
+	return nil
+}
+
+// setValue sets the body to a string as text, an ArrayBuffer as bytes and
+// the other values as JSON
+func (res *webResponse) setValue(v goja.Value) error {
+	switch x := v.Export().(type) {
+	case nil:
+		res.setBody(nil, "")
+	case string:
+		res.setBody([]byte(x), "text/plain; charset=utf-8")
+	case goja.ArrayBuffer:
+		res.setBody(x.Bytes(), "application/octet-stream")
+	default:
+		b, err := json.Marshal(x)
+		if err != nil {
+			return err
+		}
+		res.setBody(b, "application/json")
+	}
+	return nil
+}
+
+func (res *webResponse) setBody(b []byte, contentType string) {
+	res.body = b
+	res.contentType = contentType
+	res.sent = true
+}
+
+// write writes the status, headers and body to the caller
+func (res *webResponse) write(w http.ResponseWriter) {
+	for name, values := range res.header {
+		w.Header()[name] = values
+	}
+	if len(res.contentType) > 0 && len(res.header.Get("Content-Type")) == 0 {
+		w.Header().Set("Content-Type", res.contentType)
+	}
+
+	w.WriteHeader(res.status)
+
+	// those statuses cannot have a body
+	if res.status == http.StatusNoContent || res.status == http.StatusNotModified {
+		return
+	}
+	w.Write(res.body)
+}
@@ -1,6 +1,7 @@
 package staticbackend
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/staticbackendhq/core/backend"
@@ -8,6 +9,7 @@ import (
 	"github.com/staticbackendhq/core/function"
 	"github.com/staticbackendhq/core/middleware"
 	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/quota"
 )
 
 type functions struct {
@@ -16,7 +18,7 @@ type functions struct {
 }
 
 func (f *functions) add(w http.ResponseWriter, r *http.Request) {
-	conf, _, err := middleware.Extract(r, false)
+	conf, auth, err := middleware.Extract(r, false)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
@@ -33,11 +35,13 @@ func (f *functions) add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordFunctionDeployed(r, conf, auth, data.FunctionName, "added")
+
 	w.WriteHeader(http.StatusOK)
 }
 
 func (f *functions) update(w http.ResponseWriter, r *http.Request) {
-	conf, _, err := middleware.Extract(r, false)
+	conf, auth, err := middleware.Extract(r, false)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -58,11 +62,13 @@ func (f *functions) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordFunctionDeployed(r, conf, auth, data.ID, "updated")
+
 	w.WriteHeader(http.StatusOK)
 }
 
 func (f *functions) del(w http.ResponseWriter, r *http.Request) {
-	conf, _, err := middleware.Extract(r, false)
+	conf, auth, err := middleware.Extract(r, false)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
@@ -74,6 +80,8 @@ func (f *functions) del(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordRootOperation(r, conf, auth, "deleted the function "+name)
+
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -100,22 +108,35 @@ func (f *functions) exec(w http.ResponseWriter, r *http.Request) {
 	}
 
 	env := &function.ExecutionEnvironment{
-		Auth:      auth,
-		BaseName:  conf.Name,
-		DataStore: backend.DB,
-		Search:    backend.Search,
-		Volatile:  backend.Cache,
-		Data:      fn,
-		Email:     backend.Emailer,
-		Log:       backend.Log,
-	}
-
-	if err := env.Execute(r); err != nil {
+		Auth:       auth,
+		BaseName:   conf.Name,
+		DataStore:  backend.DB,
+		Search:     backend.Search,
+		Volatile:   backend.Cache,
+		Data:       fn,
+		Email:      backend.QueuedMailer(conf),
+		Events:     backend.Events,
+		Storage:    backend.Filestore,
+		Scheduler:  backend.Scheduler,
+		Log:        backend.Log,
+		OnComplete: backend.FunctionCompleted,
+		BeforeRun:  backend.BeforeFunctionRun,
+		Flag:       backend.DatabaseFlagEnabled,
+		Secret:     backend.DatabaseSecret,
+		Settings:   backend.FunctionSettings,
+		RequestID:  middleware.RequestID(r),
+	}
+
+	// the function writes the response, a 200 without body by default
+	if err := env.ExecuteWeb(w, r); quota.Respond(w, err) {
+		return
+	} else if errors.Is(err, function.ErrKilled) {
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		return
+	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-
-	w.WriteHeader(http.StatusOK)
 }
 
 func (f *functions) list(w http.ResponseWriter, r *http.Request) {
@@ -1,15 +1,18 @@
 package staticbackend
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/function"
 	"github.com/staticbackendhq/core/model"
 )
 
@@ -492,6 +495,30 @@ func TestFunctionWithVolatilizerHelpers(t *testing.T) {
 			log(res.content);
 			return;
 		}
+
+		res = presence("test-channel");
+		if (!res.ok) {
+			log(res.content);
+			return;
+		}
+
+		res = sendToUser("some-account-id", "some-type", {a: "direct data"});
+		if (!res.ok) {
+			log(res.content);
+			return;
+		}
+
+		res = broadcast("some-type", {a: "to everyone"});
+		if (!res.ok) {
+			log(res.content);
+			return;
+		}
+
+		res = publishAt("test-channel", "some-type", {a: "later"}, new Date(Date.now() + 60000));
+		if (!res.ok) {
+			log(res.content);
+			return;
+		}
 	}
 	`
 
@@ -570,3 +597,434 @@ func TestFunctionWithVolatilizerHelpers(t *testing.T) {
 		t.Errorf("expected total to be 8 got %d", total)
 	}
 }
+
+func TestFunctionFeatureFlag(t *testing.T) {
+	conf, err := backend.DB.FindDatabase(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.SetFeatureFlag(conf.TenantID, "fn-beta", true); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DeleteFeatureFlag(conf.TenantID, "fn-beta")
+
+	code := `
+	function handle(body) {
+		if (flag("fn-beta") !== true) {
+			log("ERROR: expected fn-beta to be enabled");
+		}
+		if (flag("fn-unknown") !== false) {
+			log("ERROR: expected fn-unknown to be disabled");
+		}
+		if (flag() !== false) {
+			log("ERROR: expected a missing name to be disabled");
+		}
+	}`
+
+	data := model.ExecData{
+		FunctionName: "fn-flags",
+		Code:         code,
+		TriggerTopic: "web",
+	}
+	addResp := dbReq(t, funexec.add, "POST", "/", data, true)
+	defer addResp.Body.Close()
+	if addResp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, addResp))
+	}
+
+	execResp := dbReq(t, funexec.exec, "POST", "/fn/exec/fn-flags", url.Values{}, false, true)
+	defer execResp.Body.Close()
+	if execResp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, execResp))
+	}
+
+	// the run's history is saved in the background
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := function.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	infoResp := dbReq(t, funexec.info, "GET", "/fn/info/fn-flags", nil, true)
+	defer infoResp.Body.Close()
+	if infoResp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, infoResp))
+	}
+
+	var checkFn model.ExecData
+	if err := parseBody(infoResp.Body, &checkFn); err != nil {
+		t.Fatal(err)
+	} else if len(checkFn.History) == 0 {
+		t.Fatal("expected the function to have been executed")
+	}
+
+	for _, h := range checkFn.History {
+		for _, line := range h.Output {
+			if strings.Contains(line, "ERROR") {
+				t.Errorf("found error in function exec log: %v", h.Output)
+			}
+		}
+	}
+}
+
+func TestFunctionWebResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name: "fn-web-res",
+			code: `function handle(body, query, headers, res) {
+				res.status(201).setHeader("X-From", body.from).json({from: body.from});
+			}`,
+			status:      http.StatusCreated,
+			contentType: "application/json",
+			body:        `{"from":"unit test"}`,
+		},
+		{
+			name:        "fn-web-return",
+			code:        `function handle() { return "hello"; }`,
+			status:      http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			body:        "hello",
+		},
+		{
+			name: "fn-web-return-res",
+			code: `function handle(body, query, headers, res) {
+				res.setHeader("Content-Type", "text/csv");
+				return res.status(202).send("a,b");
+			}`,
+			status:      http.StatusAccepted,
+			contentType: "text/csv",
+			body:        "a,b",
+		},
+		{
+			name:   "fn-web-empty",
+			code:   `function handle() {}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "fn-web-invalid",
+			code:   `function handle(body, query, headers, res) { res.status(42); }`,
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		data := model.ExecData{
+			FunctionName: tc.name,
+			Code:         tc.code,
+			TriggerTopic: "web",
+		}
+		addResp := dbReq(t, funexec.add, "POST", "/", data, true)
+		defer addResp.Body.Close()
+		if addResp.StatusCode != http.StatusOK {
+			t.Fatal(GetResponseBody(t, addResp))
+		}
+
+		val := url.Values{}
+		val.Add("from", "unit test")
+
+		resp := dbReq(t, funexec.exec, "POST", "/fn/exec/"+tc.name, val, false, true)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: expected status %d got %d", tc.name, tc.status, resp.StatusCode)
+			continue
+		} else if tc.status >= 500 {
+			continue
+		}
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ct := resp.Header.Get("Content-Type"); len(tc.contentType) > 0 && ct != tc.contentType {
+			t.Errorf("%s: expected content type %s got %s", tc.name, tc.contentType, ct)
+		} else if string(b) != tc.body {
+			t.Errorf("%s: expected body %s got %s", tc.name, tc.body, string(b))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := function.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFunctionLimits(t *testing.T) {
+	invalid := model.AppSettings{
+		Functions: model.FunctionSettings{Limits: model.FunctionLimits{Timeout: -1}},
+	}
+
+	resp := dbReq(t, settings, "POST", "/account/settings", invalid, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a negative timeout got %d", resp.StatusCode)
+	}
+
+	s := model.AppSettings{
+		Functions: model.FunctionSettings{
+			Limits: model.FunctionLimits{MaxDBCalls: 2},
+			Overrides: map[string]model.FunctionLimits{
+				"fn-limit-loop":   {Timeout: 1},
+				"fn-limit-output": {MaxOutput: 20},
+			},
+		},
+	}
+
+	resp = dbReq(t, settings, "POST", "/account/settings", s, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	defer func() {
+		resp := dbReq(t, settings, "POST", "/account/settings", model.AppSettings{}, true)
+		defer resp.Body.Close()
+	}()
+
+	tests := []struct {
+		name   string
+		code   string
+		status int
+		output string
+	}{
+		{
+			name:   "fn-limit-loop",
+			code:   `function handle() { while (true) {} }`,
+			status: http.StatusGatewayTimeout,
+			output: "killed: exceeded 1 seconds",
+		},
+		{
+			name:   "fn-limit-dbcalls",
+			code:   `function handle() { for (var i = 0; i < 5; i++) { list("fnlimits"); } }`,
+			status: http.StatusInternalServerError,
+			output: "exceeded 2 database calls",
+		},
+		{
+			name:   "fn-limit-output",
+			code:   `function handle() { for (var i = 0; i < 10; i++) { log("line " + i); } }`,
+			status: http.StatusOK,
+			output: "output truncated: exceeded 20 bytes",
+		},
+	}
+
+	for _, tc := range tests {
+		data := model.ExecData{
+			FunctionName: tc.name,
+			Code:         tc.code,
+			TriggerTopic: "web",
+		}
+		addResp := dbReq(t, funexec.add, "POST", "/", data, true)
+		defer addResp.Body.Close()
+		if addResp.StatusCode != http.StatusOK {
+			t.Fatal(GetResponseBody(t, addResp))
+		}
+
+		execResp := dbReq(t, funexec.exec, "POST", "/fn/exec/"+tc.name, url.Values{}, false, true)
+		defer execResp.Body.Close()
+
+		if execResp.StatusCode != tc.status {
+			t.Errorf("%s: expected status %d got %d", tc.name, tc.status, execResp.StatusCode)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := function.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		infoResp := dbReq(t, funexec.info, "GET", "/fn/info/"+tc.name, nil, true)
+		defer infoResp.Body.Close()
+
+		var fn model.ExecData
+		if err := parseBody(infoResp.Body, &fn); err != nil {
+			t.Fatal(err)
+		} else if len(fn.History) == 0 {
+			t.Fatalf("%s: expected the run in the history", tc.name)
+		}
+
+		output := strings.Join(fn.History[0].Output, "\n")
+		if !strings.Contains(output, tc.output) {
+			t.Errorf("%s: expected %q in the output got %s", tc.name, tc.output, output)
+		} else if fn.History[0].Success != (tc.status == http.StatusOK) {
+			t.Errorf("%s: expected the run's success to be %v", tc.name, tc.status == http.StatusOK)
+		}
+	}
+}
+
+func TestFunctionFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			var v map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			v["method"] = r.Method
+			v["contentType"] = r.Header.Get("Content-Type")
+			v["token"] = r.Header.Get("X-Token")
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Served-By", "echo")
+			json.NewEncoder(w).Encode(v)
+		case "/large":
+			w.Write([]byte(strings.Repeat("a", 2048)))
+		}
+	}))
+	defer srv.Close()
+
+	defer func(size int64) { function.FetchMaxResponseSize = size }(function.FetchMaxResponseSize)
+	function.FetchMaxResponseSize = 1024
+
+	// the test server listens on the loopback address
+	defer func() { function.FetchAllowPrivateNetworks = false }()
+
+	tests := []struct {
+		name     string
+		settings model.FetchSettings
+		code     string
+		output   string
+		// denyPrivate keeps the default refusing the private addresses
+		denyPrivate bool
+	}{
+		// the private addresses are checked before a connection to the test
+		// server is kept alive
+		{
+			name:        "fn-fetch-private",
+			denyPrivate: true,
+			code: `function handle() {
+				var res = fetch("` + srv.URL + `/echo");
+				log(res.content);
+			}`,
+			output: "connecting to the private address 127.0.0.1 is not allowed",
+		},
+		{
+			name:        "fn-fetch-resolved",
+			denyPrivate: true,
+			code: `function handle() {
+				var res = fetch("` + strings.Replace(srv.URL, "127.0.0.1", "localhost", 1) + `/echo");
+				log(res.content);
+			}`,
+			output: "connecting to the private address",
+		},
+		{
+			name: "fn-fetch-json",
+			code: `function handle() {
+				var res = fetch("` + srv.URL + `/echo", {
+					method: "put",
+					headers: {"X-Token": "abc"},
+					body: {name: "fetch"}
+				});
+				if (!res.ok) { throw new Error(res.content); }
+				var r = res.content;
+				log(r.status + " " + r.headers["x-served-by"] + " " + r.json.name + " " + r.json.method + " " + r.json.contentType + " " + r.json.token);
+			}`,
+			output: "200 echo fetch PUT application/json abc",
+		},
+		{
+			name: "fn-fetch-size",
+			code: `function handle() {
+				var res = fetch("` + srv.URL + `/large");
+				log(res.content);
+			}`,
+			output: "the response exceeds 1024 bytes",
+		},
+		{
+			name:     "fn-fetch-denied",
+			settings: model.FetchSettings{DeniedHosts: []string{"127.0.0.1"}},
+			code: `function handle() {
+				var res = fetch("` + srv.URL + `/echo");
+				log(res.content);
+			}`,
+			output: "the host 127.0.0.1 is not allowed",
+		},
+		{
+			name:     "fn-fetch-allowed",
+			settings: model.FetchSettings{AllowedHosts: []string{"*.example.com"}},
+			code: `function handle() {
+				var res = fetch("` + srv.URL + `/echo");
+				log(res.content);
+			}`,
+			output: "the host 127.0.0.1 is not allowed",
+		},
+		{
+			name: "fn-fetch-scheme",
+			code: `function handle() {
+				var res = fetch("file:///etc/passwd");
+				log(res.content);
+			}`,
+			output: `unsupported URL scheme "file"`,
+		},
+	}
+
+	defer func() {
+		resp := dbReq(t, settings, "POST", "/account/settings", model.AppSettings{}, true)
+		defer resp.Body.Close()
+	}()
+
+	for _, tc := range tests {
+		function.FetchAllowPrivateNetworks = !tc.denyPrivate
+
+		s := model.AppSettings{Functions: model.FunctionSettings{Fetch: tc.settings}}
+		resp := dbReq(t, settings, "POST", "/account/settings", s, true)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatal(GetResponseBody(t, resp))
+		}
+
+		data := model.ExecData{
+			FunctionName: tc.name,
+			Code:         tc.code,
+			TriggerTopic: "web",
+		}
+		addResp := dbReq(t, funexec.add, "POST", "/", data, true)
+		defer addResp.Body.Close()
+		if addResp.StatusCode != http.StatusOK {
+			t.Fatal(GetResponseBody(t, addResp))
+		}
+
+		execResp := dbReq(t, funexec.exec, "POST", "/fn/exec/"+tc.name, url.Values{}, false, true)
+		defer execResp.Body.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := function.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		infoResp := dbReq(t, funexec.info, "GET", "/fn/info/"+tc.name, nil, true)
+		defer infoResp.Body.Close()
+
+		var fn model.ExecData
+		if err := parseBody(infoResp.Body, &fn); err != nil {
+			t.Fatal(err)
+		} else if len(fn.History) == 0 {
+			t.Fatalf("%s: expected the run in the history", tc.name)
+		}
+
+		output := strings.Join(fn.History[0].Output, "\n")
+		if !strings.Contains(output, tc.output) {
+			t.Errorf("%s: expected %q in the output got %s", tc.name, tc.output, output)
+		}
+	}
+
+	invalid := model.AppSettings{
+		Functions: model.FunctionSettings{Fetch: model.FetchSettings{AllowedHosts: []string{"https://api.example.com"}}},
+	}
+	resp := dbReq(t, settings, "POST", "/account/settings", invalid, true)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a host with a scheme got %d", resp.StatusCode)
+	}
+}
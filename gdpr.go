@@ -0,0 +1,104 @@
+package staticbackend
+
+import (
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// purgeUser permanently removes a user and all their data and returns a
+// deletion report. The account's files are removed with its last user,
+// otherwise only the files linked to the user are removed.
+func purgeUser(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data struct {
+		AccountID string `json:"accountId"`
+		UserID    string `json:"userId"`
+	}
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tok, err := backend.DB.GetUserByID(conf.Name, data.AccountID, data.UserID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	users, err := backend.DB.ListUsers(conf.Name, tok.AccountID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// files belong to the account, they're removed with its last user. The
+	// files are listed before purging the records but their blobs are only
+	// removed once the purge succeeded so a failed purge can be retried.
+	var files []model.File
+	if len(users) == 1 {
+		files, err = backend.DB.ListAllFiles(conf.Name, tok.AccountID)
+	} else {
+		files, err = backend.DB.ListFiles(conf.Name, model.FileFilter{UserID: tok.ID})
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report, err := backend.DB.PurgeUser(conf.Name, tok)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report.Files, report.FailedFiles = purgeFiles(conf.Name, files)
+
+	recordRootOperation(r, conf, auth, "purged the user "+tok.Email)
+
+	recordAuthEvent(r, conf, model.AuditEvent{
+		AccountID: tok.AccountID,
+		UserID:    tok.ID,
+		Email:     tok.Email,
+		Type:      model.AuditUserPurged,
+	})
+
+	emitUserEvent(conf, model.WebhookUserDeleted, tok)
+
+	respond(w, http.StatusOK, report)
+}
+
+// purgeFiles removes the files' blobs and records, it continues past the
+// failures and returns the keys of the files that could not be removed
+func purgeFiles(dbName string, files []model.File) (n int64, failed []string) {
+	for _, f := range files {
+		if err := backend.Filestore.Delete(f.Key); err != nil {
+			backend.Log.Error().Err(err).Msgf("unable to delete file %s", f.Key)
+			failed = append(failed, f.Key)
+			continue
+		}
+
+		for _, v := range f.Variants {
+			if err := backend.Filestore.Delete(v.Key); err != nil {
+				backend.Log.Error().Err(err).Msgf("unable to delete file variant %s", v.Key)
+				failed = append(failed, v.Key)
+			}
+		}
+
+		if err := backend.DB.DeleteFile(dbName, f.ID); err != nil {
+			backend.Log.Error().Err(err).Msgf("unable to delete file record %s", f.ID)
+			failed = append(failed, f.Key)
+			continue
+		}
+
+		n++
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package staticbackend
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestPurgeUser(t *testing.T) {
+	conf, err := backend.DB.FindDatabase(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, tok, err := backend.Membership(conf).CreateAccountAndUser("gdpr@test.com", "gdpr1234", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := model.Auth{
+		AccountID: tok.AccountID,
+		UserID:    tok.ID,
+		Email:     tok.Email,
+		Role:      tok.Role,
+		Token:     tok.Token,
+	}
+
+	doc := map[string]interface{}{"title": "personal data"}
+	if _, err := backend.DB.CreateDocument(auth, dbName, "gdpr_tasks", doc); err != nil {
+		t.Fatal(err)
+	}
+
+	fileKey := fmt.Sprintf("%s/%s/gdpr.txt", dbName, tok.AccountID)
+
+	upData := model.UploadFileData{FileKey: fileKey, File: strings.NewReader("personal file")}
+	fileURL, err := backend.Filestore.Save(upData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := model.File{AccountID: tok.AccountID, Key: fileKey, URL: fileURL, Size: 13, Uploaded: time.Now()}
+	fileID, err := backend.DB.AddFile(dbName, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := new(struct {
+		AccountID string `json:"accountId"`
+		UserID    string `json:"userId"`
+	})
+	data.AccountID = tok.AccountID
+	data.UserID = tok.ID
+
+	resp := dbReq(t, purgeUser, "POST", "/sudo/_/purge-user", data, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var report model.DeletionReport
+	if err := parseBody(resp.Body, &report); err != nil {
+		t.Fatal(err)
+	} else if report.Documents["gdpr_tasks"] != 1 {
+		t.Errorf("expected 1 document removed got %d", report.Documents["gdpr_tasks"])
+	} else if !report.AccountDeleted {
+		t.Error("expected the account to be deleted")
+	} else if report.Files != 1 || len(report.FailedFiles) > 0 {
+		t.Errorf("expected 1 file removed without failure got %d %v", report.Files, report.FailedFiles)
+	}
+
+	if _, err := backend.DB.GetFileByID(dbName, fileID); err == nil {
+		t.Error("expected an error getting a purged file")
+	}
+
+	if rc, err := backend.Filestore.Open(fileKey); err == nil {
+		rc.Close()
+		t.Error("expected an error opening a purged file blob")
+	}
+
+	if _, err := backend.DB.GetUserByID(dbName, tok.AccountID, tok.ID); err == nil {
+		t.Error("expected an error getting a purged user")
+	}
+}
@@ -0,0 +1,44 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestReadiness(t *testing.T) {
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+	healthz(w, req)
+
+	if resp := w.Result(); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200 got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/readyz", nil)
+	w = httptest.NewRecorder()
+	readyz(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	var report model.HealthReport
+	if err := parseBody(resp.Body, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || report.Status != model.HealthStatusOK {
+		t.Fatalf("expected the instance to be ready got %d %v", resp.StatusCode, report)
+	}
+
+	for _, name := range []string{"database", "cache", "storage", "mailer"} {
+		hc, ok := report.Checks[name]
+		if !ok {
+			t.Errorf("expected a %s check", name)
+		} else if hc.Status == model.HealthStatusError {
+			t.Errorf("expected the %s check to pass got %s", name, hc.Error)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package hotcache caches the Persister lookups made on every request: the
+// functions to execute and the root tokens to validate. The entries are
+// kept in memory for LocalTTL and in the volatile cache for SharedTTL.
+//
+// The writes made through the Persister invalidate the entries of their
+// database by bumping its generation in the volatile cache, the other
+// instances drop their in-memory entries after at most LocalTTL.
+package hotcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/database"
+	"github.com/staticbackendhq/core/model"
+)
+
+const (
+	// LocalTTL is how long an entry is kept in the instance's memory
+	LocalTTL = 5 * time.Second
+	// SharedTTL is how long an entry is kept in the volatile cache
+	SharedTTL = 10 * time.Minute
+	// maxLocalEntries is the number of in-memory entries above which the
+	// expired ones are removed
+	maxLocalEntries = 10000
+)
+
+// kinds of cached entries, each one has its generation per database
+const (
+	kindFunctions = "fn"
+	kindRoots     = "root"
+)
+
+type entry struct {
+	value   any
+	expires time.Time
+}
+
+// persister serves the hot lookups from the cache and invalidates them on
+// the writes, the other calls go to the wrapped Persister
+type persister struct {
+	database.Persister
+
+	volatile cache.Volatilizer
+
+	mu    sync.RWMutex
+	local map[string]entry
+}
+
+// Persister returns the Persister caching the hot lookups of p in memory
+// and in v
+func Persister(p database.Persister, v cache.Volatilizer) database.Persister {
+	return &persister{Persister: p, volatile: v, local: make(map[string]entry)}
+}
+
+func generationKey(kind, dbName string) string {
+	return "hot:gen:" + kind + ":" + dbName
+}
+
+// lookup returns the cached value of id or loads and caches it, the
+// failed loads are not cached
+func lookup[T any](hp *persister, kind, dbName, id string, load func() (T, error)) (T, error) {
+	localKey := kind + ":" + dbName + ":" + id
+
+	hp.mu.RLock()
+	e, ok := hp.local[localKey]
+	hp.mu.RUnlock()
+
+	if ok && time.Now().Before(e.expires) {
+		return e.value.(T), nil
+	}
+
+	gen, err := hp.volatile.Get(generationKey(kind, dbName))
+	if err != nil {
+		gen = "0"
+	}
+	sharedKey := fmt.Sprintf("hot:%s:%s:%s:%s", kind, dbName, gen, id)
+
+	var v T
+	if err := hp.volatile.GetTyped(sharedKey, &v); err != nil {
+		if v, err = load(); err != nil {
+			return v, err
+		}
+
+		// the cache is best effort, the next lookup loads it again
+		if err := hp.volatile.SetTyped(sharedKey, v); err == nil {
+			hp.volatile.Expire(sharedKey, SharedTTL)
+		}
+	}
+
+	now := time.Now()
+
+	hp.mu.Lock()
+	if len(hp.local) >= maxLocalEntries {
+		for key, e := range hp.local {
+			if now.After(e.expires) {
+				delete(hp.local, key)
+			}
+		}
+	}
+	hp.local[localKey] = entry{value: v, expires: now.Add(LocalTTL)}
+	hp.mu.Unlock()
+
+	return v, nil
+}
+
+// invalidate drops the entries of a kind for a database on all instances
+func (hp *persister) invalidate(kind, dbName string) error {
+	prefix := kind + ":" + dbName + ":"
+
+	hp.mu.Lock()
+	for key := range hp.local {
+		if strings.HasPrefix(key, prefix) {
+			delete(hp.local, key)
+		}
+	}
+	hp.mu.Unlock()
+
+	if _, err := hp.volatile.Inc(generationKey(kind, dbName), 1); err != nil {
+		return fmt.Errorf("unable to invalidate the cached %s of %s: %w", kind, dbName, err)
+	}
+	return nil
+}
+
+func (hp *persister) GetFunctionForExecution(dbName, name string) (model.ExecData, error) {
+	return lookup(hp, kindFunctions, dbName, name, func() (model.ExecData, error) {
+		fn, err := hp.Persister.GetFunctionForExecution(dbName, name)
+		// the run history is not needed to execute it
+		fn.History = nil
+		return fn, err
+	})
+}
+
+func (hp *persister) AddFunction(dbName string, data model.ExecData) (string, error) {
+	id, err := hp.Persister.AddFunction(dbName, data)
+	if err != nil {
+		return id, err
+	}
+	return id, hp.invalidate(kindFunctions, dbName)
+}
+
+func (hp *persister) UpdateFunction(dbName, id, code, trigger string) error {
+	if err := hp.Persister.UpdateFunction(dbName, id, code, trigger); err != nil {
+		return err
+	}
+	return hp.invalidate(kindFunctions, dbName)
+}
+
+func (hp *persister) DeleteFunction(dbName, name string) error {
+	if err := hp.Persister.DeleteFunction(dbName, name); err != nil {
+		return err
+	}
+	return hp.invalidate(kindFunctions, dbName)
+}
+
+func (hp *persister) FindRootUser(dbName, userID, accountID, token string) (model.User, error) {
+	// the token is not part of the cache keys
+	sum := sha256.Sum256([]byte(userID + "|" + accountID + "|" + token))
+
+	return lookup(hp, kindRoots, dbName, hex.EncodeToString(sum[:]), func() (model.User, error) {
+		return hp.Persister.FindRootUser(dbName, userID, accountID, token)
+	})
+}
+
+func (hp *persister) SetUserRole(dbName, email string, role int) error {
+	if err := hp.Persister.SetUserRole(dbName, email, role); err != nil {
+		return err
+	}
+	return hp.invalidate(kindRoots, dbName)
+}
+
+func (hp *persister) ResetUserCredentials(dbName, userID, email, password, token string) error {
+	if err := hp.Persister.ResetUserCredentials(dbName, userID, email, password, token); err != nil {
+		return err
+	}
+	return hp.invalidate(kindRoots, dbName)
+}
+
+func (hp *persister) RemoveUser(auth model.Auth, dbName, userID string) error {
+	if err := hp.Persister.RemoveUser(auth, dbName, userID); err != nil {
+		return err
+	}
+	return hp.invalidate(kindRoots, dbName)
+}
+
+func (hp *persister) PurgeUser(dbName string, tok model.User) (model.DeletionReport, error) {
+	report, err := hp.Persister.PurgeUser(dbName, tok)
+	if err != nil {
+		return report, err
+	}
+	return report, hp.invalidate(kindRoots, dbName)
+}
@@ -0,0 +1,152 @@
+package hotcache_test
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/database"
+	"github.com/staticbackendhq/core/database/memory"
+	"github.com/staticbackendhq/core/hotcache"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+const dbName = "hotdb"
+
+// counting counts the lookups reaching the database
+type counting struct {
+	database.Persister
+	functions int
+	roots     int
+}
+
+func (c *counting) GetFunctionForExecution(dbName, name string) (model.ExecData, error) {
+	c.functions++
+	return c.Persister.GetFunctionForExecution(dbName, name)
+}
+
+func (c *counting) FindRootUser(dbName, userID, accountID, token string) (model.User, error) {
+	c.roots++
+	return c.Persister.FindRootUser(dbName, userID, accountID, token)
+}
+
+func setup(t testing.TB) (*counting, database.Persister, model.User) {
+	log := logger.Get(config.AppConfig{AppEnv: "dev"})
+	vol := cache.NewDevCache(log)
+
+	c := &counting{Persister: memory.New(vol.PublishDocument)}
+	db := hotcache.Persister(c, vol)
+
+	fn := model.ExecData{FunctionName: "hot", TriggerTopic: "web", Code: "function handle() {}"}
+	if _, err := db.AddFunction(dbName, fn); err != nil {
+		t.Fatal(err)
+	}
+
+	acctID, err := db.CreateAccount(dbName, "root@hot.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := model.User{AccountID: acctID, Email: "root@hot.test", Token: "root-token", Role: 100}
+	root.ID, err = db.CreateUser(dbName, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, db, root
+}
+
+func TestFunctionLookups(t *testing.T) {
+	c, db, _ := setup(t)
+
+	for i := 0; i < 3; i++ {
+		if fn, err := db.GetFunctionForExecution(dbName, "hot"); err != nil {
+			t.Fatal(err)
+		} else if fn.Code != "function handle() {}" {
+			t.Fatalf("unexpected function %v", fn)
+		}
+	}
+	if c.functions != 1 {
+		t.Errorf("expected 1 database lookup got %d", c.functions)
+	}
+
+	fn, err := db.GetFunctionByName(dbName, "hot")
+	if err != nil {
+		t.Fatal(err)
+	} else if err := db.UpdateFunction(dbName, fn.ID, "function handle() { log('v2'); }", "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	if fn, err := db.GetFunctionForExecution(dbName, "hot"); err != nil {
+		t.Fatal(err)
+	} else if fn.Code != "function handle() { log('v2'); }" {
+		t.Errorf("expected the updated code after the update got %s", fn.Code)
+	}
+
+	if err := db.DeleteFunction(dbName, "hot"); err != nil {
+		t.Fatal(err)
+	} else if _, err := db.GetFunctionForExecution(dbName, "hot"); err == nil {
+		t.Error("expected the deleted function to not be found")
+	}
+}
+
+func TestRootLookups(t *testing.T) {
+	c, db, root := setup(t)
+
+	for i := 0; i < 3; i++ {
+		if tok, err := db.FindRootUser(dbName, root.ID, root.AccountID, root.Token); err != nil {
+			t.Fatal(err)
+		} else if tok.ID != root.ID || tok.Token != root.Token || tok.Role != 100 {
+			t.Fatalf("unexpected root user %v", tok)
+		}
+	}
+	if c.roots != 1 {
+		t.Errorf("expected 1 database lookup got %d", c.roots)
+	}
+
+	if _, err := db.FindRootUser(dbName, root.ID, root.AccountID, "wrong"); err == nil {
+		t.Error("expected a wrong token to be refused")
+	}
+
+	// the revoked tokens are not served from the cache
+	if err := db.SetUserRole(dbName, root.Email, 0); err != nil {
+		t.Fatal(err)
+	} else if tok, err := db.FindRootUser(dbName, root.ID, root.AccountID, root.Token); err == nil && tok.Role == 100 {
+		t.Errorf("expected the role change to be seen got %v", tok)
+	}
+
+	auth := model.Auth{AccountID: root.AccountID, UserID: root.ID, Role: 100}
+	if err := db.RemoveUser(auth, dbName, root.ID); err != nil {
+		t.Fatal(err)
+	} else if _, err := db.FindRootUser(dbName, root.ID, root.AccountID, root.Token); err == nil {
+		t.Error("expected the removed user's token to be refused")
+	}
+}
+
+// the hot lookups with and without the cache:
+//
+//	go test -bench . ./hotcache
+func BenchmarkLookups(b *testing.B) {
+	c, db, root := setup(b)
+
+	lookups := map[string]database.Persister{"persister": c.Persister, "hotcache": db}
+	for _, name := range []string{"persister", "hotcache"} {
+		p := lookups[name]
+
+		b.Run("function/"+name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := p.GetFunctionForExecution(dbName, "hot"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run("root/"+name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := p.FindRootUser(dbName, root.ID, root.AccountID, root.Token); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
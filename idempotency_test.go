@@ -0,0 +1,80 @@
+package staticbackend
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+func TestIdempotencyKeyReplay(t *testing.T) {
+	calls := 0
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Location", fmt.Sprintf("/db/orders/%d", calls))
+			respond(w, http.StatusCreated, calls)
+		}),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequireAuth(backend.DB, backend.Cache),
+		middleware.Idempotency(backend.Cache),
+	)
+
+	call := func(method, key, body string) *http.Response {
+		req := httptest.NewRequest(method, "/db/orders", strings.NewReader(body))
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		if len(key) > 0 {
+			req.Header.Set(middleware.IdempotencyHeader, key)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	resp := call("POST", "order-1", `{"total": 10}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 got %s", GetResponseBody(t, resp))
+	} else if len(resp.Header.Get(middleware.ReplayedHeader)) > 0 {
+		t.Error("expected the first response to not be a replay")
+	}
+
+	// a retry returns the first response without calling the handler
+	resp = call("POST", "order-1", `{"total": 10}`)
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected the handler to be called once got %d", calls)
+	} else if resp.StatusCode != http.StatusCreated || strings.TrimSpace(string(b)) != "1" {
+		t.Errorf("expected the first response got %d %s", resp.StatusCode, b)
+	} else if resp.Header.Get(middleware.ReplayedHeader) != "true" {
+		t.Error("expected the replayed header")
+	} else if resp.Header.Get("Location") != "/db/orders/1" {
+		t.Errorf("expected the Location to be replayed got %s", resp.Header.Get("Location"))
+	}
+
+	// reusing the key for a different request is refused
+	if resp := call("POST", "order-1", `{"total": 20}`); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422 got %s", GetResponseBody(t, resp))
+	}
+
+	// without a key, or for reads, every request is processed
+	call("POST", "", `{"total": 10}`)
+	call("GET", "order-1", "")
+	if calls != 3 {
+		t.Errorf("expected the handler to be called 3 times got %d", calls)
+	}
+
+	if resp := call("POST", "bad key", `{}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 got %s", GetResponseBody(t, resp))
+	}
+}
@@ -0,0 +1,106 @@
+package staticbackend
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// importEvent is a line of the import response, the progress of the import
+// followed by its report or error
+type importEvent struct {
+	Progress *model.ImportProgress `json:"progress,omitempty"`
+	Report   *model.ImportReport   `json:"report,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// importData imports a Firebase or Supabase export sent as a multipart
+// form. The source field is firebase or supabase and the optional mapping
+// field the JSON model.ImportMapping. Firebase exports are sent in the
+// users, firestore and storage files, Supabase ones in the dump file. The
+// progress is streamed as JSON lines.
+func importData(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var mapping model.ImportMapping
+	if s := r.FormValue("mapping"); len(s) > 0 {
+		if err := json.Unmarshal([]byte(s), &mapping); err != nil {
+			http.Error(w, "invalid mapping: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	source := r.FormValue("source")
+	var files []string
+	switch source {
+	case model.ImportFirebase:
+		files = []string{"users", "firestore", "storage"}
+	case model.ImportSupabase:
+		files = []string{"dump"}
+	default:
+		http.Error(w, "the source must be firebase or supabase", http.StatusBadRequest)
+		return
+	}
+
+	readers := make(map[string]io.Reader)
+	for _, name := range files {
+		f, _, err := r.FormFile(name)
+		if err == http.ErrMissingFile {
+			continue
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+
+		readers[name] = f
+	}
+
+	if len(readers) == 0 {
+		http.Error(w, "the export files are missing", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	send := func(evt importEvent) {
+		enc.Encode(evt)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	progress := func(p model.ImportProgress) {
+		send(importEvent{Progress: &p})
+	}
+
+	var report model.ImportReport
+	if source == model.ImportFirebase {
+		report, err = backend.ImportFirebase(auth, conf, readers["users"], readers["firestore"], readers["storage"], mapping, progress)
+	} else {
+		report, err = backend.ImportSupabase(auth, conf, readers["dump"], mapping, progress)
+	}
+
+	// the status is already sent, the error is the last line
+	if err != nil {
+		send(importEvent{Error: err.Error()})
+		return
+	}
+	send(importEvent{Report: &report})
+}
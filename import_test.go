@@ -0,0 +1,92 @@
+package staticbackend
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func importRequest(t *testing.T, fields, files map[string]string) *http.Response {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	for k, v := range files {
+		part, err := writer.CreateFormFile(k, k+".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(v))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/sudo/_/import", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("SB-PUBLIC-KEY", pubKey)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", rootToken))
+
+	stdRoot := []middleware.Middleware{
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequireRoot(backend.DB, backend.Cache),
+	}
+
+	w := httptest.NewRecorder()
+	middleware.Chain(http.HandlerFunc(importData), stdRoot...).ServeHTTP(w, req)
+	return w.Result()
+}
+
+func TestImportData(t *testing.T) {
+	firestore := `{"__collections__": {"import_notes": {"n1": {"text": "a"}, "n2": {"text": "b"}}}}`
+	mapping := `{"collections": {"import_notes": {"fields": {"text": "body"}}}}`
+
+	resp := importRequest(t, map[string]string{"source": model.ImportFirebase, "mapping": mapping}, map[string]string{"firestore": firestore})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var events []importEvent
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		var evt importEvent
+		if err := json.Unmarshal(sc.Bytes(), &evt); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, evt)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("expected 3 progress lines and the report got %d", len(events))
+	}
+
+	last := events[len(events)-1]
+	if last.Report == nil || last.Report.Documents["import_notes"] != 2 {
+		t.Errorf("expected 2 imported notes got %v", last)
+	} else if p := events[1].Progress; p == nil || p.Stage != backend.ImportStageDocuments || p.Done != 2 {
+		t.Errorf("expected the documents progress got %v", events[1])
+	}
+
+	for _, fields := range []map[string]string{
+		{"source": "parse"},
+		{"source": model.ImportSupabase},
+		{"source": model.ImportSupabase, "mapping": "{"},
+	} {
+		resp := importRequest(t, fields, nil)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%v: expected status 400 got %d", fields, resp.StatusCode)
+		}
+	}
+}
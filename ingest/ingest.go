@@ -0,0 +1,144 @@
+// Package ingest buffers the analytics events sent by the apps' clients in
+// memory and appends them to the databases in batches, the clients do not
+// wait for the events to be written.
+package ingest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+// Store appends the events of a database
+type Store interface {
+	AddAppEvents(dbName string, events []model.AppEvent) error
+}
+
+// Number of buffered events triggering a write before the flush interval
+const batchSize = 1000
+
+// MaxBuffered is the number of buffered events above which the new events
+// are dropped, when the database cannot keep up
+const MaxBuffered = 100000
+
+// Buffer accumulates the events per database until it's flushed
+type Buffer struct {
+	mu      sync.Mutex
+	events  map[string][]model.AppEvent
+	size    int
+	dropped int64
+	full    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New returns an empty Buffer
+func New() *Buffer {
+	return &Buffer{
+		events: make(map[string][]model.AppEvent),
+		full:   make(chan struct{}, 1),
+	}
+}
+
+// Default is the Buffer of the server
+var Default = New()
+
+// Add buffers the events of a database, it returns the number of events
+// accepted, the others are dropped when the buffer is full
+func Add(dbName string, events []model.AppEvent) int {
+	return Default.Add(dbName, events)
+}
+
+// Add buffers the events of a database, it returns the number of events
+// accepted, the others are dropped when the buffer is full
+func (b *Buffer) Add(dbName string, events []model.AppEvent) int {
+	b.mu.Lock()
+	n := len(events)
+	if b.size+n > MaxBuffered {
+		n = MaxBuffered - b.size
+		b.dropped += int64(len(events) - n)
+	}
+	b.events[dbName] = append(b.events[dbName], events[:n]...)
+	b.size += n
+	size := b.size
+	b.mu.Unlock()
+
+	if size >= batchSize {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+	return n
+}
+
+// Flush writes the buffered events of each database in one batch. The
+// events of the databases whose write fails are kept for the next flush.
+func (b *Buffer) Flush(store Store) error {
+	b.mu.Lock()
+	events := b.events
+	b.events = make(map[string][]model.AppEvent)
+	b.size = 0
+	b.mu.Unlock()
+
+	var lastErr error
+	for dbName, list := range events {
+		if err := store.AddAppEvents(dbName, list); err != nil {
+			lastErr = err
+			b.Add(dbName, list)
+		}
+	}
+	return lastErr
+}
+
+// Dropped returns the number of events dropped since the start because the
+// buffer was full
+func (b *Buffer) Dropped() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// Start flushes the events every interval, or sooner when the buffer is
+// full, until Stop is called. It does nothing when the Buffer is already
+// started.
+func (b *Buffer) Start(store Store, interval time.Duration, log *logger.Logger) {
+	if b.stop != nil {
+		return
+	}
+
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+
+	go func() {
+		defer close(b.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-b.full:
+			case <-b.stop:
+				return
+			}
+
+			if err := b.Flush(store); err != nil {
+				log.Error().Err(err).Msg("error writing the app events")
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic flush and writes the remaining events
+func (b *Buffer) Stop(store Store) error {
+	if b.stop != nil {
+		close(b.stop)
+		<-b.done
+		b.stop = nil
+	}
+	return b.Flush(store)
+}
@@ -0,0 +1,62 @@
+package ingest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+type memStore struct {
+	events map[string][]model.AppEvent
+	err    error
+}
+
+func (s *memStore) AddAppEvents(dbName string, events []model.AppEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events[dbName] = append(s.events[dbName], events...)
+	return nil
+}
+
+func TestBufferFlush(t *testing.T) {
+	b := New()
+
+	b.Add("db1", []model.AppEvent{{Name: "signup"}, {Name: "login"}})
+	b.Add("db2", []model.AppEvent{{Name: "signup"}})
+
+	// a failed write keeps the events for the next flush
+	store := &memStore{events: make(map[string][]model.AppEvent), err: errors.New("unavailable")}
+	if err := b.Flush(store); err == nil {
+		t.Fatal("expected the write error")
+	}
+
+	b.Add("db1", []model.AppEvent{{Name: "checkout"}})
+
+	store.err = nil
+	if err := b.Flush(store); err != nil {
+		t.Fatal(err)
+	} else if len(store.events["db1"]) != 3 || len(store.events["db2"]) != 1 {
+		t.Errorf("expected 3 events of db1 and 1 of db2 got %v", store.events)
+	}
+
+	// the buffer is empty once flushed
+	if err := b.Flush(store); err != nil {
+		t.Fatal(err)
+	} else if len(store.events["db1"]) != 3 {
+		t.Errorf("expected no new events got %v", store.events)
+	}
+}
+
+func TestBufferFull(t *testing.T) {
+	b := New()
+
+	b.Add("db1", make([]model.AppEvent, MaxBuffered-1))
+
+	if n := b.Add("db1", make([]model.AppEvent, 3)); n != 1 {
+		t.Errorf("expected 1 event accepted got %d", n)
+	} else if d := b.Dropped(); d != 2 {
+		t.Errorf("expected 2 events dropped got %d", d)
+	}
+}
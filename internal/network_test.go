@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestCheckPublicAddress(t *testing.T) {
+	private := []string{
+		"127.0.0.1:80",
+		"10.0.0.1:443",
+		"192.168.1.10:8080",
+		"169.254.169.254:80",
+		"0.0.0.0:80",
+		"[::1]:80",
+		"[fe80::1]:80",
+	}
+	for _, addr := range private {
+		if err := CheckPublicAddress("tcp", addr, nil); err == nil {
+			t.Errorf("expected %s to be refused", addr)
+		}
+	}
+
+	if err := CheckPublicAddress("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("expected a public address to be allowed got %v", err)
+	}
+}
+
+func TestIsPrivateHost(t *testing.T) {
+	hosts := map[string]bool{
+		"localhost":       true,
+		"api.localhost":   true,
+		"127.0.0.1":       true,
+		"169.254.169.254": true,
+		"::1":             true,
+		"example.com":     false,
+		"93.184.216.34":   false,
+	}
+	for host, private := range hosts {
+		if IsPrivateHost(host) != private {
+			t.Errorf("expected IsPrivateHost(%s) to be %v", host, private)
+		}
+	}
+}
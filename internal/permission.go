@@ -1,7 +1,6 @@
 package internal
 
 import (
-	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -76,7 +75,7 @@ func CanWrite(s string) bool {
 func CanRead(s string) bool {
 	i, err := strconv.Atoi(s)
 	if err != nil {
-		fmt.Println(err)
+		return false
 	}
 	return uint8(i)&uint8(4) != 0
 }
@@ -0,0 +1,111 @@
+package staticbackend
+
+import (
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func (a *accounts) invite(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if auth.Role < middleware.RootRole {
+		http.Error(w, "only an admin can manage invitations", http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var data backend.InviteData
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data.Locale = localeHint(r, data.Locale)
+
+		inv, err := backend.Membership(conf).Invite(auth, data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		respond(w, http.StatusOK, inv)
+		return
+	}
+
+	invites, err := backend.DB.ListInvites(conf.Name, auth.AccountID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, invites)
+}
+
+func (a *accounts) revokeInvite(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if auth.Role < middleware.RootRole {
+		http.Error(w, "only an admin can manage invitations", http.StatusUnauthorized)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 3)
+
+	inv, err := backend.DB.GetInvite(conf.Name, id)
+	if err != nil || inv.AccountID != auth.AccountID {
+		http.Error(w, "invitation not found", http.StatusNotFound)
+		return
+	}
+
+	if err := backend.DB.DeleteInvite(conf.Name, id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+func (a *accounts) acceptInvite(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, "invalid StaticBackend key", http.StatusUnauthorized)
+		return
+	}
+
+	var data = new(struct {
+		ID       string `json:"id"`
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	inv, err := backend.DB.GetInvite(conf.Name, data.ID)
+	if err != nil {
+		http.Error(w, "invalid invitation", http.StatusBadRequest)
+		return
+	}
+
+	token, err := backend.Membership(conf).AcceptInvite(data.ID, data.Token, data.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	emitUserEventByEmail(conf, model.WebhookUserCreated, inv.Email)
+
+	respond(w, http.StatusOK, token)
+}
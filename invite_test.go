@@ -0,0 +1,172 @@
+package staticbackend
+
+import (
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
+)
+
+type captureMailer struct {
+	sent []email.SendMailData
+}
+
+func (c *captureMailer) Send(data email.SendMailData) error {
+	c.sent = append(c.sent, data)
+	return nil
+}
+
+func TestInviteAcceptAndRevoke(t *testing.T) {
+	mailer := &captureMailer{}
+
+	emailer := backend.Emailer
+	backend.Emailer = mailer
+	defer func() { backend.Emailer = emailer }()
+
+	data := backend.InviteData{
+		Email: "invitee@test.com",
+		Role:  20,
+		Note:  `<a href="https://evil.com">join us</a>`,
+		Link:  "https://myapp.com/accept",
+	}
+
+	resp := dbReq(t, acct.invite, "POST", "/account/invite", data)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	// the invitation email is queued, other tests' emails may be sent too
+	if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []email.SendMailData
+	for _, m := range mailer.sent {
+		if m.To == data.Email {
+			sent = append(sent, m)
+		}
+	}
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 email sent got %d", len(sent))
+	}
+
+	if sent[0].From != backend.Config.FromEmail {
+		t.Errorf("expected the invite to be sent from %s got %s", backend.Config.FromEmail, sent[0].From)
+	} else if strings.Contains(sent[0].HTMLBody, "evil.com\"") {
+		t.Errorf("expected the note to be escaped got %s", sent[0].HTMLBody)
+	}
+
+	link := regexp.MustCompile(`href="([^"]+)"`).FindStringSubmatch(sent[0].HTMLBody)
+	if len(link) != 2 {
+		t.Fatalf("unable to find the link in %s", sent[0].HTMLBody)
+	}
+
+	u, err := url.Parse(html.UnescapeString(link[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp2 := dbReq(t, acct.invite, "GET", "/account/invite", nil)
+	defer resp2.Body.Close()
+
+	var invites []model.Invite
+	if err := parseBody(resp2.Body, &invites); err != nil {
+		t.Fatal(err)
+	} else if len(invites) != 1 {
+		t.Fatalf("expected 1 pending invite got %d", len(invites))
+	}
+
+	accept := new(struct {
+		ID       string `json:"id"`
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	})
+	accept.ID = u.Query().Get("id")
+	accept.Token = "not-the-token"
+	accept.Password = "invitee1234"
+
+	resp3 := dbReq(t, acct.acceptInvite, "POST", "/account/invite/accept", accept)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode <= 299 {
+		t.Fatal("expected an invalid token to be rejected")
+	}
+
+	accept.Token = u.Query().Get("token")
+
+	resp4 := dbReq(t, acct.acceptInvite, "POST", "/account/invite/accept", accept)
+	defer resp4.Body.Close()
+
+	if resp4.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp4))
+	}
+
+	tok, err := backend.DB.FindUserByEmail(dbName, data.Email)
+	if err != nil {
+		t.Fatal(err)
+	} else if tok.AccountID != testAccountID {
+		t.Errorf("expected account to be %s got %s", testAccountID, tok.AccountID)
+	} else if tok.Role != data.Role {
+		t.Errorf("expected role to be %d got %d", data.Role, tok.Role)
+	}
+
+	// an invite can be revoked before being accepted
+	data.Email = "revoked@test.com"
+	resp5 := dbReq(t, acct.invite, "POST", "/account/invite", data)
+	defer resp5.Body.Close()
+
+	var inv model.Invite
+	if err := parseBody(resp5.Body, &inv); err != nil {
+		t.Fatal(err)
+	}
+
+	resp6 := dbReq(t, acct.revokeInvite, "DELETE", "/account/invite/"+inv.ID, nil)
+	defer resp6.Body.Close()
+
+	if resp6.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp6))
+	}
+
+	invites, err = backend.DB.ListInvites(dbName, testAccountID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(invites) != 0 {
+		t.Errorf("expected no pending invite got %d", len(invites))
+	}
+}
+
+func TestInviteRequiresAdmin(t *testing.T) {
+	data := backend.InviteData{
+		Email: "notinvited@test.com",
+		Link:  "https://myapp.com/accept",
+	}
+
+	resp := tokenReq(t, acct.invite, "POST", "/account/invite", userToken, data)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for a non-admin got %d", resp.StatusCode)
+	}
+
+	resp2 := tokenReq(t, acct.invite, "GET", "/account/invite", userToken, nil)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 listing as a non-admin got %d", resp2.StatusCode)
+	}
+
+	resp3 := tokenReq(t, acct.revokeInvite, "DELETE", "/account/invite/some-id", userToken, nil)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 revoking as a non-admin got %d", resp3.StatusCode)
+	}
+}
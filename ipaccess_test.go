@@ -0,0 +1,108 @@
+package staticbackend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		remote string
+		fwd    string
+		ip     string
+	}{
+		{"203.0.113.1:1234", "", "203.0.113.1"},
+		// a client connecting directly cannot spoof its IP
+		{"203.0.113.1:1234", "198.51.100.7", "203.0.113.1"},
+		{"10.0.0.5:1234", "198.51.100.7", "198.51.100.7"},
+		// the spoofed first entry is ignored, the last untrusted hop is the client
+		{"10.0.0.5:1234", "1.2.3.4, 198.51.100.7, 10.0.0.9", "198.51.100.7"},
+		{"10.0.0.5:1234", "10.0.0.8", "10.0.0.8"},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tc.remote
+		if len(tc.fwd) > 0 {
+			req.Header.Set("X-Forwarded-For", tc.fwd)
+		}
+
+		if ip := middleware.ClientIP(req); ip != tc.ip {
+			t.Errorf("%s %q: expected %s got %s", tc.remote, tc.fwd, tc.ip, ip)
+		}
+	}
+}
+
+func TestIPAccessSettings(t *testing.T) {
+	ipa := model.IPAccessSettings{Allow: []string{"198.51.100.0/24", "2001:db8::1"}, Deny: []string{"198.51.100.66"}}
+
+	tests := map[string]bool{
+		"198.51.100.7":  true,
+		"198.51.100.66": false,
+		"203.0.113.1":   false,
+		"2001:db8::1":   true,
+		"invalid":       false,
+	}
+	for ip, allowed := range tests {
+		if got := ipa.Allows(ip); got != allowed {
+			t.Errorf("%s: expected allowed %t got %t", ip, allowed, got)
+		}
+	}
+
+	if !(model.IPAccessSettings{}).Allows("203.0.113.1") {
+		t.Error("expected empty settings to allow all IPs")
+	}
+
+	if err := validateIPAccess(model.IPAccessSettings{Allow: []string{"10.0.0.0/33"}}, "10.0.0.1"); err == nil {
+		t.Error("expected an error for an invalid range")
+	} else if err := validateIPAccess(model.IPAccessSettings{Scope: "admins"}, "10.0.0.1"); err == nil {
+		t.Error("expected an error for an invalid scope")
+	} else if err := validateIPAccess(ipa, "203.0.113.1"); err == nil {
+		t.Error("expected an error when blocking the caller's IP")
+	} else if err := validateIPAccess(ipa, "198.51.100.7"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRestrictIP(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, true)
+	})
+
+	call := func(scope string, rootOnly bool, role int) int {
+		conf := model.DatabaseConfig{Name: "ipaccess"}
+		conf.Settings.IPAccess = model.IPAccessSettings{Allow: []string{"198.51.100.0/24"}, Scope: scope}
+
+		req := httptest.NewRequest("GET", "/db/tasks", nil)
+		req.RemoteAddr = "203.0.113.1:1234"
+		ctx := context.WithValue(req.Context(), middleware.ContextBase, conf)
+		ctx = context.WithValue(ctx, middleware.ContextAuth, model.Auth{Role: role})
+		req = req.WithContext(ctx)
+
+		w := httptest.NewRecorder()
+		middleware.Chain(ok, middleware.RestrictIP(rootOnly)).ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		scope    string
+		rootOnly bool
+		role     int
+		status   int
+	}{
+		{model.IPAccessRoot, true, 0, http.StatusForbidden},
+		{model.IPAccessRoot, false, middleware.RootRole, http.StatusForbidden},
+		{model.IPAccessRoot, false, 50, http.StatusOK},
+		{"", false, 0, http.StatusOK},
+		{model.IPAccessAll, false, 0, http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		if got := call(tc.scope, tc.rootOnly, tc.role); got != tc.status {
+			t.Errorf("scope %q root %t role %d: expected %d got %d", tc.scope, tc.rootOnly, tc.role, tc.status, got)
+		}
+	}
+}
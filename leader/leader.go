@@ -0,0 +1,197 @@
+// Package leader elects the instance running the singleton background
+// workers. The leader holds a lease in the volatile cache and renews it,
+// when it stops renewing another instance takes the lease over once it
+// expires and starts the workers.
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/logger"
+)
+
+// worker runs until its context is cancelled, when the leadership is lost
+type worker struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// Elector campaigns for a lease and runs the registered workers while
+// it's the leader
+type Elector struct {
+	volatile cache.Volatilizer
+	key      string
+	id       string
+	lease    time.Duration
+	log      *logger.Logger
+
+	mu      sync.Mutex
+	workers []worker
+	leading bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New returns an Elector campaigning as the instance id for the lease key,
+// the lease expires when it's not renewed for the lease duration
+func New(volatile cache.Volatilizer, key, id string, lease time.Duration, log *logger.Logger) *Elector {
+	return &Elector{
+		volatile: volatile,
+		key:      key,
+		id:       id,
+		lease:    lease,
+		log:      log,
+	}
+}
+
+// Register adds a worker started when this instance becomes the leader, its
+// context is cancelled when the leadership is lost. A worker registered
+// while leading starts right away.
+func (e *Elector) Register(name string, run func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	w := worker{name: name, run: run}
+	e.workers = append(e.workers, w)
+
+	if e.leading {
+		e.startWorker(w)
+	}
+}
+
+// IsLeader returns true while this instance holds the lease
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.leading
+}
+
+// Start campaigns once then keeps campaigning and renewing the lease in
+// the background until Stop is called. It does nothing when already
+// started.
+func (e *Elector) Start() {
+	e.mu.Lock()
+	if e.stop != nil {
+		e.mu.Unlock()
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	e.stop, e.done = stop, done
+	e.mu.Unlock()
+
+	e.Campaign()
+
+	go func() {
+		defer close(done)
+
+		// renewing three times per lease leaves room for a failed attempt
+		ticker := time.NewTicker(e.lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				e.Campaign()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Campaign renews the lease when leading or tries to acquire it, the
+// workers are started or stopped when the leadership changes
+func (e *Elector) Campaign() {
+	if e.IsLeader() {
+		holder, err := e.volatile.Get(e.key)
+		if err == nil && holder == e.id {
+			if err := e.volatile.Expire(e.key, e.lease); err == nil {
+				return
+			}
+		}
+
+		e.log.Warn().Msgf("instance %s lost the leadership", e.id)
+		e.stepDown()
+		return
+	}
+
+	ok, err := e.volatile.SetNX(e.key, e.id, e.lease)
+	if err != nil {
+		e.log.Error().Err(err).Msg("error campaigning for the leadership")
+		return
+	} else if !ok {
+		return
+	}
+
+	e.log.Info().Msgf("instance %s is the leader", e.id)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.leading = true
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+
+	for _, w := range e.workers {
+		e.startWorker(w)
+	}
+}
+
+// Stop stops campaigning, stops the workers and releases the lease so
+// another instance takes over without waiting for it to expire
+func (e *Elector) Stop() {
+	e.mu.Lock()
+	stop, done := e.stop, e.done
+	e.stop = nil
+	e.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	if !e.IsLeader() {
+		return
+	}
+
+	e.stepDown()
+
+	if holder, err := e.volatile.Get(e.key); err == nil && holder == e.id {
+		if err := e.volatile.Expire(e.key, 0); err != nil {
+			e.log.Error().Err(err).Msg("error releasing the leadership")
+		}
+	}
+}
+
+// stepDown cancels the workers and waits for them to return
+func (e *Elector) stepDown() {
+	e.mu.Lock()
+	e.leading = false
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+	e.mu.Unlock()
+
+	e.wg.Wait()
+}
+
+// startWorker runs a worker until the leadership is lost, the lock must be
+// held
+func (e *Elector) startWorker(w worker) {
+	ctx := e.ctx
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		e.log.Info().Msgf("starting the %s worker", w.name)
+		w.run(ctx)
+	}()
+}
@@ -0,0 +1,100 @@
+package leader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/leader"
+	"github.com/staticbackendhq/core/logger"
+)
+
+const lease = 100 * time.Millisecond
+
+// counter registers a worker counting the running instances
+func counter(e *leader.Elector, running *int32) {
+	e.Register("counter", func(ctx context.Context) {
+		atomic.AddInt32(running, 1)
+		<-ctx.Done()
+		atomic.AddInt32(running, -1)
+	})
+}
+
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	log := logger.Get(config.AppConfig{AppEnv: "dev"})
+	vol := cache.NewDevCache(log)
+
+	var running int32
+	a := leader.New(vol, "leader-test", "a", lease, log)
+	b := leader.New(vol, "leader-test", "b", lease, log)
+	counter(a, &running)
+	counter(b, &running)
+
+	a.Start()
+	b.Start()
+	defer b.Stop()
+
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatal("expected the first instance to be the only leader")
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&running) == 1 }, "expected the worker to run on the leader")
+
+	// the renewed lease outlives its first expiry
+	time.Sleep(2 * lease)
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatal("expected the leader to keep its lease")
+	}
+
+	// the lease is released on stop
+	a.Stop()
+	if a.IsLeader() {
+		t.Fatal("expected the stopped instance to not lead")
+	}
+	waitFor(t, b.IsLeader, "expected the other instance to take over")
+	waitFor(t, func() bool { return atomic.LoadInt32(&running) == 1 }, "expected the worker to run on the new leader only")
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	log := logger.Get(config.AppConfig{AppEnv: "dev"})
+	vol := cache.NewDevCache(log)
+
+	var running int32
+	a := leader.New(vol, "leader-test", "a", lease, log)
+	b := leader.New(vol, "leader-test", "b", lease, log)
+	counter(a, &running)
+
+	// a leader which stops renewing, i.e. a dead instance, loses its lease
+	a.Campaign()
+	if !a.IsLeader() {
+		t.Fatal("expected the first instance to lead")
+	}
+
+	time.Sleep(2 * lease)
+
+	b.Campaign()
+	if !b.IsLeader() {
+		t.Fatal("expected the other instance to lead once the lease expired")
+	}
+
+	a.Campaign()
+	if a.IsLeader() {
+		t.Error("expected the previous leader to step down")
+	} else if n := atomic.LoadInt32(&running); n != 0 {
+		t.Errorf("expected the workers of the previous leader to stop got %d running", n)
+	}
+}
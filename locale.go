@@ -0,0 +1,102 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+// localeHint returns the locale of an email when the recipient has none
+// stored, the requested locale or the preferred one of the Accept-Language
+// header
+func localeHint(r *http.Request, locale string) string {
+	if len(locale) > 0 {
+		return locale
+	}
+
+	if locales := email.ParseAcceptLanguage(r.Header.Get("Accept-Language")); len(locales) > 0 {
+		return locales[0]
+	}
+	return ""
+}
+
+// locale returns the locale of the system emails sent to the current user
+// on GET and sets it on PUT, an empty locale removes it
+func (m *membership) locale(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	mship := backend.Membership(conf)
+
+	switch r.Method {
+	case http.MethodGet:
+		locale, err := mship.GetLocale(auth.UserID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, map[string]string{"locale": locale})
+	case http.MethodPut, http.MethodPost:
+		var data = new(struct {
+			Locale string `json:"locale"`
+		})
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := mship.SetLocale(auth.UserID, data.Locale); errors.Is(err, backend.ErrInvalidLocale) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, true)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// sendVerification emails the "verification" template to the current user
+// with the app's verification link, in the user's locale
+func (m *membership) sendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var data = new(struct {
+		Link   string `json:"link"`
+		Locale string `json:"locale"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if len(data.Link) == 0 {
+		http.Error(w, "the verification link is required", http.StatusBadRequest)
+		return
+	}
+
+	mship := backend.Membership(conf)
+	if err := mship.SendVerificationEmail(auth, data.Link, localeHint(r, data.Locale)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
@@ -0,0 +1,105 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestLocaleHint(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "de;q=0.8, pt-BR")
+
+	if hint := localeHint(req, ""); hint != "pt-br" {
+		t.Errorf("expected the preferred language pt-br got %s", hint)
+	} else if hint := localeHint(req, "fr"); hint != "fr" {
+		t.Errorf("expected the requested locale fr got %s", hint)
+	}
+}
+
+func TestLocalizedVerificationEmail(t *testing.T) {
+	resp := dbReq(t, mship.locale, "PUT", "/me/locale", map[string]string{"locale": "not a locale"})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid locale got %d", resp.StatusCode)
+	}
+
+	resp2 := dbReq(t, mship.locale, "PUT", "/me/locale", map[string]string{"locale": "fr_CA"})
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp2))
+	}
+	defer dbReq(t, mship.locale, "PUT", "/me/locale", map[string]string{"locale": ""})
+
+	resp3 := dbReq(t, mship.locale, "GET", "/me/locale", nil)
+	defer resp3.Body.Close()
+
+	var data map[string]string
+	if resp3.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp3))
+	} else if err := parseBody(resp3.Body, &data); err != nil {
+		t.Fatal(err)
+	} else if data["locale"] != "fr-ca" {
+		t.Errorf("expected the normalized locale fr-ca got %v", data)
+	}
+
+	// sent returns whether the verification email was sent with the subject,
+	// the emails queued in the same instant have no particular order
+	sent := func(subject string) bool {
+		t.Helper()
+
+		resp := dbReq(t, mship.sendVerification, "POST", "/me/verification", map[string]string{"link": "https://app.com/verify?t=1", "locale": "de"})
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatal(GetResponseBody(t, resp))
+		}
+
+		// the app's translations are logged with their own template name
+		msgs, err := backend.DB.ListEmailLog(dbName, model.EmailLogFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, msg := range msgs {
+			if msg.Subject == subject {
+				return true
+			}
+		}
+		return false
+	}
+
+	// the user's stored locale is preferred to the hint
+	if !sent("Vérifiez votre adresse courriel") {
+		t.Error("expected the built-in French translation")
+	}
+
+	// the app overrides the translation
+	conf := model.DatabaseConfig{Name: dbName}
+	tmpl := model.EmailTemplate{
+		Name:     model.EmailTemplateVerification + ".fr",
+		Subject:  "Confirmez votre courriel pour MonApp",
+		TextBody: "[link]",
+	}
+	if _, err := backend.SaveEmailTemplate(conf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteEmailTemplate(dbName, tmpl.Name)
+
+	if !sent(tmpl.Subject) {
+		t.Error("expected the app's translation")
+	}
+
+	// without a stored locale the hint is used
+	resp4 := dbReq(t, mship.locale, "PUT", "/me/locale", map[string]string{"locale": ""})
+	defer resp4.Body.Close()
+
+	if !sent("Bestätigen Sie Ihre E-Mail-Adresse") {
+		t.Error("expected the built-in German translation")
+	}
+}
@@ -31,11 +31,18 @@ func Get(cfg config.AppConfig) *Logger {
 	once.Do(func() {
 		// By default create console writer
 		writers := []io.Writer{zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.Stamp}}
+		if cfg.LogFormat == "json" {
+			writers[0] = os.Stdout
+		}
 
 		if cfg.LogFilename != "" {
 			writers = append(writers, newFileWriter(cfg.LogFilename))
 		}
 
+		if cfg.LogSinkURL != "" {
+			writers = append(writers, NewHTTPSink(cfg.LogSinkURL))
+		}
+
 		if cfg.LogConsoleLevel != "" {
 			level, err := zerolog.ParseLevel(cfg.LogConsoleLevel)
 			if err != nil {
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// The log lines are posted in batches of up to SinkBatchSize lines, at most
+// SinkInterval after the first one was written
+var (
+	SinkBatchSize = 100
+	SinkInterval  = time.Second
+)
+
+// HTTPSink ships the JSON log lines to an external log collector accepting
+// newline-delimited JSON. Lines are dropped when the collector is down so
+// logging never blocks the requests.
+type HTTPSink struct {
+	URL    string
+	client *http.Client
+
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	lines int
+	timer *time.Timer
+}
+
+// NewHTTPSink returns a sink posting the log lines to url
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		URL:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Write buffers a log line, zerolog writes one line per call
+func (s *HTTPSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf.Write(p)
+	s.lines++
+
+	if s.lines >= SinkBatchSize {
+		s.flushLocked()
+	} else if s.timer == nil {
+		s.timer = time.AfterFunc(SinkInterval, s.Flush)
+	}
+	return len(p), nil
+}
+
+// Flush posts the buffered lines
+func (s *HTTPSink) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flushLocked()
+}
+
+func (s *HTTPSink) flushLocked() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+
+	if s.lines == 0 {
+		return
+	}
+
+	body := make([]byte, s.buf.Len())
+	copy(body, s.buf.Bytes())
+	s.buf.Reset()
+	s.lines = 0
+
+	go s.post(body)
+}
+
+func (s *HTTPSink) post(body []byte) {
+	resp, err := s.client.Post(s.URL, "application/x-ndjson", bytes.NewReader(body))
+	if err != nil {
+		// the logger cannot log its own failures
+		fmt.Fprintf(os.Stderr, "unable to ship logs to %s: %v\n", s.URL, err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		fmt.Fprintf(os.Stderr, "unable to ship logs to %s: status %d\n", s.URL, resp.StatusCode)
+	}
+}
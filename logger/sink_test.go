@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPSink(t *testing.T) {
+	lines := make(chan string, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("expected ndjson content type got %s", ct)
+		}
+
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}))
+	defer ts.Close()
+
+	prev := SinkInterval
+	SinkInterval = 10 * time.Millisecond
+	defer func() { SinkInterval = prev }()
+
+	s := NewHTTPSink(ts.URL)
+	s.Write([]byte(`{"message":"one"}` + "\n"))
+	s.Write([]byte(`{"message":"two"}` + "\n"))
+
+	for _, expected := range []string{`{"message":"one"}`, `{"message":"two"}`} {
+		select {
+		case line := <-lines:
+			if line != expected {
+				t.Errorf("expected %s got %s", expected, line)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the log lines were not shipped")
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/staticbackendhq/core/backend"
 	"github.com/staticbackendhq/core/config"
 	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
 )
 
 const (
@@ -43,6 +44,9 @@ func TestMain(m *testing.M) {
 
 	backend.Setup(config.Current)
 
+	// the webhooks are delivered to test servers on a loopback address
+	webhook.AllowPrivateNetworks = true
+
 	db = &Database{cache: backend.Cache, log: backend.Log}
 
 	acct = &accounts{log: backend.Log}
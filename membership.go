@@ -6,6 +6,7 @@ import (
 
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/staticbackendhq/core/backend"
 	"github.com/staticbackendhq/core/internal"
@@ -58,10 +59,18 @@ func (m *membership) login(w http.ResponseWriter, r *http.Request) {
 
 	token, err := mship.Authenticate(l.Email, l.Password)
 	if err != nil {
+		recordAuthEvent(r, conf, model.AuditEvent{
+			Email:  strings.ToLower(l.Email),
+			Type:   model.AuditLoginFailed,
+			Detail: err.Error(),
+		})
+
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
+	m.recordLogin(r, conf, l.Email, "password")
+
 	respond(w, http.StatusOK, token)
 }
 
@@ -86,6 +95,8 @@ func (m *membership) register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	emitUserEventByEmail(conf, model.WebhookUserCreated, strings.ToLower(l.Email))
+
 	respond(w, http.StatusOK, token)
 }
 
@@ -104,8 +115,10 @@ func (m *membership) setResetCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hint := localeHint(r, r.URL.Query().Get("locale"))
+
 	mship := backend.Membership(conf)
-	if err := mship.SetPasswordResetCode(email, code); err != nil {
+	if err := mship.SetPasswordResetCode(email, code, hint); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -136,6 +149,15 @@ func (m *membership) resetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	evt := model.AuditEvent{Email: strings.ToLower(data.Email), Type: model.AuditPasswordReset}
+	if tok, err := backend.DB.FindUserByEmail(conf.Name, evt.Email); err == nil {
+		evt.AccountID = tok.AccountID
+		evt.UserID = tok.ID
+
+		emitUserEvent(conf, model.WebhookPasswordReset, tok)
+	}
+	recordAuthEvent(r, conf, evt)
+
 	respond(w, http.StatusOK, true)
 }
 
@@ -241,6 +263,50 @@ func (m *membership) me(w http.ResponseWriter, r *http.Request) {
 	respond(w, http.StatusOK, auth)
 }
 
+// scopedToken mints a derived session token restricted to read operations
+// and/or specific collections, suitable to embed in a public web page.
+func (m *membership) scopedToken(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var data = new(struct {
+		model.TokenScope
+		// TTL is the validity of the token in seconds
+		TTL int64 `json:"ttl"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ttl := time.Duration(data.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = 12 * time.Hour
+	} else if ttl > 30*24*time.Hour {
+		http.Error(w, "ttl cannot exceed 30 days", http.StatusBadRequest)
+		return
+	}
+
+	tok, err := backend.DB.GetUserByID(conf.Name, auth.AccountID, auth.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	token := fmt.Sprintf("%s|%s", tok.ID, tok.Token)
+
+	jwtBytes, err := backend.GetScopedJWT(token, data.TokenScope, ttl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, string(jwtBytes))
+}
+
 func (m *membership) magicLink(w http.ResponseWriter, r *http.Request) {
 	conf, _, err := middleware.Extract(r, false)
 	if err != nil {
@@ -257,6 +323,12 @@ func (m *membership) magicLink(w http.ResponseWriter, r *http.Request) {
 
 		token, err := mship.ValidateMagicLink(email, code)
 		if err != nil {
+			recordAuthEvent(r, conf, model.AuditEvent{
+				Email:  strings.ToLower(email),
+				Type:   model.AuditLoginFailed,
+				Detail: err.Error(),
+			})
+
 			if strings.Contains(err.Error(), "maximum") {
 				http.Error(w, err.Error(), http.StatusTooManyRequests)
 				return
@@ -266,6 +338,8 @@ func (m *membership) magicLink(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		m.recordLogin(r, conf, email, "magic link")
+
 		respond(w, http.StatusOK, token)
 		return
 	}
@@ -276,6 +350,8 @@ func (m *membership) magicLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	data.Locale = localeHint(r, data.Locale)
+
 	if err := mship.SetupMagicLink(data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -283,3 +359,23 @@ func (m *membership) magicLink(w http.ResponseWriter, r *http.Request) {
 
 	respond(w, http.StatusOK, true)
 }
+
+// recordLogin adds a successful login to the audit log and notifies the
+// app's webhooks
+func (m *membership) recordLogin(r *http.Request, conf model.DatabaseConfig, email, method string) {
+	tok, err := backend.DB.FindUserByEmail(conf.Name, strings.ToLower(email))
+	if err != nil {
+		m.log.Error().Err(err).Msg("unable to find user for login audit event")
+		return
+	}
+
+	emitUserEvent(conf, model.WebhookUserLogin, tok)
+
+	recordAuthEvent(r, conf, model.AuditEvent{
+		AccountID: tok.AccountID,
+		UserID:    tok.ID,
+		Email:     tok.Email,
+		Type:      model.AuditLogin,
+		Detail:    method,
+	})
+}
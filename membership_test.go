@@ -2,10 +2,17 @@ package staticbackend
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
 	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
 )
 
 func TestGetCurrentAuthUser(t *testing.T) {
@@ -63,3 +70,163 @@ func TestMagicLink(t *testing.T) {
 		t.Fatal(GetResponseBody(t, resp2))
 	}
 }
+
+func TestLoginRecordsAuditEvents(t *testing.T) {
+	bad := model.Login{Email: userEmail, Password: "not-the-password"}
+	resp := dbReq(t, mship.login, "POST", "/login", bad)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 got %d", resp.StatusCode)
+	}
+
+	good := model.Login{Email: userEmail, Password: userPassword}
+	resp2 := dbReq(t, mship.login, "POST", "/login", good)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp2))
+	}
+
+	resp3 := dbReq(t, listAuditEvents, "GET", "/sudo/_/audit?type=login_failed", nil, true)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp3))
+	}
+
+	var events []model.AuditEvent
+	if err := parseBody(resp3.Body, &events); err != nil {
+		t.Fatal(err)
+	} else if len(events) == 0 {
+		t.Fatal("expected at least one login_failed event")
+	} else if events[0].Email != userEmail {
+		t.Errorf("expected email to be %s got %s", userEmail, events[0].Email)
+	}
+
+	resp4 := dbReq(t, listAuditEvents, "GET", "/sudo/_/audit?type=login", nil, true)
+	defer resp4.Body.Close()
+
+	events = nil
+	if err := parseBody(resp4.Body, &events); err != nil {
+		t.Fatal(err)
+	} else if len(events) == 0 {
+		t.Fatal("expected at least one login event")
+	} else if len(events[0].UserID) == 0 {
+		t.Errorf("expected login event to have the user id")
+	}
+}
+
+func TestScopedToken(t *testing.T) {
+	scope := model.TokenScope{ReadOnly: true, Collections: []string{"tasks"}}
+
+	resp := dbReq(t, mship.scopedToken, "POST", "/me/token", scope)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var token string
+	if err := parseBody(resp.Body, &token); err != nil {
+		t.Fatal(err)
+	}
+
+	req := func(method, path, body string) int {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("SB-PUBLIC-KEY", pubKey)
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+
+		h := middleware.Chain(
+			http.HandlerFunc(db.dbreq),
+			middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+			middleware.RequireAuth(backend.DB, backend.Cache),
+		)
+		h.ServeHTTP(w, r)
+		return w.Result().StatusCode
+	}
+
+	if status := req("GET", "/db/tasks", ""); status != http.StatusOK {
+		t.Errorf("expected list on scoped collection to return 200 got %d", status)
+	}
+
+	if status := req("POST", "/db/tasks", `{"title": "scoped"}`); status != http.StatusForbidden {
+		t.Errorf("expected write with read-only token to return 403 got %d", status)
+	}
+
+	if status := req("GET", "/db/notscoped", ""); status != http.StatusForbidden {
+		t.Errorf("expected list outside of the scope to return 403 got %d", status)
+	}
+
+	// a scoped token cannot be used after its TTL
+	var pl model.JWTPayload
+	if _, err := jwt.Verify([]byte(adminToken), model.HashSecret, &pl); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := backend.GetScopedJWT(pl.Token, scope, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token = string(expired)
+	if status := req("GET", "/db/tasks", ""); status != http.StatusUnauthorized {
+		t.Errorf("expected an expired scoped token to return 401 got %d", status)
+	}
+}
+
+func TestRegisterEmitsWebhook(t *testing.T) {
+	received := make(chan webhook.Payload, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var pl webhook.Payload
+		if err := parseBody(r.Body, &pl); err != nil {
+			t.Error(err)
+		}
+		received <- pl
+	}))
+	defer ts.Close()
+
+	s := model.AppSettings{
+		Webhooks: []model.WebhookSettings{
+			{URL: ts.URL, Secret: "unit-test", Events: []string{model.WebhookUserCreated}},
+		},
+	}
+
+	resp := dbReq(t, settings, "POST", "/account/settings", s, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	defer func() {
+		resp := dbReq(t, settings, "POST", "/account/settings", model.AppSettings{}, true)
+		defer resp.Body.Close()
+	}()
+
+	l := model.Login{Email: "webhook@test.com", Password: "webhook1234"}
+	resp2 := dbReq(t, mship.register, "POST", "/register", l)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp2))
+	}
+
+	select {
+	case pl := <-received:
+		if pl.Event != model.WebhookUserCreated {
+			t.Errorf("expected event %s got %s", model.WebhookUserCreated, pl.Event)
+		}
+
+		data, ok := pl.Data.(map[string]interface{})
+		if !ok || data["email"] != l.Email {
+			t.Errorf("expected email %s in data got %v", l.Email, pl.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not received")
+	}
+}
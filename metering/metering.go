@@ -0,0 +1,155 @@
+// Package metering buffers the usage of the tenants in memory and writes it
+// to the database in batches.
+package metering
+
+import (
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+// Store saves the usage records, adding their values to the stored ones
+type Store interface {
+	AddUsage(records []model.UsageRecord) error
+}
+
+// Number of buffered records triggering a write before the flush interval
+const batchSize = 500
+
+type usageKey struct {
+	tenantID string
+	dbName   string
+	day      string
+	metric   string
+}
+
+// Meter accumulates the usage per tenant, database, day and metric until
+// it's flushed
+type Meter struct {
+	mu     sync.Mutex
+	counts map[usageKey]int64
+	full   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	now    func() time.Time
+}
+
+// New returns an empty Meter
+func New() *Meter {
+	return &Meter{
+		counts: make(map[usageKey]int64),
+		full:   make(chan struct{}, 1),
+		now:    time.Now,
+	}
+}
+
+// Default is the Meter of the server
+var Default = New()
+
+// Record adds n to a usage metric of the database for the current day
+func Record(conf model.DatabaseConfig, metric string, n int64) {
+	Default.Record(conf, metric, n)
+}
+
+// Record adds n to a usage metric of the database for the current day
+func (m *Meter) Record(conf model.DatabaseConfig, metric string, n int64) {
+	if n <= 0 {
+		return
+	}
+
+	key := usageKey{
+		tenantID: conf.TenantID,
+		dbName:   conf.Name,
+		day:      m.now().UTC().Format("2006-01-02"),
+		metric:   metric,
+	}
+
+	m.mu.Lock()
+	m.counts[key] += n
+	size := len(m.counts)
+	m.mu.Unlock()
+
+	if size >= batchSize {
+		select {
+		case m.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes the buffered usage in one batch. The usage is kept for the
+// next flush when the write fails.
+func (m *Meter) Flush(store Store) error {
+	m.mu.Lock()
+	counts := m.counts
+	m.counts = make(map[usageKey]int64)
+	m.mu.Unlock()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	records := make([]model.UsageRecord, 0, len(counts))
+	for k, v := range counts {
+		records = append(records, model.UsageRecord{
+			TenantID: k.tenantID,
+			DBName:   k.dbName,
+			Day:      k.day,
+			Metric:   k.metric,
+			Value:    v,
+		})
+	}
+
+	if err := store.AddUsage(records); err != nil {
+		m.mu.Lock()
+		for k, v := range counts {
+			m.counts[k] += v
+		}
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Start flushes the usage every interval, or sooner when the buffer is full,
+// until Stop is called. It does nothing when the Meter is already started.
+func (m *Meter) Start(store Store, interval time.Duration, log *logger.Logger) {
+	if m.stop != nil {
+		return
+	}
+
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-m.full:
+			case <-m.stop:
+				return
+			}
+
+			if err := m.Flush(store); err != nil {
+				log.Error().Err(err).Msg("error writing the usage records")
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic flush and writes the remaining usage
+func (m *Meter) Stop(store Store) error {
+	if m.stop != nil {
+		close(m.stop)
+		<-m.done
+		m.stop = nil
+	}
+	return m.Flush(store)
+}
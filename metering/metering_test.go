@@ -0,0 +1,75 @@
+package metering
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+type memStore struct {
+	records []model.UsageRecord
+	err     error
+}
+
+func (s *memStore) AddUsage(records []model.UsageRecord) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func TestMeterFlush(t *testing.T) {
+	m := New()
+	m.now = func() time.Time { return time.Date(2001, 2, 3, 23, 0, 0, 0, time.UTC) }
+
+	conf := model.DatabaseConfig{TenantID: "t1", Name: "db1"}
+	m.Record(conf, model.MeterAPICalls, 1)
+	m.Record(conf, model.MeterAPICalls, 2)
+	m.Record(conf, model.MeterEmails, 1)
+	m.Record(conf, model.MeterEmails, 0)
+
+	// a failed write keeps the usage for the next flush
+	store := &memStore{err: errors.New("unavailable")}
+	if err := m.Flush(store); err == nil {
+		t.Fatal("expected the write error")
+	}
+
+	store.err = nil
+	if err := m.Flush(store); err != nil {
+		t.Fatal(err)
+	} else if len(store.records) != 2 {
+		t.Fatalf("expected 2 records got %v", store.records)
+	}
+
+	for _, u := range store.records {
+		if u.TenantID != "t1" || u.DBName != "db1" || u.Day != "2001-02-03" {
+			t.Errorf("unexpected record %v", u)
+		} else if u.Metric == model.MeterAPICalls && u.Value != 3 {
+			t.Errorf("expected 3 api calls got %d", u.Value)
+		}
+	}
+
+	// the buffer is empty once flushed
+	if err := m.Flush(store); err != nil {
+		t.Fatal(err)
+	} else if len(store.records) != 2 {
+		t.Errorf("expected no new records got %v", store.records)
+	}
+}
+
+func TestMeterStop(t *testing.T) {
+	m := New()
+	store := &memStore{}
+
+	m.Start(store, time.Hour, nil)
+	m.Record(model.DatabaseConfig{TenantID: "t1", Name: "db1"}, model.MeterRealtimeMessages, 5)
+
+	if err := m.Stop(store); err != nil {
+		t.Fatal(err)
+	} else if len(store.records) != 1 || store.records[0].Value != 5 {
+		t.Errorf("expected the usage to be written on stop got %v", store.records)
+	}
+}
@@ -0,0 +1,62 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/metering"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestMeterRequests(t *testing.T) {
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.MeterRequests(),
+	)
+
+	before, err := backend.TenantUsage("", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/db/metered", nil)
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 got %d", w.Code)
+		}
+	}
+
+	if err := metering.Default.Flush(backend.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := backend.TenantUsage("", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	} else if n := after[model.MeterAPICalls] - before[model.MeterAPICalls]; n < 3 {
+		t.Errorf("expected at least 3 metered api calls got %d", n)
+	}
+
+	resp := dbReq(t, appStats, "GET", "/sudo/_/stats?days=7", nil, true)
+	defer resp.Body.Close()
+
+	var stats model.AppStats
+	if err := parseBody(resp.Body, &stats); err != nil {
+		t.Fatal(err)
+	} else if calls := stats.Usage[model.MeterAPICalls]; len(calls) != 7 || calls[6].Value < 3 {
+		t.Errorf("expected 7 days of api calls with today's calls got %v", calls)
+	} else if len(stats.Usage[model.MeterRealtimeMessages]) != 7 {
+		t.Errorf("expected every metric in the usage got %v", stats.Usage)
+	}
+}
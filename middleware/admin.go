@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// RequireAdmin validates the instance's admin token, the admin endpoints
+// are not found when no token is configured.
+//
+// The request must have an HTTP Header of: Authorization: Bearer "admin-token".
+func RequireAdmin(token string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(token) == 0 {
+				http.NotFound(w, r)
+				return
+			}
+
+			key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(key), []byte(token)) != 1 {
+				http.Error(w, "invalid admin token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/analytics"
+	"github.com/staticbackendhq/core/model"
+)
+
+// countryHeaders are the headers set by the CDNs / load balancers with the
+// country of the client
+var countryHeaders = []string{"CF-IPCountry", "CloudFront-Viewer-Country", "X-Country-Code"}
+
+// RequestAnalytics records the endpoint class, status class, latency and
+// country of the requests of the database. Neither the IP nor the user is
+// kept. It must be chained after WithDB.
+func RequestAnalytics() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conf, _, err := Extract(r, false)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+
+			analytics.Record(conf, endpointClass(r.URL.Path), sw.status, time.Since(start), country(r))
+		})
+	}
+}
+
+// endpointClass returns the class of endpoint from the first segment of
+// the path
+func endpointClass(path string) string {
+	switch segment := strings.TrimPrefix(routeOf(path), "/"); segment {
+	case "login", "register", "password", "oauth", "me", "setrole", "email":
+		return model.EndpointAuth
+	case "db", "query", "inc", "search", "newid", "sudo", "sudoquery", "sudolistall":
+		return model.EndpointDatabase
+	case "fn":
+		return model.EndpointFunctions
+	case "postform", "form":
+		return model.EndpointForms
+	case "storage", "sudostorage":
+		return model.EndpointStorage
+	case "sse":
+		return model.EndpointRealtime
+	default:
+		return model.EndpointOther
+	}
+}
+
+// country returns the ISO country code set by a trusted proxy, an empty
+// string when unknown
+func country(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !trustedProxy(host) {
+		return ""
+	}
+
+	for _, h := range countryHeaders {
+		code := strings.ToUpper(strings.TrimSpace(r.Header.Get(h)))
+		if len(code) == 2 && code[0] >= 'A' && code[0] <= 'Z' && code[1] >= 'A' && code[1] <= 'Z' {
+			return code
+		}
+	}
+	return ""
+}
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/staticbackendhq/core/cache"
@@ -16,6 +18,10 @@ const (
 	RootRole = 100
 )
 
+// ErrTokenExpired is returned when a derived token is used outside of its
+// validity period
+var ErrTokenExpired = errors.New("authentication token expired")
+
 // RequireAuth validates that a session token is valid.
 // If not valid a 401 HTTP error is returned.
 //
@@ -59,7 +65,10 @@ func RequireAuth(datastore database.Persister, volatile cache.Volatilizer) Middl
 			ctx := r.Context()
 
 			auth, err := ValidateAuthKey(datastore, volatile, ctx, key)
-			if err != nil {
+			if errors.Is(err, ErrTokenExpired) {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			} else if err != nil {
 				err = fmt.Errorf("error validating auth key: %w", err)
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				return
@@ -81,6 +90,16 @@ func ValidateAuthKey(datastore database.Persister, volatile cache.Volatilizer, c
 		return a, fmt.Errorf("could not verify your authentication token: %s", err.Error())
 	}
 
+	// derived tokens are only valid for the TTL they were issued with
+	if pl.Scope != nil {
+		now := time.Now()
+		if err := jwt.ExpirationTimeValidator(now)(&pl.Payload); err != nil {
+			return a, ErrTokenExpired
+		} else if err := jwt.NotBeforeValidator(now)(&pl.Payload); err != nil {
+			return a, ErrTokenExpired
+		}
+	}
+
 	conf, ok := ctx.Value(ContextBase).(model.DatabaseConfig)
 	if !ok {
 		return a, fmt.Errorf("invalid StaticBackend public token")
@@ -88,6 +107,8 @@ func ValidateAuthKey(datastore database.Persister, volatile cache.Volatilizer, c
 
 	var auth model.Auth
 	if err := volatile.GetTyped(pl.Token, &auth); err == nil {
+		// derived tokens carry their restrictions in the signed payload
+		auth.Scope = pl.Scope
 		return auth, nil
 	}
 
@@ -128,9 +149,26 @@ func ValidateAuthKey(datastore database.Persister, volatile cache.Volatilizer, c
 		return a, err
 	}
 
+	a.Scope = pl.Scope
 	return a, nil
 }
 
+// RequireFullToken rejects derived tokens that have a restricted scope. It
+// must be chained after RequireAuth.
+func RequireFullToken() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth, ok := r.Context().Value(ContextAuth).(model.Auth)
+			if ok && auth.Scope != nil {
+				http.Error(w, "this action cannot be performed with a scoped token", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // RequireRoot validates that the token provided is for a "root" user.
 func RequireRoot(datastore database.Persister, volatile cache.Volatilizer) Middleware {
 	return func(next http.Handler) http.Handler {
@@ -181,18 +219,26 @@ func RequireRoot(datastore database.Persister, volatile cache.Volatilizer) Middl
 				return
 			}
 
-			tok, err := ValidateRootToken(datastore, conf.Name, key)
+			var tok model.User
+			var operator string
+			var err error
+			if strings.HasPrefix(key, model.ImpersonationTokenPrefix) {
+				tok, operator, err = ValidateImpersonationToken(datastore, volatile, conf.Name, key)
+			} else {
+				tok, err = ValidateRootToken(datastore, conf.Name, key)
+			}
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				return
 			}
 
 			a := model.Auth{
-				AccountID: tok.AccountID,
-				UserID:    tok.ID,
-				Email:     tok.Email,
-				Role:      tok.Role,
-				Token:     tok.Token,
+				AccountID:    tok.AccountID,
+				UserID:       tok.ID,
+				Email:        tok.Email,
+				Role:         tok.Role,
+				Token:        tok.Token,
+				Impersonator: operator,
 			}
 
 			ctx = context.WithValue(ctx, ContextAuth, a)
@@ -223,3 +269,20 @@ func ValidateRootToken(datastore database.Persister, base, token string) (model.
 	}
 	return tok, nil
 }
+
+// ValidateImpersonationToken validates a root token minted by an operator
+// for this database and returns its root user and the operator
+func ValidateImpersonationToken(datastore database.Persister, volatile cache.Volatilizer, base, token string) (model.User, string, error) {
+	var imp model.Impersonation
+	if err := volatile.GetTyped(model.ImpersonationKey(token), &imp); err != nil {
+		return model.User{}, "", fmt.Errorf("invalid or expired impersonation token")
+	} else if imp.DBName != base || time.Now().After(imp.Expires) {
+		return model.User{}, "", fmt.Errorf("invalid or expired impersonation token")
+	}
+
+	tok, err := datastore.GetRootForBase(base)
+	if err != nil {
+		return tok, "", err
+	}
+	return tok, imp.Operator, nil
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Endpoint classes of the request body limits
+const (
+	BodyDocuments = "documents"
+	BodyForms     = "forms"
+	BodyFunctions = "functions"
+	BodyUploads   = "uploads"
+)
+
+// BodyLimits are the maximum request body sizes in bytes of the endpoint
+// classes
+var BodyLimits = map[string]int64{
+	BodyDocuments: 4 << 20,
+	BodyForms:     10 << 20,
+	BodyFunctions: 2 << 20,
+	BodyUploads:   150 << 20,
+}
+
+// bodyClasses are the path prefixes of the endpoints not in the documents
+// class
+var bodyClasses = []struct {
+	prefix string
+	class  string
+}{
+	{"/storage/upload", BodyUploads},
+	{"/extra/resizeimg", BodyUploads},
+	{"/sudo/_/import", BodyUploads},
+	{"/postform/", BodyForms},
+	{"/fn/", BodyFunctions},
+	{"/ui/fn/", BodyFunctions},
+}
+
+// BodyClass returns the endpoint class of a path, documents when it's not a
+// form, function or upload endpoint
+func BodyClass(path string) string {
+	for _, bc := range bodyClasses {
+		if strings.HasPrefix(path, bc.prefix) {
+			return bc.class
+		}
+	}
+	return BodyDocuments
+}
+
+// LimitBody limits the request body to the size of its endpoint class
+// before it's parsed. Requests announcing a larger Content-Length are
+// rejected with a 413, the others fail to read past the limit.
+func LimitBody() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit, ok := BodyLimits[BodyClass(r.URL.Path)]
+			if !ok || limit <= 0 || r.Body == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > limit {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/captcha"
+	"github.com/staticbackendhq/core/model"
+)
+
+// Endpoints that can be protected by a captcha verification
+const (
+	CaptchaOnRegister      = "register"
+	CaptchaOnLogin         = "login"
+	CaptchaOnPasswordReset = "password-reset"
+	CaptchaOnForms         = "forms"
+)
+
+// RequireCaptcha validates the "SB-CAPTCHA-TOKEN" header when the database
+// settings requires a verification for this endpoint. It must be chained
+// after WithDB.
+//
+// For the proof-of-work provider the token has the form "challenge:nonce"
+// where the challenge was issued via the /captcha/challenge endpoint.
+func RequireCaptcha(volatile cache.Volatilizer, endpoint string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conf, _, err := Extract(r, false)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			cs := conf.Settings.Captcha
+			if !captchaRequired(cs, endpoint) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := r.Header.Get("SB-CAPTCHA-TOKEN")
+			if err := VerifyCaptcha(volatile, cs, token, ClientIP(r)); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// VerifyCaptcha validates a captcha token with the database's provider
+func VerifyCaptcha(volatile cache.Volatilizer, cs model.CaptchaSettings, token, remoteIP string) error {
+	if cs.Provider == model.CaptchaPoW {
+		return verifyPoW(volatile, cs, token)
+	}
+	return captcha.Verify(cs.Provider, cs.SecretKey, token, remoteIP)
+}
+
+func captchaRequired(cs model.CaptchaSettings, endpoint string) bool {
+	if !cs.Enabled() {
+		return false
+	}
+
+	switch endpoint {
+	case CaptchaOnRegister:
+		return cs.OnRegister
+	case CaptchaOnLogin:
+		return cs.OnLogin
+	case CaptchaOnPasswordReset:
+		return cs.OnPasswordReset
+	case CaptchaOnForms:
+		return cs.OnForms
+	}
+	return false
+}
+
+func verifyPoW(volatile cache.Volatilizer, cs model.CaptchaSettings, token string) error {
+	parts := strings.SplitN(token, ":", 2)
+	if len(parts) != 2 {
+		return captcha.ErrInvalid
+	}
+
+	challenge, nonce := parts[0], parts[1]
+
+	key := "pow-" + challenge
+	state, err := volatile.Get(key)
+	if err != nil || state != "pending" {
+		return captcha.ErrInvalid
+	}
+
+	// a challenge can only be used once, claiming it with SetNX is atomic
+	// so concurrent requests cannot replay the same solution
+	claimed, err := volatile.SetNX("pow-used-"+challenge, "1", captcha.ChallengeTTL)
+	if err != nil || !claimed {
+		return captcha.ErrInvalid
+	}
+
+	if err := volatile.Expire(key, 0); err != nil {
+		return err
+	}
+
+	difficulty := cs.Difficulty
+	if difficulty <= 0 {
+		difficulty = captcha.DefaultDifficulty
+	}
+
+	if !captcha.ValidProof(challenge, nonce, difficulty) {
+		return captcha.ErrInvalid
+	}
+	return nil
+}
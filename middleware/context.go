@@ -15,6 +15,9 @@ type ContextKey int
 const (
 	ContextAuth ContextKey = iota
 	ContextBase
+	ContextRequestID
+	ContextAPIVersion
+	ContextDomain
 )
 
 // Extract extracts the DatabaseConfig and Auth for the request
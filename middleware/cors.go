@@ -3,9 +3,38 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"sync"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+var (
+	corsMu sync.RWMutex
+	// instanceCORS are the origins allowed on the whole instance, any
+	// origin when empty
+	instanceCORS model.CORSSettings
 )
 
+// SetCorsOrigins replaces the origins allowed to call the API from a
+// browser, an empty list allows any origin
+func SetCorsOrigins(origins []string) {
+	corsMu.Lock()
+	defer corsMu.Unlock()
+
+	instanceCORS = model.CORSSettings{AllowedOrigins: origins}
+}
+
+// allowsOrigin returns true if the instance allows the origin
+func allowsOrigin(origin string) bool {
+	corsMu.RLock()
+	defer corsMu.RUnlock()
+
+	return instanceCORS.AllowsOrigin(origin)
+}
+
 // Cors enables calls via remote origin to handle external JavaScript calls mainly.
+// The origins the instance does not allow get no CORS headers and the
+// browsers refuse their requests.
 func Cors() Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -22,6 +51,13 @@ func Cors() Middleware {
 			if origin == "" {
 				next.ServeHTTP(w, r)
 				return
+			} else if !allowsOrigin(origin) {
+				if r.Method == "OPTIONS" {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
 			}
 
 			headers.Set("Access-Control-Allow-Origin", origin)
@@ -30,6 +66,7 @@ func Cors() Middleware {
 			headers.Set("Access-Control-Allow-Methods", strings.ToUpper(r.Header.Get("Access-Control-Request-Method")))
 
 			headers.Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+			headers.Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, SB-RateLimit-Limit, SB-RateLimit-Usage, Retry-After, Location, Upload-Offset, Upload-Length, Tus-Resumable, ETag, Link, Idempotent-Replayed, SB-API-Version")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -39,3 +76,44 @@ func Cors() Middleware {
 		})
 	}
 }
+
+// TenantCors enforces the database's CORS settings on browser requests. The
+// preflight requests do not carry the public key and are answered by Cors,
+// the actual request is rejected when its origin or method is not allowed.
+// It must be chained after WithDB.
+func TenantCors() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			conf, _, err := Extract(r, false)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			cs := conf.Settings.CORS
+			if !cs.AllowsOrigin(origin) {
+				w.Header().Del("Access-Control-Allow-Origin")
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			} else if !cs.AllowsMethod(r.Method) {
+				http.Error(w, "method not allowed for cross-origin requests", http.StatusForbidden)
+				return
+			}
+
+			if len(cs.AllowedMethods) > 0 {
+				w.Header().Set("Access-Control-Allow-Methods", strings.ToUpper(strings.Join(cs.AllowedMethods, ", ")))
+			}
+			if len(cs.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(cs.AllowedHeaders, ", "))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/database"
+	"github.com/staticbackendhq/core/model"
+)
+
+// domainCacheTTL is how long the custom domain of a hostname, or its
+// absence, is cached
+const domainCacheTTL = 5 * time.Minute
+
+// domainPaths are the public endpoints of an app served on its custom
+// domains: the functions, forms and files
+var domainPaths = []string{"/fn/exec/", "/postform/", "/storage/download"}
+
+// CustomDomains serves the requests made to the custom domain of an app
+// with its public key. Only its public endpoints are served on its verified
+// domains, the requests to the appHost and unknown hosts are not changed.
+func CustomDomains(datastore database.Persister, volatile cache.Volatilizer, appHost string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := requestHost(r)
+			if host == appHost || host == "localhost" || net.ParseIP(host) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// the unknown hosts are cached too so the other hostnames of
+			// the instance are not looked up on each request
+			var d model.Domain
+			key := model.DomainKey(host)
+			if err := volatile.GetTyped(key, &d); err != nil {
+				d, _ = datastore.FindDomain(host)
+				if err := volatile.SetTyped(key, d); err == nil {
+					volatile.Expire(key, domainCacheTTL)
+				}
+			}
+
+			if len(d.ID) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			} else if !d.Verified {
+				http.Error(w, "this domain has not been verified", http.StatusNotFound)
+				return
+			}
+
+			// the version prefix is removed afterward
+			_, p, _ := splitVersion(r.URL.Path)
+			if !isDomainPath(p) {
+				http.NotFound(w, r)
+				return
+			}
+
+			// the public key of another app is ignored
+			r.Header.Set("SB-PUBLIC-KEY", d.BaseID)
+
+			ctx := context.WithValue(r.Context(), ContextDomain, d)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// CustomDomain returns the custom domain the request was made to
+func CustomDomain(r *http.Request) (model.Domain, bool) {
+	d, ok := r.Context().Value(ContextDomain).(model.Domain)
+	return d, ok
+}
+
+// requestHost returns the lowercase hostname of the request without its
+// port
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+func isDomainPath(p string) bool {
+	for _, prefix := range domainPaths {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
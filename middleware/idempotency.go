@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+)
+
+// IdempotencyHeader is the header carrying the client's idempotency key
+const IdempotencyHeader = "Idempotency-Key"
+
+// ReplayedHeader is set on the responses replayed from an idempotency key
+const ReplayedHeader = "Idempotent-Replayed"
+
+var (
+	// IdempotencyTTL is how long the first response of a key is replayed
+	IdempotencyTTL = 24 * time.Hour
+	// idempotencyLock is how long a key is locked while its first request
+	// is processed
+	idempotencyLock = time.Minute
+)
+
+// maxIdempotentBody is the largest response stored for a key, larger
+// responses are not replayed
+const maxIdempotentBody = 1 << 20
+
+// replayedHeaders are the response headers stored with the body
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+type idempotentResponse struct {
+	Fingerprint string            `json:"fingerprint"`
+	Status      int               `json:"status"`
+	Headers     map[string]string `json:"headers"`
+	Body        []byte            `json:"body"`
+}
+
+// Idempotency replays the first response of the create, update and delete
+// requests carrying an Idempotency-Key header so a client's retries are not
+// applied twice. A key is scoped to the database and the caller, reusing it
+// for a different request is refused with a 422 and retrying while the first
+// request is processed with a 409. The server errors are not stored so the
+// request can be retried. It must be chained after the auth middleware.
+func Idempotency(volatile cache.Volatilizer) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyHeader)
+			if len(key) == 0 || !idempotentMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !validIdempotencyKey(key) {
+				http.Error(w, "invalid Idempotency-Key, it must be 1 to 255 printable characters", http.StatusBadRequest)
+				return
+			}
+
+			conf, auth, err := Extract(r, false)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := requestFingerprint(r, body)
+			cacheKey := "idem:" + conf.ID + ":" + auth.AccountID + ":" + auth.UserID + ":" + key
+
+			var stored idempotentResponse
+			if err := volatile.GetTyped(cacheKey, &stored); err == nil {
+				replay(w, stored, fingerprint)
+				return
+			}
+
+			locked, err := volatile.SetNX(cacheKey+":lock", "1", idempotencyLock)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			} else if !locked {
+				// the first request might have completed in between
+				if err := volatile.GetTyped(cacheKey, &stored); err == nil {
+					replay(w, stored, fingerprint)
+					return
+				}
+				http.Error(w, "a request with this Idempotency-Key is in progress", http.StatusConflict)
+				return
+			}
+
+			rec := &recordWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError || rec.overflow {
+				// releases the key so the request can be retried
+				volatile.Expire(cacheKey+":lock", 0)
+				return
+			}
+
+			stored = idempotentResponse{
+				Fingerprint: fingerprint,
+				Status:      rec.status,
+				Headers:     make(map[string]string),
+				Body:        rec.body.Bytes(),
+			}
+			for _, h := range replayedHeaders {
+				if v := w.Header().Get(h); len(v) > 0 {
+					stored.Headers[h] = v
+				}
+			}
+
+			if err := volatile.SetTyped(cacheKey, stored); err != nil {
+				volatile.Expire(cacheKey+":lock", 0)
+				return
+			}
+			volatile.Expire(cacheKey, IdempotencyTTL)
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, stored idempotentResponse, fingerprint string) {
+	if stored.Fingerprint != fingerprint {
+		http.Error(w, "this Idempotency-Key was used for a different request", http.StatusUnprocessableEntity)
+		return
+	}
+
+	for h, v := range stored.Headers {
+		w.Header().Set(h, v)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > 255 {
+		return false
+	}
+
+	for _, c := range key {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestFingerprint identifies a request by its method, URL and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordWriter writes the response while keeping a copy of it
+type recordWriter struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (rw *recordWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordWriter) Write(b []byte) (int, error) {
+	if !rw.overflow {
+		if rw.body.Len()+len(b) > maxIdempotentBody {
+			rw.overflow = true
+			rw.body.Reset()
+		} else {
+			rw.body.Write(b)
+		}
+	}
+	return rw.ResponseWriter.Write(b)
+}
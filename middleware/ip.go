@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// TrustedProxies are the proxies / load balancers whose X-Forwarded-For
+// header is trusted, the loopback and private ranges by default
+var TrustedProxies = mustParseRanges("127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "::1/128", "fc00::/7")
+
+// SetTrustedProxies replaces the trusted proxies by CIDR ranges or single
+// IPs
+func SetTrustedProxies(ranges []string) error {
+	var proxies []*net.IPNet
+	for _, r := range ranges {
+		ipnet, err := model.ParseIPRange(strings.TrimSpace(r))
+		if err != nil {
+			return err
+		}
+		proxies = append(proxies, ipnet)
+	}
+
+	TrustedProxies = proxies
+	return nil
+}
+
+func mustParseRanges(ranges ...string) []*net.IPNet {
+	var list []*net.IPNet
+	for _, r := range ranges {
+		ipnet, err := model.ParseIPRange(r)
+		if err != nil {
+			panic(err)
+		}
+		list = append(list, ipnet)
+	}
+	return list
+}
+
+func trustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, ipnet := range TrustedProxies {
+		if ipnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the originating IP of the request. The X-Forwarded-For
+// header is only used when the request comes from a trusted proxy, the
+// client is the last address that is not a trusted proxy so it cannot be
+// spoofed by the client.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	fwd := r.Header.Get("X-Forwarded-For")
+	if len(fwd) == 0 || !trustedProxy(host) {
+		return host
+	}
+
+	hops := strings.Split(fwd, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(hops[i])
+		if !trustedProxy(ip) {
+			return ip
+		}
+		host = ip
+	}
+	return host
+}
+
+// RestrictIP refuses the requests from the IPs not allowed by the
+// database's IP access settings. The root endpoints and the requests made
+// with a root token are restricted, the others only when the scope is all.
+// It must be chained after WithDB and the auth middleware if any.
+func RestrictIP(rootOnly bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conf, auth, err := Extract(r, false)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			ipa := conf.Settings.IPAccess
+			restricted := rootOnly || auth.Role >= RootRole || ipa.Scope == model.IPAccessAll
+			if restricted && !ipa.Allows(ClientIP(r)) {
+				http.Error(w, "your IP address is not allowed", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/staticbackendhq/core/metering"
+	"github.com/staticbackendhq/core/model"
+)
+
+// MeterRequests counts the API calls of the database. It must be chained
+// after WithDB.
+func MeterRequests() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if conf, _, err := Extract(r, false); err == nil {
+				metering.Record(conf, model.MeterAPICalls, 1)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
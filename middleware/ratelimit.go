@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/database"
+	"github.com/staticbackendhq/core/model"
+)
+
+// RateLimits is the number of requests per minute allowed for a public key
+// based on the tenant's plan, the route classes scale it by their factor
+var RateLimits = map[int]int64{
+	model.PlanFree:     60,
+	model.PlanIdea:     300,
+	model.PleanLaunch:  600,
+	model.PlanTraction: 1200,
+	model.PlanGrowth:   3000,
+}
+
+// Route classes of the rate limit policies
+const (
+	RouteAuth  = "auth"
+	RouteWrite = "write"
+	RouteRead  = "read"
+)
+
+// RateLimitPolicy is the limit of a route class as a factor of the plan's
+// limit. PerIP counts the requests of each client IP separately instead of
+// the public key as a whole.
+type RateLimitPolicy struct {
+	Factor float64
+	PerIP  bool
+}
+
+// RateLimitPolicies are the policies of the route classes, the auth
+// endpoints are the strictest and the reads the loosest
+var RateLimitPolicies = map[string]RateLimitPolicy{
+	RouteAuth:  {Factor: 0.1, PerIP: true},
+	RouteWrite: {Factor: 1},
+	RouteRead:  {Factor: 2},
+}
+
+// RateLimit limits the number of requests per minute a public key can make
+// based on the tenant's plan, the route class is read for GET requests and
+// queries and write otherwise. It must be chained after WithDB.
+func RateLimit(datastore database.Persister, volatile cache.Volatilizer) Middleware {
+	return rateLimit(datastore, volatile, routeClass)
+}
+
+// RateLimitRoute limits the requests of a route class, see RateLimit
+func RateLimitRoute(class string, datastore database.Persister, volatile cache.Volatilizer) Middleware {
+	return rateLimit(datastore, volatile, func(*http.Request) string { return class })
+}
+
+// routeClass returns read for the requests not changing data
+func routeClass(r *http.Request) string {
+	switch {
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		return RouteRead
+	case strings.HasPrefix(r.URL.Path, "/query/"), r.URL.Path == "/search":
+		return RouteRead
+	}
+	return RouteWrite
+}
+
+// limitsMu guards RateLimits and RateLimitPolicies once the server is
+// running, they are replaced by SetRateLimits on configuration reloads
+var limitsMu sync.RWMutex
+
+// SetRateLimits replaces the requests per minute of the plans and the
+// factors of the route classes that are set, the others are kept
+func SetRateLimits(limits map[int]int64, factors map[string]float64) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+
+	for plan, rpm := range limits {
+		RateLimits[plan] = rpm
+	}
+	for class, factor := range factors {
+		policy := RateLimitPolicies[class]
+		policy.Factor = factor
+		RateLimitPolicies[class] = policy
+	}
+}
+
+// RouteLimit returns the requests per minute allowed for a route class, the
+// database's settings can lower the plan's limit
+func RouteLimit(plan int, class string, settings model.RateLimitSettings) int64 {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+
+	base, ok := RateLimits[plan]
+	if !ok {
+		base = RateLimits[model.PlanFree]
+	}
+
+	policy, ok := RateLimitPolicies[class]
+	if !ok {
+		policy = RateLimitPolicies[RouteWrite]
+	}
+
+	limit := int64(float64(base) * policy.Factor)
+	if limit < 1 {
+		limit = 1
+	}
+
+	if override := settings.Limit(class); override > 0 && override < limit {
+		limit = override
+	}
+	return limit
+}
+
+// rateLimit limits the requests of the route class returned by classOf.
+// The current usage is returned in the standard RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers as well as
+// "SB-RateLimit-Limit" and "SB-RateLimit-Usage". When the limit is reached
+// a 429 is returned with a Retry-After header.
+func rateLimit(datastore database.Persister, volatile cache.Volatilizer, classOf func(*http.Request) string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conf, _, err := Extract(r, false)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			plan, err := TenantPlan(datastore, volatile, conf.TenantID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			class := classOf(r)
+			limit := RouteLimit(plan, class, conf.Settings.RateLimits)
+
+			now := time.Now()
+			window := now.Truncate(time.Minute)
+			key := fmt.Sprintf("rl-%s-%s-%d", class, conf.ID, window.Unix())
+			limitsMu.RLock()
+			perIP := RateLimitPolicies[class].PerIP
+			limitsMu.RUnlock()
+
+			if perIP {
+				key = fmt.Sprintf("rl-%s-%s-%s-%d", class, conf.ID, ClientIP(r), window.Unix())
+			}
+
+			n, err := volatile.Inc(key, 1)
+			if err != nil {
+				// the rate limiter should not take the API down
+				next.ServeHTTP(w, r)
+				return
+			} else if n == 1 {
+				if err := volatile.Expire(key, time.Minute); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+
+			remaining := limit - n
+			if remaining < 0 {
+				remaining = 0
+			}
+			reset := strconv.Itoa(int(window.Add(time.Minute).Sub(now).Seconds()) + 1)
+
+			w.Header().Set("RateLimit-Limit", strconv.FormatInt(limit, 10))
+			w.Header().Set("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+			w.Header().Set("RateLimit-Reset", reset)
+			w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=60", limit))
+			w.Header().Set("SB-RateLimit-Limit", strconv.FormatInt(limit, 10))
+			w.Header().Set("SB-RateLimit-Usage", strconv.FormatInt(n, 10))
+
+			if n > limit {
+				w.Header().Set("Retry-After", reset)
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TenantPlan returns the plan of a tenant, it's cached to prevent a database
+// round-trip on each request
+func TenantPlan(datastore database.Persister, volatile cache.Volatilizer, tenantID string) (int, error) {
+	key := "plan:" + tenantID
+
+	if val, err := volatile.Get(key); err == nil {
+		if plan, err := strconv.Atoi(val); err == nil {
+			return plan, nil
+		}
+	}
+
+	cus, err := datastore.FindTenant(tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("error finding tenant: %w", err)
+	}
+
+	if err := CacheTenantPlan(volatile, tenantID, cus.Plan); err != nil {
+		return 0, err
+	}
+	return cus.Plan, nil
+}
+
+// CacheTenantPlan refreshes the cached plan of a tenant once changed
+func CacheTenantPlan(volatile cache.Volatilizer, tenantID string, plan int) error {
+	return volatile.Set("plan:"+tenantID, strconv.Itoa(plan))
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// RegionHeader is the response header carrying the region of a database
+// refused by an instance of another region
+const RegionHeader = "SB-Region"
+
+var (
+	// Region is the instance's region, the databases of the other regions
+	// are not served
+	Region string
+	// RegionURLs are the URLs of the deployments of each region
+	RegionURLs = make(map[string]string)
+)
+
+// SetRegions sets the instance's region and the URLs of the regions from
+// comma-separated name=URL pairs
+func SetRegions(region, urls string) error {
+	list := make(map[string]string)
+	for _, pair := range strings.Split(urls, ",") {
+		if pair = strings.TrimSpace(pair); len(pair) == 0 {
+			continue
+		}
+
+		name, url, ok := strings.Cut(pair, "=")
+		if !ok || len(name) == 0 || len(url) == 0 {
+			return fmt.Errorf("invalid region %q, use name=URL", pair)
+		}
+		list[strings.TrimSpace(name)] = strings.TrimSpace(url)
+	}
+
+	if _, ok := list[region]; len(list) > 0 && !ok {
+		return fmt.Errorf("the region %s of the instance is not one of the regions", region)
+	}
+
+	Region = region
+	RegionURLs = list
+	return nil
+}
+
+// InRegion returns whether the data of a database is kept in the instance's
+// region, the databases without a region are kept where they were created
+func InRegion(conf model.DatabaseConfig) bool {
+	return len(Region) == 0 || len(conf.Region) == 0 || conf.Region == Region
+}
+
+// Misdirected refuses a request for a database of another region and points
+// to the URL of its region
+func Misdirected(w http.ResponseWriter, region string) {
+	w.Header().Set(RegionHeader, region)
+
+	msg := fmt.Sprintf("this data is kept in the %s region", region)
+	if url, ok := RegionURLs[region]; ok {
+		msg += ", use " + url
+	}
+	http.Error(w, msg, http.StatusMisdirectedRequest)
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/staticbackendhq/core/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header carrying the correlation ID of a request
+const RequestIDHeader = "X-Request-ID"
+
+// quietPaths are the probes logged at the debug level
+var quietPaths = map[string]bool{
+	"/ping":    true,
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// RequestLogger assigns a correlation ID to each request, the caller's
+// X-Request-ID when valid, and logs the request once completed with its
+// status, duration and the error of server errors.
+func RequestLogger(log *logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			ctx := context.WithValue(r.Context(), ContextRequestID, id)
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			var evt *zerolog.Event
+			switch {
+			case sw.status >= http.StatusInternalServerError:
+				evt = log.Error().Str("error", strings.TrimSpace(string(sw.errBody)))
+			case quietPaths[r.URL.Path]:
+				evt = log.Debug()
+			default:
+				evt = log.Info()
+			}
+
+			if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+				evt = evt.Str("traceId", sc.TraceID().String())
+			}
+
+			evt.Str("requestId", id).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", sw.status).
+				Dur("duration", time.Since(start)).
+				Msg("request")
+		})
+	}
+}
+
+// RequestID returns the correlation ID of the request
+func RequestID(r *http.Request) string {
+	id, _ := r.Context().Value(ContextRequestID).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts the IDs of up to 128 letters, digits, - and _ so
+// the callers cannot inject content in the logs
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > 128 {
+		return false
+	}
+
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
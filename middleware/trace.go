@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/staticbackendhq/core/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Trace starts a span per request, continuing the trace of the caller's
+// traceparent header. The span is named after the first segment of the path
+// since the others are often IDs.
+func Trace() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			ctx, span := tracing.Start(ctx, r.Method+" "+routeOf(r.URL.Path),
+				attribute.String("http.method", r.Method),
+				attribute.String("http.target", r.URL.Path),
+			)
+			defer span.End()
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.status_code", sw.status))
+			if sw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(sw.status))
+			}
+		})
+	}
+}
+
+func routeOf(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return "/" + segment
+}
+
+// maxErrorBody is the length of the server errors kept by the statusWriter
+const maxErrorBody = 512
+
+// statusWriter records the status code of the response and the start of
+// the server errors' body, flushes and hijacks are passed to the underlying
+// writer for the realtime endpoints
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	errBody []byte
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	sw.status = code
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status >= http.StatusInternalServerError && len(sw.errBody) < maxErrorBody {
+		n := maxErrorBody - len(sw.errBody)
+		if n > len(b) {
+			n = len(b)
+		}
+		sw.errBody = append(sw.errBody, b[:n]...)
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := sw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// API versions, the unversioned routes are the v1 API
+const (
+	APIv1            = 1
+	APIv2            = 2
+	LatestAPIVersion = APIv2
+)
+
+// APIVersionHeader is the response header carrying the API version that
+// served the request
+const APIVersionHeader = "SB-API-Version"
+
+// APIVersions serves the /v1/ and /v2/ prefixed requests without their
+// version prefix so the handlers are shared between versions. The breaking
+// changes of a version are its routes, which take precedence over the next
+// handler, or are checked by the handlers via APIVersion. Requests without
+// a prefix are v1 so existing clients keep working.
+func APIVersions(routes map[int]*http.ServeMux) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version, p, ok := splitVersion(r.URL.Path)
+			if !ok {
+				w.Header().Set(APIVersionHeader, strconv.Itoa(APIv1))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if version < APIv1 || version > LatestAPIVersion {
+				http.Error(w, "unsupported API version", http.StatusNotFound)
+				return
+			}
+
+			w.Header().Set(APIVersionHeader, strconv.Itoa(version))
+
+			ctx := context.WithValue(r.Context(), ContextAPIVersion, version)
+			r = r.WithContext(ctx)
+
+			u := *r.URL
+			u.Path = p
+			u.RawPath = ""
+			r.URL = &u
+
+			if mux, ok := routes[version]; ok {
+				if h, pattern := mux.Handler(r); len(pattern) > 0 {
+					h.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// APIVersion returns the API version of the request
+func APIVersion(r *http.Request) int {
+	if v, ok := r.Context().Value(ContextAPIVersion).(int); ok {
+		return v
+	}
+	return APIv1
+}
+
+// splitVersion returns the version and the path without its /vN prefix
+func splitVersion(path string) (int, string, bool) {
+	if !strings.HasPrefix(path, "/v") {
+		return 0, path, false
+	}
+
+	prefix, rest, _ := strings.Cut(path[2:], "/")
+	version, err := strconv.Atoi(prefix)
+	if err != nil || len(prefix) == 0 || prefix[0] == '+' || prefix[0] == '-' {
+		return 0, path, false
+	}
+	return version, "/" + rest, true
+}
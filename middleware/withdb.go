@@ -42,6 +42,11 @@ func WithDB(datastore database.Persister, volatile cache.Volatilizer, g BillingP
 
 			var conf model.DatabaseConfig
 			if err := volatile.GetTyped(key, &conf); err == nil {
+				if conf.IsSuspended() {
+					suspended(w, conf)
+					return
+				}
+
 				ctx = context.WithValue(ctx, ContextBase, conf)
 			} else {
 				// let's try to see if they are allow to use a database
@@ -72,10 +77,32 @@ func WithDB(datastore database.Persister, volatile cache.Volatilizer, g BillingP
 					return
 				}
 
+				if conf.IsSuspended() {
+					suspended(w, conf)
+					return
+				}
+
 				ctx = context.WithValue(ctx, ContextBase, conf)
 			}
 
+			// the data of another region is not read or written from here
+			if !InRegion(conf) {
+				Misdirected(w, conf.Region)
+				return
+			}
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// suspended refuses the requests of a suspended database, with a 402 when
+// it's for non-payment and a 403 otherwise
+func suspended(w http.ResponseWriter, conf model.DatabaseConfig) {
+	if conf.SuspendedReason == model.SuspendedNonPayment {
+		http.Error(w, "your account is suspended for non-payment.\n\nContact us here: support@staticbackend.com", http.StatusPaymentRequired)
+		return
+	}
+
+	http.Error(w, "your account is suspended.\n\nContact us here: support@staticbackend.com", http.StatusForbidden)
+}
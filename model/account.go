@@ -17,12 +17,44 @@ import (
 
 // Auth represents an authenticated user.
 type Auth struct {
-	AccountID string `json:"accountId"`
-	UserID    string `json:"userId"`
-	Email     string `json:"email"`
-	Role      int    `json:"role"`
-	Token     string `json:"-"`
-	Plan      int    `json:"-"`
+	AccountID string      `json:"accountId"`
+	UserID    string      `json:"userId"`
+	Email     string      `json:"email"`
+	Role      int         `json:"role"`
+	Token     string      `json:"-"`
+	Plan      int         `json:"-"`
+	Scope     *TokenScope `json:"scope,omitempty"`
+	// Impersonator is the operator using an impersonation root token
+	Impersonator string `json:"impersonator,omitempty"`
+}
+
+// TokenScope restricts a derived session token to read operations and/or a
+// list of collections
+type TokenScope struct {
+	ReadOnly    bool     `json:"readOnly"`
+	Collections []string `json:"collections"`
+}
+
+// CanRead returns true if the token scope allows reading this collection
+func (auth Auth) CanRead(col string) bool {
+	if auth.Scope == nil || len(auth.Scope.Collections) == 0 {
+		return true
+	}
+
+	for _, c := range auth.Scope.Collections {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// CanWrite returns true if the token scope allows writing to this collection
+func (auth Auth) CanWrite(col string) bool {
+	if auth.Scope != nil && auth.Scope.ReadOnly {
+		return false
+	}
+	return auth.CanRead(col)
 }
 
 func (auth Auth) ReconstructToken() string {
@@ -35,7 +67,8 @@ func (auth Auth) ReconstructToken() string {
 // JWTPayload contains the current user token
 type JWTPayload struct {
 	jwt.Payload
-	Token string `json:"token,omitempty"`
+	Token string      `json:"token,omitempty"`
+	Scope *TokenScope `json:"scope,omitempty"`
 }
 
 type Account struct {
@@ -0,0 +1,58 @@
+package model
+
+// Endpoint classes of the request analytics
+const (
+	EndpointAuth      = "auth"
+	EndpointDatabase  = "database"
+	EndpointFunctions = "functions"
+	EndpointForms     = "forms"
+	EndpointStorage   = "storage"
+	EndpointRealtime  = "realtime"
+	EndpointOther     = "other"
+)
+
+// RequestRollup aggregates the requests made to a database during an hour,
+// formatted as 2006-01-02T15 (UTC), by endpoint class, status class (200,
+// 300, 400 or 500) and client country, empty when unknown. Nothing
+// identifies the clients or the requested documents.
+type RequestRollup struct {
+	TenantID    string `json:"tenantId"`
+	DBName      string `json:"dbName"`
+	Hour        string `json:"hour"`
+	Endpoint    string `json:"endpoint"`
+	Status      int    `json:"status"`
+	Country     string `json:"country"`
+	Count       int64  `json:"count"`
+	TotalMillis int64  `json:"totalMs"`
+	MaxMillis   int64  `json:"maxMs"`
+}
+
+// RequestRollupFilter selects the request rollups of a database between two
+// hours included, formatted as 2006-01-02T15
+type RequestRollupFilter struct {
+	DBName string
+	Since  string
+	Until  string
+}
+
+// AnalyticsPoint is the requests of a period, an hour formatted as
+// 2006-01-02T15 or a day formatted as 2006-01-02 (UTC). Errors are the
+// server errors and ClientErrors the 4xx responses.
+type AnalyticsPoint struct {
+	Period       string `json:"period"`
+	Requests     int64  `json:"requests"`
+	ClientErrors int64  `json:"clientErrors"`
+	Errors       int64  `json:"errors"`
+	AvgMillis    int64  `json:"avgMs"`
+	MaxMillis    int64  `json:"maxMs"`
+}
+
+// RequestAnalytics is the time series of the requests of a database per
+// hour or day. Breakdown has a series for each endpoint class, status class
+// or country when grouped by one of them.
+type RequestAnalytics struct {
+	Interval  string                      `json:"interval"`
+	Series    []AnalyticsPoint            `json:"series"`
+	By        string                      `json:"by,omitempty"`
+	Breakdown map[string][]AnalyticsPoint `json:"breakdown,omitempty"`
+}
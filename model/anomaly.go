@@ -0,0 +1,12 @@
+package model
+
+// Anomaly is an unusual spike of a usage metric of a database for a day,
+// Baseline is the metric's daily average over the previous days
+type Anomaly struct {
+	TenantID string  `json:"tenantId"`
+	DBName   string  `json:"dbName"`
+	Metric   string  `json:"metric"`
+	Day      string  `json:"day"`
+	Value    int64   `json:"value"`
+	Baseline float64 `json:"baseline"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// AppEvent is an analytics event sent by the clients of an app, its name
+// and properties are defined by the app. Timestamp is when it happened on
+// the client and Received when the server got it.
+type AppEvent struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	SessionID  string         `json:"sessionId"`
+	Properties map[string]any `json:"properties"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Received   time.Time      `json:"received"`
+}
+
+// AppEventFilter narrows the events returned, empty fields are ignored.
+type AppEventFilter struct {
+	Name      string
+	SessionID string
+	Since     time.Time
+	Until     time.Time
+	Limit     int64
+}
+
+// AppEventRollup is the number of events of a name received during a day,
+// formatted as 2006-01-02 (UTC). The rollups are kept once the events are
+// purged.
+type AppEventRollup struct {
+	Day   string `json:"day"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// AppEventRollupFilter selects the rollups between two days included,
+// formatted as 2006-01-02
+type AppEventRollupFilter struct {
+	Name  string
+	Since string
+	Until string
+}
@@ -0,0 +1,80 @@
+package model
+
+import "time"
+
+// Authentication events recorded in the per-database audit log
+const (
+	AuditLogin         = "login"
+	AuditLoginFailed   = "login_failed"
+	AuditPasswordReset = "password_reset"
+	AuditRoleChanged   = "role_changed"
+	AuditTokenRevoked  = "token_revoked" // root token replaced by an ownership transfer
+	AuditUserDeleted   = "user_deleted"
+	AuditUserPurged    = "user_purged"
+)
+
+// AuditEvent represents an authentication related event, who did it, from
+// where and when.
+type AuditEvent struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"accountId"`
+	UserID    string    `json:"userId"`
+	Email     string    `json:"email"`
+	Type      string    `json:"type"`
+	Detail    string    `json:"detail"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Created   time.Time `json:"created"`
+}
+
+// AuditFilter narrows the audit events returned, empty fields are ignored.
+type AuditFilter struct {
+	AccountID string
+	UserID    string
+	Type      string
+	Since     time.Time
+	Until     time.Time
+	Limit     int64
+}
+
+// Privileged operations recorded in the platform audit log
+const (
+	AdminTenantCreated       = "tenant_created"
+	AdminDatabaseSuspended   = "database_suspended"
+	AdminDatabaseReactivated = "database_reactivated"
+	AdminPlanChanged         = "plan_changed"
+	AdminRootOperation       = "root_operation"
+	AdminFunctionDeployed    = "function_deployed"
+	AdminFlagChanged         = "flag_changed"
+	AdminSecretsRewrapped    = "secrets_rewrapped"
+	AdminImpersonation       = "impersonation"
+	AdminOwnershipTransfer   = "ownership_transfer"
+)
+
+// AdminEvent is a privileged operation recorded in the platform audit log.
+// Actor is who did it, the email of the tenant or root user, "admin" for
+// the admin endpoints and "billing" for the Stripe webhooks. Target is what
+// it was done to, i.e. a function name or a request path.
+type AdminEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Actor     string    `json:"actor"`
+	TenantID  string    `json:"tenantId"`
+	DBName    string    `json:"dbName"`
+	Target    string    `json:"target"`
+	Detail    string    `json:"detail"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Created   time.Time `json:"created"`
+}
+
+// AdminEventFilter narrows the platform audit events returned, empty fields
+// are ignored.
+type AdminEventFilter struct {
+	TenantID string
+	DBName   string
+	Type     string
+	Since    time.Time
+	Until    time.Time
+	Limit    int64
+}
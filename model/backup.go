@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// Backup is an encrypted full export of a database saved to the object
+// storage, TaskID is the backup task which made it, empty when made on
+// demand
+type Backup struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenantId"`
+	DBName    string    `json:"dbName"`
+	TaskID    string    `json:"taskId"`
+	Key       string    `json:"-"`
+	Size      int64     `json:"size"`
+	Documents int64     `json:"documents"`
+	Created   time.Time `json:"created"`
+}
+
+// BackupData is the content of a backup. The documents are by collection
+// and keep the ID of the account owning them.
+type BackupData struct {
+	Version   int                                 `json:"version"`
+	DBName    string                              `json:"dbName"`
+	Created   time.Time                           `json:"created"`
+	Accounts  []BackupAccount                     `json:"accounts"`
+	Documents map[string][]map[string]interface{} `json:"documents"`
+	Forms     map[string][]map[string]interface{} `json:"forms"`
+	Files     []File                              `json:"files"`
+	Functions []ExecData                          `json:"functions"`
+	Tasks     []Task                              `json:"tasks"`
+}
+
+// BackupAccount is an account of a backup with its users
+type BackupAccount struct {
+	ID    string       `json:"id"`
+	Email string       `json:"email"`
+	Users []BackupUser `json:"users"`
+}
+
+// BackupUser is a user of a backup, unlike User its password hash is kept
+type BackupUser struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	Role     int    `json:"role"`
+}
+
+// RestoreReport summarizes a restored backup. The users whose email exists
+// in the database are kept and counted in UsersSkipped, the documents are
+// recreated with new IDs.
+type RestoreReport struct {
+	Users        int            `json:"users"`
+	UsersSkipped int            `json:"usersSkipped"`
+	Documents    map[string]int `json:"documents"`
+	Forms        int            `json:"forms"`
+	Files        int            `json:"files"`
+	Functions    int            `json:"functions"`
+	Tasks        int            `json:"tasks"`
+	Errors       []string       `json:"errors"`
+}
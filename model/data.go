@@ -1,9 +1,12 @@
 package model
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gbrlsnchs/jwt/v3"
@@ -11,19 +14,48 @@ import (
 )
 
 type DatabaseConfig struct {
-	ID               string    `json:"id"`
-	TenantID         string    `json:"customerId"`
-	Name             string    `json:"name"`
-	AllowedDomain    []string  `json:"whitelist"`
-	IsActive         bool      `json:"-"`
-	MonthlySentEmail int       `json:"-"`
-	Created          time.Time `json:"created"`
+	ID               string      `json:"id"`
+	TenantID         string      `json:"customerId"`
+	Name             string      `json:"name"`
+	AllowedDomain    []string    `json:"whitelist"`
+	IsActive         bool        `json:"-"`
+	MonthlySentEmail int         `json:"-"`
+	Created          time.Time   `json:"created"`
+	Settings         AppSettings `json:"settings"`
+	// SuspendedReason is why the database is suspended, empty when it's
+	// not suspended
+	SuspendedReason string `json:"suspendedReason,omitempty"`
+	// Environment is the database's environment, ParentID the database
+	// of the app it's an environment of. They are empty for the app's
+	// production database.
+	Environment string `json:"environment,omitempty"`
+	ParentID    string `json:"parentId,omitempty"`
+	// Region is where the database's data is kept, it's only served by the
+	// instances of this region. Empty when the instance is not part of a
+	// multi-region deployment.
+	Region string `json:"region,omitempty"`
 }
 
+// Suspension reasons of a database
+const (
+	SuspendedNonPayment = "non_payment"
+	SuspendedAbuse      = "abuse"
+)
+
+// IsSuspended returns whether the database is suspended, its API calls are
+// refused and its tasks and functions do not run
+func (c DatabaseConfig) IsSuspended() bool {
+	return len(c.SuspendedReason) > 0
+}
+
+// PagedResult is a page of documents, Next and Prev are the opaque cursors
+// of the adjacent pages, empty when there's none
 type PagedResult struct {
 	Page    int64                    `json:"page"`
 	Size    int64                    `json:"size"`
 	Total   int64                    `json:"total"`
+	Next    string                   `json:"next,omitempty"`
+	Prev    string                   `json:"prev,omitempty"`
 	Results []map[string]interface{} `json:"results"`
 }
 
@@ -57,20 +89,34 @@ const (
 	MsgTypeToken        = "token"
 	MsgTypeJoin         = "join"
 	MsgTypeJoined       = "joined"
+	MsgTypeLeft         = "left"
 	MsgTypePresence     = "presence"
 	MsgTypeChanIn       = "chan_in"
 	MsgTypeChanOut      = "chan_out"
+	MsgTypeEphemeral    = "ephemeral"
+	MsgTypeAck          = "ack"
+	MsgTypeClose        = "close"
 	MsgTypeDBCreated    = "db_created"
 	MsgTypeDBUpdated    = "db_updated"
 	MsgTypeDBDeleted    = "db_deleted"
+	MsgTypeLiveQuery    = "live_query"
+	MsgTypeLiveStop     = "live_query_stop"
+	MsgTypeLiveAdded    = "live_added"
+	MsgTypeLiveChanged  = "live_changed"
+	MsgTypeLiveRemoved  = "live_removed"
 	MsgTypeFunctionCall = "fn_call"
 	MsgTypeHTTPResponse = "http_response"
+	MsgTypeFunctionLog  = "fn_log"
 )
 
 type Command struct {
-	SID           string `json:"sid"`
-	Type          string `json:"type"`
-	Data          string `json:"data"`
+	// ID is optional, when set the client must acknowledge the message
+	ID   string `json:"id,omitempty"`
+	SID  string `json:"sid"`
+	Type string `json:"type"`
+	Data string `json:"data"`
+	// Encoding is set to "base64" when Data is a binary payload
+	Encoding      string `json:"encoding,omitempty"`
 	Channel       string `json:"channel"`
 	Token         string `json:"token"`
 	Auth          Auth   `json:"auth"`
@@ -78,6 +124,115 @@ type Command struct {
 	IsSystemEvent bool   `json:"-"`
 }
 
+// LiveQuery is a collection query a realtime connection subscribes to, it's
+// sent as the Data of a live_query message. The matching documents are sent
+// as live_added, live_changed and live_removed messages on the ID channel.
+type LiveQuery struct {
+	ID         string          `json:"id"`
+	Collection string          `json:"collection"`
+	Filter     [][]interface{} `json:"filter"`
+}
+
+// UserChannelPrefix is the reserved channel namespace to reach all active
+// connections of an account
+const UserChannelPrefix = "user:"
+
+// UserChannel returns the direct messaging channel of an account
+func UserChannel(accountID string) string {
+	return UserChannelPrefix + accountID
+}
+
+// BroadcastChannelPrefix is the reserved channel namespace every connection
+// of a database is subscribed to
+const BroadcastChannelPrefix = "sbapp:"
+
+// BroadcastChannel returns the channel reaching all connections of a database
+func BroadcastChannel(base string) string {
+	return BroadcastChannelPrefix + base
+}
+
+// FunctionLogChannelPrefix is the reserved channel namespace where the
+// function runs of a database are published. Only the root user can join
+// it and they join the channel of their database whatever follows the
+// prefix.
+const FunctionLogChannelPrefix = "sbfn:"
+
+// FunctionLogChannel returns the channel of the function runs of a database
+func FunctionLogChannel(base string) string {
+	return FunctionLogChannelPrefix + base
+}
+
+// ReservedChannel reports whether the channel is in a namespace only the
+// server publishes to: the database, broadcast and function logs channels
+func ReservedChannel(channel string) bool {
+	return strings.HasPrefix(strings.ToLower(channel), "db-") ||
+		strings.HasPrefix(channel, BroadcastChannelPrefix) ||
+		strings.HasPrefix(channel, FunctionLogChannelPrefix)
+}
+
+// PresenceMember is a connection subscribed to a channel
+type PresenceMember struct {
+	SID       string    `json:"sid"`
+	AccountID string    `json:"accountId"`
+	UserID    string    `json:"userId"`
+	Joined    time.Time `json:"joined"`
+}
+
+// ChannelStats is the realtime activity of a channel
+type ChannelStats struct {
+	Channel           string `json:"channel"`
+	Subscribers       int64  `json:"subscribers"`
+	MessagesPerMinute int64  `json:"messagesPerMinute"`
+}
+
+// RealtimeUsage is the realtime activity of a database across all servers
+// with its plan caps (0 is unlimited)
+type RealtimeUsage struct {
+	Connections          int64 `json:"connections"`
+	MaxConnections       int64 `json:"maxConnections"`
+	MessagesPerSecond    int64 `json:"messagesPerSecond"`
+	MaxMessagesPerSecond int64 `json:"maxMessagesPerSecond"`
+	BytesPerMinute       int64 `json:"bytesPerMinute"`
+}
+
+// EncodingBase64 indicates the Command's Data is base64 encoded binary
+const EncodingBase64 = "base64"
+
+// MaxBinaryPayload is the maximum size of a decoded binary payload
+const MaxBinaryPayload = 64 * 1024
+
+// SetBinary sets the Data to a binary payload
+func (msg *Command) SetBinary(b []byte) {
+	msg.Data = base64.StdEncoding.EncodeToString(b)
+	msg.Encoding = EncodingBase64
+}
+
+// Binary returns the decoded binary payload
+func (msg Command) Binary() ([]byte, error) {
+	if msg.Encoding != EncodingBase64 {
+		return nil, errors.New("message does not have a binary payload")
+	}
+	return base64.StdEncoding.DecodeString(msg.Data)
+}
+
+// ValidatePayload makes sure a binary payload is properly encoded and does
+// not exceed MaxBinaryPayload
+func (msg Command) ValidatePayload() error {
+	switch msg.Encoding {
+	case "":
+		return nil
+	case EncodingBase64:
+		b, err := msg.Binary()
+		if err != nil {
+			return fmt.Errorf("invalid base64 payload: %w", err)
+		} else if len(b) > MaxBinaryPayload {
+			return fmt.Errorf("binary payload exceeds %d bytes", MaxBinaryPayload)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported encoding: %s", msg.Encoding)
+}
+
 func (msg Command) IsDBEvent() bool {
 	switch msg.Type {
 	case MsgTypeDBCreated, MsgTypeDBUpdated, MsgTypeDBDeleted:
@@ -10,3 +10,45 @@ func TestCleanCollectionName(t *testing.T) {
 		t.Errorf("expected col to be tasks got %s", col)
 	}
 }
+
+func TestCommandBinaryPayload(t *testing.T) {
+	var msg Command
+	msg.SetBinary([]byte{0, 1, 2, 255})
+
+	if msg.Encoding != EncodingBase64 {
+		t.Fatalf("expected encoding to be %s got %s", EncodingBase64, msg.Encoding)
+	} else if err := msg.ValidatePayload(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := msg.Binary()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(b) != 4 || b[3] != 255 {
+		t.Errorf("expected decoded payload to match got %v", b)
+	}
+
+	msg.SetBinary(make([]byte, MaxBinaryPayload+1))
+	if err := msg.ValidatePayload(); err == nil {
+		t.Error("expected an error for a payload exceeding the maximum size")
+	}
+
+	msg = Command{Data: "not base64!", Encoding: EncodingBase64}
+	if err := msg.ValidatePayload(); err == nil {
+		t.Error("expected an error for an invalid base64 payload")
+	}
+}
+
+func TestReservedChannel(t *testing.T) {
+	for _, ch := range []string{"db-tasks", "DB-tasks", BroadcastChannel("app"), FunctionLogChannel("app")} {
+		if !ReservedChannel(ch) {
+			t.Errorf("expected %s to be reserved", ch)
+		}
+	}
+
+	for _, ch := range []string{"chat", UserChannel("acct-1")} {
+		if ReservedChannel(ch) {
+			t.Errorf("expected %s to not be reserved", ch)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Status of an app deletion
+const (
+	AppDeletionPending   = "pending"
+	AppDeletionCompleted = "completed"
+)
+
+// AppDeletion is the deletion of a database requested by its owner, the
+// database is purged once the grace period ends unless it's cancelled
+type AppDeletion struct {
+	ID        string    `json:"id"`
+	BaseID    string    `json:"baseId"`
+	TenantID  string    `json:"tenantId"`
+	DBName    string    `json:"dbName"`
+	Email     string    `json:"email"`
+	Status    string    `json:"status"`
+	Requested time.Time `json:"requested"`
+	Scheduled time.Time `json:"scheduled"`
+	Completed time.Time `json:"completed"`
+	// Certificate is the signed DeletionCertificate as JSON once completed
+	Certificate string `json:"certificate,omitempty"`
+}
+
+// DeletionCertificate attests the data removed when a database was purged.
+// Signature is the HMAC-SHA256 of the other fields with the app secret.
+type DeletionCertificate struct {
+	DeletionID  string    `json:"deletionId"`
+	BaseID      string    `json:"baseId"`
+	TenantID    string    `json:"tenantId"`
+	DBName      string    `json:"dbName"`
+	Requested   time.Time `json:"requested"`
+	Completed   time.Time `json:"completed"`
+	Collections int       `json:"collections"`
+	Files       int64     `json:"files"`
+	Functions   int       `json:"functions"`
+	Forms       int       `json:"forms"`
+	Tasks       int       `json:"tasks"`
+	Channels    int       `json:"channels"`
+	Signature   string    `json:"signature"`
+}
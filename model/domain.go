@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// DomainVerificationPrefix prefixes the hostname of the DNS TXT record
+// proving the ownership of a custom domain, i.e.
+// _sb-verification.api.example.com
+const DomainVerificationPrefix = "_sb-verification."
+
+// Domain is a custom domain mapped to the public endpoints of an app, it's
+// served once the Token is found in its verification TXT record
+type Domain struct {
+	ID       string    `json:"id"`
+	DBName   string    `json:"dbName"`
+	BaseID   string    `json:"baseId"`
+	Hostname string    `json:"hostname"`
+	Token    string    `json:"token"`
+	Verified bool      `json:"verified"`
+	Created  time.Time `json:"created"`
+}
+
+// VerificationRecord returns the hostname of the domain's verification TXT
+// record
+func (d Domain) VerificationRecord() string {
+	return DomainVerificationPrefix + d.Hostname
+}
+
+// DomainKey returns the cache key of the custom domain of a hostname
+func DomainKey(hostname string) string {
+	return "domain:" + hostname
+}
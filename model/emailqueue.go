@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// Delivery status of a queued email
+const (
+	EmailStatusQueued = "queued"
+	EmailStatusSent   = "sent"
+	EmailStatusFailed = "failed"
+)
+
+// EmailMessage is an email queued for an asynchronous delivery. A queued
+// message is sent at NextAttempt, Attempts and LastError record the failed
+// deliveries until it is sent or failed. ProviderID is the mail provider's
+// message ID of a sent email.
+type EmailMessage struct {
+	ID          string            `json:"id"`
+	From        string            `json:"from"`
+	FromName    string            `json:"fromName"`
+	To          string            `json:"to"`
+	ToName      string            `json:"toName"`
+	ReplyTo     string            `json:"replyTo"`
+	Subject     string            `json:"subject"`
+	Template    string            `json:"template"`
+	HTMLBody    string            `json:"htmlBody"`
+	TextBody    string            `json:"textBody"`
+	Attachments []EmailAttachment `json:"attachments"`
+	Status      string            `json:"status"`
+	Attempts    int               `json:"attempts"`
+	LastError   string            `json:"lastError"`
+	ProviderID  string            `json:"providerId"`
+	NextAttempt time.Time         `json:"nextAttempt"`
+	Created     time.Time         `json:"created"`
+	Updated     time.Time         `json:"updated"`
+}
+
+// EmailLogFilter narrows the emails of the log, empty fields are ignored.
+// To matches the recipient exactly and Query the recipients or subjects
+// containing it (case-insensitive).
+type EmailLogFilter struct {
+	To       string
+	Template string
+	Status   string
+	Query    string
+	Since    time.Time
+	Until    time.Time
+	Limit    int64
+}
+
+// EmailAttachment is a file attached to an email, either its Content,
+// base64 encoded in JSON, or the FileID of a stored file read when the
+// email is sent
+type EmailAttachment struct {
+	FileID      string `json:"fileId,omitempty"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content,omitempty"`
+}
+
+// EmailUsage is the number of emails sent by a tenant in a month, formatted
+// as 2006-01, with its plan's limit
+type EmailUsage struct {
+	Month     string `json:"month"`
+	Sent      int    `json:"sent"`
+	MaxEmails int    `json:"maxEmails"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Types of the email events reported by the mail providers
+const (
+	EmailEventBounce    = "bounce"
+	EmailEventComplaint = "complaint"
+)
+
+// EmailEvent is a bounce or a complaint reported by a mail provider's
+// webhook. Permanent is set for the hard bounces.
+type EmailEvent struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Type      string    `json:"type"`
+	Permanent bool      `json:"permanent"`
+	Provider  string    `json:"provider"`
+	Detail    string    `json:"detail"`
+	Created   time.Time `json:"created"`
+}
+
+// EmailSuppressionManual is the reason of the addresses suppressed through
+// the API
+const EmailSuppressionManual = "manual"
+
+// EmailSuppression is an address no email is sent to anymore, after a hard
+// bounce, a complaint or when added manually
+type EmailSuppression struct {
+	Email    string    `json:"email"`
+	Reason   string    `json:"reason"`
+	Provider string    `json:"provider"`
+	Detail   string    `json:"detail"`
+	Created  time.Time `json:"created"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// The email templates used by the authentication emails. A database
+// overrides them, or one of their translations named like
+// password-reset.fr, by saving a template with the same name.
+const (
+	EmailTemplateMagicLink     = "magic-link"
+	EmailTemplatePasswordReset = "password-reset"
+	EmailTemplateInvite        = "invite"
+	EmailTemplateVerification  = "verification"
+)
+
+// EmailTemplate is a transactional email of a database. The [variable]
+// placeholders of the subject and bodies are replaced when the template is
+// rendered, the Variables must be provided.
+type EmailTemplate struct {
+	Name      string    `json:"name"`
+	Subject   string    `json:"subject"`
+	HTMLBody  string    `json:"htmlBody"`
+	TextBody  string    `json:"textBody"`
+	Variables []string  `json:"variables"`
+	Updated   time.Time `json:"updated"`
+}
@@ -0,0 +1,54 @@
+package model
+
+// Environments of an app, each environment is a database of the same
+// tenant with its own data
+const (
+	EnvironmentDev     = "dev"
+	EnvironmentStaging = "staging"
+	EnvironmentProd    = "prod"
+)
+
+// Environments are the environments in promotion order
+var Environments = []string{EnvironmentDev, EnvironmentStaging, EnvironmentProd}
+
+// Env returns the database's environment, prod when it's not set
+func (c DatabaseConfig) Env() string {
+	if len(c.Environment) == 0 {
+		return EnvironmentProd
+	}
+	return c.Environment
+}
+
+// AppID returns the ID of the app's production database, the database
+// linking all the environments
+func (c DatabaseConfig) AppID() string {
+	if len(c.ParentID) > 0 {
+		return c.ParentID
+	}
+	return c.ID
+}
+
+// PromotionReport summarizes a promotion from an environment to the next
+// one. The functions and tasks are added or updated by name, the
+// collection schemas and forms settings are replaced and the form
+// definitions are saved.
+type PromotionReport struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Functions int      `json:"functions"`
+	Tasks     int      `json:"tasks"`
+	Schemas   int      `json:"schemas"`
+	Forms     int      `json:"forms"`
+	Errors    []string `json:"errors"`
+}
+
+// NextEnvironment returns the environment promoted from env, false when
+// env is prod or unknown
+func NextEnvironment(env string) (string, bool) {
+	for i, e := range Environments {
+		if e == env && i+1 < len(Environments) {
+			return Environments[i+1], true
+		}
+	}
+	return "", false
+}
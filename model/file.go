@@ -8,6 +8,11 @@ import (
 type UploadFileData struct {
 	FileKey string
 	File    io.ReadSeeker
+	// CacheControl is stored with the file by providers supporting it
+	CacheControl string
+	// Private files are not publicly readable, they are downloaded via
+	// signed URLs
+	Private bool
 }
 
 type File struct {
@@ -17,4 +22,52 @@ type File struct {
 	URL       string    `json:"url"`
 	Size      int64     `json:"size"`
 	Uploaded  time.Time `json:"uploaded"`
+	// Variants are the image variants generated at upload
+	Variants []FileVariant `json:"variants,omitempty"`
+	// Name is the original file name
+	Name     string   `json:"name"`
+	MimeType string   `json:"mimeType"`
+	Tags     []string `json:"tags"`
+	// FileLink is the document or user owning the file, the file is
+	// deleted with them
+	FileLink
+}
+
+// FileLink ties a file to a document or a user, empty fields are not linked
+type FileLink struct {
+	Collection string `json:"collection,omitempty"`
+	DocumentID string `json:"documentId,omitempty"`
+	UserID     string `json:"userId,omitempty"`
+}
+
+// FileFilter narrows the files returned, empty fields are ignored.
+// Name matches files containing it (case-insensitive) and MimeType files
+// starting with it, i.e. "image/".
+type FileFilter struct {
+	AccountID string
+	Name      string
+	MimeType  string
+	Tag       string
+	// the linked document or user, see FileLink
+	Collection string
+	DocumentID string
+	UserID     string
+	Limit      int64
+	Offset     int64
+}
+
+// StorageUsage is the number of files and bytes stored by a database,
+// variants included, with its plan quota (0 is unlimited)
+type StorageUsage struct {
+	Files    int64 `json:"files"`
+	Bytes    int64 `json:"bytes"`
+	MaxBytes int64 `json:"maxBytes"`
+}
+
+// FileVariant is a processed copy of an uploaded image stored alongside it
+type FileVariant struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	URL  string `json:"url"`
+	Size int64  `json:"size"`
 }
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// FeatureFlag enables or disables a capability for a tenant, it overrides
+// the flag's default
+type FeatureFlag struct {
+	TenantID string    `json:"tenantId"`
+	Name     string    `json:"name"`
+	Enabled  bool      `json:"enabled"`
+	Updated  time.Time `json:"updated"`
+}
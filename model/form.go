@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// FormDelivery is an attempt to post a form submission to a webhook, the
+// form's delivery log. StatusCode is 0 when the request could not be sent.
+type FormDelivery struct {
+	ID         string    `json:"id"`
+	Form       string    `json:"form"`
+	URL        string    `json:"url"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error"`
+	Created    time.Time `json:"created"`
+}
+
+// Form field types validated on submission
+const (
+	FormFieldText   = "text"
+	FormFieldEmail  = "email"
+	FormFieldNumber = "number"
+	FormFieldURL    = "url"
+	FormFieldDate   = "date"
+)
+
+// FormDefinition is the set of fields validated when a form is submitted.
+// A form without a definition accepts any field.
+type FormDefinition struct {
+	Name    string      `json:"name"`
+	Fields  []FormField `json:"fields"`
+	Updated time.Time   `json:"updated"`
+}
+
+// FormField is a validation rule for a submitted field. Type defaults to
+// text, Pattern is a regular expression the value must match and
+// MaxLength is in characters, zero values are unrestricted.
+type FormField struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Required  bool   `json:"required"`
+	Pattern   string `json:"pattern"`
+	MaxLength int    `json:"maxLength"`
+}
+
+// FormFilter narrows the form submissions listed, zero values are ignored.
+// Search matches the submissions with a field value containing it, Spam and
+// Read match the submissions flagged as spam or marked as read, or not.
+type FormFilter struct {
+	Since  time.Time
+	Until  time.Time
+	Search string
+	Spam   *bool
+	Read   *bool
+}
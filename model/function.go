@@ -33,20 +33,88 @@ const (
 	TaskTypeFunction = "function"
 	TaskTypeMessage  = "message"
 	TaskTypeHTTP     = "http"
+	// TaskTypeBackup backs up the database, its value is the number of
+	// backups kept
+	TaskTypeBackup = "backup"
 )
 
+// Task run statuses
+const (
+	TaskStatusSuccess  = "success"
+	TaskStatusFailed   = "failed"
+	TaskStatusRetrying = "retrying"
+	TaskStatusSkipped  = "skipped"
+)
+
+// Task is a scheduled job, Interval is a cron expression (i.e. */5 * * * *)
+// or a named schedule like @hourly. A task without Interval is a one-off
+// task running once at RunAt. A disabled task keeps its definition but is
+// not scheduled. A task with After runs once the task with that ID ran
+// successfully and is skipped when it failed. LastStatus and LastError are
+// the outcome of the last run.
 type Task struct {
-	ID       string    `json:"id"`
-	Name     string    `json:"name"`
-	Type     string    ` json:"type"`
-	Value    string    ` json:"value"`
-	Meta     string    ` json:"meta"`
-	Interval string    ` json:"interval"`
-	LastRun  time.Time ` json:"last"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Type       string    ` json:"type"`
+	Value      string    ` json:"value"`
+	Meta       string    ` json:"meta"`
+	Interval   string    ` json:"interval"`
+	RunAt      time.Time `json:"runAt"`
+	Retry      TaskRetry `json:"retry"`
+	Enabled    bool      `json:"enabled"`
+	After      string    `json:"after"`
+	LastRun    time.Time ` json:"last"`
+	LastStatus string    `json:"lastStatus"`
+	LastError  string    `json:"lastError"`
+
+	// NextRun is computed from the Interval, it is not persisted
+	NextRun time.Time `json:"next"`
 
 	BaseName string `json:"base"`
 }
 
+// IsOneOff returns whether the task runs once at RunAt
+func (t Task) IsOneOff() bool {
+	return len(t.Interval) == 0 && !t.RunAt.IsZero()
+}
+
+// IsDependent returns whether the task runs after another one
+func (t Task) IsDependent() bool {
+	return len(t.After) > 0
+}
+
+// Completed returns whether a one-off task has already run
+func (t Task) Completed() bool {
+	if !t.IsOneOff() {
+		return false
+	}
+	return t.LastStatus == TaskStatusSuccess || t.LastStatus == TaskStatusFailed
+}
+
+// TaskRun is an attempt of a task's run with its outcome, the Output
+// describes what was executed
+type TaskRun struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"taskId"`
+	Attempt   int       `json:"attempt"`
+	Started   time.Time `json:"started"`
+	Completed time.Time `json:"completed"`
+	Success   bool      `json:"success"`
+	Output    string    `json:"output"`
+	Error     string    `json:"error"`
+}
+
+// TaskRetry is the retry policy of a task's failed runs. MaxAttempts
+// includes the first attempt, the delay before the nth retry is Backoff
+// seconds doubled n-1 times, capped at MaxBackoff seconds and randomized by
+// up to Jitter (a fraction between 0 and 1) of the delay.
+type TaskRetry struct {
+	MaxAttempts int     `json:"maxAttempts"`
+	Backoff     int     `json:"backoff"`
+	MaxBackoff  int     `json:"maxBackoff"`
+	Jitter      float64 `json:"jitter"`
+}
+
 type MetaMessage struct {
 	Data        string `json:"data"`
 	Channel     string `json:"channel"`
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// DeletionReport summarizes the data removed when a user is purged
+type DeletionReport struct {
+	UserID          string           `json:"userId"`
+	AccountID       string           `json:"accountId"`
+	Email           string           `json:"email"`
+	Documents       map[string]int64 `json:"documents"`
+	FormSubmissions int64            `json:"formSubmissions"`
+	Files           int64            `json:"files"`
+	// FailedFiles are the keys of the files that could not be removed
+	FailedFiles    []string  `json:"failedFiles,omitempty"`
+	AccountDeleted bool      `json:"accountDeleted"`
+	Completed      time.Time `json:"completed"`
+}
+
+// NewDeletionReport returns an empty report for this user
+func NewDeletionReport(tok User) DeletionReport {
+	return DeletionReport{
+		UserID:    tok.ID,
+		AccountID: tok.AccountID,
+		Email:     tok.Email,
+		Documents: make(map[string]int64),
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+// Status of a dependency check
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusError   = "error"
+	HealthStatusSkipped = "skipped"
+)
+
+// HealthCheck is the outcome of a dependency check with its duration in
+// milliseconds, a skipped dependency cannot be checked without side effects
+type HealthCheck struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport is the readiness of the instance, Status is ok when none of
+// the checks failed
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+	// Pool is not set for the memory database
+	Pool *DatabasePool `json:"pool,omitempty"`
+}
+
+// DatabasePool is the state of the database connections pool shared by all
+// the apps, MaxOpen is 0 when unlimited. The wait count and duration are
+// not reported by MongoDB.
+type DatabasePool struct {
+	MaxOpen   int     `json:"maxOpen"`
+	Open      int     `json:"open"`
+	InUse     int     `json:"inUse"`
+	Idle      int     `json:"idle"`
+	WaitCount int64   `json:"waitCount"`
+	WaitMS    float64 `json:"waitMs"`
+}
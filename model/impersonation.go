@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// ImpersonationTokenPrefix starts the time-boxed root tokens minted by the
+// platform operators to debug an app
+const ImpersonationTokenPrefix = "imp_"
+
+// Impersonation is a root token minted by an operator for a database, it
+// expires at Expires
+type Impersonation struct {
+	DBName   string    `json:"dbName"`
+	TenantID string    `json:"tenantId"`
+	Operator string    `json:"operator"`
+	Reason   string    `json:"reason"`
+	Expires  time.Time `json:"expires"`
+}
+
+// ImpersonationKey returns the cache key of an impersonation token
+func ImpersonationKey(token string) string {
+	return "impersonation:" + token
+}
@@ -0,0 +1,66 @@
+package model
+
+// Sources of an import
+const (
+	ImportFirebase = "firebase"
+	ImportSupabase = "supabase"
+)
+
+// Field types of ImportCollection.Types, the values of a Supabase dump are
+// all text and are converted according to them
+const (
+	ImportTypeNumber = "number"
+	ImportTypeBool   = "bool"
+	ImportTypeJSON   = "json"
+	ImportTypeTime   = "time"
+)
+
+// ImportMapping configures how the collections of an export are imported,
+// collections without a mapping are imported under the same name with all
+// their fields.
+type ImportMapping struct {
+	Collections map[string]ImportCollection `json:"collections"`
+	// Skip lists the source collections that are not imported
+	Skip []string `json:"skip"`
+	// SourceIDField, when set, is the field keeping the document id of the
+	// source
+	SourceIDField string `json:"sourceIdField"`
+	// StorageURL is prepended to the object names of the storage records
+	// without a URL
+	StorageURL string `json:"storageUrl"`
+}
+
+// ImportCollection maps a source collection, or a Supabase table
+type ImportCollection struct {
+	// Name is the destination collection, the source name when empty
+	Name string `json:"name"`
+	// Fields renames the source fields, an empty name drops the field
+	Fields map[string]string `json:"fields"`
+	// Types converts source fields to number, bool, json or time
+	Types map[string]string `json:"types"`
+	// Owner is the source field holding the id of the user owning the
+	// document, the document is created in that user's account
+	Owner string `json:"owner"`
+}
+
+// ImportProgress is reported while an import runs. Stage is users,
+// documents or files.
+type ImportProgress struct {
+	Stage  string `json:"stage"`
+	Done   int    `json:"done"`
+	Total  int    `json:"total"`
+	Errors int    `json:"errors"`
+}
+
+// ImportReport summarizes a completed import. The users whose password
+// hash cannot be verified by the database, Firebase's scrypt, are created
+// with a random password and must reset it, they are counted in
+// PasswordResets.
+type ImportReport struct {
+	Users          int            `json:"users"`
+	UsersSkipped   int            `json:"usersSkipped"`
+	PasswordResets int            `json:"passwordResets"`
+	Documents      map[string]int `json:"documents"`
+	Files          int            `json:"files"`
+	Errors         []string       `json:"errors"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Invite is a pending invitation for a user to join an account
+type Invite struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"accountId"`
+	Email     string    `json:"email"`
+	Role      int       `json:"role"`
+	InvitedBy string    `json:"invitedBy"`
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires"`
+}
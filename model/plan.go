@@ -0,0 +1,27 @@
+package model
+
+// Plan is a subscription plan, its Stripe price and quotas. The ID is the
+// plan level stored on the Tenant.
+type Plan struct {
+	ID      int        `json:"id"`
+	Name    string     `json:"name"`
+	PriceID string     `json:"priceId"`
+	Quotas  PlanQuotas `json:"quotas"`
+}
+
+// PlanQuotas are the limits of a plan, zero is unlimited
+type PlanQuotas struct {
+	// RequestsPerMinute per database, enforced when rate limiting is enabled
+	RequestsPerMinute int `json:"requestsPerMinute"`
+	// StorageBytes per database, enforced when storage quotas are enabled
+	StorageBytes int64 `json:"storageBytes"`
+	// MonthlyEmails per tenant, enforced when email quotas are enabled
+	MonthlyEmails int `json:"monthlyEmails"`
+	// MonthlyFunctionRuns per database, enforced when function quotas are
+	// enabled
+	MonthlyFunctionRuns int64 `json:"monthlyFunctionRuns"`
+	// RealtimeConnections and RealtimeMessagesPerSecond per database,
+	// enforced when the realtime plan caps are enabled
+	RealtimeConnections       int64 `json:"realtimeConnections"`
+	RealtimeMessagesPerSecond int64 `json:"realtimeMessagesPerSecond"`
+}
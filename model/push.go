@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	PushKindWebPush = "webpush"
+	PushKindFCM     = "fcm"
+)
+
+// PushSubscription is a device registered to receive the messages of a
+// channel as push notifications while the user is offline
+type PushSubscription struct {
+	ID        string `json:"id"`
+	AccountID string `json:"accountId"`
+	UserID    string `json:"userId"`
+	Channel   string `json:"channel"`
+	Kind      string `json:"kind"`
+	// Endpoint is the push service URL for Web Push or the registration
+	// token for FCM
+	Endpoint string `json:"endpoint"`
+	// P256dh and Auth are the Web Push subscription keys
+	P256dh  string    `json:"p256dh"`
+	Auth    string    `json:"auth"`
+	Created time.Time `json:"created"`
+}
+
+// PushRule turns the messages of the matching channels into push
+// notifications. The channel can end with a * to match a prefix.
+type PushRule struct {
+	Channel string `json:"channel"`
+	Title   string `json:"title"`
+	// Body defaults to the message data when empty
+	Body string `json:"body"`
+}
+
+// Matches returns true if the channel matches the rule's channel
+func (r PushRule) Matches(channel string) bool {
+	return MatchChannel(r.Channel, channel)
+}
@@ -0,0 +1,470 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	CaptchaHCaptcha  = "hcaptcha"
+	CaptchaTurnstile = "turnstile"
+	CaptchaPoW       = "pow"
+)
+
+// Auth lifecycle events sent to webhooks
+const (
+	WebhookUserCreated   = "user.created"
+	WebhookUserLogin     = "user.login"
+	WebhookUserDeleted   = "user.deleted"
+	WebhookPasswordReset = "password.reset"
+
+	// WebhookFileQuarantined is sent when an uploaded file is infected
+	WebhookFileQuarantined = "file.quarantined"
+
+	// WebhookChannelMessages is sent with the batched messages of channels
+	WebhookChannelMessages = "channel.messages"
+
+	// WebhookFormSubmitted is sent to a form's webhooks on new submissions
+	WebhookFormSubmitted = "form.submitted"
+
+	// Document changes of all collections
+	WebhookDocumentCreated = "document.created"
+	WebhookDocumentUpdated = "document.updated"
+	WebhookDocumentDeleted = "document.deleted"
+
+	// WebhookFunctionCompleted is sent after each function execution
+	WebhookFunctionCompleted = "function.completed"
+
+	// WebhookAnomalyDetected is sent when the usage spikes unusually
+	WebhookAnomalyDetected = "anomaly.detected"
+)
+
+// AppSettings holds the per-database configurable options
+type AppSettings struct {
+	Captcha  CaptchaSettings    `json:"captcha"`
+	Webhooks []WebhookSettings  `json:"webhooks"`
+	Realtime RealtimeSettings   `json:"realtime"`
+	Images   ImageSettings      `json:"images"`
+	Uploads  UploadSettings     `json:"uploads"`
+	Files    FileSettings       `json:"files"`
+	Forms    FormSettings       `json:"forms"`
+	Email    EmailSettings      `json:"email"`
+	Schemas  []CollectionSchema `json:"schemas"`
+	CORS     CORSSettings       `json:"cors"`
+	// RateLimits lowers the plan's rate limits of the route classes
+	RateLimits RateLimitSettings `json:"rateLimits"`
+	IPAccess   IPAccessSettings  `json:"ipAccess"`
+	Search     SearchSettings    `json:"search"`
+	Functions  FunctionSettings  `json:"functions"`
+}
+
+// FunctionLimits caps the runs of a function: Timeout in seconds, MaxOutput
+// the bytes of log output kept and MaxDBCalls the calls to the database
+// functions. They can lower the instance's limits, 0 keeps them.
+type FunctionLimits struct {
+	Timeout    int `json:"timeout"`
+	MaxOutput  int `json:"maxOutput"`
+	MaxDBCalls int `json:"maxDbCalls"`
+}
+
+// FunctionSettings are the limits of the database's functions, Overrides
+// replaces them by function name. Fetch restricts the hosts they can call.
+type FunctionSettings struct {
+	Limits    FunctionLimits            `json:"limits"`
+	Overrides map[string]FunctionLimits `json:"overrides"`
+	Fetch     FetchSettings             `json:"fetch"`
+}
+
+// FetchSettings restricts the hosts the functions can call with fetch(). A
+// host can start with *. to match all subdomains, a host matching
+// DeniedHosts is refused and when AllowedHosts is set only the hosts
+// matching it are allowed.
+type FetchSettings struct {
+	AllowedHosts []string `json:"allowedHosts"`
+	DeniedHosts  []string `json:"deniedHosts"`
+}
+
+// Allows returns true if fetch() can call the host
+func (s FetchSettings) Allows(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if matchHost(s.DeniedHosts, host) {
+		return false
+	}
+	return len(s.AllowedHosts) == 0 || matchHost(s.AllowedHosts, host)
+}
+
+// matchHost returns true if the host is one of the hosts or a subdomain of
+// a *. host
+func matchHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == host {
+			return true
+		} else if strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// For returns the limits of a function, the limits set in its overrides
+// replace the database's ones
+func (s FunctionSettings) For(name string) FunctionLimits {
+	limits := s.Limits
+	if o, ok := s.Overrides[name]; ok {
+		if o.Timeout > 0 {
+			limits.Timeout = o.Timeout
+		}
+		if o.MaxOutput > 0 {
+			limits.MaxOutput = o.MaxOutput
+		}
+		if o.MaxDBCalls > 0 {
+			limits.MaxDBCalls = o.MaxDBCalls
+		}
+	}
+	return limits
+}
+
+// IP access scopes, the root token and admin endpoints or the whole API
+const (
+	IPAccessRoot = "root"
+	IPAccessAll  = "all"
+)
+
+// IPAccessSettings restricts the client IPs allowed to use the database.
+// Allow and Deny are CIDR ranges or single IPs, an IP matching Deny is
+// refused and when Allow is set only the IPs matching it are allowed.
+// Scope is root (the default) to restrict the root token and admin
+// endpoints or all for the whole API.
+type IPAccessSettings struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+	Scope string   `json:"scope"`
+}
+
+// Allows returns true if the IP is allowed, invalid ranges never match
+func (s IPAccessSettings) Allows(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return len(s.Allow) == 0 && len(s.Deny) == 0
+	}
+
+	if matchIPRanges(s.Deny, addr) {
+		return false
+	}
+	return len(s.Allow) == 0 || matchIPRanges(s.Allow, addr)
+}
+
+// ParseIPRange parses a CIDR range or a single IP as a range
+func ParseIPRange(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP %s", s)
+		}
+
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(s)
+	return ipnet, err
+}
+
+func matchIPRanges(ranges []string, ip net.IP) bool {
+	for _, r := range ranges {
+		if ipnet, err := ParseIPRange(r); err == nil && ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimitSettings are the requests per minute allowed for the auth,
+// write and read routes. They can only lower the plan's limits, 0 keeps
+// the plan's limit.
+type RateLimitSettings struct {
+	Auth  int64 `json:"auth"`
+	Write int64 `json:"write"`
+	Read  int64 `json:"read"`
+}
+
+// Limit returns the limit of a route class, auth, write or read
+func (s RateLimitSettings) Limit(class string) int64 {
+	switch class {
+	case "auth":
+		return s.Auth
+	case "write":
+		return s.Write
+	case "read":
+		return s.Read
+	}
+	return 0
+}
+
+// CORSSettings restricts the browser origins allowed to use the database's
+// public key. An origin is a scheme and host, i.e. https://example.com, the
+// host can start with *. to allow all subdomains. Empty AllowedOrigins
+// allow any origin, empty AllowedMethods and AllowedHeaders allow the
+// requested ones.
+type CORSSettings struct {
+	AllowedOrigins []string `json:"allowedOrigins"`
+	AllowedMethods []string `json:"allowedMethods"`
+	AllowedHeaders []string `json:"allowedHeaders"`
+}
+
+// AllowsOrigin returns true if the origin matches one of the allowed origins
+func (c CORSSettings) AllowsOrigin(origin string) bool {
+	if len(c.AllowedOrigins) == 0 {
+		return true
+	}
+
+	origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+	for _, allowed := range c.AllowedOrigins {
+		allowed = strings.ToLower(strings.TrimSuffix(allowed, "/"))
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+
+		// https://*.example.com matches https://app.example.com
+		scheme, host, ok := strings.Cut(allowed, "://*.")
+		if ok && strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsMethod returns true if the HTTP method is allowed
+func (c CORSSettings) AllowsMethod(method string) bool {
+	if len(c.AllowedMethods) == 0 {
+		return true
+	}
+
+	for _, allowed := range c.AllowedMethods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormSettings protects the public form endpoint against spam. RateLimit
+// is the number of submissions per minute allowed for an IP, 0 uses the
+// default. Submissions from disposable email addresses, or the
+// BlockedEmailDomains, are flagged as spam when BlockDisposableEmails is
+// set. MaxAttachments is the number of files a submission can attach, 0
+// uses the default. Webhooks and AutoResponders handle the submissions not
+// flagged as spam.
+type FormSettings struct {
+	RateLimit             int                 `json:"rateLimit"`
+	MaxAttachments        int                 `json:"maxAttachments"`
+	BlockDisposableEmails bool                `json:"blockDisposableEmails"`
+	BlockedEmailDomains   []string            `json:"blockedEmailDomains"`
+	Webhooks              []FormWebhook       `json:"webhooks"`
+	AutoResponders        []FormAutoResponder `json:"autoResponders"`
+}
+
+// FormAutoResponder sends a confirmation email to the address submitted in
+// the EmailField of a form. The Subject and Body can contain the submitted
+// values as [field] placeholders, i.e. "Thanks [name]". Empty From values
+// use the instance's sender.
+type FormAutoResponder struct {
+	Form       string `json:"form"`
+	Enabled    bool   `json:"enabled"`
+	EmailField string `json:"emailField"`
+	FromEmail  string `json:"fromEmail"`
+	FromName   string `json:"fromName"`
+	ReplyTo    string `json:"replyTo"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+}
+
+// FormWebhook posts the submissions of the matching forms to an URL. The
+// form can end with a * to match a prefix, an empty form matches all forms.
+type FormWebhook struct {
+	Form   string `json:"form"`
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// Matches returns true if the form matches the webhook's form
+func (wh FormWebhook) Matches(form string) bool {
+	if len(wh.Form) == 0 {
+		return true
+	}
+	return MatchChannel(wh.Form, form)
+}
+
+// EmailSettings sends the database's emails with its own mail provider
+// credentials, the instance's mail provider is used when Provider is empty.
+// Provider is one of ses, smtp, sendgrid, mailgun or postmark. APIKey is the
+// SendGrid or Mailgun API key, the Postmark server token or the SES access
+// key ID with APISecret as its secret. Region is the SES region or "eu" for
+// Mailgun's EU region and Domain the Mailgun sending domain. Host,
+// Username and Password are used by SMTP.
+type EmailSettings struct {
+	Provider  string `json:"provider"`
+	APIKey    string `json:"apiKey"`
+	APISecret string `json:"apiSecret"`
+	Region    string `json:"region"`
+	Domain    string `json:"domain"`
+	Host      string `json:"host"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
+
+// CollectionSchema describes the documents of a collection in the
+// generated OpenAPI document and client code, the documents are not
+// validated against it
+type CollectionSchema struct {
+	Collection string        `json:"collection"`
+	Fields     []SchemaField `json:"fields"`
+}
+
+// SchemaField is a document field. Type is a JSON schema type: string,
+// number, integer, boolean, object or array and Format an optional format
+// of the type, i.e. date-time or email.
+type SchemaField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Format   string `json:"format"`
+	Required bool   `json:"required"`
+}
+
+// FileSettings configures how files are served. CacheControl is the
+// Cache-Control header of file responses and CDNURL replaces the storage
+// URL in the returned file URLs (the file key is appended to it).
+// Provider selects one of the storage providers enabled by the instance
+// (local, s3, azure or gcs). Stored files are not moved when it changes, it
+// should be set before uploading files.
+type FileSettings struct {
+	CacheControl string `json:"cacheControl"`
+	CDNURL       string `json:"cdnUrl"`
+	Provider     string `json:"provider"`
+}
+
+// UploadSettings restricts the uploaded files. MaxFileSize is in bytes and
+// AllowedTypes are MIME types which can end with /* (i.e. image/*), zero
+// values are unrestricted.
+type UploadSettings struct {
+	MaxFileSize  int64    `json:"maxFileSize"`
+	AllowedTypes []string `json:"allowedTypes"`
+}
+
+// ImageSettings configures the variants generated when images are uploaded
+type ImageSettings struct {
+	Variants []ImageVariant `json:"variants"`
+}
+
+// ImageVariant is a resized and/or converted copy of uploaded images. The
+// image fits within Width x Height keeping its aspect ratio, a zero value is
+// unconstrained. Format is jpeg, png or webp, empty keeps the original
+// format.
+type ImageVariant struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+}
+
+// RealtimeSettings configures the channels behavior
+type RealtimeSettings struct {
+	// HistorySize is the number of messages kept per channel and replayed
+	// to new subscribers, 0 disables the history
+	HistorySize int `json:"historySize"`
+	// PushRules are the channels forwarded as push notifications to
+	// offline subscribers
+	PushRules []PushRule `json:"pushRules"`
+	// Webhooks receive the messages published to the matching channels
+	Webhooks []ChannelWebhook `json:"webhooks"`
+}
+
+// ChannelWebhook posts the messages of the matching channels to an URL. The
+// channel can end with a * to match a prefix.
+type ChannelWebhook struct {
+	Channel string `json:"channel"`
+	URL     string `json:"url"`
+	Secret  string `json:"secret"`
+}
+
+// Matches returns true if the channel matches the webhook's channel
+func (wh ChannelWebhook) Matches(channel string) bool {
+	return MatchChannel(wh.Channel, channel)
+}
+
+// MatchChannel returns true if the channel equals the pattern or starts with
+// it when the pattern ends with a *
+func MatchChannel(pattern, channel string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(channel, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == channel
+}
+
+// SearchSettings lists the collections whose documents are kept in the
+// full-text search index as they change
+type SearchSettings struct {
+	Collections []SearchCollection `json:"collections"`
+}
+
+// SearchCollection indexes the text of the fields of a collection's
+// documents, all their string values when Fields is empty
+type SearchCollection struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+}
+
+// Find returns the indexing settings of a collection
+func (ss SearchSettings) Find(col string) (SearchCollection, bool) {
+	for _, sc := range ss.Collections {
+		if sc.Name == col {
+			return sc, true
+		}
+	}
+	return SearchCollection{}, false
+}
+
+// CaptchaSettings configures the bot verification on the authentication
+// endpoints
+type CaptchaSettings struct {
+	Provider        string `json:"provider"`
+	SecretKey       string `json:"secretKey"`
+	Difficulty      int    `json:"difficulty"`
+	OnRegister      bool   `json:"onRegister"`
+	OnLogin         bool   `json:"onLogin"`
+	OnPasswordReset bool   `json:"onPasswordReset"`
+	OnForms         bool   `json:"onForms"`
+}
+
+// Enabled returns true if a provider is configured
+func (cs CaptchaSettings) Enabled() bool {
+	return len(cs.Provider) > 0
+}
+
+// WebhookSettings is an endpoint receiving the app's events. An empty Events
+// list subscribes to all events, an event can end with a * to match a
+// prefix, i.e. document.*
+type WebhookSettings struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// Subscribed returns true if this webhook should receive the event
+func (wh WebhookSettings) Subscribed(event string) bool {
+	if len(wh.Events) == 0 {
+		return true
+	}
+
+	for _, e := range wh.Events {
+		if MatchChannel(e, event) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestFetchSettingsAllows(t *testing.T) {
+	s := FetchSettings{
+		AllowedHosts: []string{"api.example.com", "*.stripe.com"},
+		DeniedHosts:  []string{"internal.stripe.com"},
+	}
+
+	tests := map[string]bool{
+		"api.example.com":      true,
+		"API.example.com.":     true,
+		"example.com":          false,
+		"api.stripe.com":       true,
+		"stripe.com":           false,
+		"internal.stripe.com":  false,
+		"evilstripe.com":       false,
+		"api.example.com.evil": false,
+	}
+	for host, expected := range tests {
+		if got := s.Allows(host); got != expected {
+			t.Errorf("%s: expected %v got %v", host, expected, got)
+		}
+	}
+
+	var open FetchSettings
+	if !open.Allows("example.com") {
+		t.Error("expected all hosts to be allowed without settings")
+	}
+}
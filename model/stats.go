@@ -0,0 +1,24 @@
+package model
+
+// UsagePoint is the value of a usage metric over a period, a month
+// formatted as 2006-01 or a day formatted as 2006-01-02 (UTC)
+type UsagePoint struct {
+	Period string `json:"period"`
+	Value  int64  `json:"value"`
+}
+
+// AppStats is the usage overview of a database. Documents are counted per
+// collection, the function runs and emails sent per month and the active
+// users, the distinct users who signed in, per day. RealtimeConnections are
+// the connections currently open. Usage is the metered usage per metric and
+// day.
+type AppStats struct {
+	Documents           map[string]int64        `json:"documents"`
+	TotalDocuments      int64                   `json:"totalDocuments"`
+	Storage             StorageUsage            `json:"storage"`
+	FunctionRuns        []UsagePoint            `json:"functionRuns"`
+	EmailsSent          []UsagePoint            `json:"emailsSent"`
+	RealtimeConnections int64                   `json:"realtimeConnections"`
+	ActiveUsers         []UsagePoint            `json:"activeUsers"`
+	Usage               map[string][]UsagePoint `json:"usage"`
+}
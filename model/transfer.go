@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Status of an ownership transfer
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferCancelled = "cancelled"
+)
+
+// OwnershipTransfer is the transfer of a database and its environments to
+// another tenant, or of all the databases of its tenant when WholeAccount
+// is set. The recipient accepts it with the emailed token before it
+// expires.
+type OwnershipTransfer struct {
+	ID           string    `json:"id"`
+	BaseID       string    `json:"baseId"`
+	FromTenantID string    `json:"fromTenantId"`
+	ToTenantID   string    `json:"toTenantId"`
+	ToEmail      string    `json:"toEmail"`
+	WholeAccount bool      `json:"wholeAccount"`
+	Status       string    `json:"status"`
+	Created      time.Time `json:"created"`
+	Expires      time.Time `json:"expires"`
+	Completed    time.Time `json:"completed"`
+}
+
+// TransferredDatabase is a database received by an ownership transfer with
+// the new credentials of its root user
+type TransferredDatabase struct {
+	ID            string `json:"pk"`
+	Name          string `json:"name"`
+	RootToken     string `json:"rootToken"`
+	AdminPassword string `json:"pw"`
+}
@@ -0,0 +1,54 @@
+package model
+
+// Usage metrics metered per tenant, database and day
+const (
+	// MeterAPICalls counts the API requests
+	MeterAPICalls = "api_calls"
+	// MeterFunctionMillis adds the execution time of the functions in
+	// milliseconds
+	MeterFunctionMillis = "function_ms"
+	// MeterStorageBytes adds the bytes of the uploaded files
+	MeterStorageBytes = "storage_bytes"
+	// MeterEmails counts the emails sent
+	MeterEmails = "emails"
+	// MeterRealtimeMessages counts the messages sent to realtime channels
+	MeterRealtimeMessages = "realtime_messages"
+)
+
+// Failures metered for the anomaly detection, they are not part of the
+// billed usage
+const (
+	// MeterFunctionFailures counts the failed function executions
+	MeterFunctionFailures = "function_failures"
+	// MeterLoginFailures counts the failed sign-ins
+	MeterLoginFailures = "login_failures"
+)
+
+// Meters are the usage metrics
+var Meters = []string{
+	MeterAPICalls,
+	MeterFunctionMillis,
+	MeterStorageBytes,
+	MeterEmails,
+	MeterRealtimeMessages,
+}
+
+// UsageRecord is the value of a usage metric of a database for a day,
+// formatted as 2006-01-02 (UTC)
+type UsageRecord struct {
+	TenantID string `json:"tenantId"`
+	DBName   string `json:"dbName"`
+	Day      string `json:"day"`
+	Metric   string `json:"metric"`
+	Value    int64  `json:"value"`
+}
+
+// UsageFilter selects the usage records of a tenant between two days
+// included, formatted as 2006-01-02. An empty DBName returns the records of
+// all the tenant's databases.
+type UsageFilter struct {
+	TenantID string
+	DBName   string
+	Since    string
+	Until    string
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// SecretRefPrefix prefixes the settings values referencing a secret of the
+// vault, i.e. vault:SMTP_PASSWORD, they are replaced by the latest version
+// of the secret when used
+const SecretRefPrefix = "vault:"
+
+// Secret is a version of an app's secret, its value is encrypted with a
+// data key wrapped by the master key KeyID
+type Secret struct {
+	ID      string    `json:"id"`
+	DBName  string    `json:"dbName"`
+	Name    string    `json:"name"`
+	Version int       `json:"version"`
+	Value   []byte    `json:"-"`
+	DataKey []byte    `json:"-"`
+	KeyID   string    `json:"keyId"`
+	Created time.Time `json:"created"`
+}
+
+// SecretInfo describes a secret without its value, Version is its latest
+// version
+type SecretInfo struct {
+	Name     string    `json:"name"`
+	Version  int       `json:"version"`
+	Versions int       `json:"versions"`
+	Updated  time.Time `json:"updated"`
+}
+
+// SecretRef returns the name of the secret referenced by a settings value,
+// false when the value is not a reference
+func SecretRef(value string) (string, bool) {
+	if !strings.HasPrefix(value, SecretRefPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(value, SecretRefPrefix), true
+}
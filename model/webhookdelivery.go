@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Delivery status of a queued webhook event
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
+
+// WebhookDelivery is an event queued for delivery to a webhook of the app's
+// settings. Payload is the signed JSON body. A pending delivery is sent at
+// NextAttempt, Attempts, StatusCode and LastError record the failed
+// attempts until it is delivered or failed.
+type WebhookDelivery struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Event       string    `json:"event"`
+	Payload     string    `json:"payload"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	StatusCode  int       `json:"statusCode"`
+	LastError   string    `json:"lastError"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// WebhookDeliveryFilter narrows the deliveries of the log, empty fields are
+// ignored
+type WebhookDeliveryFilter struct {
+	URL    string
+	Event  string
+	Status string
+	Limit  int64
+}
@@ -237,6 +237,8 @@ func (el *ExternalLogins) signIn(conf model.DatabaseConfig, email string) (sessi
 		return
 	}
 
+	emitUserEvent(conf, model.WebhookUserLogin, tok)
+
 	sessionToken = string(b)
 	return
 }
@@ -246,11 +248,13 @@ func (el *ExternalLogins) signUp(conf model.DatabaseConfig, provider, email, acc
 
 	mship := backend.Membership(conf)
 
-	b, _, err := mship.CreateAccountAndUser(email, pw, 0)
+	b, tok, err := mship.CreateAccountAndUser(email, pw, 0)
 	if err != nil {
 		return
 	}
 
+	emitUserEvent(conf, model.WebhookUserCreated, tok)
+
 	sessionToken = string(b)
 	return
 }
@@ -0,0 +1,51 @@
+package staticbackend
+
+import (
+	"net/http"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/openapi"
+)
+
+// openAPI returns the OpenAPI document of the database's collections,
+// forms and web functions from GET /openapi.json
+func openAPI(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cols, err := backend.DB.ListCollections(conf.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	forms, err := backend.DB.GetForms(conf.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fns, err := backend.DB.ListFunctionsByTrigger(conf.Name, "web")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	app := openapi.App{
+		Name:        conf.Name,
+		ServerURL:   config.Current.AppURL,
+		Collections: cols,
+		Schemas:     conf.Settings.Schemas,
+		Forms:       forms,
+	}
+	for _, fn := range fns {
+		app.Functions = append(app.Functions, fn.FunctionName)
+	}
+
+	respond(w, http.StatusOK, openapi.Generate(app))
+}
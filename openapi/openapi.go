@@ -0,0 +1,463 @@
+// Package openapi generates the OpenAPI 3 document of an application's
+// endpoints: its database collections, authentication, storage, forms and
+// web functions.
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// Version is the OpenAPI specification version of the generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem holds the operations of a path by lowercase HTTP method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Schema is a subset of the OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// App is what is documented for an application. The collections without a
+// schema are documented as free-form documents.
+type App struct {
+	Name        string
+	ServerURL   string
+	Collections []string
+	Schemas     []model.CollectionSchema
+	Forms       []string
+	// Functions are the names of the functions triggered by "web"
+	Functions []string
+}
+
+// the security schemes of the public key and the user's session token
+const (
+	securityPublicKey = "publicKey"
+	securityToken     = "token"
+)
+
+var (
+	schemaTypes = map[string]bool{
+		"string":  true,
+		"number":  true,
+		"integer": true,
+		"boolean": true,
+		"object":  true,
+		"array":   true,
+	}
+
+	fieldName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// ValidateSchema checks a collection schema's fields have a valid name and
+// type
+func ValidateSchema(cs model.CollectionSchema) error {
+	if len(cs.Collection) == 0 {
+		return fmt.Errorf("the schema's collection is required")
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range cs.Fields {
+		if !fieldName.MatchString(f.Name) {
+			return fmt.Errorf("invalid field name %q in the %s schema", f.Name, cs.Collection)
+		} else if seen[f.Name] {
+			return fmt.Errorf("duplicate field %s in the %s schema", f.Name, cs.Collection)
+		} else if !schemaTypes[f.Type] {
+			return fmt.Errorf("invalid type %q for the field %s of the %s schema", f.Type, f.Name, cs.Collection)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+// Generate returns the OpenAPI document of an application
+func Generate(app App) Document {
+	doc := Document{
+		OpenAPI: Version,
+		Info:    Info{Title: app.Name + " API", Version: "1.0.0"},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Login": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"email":    {Type: "string", Format: "email"},
+						"password": {Type: "string", Format: "password"},
+					},
+					Required: []string{"email", "password"},
+				},
+				"SavedFile": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"id":  {Type: "string"},
+						"url": {Type: "string"},
+					},
+				},
+				"Error": {Type: "string"},
+			},
+			SecuritySchemes: map[string]SecurityScheme{
+				securityPublicKey: {Type: "apiKey", In: "header", Name: "SB-PUBLIC-KEY"},
+				securityToken:     {Type: "http", Scheme: "bearer"},
+			},
+		},
+		Security: []map[string][]string{{securityPublicKey: {}, securityToken: {}}},
+	}
+
+	if len(app.ServerURL) > 0 {
+		doc.Servers = []Server{{URL: strings.TrimSuffix(app.ServerURL, "/")}}
+	}
+
+	addAuth(&doc)
+	addStorage(&doc)
+
+	for _, cs := range CollectionSchemas(app.Collections, app.Schemas) {
+		addCollection(&doc, cs.Collection, cs)
+	}
+
+	for _, form := range app.Forms {
+		addForm(&doc, form)
+	}
+
+	for _, fn := range app.Functions {
+		addFunction(&doc, fn)
+	}
+
+	return doc
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// responses returns a successful response with its schema, a nil schema
+// has no content, and the error response
+func responses(code, description string, s *Schema) map[string]Response {
+	ok := Response{Description: description}
+	if s != nil {
+		ok.Content = jsonContent(s)
+	}
+
+	return map[string]Response{
+		code:      ok,
+		"default": {Description: "error", Content: map[string]MediaType{"text/plain": {Schema: ref("Error")}}},
+	}
+}
+
+// publicOnly is the security of the endpoints not requiring a user
+var publicOnly = []map[string][]string{{securityPublicKey: {}}}
+
+func addAuth(doc *Document) {
+	for _, path := range []string{"/register", "/login"} {
+		name := strings.TrimPrefix(path, "/")
+		doc.Paths[path] = PathItem{
+			"post": {
+				OperationID: name,
+				Summary:     "Returns the session token of a user",
+				Tags:        []string{"auth"},
+				RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("Login"))},
+				Responses:   responses("200", "session token", &Schema{Type: "string"}),
+				Security:    publicOnly,
+			},
+		}
+	}
+
+	doc.Paths["/me"] = PathItem{
+		"get": {
+			OperationID: "me",
+			Summary:     "Returns the current user",
+			Tags:        []string{"auth"},
+			Responses:   responses("200", "the current user", &Schema{Type: "object", AdditionalProperties: true}),
+		},
+	}
+}
+
+func addStorage(doc *Document) {
+	doc.Paths["/storage/upload"] = PathItem{
+		"post": {
+			OperationID: "uploadFile",
+			Tags:        []string{"storage"},
+			RequestBody: &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"multipart/form-data": {Schema: &Schema{
+						Type: "object",
+						Properties: map[string]*Schema{
+							"file": {Type: "string", Format: "binary"},
+							"name": {Type: "string"},
+						},
+						Required: []string{"file"},
+					}},
+				},
+			},
+			Responses: responses("200", "the saved file", ref("SavedFile")),
+		},
+	}
+
+	doc.Paths["/storage/delete"] = PathItem{
+		"get": {
+			OperationID: "deleteFile",
+			Tags:        []string{"storage"},
+			Parameters:  []Parameter{{Name: "id", In: "query", Required: true, Schema: &Schema{Type: "string"}}},
+			Responses:   responses("200", "the file is deleted", &Schema{Type: "boolean"}),
+		},
+	}
+}
+
+// CollectionSchemas returns the schemas of the collections and of the
+// collections with a schema even if still empty, sorted by name. The
+// system collections are excluded and the collections without a schema
+// have no fields.
+func CollectionSchemas(collections []string, schemas []model.CollectionSchema) []model.CollectionSchema {
+	bySchema := make(map[string]model.CollectionSchema)
+	for _, cs := range schemas {
+		bySchema[cs.Collection] = cs
+	}
+
+	cols := append([]string{}, collections...)
+	for name := range bySchema {
+		cols = append(cols, name)
+	}
+	sort.Strings(cols)
+
+	var list []model.CollectionSchema
+	for i, col := range cols {
+		// system collections are not accessible with the database API
+		if strings.HasPrefix(col, "sb_") || (i > 0 && cols[i-1] == col) {
+			continue
+		}
+
+		cs := bySchema[col]
+		cs.Collection = col
+		list = append(list, cs)
+	}
+	return list
+}
+
+// Identifier returns a camel case identifier of a name for the operation
+// IDs, i.e. user_tasks is UserTasks
+func Identifier(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
+
+// collectionSchema returns the schema of a collection's documents, a
+// free-form object with its id and accountId when no fields are defined
+func collectionSchema(cs model.CollectionSchema) *Schema {
+	s := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"id":        {Type: "string", ReadOnly: true},
+			"accountId": {Type: "string", ReadOnly: true},
+		},
+	}
+
+	if len(cs.Fields) == 0 {
+		s.AdditionalProperties = true
+		return s
+	}
+
+	for _, f := range cs.Fields {
+		prop := &Schema{Type: f.Type, Format: f.Format}
+		switch f.Type {
+		case "object":
+			prop.AdditionalProperties = true
+		case "array":
+			prop.Items = &Schema{}
+		}
+
+		s.Properties[f.Name] = prop
+		if f.Required {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	return s
+}
+
+func addCollection(doc *Document, col string, cs model.CollectionSchema) {
+	id := Identifier(col)
+	doc.Components.Schemas[id] = collectionSchema(cs)
+
+	tags := []string{"database"}
+	idParam := Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}
+	body := &RequestBody{Required: true, Content: jsonContent(ref(id))}
+
+	paged := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"page":    {Type: "integer"},
+			"size":    {Type: "integer"},
+			"total":   {Type: "integer"},
+			"next":    {Type: "string"},
+			"prev":    {Type: "string"},
+			"results": {Type: "array", Items: ref(id)},
+		},
+	}
+
+	doc.Paths["/db/"+col] = PathItem{
+		"get": {
+			OperationID: "list" + id,
+			Tags:        tags,
+			Parameters: []Parameter{
+				{Name: "page", In: "query", Schema: &Schema{Type: "integer"}},
+				{Name: "cursor", In: "query", Schema: &Schema{Type: "string"}},
+				{Name: "size", In: "query", Schema: &Schema{Type: "integer"}},
+				{Name: "desc", In: "query", Schema: &Schema{Type: "boolean"}},
+				{Name: "sort", In: "query", Schema: &Schema{Type: "string"}},
+				{Name: "filter", In: "query", Schema: &Schema{Type: "string"}},
+			},
+			Responses: responses("200", "a page of documents", paged),
+		},
+		"post": {
+			OperationID: "create" + id,
+			Tags:        tags,
+			RequestBody: body,
+			Responses:   responses("201", "the created document", ref(id)),
+		},
+	}
+
+	doc.Paths["/db/"+col+"/{id}"] = PathItem{
+		"get": {
+			OperationID: "get" + id,
+			Tags:        tags,
+			Parameters:  []Parameter{idParam},
+			Responses:   responses("200", "the document", ref(id)),
+		},
+		"put": {
+			OperationID: "update" + id,
+			Tags:        tags,
+			Parameters:  []Parameter{idParam},
+			RequestBody: &RequestBody{Required: true, Content: jsonContent(&Schema{Type: "object", AdditionalProperties: true})},
+			Responses:   responses("200", "the updated document", ref(id)),
+		},
+		"delete": {
+			OperationID: "delete" + id,
+			Tags:        tags,
+			Parameters:  []Parameter{idParam},
+			Responses:   responses("200", "the number of deleted documents", &Schema{Type: "integer"}),
+		},
+	}
+
+	// the query clauses are [field, operator, value] arrays
+	clauses := &Schema{Type: "array", Items: &Schema{Type: "array", Items: &Schema{}}}
+	doc.Paths["/query/"+col] = PathItem{
+		"post": {
+			OperationID: "query" + id,
+			Tags:        tags,
+			RequestBody: &RequestBody{Required: true, Content: jsonContent(clauses)},
+			Responses:   responses("200", "a page of the matching documents", paged),
+		},
+	}
+}
+
+func addForm(doc *Document, form string) {
+	fields := &Schema{Type: "object", AdditionalProperties: true}
+	doc.Paths["/postform/"+form] = PathItem{
+		"post": {
+			OperationID: "submit" + Identifier(form) + "Form",
+			Tags:        []string{"forms"},
+			RequestBody: &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"application/x-www-form-urlencoded": {Schema: fields},
+					"multipart/form-data":               {Schema: fields},
+				},
+			},
+			Responses: responses("200", "the submission is saved", &Schema{Type: "boolean"}),
+			Security:  publicOnly,
+		},
+	}
+}
+
+func addFunction(doc *Document, fn string) {
+	doc.Paths["/fn/exec/"+fn] = PathItem{
+		"post": {
+			OperationID: "exec" + Identifier(fn),
+			Tags:        []string{"functions"},
+			RequestBody: &RequestBody{Content: jsonContent(&Schema{})},
+			Responses:   responses("200", "the function's response", nil),
+		},
+	}
+}
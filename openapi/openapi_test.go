@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestGenerate(t *testing.T) {
+	app := App{
+		Name:        "myapp",
+		ServerURL:   "https://api.example.com/",
+		Collections: []string{"tasks", "sb_forms", "user_notes"},
+		Schemas: []model.CollectionSchema{
+			{
+				Collection: "tasks",
+				Fields: []model.SchemaField{
+					{Name: "title", Type: "string", Required: true},
+					{Name: "due", Type: "string", Format: "date-time"},
+					{Name: "done", Type: "boolean"},
+				},
+			},
+			{Collection: "projects", Fields: []model.SchemaField{{Name: "name", Type: "string"}}},
+		},
+		Forms:     []string{"contact"},
+		Functions: []string{"send-report"},
+	}
+
+	doc := Generate(app)
+
+	if doc.OpenAPI != Version || doc.Servers[0].URL != "https://api.example.com" {
+		t.Errorf("unexpected document header %v %v", doc.OpenAPI, doc.Servers)
+	}
+
+	paths := []string{
+		"/login", "/register", "/me", "/storage/upload",
+		"/db/tasks", "/db/tasks/{id}", "/query/tasks",
+		"/db/user_notes", "/db/projects",
+		"/postform/contact", "/fn/exec/send-report",
+	}
+	for _, p := range paths {
+		if _, ok := doc.Paths[p]; !ok {
+			t.Errorf("expected path %s", p)
+		}
+	}
+
+	if _, ok := doc.Paths["/db/sb_forms"]; ok {
+		t.Error("expected the system collections to be excluded")
+	}
+
+	tasks := doc.Components.Schemas["Tasks"]
+	if tasks == nil {
+		t.Fatal("expected the Tasks schema")
+	} else if tasks.Properties["due"].Format != "date-time" || len(tasks.Required) != 1 || tasks.Required[0] != "title" {
+		t.Errorf("unexpected tasks schema %v", tasks)
+	} else if tasks.AdditionalProperties != nil {
+		t.Errorf("expected a defined schema to not be free-form")
+	}
+
+	if notes := doc.Components.Schemas["UserNotes"]; notes == nil || notes.AdditionalProperties != true {
+		t.Errorf("expected a free-form schema without fields got %v", notes)
+	}
+
+	if op := doc.Paths["/db/tasks"]["post"]; op.OperationID != "createTasks" {
+		t.Errorf("unexpected operation ID %s", op.OperationID)
+	}
+
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		cs    model.CollectionSchema
+		valid bool
+	}{
+		{"valid", model.CollectionSchema{Collection: "tasks", Fields: []model.SchemaField{{Name: "title", Type: "string"}}}, true},
+		{"no collection", model.CollectionSchema{Fields: []model.SchemaField{{Name: "title", Type: "string"}}}, false},
+		{"invalid type", model.CollectionSchema{Collection: "tasks", Fields: []model.SchemaField{{Name: "title", Type: "text"}}}, false},
+		{"invalid name", model.CollectionSchema{Collection: "tasks", Fields: []model.SchemaField{{Name: "a b", Type: "string"}}}, false},
+		{"duplicate", model.CollectionSchema{Collection: "tasks", Fields: []model.SchemaField{{Name: "a", Type: "string"}, {Name: "a", Type: "number"}}}, false},
+	}
+
+	for _, tc := range tests {
+		if err := ValidateSchema(tc.cs); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid %v got %v", tc.name, tc.valid, err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package staticbackend
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/openapi"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	resp := dbReq(t, openAPI, "GET", "/openapi.json", nil, true)
+	defer resp.Body.Close()
+
+	var doc openapi.Document
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("expected OpenAPI %s got %s", openapi.Version, doc.OpenAPI)
+	} else if _, ok := doc.Paths["/login"]; !ok {
+		t.Errorf("expected the auth endpoints got %v", doc.Paths)
+	}
+
+	for path := range doc.Paths {
+		if strings.HasPrefix(path, "/db/sb_") {
+			t.Errorf("unexpected system collection %s", path)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package staticbackend
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// pageCursor returns the opaque cursor of a page, clients follow the next
+// and prev cursors instead of computing the pages
+func pageCursor(page int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("page:" + strconv.FormatInt(page, 10)))
+}
+
+// parseCursor returns the page of a cursor
+func parseCursor(cursor string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	s := string(b)
+	if !strings.HasPrefix(s, "page:") {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	page, err := strconv.ParseInt(strings.TrimPrefix(s, "page:"), 10, 64)
+	if err != nil || page < 1 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return page, nil
+}
+
+// paginateResult sets the cursors and Link header of a page of documents
+func paginateResult(w http.ResponseWriter, r *http.Request, result *model.PagedResult) {
+	result.Next, result.Prev = paginate(w, r, result.Page, result.Size, result.Total, len(result.Results))
+}
+
+// paginate sets the RFC 5988 Link header of a page and returns its next and
+// prev cursors. When the total is unknown, below zero, a full page has a
+// next page.
+func paginate(w http.ResponseWriter, r *http.Request, page, size, total int64, count int) (next, prev string) {
+	if page < 1 || size < 1 {
+		return
+	}
+
+	if total < 0 {
+		if int64(count) >= size {
+			next = pageCursor(page + 1)
+		}
+	} else if page*size < total {
+		next = pageCursor(page + 1)
+	}
+
+	if page > 1 {
+		prev = pageCursor(page - 1)
+	}
+
+	links := []string{pageLink(r, pageCursor(1), "first")}
+	if len(prev) > 0 {
+		links = append(links, pageLink(r, prev, "prev"))
+	}
+	if len(next) > 0 {
+		links = append(links, pageLink(r, next, "next"))
+	}
+	if total >= 0 {
+		last := (total + size - 1) / size
+		if last < 1 {
+			last = 1
+		}
+		links = append(links, pageLink(r, pageCursor(last), "last"))
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+	return
+}
+
+// pageLink returns the link of the request's URL at a cursor, the request
+// URI keeps the API version prefix
+func pageLink(r *http.Request, cursor, rel string) string {
+	u, err := url.ParseRequestURI(r.RequestURI)
+	if err != nil || len(r.RequestURI) == 0 {
+		u = &url.URL{Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+	}
+
+	qs := u.Query()
+	qs.Del("page")
+	qs.Set("cursor", cursor)
+
+	return fmt.Sprintf(`<%s?%s>; rel="%s"`, u.Path, qs.Encode(), rel)
+}
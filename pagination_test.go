@@ -0,0 +1,55 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestPaginationCursors(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		resp := dbReq(t, db.dbreq, "POST", "/db/pagination", map[string]interface{}{"n": i})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatal(GetResponseBody(t, resp))
+		}
+	}
+
+	resp := dbReq(t, db.dbreq, "GET", "/db/pagination?size=2", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var first model.PagedResult
+	if err := parseBody(resp.Body, &first); err != nil {
+		t.Fatal(err)
+	} else if first.Total != 3 || len(first.Next) == 0 || len(first.Prev) > 0 {
+		t.Fatalf("expected a next cursor only got %+v", first)
+	}
+
+	link := resp.Header.Get("Link")
+	for _, rel := range []string{"first", "next", "last"} {
+		if !strings.Contains(link, fmt.Sprintf(`rel="%s"`, rel)) {
+			t.Errorf("expected the %s link in %s", rel, link)
+		}
+	}
+	if strings.Contains(link, `rel="prev"`) {
+		t.Errorf("expected no prev link in %s", link)
+	}
+
+	resp = dbReq(t, db.dbreq, "GET", "/db/pagination?size=2&cursor="+first.Next, nil)
+	var second model.PagedResult
+	if err := parseBody(resp.Body, &second); err != nil {
+		t.Fatal(err)
+	} else if second.Page != 2 || len(second.Results) != 1 {
+		t.Errorf("expected the last document on page 2 got %+v", second)
+	} else if len(second.Next) > 0 || second.Prev != pageCursor(1) {
+		t.Errorf("expected a prev cursor only got next %q prev %q", second.Next, second.Prev)
+	}
+
+	if _, err := parseCursor("bad"); err == nil {
+		t.Error("expected an invalid cursor error")
+	}
+}
@@ -0,0 +1,93 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func pushSubscribe(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var sub model.PushSubscription
+	if err := parseBody(r.Body, &sub); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(sub.Channel) == 0 || len(sub.Endpoint) == 0 {
+		http.Error(w, "channel and endpoint are required", http.StatusBadRequest)
+		return
+	} else if sub.Kind != model.PushKindWebPush && sub.Kind != model.PushKindFCM {
+		http.Error(w, "kind must be webpush or fcm", http.StatusBadRequest)
+		return
+	} else if sub.Kind == model.PushKindWebPush && (len(sub.P256dh) == 0 || len(sub.Auth) == 0) {
+		http.Error(w, "p256dh and auth keys are required for webpush", http.StatusBadRequest)
+		return
+	} else if model.ReservedChannel(sub.Channel) {
+		http.Error(w, "you cannot subscribe to a reserved channel", http.StatusBadRequest)
+		return
+	} else if strings.HasPrefix(sub.Channel, model.UserChannelPrefix) &&
+		sub.Channel != model.UserChannel(auth.AccountID) {
+		http.Error(w, "you cannot subscribe to another user's channel", http.StatusForbidden)
+		return
+	}
+
+	// the notifications are posted to the Web Push endpoint
+	if sub.Kind == model.PushKindWebPush {
+		u, err := url.Parse(sub.Endpoint)
+		if err != nil || u.Scheme != "https" || len(u.Host) == 0 {
+			http.Error(w, "the webpush endpoint must be an https URL", http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub.AccountID = auth.AccountID
+	sub.UserID = auth.UserID
+	sub.Created = time.Now()
+
+	id, err := backend.DB.AddPushSubscription(conf.Name, sub)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, id)
+}
+
+func pushUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 3)
+
+	if err := backend.DB.DeletePushSubscription(conf.Name, auth.AccountID, id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+// vapidKey returns the public key browsers need to create a Web Push
+// subscription
+func vapidKey(w http.ResponseWriter, r *http.Request) {
+	if backend.WebPush == nil {
+		http.Error(w, "Web Push is not configured", http.StatusNotFound)
+		return
+	}
+
+	respond(w, http.StatusOK, backend.WebPush.PublicKey())
+}
@@ -0,0 +1,108 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/staticbackendhq/core/model"
+	"golang.org/x/oauth2/jwt"
+)
+
+// FCMEndpoint is the Firebase Cloud Messaging HTTP v1 send URL, %s is the
+// project ID
+var FCMEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+
+// FCM sends notifications with the Firebase Cloud Messaging HTTP v1 API
+type FCM struct {
+	projectID string
+	client    *http.Client
+}
+
+// NewFCM returns a FCM sender from the service account JSON file of the
+// Firebase project
+func NewFCM(credentialsFile string) (*FCM, error) {
+	b, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var sa struct {
+		ProjectID    string `json:"project_id"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(b, &sa); err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	} else if len(sa.ProjectID) == 0 || len(sa.ClientEmail) == 0 {
+		return nil, errors.New("invalid FCM credentials: missing project_id or client_email")
+	}
+
+	if len(sa.TokenURI) == 0 {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	conf := &jwt.Config{
+		Email:        sa.ClientEmail,
+		PrivateKey:   []byte(sa.PrivateKey),
+		PrivateKeyID: sa.PrivateKeyID,
+		TokenURL:     sa.TokenURI,
+		Scopes:       []string{"https://www.googleapis.com/auth/firebase.messaging"},
+	}
+
+	return &FCM{
+		projectID: sa.ProjectID,
+		client:    conf.Client(context.Background()),
+	}, nil
+}
+
+// Send posts the notification to the registration token of the
+// subscription
+func (f *FCM) Send(sub model.PushSubscription, n Notification) error {
+	msg := map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": sub.Endpoint,
+			"notification": map[string]string{
+				"title": n.Title,
+				"body":  n.Body,
+			},
+			"data": map[string]string{
+				"channel": n.Channel,
+				"type":    n.Type,
+				"data":    n.Data,
+			},
+		},
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf(FCMEndpoint, f.projectID)
+	resp, err := f.client.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// the registration token is no longer valid
+		return ErrExpired
+	} else if resp.StatusCode > 299 {
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("error returned by FCM: %s", string(b))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Package push forwards channel messages as Web Push and FCM notifications
+// to subscribers that are not connected.
+package push
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/database"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+// ErrExpired is returned by a Sender when the subscription is no longer
+// valid and should be removed
+var ErrExpired = errors.New("push subscription expired")
+
+// maxBodyLength is the maximum length of a notification body taken from the
+// message data
+const maxBodyLength = 200
+
+// Notification is the content delivered to a device
+type Notification struct {
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Channel string `json:"channel"`
+	Type    string `json:"type"`
+	Data    string `json:"data"`
+}
+
+// JSON returns the notification encoded as JSON
+func (n Notification) JSON() ([]byte, error) {
+	return json.Marshal(n)
+}
+
+// Sender delivers a notification to a subscription
+type Sender interface {
+	Send(sub model.PushSubscription, n Notification) error
+}
+
+// Notifier sends channel messages to the push subscriptions of the users
+// that are not present in the channel
+type Notifier struct {
+	DataStore database.Persister
+	Volatile  cache.Volatilizer
+	Log       *logger.Logger
+	// Senders are the available senders by subscription kind
+	Senders map[string]Sender
+}
+
+// Notify sends the message to the offline subscribers of its channel if a
+// push rule of the database matches the channel
+func (n *Notifier) Notify(conf model.DatabaseConfig, msg model.Command) {
+	rule, ok := matchRule(conf.Settings.Realtime.PushRules, msg.Channel)
+	if !ok {
+		return
+	}
+
+	subs, err := n.DataStore.ListPushSubscriptions(conf.Name, msg.Channel)
+	if err != nil {
+		n.Log.Error().Err(err).Msg("error listing push subscriptions")
+		return
+	} else if len(subs) == 0 {
+		return
+	}
+
+	members, err := cache.Presence(n.Volatile, conf.Name, msg.Channel)
+	if err != nil {
+		n.Log.Error().Err(err).Msg("error getting channel presence")
+		return
+	}
+
+	online := make(map[string]bool)
+	for _, m := range members {
+		online[m.AccountID] = true
+	}
+
+	notif := Notification{
+		Title:   rule.Title,
+		Body:    rule.Body,
+		Channel: msg.Channel,
+		Type:    msg.Type,
+		Data:    msg.Data,
+	}
+	if len(notif.Body) == 0 {
+		notif.Body = msg.Data
+		if len(notif.Body) > maxBodyLength {
+			notif.Body = notif.Body[:maxBodyLength]
+		}
+	}
+
+	for _, sub := range subs {
+		if online[sub.AccountID] {
+			continue
+		}
+
+		sender, ok := n.Senders[sub.Kind]
+		if !ok {
+			continue
+		}
+
+		if err := sender.Send(sub, notif); errors.Is(err, ErrExpired) {
+			if err := n.DataStore.DeletePushSubscription(conf.Name, sub.AccountID, sub.ID); err != nil {
+				n.Log.Error().Err(err).Msg("error removing expired push subscription")
+			}
+		} else if err != nil {
+			n.Log.Error().Err(err).Msgf("error sending %s push notification", sub.Kind)
+		}
+	}
+}
+
+func matchRule(rules []model.PushRule, channel string) (model.PushRule, bool) {
+	for _, r := range rules {
+		if r.Matches(channel) {
+			return r, true
+		}
+	}
+	return model.PushRule{}, false
+}
@@ -0,0 +1,83 @@
+package push
+
+import (
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/database/memory"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+type fakeSender struct {
+	sent []model.PushSubscription
+	err  error
+}
+
+func (f *fakeSender) Send(sub model.PushSubscription, n Notification) error {
+	f.sent = append(f.sent, sub)
+	return f.err
+}
+
+func TestNotifyOfflineSubscribers(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+	volatile := cache.NewDevCache(log)
+	datastore := memory.New(volatile.PublishDocument)
+
+	conf := model.DatabaseConfig{Name: "pushtest"}
+	conf.Settings.Realtime.PushRules = []model.PushRule{
+		{Channel: "chat-*", Title: "New message"},
+	}
+
+	for _, acctID := range []string{"online", "offline"} {
+		sub := model.PushSubscription{
+			AccountID: acctID,
+			UserID:    acctID,
+			Channel:   "chat-1",
+			Kind:      model.PushKindWebPush,
+			Endpoint:  "https://push.example.com/" + acctID,
+			Created:   time.Now(),
+		}
+		if _, err := datastore.AddPushSubscription(conf.Name, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	member := model.PresenceMember{SID: "sid-1", AccountID: "online", Joined: time.Now()}
+	if err := cache.AddPresence(volatile, conf.Name, "chat-1", member); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &fakeSender{}
+	n := &Notifier{
+		DataStore: datastore,
+		Volatile:  volatile,
+		Log:       log,
+		Senders:   map[string]Sender{model.PushKindWebPush: sender},
+	}
+
+	n.Notify(conf, model.Command{Type: "msg", Channel: "other", Data: "ignored"})
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no notification for a channel without rule got %d", len(sender.sent))
+	}
+
+	n.Notify(conf, model.Command{Type: "msg", Channel: "chat-1", Data: "hello"})
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 notification got %d", len(sender.sent))
+	} else if sender.sent[0].AccountID != "offline" {
+		t.Errorf("expected the offline account to be notified got %s", sender.sent[0].AccountID)
+	}
+
+	// expired subscriptions are removed
+	sender.err = ErrExpired
+	n.Notify(conf, model.Command{Type: "msg", Channel: "chat-1", Data: "hello"})
+
+	subs, err := datastore.ListPushSubscriptions(conf.Name, "chat-1")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(subs) != 1 {
+		t.Errorf("expected the expired subscription to be removed, %d left", len(subs))
+	}
+}
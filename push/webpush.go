@@ -0,0 +1,227 @@
+package push
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/staticbackendhq/core/internal"
+	"github.com/staticbackendhq/core/model"
+	"golang.org/x/crypto/hkdf"
+)
+
+// WebPush sends notifications with the Web Push protocol using VAPID
+// (RFC 8292) and the aes128gcm content encoding (RFC 8291)
+type WebPush struct {
+	key     *ecdsa.PrivateKey
+	subject string
+	// client only connects to public addresses, the endpoints are
+	// provided by the users
+	client *http.Client
+}
+
+// NewWebPush returns a WebPush sender from a base64url encoded VAPID private
+// key and a contact subject (mailto: or https: URL)
+func NewWebPush(privateKey, subject string) (*WebPush, error) {
+	d, err := decodeKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	} else if len(d) != 32 {
+		return nil, errors.New("invalid VAPID private key: expected 32 bytes")
+	}
+
+	curve := elliptic.P256()
+
+	key := new(ecdsa.PrivateKey)
+	key.Curve = curve
+	key.D = new(big.Int).SetBytes(d)
+	key.X, key.Y = curve.ScalarBaseMult(d)
+
+	return &WebPush{
+		key:     key,
+		subject: subject,
+		client:  internal.PublicClient(10 * time.Second),
+	}, nil
+}
+
+// PublicKey returns the VAPID public key clients use as their
+// applicationServerKey when subscribing
+func (wp *WebPush) PublicKey() string {
+	pub := elliptic.Marshal(wp.key.Curve, wp.key.X, wp.key.Y)
+	return base64.RawURLEncoding.EncodeToString(pub)
+}
+
+// Send encrypts and posts the notification to the subscription endpoint
+func (wp *WebPush) Send(sub model.PushSubscription, n Notification) error {
+	payload, err := n.JSON()
+	if err != nil {
+		return err
+	}
+
+	body, err := encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+
+	auth, err := wp.vapid(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("TTL", "86400")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Authorization", auth)
+
+	resp, err := wp.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return ErrExpired
+	} else if resp.StatusCode > 299 {
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("error returned by the push service: %s", string(b))
+	}
+	return nil
+}
+
+// vapid returns the Authorization header value for the endpoint's origin
+func (wp *WebPush) vapid(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	pl := jwt.Payload{
+		Audience:       jwt.Audience{u.Scheme + "://" + u.Host},
+		Subject:        wp.subject,
+		ExpirationTime: jwt.NumericDate(time.Now().Add(12 * time.Hour)),
+	}
+
+	token, err := jwt.Sign(pl, jwt.NewES256(jwt.ECDSAPrivateKey(wp.key)))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("vapid t=%s, k=%s", token, wp.PublicKey()), nil
+}
+
+// encrypt encrypts the payload for the subscription keys as a single
+// aes128gcm record
+func encrypt(sub model.PushSubscription, payload []byte) ([]byte, error) {
+	curve := elliptic.P256()
+
+	uaPublic, err := decodeKey(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	uaX, uaY := elliptic.Unmarshal(curve, uaPublic)
+	if uaX == nil {
+		return nil, errors.New("invalid p256dh key: not a P-256 point")
+	}
+
+	authSecret, err := decodeKey(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	// the application server keys are generated for each message
+	asPrivate, asX, asY, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := elliptic.Marshal(curve, asX, asY)
+
+	sx, _ := curve.ScalarMult(uaX, uaY, asPrivate)
+	ecdhSecret := make([]byte, 32)
+	sx.FillBytes(ecdhSecret)
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+
+	ikm, err := expand(hkdf.Extract(sha256.New, ecdhSecret, authSecret), keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+
+	cek, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 is the padding delimiter of the last record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	// header: salt | record size | key id length | key id
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = header[:len(header)+4]
+	binary.BigEndian.PutUint32(header[16:], 4096)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func expand(prk, info []byte, size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// decodeKey decodes base64url keys, padded or not
+func decodeKey(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
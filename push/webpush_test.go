@@ -0,0 +1,157 @@
+package push
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+	"golang.org/x/crypto/hkdf"
+)
+
+func newVAPIDKey(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := make([]byte, 32)
+	key.D.FillBytes(d)
+	return base64.RawURLEncoding.EncodeToString(d)
+}
+
+// decrypt is the user agent side of RFC 8291
+func decrypt(t *testing.T, uaPrivate, uaPublic, authSecret, body []byte) []byte {
+	curve := elliptic.P256()
+
+	salt := body[:16]
+	idlen := int(body[20])
+	asPublic := body[21 : 21+idlen]
+	ciphertext := body[21+idlen:]
+
+	asX, asY := elliptic.Unmarshal(curve, asPublic)
+	sx, _ := curve.ScalarMult(asX, asY, uaPrivate)
+	ecdhSecret := make([]byte, 32)
+	sx.FillBytes(ecdhSecret)
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+
+	ikm, err := expand(hkdf.Extract(sha256.New, ecdhSecret, authSecret), keyInfo, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek, _ := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce, _ := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// remove the padding delimiter
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestWebPushSend(t *testing.T) {
+	curve := elliptic.P256()
+
+	uaPrivate, x, y, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uaPublic := elliptic.Marshal(curve, x, y)
+
+	authSecret := make([]byte, 16)
+	if _, err := rand.Read(authSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	var received Notification
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/expired") {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "vapid t=") {
+			t.Errorf("expected a VAPID authorization got %s", r.Header.Get("Authorization"))
+		} else if r.Header.Get("Content-Encoding") != "aes128gcm" {
+			t.Errorf("expected aes128gcm encoding got %s", r.Header.Get("Content-Encoding"))
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := decrypt(t, uaPrivate, uaPublic, authSecret, body)
+		if err := json.Unmarshal(b, &received); err != nil {
+			t.Fatal(err)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	wp, err := NewWebPush(newVAPIDKey(t), "mailto:admin@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub := model.PushSubscription{
+		Kind:     model.PushKindWebPush,
+		Endpoint: ts.URL + "/push/abc",
+		P256dh:   base64.RawURLEncoding.EncodeToString(uaPublic),
+		Auth:     base64.RawURLEncoding.EncodeToString(authSecret),
+	}
+
+	n := Notification{Title: "New message", Body: "hello", Channel: "chat-1"}
+
+	// the test server listens on a loopback address
+	if err := wp.Send(sub, n); err == nil || !strings.Contains(err.Error(), "private address") {
+		t.Fatalf("expected the private address to be refused got %v", err)
+	}
+	wp.client = ts.Client()
+
+	sub = model.PushSubscription{
+		Kind:     model.PushKindWebPush,
+		Endpoint: ts.URL + "/push/abc",
+		P256dh:   base64.RawURLEncoding.EncodeToString(uaPublic),
+		Auth:     base64.RawURLEncoding.EncodeToString(authSecret),
+	}
+
+	if err := wp.Send(sub, n); err != nil {
+		t.Fatal(err)
+	} else if received != n {
+		t.Errorf("expected %v got %v", n, received)
+	}
+
+	sub.Endpoint = ts.URL + "/push/expired"
+	if err := wp.Send(sub, n); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired got %v", err)
+	}
+}
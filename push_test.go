@@ -0,0 +1,83 @@
+package staticbackend
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestPushSubscribe(t *testing.T) {
+	sub := model.PushSubscription{
+		Channel:  model.UserChannel(testAccountID),
+		Kind:     model.PushKindFCM,
+		Endpoint: "fcm-registration-token",
+	}
+
+	resp := dbReq(t, pushSubscribe, "POST", "/push/subscribe", sub)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var id string
+	if err := parseBody(resp.Body, &id); err != nil {
+		t.Fatal(err)
+	}
+
+	subs, err := backend.DB.ListPushSubscriptions(dbName, sub.Channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(subs) != 1 {
+		t.Fatalf("expected 1 subscription got %d", len(subs))
+	} else if subs[0].AccountID != testAccountID {
+		t.Errorf("expected account %s got %s", testAccountID, subs[0].AccountID)
+	}
+
+	resp2 := dbReq(t, pushUnsubscribe, "POST", "/push/unsubscribe/"+id, nil)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp2))
+	}
+
+	subs, err = backend.DB.ListPushSubscriptions(dbName, sub.Channel)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(subs) != 0 {
+		t.Errorf("expected no subscription got %d", len(subs))
+	}
+}
+
+func TestPushSubscribeOtherUserChannel(t *testing.T) {
+	sub := model.PushSubscription{
+		Channel:  model.UserChannel("someone-else"),
+		Kind:     model.PushKindFCM,
+		Endpoint: "fcm-registration-token",
+	}
+
+	resp := dbReq(t, pushSubscribe, "POST", "/push/subscribe", sub)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 403 {
+		t.Errorf("expected status 403 got %d", resp.StatusCode)
+	}
+}
+
+func TestPushSubscribeInsecureEndpoint(t *testing.T) {
+	sub := model.PushSubscription{
+		Channel:  model.UserChannel(testAccountID),
+		Kind:     model.PushKindWebPush,
+		Endpoint: "http://169.254.169.254/latest/meta-data",
+		P256dh:   "p256dh",
+		Auth:     "auth",
+	}
+
+	resp := dbReq(t, pushSubscribe, "POST", "/push/subscribe", sub)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status 400 got %d", resp.StatusCode)
+	}
+}
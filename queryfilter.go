@@ -0,0 +1,85 @@
+package staticbackend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// filterOperators are the operators of the filter query string parameter,
+// the two-character ones are matched first
+var filterOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// parseFilterParam parses the filter query string parameter into the query
+// clauses accepted by ParseQuery, i.e. status==active,total>=100 becomes
+// [["status", "==", "active"], ["total", ">=", 100]]. Values are numbers,
+// booleans or strings, a value can be double quoted to keep it a string or
+// to contain a comma.
+func parseFilterParam(s string) ([][]interface{}, error) {
+	var clauses [][]interface{}
+
+	for _, expr := range splitFilter(s) {
+		expr = strings.TrimSpace(expr)
+		if len(expr) == 0 {
+			continue
+		}
+
+		field, op, raw := "", "", ""
+		for _, o := range filterOperators {
+			if i := strings.Index(expr, o); i > 0 {
+				field, op, raw = strings.TrimSpace(expr[:i]), o, strings.TrimSpace(expr[i+len(o):])
+				break
+			}
+		}
+
+		if len(op) == 0 || len(field) == 0 {
+			return nil, fmt.Errorf("invalid filter %s, expected field, operator and value i.e. status==active", expr)
+		}
+
+		clauses = append(clauses, []interface{}{field, op, filterValue(raw)})
+	}
+	return clauses, nil
+}
+
+// splitFilter splits the filter on the commas outside of double quotes
+func splitFilter(s string) (exprs []string) {
+	quoted, start := false, 0
+	for i, c := range s {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				exprs = append(exprs, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(exprs, s[start:])
+}
+
+// filterValue converts a filter value to the type it would have in a JSON
+// query body
+func filterValue(raw string) interface{} {
+	if len(raw) >= 2 && strings.HasPrefix(raw, `"`) && strings.HasSuffix(raw, `"`) {
+		return raw[1 : len(raw)-1]
+	}
+
+	if raw == "true" || raw == "false" {
+		return raw == "true"
+	}
+
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f
+	}
+	return raw
+}
+
+// parseSortParam returns the field and direction of the sort query string
+// parameter, a - prefix sorts in descending order i.e. -created
+func parseSortParam(s string) (field string, desc bool) {
+	if strings.HasPrefix(s, "-") {
+		return s[1:], true
+	}
+	return strings.TrimPrefix(s, "+"), false
+}
@@ -0,0 +1,83 @@
+package staticbackend
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFilterParam(t *testing.T) {
+	tests := []struct {
+		filter  string
+		clauses [][]interface{}
+	}{
+		{"", nil},
+		{"status==active", [][]interface{}{{"status", "==", "active"}}},
+		{"status==active,total>=100", [][]interface{}{{"status", "==", "active"}, {"total", ">=", 100.0}}},
+		{"done!=true, count<3", [][]interface{}{{"done", "!=", true}, {"count", "<", 3.0}}},
+		{`code=="42",title=="a, b"`, [][]interface{}{{"code", "==", "42"}, {"title", "==", "a, b"}}},
+	}
+
+	for _, tc := range tests {
+		clauses, err := parseFilterParam(tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(clauses, tc.clauses) {
+			t.Errorf("%s: expected %v got %v", tc.filter, tc.clauses, clauses)
+		}
+	}
+
+	for _, invalid := range []string{"status", "==active"} {
+		if _, err := parseFilterParam(invalid); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+
+	if field, desc := parseSortParam("-created"); field != "created" || !desc {
+		t.Errorf("expected created descending got %s %v", field, desc)
+	}
+}
+
+func TestDBListWithFilter(t *testing.T) {
+	for i := 1; i <= 4; i++ {
+		task := Task{Title: "filtered", Count: i, Created: time.Now()}
+
+		resp := dbReq(t, db.add, "POST", "/db/filteredtasks", task)
+		defer resp.Body.Close()
+
+		if resp.StatusCode > 299 {
+			t.Fatal(GetResponseBody(t, resp))
+		}
+	}
+
+	qs := url.Values{}
+	qs.Set("filter", "count>=2,title==filtered")
+	qs.Set("sort", "-count")
+
+	resp := dbReq(t, db.list, "GET", "/db/filteredtasks?"+qs.Encode(), nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var result struct {
+		Total   int64  `json:"total"`
+		Results []Task `json:"results"`
+	}
+	if err := parseBody(resp.Body, &result); err != nil {
+		t.Fatal(err)
+	} else if result.Total != 3 || len(result.Results) != 3 {
+		t.Fatalf("expected 3 tasks got %d", len(result.Results))
+	} else if result.Results[0].Count != 4 || result.Results[2].Count != 2 {
+		t.Errorf("expected tasks sorted by count descending got %v", result.Results)
+	}
+
+	resp = dbReq(t, db.list, "GET", "/db/filteredtasks?filter=count", nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status 400 for an invalid filter got %d", resp.StatusCode)
+	}
+}
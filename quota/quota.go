@@ -0,0 +1,173 @@
+// Package quota enforces the plan quotas of the databases. The modules
+// consuming a resource check its quota here with their current usage and
+// return its Error as is so all the quotas are reported the same way.
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/database"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// Resources limited by the plans
+const (
+	// Storage is the bytes stored by a database, variants included
+	Storage = "storage"
+	// FunctionRuns is the function executions of a database per month
+	FunctionRuns = "function_runs"
+	// Emails is the emails sent by a tenant per month, all its databases
+	// included
+	Emails = "emails"
+	// Connections is the concurrent realtime connections of a database
+	// across all servers
+	Connections = "realtime_connections"
+)
+
+// Enforced are the resources whose quotas are enforced, they are set from
+// the server config on start
+var Enforced = map[string]bool{}
+
+// Limits are the quotas of the resources per plan, a zero or missing value
+// is unlimited
+var Limits = map[string]map[int]int64{
+	Storage: {
+		model.PlanFree:     1 << 30,
+		model.PlanIdea:     10 << 30,
+		model.PleanLaunch:  50 << 30,
+		model.PlanTraction: 200 << 30,
+		model.PlanGrowth:   1 << 40,
+	},
+	FunctionRuns: {
+		model.PlanFree:     10000,
+		model.PlanIdea:     100000,
+		model.PleanLaunch:  500000,
+		model.PlanTraction: 2000000,
+		model.PlanGrowth:   10000000,
+	},
+	Emails: {
+		model.PlanFree:     200,
+		model.PlanIdea:     2000,
+		model.PleanLaunch:  10000,
+		model.PlanTraction: 50000,
+		model.PlanGrowth:   200000,
+	},
+	Connections: {
+		model.PlanFree:     100,
+		model.PlanIdea:     500,
+		model.PleanLaunch:  2000,
+		model.PlanTraction: 5000,
+		model.PlanGrowth:   20000,
+	},
+}
+
+// monthly are the resources whose usage resets each month
+var monthly = map[string]bool{
+	FunctionRuns: true,
+	Emails:       true,
+}
+
+var messages = map[string]string{
+	Storage:      "storage quota of %d bytes exceeded",
+	FunctionRuns: "monthly function runs quota of %d exceeded",
+	Emails:       "monthly email quota of %d exceeded",
+	Connections:  "realtime connections quota of %d exceeded",
+}
+
+// ErrExceeded matches all the quota errors with errors.Is
+var ErrExceeded = errors.New("quota exceeded")
+
+// Error is returned when consuming a resource over the plan's quota. Reset
+// is when the usage resets, it's not set for the resources that need to be
+// freed, i.e. the storage.
+type Error struct {
+	Resource string     `json:"resource"`
+	Message  string     `json:"message"`
+	Limit    int64      `json:"limit"`
+	Usage    int64      `json:"usage"`
+	Reset    *time.Time `json:"reset,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == ErrExceeded
+}
+
+// Limit returns the quota of a resource for a plan, zero when unlimited
+func Limit(resource string, plan int) int64 {
+	return Limits[resource][plan]
+}
+
+// PeriodStart returns the start of the current usage period of a monthly
+// resource, the first day of the month in UTC
+func PeriodStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ResetTime returns when the usage of a resource resets, nil when it does
+// not reset
+func ResetTime(resource string, now time.Time) *time.Time {
+	if !monthly[resource] {
+		return nil
+	}
+
+	reset := PeriodStart(now).AddDate(0, 1, 0)
+	return &reset
+}
+
+// Check returns an Error when consuming n more of a resource, on top of the
+// current usage, exceeds the quota of the database's plan. It returns nil
+// when the resource's quota is not enforced.
+func Check(datastore database.Persister, volatile cache.Volatilizer, conf model.DatabaseConfig, resource string, usage, n int64) error {
+	if !Enforced[resource] {
+		return nil
+	}
+
+	plan, err := middleware.TenantPlan(datastore, volatile, conf.TenantID)
+	if err != nil {
+		return err
+	}
+
+	limit := Limit(resource, plan)
+	if limit <= 0 || usage+n <= limit {
+		return nil
+	}
+
+	return &Error{
+		Resource: resource,
+		Message:  fmt.Sprintf(messages[resource], limit),
+		Limit:    limit,
+		Usage:    usage,
+		Reset:    ResetTime(resource, time.Now()),
+	}
+}
+
+// Respond writes a quota error as a 429 with a Retry-After header when the
+// usage resets and returns true, it returns false for the other errors
+func Respond(w http.ResponseWriter, err error) bool {
+	var qe *Error
+	if !errors.As(err, &qe) {
+		return false
+	}
+
+	if qe.Reset != nil {
+		secs := int64(time.Until(*qe.Reset).Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(qe)
+	return true
+}
@@ -0,0 +1,74 @@
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/database/memory"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestCheck(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+	volatile := cache.NewDevCache(log)
+	datastore := memory.New(volatile.PublishDocument)
+
+	cus, err := datastore.CreateTenant(model.Tenant{Email: "quota@test.com", Plan: model.PlanIdea})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := model.DatabaseConfig{TenantID: cus.ID, Name: "quotatest"}
+
+	limit := Limit(FunctionRuns, model.PlanIdea)
+
+	// nothing is enforced by default
+	if err := Check(datastore, volatile, conf, FunctionRuns, limit, 1); err != nil {
+		t.Fatalf("expected the quota to not be enforced got %v", err)
+	}
+
+	Enforced[FunctionRuns] = true
+	defer delete(Enforced, FunctionRuns)
+
+	if err := Check(datastore, volatile, conf, FunctionRuns, limit-1, 1); err != nil {
+		t.Fatalf("expected the last run to be allowed got %v", err)
+	}
+
+	err = Check(datastore, volatile, conf, FunctionRuns, limit, 1)
+
+	var qe *Error
+	if !errors.As(err, &qe) || !errors.Is(err, ErrExceeded) {
+		t.Fatalf("expected a quota error got %v", err)
+	} else if qe.Resource != FunctionRuns || qe.Limit != limit || qe.Usage != limit {
+		t.Errorf("unexpected quota error %v", qe)
+	} else if qe.Reset == nil || !qe.Reset.Equal(PeriodStart(time.Now()).AddDate(0, 1, 0)) {
+		t.Errorf("expected the quota to reset next month got %v", qe.Reset)
+	}
+
+	w := httptest.NewRecorder()
+	if !Respond(w, err) {
+		t.Fatal("expected the quota error to be written")
+	} else if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status 429 got %d", w.Code)
+	} else if secs, _ := strconv.Atoi(w.Header().Get("Retry-After")); secs <= 0 {
+		t.Errorf("expected a Retry-After header got %q", w.Header().Get("Retry-After"))
+	}
+
+	var body Error
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	} else if body.Resource != FunctionRuns || body.Limit != limit {
+		t.Errorf("expected the error as JSON got %v", body)
+	}
+
+	if Respond(httptest.NewRecorder(), errors.New("other")) {
+		t.Error("expected the other errors to not be written")
+	}
+}
@@ -0,0 +1,163 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestRateLimitByPlan(t *testing.T) {
+	conf, err := backend.DB.FindDatabase(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cus, err := backend.DB.FindTenant(conf.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limit := middleware.RateLimits[cus.Plan]
+	middleware.RateLimits[cus.Plan] = 2
+	defer func() {
+		middleware.RateLimits[cus.Plan] = limit
+	}()
+
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respond(w, http.StatusOK, true)
+		}),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RateLimit(backend.DB, backend.Cache),
+	)
+
+	call := func() *http.Response {
+		req := httptest.NewRequest("POST", "/db/tasks", nil)
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	for i := 0; i < 2; i++ {
+		resp := call()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200 got %d", resp.StatusCode)
+		} else if resp.Header.Get("SB-RateLimit-Limit") != "2" {
+			t.Errorf("expected limit header to be 2 got %s", resp.Header.Get("SB-RateLimit-Limit"))
+		}
+	}
+
+	resp := call()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 got %d", resp.StatusCode)
+	} else if len(resp.Header.Get("Retry-After")) == 0 {
+		t.Error("expected a Retry-After header")
+	} else if resp.Header.Get("SB-RateLimit-Usage") != "3" {
+		t.Errorf("expected usage header to be 3 got %s", resp.Header.Get("SB-RateLimit-Usage"))
+	} else if resp.Header.Get("RateLimit-Remaining") != "0" || len(resp.Header.Get("RateLimit-Reset")) == 0 {
+		t.Errorf("expected the standard headers got %v", resp.Header)
+	}
+}
+
+func TestRateLimitRouteClasses(t *testing.T) {
+	conf, err := backend.DB.FindDatabase(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cus, err := backend.DB.FindTenant(conf.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limit := middleware.RateLimits[cus.Plan]
+	middleware.RateLimits[cus.Plan] = 10
+	defer func() {
+		middleware.RateLimits[cus.Plan] = limit
+	}()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, true)
+	})
+	withDB := middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL)
+
+	data := middleware.Chain(ok, withDB, middleware.RateLimit(backend.DB, backend.Cache))
+	auth := middleware.Chain(ok, withDB, middleware.RateLimitRoute(middleware.RouteAuth, backend.DB, backend.Cache))
+
+	call := func(h http.Handler, method, path, ip string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("SB-PUBLIC-KEY", pubKey)
+		// behind a trusted proxy
+		req.RemoteAddr = "10.1.1.1:4321"
+		req.Header.Set("X-Forwarded-For", ip)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	tests := []struct {
+		h      http.Handler
+		method string
+		path   string
+		limit  string
+	}{
+		{data, "GET", "/db/tasks", "20"},
+		{data, "POST", "/query/tasks", "20"},
+		{data, "PUT", "/db/tasks/1", "10"},
+		{auth, "POST", "/login", "1"},
+	}
+	for _, tc := range tests {
+		resp := call(tc.h, tc.method, tc.path, "203.0.113.1")
+		if got := resp.Header.Get("RateLimit-Limit"); got != tc.limit {
+			t.Errorf("%s %s: expected limit %s got %s", tc.method, tc.path, tc.limit, got)
+		}
+	}
+
+	// the auth limit is per client IP
+	if resp := call(auth, "POST", "/login", "203.0.113.1"); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status 429 got %d", resp.StatusCode)
+	} else if resp := call(auth, "POST", "/login", "203.0.113.2"); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected another IP to be allowed got %d", resp.StatusCode)
+	}
+}
+
+func TestRouteLimitOverrides(t *testing.T) {
+	plan := model.PlanIdea
+	base := middleware.RateLimits[plan]
+
+	if n := middleware.RouteLimit(plan, middleware.RouteWrite, model.RateLimitSettings{}); n != base {
+		t.Errorf("expected the plan limit %d got %d", base, n)
+	} else if n := middleware.RouteLimit(plan, middleware.RouteWrite, model.RateLimitSettings{Write: 5}); n != 5 {
+		t.Errorf("expected the override to lower the limit to 5 got %d", n)
+	} else if n := middleware.RouteLimit(plan, middleware.RouteRead, model.RateLimitSettings{Read: base * 10}); n != base*2 {
+		t.Errorf("expected the override to not exceed the plan got %d", n)
+	}
+}
+
+func TestSetRateLimits(t *testing.T) {
+	limit := middleware.RateLimits[model.PlanTraction]
+	factor := middleware.RateLimitPolicies[middleware.RouteRead].Factor
+	defer backend.SetRateLimits(map[string]int64{"traction": limit}, map[string]float64{middleware.RouteRead: factor})
+
+	if err := backend.SetRateLimits(map[string]int64{"unknown": 1}, nil); err == nil {
+		t.Error("expected an error for an unknown plan")
+	}
+
+	err := backend.SetRateLimits(map[string]int64{"traction": 100}, map[string]float64{middleware.RouteRead: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := middleware.RouteLimit(model.PlanTraction, middleware.RouteRead, model.RateLimitSettings{}); n != 300 {
+		t.Errorf("expected the read limit to be 300 got %d", n)
+	} else if p, ok := backend.FindPlan(model.PlanTraction); !ok || p.Quotas.RequestsPerMinute != 100 {
+		t.Errorf("expected the plan's quota to be 100 got %v", p.Quotas)
+	}
+}
@@ -0,0 +1,90 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// Messages published with an ID must be acknowledged by the client. Unacked
+// messages are sent again every AckTimeout, up to MaxRedeliveries times.
+var (
+	AckTimeout      = 5 * time.Second
+	MaxRedeliveries = 3
+)
+
+type pendingMsg struct {
+	msg      model.Command
+	sent     time.Time
+	attempts int
+}
+
+// pendingAcks holds the messages waiting for an acknowledgement per
+// connection
+type pendingAcks struct {
+	mx   sync.Mutex
+	msgs map[string]map[string]*pendingMsg
+}
+
+func newPendingAcks() *pendingAcks {
+	return &pendingAcks{msgs: make(map[string]map[string]*pendingMsg)}
+}
+
+// track records a message delivered to a connection
+func (p *pendingAcks) track(sid string, msg model.Command) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	conn, ok := p.msgs[sid]
+	if !ok {
+		conn = make(map[string]*pendingMsg)
+		p.msgs[sid] = conn
+	}
+
+	conn[msg.ID] = &pendingMsg{msg: msg, sent: time.Now(), attempts: 1}
+}
+
+// ack removes a message once the client confirmed its reception
+func (p *pendingAcks) ack(sid, id string) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if conn, ok := p.msgs[sid]; ok {
+		delete(conn, id)
+	}
+}
+
+// due returns the messages of a connection that need to be delivered again.
+// Messages that reached the maximum redeliveries are dropped.
+func (p *pendingAcks) due(sid string) []model.Command {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	var msgs []model.Command
+
+	now := time.Now()
+	for id, pm := range p.msgs[sid] {
+		if now.Sub(pm.sent) < AckTimeout {
+			continue
+		}
+
+		if pm.attempts > MaxRedeliveries {
+			delete(p.msgs[sid], id)
+			continue
+		}
+
+		pm.attempts++
+		pm.sent = now
+		msgs = append(msgs, pm.msg)
+	}
+	return msgs
+}
+
+// remove drops all pending messages of a closed connection
+func (p *pendingAcks) remove(sid string) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	delete(p.msgs, sid)
+}
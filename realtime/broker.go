@@ -1,20 +1,37 @@
 package realtime
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/database"
 	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/metering"
+	"github.com/staticbackendhq/core/middleware"
 	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/quota"
 
 	"github.com/google/uuid"
 )
 
+// Ephemeral events (typing indicators, cursors, etc) are capped per second
+// for each connection and for each channel across all servers. Events over
+// the caps are dropped.
+var (
+	EphemeralPerConnection int64 = 30
+	EphemeralPerChannel    int64 = 300
+)
+
 // Validator validates a session token
 type Validator func(context.Context, string) (string, error)
 
@@ -22,6 +39,7 @@ type Validator func(context.Context, string) (string, error)
 type ConnectionData struct {
 	ctx      context.Context
 	messages chan model.Command
+	lastSeen *int64
 }
 
 // Broker is used to hold all web socket connections
@@ -33,15 +51,27 @@ type Broker struct {
 	ids                map[string]chan model.Command
 	conf               map[string]context.Context
 	subscriptions      map[string][]chan bool
+	liveQueries        map[string]map[string]chan bool
+	channels           map[string][]string
+	members            map[string]model.PresenceMember
+	ephemeral          map[string]rateWindow
+	rates              map[string]rateWindow
+	activity           map[string]*int64
+	pending            *pendingAcks
 	validateAuth       Validator
+	// done is closed when the server is shutting down
+	done      chan struct{}
+	closeOnce sync.Once
 
-	pubsub cache.Volatilizer
+	datastore database.Persister
+	pubsub    cache.Volatilizer
+	stats     Stats
 
 	log *logger.Logger
 }
 
 // NewBroker returns a ready to use Broker for accepting web socket connections
-func NewBroker(v Validator, pubsub cache.Volatilizer, log *logger.Logger) *Broker {
+func NewBroker(v Validator, datastore database.Persister, pubsub cache.Volatilizer, log *logger.Logger) *Broker {
 	b := &Broker{
 		Broadcast:          make(chan model.Command, 1),
 		newConnections:     make(chan ConnectionData),
@@ -50,7 +80,16 @@ func NewBroker(v Validator, pubsub cache.Volatilizer, log *logger.Logger) *Broke
 		ids:                make(map[string]chan model.Command),
 		conf:               make(map[string]context.Context),
 		subscriptions:      make(map[string][]chan bool),
+		liveQueries:        make(map[string]map[string]chan bool),
+		channels:           make(map[string][]string),
+		members:            make(map[string]model.PresenceMember),
+		ephemeral:          make(map[string]rateWindow),
+		rates:              make(map[string]rateWindow),
+		activity:           make(map[string]*int64),
+		pending:            newPendingAcks(),
 		validateAuth:       v,
+		done:               make(chan struct{}),
+		datastore:          datastore,
 		pubsub:             pubsub,
 		log:                log,
 	}
@@ -61,6 +100,10 @@ func NewBroker(v Validator, pubsub cache.Volatilizer, log *logger.Logger) *Broke
 }
 
 func (b *Broker) start() {
+	// the presence of the connections expires unless refreshed
+	presence := time.NewTicker(cache.PresenceTTL / 3)
+	defer presence.Stop()
+
 	for {
 		select {
 		case data := <-b.newConnections:
@@ -72,6 +115,7 @@ func (b *Broker) start() {
 			b.clients[data.messages] = id.String()
 			b.ids[id.String()] = data.messages
 			b.conf[id.String()] = data.ctx
+			b.activity[id.String()] = data.lastSeen
 
 			msg := model.Command{
 				Type: model.MsgTypeInit,
@@ -79,6 +123,14 @@ func (b *Broker) start() {
 			}
 
 			data.messages <- msg
+
+			// every connection receives the database broadcasts
+			if conf, ok := data.ctx.Value(middleware.ContextBase).(model.DatabaseConfig); ok {
+				closesub := make(chan bool)
+				b.subscriptions[id.String()] = append(b.subscriptions[id.String()], closesub)
+
+				go b.pubsub.Subscribe(data.messages, "", model.BroadcastChannel(conf.Name), closesub)
+			}
 		case c := <-b.closingConnections:
 			b.unsub(c)
 		case msg := <-b.Broadcast:
@@ -86,10 +138,43 @@ func (b *Broker) start() {
 			for _, c := range clients {
 				c <- payload
 			}
+		case <-presence.C:
+			b.refreshPresence()
 		}
 	}
 }
 
+// presenceRefresh is a connection's channel presence to refresh
+type presenceRefresh struct {
+	base    string
+	channel string
+	member  model.PresenceMember
+}
+
+// refreshPresence extends the presence of the open connections in the
+// channels they joined
+func (b *Broker) refreshPresence() {
+	var list []presenceRefresh
+	for id, channels := range b.channels {
+		conf, ok := b.getConf(id)
+		if !ok {
+			continue
+		}
+
+		for _, channel := range channels {
+			list = append(list, presenceRefresh{base: conf.Name, channel: channel, member: b.members[id]})
+		}
+	}
+
+	go func() {
+		for _, p := range list {
+			if err := cache.AddPresence(b.pubsub, p.base, p.channel, p.member); err != nil {
+				b.log.Error().Err(err).Msg("error refreshing presence")
+			}
+		}
+	}()
+}
+
 func (b *Broker) unsub(c chan model.Command) {
 	defer delete(b.clients, c)
 
@@ -105,9 +190,52 @@ func (b *Broker) unsub(c chan model.Command) {
 		}
 	}
 
+	for _, stop := range b.liveQueries[id] {
+		close(stop)
+	}
+
+	if channels, ok := b.channels[id]; ok {
+		conf, _ := b.getConf(id)
+		go b.leave(id, conf.Name, channels)
+	}
+
+	delete(b.conf, id)
+	delete(b.subscriptions, id)
+	delete(b.liveQueries, id)
+	delete(b.channels, id)
+	delete(b.members, id)
+	delete(b.ephemeral, id)
+	delete(b.rates, id)
+	delete(b.activity, id)
 	delete(b.ids, id)
 }
 
+// leave removes the connection from the presence of the channels it joined
+// and let the remaining subscribers know.
+func (b *Broker) leave(id, base string, channels []string) {
+	for _, channel := range channels {
+		if len(base) > 0 {
+			if err := cache.RemovePresence(b.pubsub, base, channel, id); err != nil {
+				b.log.Error().Err(err).Msg("error removing presence")
+			}
+
+			if err := cache.LeaveChannel(b.pubsub, base, channel); err != nil {
+				b.log.Error().Err(err).Msg("error removing channel subscriber")
+			}
+		}
+
+		leftMsg := model.Command{
+			SID:     model.SystemID,
+			Type:    model.MsgTypeLeft,
+			Data:    id,
+			Channel: channel,
+		}
+		if err := b.pubsub.Publish(leftMsg); err != nil {
+			b.log.Error().Err(err)
+		}
+	}
+}
+
 // Accept turns a request into a web socket request and creates a new
 // connection in the Broker
 func (b *Broker) Accept(w http.ResponseWriter, r *http.Request) {
@@ -124,39 +252,130 @@ func (b *Broker) Accept(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "keep-alive")
 	//w.Header().Set("Access-Control-Allow-Origin", "*")
 
+	var out io.Writer = w
+	if Compression && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+
+		out = gz
+	}
+
+	// the connection caps are per database
+	conf, hasConf := r.Context().Value(middleware.ContextBase).(model.DatabaseConfig)
+	if hasConf {
+		defer func() {
+			if err := cache.CloseConnection(b.pubsub, conf.Name); err != nil {
+				b.log.Error().Err(err).Msg("error removing realtime connection")
+			}
+		}()
+
+		if err := b.allowConnection(conf); err != nil {
+			quota.Respond(w, err)
+			return
+		}
+	}
+
 	// each connection has their own message channel
 	messages := make(chan model.Command)
+	lastSeen := time.Now().UnixNano()
 	data := ConnectionData{
 		ctx:      r.Context(),
 		messages: messages,
+		lastSeen: &lastSeen,
 	}
 	b.newConnections <- data
 
-	// make sure we'r removing this connection
-	// when the handler completes.
-	defer func() {
-		b.closingConnections <- messages
-	}()
+	atomic.AddInt64(&b.stats.Connections, 1)
+	defer atomic.AddInt64(&b.stats.Connections, -1)
 
 	// handles the client-side disconnection
 	ctx := r.Context()
 
+	write := func(s string) {
+		n, _ := fmt.Fprint(out, s)
+		if hasConf {
+			if err := cache.CountBandwidth(b.pubsub, conf.Name, n); err != nil {
+				b.log.Error().Err(err).Msg("error counting realtime bandwidth")
+			}
+		}
+
+		// flush immediately.
+		if gz, ok := out.(*gzip.Writer); ok {
+			gz.Flush()
+		}
+		flusher.Flush()
+	}
+
+	send := func(msg model.Command) {
+		// write Server Sent Event data
+		bytes, err := json.Marshal(msg)
+		if err != nil {
+			b.log.Warn().Err(err).Msg("error converting to JSON")
+			return
+		}
+
+		write(fmt.Sprintf("data: %s\n\n", bytes))
+	}
+
+	// the connection id is received in the init message
+	var sid string
+	defer func() {
+		b.pending.remove(sid)
+	}()
+
+	redeliver := time.NewTicker(time.Second)
+	defer redeliver.Stop()
+
+	// a nil channel never fires when the keep-alive is disabled
+	var keepAlive <-chan time.Time
+	if KeepAlive > 0 {
+		t := time.NewTicker(KeepAlive)
+		defer t.Stop()
+
+		keepAlive = t.C
+	}
+
+	// the shutdown closes the connection once the broker sent its init
+	// message, the broker would block sending it otherwise
+	var closing <-chan struct{}
+
 	// broadcast messages
 	for {
 		select {
 		case msg := <-messages:
-			// write Server Sent Event data
-			bytes, err := json.Marshal(msg)
-			if err != nil {
-				b.log.Warn().Err(err).Msg("error converting to JSON")
+			if msg.Type == model.MsgTypeInit {
+				sid = msg.Data
+				closing = b.done
+			} else if len(msg.ID) > 0 && msg.Type != model.MsgTypeEphemeral {
+				b.pending.track(sid, msg)
+			}
 
-				continue
+			send(msg)
+		case <-redeliver.C:
+			for _, msg := range b.pending.due(sid) {
+				send(msg)
 			}
 
-			fmt.Fprintf(w, "data: %s\n\n", bytes)
+			if idle(&lastSeen) {
+				atomic.AddInt64(&b.stats.IdleClosed, 1)
 
-			// flush immediately.
-			flusher.Flush()
+				send(model.Command{Type: model.MsgTypeClose, Data: "idle timeout"})
+
+				b.closingConnections <- messages
+				return
+			}
+		case <-keepAlive:
+			// SSE comments are ignored by the clients
+			write(": ping\n\n")
+		case <-closing:
+			// the clients reconnect to another instance
+			send(model.Command{Type: model.MsgTypeClose, Data: "going away"})
+
+			b.closingConnections <- messages
+			return
 		case <-ctx.Done():
 			b.closingConnections <- messages
 			return
@@ -164,6 +383,121 @@ func (b *Broker) Accept(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Close closes the connections with a going away close message when the
+// server shuts down, they would otherwise prevent it from draining
+func (b *Broker) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+	})
+}
+
+// ownsUserChannel makes sure a direct messaging channel is only joined by
+// the account it belongs to
+func (b *Broker) ownsUserChannel(token, channel string) bool {
+	var auth model.Auth
+	if err := b.pubsub.GetTyped(token, &auth); err != nil {
+		return false
+	}
+
+	return channel == model.UserChannel(auth.AccountID)
+}
+
+// tailsOwnFunctions makes sure the function logs channel is only joined by
+// the root user of the connection's database
+func (b *Broker) tailsOwnFunctions(sid, token string) (model.DatabaseConfig, bool) {
+	conf, ok := b.getConf(sid)
+	if !ok {
+		return conf, false
+	}
+
+	var auth model.Auth
+	if err := b.pubsub.GetTyped(token, &auth); err != nil {
+		return conf, false
+	}
+
+	return conf, auth.Role >= middleware.RootRole
+}
+
+// canJoin authorizes a connection to join or see the presence of the
+// channel in msg.Data and returns the channel to use, the function logs
+// channel is the one of the connection's database
+func (b *Broker) canJoin(msg model.Command) (string, error) {
+	channel := msg.Data
+	if strings.HasPrefix(channel, model.FunctionLogChannelPrefix) {
+		conf, ok := b.tailsOwnFunctions(msg.SID, msg.Token)
+		if !ok {
+			return "", errors.New("only the root user can join the function logs channel")
+		}
+
+		channel = model.FunctionLogChannel(conf.Name)
+	} else if model.ReservedChannel(channel) && !strings.HasPrefix(strings.ToLower(channel), "db-") {
+		// the database channels are readable, not the other reserved ones
+		return "", errors.New("you cannot join a reserved channel")
+	} else if strings.HasPrefix(channel, model.UserChannelPrefix) && !b.ownsUserChannel(msg.Token, channel) {
+		return "", errors.New("you cannot join another user's channel")
+	}
+	return channel, nil
+}
+
+// rateWindow counts events for the current second
+type rateWindow struct {
+	second int64
+	count  int64
+}
+
+// allowEphemeral enforces the ephemeral events caps for the connection and
+// the channel
+func (b *Broker) allowEphemeral(sid, channel string) bool {
+	now := time.Now().Unix()
+
+	w := b.ephemeral[sid]
+	if w.second != now {
+		w = rateWindow{second: now}
+	}
+	w.count++
+	b.ephemeral[sid] = w
+
+	if w.count > EphemeralPerConnection {
+		return false
+	}
+
+	key := fmt.Sprintf("eph-%s-%d", channel, now)
+	n, err := b.pubsub.Inc(key, 1)
+	if err != nil {
+		return false
+	} else if n == 1 {
+		if err := b.pubsub.Expire(key, 2*time.Second); err != nil {
+			b.log.Error().Err(err).Msg("error setting ephemeral counter expiration")
+		}
+	}
+
+	return n <= EphemeralPerChannel
+}
+
+// getConf returns the database config of a connection
+func (b *Broker) getConf(sid string) (model.DatabaseConfig, bool) {
+	ctx, ok := b.conf[sid]
+	if !ok {
+		return model.DatabaseConfig{}, false
+	}
+
+	conf, ok := ctx.Value(middleware.ContextBase).(model.DatabaseConfig)
+	return conf, ok
+}
+
+// replay sends the channel history to a connection that just joined
+func (b *Broker) replay(sender chan model.Command, base, channel string) {
+	msgs, err := cache.History(b.pubsub, base, channel)
+	if err != nil {
+		b.log.Error().Err(err).Msg("error getting channel history")
+		return
+	}
+
+	for _, msg := range msgs {
+		sender <- msg
+	}
+}
+
 func (b *Broker) getTargets(msg model.Command) (sockets []chan model.Command, payload model.Command) {
 	var sender chan model.Command
 
@@ -175,6 +509,18 @@ func (b *Broker) getTargets(msg model.Command) (sockets []chan model.Command, pa
 		}
 		sender = s
 		sockets = append(sockets, sender)
+
+		b.touch(msg.SID)
+
+		if !b.allowMessage(msg.SID) {
+			payload = model.Command{Type: model.MsgTypeError, Data: "rate limit exceeded"}
+			return
+		}
+
+		if conf, ok := b.getConf(msg.SID); ok && msg.Type != model.MsgTypeAck && !b.allowDatabaseMessage(conf) {
+			payload = model.Command{Type: model.MsgTypeError, Data: "rate limit exceeded for your plan"}
+			return
+		}
 	}
 
 	switch msg.Type {
@@ -195,6 +541,13 @@ func (b *Broker) getTargets(msg model.Command) (sockets []chan model.Command, pa
 
 		payload = model.Command{Type: model.MsgTypeToken, Data: msg.Data}
 	case model.MsgTypeJoin:
+		channel, err := b.canJoin(msg)
+		if err != nil {
+			payload = model.Command{Type: model.MsgTypeError, Data: err.Error()}
+			return
+		}
+		msg.Data = channel
+
 		subs, ok := b.subscriptions[msg.SID]
 		if !ok {
 			subs = make([]chan bool, 0)
@@ -207,6 +560,32 @@ func (b *Broker) getTargets(msg model.Command) (sockets []chan model.Command, pa
 
 		go b.pubsub.Subscribe(sender, msg.Token, msg.Data, closesub)
 
+		b.channels[msg.SID] = append(b.channels[msg.SID], msg.Data)
+
+		member := model.PresenceMember{SID: msg.SID, Joined: time.Now()}
+
+		var auth model.Auth
+		if err := b.pubsub.GetTyped(msg.Token, &auth); err == nil {
+			member.AccountID = auth.AccountID
+			member.UserID = auth.UserID
+		}
+
+		b.members[msg.SID] = member
+
+		if conf, ok := b.getConf(msg.SID); ok {
+			if err := cache.AddPresence(b.pubsub, conf.Name, msg.Data, member); err != nil {
+				b.log.Error().Err(err).Msg("error adding presence")
+			}
+
+			if err := cache.JoinChannel(b.pubsub, conf.Name, msg.Data); err != nil {
+				b.log.Error().Err(err).Msg("error adding channel subscriber")
+			}
+
+			if conf.Settings.Realtime.HistorySize > 0 {
+				go b.replay(sender, conf.Name, msg.Data)
+			}
+		}
+
 		joinedMsg := model.Command{
 			Type:    model.MsgTypeJoined,
 			Data:    msg.SID,
@@ -222,22 +601,42 @@ func (b *Broker) getTargets(msg model.Command) (sockets []chan model.Command, pa
 
 		payload = model.Command{Type: model.MsgTypeOk, Data: msg.Data}
 	case model.MsgTypePresence:
-		v, err := b.pubsub.Get(msg.Data)
+		channel, err := b.canJoin(msg)
+		if err != nil {
+			payload = model.Command{Type: model.MsgTypeError, Data: err.Error()}
+			return
+		}
+
+		conf, ok := b.getConf(msg.SID)
+		if !ok {
+			payload = model.Command{Type: model.MsgTypeError, Data: "invalid request"}
+			return
+		}
+
+		members, err := cache.Presence(b.pubsub, conf.Name, channel)
 		if err != nil {
-			//TODO: Make sure it's because the channel key does not exists
-			v = "0"
+			payload = model.Command{Type: model.MsgTypeError, Data: err.Error()}
+			return
 		}
 
-		payload = model.Command{Type: model.MsgTypePresence, Data: v}
+		v, err := json.Marshal(members)
+		if err != nil {
+			payload = model.Command{Type: model.MsgTypeError, Data: err.Error()}
+			return
+		}
+
+		payload = model.Command{Type: model.MsgTypePresence, Data: string(v), Channel: channel}
 	case model.MsgTypeChanIn:
 		if len(msg.Channel) == 0 {
 			payload = model.Command{Type: model.MsgTypeError, Data: "no channel was specified"}
 			return
-		} else if strings.HasPrefix(strings.ToLower(msg.Channel), "db-") {
-			payload = model.Command{
-				Type: model.MsgTypeError,
-				Data: "you cannot write to database channel",
-			}
+		} else if model.ReservedChannel(msg.Channel) {
+			payload = model.Command{Type: model.MsgTypeError, Data: "you cannot write to a reserved channel"}
+			return
+		}
+
+		if err := msg.ValidatePayload(); err != nil {
+			payload = model.Command{Type: model.MsgTypeError, Data: err.Error()}
 			return
 		}
 
@@ -247,7 +646,122 @@ func (b *Broker) getTargets(msg model.Command) (sockets []chan model.Command, pa
 			}
 		}()
 
+		if conf, ok := b.getConf(msg.SID); ok {
+			if err := cache.CountChannelMessage(b.pubsub, conf.Name, msg.Channel); err != nil {
+				b.log.Error().Err(err).Msg("error counting channel message")
+			}
+			metering.Record(conf, model.MeterRealtimeMessages, 1)
+
+			if conf.Settings.Realtime.HistorySize > 0 {
+				// subscribers receive channel messages as chan_out
+				out := msg
+				out.Type = model.MsgTypeChanOut
+
+				if err := cache.AppendHistory(b.pubsub, conf.Name, msg.Channel, out, conf.Settings.Realtime.HistorySize); err != nil {
+					b.log.Error().Err(err).Msg("error adding message to channel history")
+				}
+			}
+		}
+
 		payload = model.Command{Type: model.MsgTypeOk}
+	case model.MsgTypeLiveQuery:
+		var q model.LiveQuery
+		if err := json.Unmarshal([]byte(msg.Data), &q); err != nil {
+			payload = model.Command{Type: model.MsgTypeError, Data: "invalid live query"}
+			return
+		} else if len(q.Collection) == 0 {
+			payload = model.Command{Type: model.MsgTypeError, Data: "no collection was specified"}
+			return
+		}
+
+		conf, ok := b.getConf(msg.SID)
+		if !ok {
+			payload = model.Command{Type: model.MsgTypeError, Data: "invalid request"}
+			return
+		}
+
+		var auth model.Auth
+		if err := b.pubsub.GetTyped(msg.Token, &auth); err != nil {
+			payload = model.Command{Type: model.MsgTypeError, Data: "invalid token"}
+			return
+		} else if !auth.CanRead(q.Collection) {
+			payload = model.Command{Type: model.MsgTypeError, Data: "this token's scope does not allow this operation"}
+			return
+		}
+
+		if _, err := b.datastore.ParseQuery(q.Filter); err != nil {
+			payload = model.Command{Type: model.MsgTypeError, Data: err.Error()}
+			return
+		}
+
+		if len(q.ID) == 0 {
+			id, err := uuid.NewUUID()
+			if err != nil {
+				payload = model.Command{Type: model.MsgTypeError, Data: err.Error()}
+				return
+			}
+			q.ID = id.String()
+		}
+
+		queries, ok := b.liveQueries[msg.SID]
+		if !ok {
+			queries = make(map[string]chan bool)
+			b.liveQueries[msg.SID] = queries
+		}
+
+		if _, ok := queries[q.ID]; ok {
+			payload = model.Command{Type: model.MsgTypeError, Data: "this live query id is already used"}
+			return
+		}
+
+		stop := make(chan bool)
+		queries[q.ID] = stop
+
+		lq := &liveQuery{
+			id:       q.ID,
+			base:     conf.Name,
+			col:      q.Collection,
+			token:    msg.Token,
+			auth:     auth,
+			clauses:  q.Filter,
+			matching: make(map[string]bool),
+		}
+		go b.runLiveQuery(sender, lq, stop)
+
+		payload = model.Command{Type: model.MsgTypeOk, Data: q.ID, Channel: q.ID}
+	case model.MsgTypeLiveStop:
+		stop, ok := b.liveQueries[msg.SID][msg.Data]
+		if !ok {
+			payload = model.Command{Type: model.MsgTypeError, Data: "live query not found"}
+			return
+		}
+
+		close(stop)
+		delete(b.liveQueries[msg.SID], msg.Data)
+
+		payload = model.Command{Type: model.MsgTypeOk, Data: msg.Data, Channel: msg.Data}
+	case model.MsgTypeAck:
+		// acknowledgements are not acknowledged
+		sockets = nil
+
+		b.pending.ack(msg.SID, msg.Data)
+	case model.MsgTypeEphemeral:
+		// ephemeral events are not acknowledged, nothing is sent back
+		sockets = nil
+
+		if len(msg.Channel) == 0 || model.ReservedChannel(msg.Channel) {
+			return
+		} else if msg.ValidatePayload() != nil {
+			return
+		} else if !b.allowEphemeral(msg.SID, msg.Channel) {
+			return
+		}
+
+		go func() {
+			if err := b.pubsub.Publish(msg); err != nil {
+				b.log.Error().Err(err)
+			}
+		}()
 	default:
 		payload.Type = model.MsgTypeError
 		payload.Data = fmt.Sprintf(`%s command not found`, msg.Type)
@@ -0,0 +1,315 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/database/memory"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/quota"
+)
+
+func TestAllowEphemeral(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+
+	b := &Broker{
+		ephemeral: make(map[string]rateWindow),
+		pubsub:    cache.NewDevCache(log),
+		log:       log,
+	}
+
+	perConn, perChan := EphemeralPerConnection, EphemeralPerChannel
+	defer func() {
+		EphemeralPerConnection, EphemeralPerChannel = perConn, perChan
+	}()
+
+	EphemeralPerConnection = 2
+	EphemeralPerChannel = 3
+
+	for i := 0; i < 2; i++ {
+		if !b.allowEphemeral("sid-1", "cursors") {
+			t.Fatalf("expected event %d to be allowed", i)
+		}
+	}
+
+	if b.allowEphemeral("sid-1", "cursors") {
+		t.Error("expected the connection cap to be reached")
+	}
+
+	if !b.allowEphemeral("sid-2", "cursors") {
+		t.Error("expected event from another connection to be allowed")
+	} else if b.allowEphemeral("sid-3", "cursors") {
+		t.Error("expected the channel cap to be reached")
+	}
+}
+
+func TestPendingAcks(t *testing.T) {
+	timeout, max := AckTimeout, MaxRedeliveries
+	defer func() {
+		AckTimeout, MaxRedeliveries = timeout, max
+	}()
+
+	AckTimeout = 0
+	MaxRedeliveries = 1
+
+	p := newPendingAcks()
+	p.track("sid-1", model.Command{ID: "msg-1", Type: model.MsgTypeChanOut})
+	p.track("sid-1", model.Command{ID: "msg-2", Type: model.MsgTypeChanOut})
+
+	p.ack("sid-1", "msg-1")
+
+	msgs := p.due("sid-1")
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message to redeliver got %d", len(msgs))
+	} else if msgs[0].ID != "msg-2" {
+		t.Errorf("expected msg-2 to be redelivered got %s", msgs[0].ID)
+	}
+
+	// maximum redeliveries reached, the message is dropped
+	if msgs := p.due("sid-1"); len(msgs) != 0 {
+		t.Errorf("expected no message to redeliver got %d", len(msgs))
+	}
+}
+
+func TestAllowMessage(t *testing.T) {
+	b := &Broker{rates: make(map[string]rateWindow)}
+
+	rate := MessagesPerSecond
+	defer func() {
+		MessagesPerSecond = rate
+	}()
+
+	MessagesPerSecond = 2
+
+	for i := 0; i < 2; i++ {
+		if !b.allowMessage("sid-1") {
+			t.Fatalf("expected message %d to be allowed", i)
+		}
+	}
+
+	if b.allowMessage("sid-1") {
+		t.Error("expected the connection rate limit to be reached")
+	} else if !b.allowMessage("sid-2") {
+		t.Error("expected message from another connection to be allowed")
+	}
+
+	if n := b.Stats().RateLimited; n != 1 {
+		t.Errorf("expected 1 rate limited message got %d", n)
+	}
+}
+
+func TestIdleConnectionClosed(t *testing.T) {
+	timeout := IdleTimeout
+	defer func() {
+		IdleTimeout = timeout
+	}()
+
+	IdleTimeout = 500 * time.Millisecond
+
+	log := logger.Get(config.AppConfig{})
+	b := NewBroker(nil, nil, cache.NewDevCache(log), log)
+
+	req := httptest.NewRequest(http.MethodGet, "/sse/connect", nil)
+	w := httptest.NewRecorder()
+
+	done := make(chan bool)
+	go func() {
+		b.Accept(w, req)
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the idle connection to be closed")
+	}
+
+	if !strings.Contains(w.Body.String(), `"type":"close"`) {
+		t.Errorf("expected a close message got %s", w.Body.String())
+	} else if n := b.Stats().IdleClosed; n != 1 {
+		t.Errorf("expected 1 idle connection closed got %d", n)
+	}
+}
+
+func TestCloseGoingAway(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+	b := NewBroker(nil, nil, cache.NewDevCache(log), log)
+
+	req := httptest.NewRequest(http.MethodGet, "/sse/connect", nil)
+	w := httptest.NewRecorder()
+
+	done := make(chan bool)
+	go func() {
+		b.Accept(w, req)
+		done <- true
+	}()
+
+	b.Close()
+	// closing twice is a no-op
+	b.Close()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the connection to be closed")
+	}
+
+	if !strings.Contains(w.Body.String(), `"data":"going away"`) {
+		t.Errorf("expected a going away message got %s", w.Body.String())
+	}
+}
+
+func TestConnectionPlanCap(t *testing.T) {
+	enforced, max := quota.Enforced[quota.Connections], quota.Limits[quota.Connections][model.PlanFree]
+	defer func() {
+		quota.Enforced[quota.Connections] = enforced
+		quota.Limits[quota.Connections][model.PlanFree] = max
+	}()
+
+	quota.Enforced[quota.Connections] = true
+	quota.Limits[quota.Connections][model.PlanFree] = 1
+
+	log := logger.Get(config.AppConfig{})
+	volatile := cache.NewDevCache(log)
+	datastore := memory.New(volatile.PublishDocument)
+
+	cus, err := datastore.CreateTenant(model.Tenant{Email: "caps@test.com", Plan: model.PlanFree})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Broker{datastore: datastore, pubsub: volatile, log: log}
+
+	conf := model.DatabaseConfig{TenantID: cus.ID, Name: "capstest"}
+	if err := b.allowConnection(conf); err != nil {
+		t.Fatal("expected the first connection to be allowed")
+	} else if err := b.allowConnection(conf); !errors.Is(err, quota.ErrExceeded) {
+		t.Errorf("expected the plan connection quota to be reached got %v", err)
+	}
+
+	usage, err := b.Usage(conf)
+	if err != nil {
+		t.Fatal(err)
+	} else if usage.Connections != 2 || usage.MaxConnections != 1 {
+		t.Errorf("expected 2/1 connections got %d/%d", usage.Connections, usage.MaxConnections)
+	}
+}
+
+func TestFunctionLogChannel(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+	volatile := cache.NewDevCache(log)
+
+	conf := model.DatabaseConfig{Name: "fnlogs"}
+	ctx := context.WithValue(context.Background(), middleware.ContextBase, conf)
+
+	b := &Broker{conf: map[string]context.Context{"sid-1": ctx}, pubsub: volatile, log: log}
+
+	if err := volatile.SetTyped("root-token", model.Auth{Role: middleware.RootRole}); err != nil {
+		t.Fatal(err)
+	} else if err := volatile.SetTyped("user-token", model.Auth{Role: 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := b.tailsOwnFunctions("sid-1", "root-token"); !ok {
+		t.Error("expected the root user to join the function logs channel")
+	} else if _, ok := b.tailsOwnFunctions("sid-1", "user-token"); ok {
+		t.Error("expected a user to not join the function logs channel")
+	} else if _, ok := b.tailsOwnFunctions("sid-2", "root-token"); ok {
+		t.Error("expected an unknown connection to not join the function logs channel")
+	}
+}
+
+func TestPresenceAuthorization(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+	volatile := cache.NewDevCache(log)
+
+	conf := model.DatabaseConfig{Name: "presencedb"}
+	ctx := context.WithValue(context.Background(), middleware.ContextBase, conf)
+
+	b := &Broker{
+		ids:    map[string]chan model.Command{"sid-1": make(chan model.Command, 1)},
+		conf:   map[string]context.Context{"sid-1": ctx},
+		rates:  make(map[string]rateWindow),
+		pubsub: volatile,
+		log:    log,
+	}
+
+	if err := volatile.SetTyped("user-token", model.Auth{AccountID: "acct-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	member := model.PresenceMember{SID: "sid-2", AccountID: "acct-2", Joined: time.Now()}
+	for _, base := range []string{conf.Name, "otherdb"} {
+		if err := cache.AddPresence(volatile, base, "chat", member); err != nil {
+			t.Fatal(err)
+		} else if err := cache.AddPresence(volatile, base, model.UserChannel("acct-2"), member); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	presence := func(channel string) model.Command {
+		_, payload := b.getTargets(model.Command{SID: "sid-1", Type: model.MsgTypePresence, Data: channel, Token: "user-token"})
+		return payload
+	}
+
+	if payload := presence(model.UserChannel("acct-2")); payload.Type != model.MsgTypeError {
+		t.Errorf("expected an error for another user's channel got %v", payload)
+	}
+
+	payload := presence("chat")
+	if payload.Type != model.MsgTypePresence {
+		t.Fatalf("expected the channel presence got %v", payload)
+	}
+
+	var members []model.PresenceMember
+	if err := json.Unmarshal([]byte(payload.Data), &members); err != nil {
+		t.Fatal(err)
+	} else if len(members) != 1 {
+		t.Errorf("expected only the members of the connection's database got %d", len(members))
+	}
+}
+
+func TestReservedChannels(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+	volatile := cache.NewDevCache(log)
+
+	conf := model.DatabaseConfig{Name: "reserveddb"}
+	ctx := context.WithValue(context.Background(), middleware.ContextBase, conf)
+
+	b := &Broker{
+		ids:    map[string]chan model.Command{"sid-1": make(chan model.Command, 1)},
+		conf:   map[string]context.Context{"sid-1": ctx},
+		rates:  make(map[string]rateWindow),
+		pubsub: volatile,
+		log:    log,
+	}
+
+	if err := volatile.SetTyped("user-token", model.Auth{AccountID: "acct-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range []string{"db-tasks", model.BroadcastChannel(conf.Name), model.FunctionLogChannel(conf.Name)} {
+		msg := model.Command{SID: "sid-1", Type: model.MsgTypeChanIn, Channel: ch, Data: "hi", Token: "user-token"}
+		if _, payload := b.getTargets(msg); payload.Type != model.MsgTypeError {
+			t.Errorf("expected writing to %s to be refused got %v", ch, payload)
+		}
+	}
+
+	if _, err := b.canJoin(model.Command{SID: "sid-1", Data: "db-tasks", Token: "user-token"}); err != nil {
+		t.Errorf("expected the database channel to be readable got %v", err)
+	} else if _, err := b.canJoin(model.Command{SID: "sid-1", Data: model.BroadcastChannel(conf.Name), Token: "user-token"}); err == nil {
+		t.Error("expected the broadcast channel to not be joinable")
+	} else if _, err := b.canJoin(model.Command{SID: "sid-1", Data: model.FunctionLogChannel(conf.Name), Token: "user-token"}); err == nil {
+		t.Error("expected a user to not join the function logs channel")
+	}
+}
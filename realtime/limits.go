@@ -0,0 +1,163 @@
+package realtime
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/quota"
+)
+
+// Connection limits, they are set from the server config on start.
+//
+// KeepAlive is the interval between the keep-alive comments sent on idle
+// streams so proxies do not drop them. Connections that did not send any
+// message for IdleTimeout are closed. MessagesPerSecond caps the messages a
+// connection can send and MaxMessageSize the size of each of them in bytes.
+// Compression gzips the event stream for clients that accept it.
+//
+// A zero value disables the limit.
+var (
+	KeepAlive         = 30 * time.Second
+	IdleTimeout       time.Duration
+	MessagesPerSecond int64
+	MaxMessageSize    int64 = 1 << 20
+	Compression       bool
+)
+
+// Plan based messages per second caps of each database across all servers,
+// they are enforced when PlanCaps is set. The connections are limited by
+// the quota package.
+var (
+	PlanCaps bool
+
+	MaxMessagesPerSecond = map[int]int64{
+		model.PlanFree:     50,
+		model.PlanIdea:     100,
+		model.PleanLaunch:  250,
+		model.PlanTraction: 500,
+		model.PlanGrowth:   1000,
+	}
+)
+
+// Stats are the connection counters of a Broker
+type Stats struct {
+	Connections int64 `json:"connections"`
+	IdleClosed  int64 `json:"idleClosed"`
+	RateLimited int64 `json:"rateLimited"`
+}
+
+// Stats returns the current connection counters
+func (b *Broker) Stats() Stats {
+	return Stats{
+		Connections: atomic.LoadInt64(&b.stats.Connections),
+		IdleClosed:  atomic.LoadInt64(&b.stats.IdleClosed),
+		RateLimited: atomic.LoadInt64(&b.stats.RateLimited),
+	}
+}
+
+// allowMessage enforces the MessagesPerSecond cap of a connection
+func (b *Broker) allowMessage(sid string) bool {
+	if MessagesPerSecond <= 0 {
+		return true
+	}
+
+	now := time.Now().Unix()
+
+	w := b.rates[sid]
+	if w.second != now {
+		w = rateWindow{second: now}
+	}
+	w.count++
+	b.rates[sid] = w
+
+	if w.count > MessagesPerSecond {
+		atomic.AddInt64(&b.stats.RateLimited, 1)
+		return false
+	}
+	return true
+}
+
+// touch records activity from a connection, used to detect idle connections
+func (b *Broker) touch(sid string) {
+	if seen, ok := b.activity[sid]; ok {
+		atomic.StoreInt64(seen, time.Now().UnixNano())
+	}
+}
+
+// idle returns true when a connection did not send anything for IdleTimeout
+func idle(seen *int64) bool {
+	if IdleTimeout <= 0 {
+		return false
+	}
+
+	last := time.Unix(0, atomic.LoadInt64(seen))
+	return time.Since(last) > IdleTimeout
+}
+
+// caps returns the connections and messages per second caps of a database
+// based on its tenant's plan
+func (b *Broker) caps(conf model.DatabaseConfig) (maxConns int64, maxMsgs int64, err error) {
+	plan, err := middleware.TenantPlan(b.datastore, b.pubsub, conf.TenantID)
+	if err != nil {
+		return
+	}
+
+	return quota.Limit(quota.Connections, plan), MaxMessagesPerSecond[plan], nil
+}
+
+// Usage returns the realtime activity of a database with its caps
+func (b *Broker) Usage(conf model.DatabaseConfig) (model.RealtimeUsage, error) {
+	usage, err := cache.RealtimeUsage(b.pubsub, conf.Name)
+	if err != nil {
+		return usage, err
+	}
+
+	usage.MaxConnections, usage.MaxMessagesPerSecond, err = b.caps(conf)
+	return usage, err
+}
+
+// allowConnection counts a new connection for the database and returns a
+// quota.Error if it's over its plan quota
+func (b *Broker) allowConnection(conf model.DatabaseConfig) error {
+	n, err := cache.OpenConnection(b.pubsub, conf.Name)
+	if err != nil {
+		b.log.Error().Err(err).Msg("error counting realtime connection")
+		return nil
+	}
+
+	err = quota.Check(b.datastore, b.pubsub, conf, quota.Connections, n-1, 1)
+	if errors.Is(err, quota.ErrExceeded) {
+		return err
+	} else if err != nil {
+		b.log.Error().Err(err).Msg("error checking the realtime connections quota")
+	}
+	return nil
+}
+
+// allowDatabaseMessage counts a message for the database and returns false
+// if it's over its plan cap
+func (b *Broker) allowDatabaseMessage(conf model.DatabaseConfig) bool {
+	n, err := cache.CountMessage(b.pubsub, conf.Name)
+	if err != nil {
+		b.log.Error().Err(err).Msg("error counting realtime message")
+		return true
+	} else if !PlanCaps {
+		return true
+	}
+
+	_, max, err := b.caps(conf)
+	if err != nil {
+		b.log.Error().Err(err).Msg("error getting realtime caps")
+		return true
+	}
+
+	if max > 0 && n > max {
+		atomic.AddInt64(&b.stats.RateLimited, 1)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,320 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// MaxLiveQueryResults caps the documents a live query keeps track of, the
+// initial results are limited to this size.
+var MaxLiveQueryResults int64 = 1000
+
+// liveQuery holds the ids of the documents currently matching the filter so
+// the database events can be turned into added, changed and removed events.
+type liveQuery struct {
+	id       string
+	base     string
+	col      string
+	token    string
+	auth     model.Auth
+	clauses  [][]interface{}
+	matching map[string]bool
+}
+
+// runLiveQuery sends the initial results of the query and then the changes
+// from the collection's database events until stop is closed.
+func (b *Broker) runLiveQuery(sender chan model.Command, q *liveQuery, stop chan bool) {
+	feed := make(chan model.Command)
+	closesub := make(chan bool)
+
+	// subscribing before the initial query so no change is missed
+	go b.pubsub.Subscribe(feed, q.token, "db-"+q.col, closesub)
+	defer func() {
+		for {
+			select {
+			case <-feed:
+			case closesub <- true:
+				return
+			}
+		}
+	}()
+
+	send := func(msgs []model.Command) bool {
+		for _, msg := range msgs {
+			select {
+			case sender <- msg:
+			case <-stop:
+				return false
+			}
+		}
+		return true
+	}
+
+	initial, err := b.initialResults(q)
+	if err != nil {
+		b.log.Error().Err(err).Msg("error executing live query")
+
+		send([]model.Command{{Type: model.MsgTypeError, Channel: q.id, Data: err.Error()}})
+		return
+	}
+
+	if !send(initial) {
+		return
+	}
+
+	for {
+		select {
+		case msg := <-feed:
+			if msg.Base != q.base {
+				continue
+			}
+
+			if !send(q.apply(msg)) {
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// initialResults queries the matching documents and returns them as added
+// events.
+func (b *Broker) initialResults(q *liveQuery) ([]model.Command, error) {
+	filter, err := b.datastore.ParseQuery(q.clauses)
+	if err != nil {
+		return nil, err
+	}
+
+	params := model.ListParams{Page: 1, Size: MaxLiveQueryResults}
+	result, err := b.datastore.QueryDocuments(q.auth, q.base, q.col, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []model.Command
+	for _, doc := range result.Results {
+		id := fmt.Sprintf("%v", doc["id"])
+		if q.matching[id] {
+			continue
+		}
+
+		buf, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		q.matching[id] = true
+		msgs = append(msgs, q.event(model.MsgTypeLiveAdded, string(buf)))
+	}
+	return msgs, nil
+}
+
+// apply returns the live events caused by a database event
+func (q *liveQuery) apply(msg model.Command) []model.Command {
+	switch msg.Type {
+	case model.MsgTypeDBDeleted:
+		// deleted events carry the document id or the whole document
+		var id string
+		if err := json.Unmarshal([]byte(msg.Data), &id); err != nil {
+			doc := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(msg.Data), &doc); err != nil {
+				return nil
+			}
+			id = fmt.Sprintf("%v", doc["id"])
+		}
+
+		if !q.matching[id] {
+			return nil
+		}
+
+		delete(q.matching, id)
+		return []model.Command{q.event(model.MsgTypeLiveRemoved, id)}
+	case model.MsgTypeDBCreated, model.MsgTypeDBUpdated:
+		doc := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(msg.Data), &doc); err != nil {
+			return nil
+		}
+
+		id := fmt.Sprintf("%v", doc["id"])
+		tracked := q.matching[id]
+
+		if !matchClauses(doc, q.clauses) {
+			if !tracked {
+				return nil
+			}
+
+			delete(q.matching, id)
+			return []model.Command{q.event(model.MsgTypeLiveRemoved, id)}
+		} else if tracked {
+			return []model.Command{q.event(model.MsgTypeLiveChanged, msg.Data)}
+		} else if int64(len(q.matching)) >= MaxLiveQueryResults {
+			return nil
+		}
+
+		q.matching[id] = true
+		return []model.Command{q.event(model.MsgTypeLiveAdded, msg.Data)}
+	}
+	return nil
+}
+
+func (q *liveQuery) event(typ, data string) model.Command {
+	return model.Command{Type: typ, Channel: q.id, Data: data}
+}
+
+// matchClauses returns true if the document satisfies all the query clauses,
+// it supports the same operators as the database's ParseQuery.
+func matchClauses(doc map[string]interface{}, clauses [][]interface{}) bool {
+	for _, clause := range clauses {
+		if len(clause) != 3 {
+			return false
+		}
+
+		field, _ := clause[0].(string)
+		op, _ := clause[1].(string)
+
+		v := lookupField(doc, field)
+
+		switch op {
+		case "=", "==":
+			if !equals(v, clause[2]) {
+				return false
+			}
+		case "!=", "<>":
+			if equals(v, clause[2]) {
+				return false
+			}
+		case ">", "<", ">=", "<=":
+			n, ok := compare(v, clause[2])
+			if !ok {
+				return false
+			}
+
+			switch op {
+			case ">":
+				ok = n > 0
+			case "<":
+				ok = n < 0
+			case ">=":
+				ok = n >= 0
+			case "<=":
+				ok = n <= 0
+			}
+
+			if !ok {
+				return false
+			}
+		case "in":
+			if !contains(clause[2], v) {
+				return false
+			}
+		case "!in", "nin":
+			if contains(clause[2], v) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// lookupField returns the value of a field, nested fields are separated by
+// a dot i.e. address.city
+func lookupField(doc map[string]interface{}, field string) interface{} {
+	var v interface{} = doc
+	for _, part := range strings.Split(field, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	return v
+}
+
+func equals(a, b interface{}) bool {
+	if n, ok := compare(a, b); ok {
+		return n == 0
+	}
+	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+// compare compares two numbers or two strings
+func compare(a, b interface{}) (int, bool) {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	x, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+
+	y, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// contains returns true if the value, or one of its items for an array, is
+// in the list
+func contains(list, v interface{}) bool {
+	var items []interface{}
+	switch l := list.(type) {
+	case []interface{}:
+		items = l
+	case []string:
+		for _, s := range l {
+			items = append(items, s)
+		}
+	default:
+		items = []interface{}{l}
+	}
+
+	values, ok := v.([]interface{})
+	if !ok {
+		values = []interface{}{v}
+	}
+
+	for _, val := range values {
+		for _, item := range items {
+			if equals(val, item) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/database/memory"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestMatchClauses(t *testing.T) {
+	doc := map[string]interface{}{
+		"done":    false,
+		"count":   float64(3),
+		"title":   "buy milk",
+		"tags":    []interface{}{"home", "urgent"},
+		"address": map[string]interface{}{"city": "Montreal"},
+	}
+
+	tests := []struct {
+		clauses [][]interface{}
+		want    bool
+	}{
+		{[][]interface{}{{"done", "=", false}}, true},
+		{[][]interface{}{{"done", "==", true}}, false},
+		{[][]interface{}{{"title", "!=", "walk dog"}}, true},
+		{[][]interface{}{{"count", ">", 2}, {"count", "<=", float64(3)}}, true},
+		{[][]interface{}{{"count", ">=", 4}}, false},
+		{[][]interface{}{{"title", "<", "c"}}, true},
+		{[][]interface{}{{"tags", "in", []interface{}{"urgent"}}}, true},
+		{[][]interface{}{{"tags", "!in", []interface{}{"work"}}}, true},
+		{[][]interface{}{{"tags", "nin", []interface{}{"home"}}}, false},
+		{[][]interface{}{{"address.city", "=", "Montreal"}}, true},
+		{[][]interface{}{{"missing", ">", 1}}, false},
+		{[][]interface{}{{"count", "like", 1}}, false},
+	}
+
+	for i, tc := range tests {
+		if got := matchClauses(doc, tc.clauses); got != tc.want {
+			t.Errorf("clauses %d: expected %v got %v", i, tc.want, got)
+		}
+	}
+}
+
+func TestLiveQuery(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+	volatile := cache.NewDevCache(log)
+	datastore := memory.New(volatile.PublishDocument)
+
+	auth := model.Auth{AccountID: "acct-1", UserID: "user-1", Role: 100}
+	if err := volatile.SetTyped("live-token", auth); err != nil {
+		t.Fatal(err)
+	}
+
+	base, col := "livetest", "tasks"
+
+	existing, err := datastore.CreateDocument(auth, base, col, map[string]interface{}{"done": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBroker(nil, datastore, volatile, log)
+
+	conf := model.DatabaseConfig{Name: base}
+	ctx := context.WithValue(context.Background(), middleware.ContextBase, conf)
+
+	messages := make(chan model.Command)
+	lastSeen := time.Now().UnixNano()
+	b.newConnections <- ConnectionData{ctx: ctx, messages: messages, lastSeen: &lastSeen}
+
+	sid := (<-messages).Data
+
+	next := func(typ string) model.Command {
+		t.Helper()
+
+		timeout := time.After(3 * time.Second)
+		for {
+			select {
+			case msg := <-messages:
+				if msg.Channel != "todo" {
+					continue
+				} else if msg.Type != typ {
+					t.Fatalf("expected %s got %s: %s", typ, msg.Type, msg.Data)
+				}
+				return msg
+			case <-timeout:
+				t.Fatalf("timed out waiting for %s", typ)
+			}
+		}
+	}
+
+	q := model.LiveQuery{
+		ID:         "todo",
+		Collection: col,
+		Filter:     [][]interface{}{{"done", "=", false}},
+	}
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.Broadcast <- model.Command{SID: sid, Type: model.MsgTypeLiveQuery, Token: "live-token", Data: string(data)}
+
+	// the ok reply and the initial results are sent concurrently
+	var added model.Command
+	timeout := time.After(3 * time.Second)
+	for len(added.Type) == 0 {
+		select {
+		case msg := <-messages:
+			if msg.Type == model.MsgTypeError {
+				t.Fatal(msg.Data)
+			} else if msg.Type == model.MsgTypeLiveAdded {
+				added = msg
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the initial results")
+		}
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(added.Data), &doc); err != nil {
+		t.Fatal(err)
+	} else if doc["id"] != existing["id"] {
+		t.Errorf("expected initial document %v got %v", existing["id"], doc["id"])
+	}
+
+	// give the subscription time to kick-off
+	time.Sleep(250 * time.Millisecond)
+
+	created, err := datastore.CreateDocument(auth, base, col, map[string]interface{}{"done": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next(model.MsgTypeLiveAdded)
+
+	id := created["id"].(string)
+
+	if _, err := datastore.UpdateDocument(auth, base, col, id, map[string]interface{}{"title": "changed"}); err != nil {
+		t.Fatal(err)
+	}
+	next(model.MsgTypeLiveChanged)
+
+	if _, err := datastore.UpdateDocument(auth, base, col, id, map[string]interface{}{"done": true}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := next(model.MsgTypeLiveRemoved); msg.Data != id {
+		t.Errorf("expected removed id %s got %s", id, msg.Data)
+	}
+
+	existingID := existing["id"].(string)
+	if _, err := datastore.DeleteDocument(auth, base, col, existingID); err != nil {
+		t.Fatal(err)
+	}
+	if msg := next(model.MsgTypeLiveRemoved); msg.Data != existingID {
+		t.Errorf("expected removed id %s got %s", existingID, msg.Data)
+	}
+
+	b.Broadcast <- model.Command{SID: sid, Type: model.MsgTypeLiveStop, Data: "todo"}
+	if msg := <-messages; msg.Type != model.MsgTypeOk {
+		t.Fatalf("expected ok got %s: %s", msg.Type, msg.Data)
+	}
+
+	if _, err := datastore.CreateDocument(auth, base, col, map[string]interface{}{"done": false}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-messages:
+		if msg.Channel == "todo" {
+			t.Errorf("expected no event after stopping got %s", msg.Type)
+		}
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestLiveQueryScope(t *testing.T) {
+	log := logger.Get(config.AppConfig{})
+	volatile := cache.NewDevCache(log)
+	datastore := memory.New(volatile.PublishDocument)
+
+	auth := model.Auth{
+		AccountID: "acct-1",
+		UserID:    "user-1",
+		Scope:     &model.TokenScope{ReadOnly: true, Collections: []string{"public"}},
+	}
+	if err := volatile.SetTyped("scoped-token", auth); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBroker(nil, datastore, volatile, log)
+
+	conf := model.DatabaseConfig{Name: "livescope"}
+	ctx := context.WithValue(context.Background(), middleware.ContextBase, conf)
+
+	messages := make(chan model.Command)
+	lastSeen := time.Now().UnixNano()
+	b.newConnections <- ConnectionData{ctx: ctx, messages: messages, lastSeen: &lastSeen}
+
+	sid := (<-messages).Data
+
+	subscribe := func(id, col string) model.Command {
+		t.Helper()
+
+		data, err := json.Marshal(model.LiveQuery{ID: id, Collection: col})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b.Broadcast <- model.Command{SID: sid, Type: model.MsgTypeLiveQuery, Token: "scoped-token", Data: string(data)}
+
+		timeout := time.After(3 * time.Second)
+		for {
+			select {
+			case msg := <-messages:
+				if msg.Type == model.MsgTypeError || msg.Type == model.MsgTypeOk {
+					return msg
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for the %s reply", id)
+			}
+		}
+	}
+
+	if msg := subscribe("denied", "private"); msg.Type != model.MsgTypeError {
+		t.Errorf("expected the scope to deny the collection got %s", msg.Type)
+	}
+
+	if msg := subscribe("allowed", "public"); msg.Type != model.MsgTypeOk {
+		t.Errorf("expected ok got %s: %s", msg.Type, msg.Data)
+	}
+}
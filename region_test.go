@@ -0,0 +1,90 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestDataResidency(t *testing.T) {
+	if err := middleware.SetRegions("ca", "ca=https://ca.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := middleware.SetRegions("ca", "eu=https://eu.example.com"); err == nil {
+		t.Error("expected an error when the instance's region is not one of the regions")
+	}
+
+	if err := middleware.SetRegions("ca", "ca=https://ca.example.com, eu=https://eu.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	defer middleware.SetRegions("", "")
+
+	resp := dbReq(t, acct.addDatabase, "GET", "/account/add-db?region=mars", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 with an unknown region got %s", resp.Status)
+	}
+
+	resp = dbReq(t, acct.addDatabase, "GET", "/account/add-db?region=eu", nil)
+	if resp.StatusCode != http.StatusMisdirectedRequest {
+		t.Errorf("expected status 421 creating a database of another region got %s", resp.Status)
+	} else if body := GetResponseBody(t, resp); !strings.Contains(body, "https://eu.example.com") {
+		t.Errorf("expected the URL of the region got %s", body)
+	}
+
+	resp = dbReq(t, acct.addDatabase, "GET", "/account/add-db", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var data struct {
+		PublicKey string `json:"pk"`
+	}
+	if err := parseBody(resp.Body, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := backend.DB.FindDatabase(data.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	} else if conf.Region != "ca" {
+		t.Errorf("expected the database to be in the instance's region got %q", conf.Region)
+	}
+
+	// a database of another region is not served
+	eu, err := backend.DB.CreateDatabase(model.DatabaseConfig{
+		ID:            "residencyeu",
+		TenantID:      conf.TenantID,
+		Name:          "residencyeu",
+		AllowedDomain: []string{"localhost"},
+		IsActive:      true,
+		Created:       time.Now(),
+		Region:        "eu",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := middleware.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL))
+
+	for pk, status := range map[string]int{pubKey: http.StatusNoContent, eu.ID: http.StatusMisdirectedRequest} {
+		req := httptest.NewRequest("POST", "/db/tasks", nil)
+		req.Header.Set("SB-PUBLIC-KEY", pk)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != status {
+			t.Errorf("%s: expected status %d got %d", pk, status, w.Code)
+		} else if status == http.StatusMisdirectedRequest && w.Header().Get(middleware.RegionHeader) != "eu" {
+			t.Errorf("expected the region header eu got %q", w.Header().Get(middleware.RegionHeader))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package staticbackend
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	zl := zerolog.New(&buf)
+	log := &logger.Logger{Logger: &zl}
+
+	var seen string
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = middleware.RequestID(r)
+			http.Error(w, "database is down", http.StatusInternalServerError)
+		}),
+		middleware.RequestLogger(log),
+	)
+
+	call := func(id string) (*httptest.ResponseRecorder, map[string]any) {
+		buf.Reset()
+
+		req := httptest.NewRequest("GET", "/db/tasks", nil)
+		if len(id) > 0 {
+			req.Header.Set(middleware.RequestIDHeader, id)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		var line map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		return w, line
+	}
+
+	w, line := call("caller-id_42")
+	if seen != "caller-id_42" {
+		t.Errorf("expected the caller's request id got %s", seen)
+	} else if v := w.Header().Get(middleware.RequestIDHeader); v != seen {
+		t.Errorf("expected the request id in the response got %s", v)
+	} else if line["requestId"] != seen {
+		t.Errorf("expected the request id in the log got %v", line["requestId"])
+	} else if line["level"] != "error" || line["error"] != "database is down" {
+		t.Errorf("expected the server error to be logged got %v", line)
+	}
+
+	_, line = call("bad id\nforged")
+	if seen == "bad id\nforged" || len(seen) == 0 {
+		t.Errorf("expected a generated request id got %q", seen)
+	} else if line["requestId"] != seen {
+		t.Errorf("expected the generated request id in the log got %v", line["requestId"])
+	}
+}
@@ -0,0 +1,182 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/quota"
+)
+
+// tusVersion is the version of the tus resumable upload protocol the
+// upload endpoints follow
+const tusVersion = "1.0.0"
+
+// startUpload creates a resumable upload session. The size comes from the
+// JSON body or the Upload-Length header.
+func startUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if auth.Scope != nil && auth.Scope.ReadOnly {
+		http.Error(w, "read-only tokens cannot upload files", http.StatusForbidden)
+		return
+	}
+
+	var data struct {
+		Filename string   `json:"filename"`
+		Name     string   `json:"name"`
+		Size     int64    `json:"size"`
+		Tags     []string `json:"tags"`
+	}
+	if r.ContentLength != 0 {
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if length := r.Header.Get("Upload-Length"); len(length) > 0 {
+		data.Size, err = strconv.ParseInt(length, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Upload-Length header", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if len(data.Filename) == 0 {
+		http.Error(w, "the filename is required", http.StatusBadRequest)
+		return
+	}
+
+	fileSvc := backend.Storage(auth, conf)
+	up, err := fileSvc.StartUpload(data.Filename, data.Name, data.Size, data.Tags)
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Location", "/storage/uploads/"+up.ID)
+	respond(w, http.StatusCreated, up)
+}
+
+// resumableUpload handles the requests on an upload session:
+// HEAD returns the current offset, PATCH appends a chunk, DELETE aborts
+// the upload and POST /storage/uploads/{id}/complete saves the file.
+func resumableUpload(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if auth.Scope != nil && auth.Scope.ReadOnly {
+		http.Error(w, "read-only tokens cannot upload files", http.StatusForbidden)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 3)
+	action := getURLPart(r.URL.Path, 4)
+
+	fileSvc := backend.Storage(auth, conf)
+
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Cache-Control", "no-store")
+
+	switch {
+	case r.Method == http.MethodHead && len(action) == 0:
+		up, err := fileSvc.GetUpload(id)
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+
+		w.Header().Set("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+		w.Header().Set("Upload-Length", strconv.FormatInt(up.Size, 10))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet && len(action) == 0:
+		up, err := fileSvc.GetUpload(id)
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+
+		respond(w, http.StatusOK, up)
+	case r.Method == http.MethodPatch && len(action) == 0:
+		offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Upload-Offset header", http.StatusBadRequest)
+			return
+		}
+
+		newOffset, err := fileSvc.WriteChunk(id, offset, r.Body)
+		if newOffset > 0 {
+			w.Header().Set("Upload-Offset", strconv.FormatInt(newOffset, 10))
+		}
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete && len(action) == 0:
+		if err := fileSvc.AbortUpload(id); err != nil {
+			uploadError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && action == "complete":
+		savedFile, err := fileSvc.CompleteUpload(id)
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+
+		respond(w, http.StatusOK, savedFile)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// uploadError writes the status code matching an upload error
+func uploadError(w http.ResponseWriter, err error) {
+	if quota.Respond(w, err) {
+		return
+	}
+
+	var uploadErr *backend.UploadError
+	if errors.As(err, &uploadErr) {
+		status := http.StatusUnsupportedMediaType
+		if uploadErr.Code == backend.UploadErrFileTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		} else if uploadErr.Code == backend.UploadErrFileInfected {
+			status = http.StatusUnprocessableEntity
+		}
+
+		respond(w, status, uploadErr)
+		return
+	}
+
+	switch {
+	case errors.Is(err, backend.ErrUploadNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, backend.ErrInvalidUploadSignature):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, backend.ErrOffsetMismatch):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, backend.ErrUploadIncomplete),
+		errors.Is(err, backend.ErrUploadSizeRequired),
+		errors.Is(err, backend.ErrChunkTooLarge):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
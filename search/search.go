@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/search/query"
@@ -18,9 +21,21 @@ const (
 	ChannelIndexEvent = "sys-fts"
 )
 
+// Operations of the index events, the document is indexed when empty
+const (
+	opRemove = "remove"
+	opDrop   = "drop"
+)
+
+// Search keeps a full-text index per database in a directory, each
+// instance applies the index events published by any instance so their
+// indexes stay in sync.
 type Search struct {
 	pubsub cache.Volatilizer
-	index  bleve.Index
+	dir    string
+
+	mu      sync.Mutex
+	indexes map[string]bleve.Index
 }
 
 type IndexDocument struct {
@@ -28,28 +43,24 @@ type IndexDocument struct {
 	DBName string `json:"dbname"`
 	Key    string `json:"key"`
 	Text   string `json:"text"`
+	Op     string `json:"op,omitempty"`
 }
 
-func New(filename string, pubsub cache.Volatilizer) (*Search, error) {
-	s := &Search{pubsub: pubsub}
+// New opens the indexes kept in the dir directory, created if missing. An
+// index shared by all databases, from previous versions, is moved aside
+// since the databases' indexes are rebuilt by reindexing their collections.
+func New(dir string, pubsub cache.Volatilizer) (*Search, error) {
+	s := &Search{pubsub: pubsub, dir: dir, indexes: make(map[string]bleve.Index)}
 
-	if _, err := os.Stat(filename); err != nil {
-		if !os.IsNotExist(err) {
-			return nil, err
-		}
-
-		idx, err := createMapping(filename)
-		if err != nil {
-			return nil, err
-		}
-		s.index = idx
-	} else {
-		idx, err := bleve.Open(filename)
-		if err != nil {
+	if _, err := os.Stat(filepath.Join(dir, "index_meta.json")); err == nil {
+		if err := os.Rename(dir, dir+".legacy"); err != nil {
 			return nil, err
 		}
+		log.Printf("the shared search index was moved to %s.legacy, the collections need to be reindexed", dir)
+	}
 
-		s.index = idx
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
 	}
 
 	go s.setupIndexEvent()
@@ -75,14 +86,76 @@ func createMapping(filename string) (bleve.Index, error) {
 	return bleve.New(filename, idxmap)
 }
 
+// indexOf returns the index of a database, opening or creating it. It
+// returns nil without error when the index does not exist and create is
+// false.
+func (s *Search) indexOf(dbName string, create bool) (bleve.Index, error) {
+	if !validName(dbName) {
+		return nil, fmt.Errorf("invalid database name: %s", dbName)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx, ok := s.indexes[dbName]; ok {
+		return idx, nil
+	}
+
+	filename := filepath.Join(s.dir, dbName)
+
+	var idx bleve.Index
+	if _, err := os.Stat(filename); err == nil {
+		idx, err = bleve.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	} else if !create {
+		return nil, nil
+	} else {
+		idx, err = createMapping(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.indexes[dbName] = idx
+	return idx, nil
+}
+
+// validName returns true if the database name can be used as the index's
+// directory name
+func validName(dbName string) bool {
+	return len(dbName) > 0 && dbName == filepath.Base(dbName) && !strings.HasPrefix(dbName, ".")
+}
+
+// Index adds or replaces the text of a document in its database's index
 func (s *Search) Index(dbName, col, id, text string) error {
-	doc := IndexDocument{
+	return s.publish(IndexDocument{
 		ID:     id,
 		DBName: dbName,
 		Key:    col,
 		Text:   text,
-	}
+	})
+}
+
+// Remove removes a document from its database's index
+func (s *Search) Remove(dbName, col, id string) error {
+	return s.publish(IndexDocument{
+		ID:     id,
+		DBName: dbName,
+		Key:    col,
+		Op:     opRemove,
+	})
+}
 
+// DeleteIndex deletes the index of a database
+func (s *Search) DeleteIndex(dbName string) error {
+	return s.publish(IndexDocument{DBName: dbName, Op: opDrop})
+}
+
+func (s *Search) publish(doc IndexDocument) error {
 	b, err := json.Marshal(doc)
 	if err != nil {
 		return err
@@ -109,20 +182,22 @@ type SearchResult struct {
 func (s *Search) Search(dbName, col, keywords string) (SearchResult, error) {
 	sr := SearchResult{DBName: dbName, Col: col}
 
-	tokens := strings.Split(keywords, " ")
+	idx, err := s.indexOf(dbName, false)
+	if err != nil {
+		return sr, err
+	} else if idx == nil {
+		// nothing was indexed for this database yet
+		return sr, nil
+	}
 
 	var queries []query.Query
 
-	dbQry := bleve.NewTermQuery(dbName)
-	dbQry.SetField("dbname")
-
 	colQry := bleve.NewTermQuery(col)
 	colQry.SetField("key")
 
-	queries = append(queries, dbQry)
 	queries = append(queries, colQry)
 
-	for _, keyword := range tokens {
+	for _, keyword := range strings.Fields(keywords) {
 		fq := bleve.NewFuzzyQuery(keyword)
 		fq.SetField("text")
 
@@ -140,18 +215,17 @@ func (s *Search) Search(dbName, col, keywords string) (SearchResult, error) {
 		return sr, errors.New("wtf? it's nil")
 	}
 
-	results, err := s.index.Search(req)
+	results, err := idx.Search(req)
 	if err != nil {
 		return sr, err
 	}
 
 	for _, r := range results.Hits {
-		parts := strings.Split(r.ID, "_")
-		if len(parts) != 3 {
+		if !strings.HasPrefix(r.ID, col+"/") {
 			continue
 		}
 
-		sr.IDs = append(sr.IDs, parts[2])
+		sr.IDs = append(sr.IDs, strings.TrimPrefix(r.ID, col+"/"))
 	}
 
 	return sr, nil
@@ -166,7 +240,9 @@ func (s *Search) setupIndexEvent() {
 	for {
 		select {
 		case msg := <-receiver:
-			go s.receivedIndexEvent(msg.Data)
+			// applied in order so a removal is not overwritten by an
+			// earlier index of the same document
+			s.receivedIndexEvent(msg.Data)
 		case <-close:
 			return
 		}
@@ -180,14 +256,113 @@ func (s *Search) receivedIndexEvent(data string) {
 		return
 	}
 
-	docID := fmt.Sprintf("%s_%s_%s", doc.DBName, doc.Key, doc.ID)
-	if err := s.index.Index(docID, doc); err != nil {
+	if doc.Op == opDrop {
+		if err := s.drop(doc.DBName); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	idx, err := s.indexOf(doc.DBName, doc.Op != opRemove)
+	if err != nil {
+		log.Println(err)
+		return
+	} else if idx == nil {
+		return
+	}
+
+	docID := doc.Key + "/" + doc.ID
+	if doc.Op == opRemove {
+		err = idx.Delete(docID)
+	} else {
+		err = idx.Index(docID, doc)
+	}
+	if err != nil {
 		log.Println(err)
 	}
 }
 
+func (s *Search) drop(dbName string) error {
+	if !validName(dbName) {
+		return fmt.Errorf("invalid database name: %s", dbName)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx, ok := s.indexes[dbName]; ok {
+		if err := idx.Close(); err != nil {
+			return err
+		}
+		delete(s.indexes, dbName)
+	}
+
+	return os.RemoveAll(filepath.Join(s.dir, dbName))
+}
+
 func (s *Search) Close() {
-	if err := s.index.Close(); err != nil {
-		log.Println(err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for dbName, idx := range s.indexes {
+		if err := idx.Close(); err != nil {
+			log.Println(err)
+		}
+		delete(s.indexes, dbName)
+	}
+}
+
+// systemFields are the documents' fields not indexed by default, with the
+// ones prefixed by sb_
+var systemFields = map[string]bool{"id": true, "accountId": true, "ownerId": true}
+
+// DocumentText returns the text of a document to index, the values of the
+// fields in order or, when no fields are set, its string values sorted by
+// field name. The nested objects and arrays are included.
+func DocumentText(doc map[string]any, fields []string) string {
+	var parts []string
+	if len(fields) > 0 {
+		for _, f := range fields {
+			parts = appendText(parts, doc[f])
+		}
+		return strings.Join(parts, " ")
+	}
+
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		if systemFields[k] || strings.HasPrefix(k, "sb_") {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		parts = appendText(parts, doc[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+func appendText(parts []string, v any) []string {
+	switch x := v.(type) {
+	case string:
+		if len(x) > 0 {
+			parts = append(parts, x)
+		}
+	case []any:
+		for _, item := range x {
+			parts = appendText(parts, item)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			parts = appendText(parts, x[k])
+		}
 	}
+	return parts
 }
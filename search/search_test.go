@@ -20,7 +20,7 @@ func TestSearchIndexAndQuery(t *testing.T) {
 
 	go fakeSySubscriber(pubsub, l)
 
-	s, err := search.New("testdata/test.fts", pubsub)
+	s, err := search.New(t.TempDir(), pubsub)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -39,6 +39,12 @@ func TestSearchIndexAndQuery(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// the databases have their own index
+	err = s.Index("other", "catalog", "789", "this is the first doc of other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	// let time for go rountines to propagate the system
 	// event to create new full-text index
 	time.Sleep(4250 * time.Millisecond)
@@ -50,7 +56,53 @@ func TestSearchIndexAndQuery(t *testing.T) {
 		t.Errorf("expected 1 result, got %d", len(results.IDs))
 	} else if results.IDs[0] != "123" {
 		t.Log(results)
-		t.Errorf("expected id to be test_catalog_123 got %s", results.IDs[0])
+		t.Errorf("expected id to be 123 got %s", results.IDs[0])
+	}
+
+	if err := s.Remove("test", "catalog", "123"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(1250 * time.Millisecond)
+
+	results, err = s.Search("test", "catalog", "first doc")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(results.IDs) != 0 {
+		t.Errorf("expected the removed document not to be found got %v", results.IDs)
+	}
+
+	if err := s.DeleteIndex("other"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(1250 * time.Millisecond)
+
+	results, err = s.Search("other", "catalog", "first doc")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(results.IDs) != 0 {
+		t.Errorf("expected the deleted index to be empty got %v", results.IDs)
+	}
+}
+
+func TestDocumentText(t *testing.T) {
+	doc := map[string]any{
+		"id":         "123",
+		"accountId":  "acct",
+		"sb_created": "2001-02-03",
+		"title":      "hello world",
+		"tags":       []any{"go", "search"},
+		"author":     map[string]any{"name": "Dominic"},
+		"count":      float64(4),
+	}
+
+	if text := search.DocumentText(doc, nil); text != "Dominic go search hello world" {
+		t.Errorf("unexpected text of all the fields: %s", text)
+	}
+
+	if text := search.DocumentText(doc, []string{"title", "tags"}); text != "hello world go search" {
+		t.Errorf("unexpected text of the title and tags: %s", text)
 	}
 }
 
@@ -1,12 +1,15 @@
 package staticbackend
 
 import (
-	"log"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/staticbackendhq/core/backend"
 	"github.com/staticbackendhq/core/email"
 	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/quota"
 )
 
 func sudoSendMail(w http.ResponseWriter, r *http.Request) {
@@ -26,21 +29,126 @@ func sudoSendMail(w http.ResponseWriter, r *http.Request) {
 		data.HTMLBody = data.TextBody
 	}
 
-	if err := backend.Emailer.Send(data); err != nil {
+	config, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the email is queued, its ID is used to follow the delivery status
+	id, err := backend.QueueEmail(config, data)
+	if quota.Respond(w, err) {
+		return
+	} else if errors.Is(err, email.ErrInvalidAttachment) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	config, _, err := middleware.Extract(r, false)
+	respond(w, http.StatusOK, id)
+}
+
+// emailMessage returns a queued email and its delivery status from GET
+// /email/message/{id}
+func emailMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	msg, err := backend.DB.GetEmailMessage(conf.Name, getURLPart(r.URL.Path, 3))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	respond(w, http.StatusOK, msg)
+}
+
+// emailLog searches the emails sent by the database from GET /email/log
+// with the to, template, status, q, since, until and limit query string
+// parameters. The bodies and attachments' content are omitted, they are
+// returned by /email/message/{id}.
+func emailLog(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	filter := model.EmailLogFilter{
+		To:       qs.Get("to"),
+		Template: qs.Get("template"),
+		Status:   qs.Get("status"),
+		Query:    qs.Get("q"),
+		Limit:    100,
+	}
+
+	if s := qs.Get("limit"); len(s) > 0 {
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if s := qs.Get("since"); len(s) > 0 {
+		filter.Since, err = parseDate(s)
+		if err != nil {
+			http.Error(w, "invalid since date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if s := qs.Get("until"); len(s) > 0 {
+		filter.Until, err = parseDate(s)
+		if err != nil {
+			http.Error(w, "invalid until date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	msgs, err := backend.DB.ListEmailLog(conf.Name, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for i := range msgs {
+		msgs[i].HTMLBody, msgs[i].TextBody = "", ""
+		for j := range msgs[i].Attachments {
+			msgs[i].Attachments[j].Content = nil
+		}
+	}
+
+	respond(w, http.StatusOK, msgs)
+}
+
+// emailUsage returns the emails sent this month by the tenant with its
+// plan's quota
+func emailUsage(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := backend.DB.IncrementMonthlyEmailSent(config.ID); err != nil {
-		//TODO: do something better with this error
-		log.Println("error increasing monthly email sent: ", err)
+	usage, err := backend.EmailUsage(conf)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	respond(w, http.StatusOK, true)
+	respond(w, http.StatusOK, usage)
 }
@@ -1,11 +1,13 @@
 package staticbackend
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/staticbackendhq/core/backend"
 	"github.com/staticbackendhq/core/config"
 	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/model"
 )
 
 func Test_Sendmail(t *testing.T) {
@@ -23,3 +25,110 @@ func Test_Sendmail(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestSendmailDeliveryStatus(t *testing.T) {
+	data := email.SendMailData{
+		From:    config.Current.FromEmail,
+		To:      "queued@test.com",
+		Subject: "Queued from unit test",
+		Body:    "<p>queued</p>",
+	}
+
+	resp := dbReq(t, sudoSendMail, "POST", "/sudo/sendmail", data, true)
+	defer resp.Body.Close()
+
+	var id string
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp2 := dbReq(t, emailMessage, "GET", "/email/message/"+id, nil, true)
+	defer resp2.Body.Close()
+
+	var msg model.EmailMessage
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp2))
+	} else if err := parseBody(resp2.Body, &msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Status != model.EmailStatusSent || msg.Attempts != 1 || msg.To != data.To {
+		t.Errorf("unexpected delivery status %v", msg)
+	}
+
+	resp3 := dbReq(t, emailMessage, "GET", "/email/message/unknown", nil, true)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown email got %d", resp3.StatusCode)
+	}
+}
+
+func TestEmailUsageEndpoint(t *testing.T) {
+	resp := dbReq(t, emailUsage, "GET", "/sudo/_/email-usage", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var usage model.EmailUsage
+	if err := parseBody(resp.Body, &usage); err != nil {
+		t.Fatal(err)
+	} else if usage.MaxEmails == 0 || len(usage.Month) == 0 {
+		t.Errorf("expected the month and plan quota got %v", usage)
+	}
+}
+
+func TestEmailLogEndpoint(t *testing.T) {
+	conf, err := backend.DB.FindDatabase(dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := model.EmailTemplate{
+		Name:     model.EmailTemplatePasswordReset,
+		Subject:  "Reset your password",
+		HTMLBody: "<p>Your code is [code]</p>",
+	}
+	if _, err := backend.SaveEmailTemplate(conf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteEmailTemplate(dbName, tmpl.Name)
+
+	data := email.SendMailData{To: "log@test.com"}
+	if err := backend.SendEmailTemplate(conf, tmpl.Name, data, map[string]any{"code": "123456"}); err != nil {
+		t.Fatal(err)
+	} else if _, err := backend.ProcessEmailQueue(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := dbReq(t, emailLog, "GET", "/email/log?to=log@test.com&template="+tmpl.Name+"&status=sent", nil, true)
+	defer resp.Body.Close()
+
+	var msgs []model.EmailMessage
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected the password reset email got %v", msgs)
+	} else if msgs[0].Subject != tmpl.Subject || len(msgs[0].HTMLBody) > 0 {
+		t.Errorf("expected the email metadata without its body got %v", msgs[0])
+	}
+
+	resp2 := dbReq(t, emailLog, "GET", "/email/log?since=not-a-date", nil, true)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid date got %d", resp2.StatusCode)
+	}
+}
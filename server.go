@@ -9,16 +9,23 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/cache"
 	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/function"
 	"github.com/staticbackendhq/core/internal"
 	"github.com/staticbackendhq/core/logger"
 	"github.com/staticbackendhq/core/middleware"
 	"github.com/staticbackendhq/core/model"
 	"github.com/staticbackendhq/core/realtime"
+	"github.com/staticbackendhq/core/storage"
+	"github.com/staticbackendhq/core/tracing"
 
 	"github.com/stripe/stripe-go/v72"
 	"golang.org/x/sync/errgroup"
@@ -61,6 +68,8 @@ func Start(c config.AppConfig, log *logger.Logger) {
 	//go hub.run()
 
 	// Server Send Event, alternative to websocket
+	setRealtimeLimits(c)
+
 	b := realtime.NewBroker(func(ctx context.Context, key string) (string, error) {
 		//TODO: Experimental, let un-authenticated user connect
 		// useful for an Intercom-like SaaS I'm building.
@@ -101,7 +110,7 @@ func Start(c config.AppConfig, log *logger.Logger) {
 		}
 
 		return key, nil
-	}, backend.Cache, log)
+	}, backend.DB, backend.Cache, log)
 
 	database := &Database{
 		cache: backend.Cache,
@@ -112,82 +121,206 @@ func Start(c config.AppConfig, log *logger.Logger) {
 		middleware.Cors(),
 	}
 
+	// per-plan rate limiting is only enforced when enabled, the auth
+	// endpoints have their own stricter limit per client IP
+	rateLimit := func(next http.Handler) http.Handler { return next }
+	authRateLimit := rateLimit
+	if config.Current.RateLimit {
+		rateLimit = middleware.RateLimit(backend.DB, backend.Cache)
+		authRateLimit = middleware.RateLimitRoute(middleware.RouteAuth, backend.DB, backend.Cache)
+	}
+
 	pubWithDB := []middleware.Middleware{
 		middleware.Cors(),
 		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequestAnalytics(),
+		middleware.TenantCors(),
+		rateLimit,
+		middleware.MeterRequests(),
+		middleware.RestrictIP(false),
+	}
+
+	authWithDB := []middleware.Middleware{
+		middleware.Cors(),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequestAnalytics(),
+		middleware.TenantCors(),
+		authRateLimit,
+		middleware.MeterRequests(),
+		middleware.RestrictIP(false),
 	}
 
 	stdAuth := []middleware.Middleware{
 		middleware.Cors(),
 		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequestAnalytics(),
+		middleware.TenantCors(),
+		rateLimit,
+		middleware.MeterRequests(),
 		middleware.RequireAuth(backend.DB, backend.Cache),
+		middleware.RestrictIP(false),
 	}
 
+	// account management is not allowed with a scoped token
+	stdFullAuth := []middleware.Middleware{
+		middleware.Cors(),
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequestAnalytics(),
+		middleware.TenantCors(),
+		rateLimit,
+		middleware.MeterRequests(),
+		middleware.RequireAuth(backend.DB, backend.Cache),
+		middleware.RequireFullToken(),
+		middleware.RestrictIP(false),
+	}
+
+	// the IP access settings restrict the root endpoints and, when their
+	// scope is all, the other endpoints of the database
 	stdRoot := []middleware.Middleware{
 		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequestAnalytics(),
+		middleware.MeterRequests(),
+		middleware.RestrictIP(true),
 		middleware.RequireRoot(backend.DB, backend.Cache),
 	}
 
+	// withCaptcha appends the captcha verification to a chain that
+	// includes the WithDB middleware
+	withCaptcha := func(chain []middleware.Middleware, endpoint string) []middleware.Middleware {
+		mw := append([]middleware.Middleware{}, chain...)
+		return append(mw, middleware.RequireCaptcha(backend.Cache, endpoint))
+	}
+
+	// idempotent appends the Idempotency-Key replay to a chain that
+	// includes the auth middleware
+	idempotent := func(chain []middleware.Middleware) []middleware.Middleware {
+		mw := append([]middleware.Middleware{}, chain...)
+		return append(mw, middleware.Idempotency(backend.Cache))
+	}
+
 	// static assets
 	http.Handle("/static/", http.StripPrefix("/", http.FileServer(http.FS(content))))
 
 	m := &membership{log: log}
 
-	http.Handle("/login/magic", middleware.Chain(http.HandlerFunc(m.magicLink), pubWithDB...))
-	http.Handle("/login", middleware.Chain(http.HandlerFunc(m.login), pubWithDB...))
-	http.Handle("/register", middleware.Chain(http.HandlerFunc(m.register), pubWithDB...))
-	http.Handle("/email", middleware.Chain(http.HandlerFunc(m.emailExists), pubWithDB...))
+	http.Handle("/login/magic", middleware.Chain(http.HandlerFunc(m.magicLink), authWithDB...))
+	http.Handle("/login", middleware.Chain(http.HandlerFunc(m.login), withCaptcha(authWithDB, middleware.CaptchaOnLogin)...))
+	http.Handle("/register", middleware.Chain(http.HandlerFunc(m.register), withCaptcha(authWithDB, middleware.CaptchaOnRegister)...))
+	http.Handle("/email", middleware.Chain(http.HandlerFunc(m.emailExists), authWithDB...))
 	http.Handle("/password/resetcode", middleware.Chain(http.HandlerFunc(m.setResetCode), stdRoot...))
-	http.Handle("/password/reset", middleware.Chain(http.HandlerFunc(m.resetPassword), pubWithDB...))
+	http.Handle("/password/reset", middleware.Chain(http.HandlerFunc(m.resetPassword), withCaptcha(authWithDB, middleware.CaptchaOnPasswordReset)...))
+	http.Handle("/captcha/challenge", middleware.Chain(http.HandlerFunc(captchaChallenge), pubWithDB...))
 	//http.Handle("/setrole", chain(http.HandlerFunc(setRole), withDB))
 	http.Handle("/me", middleware.Chain(http.HandlerFunc(m.me), stdAuth...))
+	http.Handle("/me/token", middleware.Chain(http.HandlerFunc(m.scopedToken), stdFullAuth...))
+	http.Handle("/me/locale", middleware.Chain(http.HandlerFunc(m.locale), stdAuth...))
+	http.Handle("/me/verification", middleware.Chain(http.HandlerFunc(m.sendVerification), stdAuth...))
 
 	// oauth handlers
 	el := &ExternalLogins{log: log}
-	http.Handle("/oauth/login", middleware.Chain(el.login(), pubWithDB...))
+	http.Handle("/oauth/login", middleware.Chain(el.login(), authWithDB...))
 	http.Handle("/oauth/callback/", middleware.Chain(el.callback(), stdPub...))
 	http.Handle("/oauth/get-user", middleware.Chain(http.HandlerFunc(el.getUser), pubWithDB...))
 
 	http.Handle("/sudogettoken/", middleware.Chain(http.HandlerFunc(m.sudoGetTokenFromAccountID), stdRoot...))
 
 	// database routes
-	http.Handle("/db/", middleware.Chain(http.HandlerFunc(database.dbreq), stdAuth...))
+	http.Handle("/db/", middleware.Chain(http.HandlerFunc(database.dbreq), idempotent(stdAuth)...))
 	http.Handle("/db/count/", middleware.Chain(http.HandlerFunc(database.count), stdAuth...))
 	http.Handle("/query/", middleware.Chain(http.HandlerFunc(database.query), stdAuth...))
-	http.Handle("/inc/", middleware.Chain(http.HandlerFunc(database.increase), stdAuth...))
+	http.Handle("/inc/", middleware.Chain(http.HandlerFunc(database.increase), idempotent(stdAuth)...))
 	http.Handle("/sudoquery/", middleware.Chain(http.HandlerFunc(database.query), stdRoot...))
 	http.Handle("/sudolistall/", middleware.Chain(http.HandlerFunc(database.listCollections), stdRoot...))
 	http.Handle("/sudo/index", middleware.Chain(http.HandlerFunc(database.index), stdRoot...))
-	http.Handle("/sudo/", middleware.Chain(http.HandlerFunc(database.dbreq), stdRoot...))
+	// the root actions added next to the collections are under /sudo/_/
+	// since /sudo/{name} is the collection with that name
+	http.Handle("/sudo/", middleware.Chain(http.HandlerFunc(database.dbreq), idempotent(stdRoot)...))
 	http.Handle("/newid", middleware.Chain(http.HandlerFunc(database.newID), stdAuth...))
 	http.Handle("/search", middleware.Chain(http.HandlerFunc(database.search), stdAuth...))
+	http.Handle("/sudo/search/reindex/", middleware.Chain(http.HandlerFunc(database.reindex), stdRoot...))
 
 	// forms routes
 	http.Handle("/postform/", middleware.Chain(http.HandlerFunc(submitForm), pubWithDB...))
 	http.Handle("/form", middleware.Chain(http.HandlerFunc(listForm), stdRoot...))
+	http.Handle("/form/", middleware.Chain(http.HandlerFunc(formActions), stdRoot...))
 
 	// storage
 	http.Handle("/storage/upload", middleware.Chain(http.HandlerFunc(upload), stdAuth...))
+	http.Handle("/storage/sign", middleware.Chain(http.HandlerFunc(signedURL), stdAuth...))
+	http.Handle("/storage/files", middleware.Chain(http.HandlerFunc(listFiles), stdAuth...))
+	http.Handle("/storage/delete", middleware.Chain(http.HandlerFunc(deleteFile), stdAuth...))
+	http.Handle("/storage/link", middleware.Chain(http.HandlerFunc(linkFile), stdAuth...))
+	http.Handle("/storage/presign", middleware.Chain(http.HandlerFunc(presignUpload), stdAuth...))
+	http.Handle("/storage/presign/confirm", middleware.Chain(http.HandlerFunc(confirmUpload), stdAuth...))
+	http.Handle("/storage/uploads", middleware.Chain(http.HandlerFunc(startUpload), stdAuth...))
+	http.Handle("/storage/uploads/", middleware.Chain(http.HandlerFunc(resumableUpload), stdAuth...))
+	http.Handle("/storage/download", compressFiles(http.HandlerFunc(download)))
 	http.Handle("/sudostorage/delete", middleware.Chain(http.HandlerFunc(deleteFile), stdRoot...))
+	http.Handle("/sudo/_/storage-usage", middleware.Chain(http.HandlerFunc(storageUsage), stdRoot...))
+	http.Handle("/sudo/_/email-usage", middleware.Chain(http.HandlerFunc(emailUsage), stdRoot...))
+	http.Handle("/sudo/_/stats", middleware.Chain(http.HandlerFunc(appStats), stdRoot...))
+	http.Handle("/sudo/_/analytics", middleware.Chain(http.HandlerFunc(requestAnalytics), stdRoot...))
+	http.Handle("/sudo/_/import", middleware.Chain(http.HandlerFunc(importData), stdRoot...))
 
 	// sudo actions
 	http.Handle("/sudo/sendmail", middleware.Chain(http.HandlerFunc(sudoSendMail), stdRoot...))
+	http.Handle("/email/template", middleware.Chain(http.HandlerFunc(emailTemplates), stdRoot...))
+	http.Handle("/email/template/", middleware.Chain(http.HandlerFunc(emailTemplateActions), stdRoot...))
+	http.Handle("/email/message/", middleware.Chain(http.HandlerFunc(emailMessage), stdRoot...))
+	http.Handle("/email/log", middleware.Chain(http.HandlerFunc(emailLog), stdRoot...))
+	http.Handle("/email/webhook", middleware.Chain(http.HandlerFunc(emailWebhookURLs), stdRoot...))
+	http.Handle("/email/webhook/", middleware.Chain(http.HandlerFunc(emailWebhook), pubWithDB...))
+	http.Handle("/email/suppression", middleware.Chain(http.HandlerFunc(emailSuppressions), stdRoot...))
+	http.Handle("/email/suppression/", middleware.Chain(http.HandlerFunc(removeEmailSuppression), stdRoot...))
+	http.Handle("/email/events", middleware.Chain(http.HandlerFunc(listEmailEvents), stdRoot...))
+	http.Handle("/webhook", middleware.Chain(http.HandlerFunc(webhooks), stdRoot...))
+	http.Handle("/webhook/deliveries", middleware.Chain(http.HandlerFunc(webhookDeliveries), stdRoot...))
+	http.Handle("/webhook/redeliver/", middleware.Chain(http.HandlerFunc(redeliverWebhook), stdRoot...))
 	http.Handle("/sudo/cache", middleware.Chain(http.HandlerFunc(sudoCache), stdRoot...))
+	http.Handle("/sudo/_/audit", middleware.Chain(http.HandlerFunc(listAuditEvents), stdRoot...))
+	http.Handle("/sudo/_/purge-user", middleware.Chain(http.HandlerFunc(purgeUser), stdRoot...))
+
+	// app analytics events
+	http.Handle("/events", middleware.Chain(http.HandlerFunc(ingestEvents), pubWithDB...))
+	http.Handle("/sudo/_/events", middleware.Chain(http.HandlerFunc(listEvents), stdRoot...))
+	http.Handle("/sudo/_/events/series", middleware.Chain(http.HandlerFunc(eventSeries), stdRoot...))
 
 	// account
 	acct := &accounts{log: log}
 	http.Handle("/account/init", middleware.Chain(http.HandlerFunc(acct.create), stdPub...))
 	http.Handle("/account/auth", middleware.Chain(http.HandlerFunc(acct.auth), stdRoot...))
 	http.Handle("/account/portal", middleware.Chain(http.HandlerFunc(acct.portal), stdRoot...))
-	http.Handle("/account/users/", middleware.Chain(http.HandlerFunc(acct.deleteUser), stdAuth...))
-	http.Handle("/account/users", middleware.Chain(http.HandlerFunc(acct.addUser), stdAuth...))
-	http.Handle("/account/add-db", middleware.Chain(http.HandlerFunc(acct.addDatabase), stdAuth...))
+	http.Handle("/account/users/", middleware.Chain(http.HandlerFunc(acct.userReq), stdFullAuth...))
+	http.Handle("/account/users", middleware.Chain(http.HandlerFunc(acct.addUser), stdFullAuth...))
+	http.Handle("/account/add-db", middleware.Chain(http.HandlerFunc(acct.addDatabase), stdFullAuth...))
+	http.Handle("/account/invite/accept", middleware.Chain(http.HandlerFunc(acct.acceptInvite), pubWithDB...))
+	http.Handle("/account/invite/", middleware.Chain(http.HandlerFunc(acct.revokeInvite), stdFullAuth...))
+	http.Handle("/account/invite", middleware.Chain(http.HandlerFunc(acct.invite), stdFullAuth...))
+	http.Handle("/account/settings", middleware.Chain(http.HandlerFunc(settings), stdRoot...))
+	http.Handle("/account/cors", middleware.Chain(http.HandlerFunc(corsSettings), stdRoot...))
+	http.Handle("/account/delete", middleware.Chain(http.HandlerFunc(appDeletion), stdRoot...))
+	http.Handle("/account/delete/confirm", middleware.Chain(http.HandlerFunc(confirmAppDeletion), stdRoot...))
+	http.Handle("/account/transfer/accept", middleware.Chain(http.HandlerFunc(acceptOwnershipTransfer), stdRoot...))
+	http.Handle("/account/transfer/", middleware.Chain(http.HandlerFunc(cancelOwnershipTransfer), stdRoot...))
+	http.Handle("/account/transfer", middleware.Chain(http.HandlerFunc(ownershipTransfers), stdRoot...))
 
 	// stripe webhooks
 	swh := stripeWebhook{log: log}
 	http.HandleFunc("/stripe", swh.process)
 
+	// billing plans
+	http.Handle("/billing/plans", middleware.Chain(http.HandlerFunc(listPlans), stdRoot...))
+	http.Handle("/billing/subscribe", middleware.Chain(http.HandlerFunc(subscribePlan), stdRoot...))
+
+	// instance admin
+	http.Handle("/admin/databases/", middleware.Chain(http.HandlerFunc(adminDatabase), middleware.RequireAdmin(config.Current.AdminToken)))
+	http.Handle("/admin/tenants/", middleware.Chain(http.HandlerFunc(adminTenantFlags), middleware.RequireAdmin(config.Current.AdminToken)))
+	http.Handle("/admin/audit", middleware.Chain(http.HandlerFunc(adminAuditEvents), middleware.RequireAdmin(config.Current.AdminToken)))
+	http.Handle("/admin/vault/rewrap", middleware.Chain(http.HandlerFunc(adminRewrapSecrets), middleware.RequireAdmin(config.Current.AdminToken)))
+
 	http.HandleFunc("/ping", ping)
+	http.HandleFunc("/healthz", healthz)
+	http.HandleFunc("/readyz", readyz)
 
 	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		//TODO: when we move from SSE to full WebSocket re-enable this upgrade
@@ -196,6 +329,10 @@ func Start(c config.AppConfig, log *logger.Logger) {
 
 	http.Handle("/sse/connect", middleware.Chain(http.HandlerFunc(b.Accept), pubWithDB...))
 	receiveMessage := func(w http.ResponseWriter, r *http.Request) {
+		if realtime.MaxMessageSize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, realtime.MaxMessageSize)
+		}
+
 		var msg model.Command
 		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -207,6 +344,29 @@ func Start(c config.AppConfig, log *logger.Logger) {
 		respond(w, http.StatusOK, true)
 	}
 	http.Handle("/sse/msg", middleware.Chain(http.HandlerFunc(receiveMessage), pubWithDB...))
+	realtimeStats := func(w http.ResponseWriter, r *http.Request) {
+		conf, _, err := middleware.Extract(r, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		usage, err := b.Usage(conf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		data := new(struct {
+			Usage  model.RealtimeUsage `json:"usage"`
+			Server realtime.Stats      `json:"server"`
+		})
+		data.Usage = usage
+		data.Server = b.Stats()
+
+		respond(w, http.StatusOK, data)
+	}
+	http.Handle("/sudo/_/realtime-stats", middleware.Chain(http.HandlerFunc(realtimeStats), stdRoot...))
 
 	// server-side functions
 	f := &functions{datastore: backend.DB}
@@ -215,11 +375,42 @@ func Start(c config.AppConfig, log *logger.Logger) {
 	http.Handle("/fn/delete/", middleware.Chain(http.HandlerFunc(f.del), stdRoot...))
 	http.Handle("/fn/del/", middleware.Chain(http.HandlerFunc(f.del), stdRoot...))
 	http.Handle("/fn/info/", middleware.Chain(http.HandlerFunc(f.info), stdRoot...))
-	http.Handle("/fn/exec/", middleware.Chain(http.HandlerFunc(f.exec), stdAuth...))
+	http.Handle("/fn/exec/", middleware.Chain(http.HandlerFunc(f.exec), idempotent(stdAuth)...))
 	http.Handle("/fn", middleware.Chain(http.HandlerFunc(f.list), stdRoot...))
 
+	// OpenAPI document of the database's endpoints
+	http.Handle("/openapi.json", middleware.Chain(http.HandlerFunc(openAPI), stdRoot...))
+	http.Handle("/codegen", middleware.Chain(http.HandlerFunc(generateClient), stdRoot...))
+
+	// scheduled tasks
+	http.Handle("/task", middleware.Chain(http.HandlerFunc(tasks), stdRoot...))
+	http.Handle("/task/", middleware.Chain(http.HandlerFunc(taskActions), stdRoot...))
+
+	// database backups
+	http.Handle("/backup", middleware.Chain(http.HandlerFunc(backups), stdRoot...))
+	http.Handle("/backup/", middleware.Chain(http.HandlerFunc(backupActions), stdRoot...))
+
+	// dev and staging environments of the apps
+	http.Handle("/environments", middleware.Chain(http.HandlerFunc(environments), stdRoot...))
+	http.Handle("/environments/promote", middleware.Chain(http.HandlerFunc(promoteEnvironment), stdRoot...))
+
+	// secrets vault
+	http.Handle("/vault", middleware.Chain(http.HandlerFunc(listSecrets), stdRoot...))
+	http.Handle("/vault/", middleware.Chain(http.HandlerFunc(secretActions), stdRoot...))
+
+	// custom domains
+	http.Handle("/domain", middleware.Chain(http.HandlerFunc(domains), stdRoot...))
+	http.Handle("/domain/", middleware.Chain(http.HandlerFunc(domainActions), stdRoot...))
+
 	// pubsub
 	http.Handle("/publish-message", middleware.Chain(http.HandlerFunc(publishMessage), stdRoot...))
+	http.Handle("/sudo/_/channels", middleware.Chain(http.HandlerFunc(listChannels), stdRoot...))
+	http.Handle("/sudo/_/broadcast", middleware.Chain(http.HandlerFunc(broadcast), stdRoot...))
+
+	// push notifications
+	http.Handle("/push/subscribe", middleware.Chain(http.HandlerFunc(pushSubscribe), stdAuth...))
+	http.Handle("/push/unsubscribe/", middleware.Chain(http.HandlerFunc(pushUnsubscribe), stdAuth...))
+	http.Handle("/push/vapid-key", middleware.Chain(http.HandlerFunc(vapidKey), pubWithDB...))
 
 	// extras routes
 	ex := &extras{log: log}
@@ -228,11 +419,17 @@ func Start(c config.AppConfig, log *logger.Logger) {
 	http.Handle("/extra/htmltox", middleware.Chain(http.HandlerFunc(ex.htmlToX), stdAuth...))
 
 	// local storage file serving
-	// only available in dev mode since it's serving /tmp
-	// where the local storage provider serve files
-	if config.Current.AppEnv == AppEnvDev {
-		fs := http.FileServer(http.Dir(os.TempDir()))
-		http.Handle("/localfs/", http.StripPrefix("/localfs/", fs))
+	// available in dev mode (serving /tmp by default) or when a storage
+	// directory is configured for self-hosted instances
+	if local, ok := backend.LocalStorage(); ok {
+		if config.Current.AppEnv == AppEnvDev || len(local.Root) > 0 {
+			dir := local.Root
+			if len(dir) == 0 {
+				dir = os.TempDir()
+			}
+
+			http.Handle("/localfs/", localFileServer(dir))
+		}
 	}
 
 	// ui routes
@@ -272,20 +469,123 @@ func Start(c config.AppConfig, log *logger.Logger) {
 		cancel()
 	}()
 
+	setBodyLimits(c)
+	setFunctionLimits(c)
+
+	if err := setReloadable(c); err != nil {
+		log.Fatal().Err(err).Msg("invalid rate limits")
+	}
+
+	// the rate limits and CORS origins of the configuration file are
+	// reloaded on SIGHUP
+	if len(c.ConfigFile) > 0 {
+		go reloadOnHangup(c, log)
+	}
+
+	if err := middleware.SetRegions(c.Region, c.RegionURLs); err != nil {
+		log.Fatal().Err(err).Msg("invalid REGION_URLS")
+	}
+
+	if len(c.TrustedProxies) > 0 {
+		if err := middleware.SetTrustedProxies(strings.Split(c.TrustedProxies, ",")); err != nil {
+			log.Fatal().Err(err).Msg("invalid TRUSTED_PROXIES")
+		}
+	}
+
+	// the routes of the v2 API replacing their v1 handler, the others
+	// are shared by both versions
+	v2 := http.NewServeMux()
+	versions := map[int]*http.ServeMux{middleware.APIv2: v2}
+
+	// every request gets a correlation ID, in the trace when enabled, the
+	// ones to a custom domain get its app's public key, its version prefix
+	// is removed and its body is limited before being parsed
+	global := []middleware.Middleware{
+		middleware.RequestLogger(log),
+		middleware.CustomDomains(backend.DB, backend.Cache, backend.AppHost()),
+		middleware.APIVersions(versions),
+		middleware.LimitBody(),
+	}
+	if tracing.Enabled() {
+		global = append([]middleware.Middleware{middleware.Trace()}, global...)
+	}
+
+	handler := middleware.Chain(http.DefaultServeMux, global...)
+
 	httpsvr := &http.Server{
-		Addr: ":" + c.Port,
+		Addr:    ":" + c.Port,
+		Handler: handler,
+	}
+
+	// the custom domains are served over HTTPS when ACME is configured, the
+	// HTTP server answers the ACME challenges
+	var tlssvr *http.Server
+	if len(c.ACMEEmail) > 0 {
+		certs := backend.NewCertManager(c)
+
+		port := c.TLSPort
+		if len(port) == 0 {
+			port = "443"
+		}
+
+		tlssvr = &http.Server{
+			Addr:      ":" + port,
+			Handler:   handler,
+			TLSConfig: certs.TLSConfig(),
+		}
+		httpsvr.Handler = certs.HTTPHandler(handler)
 	}
 
 	g, gCtx := errgroup.WithContext(ctx)
 	g.Go(func() error {
-		return httpsvr.ListenAndServe()
+		if err := httpsvr.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
 	})
+	if tlssvr != nil {
+		g.Go(func() error {
+			if err := tlssvr.ListenAndServeTLS("", ""); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+	}
 	g.Go(func() error {
 		<-gCtx.Done()
+
+		// the in-flight requests and running functions have until the
+		// deadline to complete
+		timeout := 30 * time.Second
+		if c.ShutdownTimeout > 0 {
+			timeout = time.Duration(c.ShutdownTimeout) * time.Second
+		}
+
+		sctx, scancel := context.WithTimeout(context.Background(), timeout)
+		defer scancel()
+
+		log.Info().Dur("timeout", timeout).Msg("shutting down, draining connections")
+
+		// the realtime connections never complete by themselves
+		b.Close()
+
+		err := httpsvr.Shutdown(sctx)
+		if tlssvr != nil {
+			if terr := tlssvr.Shutdown(sctx); err == nil {
+				err = terr
+			}
+		}
+		if err := backend.Drain(sctx); err != nil {
+			log.Error().Err(err).Msg("error draining the functions and email queue")
+		}
+
 		if !c.NoFullTextSearch {
 			backend.Search.Close()
 		}
-		return httpsvr.Shutdown(context.Background())
+		if err := tracing.Shutdown(context.Background()); err != nil {
+			log.Error().Err(err).Msg("error exporting the pending spans")
+		}
+		return err
 	})
 
 	if err := g.Wait(); err != nil {
@@ -293,6 +593,130 @@ func Start(c config.AppConfig, log *logger.Logger) {
 	}
 }
 
+// setRealtimeLimits overrides the realtime connection limits that are
+// configured
+func setRealtimeLimits(c config.AppConfig) {
+	if c.RealtimeKeepAlive != 0 {
+		realtime.KeepAlive = time.Duration(c.RealtimeKeepAlive) * time.Second
+	}
+	if c.RealtimeIdleTimeout > 0 {
+		realtime.IdleTimeout = time.Duration(c.RealtimeIdleTimeout) * time.Second
+	}
+	if c.RealtimeMessageRate > 0 {
+		realtime.MessagesPerSecond = int64(c.RealtimeMessageRate)
+	}
+	if c.RealtimeMaxMessageSize > 0 {
+		realtime.MaxMessageSize = int64(c.RealtimeMaxMessageSize)
+	}
+	realtime.Compression = c.RealtimeCompression
+	realtime.PlanCaps = c.RateLimit
+}
+
+// setBodyLimits overrides the request body limits that are configured
+func setBodyLimits(c config.AppConfig) {
+	sizes := map[string]int{
+		middleware.BodyDocuments: c.MaxDocumentSize,
+		middleware.BodyForms:     c.MaxFormSize,
+		middleware.BodyFunctions: c.MaxFunctionSize,
+		middleware.BodyUploads:   c.MaxUploadSize,
+	}
+	for class, size := range sizes {
+		if size > 0 {
+			middleware.BodyLimits[class] = int64(size)
+		}
+	}
+}
+
+// setFunctionLimits overrides the function run limits that are configured
+func setFunctionLimits(c config.AppConfig) {
+	if c.FunctionTimeout > 0 {
+		function.MaxDuration = time.Duration(c.FunctionTimeout) * time.Second
+	}
+	function.MaxOutput = c.FunctionMaxOutput
+	function.MaxDBCalls = c.FunctionMaxDBCalls
+}
+
+// setReloadable applies the rate limits and CORS origins that can change
+// while the server is running
+func setReloadable(c config.AppConfig) error {
+	if err := backend.SetRateLimits(c.RequestsPerMinute, c.RouteFactors); err != nil {
+		return err
+	}
+
+	middleware.SetCorsOrigins(c.CORSOrigins)
+	return nil
+}
+
+// reloadOnHangup reloads the configuration file on SIGHUP, an invalid file
+// keeps the current configuration
+func reloadOnHangup(c config.AppConfig, log *logger.Logger) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+
+	for range ch {
+		next, err := config.Reload(c)
+		if err != nil {
+			log.Error().Err(err).Msg("error reloading the configuration")
+			continue
+		}
+
+		if err := setReloadable(next); err != nil {
+			log.Error().Err(err).Msg("error reloading the configuration")
+			continue
+		}
+
+		c = next
+		log.Info().Str("file", c.ConfigFile).Msg("configuration reloaded")
+	}
+}
+
+// noDirListing prevents the file server from listing the files of a
+// directory
+// localFileServer serves the public files of the local storage directory,
+// private files are only downloadable via signed URLs
+func localFileServer(dir string) http.Handler {
+	fs := http.FileServer(http.Dir(dir))
+	return http.StripPrefix("/localfs/", noPrivateFiles(noDirListing(fileCaching(dir, precompressedFiles(dir, compressFiles(fs))))))
+}
+
+// noPrivateFiles responds with 404 for the private files
+func noPrivateFiles(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if storage.IsPrivate(path.Clean("/" + r.URL.Path)) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func noDirListing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) == 0 || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// fileCaching adds the Cache-Control and ETag headers of the served files,
+// the file server responds with 304 when the ETag matches
+func fileCaching(dir string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+		if fi, err := os.Stat(name); err != nil || fi.IsDir() {
+			// missing files are not cached by CDNs
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Cache-Control", backend.FileCacheControl(r.URL.Path))
+		w.Header().Set("ETag", backend.FileETag(r.URL.Path))
+		next.ServeHTTP(w, r)
+	})
+}
+
 func ping(w http.ResponseWriter, r *http.Request) {
 	if err := backend.DB.Ping(); err != nil {
 		http.Error(w, "connection failed to database, I'm down.", http.StatusInternalServerError)
@@ -301,6 +725,27 @@ func ping(w http.ResponseWriter, r *http.Request) {
 	respond(w, http.StatusOK, true)
 }
 
+// healthz is the liveness probe, the process is able to serve requests
+func healthz(w http.ResponseWriter, r *http.Request) {
+	status := map[string]string{"status": model.HealthStatusOK}
+	if len(config.Current.Region) > 0 {
+		status["region"] = config.Current.Region
+	}
+	respond(w, http.StatusOK, status)
+}
+
+// readyz is the readiness probe, it returns 503 when one of the
+// dependencies is not available with the status and latency of each check
+func readyz(w http.ResponseWriter, r *http.Request) {
+	report := backend.CheckReadiness()
+
+	status := http.StatusOK
+	if report.Status != model.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	respond(w, status, report)
+}
+
 func sudoCache(w http.ResponseWriter, r *http.Request) {
 	conf, _, err := middleware.Extract(r, false)
 	if err != nil {
@@ -390,6 +835,10 @@ func publishMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := cache.CountChannelMessage(backend.Cache, conf.Name, data.Channel); err != nil {
+		backend.Log.Error().Err(err).Msg("error counting channel message")
+	}
+
 	respond(w, http.StatusOK, true)
 }
 
@@ -0,0 +1,162 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/email"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/openapi"
+)
+
+func settings(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		respond(w, http.StatusOK, conf.Settings)
+		return
+	}
+
+	var s model.AppSettings
+	if err := parseBody(r.Body, &s); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if p := s.Files.Provider; len(p) > 0 && !backend.StorageProviderEnabled(p) {
+		http.Error(w, "storage provider not available: "+p, http.StatusBadRequest)
+		return
+	}
+
+	if len(s.Email.Provider) > 0 {
+		if _, err := email.NewMailer(s.Email); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, cs := range s.Schemas {
+		if err := openapi.ValidateSchema(cs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := validateCORS(s.CORS); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if rl := s.RateLimits; rl.Auth < 0 || rl.Write < 0 || rl.Read < 0 {
+		http.Error(w, "the rate limits cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateFunctionLimits(s.Functions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateIPAccess(s.IPAccess, middleware.ClientIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateWebhooks(s.Webhooks); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateFormWebhooks(s.Forms.Webhooks); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateSearch(s.Search); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := backend.DB.UpdateDatabaseSettings(conf.ID, s); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the cached config is used by the WithDB middleware
+	conf.Settings = s
+	if err := backend.Cache.SetTyped(conf.ID, conf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+// validateIPAccess checks the IP ranges and that the IP saving the settings
+// is still allowed
+func validateIPAccess(ipa model.IPAccessSettings, ip string) error {
+	switch ipa.Scope {
+	case "", model.IPAccessRoot, model.IPAccessAll:
+	default:
+		return fmt.Errorf("invalid IP access scope %s, expected root or all", ipa.Scope)
+	}
+
+	for _, r := range append(append([]string{}, ipa.Allow...), ipa.Deny...) {
+		if _, err := model.ParseIPRange(r); err != nil {
+			return err
+		}
+	}
+
+	if !ipa.Allows(ip) {
+		return fmt.Errorf("the IP access settings would block your IP address %s", ip)
+	}
+	return nil
+}
+
+// validateFunctionLimits checks that the function limits are not negative
+func validateFunctionLimits(fs model.FunctionSettings) error {
+	limits := map[string]model.FunctionLimits{"": fs.Limits}
+	for name, fl := range fs.Overrides {
+		limits[name] = fl
+	}
+
+	for name, fl := range limits {
+		if fl.Timeout < 0 || fl.MaxOutput < 0 || fl.MaxDBCalls < 0 {
+			if len(name) == 0 {
+				return fmt.Errorf("the function limits cannot be negative")
+			}
+			return fmt.Errorf("the limits of the function %s cannot be negative", name)
+		}
+	}
+
+	var hosts []string
+	hosts = append(hosts, fs.Fetch.AllowedHosts...)
+	hosts = append(hosts, fs.Fetch.DeniedHosts...)
+	for _, h := range hosts {
+		if len(strings.TrimSpace(h)) == 0 || strings.ContainsAny(h, "/: ") {
+			return fmt.Errorf("invalid fetch host %q, use a host name like api.example.com or *.example.com", h)
+		}
+	}
+	return nil
+}
+
+// validateSearch checks that the indexed collections are named once
+func validateSearch(ss model.SearchSettings) error {
+	seen := make(map[string]bool)
+	for _, sc := range ss.Collections {
+		if len(sc.Name) == 0 {
+			return fmt.Errorf("the indexed collections must have a name")
+		} else if seen[sc.Name] {
+			return fmt.Errorf("the collection %s is indexed more than once", sc.Name)
+		}
+		seen[sc.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package staticbackend
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+// appStats returns the usage overview of the database for the dashboard,
+// the months and days query string parameters set the length of the
+// monthly and daily series
+func appStats(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	months, ok := statsPeriods(qs.Get("months"), backend.StatsMonths, backend.MaxStatsMonths)
+	if !ok {
+		http.Error(w, "invalid months", http.StatusBadRequest)
+		return
+	}
+
+	days, ok := statsPeriods(qs.Get("days"), backend.StatsDays, backend.MaxStatsDays)
+	if !ok {
+		http.Error(w, "invalid days", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := backend.AppStats(auth, conf, months, days)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, stats)
+}
+
+// statsPeriods parses a number of periods between 1 and max, def when empty
+func statsPeriods(s string, def, max int) (int, bool) {
+	if len(s) == 0 {
+		return def, true
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > max {
+		return 0, false
+	}
+	return n, true
+}
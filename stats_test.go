@@ -0,0 +1,57 @@
+package staticbackend
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAppStats(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		resp := dbReq(t, db.add, "POST", "/db/stats_items", map[string]any{"n": i})
+		resp.Body.Close()
+		if resp.StatusCode > 299 {
+			t.Fatalf("expected the document to be created got status %d", resp.StatusCode)
+		}
+	}
+
+	login := dbReq(t, mship.login, "POST", "/login", model.Login{Email: userEmail, Password: userPassword})
+	login.Body.Close()
+	if login.StatusCode > 299 {
+		t.Fatalf("expected the login to succeed got status %d", login.StatusCode)
+	}
+
+	resp := dbReq(t, appStats, "GET", "/sudo/_/stats?months=3&days=7", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var stats model.AppStats
+	if err := parseBody(resp.Body, &stats); err != nil {
+		t.Fatal(err)
+	} else if n := stats.Documents["stats_items"]; n != 2 {
+		t.Errorf("expected 2 documents in stats_items got %d", n)
+	} else if stats.TotalDocuments < 2 {
+		t.Errorf("expected at least 2 documents in total got %d", stats.TotalDocuments)
+	} else if len(stats.FunctionRuns) != 3 || len(stats.EmailsSent) != 3 {
+		t.Errorf("expected 3 months of function runs and emails got %v", stats)
+	} else if len(stats.ActiveUsers) != 7 {
+		t.Errorf("expected 7 days of active users got %d", len(stats.ActiveUsers))
+	} else if stats.ActiveUsers[6].Value == 0 {
+		t.Error("expected the users who logged in today to be active")
+	} else if stats.Storage.MaxBytes == 0 {
+		t.Error("expected the storage plan quota to be returned")
+	}
+
+	for _, qs := range []string{"months=0", "months=abc", "days=1000"} {
+		resp := dbReq(t, appStats, "GET", "/sudo/_/stats?"+qs, nil, true)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400 got %d", qs, resp.StatusCode)
+		}
+	}
+}
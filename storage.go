@@ -1,10 +1,20 @@
 package staticbackend
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/staticbackendhq/core/backend"
 	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/storage"
 )
 
 func upload(w http.ResponseWriter, r *http.Request) {
@@ -17,6 +27,9 @@ func upload(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
+	} else if auth.Scope != nil && auth.Scope.ReadOnly {
+		http.Error(w, "read-only tokens cannot upload files", http.StatusForbidden)
+		return
 	}
 
 	file, h, err := r.FormFile("file")
@@ -27,28 +40,84 @@ func upload(w http.ResponseWriter, r *http.Request) {
 
 	// check for file size
 	// TODO: This should be based on current plan
-	if h.Size/(1000*1000) > 150 {
+	if h.Size > middleware.BodyLimits[middleware.BodyUploads] {
 		http.Error(w, "file size exeeded your limit", http.StatusBadRequest)
 		return
 	}
 
 	name := r.Form.Get("name")
 
+	var tags []string
+	for _, tag := range strings.Split(r.Form.Get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+
 	fileSvc := backend.Storage(auth, conf)
-	savedFile, err := fileSvc.Save(h.Filename, name, file, h.Size)
+
+	// private files are only downloadable via signed URLs
+	save := fileSvc.SaveWithTags
+	if private, _ := strconv.ParseBool(r.Form.Get("private")); private {
+		save = fileSvc.SavePrivate
+	}
+
+	savedFile, err := save(h.Filename, name, file, h.Size, tags)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		uploadError(w, err)
 		return
 	}
 
 	respond(w, http.StatusOK, savedFile)
 }
 
+func listFiles(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, size := getPagination(r.URL)
+
+	qs := r.URL.Query()
+	filter := model.FileFilter{
+		Name:     qs.Get("name"),
+		MimeType: qs.Get("type"),
+		Tag:      qs.Get("tag"),
+		Limit:    size,
+		Offset:   (page - 1) * size,
+	}
+
+	// root tokens can list the files of a specific account
+	if auth.Role == 100 {
+		filter.AccountID = qs.Get("accountId")
+	}
+
+	fileSvc := backend.Storage(auth, conf)
+	files, err := fileSvc.List(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if files == nil {
+		files = []model.File{}
+	}
+
+	// the total of files is unknown, the array is kept for compatibility
+	paginate(w, r, page, size, -1, len(files))
+	respond(w, http.StatusOK, files)
+}
+
 func deleteFile(w http.ResponseWriter, r *http.Request) {
 	conf, auth, err := middleware.Extract(r, true)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
+	} else if auth.Scope != nil && auth.Scope.ReadOnly {
+		http.Error(w, "read-only tokens cannot delete files", http.StatusForbidden)
+		return
 	}
 
 	fileID := r.URL.Query().Get("id")
@@ -59,5 +128,223 @@ func deleteFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordRootOperation(r, conf, auth, "deleted the file "+fileID)
+
 	respond(w, http.StatusOK, true)
 }
+
+// storageUsage returns the files and bytes stored by the database with its
+// plan quota
+func storageUsage(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	usage, err := backend.StorageUsage(conf)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, usage)
+}
+
+// linkFile ties a file to a document or a user, the file is deleted with
+// them
+func linkFile(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if auth.Scope != nil && auth.Scope.ReadOnly {
+		http.Error(w, "read-only tokens cannot link files", http.StatusForbidden)
+		return
+	}
+
+	var data = new(struct {
+		ID string `json:"id"`
+		model.FileLink
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fileSvc := backend.Storage(auth, conf)
+	if err := fileSvc.Link(data.ID, data.FileLink); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+func signedURL(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data = new(struct {
+		ID        string `json:"id"`
+		Variant   string `json:"variant"`
+		ExpiresIn int64  `json:"expiresIn"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fileSvc := backend.Storage(auth, conf)
+	url, expires, err := fileSvc.SignedURL(data.ID, data.Variant, time.Duration(data.ExpiresIn)*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	respond(w, http.StatusOK, struct {
+		URL     string    `json:"url"`
+		Expires time.Time `json:"expires"`
+	}{url, expires})
+}
+
+func presignUpload(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if auth.Scope != nil && auth.Scope.ReadOnly {
+		http.Error(w, "read-only tokens cannot upload files", http.StatusForbidden)
+		return
+	}
+
+	var data = new(struct {
+		Filename  string `json:"filename"`
+		Name      string `json:"name"`
+		Size      int64  `json:"size"`
+		ExpiresIn int64  `json:"expiresIn"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if len(data.Filename) == 0 {
+		http.Error(w, "the filename is required", http.StatusBadRequest)
+		return
+	}
+
+	fileSvc := backend.Storage(auth, conf)
+	pu, err := fileSvc.PresignUpload(data.Filename, data.Name, data.Size, time.Duration(data.ExpiresIn)*time.Second)
+	if errors.Is(err, backend.ErrPresignNotSupported) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	} else if err != nil {
+		uploadError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, pu)
+}
+
+func confirmUpload(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if auth.Scope != nil && auth.Scope.ReadOnly {
+		http.Error(w, "read-only tokens cannot upload files", http.StatusForbidden)
+		return
+	}
+
+	var data = new(struct {
+		Key       string   `json:"key"`
+		Signature string   `json:"signature"`
+		Filename  string   `json:"filename"`
+		Tags      []string `json:"tags"`
+	})
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fileSvc := backend.Storage(auth, conf)
+	savedFile, err := fileSvc.ConfirmUpload(data.Key, data.Signature, data.Filename, data.Tags)
+	if errors.Is(err, backend.ErrPresignNotSupported) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	} else if err != nil {
+		uploadError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, savedFile)
+}
+
+func download(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	fileKey := qs.Get("key")
+
+	if err := backend.VerifySignedURL(fileKey, qs.Get("expires"), qs.Get("sig")); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	// a custom domain only serves the files of its app
+	if d, ok := middleware.CustomDomain(r); ok {
+		if dbName, _, _ := strings.Cut(strings.TrimPrefix(fileKey, "/"), "/"); dbName != d.DBName {
+			http.Error(w, "file not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	// signed URLs can be cached by the browser until they expire, not by
+	// shared caches
+	expires, _ := strconv.ParseInt(qs.Get("expires"), 10, 64)
+	maxAge := expires - time.Now().Unix()
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
+
+	etag := backend.FileETag(fileKey)
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	file, err := storage.OpenSeeker(backend.Filestore, fileKey)
+	if errors.Is(err, storage.ErrNotSeekable) {
+		streamFile(w, fileKey)
+		return
+	} else if err != nil {
+		w.Header().Del("Cache-Control")
+		w.Header().Del("ETag")
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	// handles the Range requests used to stream audio and video
+	http.ServeContent(w, r, path.Base(fileKey), time.Time{}, file)
+}
+
+// streamFile sends the whole content of a file for storage providers
+// which cannot serve ranges
+func streamFile(w http.ResponseWriter, fileKey string) {
+	file, err := backend.Filestore.Open(fileKey)
+	if err != nil {
+		w.Header().Del("Cache-Control")
+		w.Header().Del("ETag")
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	if ct := mime.TypeByExtension(path.Ext(fileKey)); len(ct) > 0 {
+		w.Header().Set("Content-Type", ct)
+	}
+
+	if _, err := io.Copy(w, file); err != nil {
+		backend.Log.Error().Err(err).Msg("error sending file")
+	}
+}
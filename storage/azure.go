@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// azureVersion is the Blob service REST API version requests are signed for
+const azureVersion = "2020-10-02"
+
+// Azure stores files as block blobs in an Azure Blob Storage container.
+// Requests are authorized with the account's shared key.
+type Azure struct {
+	Account   string
+	Key       []byte
+	Container string
+	// Endpoint is the blob service URL, i.e. for Azurite
+	// http://127.0.0.1:10000/devstoreaccount1
+	Endpoint string
+
+	client *http.Client
+}
+
+// NewAzure returns an Azure provider for the container, the key is the
+// base64 encoded account access key
+func NewAzure(account, key, container, endpoint string) (Azure, error) {
+	if len(account) == 0 || len(container) == 0 {
+		return Azure{}, fmt.Errorf("the Azure storage account and container are required")
+	}
+
+	k, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return Azure{}, fmt.Errorf("invalid Azure storage key: %w", err)
+	}
+
+	if len(endpoint) == 0 {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	}
+
+	return Azure{
+		Account:   account,
+		Key:       k,
+		Container: container,
+		Endpoint:  strings.TrimSuffix(endpoint, "/"),
+		client:    &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// blobURL returns the URL of a blob, which is also its public URL
+func (a Azure) blobURL(fileKey string) string {
+	return fmt.Sprintf("%s/%s/%s", a.Endpoint, a.Container, escapeKey(fileKey))
+}
+
+func (a Azure) Save(data model.UploadFileData) (string, error) {
+	size, err := data.File.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", err
+	}
+	if _, err := data.File.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, a.blobURL(data.FileKey), data.File)
+	if err != nil {
+		return "", err
+	}
+
+	req.ContentLength = size
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	if ct := mime.TypeByExtension(path.Ext(data.FileKey)); len(ct) > 0 {
+		req.Header.Set("Content-Type", ct)
+	}
+	if len(data.CacheControl) > 0 {
+		req.Header.Set("x-ms-blob-cache-control", data.CacheControl)
+	}
+
+	resp, err := a.do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if data.Private {
+		return "", nil
+	}
+	return a.blobURL(data.FileKey), nil
+}
+
+// Open returns the blob content, the caller must close it
+func (a Azure) Open(fileKey string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, a.blobURL(fileKey), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// OpenAt returns the blob content from offset and the blob size
+func (a Azure) OpenAt(fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, a.blobURL(fileKey), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if offset > 0 {
+		req.Header.Set("x-ms-range", rangeHeader(offset))
+	}
+
+	resp, err := a.do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rangeResponse(resp, offset)
+}
+
+func (a Azure) Delete(fileKey string) error {
+	req, err := http.NewRequest(http.MethodDelete, a.blobURL(fileKey), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do signs and sends the request, returning an error for non 2xx responses
+func (a Azure) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureVersion)
+	req.Header.Set("Authorization", a.authorization(req))
+
+	client := a.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("azure storage error %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return resp, nil
+}
+
+// authorization returns the SharedKey Authorization header of a request
+// https://learn.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (a Azure) authorization(req *http.Request) string {
+	mac := hmac.New(sha256.New, a.Key)
+	mac.Write([]byte(a.stringToSign(req)))
+	return fmt.Sprintf("SharedKey %s:%s", a.Account, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+func (a Azure) stringToSign(req *http.Request) string {
+	length := ""
+	if req.ContentLength > 0 {
+		length = fmt.Sprintf("%d", req.ContentLength)
+	}
+
+	h := req.Header
+	parts := []string{
+		req.Method,
+		h.Get("Content-Encoding"),
+		h.Get("Content-Language"),
+		length,
+		h.Get("Content-MD5"),
+		h.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		h.Get("If-Modified-Since"),
+		h.Get("If-Match"),
+		h.Get("If-None-Match"),
+		h.Get("If-Unmodified-Since"),
+		h.Get("Range"),
+	}
+
+	var msHeaders []string
+	for k, v := range h {
+		if k = strings.ToLower(k); strings.HasPrefix(k, "x-ms-") {
+			msHeaders = append(msHeaders, k+":"+strings.TrimSpace(strings.Join(v, ",")))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(parts, "\n"))
+	sb.WriteString("\n")
+	for _, mh := range msHeaders {
+		sb.WriteString(mh)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("/" + a.Account + req.URL.EscapedPath())
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		sb.WriteString("\n" + strings.ToLower(k) + ":" + strings.Join(values, ","))
+	}
+	return sb.String()
+}
+
+// escapeKey escapes each segment of a file key for use in a URL path
+func escapeKey(fileKey string) string {
+	segments := strings.Split(strings.TrimPrefix(fileKey, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// fakeBlobs is a blob service verifying the SharedKey signatures
+func fakeBlobs(t *testing.T, a *Azure) *httptest.Server {
+	var mu sync.Mutex
+	blobs := make(map[string]string)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hmac.Equal([]byte(r.Header.Get("Authorization")), []byte(a.authorization(r))) {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+				http.Error(w, "missing blob type", http.StatusBadRequest)
+				return
+			}
+
+			b, _ := io.ReadAll(r.Body)
+			blobs[r.URL.Path] = string(b)
+			w.Header().Set("Cache-Control", r.Header.Get("x-ms-blob-cache-control"))
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			b, ok := blobs[r.URL.Path]
+			if !ok {
+				http.Error(w, "BlobNotFound", http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(b))
+		case http.MethodDelete:
+			delete(blobs, r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+}
+
+func TestAzure(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("secret"))
+
+	a, err := NewAzure("devstoreaccount1", key, "files", "http://localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := fakeBlobs(t, &a)
+	defer ts.Close()
+
+	a.Endpoint = ts.URL + "/devstoreaccount1"
+
+	data := model.UploadFileData{
+		FileKey:      "db/acct/my file.txt",
+		File:         strings.NewReader("azure content"),
+		CacheControl: "public, max-age=60",
+	}
+
+	url, err := a.Save(data)
+	if err != nil {
+		t.Fatal(err)
+	} else if url != ts.URL+"/devstoreaccount1/files/db/acct/my%20file.txt" {
+		t.Errorf("unexpected blob URL %s", url)
+	}
+
+	r, err := a.Open(data.FileKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(r)
+	r.Close()
+	if string(b) != "azure content" {
+		t.Errorf("expected azure content got %s", b)
+	}
+
+	if err := a.Delete(data.FileKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Open(data.FileKey); err == nil {
+		t.Error("expected an error opening a deleted blob")
+	}
+
+	// a wrong key is rejected by the service
+	other := a
+	other.Key = []byte("wrong")
+	if _, err := other.Save(data); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a 403 error got %v", err)
+	}
+}
+
+func TestAzureStringToSign(t *testing.T) {
+	a := Azure{Account: "acct"}
+
+	req := httptest.NewRequest(http.MethodGet, "http://acct.blob.core.windows.net/files/a.txt?comp=metadata", nil)
+	req.Header.Set("x-ms-date", "Fri, 26 Jun 2015 23:39:12 GMT")
+	req.Header.Set("x-ms-version", azureVersion)
+
+	expected := "GET\n\n\n\n\n\n\n\n\n\n\n\n" +
+		"x-ms-date:Fri, 26 Jun 2015 23:39:12 GMT\n" +
+		"x-ms-version:" + azureVersion + "\n" +
+		"/acct/files/a.txt\ncomp:metadata"
+
+	if s := a.stringToSign(req); s != expected {
+		t.Errorf("expected\n%q\ngot\n%q", expected, s)
+	}
+}
+
+func TestNewAzureRequiresContainer(t *testing.T) {
+	if _, err := NewAzure("acct", "", "", ""); err == nil {
+		t.Error("expected an error without container")
+	}
+}
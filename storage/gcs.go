@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/staticbackendhq/core/model"
+	"golang.org/x/oauth2/jwt"
+)
+
+// GCS stores files in a Google Cloud Storage bucket via the JSON API.
+// Requests are authorized with a service account.
+type GCS struct {
+	Bucket string
+	// Endpoint is the API URL, i.e. for an emulator
+	Endpoint string
+
+	client *http.Client
+}
+
+// NewGCS returns a GCS provider for the bucket from the service account JSON
+// file
+func NewGCS(bucket, credentialsFile, endpoint string) (GCS, error) {
+	if len(bucket) == 0 {
+		return GCS{}, errors.New("the GCS bucket is required")
+	}
+
+	b, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return GCS{}, err
+	}
+
+	var sa struct {
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(b, &sa); err != nil {
+		return GCS{}, fmt.Errorf("invalid GCS credentials: %w", err)
+	} else if len(sa.ClientEmail) == 0 || len(sa.PrivateKey) == 0 {
+		return GCS{}, errors.New("invalid GCS credentials: missing client_email or private_key")
+	}
+
+	if len(sa.TokenURI) == 0 {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	conf := &jwt.Config{
+		Email:        sa.ClientEmail,
+		PrivateKey:   []byte(sa.PrivateKey),
+		PrivateKeyID: sa.PrivateKeyID,
+		TokenURL:     sa.TokenURI,
+		Scopes:       []string{"https://www.googleapis.com/auth/devstorage.read_write"},
+	}
+
+	if len(endpoint) == 0 {
+		endpoint = "https://storage.googleapis.com"
+	}
+
+	return GCS{
+		Bucket:   bucket,
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   conf.Client(context.Background()),
+	}, nil
+}
+
+// objectURL returns the JSON API URL of an object
+func (g GCS) objectURL(fileKey string) string {
+	return fmt.Sprintf("%s/storage/v1/b/%s/o/%s", g.Endpoint, g.Bucket, url.PathEscape(fileKey))
+}
+
+// publicURL returns the public URL of an object
+func (g GCS) publicURL(fileKey string) string {
+	return fmt.Sprintf("%s/%s/%s", g.Endpoint, g.Bucket, escapeKey(fileKey))
+}
+
+// Save sends a multipart upload, the object metadata holding its content
+// type and Cache-Control
+func (g GCS) Save(data model.UploadFileData) (string, error) {
+	contentType := mime.TypeByExtension(path.Ext(data.FileKey))
+	if len(contentType) == 0 {
+		contentType = "application/octet-stream"
+	}
+
+	meta, err := json.Marshal(map[string]string{
+		"name":         data.FileKey,
+		"contentType":  contentType,
+		"cacheControl": data.CacheControl,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// the content is streamed to the request instead of buffered
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipart(mw, meta, contentType, data.File))
+	}()
+
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=multipart", g.Endpoint, g.Bucket)
+	req, err := http.NewRequest(http.MethodPost, u, pr)
+	if err != nil {
+		pr.Close()
+		return "", err
+	}
+	req.Header.Set("Content-Type", "multipart/related; boundary="+mw.Boundary())
+
+	resp, err := g.do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if data.Private {
+		return "", nil
+	}
+	return g.publicURL(data.FileKey), nil
+}
+
+// writeMultipart writes the metadata and media parts of a multipart upload
+func writeMultipart(mw *multipart.Writer, meta []byte, contentType string, file io.Reader) error {
+	part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=UTF-8"}})
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(meta); err != nil {
+		return err
+	}
+
+	part, err = mw.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+// Open returns the object content, the caller must close it
+func (g GCS) Open(fileKey string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, g.objectURL(fileKey)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// OpenAt returns the object content from offset and the object size
+func (g GCS) OpenAt(fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, g.objectURL(fileKey)+"?alt=media", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", rangeHeader(offset))
+	}
+
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rangeResponse(resp, offset)
+}
+
+func (g GCS) Delete(fileKey string) error {
+	req, err := http.NewRequest(http.MethodDelete, g.objectURL(fileKey), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := g.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends the request, returning an error for non 2xx responses
+func (g GCS) do(req *http.Request) (*http.Response, error) {
+	client := g.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("GCS error %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return resp, nil
+}
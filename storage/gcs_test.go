@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// fakeGCS implements the media upload, download and delete of the JSON API
+func fakeGCS(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	objects := make(map[string]string)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/sb/o" {
+			_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			mr := multipart.NewReader(r.Body, params["boundary"])
+			meta, err := mr.NextPart()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			b, _ := io.ReadAll(meta)
+			if !strings.Contains(string(b), `"cacheControl":"public, max-age=60"`) {
+				http.Error(w, "missing metadata", http.StatusBadRequest)
+				return
+			}
+
+			media, err := mr.NextPart()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			content, _ := io.ReadAll(media)
+			objects["db/acct/a.txt"] = string(content)
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		// the object name is a single escaped path segment
+		name := strings.TrimPrefix(r.URL.EscapedPath(), "/storage/v1/b/sb/o/")
+		if name != "db%2Facct%2Fa.txt" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			content, ok := objects["db/acct/a.txt"]
+			if !ok || r.URL.Query().Get("alt") != "media" {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+
+			if rng := r.Header.Get("Range"); len(rng) > 0 {
+				var offset int
+				fmt.Sscanf(rng, "bytes=%d-", &offset)
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				content = content[offset:]
+			}
+			w.Write([]byte(content))
+		case http.MethodDelete:
+			delete(objects, "db/acct/a.txt")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestGCS(t *testing.T) {
+	ts := fakeGCS(t)
+	defer ts.Close()
+
+	g := GCS{Bucket: "sb", Endpoint: ts.URL}
+
+	data := model.UploadFileData{
+		FileKey:      "db/acct/a.txt",
+		File:         strings.NewReader("gcs content"),
+		CacheControl: "public, max-age=60",
+	}
+
+	url, err := g.Save(data)
+	if err != nil {
+		t.Fatal(err)
+	} else if url != ts.URL+"/sb/db/acct/a.txt" {
+		t.Errorf("unexpected object URL %s", url)
+	}
+
+	r, err := g.Open(data.FileKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(r)
+	r.Close()
+	if string(b) != "gcs content" {
+		t.Errorf("expected gcs content got %s", b)
+	}
+
+	r, size, err := g.OpenAt(data.FileKey, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ = io.ReadAll(r)
+	r.Close()
+	if string(b) != "content" {
+		t.Errorf("expected content got %s", b)
+	} else if size != int64(len("gcs content")) {
+		t.Errorf("expected size %d got %d", len("gcs content"), size)
+	}
+
+	if err := g.Delete(data.FileKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.Open(data.FileKey); err == nil {
+		t.Error("expected an error opening a deleted object")
+	}
+}
@@ -1,38 +1,144 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/staticbackendhq/core/config"
 	"github.com/staticbackendhq/core/model"
 )
 
-type Local struct{}
+// ErrInvalidFileKey is returned for file keys resolving outside the root
+// directory
+var ErrInvalidFileKey = errors.New("invalid file key")
 
-func (Local) Save(data model.UploadFileData) (string, error) {
-	dir := path.Join(os.TempDir(), path.Dir(data.FileKey))
+// Local stores files on the local disk under Root, each database (tenant) in
+// its own directory since file keys are prefixed with the database name.
+// The OS temp directory is used when Root is empty.
+type Local struct {
+	Root string
+}
+
+// NewLocal returns a Local provider storing files under root, creating the
+// directory if needed
+func NewLocal(root string) (Local, error) {
+	if len(root) == 0 {
+		return Local{}, nil
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return Local{}, err
+	}
+	return Local{Root: root}, nil
+}
+
+func (l Local) root() string {
+	if len(l.Root) == 0 {
+		return os.TempDir()
+	}
+	return l.Root
+}
+
+// filename returns the path of a file key, making sure it cannot escape the
+// root directory
+func (l Local) filename(fileKey string) (string, error) {
+	root := filepath.Clean(l.root())
+	filename := filepath.Join(root, filepath.FromSlash(fileKey))
+
+	if filename == root || !strings.HasPrefix(filename, root+string(filepath.Separator)) {
+		return "", ErrInvalidFileKey
+	}
+	return filename, nil
+}
+
+// Save writes the file atomically: the content is written to a temporary
+// file in the destination directory and renamed once complete, so readers
+// never see a partial file.
+func (l Local) Save(data model.UploadFileData) (string, error) {
+	filename, err := l.filename(data.FileKey)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Dir(filename)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", err
 	}
 
-	b, err := io.ReadAll(data.File)
+	tmp, err := os.CreateTemp(dir, ".upload-*")
 	if err != nil {
 		return "", err
 	}
+	// removing the temp file fails once it's renamed, which is fine
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, data.File); err != nil {
+		tmp.Close()
+		return "", err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
 
-	filename := path.Join(os.TempDir(), data.FileKey)
-	if err := os.WriteFile(filename, b, 0644); err != nil {
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
 		return "", err
 	}
 
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return "", err
+	}
+
+	// private files are not served by /localfs/, see IsPrivate
+	if data.Private {
+		return "", nil
+	}
+
 	url := fmt.Sprintf("%s/localfs/%s", config.Current.LocalStorageURL, data.FileKey)
 	return url, nil
 }
 
-func (Local) Delete(fileKey string) error {
-	filename := path.Join(os.TempDir(), fileKey)
-	return os.Remove(filename)
+// Delete removes the file and its parent directories left empty
+func (l Local) Delete(fileKey string) error {
+	filename, err := l.filename(fileKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filename); err != nil {
+		return err
+	}
+
+	l.prune(filepath.Dir(filename))
+	return nil
+}
+
+// Open returns the file content, the caller must close it
+func (l Local) Open(fileKey string) (io.ReadCloser, error) {
+	filename, err := l.filename(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filename)
+}
+
+// prune removes empty directories from dir up to the root directory
+func (l Local) prune(dir string) {
+	root := filepath.Clean(l.root())
+	for dir != root && strings.HasPrefix(dir, root) {
+		// Remove fails on non-empty directories, which ends the cleanup
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
 }
@@ -3,6 +3,8 @@ package storage
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -24,3 +26,56 @@ func TestLocalSave(t *testing.T) {
 
 	fmt.Println(url)
 }
+
+func TestLocalSaveDeleteInRoot(t *testing.T) {
+	root := t.TempDir()
+
+	local, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := model.UploadFileData{FileKey: "db1/acct1/file.txt", File: strings.NewReader("unit test")}
+	if _, err := local.Save(data); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(root, "db1", "acct1", "file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(b) != "unit test" {
+		t.Errorf("expected unit test got %s", string(b))
+	}
+
+	// no temporary file should be left behind
+	entries, err := os.ReadDir(filepath.Join(root, "db1", "acct1"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 1 {
+		t.Errorf("expected 1 file got %d", len(entries))
+	}
+
+	if err := local.Delete(data.FileKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// empty tenant directories are removed
+	if _, err := os.Stat(filepath.Join(root, "db1")); !os.IsNotExist(err) {
+		t.Errorf("expected db1 directory to be removed, got %v", err)
+	} else if _, err := os.Stat(root); err != nil {
+		t.Errorf("expected root directory to be kept, got %v", err)
+	}
+}
+
+func TestLocalInvalidFileKey(t *testing.T) {
+	local := Local{Root: t.TempDir()}
+
+	data := model.UploadFileData{FileKey: "../outside.txt", File: strings.NewReader("x")}
+	if _, err := local.Save(data); err != ErrInvalidFileKey {
+		t.Errorf("expected ErrInvalidFileKey got %v", err)
+	}
+
+	if err := local.Delete("db1/../../outside.txt"); err != ErrInvalidFileKey {
+		t.Errorf("expected ErrInvalidFileKey got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// ErrPresignNotSupported is returned when the storage provider does not
+// accept direct uploads, i.e. the local storage
+var ErrPresignNotSupported = errors.New("the storage provider does not support direct uploads")
+
+// Router dispatches to the storage provider selected by the database owning
+// a file, file keys start with the database name. Databases without a
+// selected provider, or selecting one not in Providers, use Default.
+//
+// The provider storing a file is kept in its key, see StoreKey, so files
+// are still found after their database selects another provider.
+type Router struct {
+	Default Storer
+	// DefaultName is the provider name of Default
+	DefaultName string
+	Providers   map[string]Storer
+	// Provider returns the provider name selected by a database
+	Provider func(dbName string) string
+}
+
+// StoreKey returns the key a new file is saved under, the name of the
+// provider selected by its database is added after the database name
+func (r Router) StoreKey(fileKey string) string {
+	dbName, rest, ok := strings.Cut(strings.TrimPrefix(fileKey, "/"), "/")
+	if !ok {
+		return fileKey
+	}
+	return fmt.Sprintf("%s/%s/%s", dbName, r.providerName(dbName), rest)
+}
+
+// providerName returns the name of the provider storing the new files of a
+// database
+func (r Router) providerName(dbName string) string {
+	if r.Provider != nil {
+		name := strings.ToLower(r.Provider(dbName))
+		if _, ok := r.Providers[name]; ok {
+			return name
+		}
+	}
+	return r.DefaultName
+}
+
+// For returns the storage provider of a file key, keys without a provider
+// name, stored before it was kept in the key, use the provider currently
+// selected by their database
+func (r Router) For(fileKey string) Storer {
+	dbName, rest, _ := strings.Cut(strings.TrimPrefix(fileKey, "/"), "/")
+	if name, _, ok := strings.Cut(rest, "/"); ok && isProviderName(name) {
+		if name == r.DefaultName {
+			return r.Default
+		} else if s, ok := r.Providers[name]; ok {
+			return s
+		}
+		return r.Default
+	}
+
+	if r.Provider == nil {
+		return r.Default
+	}
+
+	if s, ok := r.Providers[strings.ToLower(r.Provider(dbName))]; ok {
+		return s
+	}
+	return r.Default
+}
+
+func isProviderName(name string) bool {
+	switch name {
+	case StorageProviderLocal, StorageProviderS3, StorageProviderAzure, StorageProviderGCS:
+		return true
+	}
+	return false
+}
+
+func (r Router) Save(data model.UploadFileData) (string, error) {
+	return r.For(data.FileKey).Save(data)
+}
+
+func (r Router) Delete(fileKey string) error {
+	return r.For(fileKey).Delete(fileKey)
+}
+
+func (r Router) Open(fileKey string) (io.ReadCloser, error) {
+	return r.For(fileKey).Open(fileKey)
+}
+
+func (r Router) PresignUpload(fileKey, contentType string, validity time.Duration) (string, http.Header, error) {
+	p, ok := r.For(fileKey).(Presigner)
+	if !ok {
+		return "", nil, ErrPresignNotSupported
+	}
+	return p.PresignUpload(fileKey, contentType, validity)
+}
+
+func (r Router) Stat(fileKey string) (int64, string, error) {
+	p, ok := r.For(fileKey).(Presigner)
+	if !ok {
+		return 0, "", ErrPresignNotSupported
+	}
+	return p.Stat(fileKey)
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestRouter(t *testing.T) {
+	def, other := Local{Root: t.TempDir()}, Local{Root: t.TempDir()}
+
+	r := Router{
+		Default:   def,
+		Providers: map[string]Storer{StorageProviderLocal: other},
+		Provider: func(dbName string) string {
+			if dbName == "tenant" {
+				return StorageProviderLocal
+			}
+			return StorageProviderGCS
+		},
+	}
+
+	for _, key := range []string{"tenant/acct/a.txt", "db/acct/a.txt"} {
+		data := model.UploadFileData{FileKey: key, File: strings.NewReader(key)}
+		if _, err := r.Save(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the database selecting a provider not enabled uses the default
+	if _, err := def.Open("db/acct/a.txt"); err != nil {
+		t.Errorf("expected the file in the default provider: %v", err)
+	}
+
+	f, err := other.Open("tenant/acct/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(f)
+	f.Close()
+	if string(b) != "tenant/acct/a.txt" {
+		t.Errorf("unexpected content %s", b)
+	}
+
+	if _, err := def.Open("tenant/acct/a.txt"); err == nil {
+		t.Error("expected the tenant file not to be in the default provider")
+	}
+
+	if _, _, err := r.PresignUpload("tenant/acct/b.txt", "text/plain", time.Minute); !errors.Is(err, ErrPresignNotSupported) {
+		t.Errorf("expected ErrPresignNotSupported got %v", err)
+	}
+}
+
+func TestRouterProviderSwitch(t *testing.T) {
+	def, other := Local{Root: t.TempDir()}, Local{Root: t.TempDir()}
+
+	selected := ""
+	r := Router{
+		Default:     def,
+		DefaultName: StorageProviderLocal,
+		Providers:   map[string]Storer{StorageProviderS3: other},
+		Provider:    func(dbName string) string { return selected },
+	}
+
+	oldKey := r.StoreKey("tenant/acct/old.txt")
+	if oldKey != "tenant/local/acct/old.txt" {
+		t.Fatalf("expected the provider in the key got %s", oldKey)
+	}
+
+	data := model.UploadFileData{FileKey: oldKey, File: strings.NewReader("old")}
+	if _, err := r.Save(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// the database switches provider, new files use it
+	selected = StorageProviderS3
+
+	newKey := r.StoreKey("tenant/acct/new.txt")
+	data = model.UploadFileData{FileKey: newKey, File: strings.NewReader("new")}
+	if _, err := r.Save(data); err != nil {
+		t.Fatal(err)
+	} else if _, err := other.Open(newKey); err != nil {
+		t.Errorf("expected the new file in the selected provider: %v", err)
+	}
+
+	// the old file is still read and deleted from its provider
+	f, err := r.Open(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(f)
+	f.Close()
+	if string(b) != "old" {
+		t.Errorf("unexpected content %s", b)
+	}
+
+	if err := r.Delete(oldKey); err != nil {
+		t.Fatal(err)
+	} else if _, err := def.Open(oldKey); err == nil {
+		t.Error("expected the old file to be deleted")
+	}
+}
@@ -2,6 +2,10 @@ package storage
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/staticbackendhq/core/config"
 	"github.com/staticbackendhq/core/model"
@@ -11,10 +15,55 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
+// defaultS3Region is used when neither AWS_S3_REGION nor AWS_REGION are set
+const defaultS3Region = "ca-central-1"
+
+// S3 stores files in an S3 bucket. Custom endpoints, path-style addressing
+// and region overrides allow S3-compatible services like MinIO, Cloudflare R2
+// and Backblaze B2.
 type S3 struct{}
 
+// s3Config returns the AWS config from the current app config
+func s3Config(c config.AppConfig) *aws.Config {
+	region := c.AWSS3Region
+	if len(region) == 0 {
+		region = c.AWSRegion
+	}
+	if len(region) == 0 {
+		region = defaultS3Region
+	}
+
+	cfg := &aws.Config{Region: aws.String(region)}
+	if len(c.AWSS3Endpoint) > 0 {
+		cfg.Endpoint = aws.String(c.AWSS3Endpoint)
+	}
+	if c.AWSS3ForcePathStyle {
+		cfg.S3ForcePathStyle = aws.Bool(true)
+	}
+	return cfg
+}
+
+// fileURL returns the public URL of a file, using the CDN URL if set or the
+// custom endpoint otherwise
+func fileURL(c config.AppConfig, fileKey string) string {
+	if len(c.AWSCDNURL) > 0 || len(c.AWSS3Endpoint) == 0 {
+		return fmt.Sprintf("%s/%s", c.AWSCDNURL, fileKey)
+	}
+
+	endpoint := strings.TrimSuffix(c.AWSS3Endpoint, "/")
+	if c.AWSS3ForcePathStyle {
+		return fmt.Sprintf("%s/%s/%s", endpoint, c.AWSS3Bucket, fileKey)
+	}
+
+	scheme, host, ok := strings.Cut(endpoint, "://")
+	if !ok {
+		return fmt.Sprintf("%s.%s/%s", c.AWSS3Bucket, endpoint, fileKey)
+	}
+	return fmt.Sprintf("%s://%s.%s/%s", scheme, c.AWSS3Bucket, host, fileKey)
+}
+
 func (S3) Save(data model.UploadFileData) (string, error) {
-	sess, err := session.NewSession(&aws.Config{Region: aws.String("ca-central-1")})
+	sess, err := session.NewSession(s3Config(config.Current))
 	if err != nil {
 		return "", err
 	}
@@ -22,25 +71,124 @@ func (S3) Save(data model.UploadFileData) (string, error) {
 	svc := s3.New(sess)
 	obj := &s3.PutObjectInput{}
 	obj.Body = data.File
-	obj.ACL = aws.String(s3.ObjectCannedACLPublicRead)
+	if data.Private {
+		obj.ACL = aws.String(s3.ObjectCannedACLPrivate)
+	} else if !config.Current.AWSS3NoACL {
+		obj.ACL = aws.String(s3.ObjectCannedACLPublicRead)
+	}
 	obj.Bucket = aws.String(config.Current.AWSS3Bucket)
 	obj.Key = aws.String(data.FileKey)
+	if len(data.CacheControl) > 0 {
+		obj.CacheControl = aws.String(data.CacheControl)
+	}
 
 	if _, err := svc.PutObject(obj); err != nil {
 		return "", err
 	}
 
-	url := fmt.Sprintf(
-		"%s/%s",
-		config.Current.AWSCDNURL,
-		data.FileKey,
-	)
+	if data.Private {
+		return "", nil
+	}
+	return fileURL(config.Current, data.FileKey), nil
+}
 
-	return url, nil
+// Open returns the object content, the caller must close it
+func (S3) Open(fileKey string) (io.ReadCloser, error) {
+	sess, err := session.NewSession(s3Config(config.Current))
+	if err != nil {
+		return nil, err
+	}
+
+	svc := s3.New(sess)
+	obj := &s3.GetObjectInput{
+		Bucket: aws.String(config.Current.AWSS3Bucket),
+		Key:    aws.String(fileKey),
+	}
+	out, err := svc.GetObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// OpenAt returns the object content from offset and the object size
+func (S3) OpenAt(fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	sess, err := session.NewSession(s3Config(config.Current))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	svc := s3.New(sess)
+	obj := &s3.GetObjectInput{
+		Bucket: aws.String(config.Current.AWSS3Bucket),
+		Key:    aws.String(fileKey),
+	}
+	if offset > 0 {
+		obj.Range = aws.String(rangeHeader(offset))
+	}
+
+	out, err := svc.GetObject(obj)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if offset == 0 {
+		return out.Body, aws.Int64Value(out.ContentLength), nil
+	}
+
+	size, err := parseContentRange(aws.StringValue(out.ContentRange))
+	if err != nil {
+		out.Body.Close()
+		return nil, 0, err
+	}
+	return out.Body, size, nil
+}
+
+// PresignUpload returns a presigned PUT URL for the file key. The returned
+// headers are part of the signature, i.e. the public-read ACL.
+func (S3) PresignUpload(fileKey, contentType string, validity time.Duration) (string, http.Header, error) {
+	sess, err := session.NewSession(s3Config(config.Current))
+	if err != nil {
+		return "", nil, err
+	}
+
+	svc := s3.New(sess)
+	obj := &s3.PutObjectInput{
+		Bucket: aws.String(config.Current.AWSS3Bucket),
+		Key:    aws.String(fileKey),
+	}
+	if len(contentType) > 0 {
+		obj.ContentType = aws.String(contentType)
+	}
+	if !config.Current.AWSS3NoACL {
+		obj.ACL = aws.String(s3.ObjectCannedACLPublicRead)
+	}
+
+	req, _ := svc.PutObjectRequest(obj)
+	return req.PresignRequest(validity)
+}
+
+// Stat returns the size and URL of an object
+func (S3) Stat(fileKey string) (int64, string, error) {
+	sess, err := session.NewSession(s3Config(config.Current))
+	if err != nil {
+		return 0, "", err
+	}
+
+	svc := s3.New(sess)
+	obj := &s3.HeadObjectInput{
+		Bucket: aws.String(config.Current.AWSS3Bucket),
+		Key:    aws.String(fileKey),
+	}
+	out, err := svc.HeadObject(obj)
+	if err != nil {
+		return 0, "", err
+	}
+	return aws.Int64Value(out.ContentLength), fileURL(config.Current, fileKey), nil
 }
 
 func (S3) Delete(fileKey string) error {
-	sess, err := session.NewSession(&aws.Config{Region: aws.String("ca-central-1")})
+	sess, err := session.NewSession(s3Config(config.Current))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/config"
+)
+
+func TestS3Config(t *testing.T) {
+	cfg := s3Config(config.AppConfig{})
+	if *cfg.Region != defaultS3Region {
+		t.Errorf("expected region %s got %s", defaultS3Region, *cfg.Region)
+	} else if cfg.Endpoint != nil {
+		t.Errorf("expected no endpoint got %s", *cfg.Endpoint)
+	}
+
+	cfg = s3Config(config.AppConfig{
+		AWSRegion:           "us-east-1",
+		AWSS3Region:         "auto",
+		AWSS3Endpoint:       "http://localhost:9000",
+		AWSS3ForcePathStyle: true,
+	})
+	if *cfg.Region != "auto" {
+		t.Errorf("expected region auto got %s", *cfg.Region)
+	} else if *cfg.Endpoint != "http://localhost:9000" {
+		t.Errorf("expected custom endpoint got %s", *cfg.Endpoint)
+	} else if !*cfg.S3ForcePathStyle {
+		t.Error("expected path-style addressing")
+	}
+}
+
+func TestS3FileURL(t *testing.T) {
+	tests := []struct {
+		conf     config.AppConfig
+		expected string
+	}{
+		{
+			config.AppConfig{AWSCDNURL: "https://cdn.test.com"},
+			"https://cdn.test.com/db/acct/a.txt",
+		},
+		{
+			config.AppConfig{AWSS3Bucket: "sb", AWSS3Endpoint: "http://localhost:9000/", AWSS3ForcePathStyle: true},
+			"http://localhost:9000/sb/db/acct/a.txt",
+		},
+		{
+			config.AppConfig{AWSS3Bucket: "sb", AWSS3Endpoint: "https://s3.us-west-004.backblazeb2.com"},
+			"https://sb.s3.us-west-004.backblazeb2.com/db/acct/a.txt",
+		},
+	}
+
+	for _, tc := range tests {
+		if url := fileURL(tc.conf, "db/acct/a.txt"); url != tc.expected {
+			t.Errorf("expected %s got %s", tc.expected, url)
+		}
+	}
+}
+
+func TestS3PresignUpload(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	cur := config.Current
+	defer func() { config.Current = cur }()
+
+	config.Current = config.AppConfig{
+		AWSS3Bucket:         "sb",
+		AWSS3Endpoint:       "http://localhost:9000",
+		AWSS3ForcePathStyle: true,
+	}
+
+	url, headers, err := S3{}.PresignUpload("db/acct/a.txt", "text/plain", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.HasPrefix(url, "http://localhost:9000/sb/db/acct/a.txt?") {
+		t.Errorf("unexpected presigned URL %s", url)
+	} else if !strings.Contains(url, "X-Amz-Signature=") {
+		t.Errorf("expected a signature in %s", url)
+	} else if strings.Join(headers["x-amz-acl"], "") != "public-read" {
+		t.Errorf("expected the ACL header got %v", headers)
+	}
+}
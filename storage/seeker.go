@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNotSeekable is returned by OpenSeeker for providers which can neither
+// seek nor read a file from an offset
+var ErrNotSeekable = errors.New("the storage provider cannot read files from an offset")
+
+// Ranger is implemented by storage providers able to read a file from an
+// offset, allowing HTTP range requests without downloading the whole file
+type Ranger interface {
+	// OpenAt returns the content of a file from offset and the file size
+	OpenAt(fileKey string, offset int64) (io.ReadCloser, int64, error)
+}
+
+// OpenSeeker returns the seekable content of a file, the caller must close
+// it. The content of Rangers is requested again from the new offset after a
+// seek.
+func OpenSeeker(s Storer, fileKey string) (io.ReadSeekCloser, error) {
+	if r, ok := s.(Router); ok {
+		s = r.For(fileKey)
+	}
+
+	if ranger, ok := s.(Ranger); ok {
+		body, size, err := ranger.OpenAt(fileKey, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &rangeSeeker{ranger: ranger, key: fileKey, body: body, size: size}, nil
+	}
+
+	rc, err := s.Open(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if rs, ok := rc.(io.ReadSeekCloser); ok {
+		return rs, nil
+	}
+
+	rc.Close()
+	return nil, ErrNotSeekable
+}
+
+// rangeSeeker reads a file from a Ranger, a seek closes the current content
+// which is opened again from the new offset on the next read
+type rangeSeeker struct {
+	ranger Ranger
+	key    string
+	body   io.ReadCloser
+	offset int64
+	size   int64
+}
+
+func (rs *rangeSeeker) Read(p []byte) (int, error) {
+	if rs.offset >= rs.size {
+		return 0, io.EOF
+	}
+
+	if rs.body == nil {
+		body, _, err := rs.ranger.OpenAt(rs.key, rs.offset)
+		if err != nil {
+			return 0, err
+		}
+		rs.body = body
+	}
+
+	n, err := rs.body.Read(p)
+	rs.offset += int64(n)
+	return n, err
+}
+
+func (rs *rangeSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += rs.offset
+	case io.SeekEnd:
+		offset += rs.size
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != rs.offset && rs.body != nil {
+		rs.body.Close()
+		rs.body = nil
+	}
+	rs.offset = offset
+	return offset, nil
+}
+
+func (rs *rangeSeeker) Close() error {
+	if rs.body == nil {
+		return nil
+	}
+	return rs.body.Close()
+}
+
+// rangeHeader returns the Range header reading from offset
+func rangeHeader(offset int64) string {
+	return fmt.Sprintf("bytes=%d-", offset)
+}
+
+// rangeResponse returns the body and the file size of a response to a
+// request reading from offset
+func rangeResponse(resp *http.Response, offset int64) (io.ReadCloser, int64, error) {
+	if offset == 0 {
+		return resp.Body, resp.ContentLength, nil
+	}
+
+	size, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		resp.Body.Close()
+		return nil, 0, err
+	}
+	return resp.Body, size, nil
+}
+
+// parseContentRange returns the complete length of a Content-Range header
+// like "bytes 200-1000/67589"
+func parseContentRange(contentRange string) (int64, error) {
+	_, size, ok := strings.Cut(contentRange, "/")
+	if !ok || size == "*" {
+		return 0, fmt.Errorf("invalid Content-Range %q", contentRange)
+	}
+	return strconv.ParseInt(size, 10, 64)
+}
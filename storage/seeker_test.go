@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+// memRanger counts the reads of its content
+type memRanger struct {
+	content string
+	opens   int
+}
+
+func (m *memRanger) OpenAt(fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	m.opens++
+	return io.NopCloser(strings.NewReader(m.content[offset:])), int64(len(m.content)), nil
+}
+
+func TestRangeSeeker(t *testing.T) {
+	m := &memRanger{content: "0123456789"}
+	body, size, _ := m.OpenAt("key", 0)
+	rs := &rangeSeeker{ranger: m, key: "key", body: body, size: size}
+
+	req := httptest.NewRequest("GET", "/key", nil)
+	req.Header.Set("Range", "bytes=4-6")
+
+	w := httptest.NewRecorder()
+	http.ServeContent(w, req, "key.txt", time.Time{}, rs)
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206 got %d", w.Code)
+	} else if w.Body.String() != "456" {
+		t.Errorf("expected 456 got %s", w.Body.String())
+	} else if m.opens != 2 {
+		t.Errorf("expected the content to be opened again at the offset, got %d opens", m.opens)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	if size, err := parseContentRange("bytes 200-1000/67589"); err != nil {
+		t.Fatal(err)
+	} else if size != 67589 {
+		t.Errorf("expected 67589 got %d", size)
+	}
+
+	if _, err := parseContentRange("bytes 0-10/*"); err == nil {
+		t.Error("expected an error for an unknown length")
+	}
+}
+
+func TestOpenSeekerLocal(t *testing.T) {
+	l := Local{Root: t.TempDir()}
+	if _, err := l.Save(model.UploadFileData{FileKey: "db/acct/a.txt", File: strings.NewReader("local")}); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := OpenSeeker(Router{Default: l}, "db/acct/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+
+	if _, err := rs.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(rs)
+	if string(b) != "cal" {
+		t.Errorf("expected cal got %s", b)
+	}
+}
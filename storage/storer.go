@@ -1,16 +1,61 @@
 package storage
 
-import "github.com/staticbackendhq/core/model"
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
 
 const (
 	StorageProviderLocal = "local"
 	StorageProviderS3    = "s3"
+	StorageProviderAzure = "azure"
+	StorageProviderGCS   = "gcs"
 )
 
+// PrivateDir is the directory of the private files in their account's
+// directory, they are only downloadable via signed URLs
+const PrivateDir = "_private"
+
+// IsPrivate returns true if the file key is in a PrivateDir directory
+func IsPrivate(fileKey string) bool {
+	for _, part := range strings.Split(fileKey, "/") {
+		if part == PrivateDir {
+			return true
+		}
+	}
+	return false
+}
+
+// StoreKey returns the key a new file is saved under with s, see
+// Router.StoreKey, other providers use the key as is
+func StoreKey(s Storer, fileKey string) string {
+	if r, ok := s.(Router); ok {
+		return r.StoreKey(fileKey)
+	}
+	return fileKey
+}
+
 // Storer handles file saving/deleting
 type Storer interface {
-	// Save saves a file via a storage provider
+	// Save saves a file via a storage provider and returns its public URL,
+	// which is empty for private files
 	Save(model.UploadFileData) (string, error)
 	// Delete removes a file via a storage provider
 	Delete(string) error
+	// Open returns the content of a file via a storage provider
+	Open(string) (io.ReadCloser, error)
+}
+
+// Presigner is implemented by storage providers accepting uploads sent
+// directly by clients without going through the server
+type Presigner interface {
+	// PresignUpload returns a URL accepting a PUT of the file content until
+	// validity elapses and the headers the client must send with it
+	PresignUpload(fileKey, contentType string, validity time.Duration) (string, http.Header, error)
+	// Stat returns the size and public URL of an uploaded file
+	Stat(fileKey string) (int64, string, error)
 }
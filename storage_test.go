@@ -6,8 +6,10 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -15,6 +17,7 @@ import (
 	"github.com/staticbackendhq/core/backend"
 	"github.com/staticbackendhq/core/internal"
 	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
 )
 
 func TestFileUpload(t *testing.T) {
@@ -99,3 +102,275 @@ func TestCleanUpFileName(t *testing.T) {
 		}
 	}
 }
+
+func TestSignedDownloadURL(t *testing.T) {
+	fileKey := fmt.Sprintf("%s/%s/signed_test.txt", dbName, testAccountID)
+
+	upData := model.UploadFileData{FileKey: fileKey, File: strings.NewReader("private content")}
+	fileURL, err := backend.Filestore.Save(upData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Filestore.Delete(fileKey)
+
+	f := model.File{
+		AccountID: testAccountID,
+		Key:       fileKey,
+		URL:       fileURL,
+		Size:      15,
+		Uploaded:  time.Now(),
+	}
+	fileID, err := backend.DB.AddFile(dbName, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]interface{}{"id": fileID, "expiresIn": 60}
+	resp := dbReq(t, signedURL, "POST", "/storage/sign", data)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var signed struct {
+		URL     string    `json:"url"`
+		Expires time.Time `json:"expires"`
+	}
+	if err := parseBody(resp.Body, &signed); err != nil {
+		t.Fatal(err)
+	} else if time.Until(signed.Expires) > time.Minute {
+		t.Errorf("expected the URL to expire within a minute got %v", signed.Expires)
+	}
+
+	u, err := url.Parse(signed.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	download(w, httptest.NewRequest("GET", "/storage/download?"+u.RawQuery, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	} else if w.Body.String() != "private content" {
+		t.Errorf("expected file content got %s", w.Body.String())
+	}
+
+	etag := w.Header().Get("ETag")
+	if len(etag) == 0 {
+		t.Fatal("expected an ETag header")
+	} else if !strings.HasPrefix(w.Header().Get("Cache-Control"), "private") {
+		t.Errorf("expected a private Cache-Control got %s", w.Header().Get("Cache-Control"))
+	}
+
+	req := httptest.NewRequest("GET", "/storage/download?"+u.RawQuery, nil)
+	req.Header.Set("If-None-Match", etag)
+
+	w = httptest.NewRecorder()
+	download(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/storage/download?"+u.RawQuery, nil)
+	req.Header.Set("Range", "bytes=8-")
+
+	w = httptest.NewRecorder()
+	download(w, req)
+	if w.Code != http.StatusPartialContent {
+		t.Errorf("expected 206 got %d", w.Code)
+	} else if w.Body.String() != "content" {
+		t.Errorf("expected the requested range got %s", w.Body.String())
+	} else if cr := w.Header().Get("Content-Range"); cr != "bytes 8-14/15" {
+		t.Errorf("expected Content-Range bytes 8-14/15 got %s", cr)
+	}
+
+	// a tampered key must be rejected
+	qs := u.Query()
+	qs.Set("key", fmt.Sprintf("%s/%s/other.txt", dbName, testAccountID))
+
+	w = httptest.NewRecorder()
+	download(w, httptest.NewRequest("GET", "/storage/download?"+qs.Encode(), nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 got %d", w.Code)
+	}
+
+	// so does a changed expiration
+	qs = u.Query()
+	qs.Set("expires", fmt.Sprintf("%d", time.Now().Add(-time.Minute).Unix()))
+
+	w = httptest.NewRecorder()
+	download(w, httptest.NewRequest("GET", "/storage/download?"+qs.Encode(), nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 got %d", w.Code)
+	}
+}
+
+func TestPrivateFileUpload(t *testing.T) {
+	body := new(strings.Builder)
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", "private.txt")
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := part.Write([]byte("private upload")); err != nil {
+		t.Fatal(err)
+	} else if err := writer.WriteField("private", "true"); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/storage/upload", strings.NewReader(body.String()))
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	req.Header.Set("SB-PUBLIC-KEY", pubKey)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+
+	stdAuth := []middleware.Middleware{
+		middleware.WithDB(backend.DB, backend.Cache, getStripePortalURL),
+		middleware.RequireAuth(backend.DB, backend.Cache),
+	}
+
+	w := httptest.NewRecorder()
+	middleware.Chain(http.HandlerFunc(upload), stdAuth...).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	}
+
+	var data backend.SavedFile
+	if err := parseBody(w.Result().Body, &data); err != nil {
+		t.Fatal(err)
+	}
+	defer dbReq(t, deleteFile, "DELETE", "/sudostorage/delete?id="+data.ID, nil)
+
+	// only the signed URL is returned
+	u, err := url.Parse(data.URL)
+	if err != nil {
+		t.Fatal(err)
+	} else if u.Path != "/storage/download" {
+		t.Fatalf("expected a signed download URL got %s", data.URL)
+	}
+
+	file, err := backend.DB.GetFileByID(dbName, data.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(file.URL) > 0 {
+		t.Errorf("expected no public URL got %s", file.URL)
+	}
+
+	// the raw path is not served by the local file server
+	for _, p := range []string{
+		"/localfs/" + file.Key,
+		"/localfs/" + strings.Replace(file.Key, "/_private/", "/x/../_private/", 1),
+	} {
+		w = httptest.NewRecorder()
+		localFileServer(os.TempDir()).ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+		if w.Code != http.StatusNotFound && w.Code != http.StatusForbidden {
+			t.Errorf("expected %s not to be served got %d", p, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	download(w, httptest.NewRequest("GET", "/storage/download?"+u.RawQuery, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	} else if w.Body.String() != "private upload" {
+		t.Errorf("expected file content got %s", w.Body.String())
+	}
+}
+
+func TestListFilesEndpoint(t *testing.T) {
+	f := model.File{
+		AccountID: testAccountID,
+		Key:       fmt.Sprintf("%s/%s/endpoint_test.png", dbName, testAccountID),
+		URL:       "https://test/endpoint_test.png",
+		Uploaded:  time.Now(),
+		Name:      "endpoint_test.png",
+		MimeType:  "image/png",
+		Tags:      []string{"endpoint"},
+	}
+	fileID, err := backend.DB.AddFile(dbName, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteFile(dbName, fileID)
+
+	resp := dbReq(t, listFiles, "GET", "/storage/files?tag=endpoint&type=image/", nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var files []model.File
+	if err := parseBody(resp.Body, &files); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 || files[0].ID != fileID {
+		t.Errorf("expected file %s got %v", fileID, files)
+	}
+}
+
+func TestLocalFileCaching(t *testing.T) {
+	dir := t.TempDir()
+	fileKey := fmt.Sprintf("%s/%s/cached.txt", dbName, testAccountID)
+
+	if err := os.MkdirAll(filepath.Join(dir, dbName, testAccountID), 0755); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(fileKey)), []byte("cached"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := fileCaching(dir, http.FileServer(http.Dir(dir)))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/"+fileKey, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	} else if cc := w.Header().Get("Cache-Control"); cc != backend.DefaultFileCacheControl {
+		t.Errorf("expected %s got %s", backend.DefaultFileCacheControl, cc)
+	}
+
+	req := httptest.NewRequest("GET", "/"+fileKey, nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/"+dbName+"/missing.txt", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 got %d", w.Code)
+	} else if len(w.Header().Get("Cache-Control")) > 0 {
+		t.Error("expected missing files not to be cached")
+	}
+}
+
+func TestStorageUsageEndpoint(t *testing.T) {
+	resp := dbReq(t, storageUsage, "GET", "/sudo/_/storage-usage", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var usage model.StorageUsage
+	if err := parseBody(resp.Body, &usage); err != nil {
+		t.Fatal(err)
+	} else if usage.MaxBytes == 0 {
+		t.Error("expected the plan quota to be returned")
+	}
+}
+
+func TestStorageProviderSetting(t *testing.T) {
+	s := model.AppSettings{Files: model.FileSettings{Provider: "azure"}}
+
+	// the instance does not enable additional storage providers
+	resp := dbReq(t, settings, "POST", "/account/settings", s, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 got %d", resp.StatusCode)
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/staticbackendhq/core/backend"
@@ -64,6 +64,15 @@ func (wh *stripeWebhook) process(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		go wh.handleSubCancelled(sub)
+	} else if event.Type == "checkout.session.completed" {
+		var cs stripe.CheckoutSession
+		if err := json.Unmarshal(event.Data.Raw, &cs); err != nil {
+			wh.log.Error().Err(err).Msg("STRIPE ERROR (checkout json)")
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		go wh.handleCheckoutCompleted(cs)
 	} else if event.Type == "payment_method.attached" {
 		var paymentMethod stripe.PaymentMethod
 		err := json.Unmarshal(event.Data.Raw, &paymentMethod)
@@ -74,7 +83,7 @@ func (wh *stripeWebhook) process(w http.ResponseWriter, r *http.Request) {
 		}
 		go wh.handlePaymentMethodAttached(paymentMethod)
 	} else {
-		log.Printf("received unhandled Stripe webhook: %s\n", event.Type)
+		wh.log.Info().Str("type", string(event.Type)).Msg("received unhandled Stripe webhook")
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -100,13 +109,38 @@ func (wh *stripeWebhook) handleSubChanged(sub stripe.Subscription) {
 		priceID := sub.Items.Data[0].Price.ID
 		newLevel := wh.priceToLevel(priceID)
 
-		if err := backend.DB.ChangeTenantPlan(cus.ID, newLevel); err != nil {
+		if err := backend.ChangePlan(cus.ID, newLevel); err != nil {
 			wh.log.Error().Err(err).Msg("STRIPE ERROR (update cus plan)")
 			return
 		}
 	}
 }
 
+// handleCheckoutCompleted records the subscription of a checkout session
+// started by subscribePlan and changes the tenant's plan
+func (wh *stripeWebhook) handleCheckoutCompleted(cs stripe.CheckoutSession) {
+	if cs.Subscription == nil || len(cs.ClientReferenceID) == 0 {
+		wh.log.Info().Msgf("[Checkout]: session %s without subscription or tenant", cs.ID)
+		return
+	}
+
+	plan, err := strconv.Atoi(cs.Metadata["plan"])
+	if err != nil {
+		wh.log.Error().Err(err).Msgf("STRIPE ERROR (checkout %s plan)", cs.ID)
+		return
+	}
+
+	tenantID := cs.ClientReferenceID
+	if err := backend.DB.SetTenantSubscription(tenantID, cs.Subscription.ID); err != nil {
+		wh.log.Error().Err(err).Msg("STRIPE ERROR (update cus subscription)")
+		return
+	}
+
+	if err := backend.ChangePlan(tenantID, plan); err != nil {
+		wh.log.Error().Err(err).Msg("STRIPE ERROR (update cus plan)")
+	}
+}
+
 func (wh *stripeWebhook) handleSubCancelled(sub stripe.Subscription) {
 	// To prevent from the customer.subscription.updated events
 	time.Sleep(15 * time.Second)
@@ -119,7 +153,7 @@ func (wh *stripeWebhook) handleSubCancelled(sub stripe.Subscription) {
 		return
 	}
 
-	if err := backend.DB.ChangeTenantPlan(cus.ID, model.PlanIdea); err != nil {
+	if err := backend.ChangePlan(cus.ID, model.PlanIdea); err != nil {
 		wh.log.Error().Err(err).Msg("STRIPE ERROR (update cus plan)")
 	}
 }
@@ -142,17 +176,11 @@ func (wh *stripeWebhook) handlePaymentMethodAttached(pm stripe.PaymentMethod) {
 	}
 }
 
+// priceToLevel returns the plan of a Stripe price, the Idea plan when it's
+// not one of the plans
 func (wh *stripeWebhook) priceToLevel(priceID string) int {
-	switch priceID {
-	case config.Current.StripePriceIDIdea:
-		return model.PlanIdea
-	case config.Current.StripePriceIDLaunch:
-		return model.PleanLaunch
-	case config.Current.StripePriceIDTraction:
-		return model.PlanTraction
-	case config.Current.StripePriceIDGrowth:
-		return model.PlanGrowth
-	default:
-		return model.PlanIdea
+	if plan, ok := backend.PlanByPrice(priceID); ok {
+		return plan.ID
 	}
+	return model.PlanIdea
 }
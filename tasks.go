@@ -0,0 +1,283 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/function"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// tasks handles the scheduled tasks of a database, GET /task lists them
+// with the status of their last run and POST /task creates a task running
+// on an interval or once at a given time
+func tasks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		listTasks(w, r)
+	case http.MethodPost:
+		addTask(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// taskActions dispatches the /task/{id} and /task/{id}/{action} requests
+func taskActions(w http.ResponseWriter, r *http.Request) {
+	if len(getURLPart(r.URL.Path, 2)) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch getURLPart(r.URL.Path, 3) {
+	case "":
+		task(w, r)
+	case "runs":
+		listTaskRuns(w, r)
+	case "pause":
+		setTaskEnabled(w, r, false)
+	case "resume":
+		setTaskEnabled(w, r, true)
+	case "run":
+		runTask(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func listTasks(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	results, err := backend.DB.ListTasksByBase(conf.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	for i, task := range results {
+		results[i].NextRun, _ = function.NextRun(task, now)
+	}
+
+	if results == nil {
+		results = make([]model.Task, 0)
+	}
+
+	respond(w, http.StatusOK, results)
+}
+
+func addTask(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// tasks are enabled unless specified
+	task := model.Task{Enabled: true}
+	if err := parseBody(r.Body, &task); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task, err = backend.AddTask(conf, task)
+	if errors.Is(err, backend.ErrInvalidTask) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusCreated, task)
+}
+
+// task handles a single task, GET /task/{id} returns it, PUT /task/{id}
+// replaces its definition and DELETE /task/{id} removes it with its runs
+func task(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		getTask(w, r)
+	case http.MethodPut:
+		updateTask(w, r)
+	case http.MethodDelete:
+		deleteTask(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func getTask(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	task, err := backend.DB.GetTaskByID(conf.Name, getURLPart(r.URL.Path, 2))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	task.NextRun, _ = function.NextRun(task, time.Now())
+
+	respond(w, http.StatusOK, task)
+}
+
+func updateTask(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 2)
+
+	// the fields not specified keep their current value
+	task, err := backend.DB.GetTaskByID(conf.Name, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := parseBody(r.Body, &task); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task, err = backend.UpdateTask(conf, id, task)
+	if errors.Is(err, backend.ErrInvalidTask) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	task.NextRun, _ = function.NextRun(task, time.Now())
+
+	respond(w, http.StatusOK, task)
+}
+
+func deleteTask(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 2)
+	if _, err := backend.DB.GetTaskByID(conf.Name, id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = backend.DeleteTask(conf, id)
+	if errors.Is(err, backend.ErrInvalidTask) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+// listTaskRuns returns the most recent runs of a task with their outcome,
+// GET /task/{id}/runs?limit=50
+func listTaskRuns(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 2)
+
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); len(v) > 0 {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	results, err := backend.DB.ListTaskRuns(conf.Name, id, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if results == nil {
+		results = make([]model.TaskRun, 0)
+	}
+
+	respond(w, http.StatusOK, results)
+}
+
+// setTaskEnabled pauses or resumes a task, POST /task/{id}/pause and
+// POST /task/{id}/resume
+func setTaskEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	task, err := backend.SetTaskEnabled(conf, getURLPart(r.URL.Path, 2), enabled)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, task)
+}
+
+// runTask executes a task immediately regardless of its schedule and returns
+// the ID of its first run, POST /task/{id}/run
+func runTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id := getURLPart(r.URL.Path, 2)
+	if _, err := backend.DB.GetTaskByID(conf.Name, id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	runID, err := backend.RunTask(conf, id)
+	if errors.Is(err, function.ErrTaskRunning) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, map[string]string{"runId": runID})
+}
@@ -0,0 +1,278 @@
+package staticbackend
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestAddOneOffTask(t *testing.T) {
+	task := model.Task{
+		Name:  "deferred-message",
+		Type:  model.TaskTypeMessage,
+		Value: "deferred",
+		RunAt: time.Now().Add(time.Hour),
+	}
+
+	resp := dbReq(t, tasks, "POST", "/task", task, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var created model.Task
+	if err := parseBody(resp.Body, &created); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteTask(dbName, created.ID)
+
+	if len(created.ID) == 0 || !created.IsOneOff() {
+		t.Errorf("expected a saved one-off task got %v", created)
+	}
+}
+
+func TestAddTaskInvalid(t *testing.T) {
+	task := model.Task{
+		Name:  "no-schedule",
+		Type:  model.TaskTypeMessage,
+		Value: "deferred",
+	}
+
+	resp := dbReq(t, tasks, "POST", "/task", task, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 got %d", resp.StatusCode)
+	}
+}
+
+func TestListTaskRuns(t *testing.T) {
+	task := model.Task{
+		Name:     "listed-runs",
+		Type:     model.TaskTypeMessage,
+		Value:    "listed",
+		Interval: "@daily",
+	}
+
+	id, err := backend.DB.AddTask(dbName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteTask(dbName, id)
+
+	run := model.TaskRun{TaskID: id, Attempt: 1, Started: time.Now(), Completed: time.Now(), Success: true}
+	if _, err := backend.DB.AddTaskRun(dbName, run); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := dbReq(t, taskActions, "GET", "/task/"+id+"/runs", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var runs []model.TaskRun
+	if err := parseBody(resp.Body, &runs); err != nil {
+		t.Fatal(err)
+	} else if len(runs) != 1 || runs[0].TaskID != id {
+		t.Errorf("expected the task run got %v", runs)
+	}
+}
+
+func TestPauseResumeTask(t *testing.T) {
+	task := model.Task{
+		Name:     "paused-task",
+		Type:     model.TaskTypeMessage,
+		Value:    "paused",
+		Interval: "@daily",
+		Enabled:  true,
+	}
+
+	id, err := backend.DB.AddTask(dbName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteTask(dbName, id)
+
+	resp := dbReq(t, taskActions, "POST", "/task/"+id+"/pause", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	if paused, err := backend.DB.GetTaskByID(dbName, id); err != nil {
+		t.Fatal(err)
+	} else if paused.Enabled {
+		t.Fatal("expected the task to be paused")
+	}
+
+	resp2 := dbReq(t, taskActions, "POST", "/task/"+id+"/resume", nil, true)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp2))
+	}
+
+	if resumed, err := backend.DB.GetTaskByID(dbName, id); err != nil {
+		t.Fatal(err)
+	} else if !resumed.Enabled {
+		t.Error("expected the task to be resumed")
+	}
+}
+
+func TestAddDependentTask(t *testing.T) {
+	prerequisite := model.Task{
+		Name:     "prerequisite",
+		Type:     model.TaskTypeMessage,
+		Value:    "first",
+		Interval: "@daily",
+	}
+
+	resp := dbReq(t, tasks, "POST", "/task", prerequisite, true)
+	defer resp.Body.Close()
+
+	var created model.Task
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &created); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteTask(dbName, created.ID)
+
+	dependent := model.Task{
+		Name:  "dependent",
+		Type:  model.TaskTypeMessage,
+		Value: "second",
+		After: created.ID,
+	}
+
+	resp2 := dbReq(t, tasks, "POST", "/task", dependent, true)
+	defer resp2.Body.Close()
+
+	var dep model.Task
+	if resp2.StatusCode != http.StatusCreated {
+		t.Fatal(GetResponseBody(t, resp2))
+	} else if err := parseBody(resp2.Body, &dep); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteTask(dbName, dep.ID)
+
+	dependent.Name = "orphan"
+	dependent.After = "not-a-task"
+
+	resp3 := dbReq(t, tasks, "POST", "/task", dependent, true)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown prerequisite got %d", resp3.StatusCode)
+	}
+}
+
+func TestUpdateDeleteTask(t *testing.T) {
+	task := model.Task{
+		Name:     "crud-task",
+		Type:     model.TaskTypeMessage,
+		Value:    "crud",
+		Interval: "@daily",
+		Enabled:  true,
+	}
+
+	id, err := backend.DB.AddTask(dbName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteTask(dbName, id)
+
+	update := map[string]any{"interval": "@hourly", "value": "updated"}
+	resp := dbReq(t, taskActions, "PUT", "/task/"+id, update, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	resp2 := dbReq(t, taskActions, "GET", "/task/"+id, nil, true)
+	defer resp2.Body.Close()
+
+	var updated model.Task
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp2))
+	} else if err := parseBody(resp2.Body, &updated); err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.Interval != "@hourly" || updated.Value != "updated" || updated.Name != task.Name {
+		t.Errorf("unexpected updated task %v", updated)
+	} else if updated.NextRun.IsZero() {
+		t.Error("expected the next run of the updated task")
+	}
+
+	invalid := map[string]any{"type": model.TaskTypeHTTP, "value": "not a url"}
+	resp3 := dbReq(t, taskActions, "PUT", "/task/"+id, invalid, true)
+	defer resp3.Body.Close()
+
+	if resp3.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid URL got %d", resp3.StatusCode)
+	}
+
+	resp4 := dbReq(t, taskActions, "DELETE", "/task/"+id, nil, true)
+	defer resp4.Body.Close()
+
+	if resp4.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp4))
+	}
+
+	if _, err := backend.DB.GetTaskByID(dbName, id); err == nil {
+		t.Error("expected the task to be deleted")
+	}
+
+	resp5 := dbReq(t, taskActions, "GET", "/task/"+id, nil, true)
+	defer resp5.Body.Close()
+
+	if resp5.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for a deleted task got %d", resp5.StatusCode)
+	}
+}
+
+func TestRunTaskNow(t *testing.T) {
+	task := model.Task{
+		Name:     "run-now",
+		Type:     model.TaskTypeMessage,
+		Value:    "run-now",
+		Interval: "@yearly",
+		Enabled:  true,
+	}
+
+	id, err := backend.DB.AddTask(dbName, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteTask(dbName, id)
+
+	resp := dbReq(t, taskActions, "POST", "/task/"+id+"/run", nil, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var result map[string]string
+	if err := parseBody(resp.Body, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := backend.DB.ListTaskRuns(dbName, id, 1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(runs) != 1 || runs[0].ID != result["runId"] {
+		t.Fatalf("expected the run %s got %v", result["runId"], runs)
+	} else if !runs[0].Success {
+		t.Errorf("expected a successful run got %v", runs[0])
+	}
+}
@@ -0,0 +1,1184 @@
+// Code generated by persister_gen.go; DO NOT EDIT.
+
+package tracing
+
+import (
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func (tp persister) Ping() error {
+	span := startPersister("Ping", "")
+	err := tp.Persister.Ping()
+	End(span, err)
+	return err
+}
+
+func (tp persister) CreateIndex(dbName string, col string, field string) error {
+	span := startPersister("CreateIndex", dbName)
+	err := tp.Persister.CreateIndex(dbName, col, field)
+	End(span, err)
+	return err
+}
+
+func (tp persister) CreateTenant(p0 model.Tenant) (model.Tenant, error) {
+	span := startPersister("CreateTenant", "")
+	r0, err := tp.Persister.CreateTenant(p0)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) CreateDatabase(p0 model.DatabaseConfig) (model.DatabaseConfig, error) {
+	span := startPersister("CreateDatabase", "")
+	r0, err := tp.Persister.CreateDatabase(p0)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) EmailExists(email string) (bool, error) {
+	span := startPersister("EmailExists", "")
+	r0, err := tp.Persister.EmailExists(email)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) FindTenant(tenantID string) (model.Tenant, error) {
+	span := startPersister("FindTenant", "")
+	r0, err := tp.Persister.FindTenant(tenantID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) FindDatabase(baseID string) (model.DatabaseConfig, error) {
+	span := startPersister("FindDatabase", "")
+	r0, err := tp.Persister.FindDatabase(baseID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DatabaseExists(name string) (bool, error) {
+	span := startPersister("DatabaseExists", "")
+	r0, err := tp.Persister.DatabaseExists(name)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListDatabases() ([]model.DatabaseConfig, error) {
+	span := startPersister("ListDatabases", "")
+	r0, err := tp.Persister.ListDatabases()
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) IncrementMonthlyEmailSent(baseID string) error {
+	span := startPersister("IncrementMonthlyEmailSent", "")
+	err := tp.Persister.IncrementMonthlyEmailSent(baseID)
+	End(span, err)
+	return err
+}
+
+func (tp persister) IncrementEmailUsage(tenantID string, month string) (int, error) {
+	span := startPersister("IncrementEmailUsage", "")
+	r0, err := tp.Persister.IncrementEmailUsage(tenantID, month)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetEmailUsage(tenantID string, month string) (int, error) {
+	span := startPersister("GetEmailUsage", "")
+	r0, err := tp.Persister.GetEmailUsage(tenantID, month)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddUsage(records []model.UsageRecord) error {
+	span := startPersister("AddUsage", "")
+	err := tp.Persister.AddUsage(records)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListUsage(filter model.UsageFilter) ([]model.UsageRecord, error) {
+	span := startPersister("ListUsage", "")
+	r0, err := tp.Persister.ListUsage(filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UpdateDatabaseSettings(baseID string, settings model.AppSettings) error {
+	span := startPersister("UpdateDatabaseSettings", "")
+	err := tp.Persister.UpdateDatabaseSettings(baseID, settings)
+	End(span, err)
+	return err
+}
+
+func (tp persister) SuspendDatabase(baseID string, reason string) error {
+	span := startPersister("SuspendDatabase", "")
+	err := tp.Persister.SuspendDatabase(baseID, reason)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListEnvironments(parentID string) ([]model.DatabaseConfig, error) {
+	span := startPersister("ListEnvironments", "")
+	r0, err := tp.Persister.ListEnvironments(parentID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetTenantByEmail(email string) (model.Tenant, error) {
+	span := startPersister("GetTenantByEmail", "")
+	r0, err := tp.Persister.GetTenantByEmail(email)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetTenantByStripeID(stripeID string) (model.Tenant, error) {
+	span := startPersister("GetTenantByStripeID", "")
+	r0, err := tp.Persister.GetTenantByStripeID(stripeID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ActivateTenant(tenantID string, active bool) error {
+	span := startPersister("ActivateTenant", "")
+	err := tp.Persister.ActivateTenant(tenantID, active)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ChangeTenantPlan(tenantID string, plan int) error {
+	span := startPersister("ChangeTenantPlan", "")
+	err := tp.Persister.ChangeTenantPlan(tenantID, plan)
+	End(span, err)
+	return err
+}
+
+func (tp persister) SetTenantSubscription(tenantID string, subscriptionID string) error {
+	span := startPersister("SetTenantSubscription", "")
+	err := tp.Persister.SetTenantSubscription(tenantID, subscriptionID)
+	End(span, err)
+	return err
+}
+
+func (tp persister) EnableExternalLogin(tenantID string, config map[string]model.OAuthConfig) error {
+	span := startPersister("EnableExternalLogin", "")
+	err := tp.Persister.EnableExternalLogin(tenantID, config)
+	End(span, err)
+	return err
+}
+
+func (tp persister) NewID() string {
+	span := startPersister("NewID", "")
+	defer span.End()
+	return tp.Persister.NewID()
+}
+
+func (tp persister) DeleteTenant(dbName string, email string) error {
+	span := startPersister("DeleteTenant", dbName)
+	err := tp.Persister.DeleteTenant(dbName, email)
+	End(span, err)
+	return err
+}
+
+func (tp persister) DeleteDatabase(baseID string) error {
+	span := startPersister("DeleteDatabase", "")
+	err := tp.Persister.DeleteDatabase(baseID)
+	End(span, err)
+	return err
+}
+
+func (tp persister) AddAppDeletion(d model.AppDeletion) (string, error) {
+	span := startPersister("AddAppDeletion", "")
+	r0, err := tp.Persister.AddAppDeletion(d)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetAppDeletion(baseID string) (model.AppDeletion, error) {
+	span := startPersister("GetAppDeletion", "")
+	r0, err := tp.Persister.GetAppDeletion(baseID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListDueAppDeletions(now time.Time) ([]model.AppDeletion, error) {
+	span := startPersister("ListDueAppDeletions", "")
+	r0, err := tp.Persister.ListDueAppDeletions(now)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) CompleteAppDeletion(id string, completed time.Time, certificate string) error {
+	span := startPersister("CompleteAppDeletion", "")
+	err := tp.Persister.CompleteAppDeletion(id, completed, certificate)
+	End(span, err)
+	return err
+}
+
+func (tp persister) CancelAppDeletion(id string) error {
+	span := startPersister("CancelAppDeletion", "")
+	err := tp.Persister.CancelAppDeletion(id)
+	End(span, err)
+	return err
+}
+
+func (tp persister) AddOwnershipTransfer(t model.OwnershipTransfer) (string, error) {
+	span := startPersister("AddOwnershipTransfer", "")
+	r0, err := tp.Persister.AddOwnershipTransfer(t)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetOwnershipTransfer(id string) (model.OwnershipTransfer, error) {
+	span := startPersister("GetOwnershipTransfer", "")
+	r0, err := tp.Persister.GetOwnershipTransfer(id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListOwnershipTransfers(tenantID string) ([]model.OwnershipTransfer, error) {
+	span := startPersister("ListOwnershipTransfers", "")
+	r0, err := tp.Persister.ListOwnershipTransfers(tenantID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) CompleteOwnershipTransfer(id string, status string, completed time.Time) error {
+	span := startPersister("CompleteOwnershipTransfer", "")
+	err := tp.Persister.CompleteOwnershipTransfer(id, status, completed)
+	End(span, err)
+	return err
+}
+
+func (tp persister) SetDatabaseTenant(baseID string, tenantID string) error {
+	span := startPersister("SetDatabaseTenant", "")
+	err := tp.Persister.SetDatabaseTenant(baseID, tenantID)
+	End(span, err)
+	return err
+}
+
+func (tp persister) AddBackup(b model.Backup) (string, error) {
+	span := startPersister("AddBackup", "")
+	r0, err := tp.Persister.AddBackup(b)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListBackups(dbName string) ([]model.Backup, error) {
+	span := startPersister("ListBackups", dbName)
+	r0, err := tp.Persister.ListBackups(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetBackup(id string) (model.Backup, error) {
+	span := startPersister("GetBackup", "")
+	r0, err := tp.Persister.GetBackup(id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeleteBackup(id string) error {
+	span := startPersister("DeleteBackup", "")
+	err := tp.Persister.DeleteBackup(id)
+	End(span, err)
+	return err
+}
+
+func (tp persister) AddSecret(s model.Secret) (string, error) {
+	span := startPersister("AddSecret", "")
+	r0, err := tp.Persister.AddSecret(s)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListSecrets(dbName string) ([]model.Secret, error) {
+	span := startPersister("ListSecrets", dbName)
+	r0, err := tp.Persister.ListSecrets(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetSecret(dbName string, name string, version int) (model.Secret, error) {
+	span := startPersister("GetSecret", dbName)
+	r0, err := tp.Persister.GetSecret(dbName, name, version)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UpdateSecret(s model.Secret) error {
+	span := startPersister("UpdateSecret", "")
+	err := tp.Persister.UpdateSecret(s)
+	End(span, err)
+	return err
+}
+
+func (tp persister) DeleteSecret(dbName string, name string) error {
+	span := startPersister("DeleteSecret", dbName)
+	err := tp.Persister.DeleteSecret(dbName, name)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListSecretsToRewrap(keyID string, limit int64) ([]model.Secret, error) {
+	span := startPersister("ListSecretsToRewrap", "")
+	r0, err := tp.Persister.ListSecretsToRewrap(keyID, limit)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddRequestRollups(records []model.RequestRollup) error {
+	span := startPersister("AddRequestRollups", "")
+	err := tp.Persister.AddRequestRollups(records)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListRequestRollups(filter model.RequestRollupFilter) ([]model.RequestRollup, error) {
+	span := startPersister("ListRequestRollups", "")
+	r0, err := tp.Persister.ListRequestRollups(filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddDomain(d model.Domain) (string, error) {
+	span := startPersister("AddDomain", "")
+	r0, err := tp.Persister.AddDomain(d)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListDomains(dbName string) ([]model.Domain, error) {
+	span := startPersister("ListDomains", dbName)
+	r0, err := tp.Persister.ListDomains(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) FindDomain(hostname string) (model.Domain, error) {
+	span := startPersister("FindDomain", "")
+	r0, err := tp.Persister.FindDomain(hostname)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) VerifyDomain(id string) error {
+	span := startPersister("VerifyDomain", "")
+	err := tp.Persister.VerifyDomain(id)
+	End(span, err)
+	return err
+}
+
+func (tp persister) DeleteDomain(dbName string, hostname string) error {
+	span := startPersister("DeleteDomain", dbName)
+	err := tp.Persister.DeleteDomain(dbName, hostname)
+	End(span, err)
+	return err
+}
+
+func (tp persister) SetFeatureFlag(flag model.FeatureFlag) error {
+	span := startPersister("SetFeatureFlag", "")
+	err := tp.Persister.SetFeatureFlag(flag)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListFeatureFlags(tenantID string) ([]model.FeatureFlag, error) {
+	span := startPersister("ListFeatureFlags", "")
+	r0, err := tp.Persister.ListFeatureFlags(tenantID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeleteFeatureFlag(tenantID string, name string) error {
+	span := startPersister("DeleteFeatureFlag", "")
+	err := tp.Persister.DeleteFeatureFlag(tenantID, name)
+	End(span, err)
+	return err
+}
+
+func (tp persister) AddAdminEvent(evt model.AdminEvent) error {
+	span := startPersister("AddAdminEvent", "")
+	err := tp.Persister.AddAdminEvent(evt)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListAdminEvents(filter model.AdminEventFilter) ([]model.AdminEvent, error) {
+	span := startPersister("ListAdminEvents", "")
+	r0, err := tp.Persister.ListAdminEvents(filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetUserByID(dbName string, accountID string, userID string) (model.User, error) {
+	span := startPersister("GetUserByID", dbName)
+	r0, err := tp.Persister.GetUserByID(dbName, accountID, userID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) FindUser(dbName string, userID string, token string) (model.User, error) {
+	span := startPersister("FindUser", dbName)
+	r0, err := tp.Persister.FindUser(dbName, userID, token)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) FindRootUser(dbName string, userID string, accountID string, token string) (model.User, error) {
+	span := startPersister("FindRootUser", dbName)
+	r0, err := tp.Persister.FindRootUser(dbName, userID, accountID, token)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetRootForBase(dbName string) (model.User, error) {
+	span := startPersister("GetRootForBase", dbName)
+	r0, err := tp.Persister.GetRootForBase(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) FindUserByEmail(dbName string, email string) (model.User, error) {
+	span := startPersister("FindUserByEmail", dbName)
+	r0, err := tp.Persister.FindUserByEmail(dbName, email)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UserEmailExists(dbName string, email string) (bool, error) {
+	span := startPersister("UserEmailExists", dbName)
+	r0, err := tp.Persister.UserEmailExists(dbName, email)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetFirstUserFromAccountID(dbName string, accountID string) (model.User, error) {
+	span := startPersister("GetFirstUserFromAccountID", dbName)
+	r0, err := tp.Persister.GetFirstUserFromAccountID(dbName, accountID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListAccounts(dbname string) ([]model.Account, error) {
+	span := startPersister("ListAccounts", "")
+	r0, err := tp.Persister.ListAccounts(dbname)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListUsers(dbname string, accountID string) ([]model.User, error) {
+	span := startPersister("ListUsers", "")
+	r0, err := tp.Persister.ListUsers(dbname, accountID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) CreateAccount(dbName string, email string) (string, error) {
+	span := startPersister("CreateAccount", dbName)
+	r0, err := tp.Persister.CreateAccount(dbName, email)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) CreateUser(dbName string, tok model.User) (string, error) {
+	span := startPersister("CreateUser", dbName)
+	r0, err := tp.Persister.CreateUser(dbName, tok)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) SetPasswordResetCode(dbName string, tokenID string, code string) error {
+	span := startPersister("SetPasswordResetCode", dbName)
+	err := tp.Persister.SetPasswordResetCode(dbName, tokenID, code)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ResetPassword(dbName string, email string, code string, password string) error {
+	span := startPersister("ResetPassword", dbName)
+	err := tp.Persister.ResetPassword(dbName, email, code, password)
+	End(span, err)
+	return err
+}
+
+func (tp persister) SetUserRole(dbName string, email string, role int) error {
+	span := startPersister("SetUserRole", dbName)
+	err := tp.Persister.SetUserRole(dbName, email, role)
+	End(span, err)
+	return err
+}
+
+func (tp persister) UserSetPassword(dbName string, userID string, password string) error {
+	span := startPersister("UserSetPassword", dbName)
+	err := tp.Persister.UserSetPassword(dbName, userID, password)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ResetUserCredentials(dbName string, userID string, email string, password string, token string) error {
+	span := startPersister("ResetUserCredentials", dbName)
+	err := tp.Persister.ResetUserCredentials(dbName, userID, email, password, token)
+	End(span, err)
+	return err
+}
+
+func (tp persister) RemoveUser(auth model.Auth, dbName string, userID string) error {
+	span := startPersister("RemoveUser", dbName)
+	err := tp.Persister.RemoveUser(auth, dbName, userID)
+	End(span, err)
+	return err
+}
+
+func (tp persister) PurgeUser(dbName string, tok model.User) (model.DeletionReport, error) {
+	span := startPersister("PurgeUser", dbName)
+	r0, err := tp.Persister.PurgeUser(dbName, tok)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddInvite(dbName string, inv model.Invite) (string, error) {
+	span := startPersister("AddInvite", dbName)
+	r0, err := tp.Persister.AddInvite(dbName, inv)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetInvite(dbName string, id string) (model.Invite, error) {
+	span := startPersister("GetInvite", dbName)
+	r0, err := tp.Persister.GetInvite(dbName, id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListInvites(dbName string, accountID string) ([]model.Invite, error) {
+	span := startPersister("ListInvites", dbName)
+	r0, err := tp.Persister.ListInvites(dbName, accountID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeleteInvite(dbName string, id string) error {
+	span := startPersister("DeleteInvite", dbName)
+	err := tp.Persister.DeleteInvite(dbName, id)
+	End(span, err)
+	return err
+}
+
+func (tp persister) AddPushSubscription(dbName string, sub model.PushSubscription) (string, error) {
+	span := startPersister("AddPushSubscription", dbName)
+	r0, err := tp.Persister.AddPushSubscription(dbName, sub)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListPushSubscriptions(dbName string, channel string) ([]model.PushSubscription, error) {
+	span := startPersister("ListPushSubscriptions", dbName)
+	r0, err := tp.Persister.ListPushSubscriptions(dbName, channel)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeletePushSubscription(dbName string, accountID string, id string) error {
+	span := startPersister("DeletePushSubscription", dbName)
+	err := tp.Persister.DeletePushSubscription(dbName, accountID, id)
+	End(span, err)
+	return err
+}
+
+func (tp persister) CreateDocument(auth model.Auth, dbName string, col string, doc map[string]interface{}) (map[string]interface{}, error) {
+	span := startPersister("CreateDocument", dbName)
+	r0, err := tp.Persister.CreateDocument(auth, dbName, col, doc)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) BulkCreateDocument(auth model.Auth, dbName string, col string, docs []interface{}) error {
+	span := startPersister("BulkCreateDocument", dbName)
+	err := tp.Persister.BulkCreateDocument(auth, dbName, col, docs)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListDocuments(auth model.Auth, dbName string, col string, params model.ListParams) (model.PagedResult, error) {
+	span := startPersister("ListDocuments", dbName)
+	r0, err := tp.Persister.ListDocuments(auth, dbName, col, params)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) QueryDocuments(auth model.Auth, dbName string, col string, filter map[string]interface{}, params model.ListParams) (model.PagedResult, error) {
+	span := startPersister("QueryDocuments", dbName)
+	r0, err := tp.Persister.QueryDocuments(auth, dbName, col, filter, params)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetDocumentByID(auth model.Auth, dbName string, col string, id string) (map[string]interface{}, error) {
+	span := startPersister("GetDocumentByID", dbName)
+	r0, err := tp.Persister.GetDocumentByID(auth, dbName, col, id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetDocumentsByIDs(auth model.Auth, dbName string, col string, ids []string) ([]map[string]interface{}, error) {
+	span := startPersister("GetDocumentsByIDs", dbName)
+	r0, err := tp.Persister.GetDocumentsByIDs(auth, dbName, col, ids)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UpdateDocument(auth model.Auth, dbName string, col string, id string, doc map[string]interface{}) (map[string]interface{}, error) {
+	span := startPersister("UpdateDocument", dbName)
+	r0, err := tp.Persister.UpdateDocument(auth, dbName, col, id, doc)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UpdateDocuments(auth model.Auth, dbName string, col string, filters map[string]interface{}, updateFields map[string]interface{}) (int64, error) {
+	span := startPersister("UpdateDocuments", dbName)
+	r0, err := tp.Persister.UpdateDocuments(auth, dbName, col, filters, updateFields)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) IncrementValue(auth model.Auth, dbName string, col string, id string, field string, n int) error {
+	span := startPersister("IncrementValue", dbName)
+	err := tp.Persister.IncrementValue(auth, dbName, col, id, field, n)
+	End(span, err)
+	return err
+}
+
+func (tp persister) DeleteDocument(auth model.Auth, dbName string, col string, id string) (int64, error) {
+	span := startPersister("DeleteDocument", dbName)
+	r0, err := tp.Persister.DeleteDocument(auth, dbName, col, id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeleteDocuments(auth model.Auth, dbName string, col string, filters map[string]interface{}) (int64, error) {
+	span := startPersister("DeleteDocuments", dbName)
+	r0, err := tp.Persister.DeleteDocuments(auth, dbName, col, filters)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListCollections(dbName string) ([]string, error) {
+	span := startPersister("ListCollections", dbName)
+	r0, err := tp.Persister.ListCollections(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ParseQuery(clauses [][]interface{}) (map[string]interface{}, error) {
+	span := startPersister("ParseQuery", "")
+	r0, err := tp.Persister.ParseQuery(clauses)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddFormSubmission(dbName string, form string, doc map[string]interface{}) error {
+	span := startPersister("AddFormSubmission", dbName)
+	err := tp.Persister.AddFormSubmission(dbName, form, doc)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListFormSubmissions(dbName string, name string) ([]map[string]interface{}, error) {
+	span := startPersister("ListFormSubmissions", dbName)
+	r0, err := tp.Persister.ListFormSubmissions(dbName, name)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) QueryFormSubmissions(dbName string, name string, f model.FormFilter, params model.ListParams) (model.PagedResult, error) {
+	span := startPersister("QueryFormSubmissions", dbName)
+	r0, err := tp.Persister.QueryFormSubmissions(dbName, name, f, params)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeleteFormSubmission(dbName string, name string, id string) error {
+	span := startPersister("DeleteFormSubmission", dbName)
+	err := tp.Persister.DeleteFormSubmission(dbName, name, id)
+	End(span, err)
+	return err
+}
+
+func (tp persister) MarkFormSubmissionRead(dbName string, name string, id string, read bool) error {
+	span := startPersister("MarkFormSubmissionRead", dbName)
+	err := tp.Persister.MarkFormSubmissionRead(dbName, name, id, read)
+	End(span, err)
+	return err
+}
+
+func (tp persister) GetForms(dbName string) ([]string, error) {
+	span := startPersister("GetForms", dbName)
+	r0, err := tp.Persister.GetForms(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) EachFormSubmission(dbName string, name string, since time.Time, until time.Time, fn func(doc map[string]interface{}) error) error {
+	span := startPersister("EachFormSubmission", dbName)
+	err := tp.Persister.EachFormSubmission(dbName, name, since, until, fn)
+	End(span, err)
+	return err
+}
+
+func (tp persister) AddFormDelivery(dbName string, d model.FormDelivery) error {
+	span := startPersister("AddFormDelivery", dbName)
+	err := tp.Persister.AddFormDelivery(dbName, d)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListFormDeliveries(dbName string, form string, limit int64) ([]model.FormDelivery, error) {
+	span := startPersister("ListFormDeliveries", dbName)
+	r0, err := tp.Persister.ListFormDeliveries(dbName, form, limit)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) SaveFormDefinition(dbName string, def model.FormDefinition) error {
+	span := startPersister("SaveFormDefinition", dbName)
+	err := tp.Persister.SaveFormDefinition(dbName, def)
+	End(span, err)
+	return err
+}
+
+func (tp persister) GetFormDefinition(dbName string, form string) (model.FormDefinition, error) {
+	span := startPersister("GetFormDefinition", dbName)
+	r0, err := tp.Persister.GetFormDefinition(dbName, form)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeleteFormDefinition(dbName string, form string) error {
+	span := startPersister("DeleteFormDefinition", dbName)
+	err := tp.Persister.DeleteFormDefinition(dbName, form)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListFormDefinitions(dbName string) ([]model.FormDefinition, error) {
+	span := startPersister("ListFormDefinitions", dbName)
+	r0, err := tp.Persister.ListFormDefinitions(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListEmailTemplates(dbName string) ([]model.EmailTemplate, error) {
+	span := startPersister("ListEmailTemplates", dbName)
+	r0, err := tp.Persister.ListEmailTemplates(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetEmailTemplate(dbName string, name string) (model.EmailTemplate, error) {
+	span := startPersister("GetEmailTemplate", dbName)
+	r0, err := tp.Persister.GetEmailTemplate(dbName, name)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) SaveEmailTemplate(dbName string, tmpl model.EmailTemplate) error {
+	span := startPersister("SaveEmailTemplate", dbName)
+	err := tp.Persister.SaveEmailTemplate(dbName, tmpl)
+	End(span, err)
+	return err
+}
+
+func (tp persister) DeleteEmailTemplate(dbName string, name string) error {
+	span := startPersister("DeleteEmailTemplate", dbName)
+	err := tp.Persister.DeleteEmailTemplate(dbName, name)
+	End(span, err)
+	return err
+}
+
+func (tp persister) GetUserLocale(dbName string, userID string) (string, error) {
+	span := startPersister("GetUserLocale", dbName)
+	r0, err := tp.Persister.GetUserLocale(dbName, userID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) SetUserLocale(dbName string, userID string, locale string) error {
+	span := startPersister("SetUserLocale", dbName)
+	err := tp.Persister.SetUserLocale(dbName, userID, locale)
+	End(span, err)
+	return err
+}
+
+func (tp persister) QueueEmail(dbName string, msg model.EmailMessage) (string, error) {
+	span := startPersister("QueueEmail", dbName)
+	r0, err := tp.Persister.QueueEmail(dbName, msg)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetEmailMessage(dbName string, id string) (model.EmailMessage, error) {
+	span := startPersister("GetEmailMessage", dbName)
+	r0, err := tp.Persister.GetEmailMessage(dbName, id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListDueEmails(dbName string, now time.Time, limit int64) ([]model.EmailMessage, error) {
+	span := startPersister("ListDueEmails", dbName)
+	r0, err := tp.Persister.ListDueEmails(dbName, now, limit)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UpdateEmailStatus(dbName string, id string, status string, attempts int, lastError string, providerID string, nextAttempt time.Time) error {
+	span := startPersister("UpdateEmailStatus", dbName)
+	err := tp.Persister.UpdateEmailStatus(dbName, id, status, attempts, lastError, providerID, nextAttempt)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListEmailLog(dbName string, filter model.EmailLogFilter) ([]model.EmailMessage, error) {
+	span := startPersister("ListEmailLog", dbName)
+	r0, err := tp.Persister.ListEmailLog(dbName, filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) CountEmails(dbName string, filter model.EmailLogFilter) (int64, error) {
+	span := startPersister("CountEmails", dbName)
+	r0, err := tp.Persister.CountEmails(dbName, filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddEmailEvent(dbName string, e model.EmailEvent) error {
+	span := startPersister("AddEmailEvent", dbName)
+	err := tp.Persister.AddEmailEvent(dbName, e)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListEmailEvents(dbName string, email string, limit int64) ([]model.EmailEvent, error) {
+	span := startPersister("ListEmailEvents", dbName)
+	r0, err := tp.Persister.ListEmailEvents(dbName, email, limit)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListEmailSuppressions(dbName string) ([]model.EmailSuppression, error) {
+	span := startPersister("ListEmailSuppressions", dbName)
+	r0, err := tp.Persister.ListEmailSuppressions(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) IsEmailSuppressed(dbName string, email string) (bool, error) {
+	span := startPersister("IsEmailSuppressed", dbName)
+	r0, err := tp.Persister.IsEmailSuppressed(dbName, email)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) SuppressEmail(dbName string, s model.EmailSuppression) error {
+	span := startPersister("SuppressEmail", dbName)
+	err := tp.Persister.SuppressEmail(dbName, s)
+	End(span, err)
+	return err
+}
+
+func (tp persister) RemoveEmailSuppression(dbName string, email string) error {
+	span := startPersister("RemoveEmailSuppression", dbName)
+	err := tp.Persister.RemoveEmailSuppression(dbName, email)
+	End(span, err)
+	return err
+}
+
+func (tp persister) QueueWebhook(dbName string, d model.WebhookDelivery) (string, error) {
+	span := startPersister("QueueWebhook", dbName)
+	r0, err := tp.Persister.QueueWebhook(dbName, d)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetWebhookDelivery(dbName string, id string) (model.WebhookDelivery, error) {
+	span := startPersister("GetWebhookDelivery", dbName)
+	r0, err := tp.Persister.GetWebhookDelivery(dbName, id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListDueWebhooks(dbName string, now time.Time, limit int64) ([]model.WebhookDelivery, error) {
+	span := startPersister("ListDueWebhooks", dbName)
+	r0, err := tp.Persister.ListDueWebhooks(dbName, now, limit)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UpdateWebhookStatus(dbName string, id string, status string, attempts int, statusCode int, lastError string, nextAttempt time.Time) error {
+	span := startPersister("UpdateWebhookStatus", dbName)
+	err := tp.Persister.UpdateWebhookStatus(dbName, id, status, attempts, statusCode, lastError, nextAttempt)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListWebhookDeliveries(dbName string, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	span := startPersister("ListWebhookDeliveries", dbName)
+	r0, err := tp.Persister.ListWebhookDeliveries(dbName, filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddFunction(dbName string, data model.ExecData) (string, error) {
+	span := startPersister("AddFunction", dbName)
+	r0, err := tp.Persister.AddFunction(dbName, data)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UpdateFunction(dbName string, id string, code string, trigger string) error {
+	span := startPersister("UpdateFunction", dbName)
+	err := tp.Persister.UpdateFunction(dbName, id, code, trigger)
+	End(span, err)
+	return err
+}
+
+func (tp persister) GetFunctionForExecution(dbName string, name string) (model.ExecData, error) {
+	span := startPersister("GetFunctionForExecution", dbName)
+	r0, err := tp.Persister.GetFunctionForExecution(dbName, name)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetFunctionByID(dbName string, id string) (model.ExecData, error) {
+	span := startPersister("GetFunctionByID", dbName)
+	r0, err := tp.Persister.GetFunctionByID(dbName, id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetFunctionByName(dbName string, name string) (model.ExecData, error) {
+	span := startPersister("GetFunctionByName", dbName)
+	r0, err := tp.Persister.GetFunctionByName(dbName, name)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListFunctions(dbName string) ([]model.ExecData, error) {
+	span := startPersister("ListFunctions", dbName)
+	r0, err := tp.Persister.ListFunctions(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListFunctionsByTrigger(dbName string, trigger string) ([]model.ExecData, error) {
+	span := startPersister("ListFunctionsByTrigger", dbName)
+	r0, err := tp.Persister.ListFunctionsByTrigger(dbName, trigger)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeleteFunction(dbName string, name string) error {
+	span := startPersister("DeleteFunction", dbName)
+	err := tp.Persister.DeleteFunction(dbName, name)
+	End(span, err)
+	return err
+}
+
+func (tp persister) RanFunction(dbName string, id string, rh model.ExecHistory) error {
+	span := startPersister("RanFunction", dbName)
+	err := tp.Persister.RanFunction(dbName, id, rh)
+	End(span, err)
+	return err
+}
+
+func (tp persister) CountFunctionRuns(dbName string, since time.Time, until time.Time) (int64, error) {
+	span := startPersister("CountFunctionRuns", dbName)
+	r0, err := tp.Persister.CountFunctionRuns(dbName, since, until)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListTasks() ([]model.Task, error) {
+	span := startPersister("ListTasks", "")
+	r0, err := tp.Persister.ListTasks()
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListTasksByBase(dbName string) ([]model.Task, error) {
+	span := startPersister("ListTasksByBase", dbName)
+	r0, err := tp.Persister.ListTasksByBase(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetTaskByID(dbName string, id string) (model.Task, error) {
+	span := startPersister("GetTaskByID", dbName)
+	r0, err := tp.Persister.GetTaskByID(dbName, id)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddTask(p0 string, p1 model.Task) (string, error) {
+	span := startPersister("AddTask", "")
+	r0, err := tp.Persister.AddTask(p0, p1)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) UpdateTask(dbName string, id string, task model.Task) error {
+	span := startPersister("UpdateTask", dbName)
+	err := tp.Persister.UpdateTask(dbName, id, task)
+	End(span, err)
+	return err
+}
+
+func (tp persister) DeleteTask(dbName string, id string) error {
+	span := startPersister("DeleteTask", dbName)
+	err := tp.Persister.DeleteTask(dbName, id)
+	End(span, err)
+	return err
+}
+
+func (tp persister) UpdateTaskRun(dbName string, id string, lastRun time.Time, status string, lastError string) error {
+	span := startPersister("UpdateTaskRun", dbName)
+	err := tp.Persister.UpdateTaskRun(dbName, id, lastRun, status, lastError)
+	End(span, err)
+	return err
+}
+
+func (tp persister) SetTaskEnabled(dbName string, id string, enabled bool) error {
+	span := startPersister("SetTaskEnabled", dbName)
+	err := tp.Persister.SetTaskEnabled(dbName, id, enabled)
+	End(span, err)
+	return err
+}
+
+func (tp persister) AddTaskRun(dbName string, run model.TaskRun) (string, error) {
+	span := startPersister("AddTaskRun", dbName)
+	r0, err := tp.Persister.AddTaskRun(dbName, run)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListTaskRuns(dbName string, taskID string, limit int64) ([]model.TaskRun, error) {
+	span := startPersister("ListTaskRuns", dbName)
+	r0, err := tp.Persister.ListTaskRuns(dbName, taskID, limit)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddFile(dbName string, f model.File) (string, error) {
+	span := startPersister("AddFile", dbName)
+	r0, err := tp.Persister.AddFile(dbName, f)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) GetFileByID(dbName string, fileID string) (model.File, error) {
+	span := startPersister("GetFileByID", dbName)
+	r0, err := tp.Persister.GetFileByID(dbName, fileID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) DeleteFile(dbName string, fileID string) error {
+	span := startPersister("DeleteFile", dbName)
+	err := tp.Persister.DeleteFile(dbName, fileID)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListAllFiles(dbName string, accountID string) ([]model.File, error) {
+	span := startPersister("ListAllFiles", dbName)
+	r0, err := tp.Persister.ListAllFiles(dbName, accountID)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListFiles(dbName string, filter model.FileFilter) ([]model.File, error) {
+	span := startPersister("ListFiles", dbName)
+	r0, err := tp.Persister.ListFiles(dbName, filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) LinkFile(dbName string, fileID string, link model.FileLink) error {
+	span := startPersister("LinkFile", dbName)
+	err := tp.Persister.LinkFile(dbName, fileID, link)
+	End(span, err)
+	return err
+}
+
+func (tp persister) StorageUsage(dbName string) (model.StorageUsage, error) {
+	span := startPersister("StorageUsage", dbName)
+	r0, err := tp.Persister.StorageUsage(dbName)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) Count(auth model.Auth, dbName string, col string, filters map[string]interface{}) (int64, error) {
+	span := startPersister("Count", dbName)
+	r0, err := tp.Persister.Count(auth, dbName, col, filters)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddAuditEvent(dbName string, evt model.AuditEvent) error {
+	span := startPersister("AddAuditEvent", dbName)
+	err := tp.Persister.AddAuditEvent(dbName, evt)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListAuditEvents(dbName string, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	span := startPersister("ListAuditEvents", dbName)
+	r0, err := tp.Persister.ListAuditEvents(dbName, filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) CountAuditUsers(dbName string, filter model.AuditFilter) (int64, error) {
+	span := startPersister("CountAuditUsers", dbName)
+	r0, err := tp.Persister.CountAuditUsers(dbName, filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) PurgeAuditEvents(dbName string, before time.Time) (int64, error) {
+	span := startPersister("PurgeAuditEvents", dbName)
+	r0, err := tp.Persister.PurgeAuditEvents(dbName, before)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) AddAppEvents(dbName string, events []model.AppEvent) error {
+	span := startPersister("AddAppEvents", dbName)
+	err := tp.Persister.AddAppEvents(dbName, events)
+	End(span, err)
+	return err
+}
+
+func (tp persister) ListAppEvents(dbName string, filter model.AppEventFilter) ([]model.AppEvent, error) {
+	span := startPersister("ListAppEvents", dbName)
+	r0, err := tp.Persister.ListAppEvents(dbName, filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) ListAppEventRollups(dbName string, filter model.AppEventRollupFilter) ([]model.AppEventRollup, error) {
+	span := startPersister("ListAppEventRollups", dbName)
+	r0, err := tp.Persister.ListAppEventRollups(dbName, filter)
+	End(span, err)
+	return r0, err
+}
+
+func (tp persister) PurgeAppEvents(dbName string, before time.Time) (int64, error) {
+	span := startPersister("PurgeAppEvents", dbName)
+	r0, err := tp.Persister.PurgeAppEvents(dbName, before)
+	End(span, err)
+	return r0, err
+}
@@ -0,0 +1,151 @@
+//go:build ignore
+
+// persister_gen.go generates persister.go, the Persister wrapping each call
+// of the database.Persister interface in a span
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "../database/persister.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var iface *ast.InterfaceType
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == "Persister" {
+			iface, _ = ts.Type.(*ast.InterfaceType)
+		}
+		return iface == nil
+	})
+	if iface == nil {
+		log.Fatal("Persister interface not found")
+	}
+
+	var body bytes.Buffer
+	for _, m := range iface.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			continue
+		}
+		writeMethod(&body, fset, m.Names[0].Name, ft)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by persister_gen.go; DO NOT EDIT.\n\n")
+	out.WriteString("package tracing\n\nimport (\n")
+	for _, imp := range []string{"time", "", "github.com/staticbackendhq/core/database", "github.com/staticbackendhq/core/model"} {
+		pkg := imp[strings.LastIndex(imp, "/")+1:]
+		if len(imp) == 0 {
+			out.WriteString("\n")
+		} else if bytes.Contains(body.Bytes(), []byte(pkg+".")) {
+			fmt.Fprintf(&out, "\t%q\n", imp)
+		}
+	}
+	out.WriteString(")\n\n")
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile("persister.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeMethod(w *bytes.Buffer, fset *token.FileSet, name string, ft *ast.FuncType) {
+	var params, args []string
+	dbName := `""`
+	for i, p := range ft.Params.List {
+		typ := exprString(fset, p.Type)
+
+		names := p.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: fmt.Sprintf("p%d", i)}}
+		}
+
+		for _, n := range names {
+			params = append(params, n.Name+" "+typ)
+			if strings.HasPrefix(typ, "...") {
+				args = append(args, n.Name+"...")
+			} else {
+				args = append(args, n.Name)
+			}
+
+			if n.Name == "dbName" {
+				dbName = "dbName"
+			}
+		}
+	}
+
+	var results []string
+	if ft.Results != nil {
+		for _, r := range ft.Results.List {
+			typ := exprString(fset, r.Type)
+			n := len(r.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				results = append(results, typ)
+			}
+		}
+	}
+
+	ret := strings.Join(results, ", ")
+	if len(results) > 1 {
+		ret = "(" + ret + ")"
+	}
+
+	call := fmt.Sprintf("tp.Persister.%s(%s)", name, strings.Join(args, ", "))
+
+	fmt.Fprintf(w, "func (tp persister) %s(%s) %s {\n", name, strings.Join(params, ", "), ret)
+	fmt.Fprintf(w, "\tspan := startPersister(%q, %s)\n", name, dbName)
+
+	hasErr := len(results) > 0 && results[len(results)-1] == "error"
+	switch {
+	case len(results) == 0:
+		fmt.Fprintf(w, "\tdefer span.End()\n\t%s\n", call)
+	case !hasErr:
+		fmt.Fprintf(w, "\tdefer span.End()\n\treturn %s\n", call)
+	default:
+		var vars []string
+		for i := 0; i < len(results)-1; i++ {
+			vars = append(vars, fmt.Sprintf("r%d", i))
+		}
+		vars = append(vars, "err")
+
+		fmt.Fprintf(w, "\t%s := %s\n", strings.Join(vars, ", "), call)
+		fmt.Fprintf(w, "\tEnd(span, err)\n")
+		fmt.Fprintf(w, "\treturn %s\n", strings.Join(vars, ", "))
+	}
+	w.WriteString("}\n\n")
+}
+
+func exprString(fset *token.FileSet, e ast.Expr) string {
+	var b bytes.Buffer
+	if err := format.Node(&b, fset, e); err != nil {
+		log.Fatal(err)
+	}
+
+	s := b.String()
+	// the types of the database package are qualified once generated here
+	if id, ok := e.(*ast.Ident); ok && ast.IsExported(id.Name) {
+		s = "database." + s
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+// Package tracing instruments the HTTP requests, the function executions,
+// the Persister calls and the volatile store publishes with OpenTelemetry
+// spans.
+//
+// The exporter is selected with the TRACING_EXPORTER environment variable:
+// "otlp" sends the spans over OTLP/HTTP configured with the standard
+// OTEL_EXPORTER_OTLP_* variables and "stdout" prints them. Tracing is
+// disabled when it's empty, the spans are then no-ops.
+package tracing
+
+//go:generate go run persister_gen.go
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/staticbackendhq/core/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters of the TRACING_EXPORTER environment variable
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const instrumentationName = "github.com/staticbackendhq/core"
+
+var provider *sdktrace.TracerProvider
+
+// Setup registers the tracer provider exporting the spans with the
+// configured exporter. It does nothing when no exporter is configured.
+func Setup(cfg config.AppConfig) error {
+	var exp sdktrace.SpanExporter
+	var err error
+
+	switch cfg.TracingExporter {
+	case "":
+		return nil
+	case ExporterOTLP:
+		exp, err = otlptracehttp.New(context.Background())
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return fmt.Errorf("unsupported tracing exporter %s, expected %s or %s", cfg.TracingExporter, ExporterOTLP, ExporterStdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("staticbackend"))
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return nil
+}
+
+// Enabled returns true when the spans are exported
+func Enabled() bool {
+	return provider != nil
+}
+
+// Shutdown exports the pending spans
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// Start starts a span, it's a child of the span of ctx if there's one
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error if any and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
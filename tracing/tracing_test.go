@@ -0,0 +1,112 @@
+package tracing_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/database/memory"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func record(t *testing.T) *tracetest.SpanRecorder {
+	sr := tracetest.NewSpanRecorder()
+
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	return sr
+}
+
+func attr(span sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestPersisterSpans(t *testing.T) {
+	sr := record(t)
+
+	log := logger.Get(config.AppConfig{AppEnv: "dev"})
+	vol := tracing.Volatilizer(cache.NewDevCache(log))
+	db := tracing.Persister(memory.New(vol.PublishDocument))
+
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := model.Auth{AccountID: "acct", UserID: "user", Role: 100}
+	if _, err := db.CreateDocument(auth, "tracedb", "tasks", map[string]any{"title": "traced"}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range sr.Ended() {
+		spans[span.Name()] = span
+	}
+
+	if _, ok := spans["persister.Ping"]; !ok {
+		t.Error("expected a span for Ping")
+	}
+
+	span, ok := spans["persister.CreateDocument"]
+	if !ok {
+		t.Fatal("expected a span for CreateDocument")
+	} else if v := attr(span, "db.name").AsString(); v != "tracedb" {
+		t.Errorf("expected the db.name attribute to be tracedb got %s", v)
+	}
+
+	span, ok = spans["volatile.PublishDocument"]
+	if !ok {
+		t.Fatal("expected a span for the document publish")
+	} else if v := attr(span, "messaging.destination").AsString(); v != "db-tasks" {
+		t.Errorf("expected the db-tasks destination got %s", v)
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	sr := record(t)
+
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := w.(http.Flusher); !ok {
+				t.Error("expected the response writer to remain a Flusher")
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}),
+		middleware.Trace(),
+	)
+
+	req := httptest.NewRequest("GET", "/db/tasks/123", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name() != "GET /db" {
+		t.Errorf("expected span name GET /db got %s", span.Name())
+	} else if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the caller's trace got %s", span.SpanContext().TraceID())
+	} else if v := attr(span, "http.status_code").AsInt64(); v != http.StatusNotFound {
+		t.Errorf("expected status code 404 got %d", v)
+	}
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/staticbackendhq/core/cache"
+	"github.com/staticbackendhq/core/database"
+	"github.com/staticbackendhq/core/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// persister wraps each Persister call in a span. The calls do not receive
+// the request context, their spans are tagged with the database name to be
+// found from the request ones.
+type persister struct {
+	database.Persister
+}
+
+// Persister returns the Persister tracing the calls of p
+func Persister(p database.Persister) database.Persister {
+	return persister{Persister: p}
+}
+
+func startPersister(method, dbName string) trace.Span {
+	attrs := []attribute.KeyValue{attribute.String("db.operation", method)}
+	if len(dbName) > 0 {
+		attrs = append(attrs, attribute.String("db.name", dbName))
+	}
+
+	_, span := Start(context.Background(), "persister."+method, attrs...)
+	return span
+}
+
+// volatilizer wraps the publishes of the volatile store in a span
+type volatilizer struct {
+	cache.Volatilizer
+}
+
+// Volatilizer returns the Volatilizer tracing the publishes of v
+func Volatilizer(v cache.Volatilizer) cache.Volatilizer {
+	return volatilizer{Volatilizer: v}
+}
+
+func startPublish(method, channel, typ string) trace.Span {
+	_, span := Start(context.Background(), "volatile."+method,
+		attribute.String("messaging.destination", channel),
+		attribute.String("messaging.message_type", typ),
+	)
+	return span
+}
+
+func (v volatilizer) Publish(msg model.Command) error {
+	span := startPublish("Publish", msg.Channel, msg.Type)
+	err := v.Volatilizer.Publish(msg)
+	End(span, err)
+	return err
+}
+
+func (v volatilizer) PublishDocument(auth model.Auth, dbname, channel, typ string, doc any) {
+	span := startPublish("PublishDocument", channel, typ)
+	defer span.End()
+
+	v.Volatilizer.PublishDocument(auth, dbname, channel, typ, doc)
+}
+
+func (v volatilizer) PublishAt(msg model.Command, at time.Time) error {
+	span := startPublish("PublishAt", msg.Channel, msg.Type)
+	err := v.Volatilizer.PublishAt(msg, at)
+	End(span, err)
+	return err
+}
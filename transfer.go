@@ -0,0 +1,140 @@
+package staticbackend
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+// ownershipTransfers lists the transfers sent and received by the tenant
+// on GET and invites another customer to take over the app, or the whole
+// account, on POST from /account/transfer
+func ownershipTransfers(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := backend.ListOwnershipTransfers(conf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respond(w, http.StatusOK, list)
+	case http.MethodPost:
+		var data struct {
+			Email        string `json:"email"`
+			WholeAccount bool   `json:"wholeAccount"`
+		}
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		t, err := backend.RequestOwnershipTransfer(conf, data.Email, data.WholeAccount)
+		if err != nil {
+			http.Error(w, err.Error(), transferStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, fmt.Sprintf("requested the ownership transfer %s to %s", t.ID, t.ToEmail))
+
+		respond(w, http.StatusOK, t)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// acceptOwnershipTransfer moves the databases of a transfer to the tenant
+// of the database from /account/transfer/accept and returns their new root
+// credentials
+func acceptOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data struct {
+		ID    string `json:"id"`
+		Token string `json:"token"`
+	}
+	if err := parseBody(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t, transferred, err := backend.AcceptOwnershipTransfer(conf, data.ID, data.Token)
+
+	// the databases already moved are recorded even if one failed
+	for _, db := range transferred {
+		recordAdminEvent(r, model.AdminEvent{
+			Type:     model.AdminOwnershipTransfer,
+			Actor:    auditActor(auth),
+			TenantID: t.ToTenantID,
+			DBName:   db.Name,
+			Target:   t.ID,
+			Detail:   fmt.Sprintf("transferred from tenant %s to tenant %s", t.FromTenantID, t.ToTenantID),
+		})
+
+		// the root token of the previous owner was replaced
+		parts := strings.Split(db.RootToken, "|")
+		if len(parts) == 3 {
+			recordAuthEvent(r, model.DatabaseConfig{ID: db.ID, Name: db.Name}, model.AuditEvent{
+				AccountID: parts[1],
+				UserID:    parts[0],
+				Type:      model.AuditTokenRevoked,
+				Detail:    "database transferred to tenant " + t.ToTenantID,
+			})
+		}
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), transferStatus(err))
+		return
+	}
+
+	respond(w, http.StatusOK, transferred)
+}
+
+// cancelOwnershipTransfer cancels a pending transfer sent or received by
+// the tenant from /account/transfer/{id}
+func cancelOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := backend.CancelOwnershipTransfer(conf, getURLPart(r.URL.Path, 3)); err != nil {
+		http.Error(w, err.Error(), transferStatus(err))
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+// transferStatus returns the status of a failed transfer request
+func transferStatus(err error) int {
+	switch {
+	case errors.Is(err, backend.ErrTransferRecipient), errors.Is(err, backend.ErrInvalidTransfer):
+		return http.StatusBadRequest
+	case errors.Is(err, backend.ErrTransferPending):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,157 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestOwnershipTransfer(t *testing.T) {
+	mailer := &captureMailer{}
+
+	emailer := backend.Emailer
+	backend.Emailer = mailer
+	defer func() { backend.Emailer = emailer }()
+
+	from, err := backend.DB.CreateTenant(model.Tenant{ID: "transfer-agency", Email: "agency@test.com", Created: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := backend.DB.CreateDatabase(model.DatabaseConfig{
+		ID:       "transfersrc",
+		TenantID: from.ID,
+		Name:     "transfersrc",
+		IsActive: true,
+		Created:  time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.DB.DeleteDatabase(src.ID)
+
+	_, root, err := backend.Membership(src).CreateAccountAndUser(from.Email, "agencypw", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldRoot := fmt.Sprintf("%s|%s|%s", root.ID, root.AccountID, root.Token)
+
+	// the recipient must be another customer
+	for _, to := range []string{admEmail, "unknown@test.com"} {
+		resp := dbReq(t, ownershipTransfers, "POST", "/account/transfer", map[string]string{"email": to}, true)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status 400 transferring to %s got %d", to, resp.StatusCode)
+		}
+	}
+
+	tr, err := backend.RequestOwnershipTransfer(src, admEmail, false)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := backend.RequestOwnershipTransfer(src, admEmail, false); err != backend.ErrTransferPending {
+		t.Errorf("expected the transfer to be pending got %v", err)
+	}
+
+	var token string
+	for _, m := range mailer.sent {
+		if m.To == admEmail {
+			if match := regexp.MustCompile(`Token: <strong>([0-9a-f]+)</strong>`).FindStringSubmatch(m.HTMLBody); len(match) == 2 {
+				token = match[1]
+			}
+		}
+	}
+	if len(token) == 0 {
+		t.Fatalf("expected the transfer token to be emailed got %v", mailer.sent)
+	}
+
+	resp := dbReq(t, ownershipTransfers, "GET", "/account/transfer", nil, true)
+	defer resp.Body.Close()
+
+	var list []model.OwnershipTransfer
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &list); err != nil {
+		t.Fatal(err)
+	} else if len(list) == 0 || list[0].ID != tr.ID || list[0].Status != model.TransferPending {
+		t.Fatalf("expected the received transfer got %v", list)
+	}
+
+	data := map[string]string{"id": tr.ID, "token": "wrong"}
+	resp2 := dbReq(t, acceptOwnershipTransfer, "POST", "/account/transfer/accept", data, true)
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 with a wrong token got %d", resp2.StatusCode)
+	}
+
+	data["token"] = token
+	resp3 := dbReq(t, acceptOwnershipTransfer, "POST", "/account/transfer/accept", data, true)
+	defer resp3.Body.Close()
+
+	var transferred []model.TransferredDatabase
+	if resp3.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp3))
+	} else if err := parseBody(resp3.Body, &transferred); err != nil {
+		t.Fatal(err)
+	} else if len(transferred) != 1 || transferred[0].Name != src.Name {
+		t.Fatalf("expected the transferred database got %v", transferred)
+	}
+
+	conf, err := backend.DB.FindDatabase(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if moved, err := backend.DB.FindDatabase(src.ID); err != nil {
+		t.Fatal(err)
+	} else if moved.TenantID != conf.TenantID {
+		t.Errorf("expected the database to belong to the recipient got %s", moved.TenantID)
+	}
+
+	// the previous owner's root token is re-keyed
+	if _, err := middleware.ValidateRootToken(backend.DB, src.Name, oldRoot); err == nil {
+		t.Error("expected the previous root token to be refused")
+	}
+
+	if tok, err := middleware.ValidateRootToken(backend.DB, src.Name, transferred[0].RootToken); err != nil {
+		t.Fatal(err)
+	} else if tok.Email != admEmail {
+		t.Errorf("expected the root user to take the recipient's email got %s", tok.Email)
+	}
+
+	events, err := backend.ListAdminEvents(model.AdminEventFilter{Type: model.AdminOwnershipTransfer, DBName: src.Name})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 1 || events[0].Target != tr.ID {
+		t.Errorf("expected the transfer in the audit trail got %v", events)
+	}
+
+	revoked, err := backend.DB.ListAuditEvents(src.Name, model.AuditFilter{Type: model.AuditTokenRevoked})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(revoked) != 1 || revoked[0].UserID != root.ID {
+		t.Errorf("expected the revoked root token in the auth audit got %v", revoked)
+	}
+
+	// an accepted transfer cannot be accepted or cancelled again
+	resp4 := dbReq(t, acceptOwnershipTransfer, "POST", "/account/transfer/accept", data, true)
+	defer resp4.Body.Close()
+
+	if resp4.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 accepting twice got %d", resp4.StatusCode)
+	}
+
+	resp5 := dbReq(t, cancelOwnershipTransfer, "DELETE", "/account/transfer/"+tr.ID, nil, true)
+	defer resp5.Body.Close()
+
+	if resp5.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 cancelling an accepted transfer got %d", resp5.StatusCode)
+	}
+}
@@ -2,6 +2,7 @@ package staticbackend
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/function"
 	"github.com/staticbackendhq/core/logger"
 	"github.com/staticbackendhq/core/middleware"
 	"github.com/staticbackendhq/core/model"
@@ -485,7 +487,7 @@ func (x *ui) fnEdit(w http.ResponseWriter, r *http.Request) {
 }
 
 func (x *ui) fnSave(w http.ResponseWriter, r *http.Request) {
-	conf, _, err := middleware.Extract(r, false)
+	conf, auth, err := middleware.Extract(r, false)
 	if err != nil {
 		renderErr(w, r, err, x.log)
 		return
@@ -512,6 +514,8 @@ func (x *ui) fnSave(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		recordFunctionDeployed(r, conf, auth, name, "added")
+
 		http.Redirect(w, r, "/ui/fn/"+newID, http.StatusSeeOther)
 		return
 	}
@@ -521,11 +525,13 @@ func (x *ui) fnSave(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordFunctionDeployed(r, conf, auth, name, "updated")
+
 	http.Redirect(w, r, "/ui/fn/"+id, http.StatusSeeOther)
 }
 
 func (x *ui) fnDel(w http.ResponseWriter, r *http.Request) {
-	conf, _, err := middleware.Extract(r, false)
+	conf, auth, err := middleware.Extract(r, false)
 	if err != nil {
 		renderErr(w, r, err, x.log)
 		return
@@ -536,6 +542,8 @@ func (x *ui) fnDel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordRootOperation(r, conf, auth, "deleted the function "+name)
+
 	http.Redirect(w, r, "/ui/fn", http.StatusSeeOther)
 }
 
@@ -621,6 +629,12 @@ func (x ui) tasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	now := time.Now()
+	for i, task := range allTasks {
+		// tasks saved before the interval was validated might not parse
+		allTasks[i].NextRun, _ = function.NextRun(task, now)
+	}
+
 	render(w, r, "tasks_list.html", allTasks, nil, x.log)
 }
 
@@ -643,18 +657,31 @@ func (x ui) taskNew(w http.ResponseWriter, r *http.Request) {
 			Value:    r.Form.Get("value"),
 			Interval: r.Form.Get("interval"),
 			Meta:     r.Form.Get("meta"),
+			Enabled:  true,
 			BaseName: conf.Name,
 		}
 
-		taskID, err := backend.DB.AddTask(conf.Name, task)
-		if err != nil {
+		// empty values leave the default retry policy
+		task.Retry.MaxAttempts, _ = strconv.Atoi(r.Form.Get("maxAttempts"))
+		task.Retry.Backoff, _ = strconv.Atoi(r.Form.Get("backoff"))
+
+		if runAt := r.Form.Get("runAt"); len(runAt) > 0 {
+			at, err := time.ParseInLocation("2006-01-02T15:04", runAt, time.UTC)
+			if err != nil {
+				render(w, r, "tasks_new.html", task, &Flash{Type: "danger", Message: "invalid run at time"}, x.log)
+				return
+			}
+			task.RunAt = at
+		}
+
+		if _, err := backend.AddTask(conf, task); errors.Is(err, backend.ErrInvalidTask) {
+			render(w, r, "tasks_new.html", task, &Flash{Type: "danger", Message: err.Error()}, x.log)
+			return
+		} else if err != nil {
 			renderErr(w, r, err, x.log)
 			return
 		}
 
-		task.ID = taskID
-		backend.Scheduler.AddOnTheFly(task)
-
 		http.Redirect(w, r, "/ui/tasks", http.StatusSeeOther)
 		return
 	}
@@ -0,0 +1,124 @@
+package staticbackend
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/middleware"
+)
+
+// listSecrets returns the secrets of the database's vault without their
+// values from /vault
+func listSecrets(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	list, err := backend.ListSecrets(conf)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, list)
+}
+
+// secretActions handles a secret of the database's vault from /vault/{name}:
+// GET returns the latest value or the one of the version query string
+// parameter, PUT adds a version with {value} and DELETE removes all its
+// versions. /vault/rotate encrypts all the secrets again with new data keys.
+func secretActions(w http.ResponseWriter, r *http.Request) {
+	conf, auth, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := getURLPart(r.URL.Path, 2)
+	if len(name) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case name == "rotate" && r.Method == http.MethodPost:
+		n, err := backend.RotateSecrets(conf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "rotated the data keys of "+strconv.Itoa(n)+" secret versions")
+
+		respond(w, http.StatusOK, n)
+	case r.Method == http.MethodGet:
+		version := 0
+		if s := r.URL.Query().Get("version"); len(s) > 0 {
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				http.Error(w, "invalid version", http.StatusBadRequest)
+				return
+			}
+			version = v
+		}
+
+		value, err := backend.GetSecret(conf, name, version)
+		if err != nil {
+			http.Error(w, err.Error(), secretStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "read the secret "+name)
+
+		respond(w, http.StatusOK, value)
+	case r.Method == http.MethodPut:
+		var data struct {
+			Value string `json:"value"`
+		}
+		if err := parseBody(r.Body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s, err := backend.SetSecret(conf, name, data.Value)
+		if err != nil {
+			http.Error(w, err.Error(), secretStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "set version "+strconv.Itoa(s.Version)+" of the secret "+name)
+
+		respond(w, http.StatusOK, s)
+	case r.Method == http.MethodDelete:
+		if err := backend.DeleteSecret(conf, name); err != nil {
+			http.Error(w, err.Error(), secretStatus(err))
+			return
+		}
+
+		recordRootOperation(r, conf, auth, "deleted the secret "+name)
+
+		respond(w, http.StatusOK, true)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// secretStatus returns the status of a failed secret request
+func secretStatus(err error) int {
+	switch {
+	case errors.Is(err, backend.ErrInvalidSecret):
+		return http.StatusBadRequest
+	case errors.Is(err, backend.ErrSecretNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
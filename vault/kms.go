@@ -0,0 +1,51 @@
+package vault
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+// kmsKey is a master key kept in AWS KMS, the data keys are wrapped and
+// unwrapped by KMS
+type kmsKey struct {
+	keyID  string
+	client *kms.KMS
+}
+
+// NewKMSKey returns the AWS KMS master key keyID, a key ID, ARN or alias.
+// The credentials are the instance's AWS credentials.
+func NewKMSKey(keyID, region string) (MasterKey, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, err
+	}
+
+	return kmsKey{keyID: keyID, client: kms.New(sess)}, nil
+}
+
+func (k kmsKey) ID() string {
+	return "kms-" + k.keyID
+}
+
+func (k kmsKey) Wrap(dataKey []byte) ([]byte, error) {
+	out, err := k.client.Encrypt(&kms.EncryptInput{
+		KeyId:     aws.String(k.keyID),
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (k kmsKey) Unwrap(wrapped []byte) ([]byte, error) {
+	out, err := k.client.Decrypt(&kms.DecryptInput{
+		KeyId:          aws.String(k.keyID),
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
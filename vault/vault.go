@@ -0,0 +1,186 @@
+// Package vault encrypts the apps' secrets with envelope encryption. Each
+// value is encrypted with its own data key and the data key is wrapped by
+// a master key, from the environment or a KMS. Rotating the master key
+// only rewraps the data keys, the previous master keys stay in the keyring
+// to open the secrets until they are rewrapped.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrUnknownKey is returned when opening an envelope wrapped by a
+	// master key not in the keyring
+	ErrUnknownKey = errors.New("unknown master key")
+	// ErrDecrypt is returned when a value or data key cannot be decrypted
+	ErrDecrypt = errors.New("unable to decrypt")
+)
+
+// MasterKey wraps and unwraps the data keys
+type MasterKey interface {
+	// ID identifies the master key which wrapped a data key
+	ID() string
+	Wrap(dataKey []byte) ([]byte, error)
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// Envelope is an encrypted value with its data key wrapped by the master
+// key KeyID
+type Envelope struct {
+	Ciphertext []byte
+	DataKey    []byte
+	KeyID      string
+}
+
+// Keyring seals the values with the current master key and opens the ones
+// sealed with the current or a previous master key
+type Keyring struct {
+	current MasterKey
+	keys    map[string]MasterKey
+}
+
+// NewKeyring returns a keyring sealing with the current master key
+func NewKeyring(current MasterKey, previous ...MasterKey) *Keyring {
+	k := &Keyring{current: current, keys: make(map[string]MasterKey)}
+	for _, key := range append(previous, current) {
+		k.keys[key.ID()] = key
+	}
+	return k
+}
+
+// KeyID returns the ID of the current master key
+func (k *Keyring) KeyID() string {
+	return k.current.ID()
+}
+
+// Seal encrypts a value with a new data key wrapped by the current master
+// key
+func (k *Keyring) Seal(plaintext []byte) (Envelope, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return Envelope{}, err
+	}
+
+	ciphertext, err := seal(dataKey, plaintext)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	wrapped, err := k.current.Wrap(dataKey)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{Ciphertext: ciphertext, DataKey: wrapped, KeyID: k.current.ID()}, nil
+}
+
+// Open decrypts the value of an envelope
+func (k *Keyring) Open(e Envelope) ([]byte, error) {
+	dataKey, err := k.unwrap(e)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, e.Ciphertext)
+}
+
+// Rewrap wraps the data key of an envelope with the current master key,
+// the value is not encrypted again
+func (k *Keyring) Rewrap(e Envelope) (Envelope, error) {
+	if e.KeyID == k.current.ID() {
+		return e, nil
+	}
+
+	dataKey, err := k.unwrap(e)
+	if err != nil {
+		return e, err
+	}
+
+	wrapped, err := k.current.Wrap(dataKey)
+	if err != nil {
+		return e, err
+	}
+
+	return Envelope{Ciphertext: e.Ciphertext, DataKey: wrapped, KeyID: k.current.ID()}, nil
+}
+
+func (k *Keyring) unwrap(e Envelope) ([]byte, error) {
+	key, ok := k.keys[e.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownKey, e.KeyID)
+	}
+	return key.Unwrap(e.DataKey)
+}
+
+// localKey is a master key derived from a secret of the environment
+type localKey struct {
+	id  string
+	key []byte
+}
+
+// NewLocalKey returns the master key derived from a secret, its ID is
+// derived from the key so the same secret always opens its data keys
+func NewLocalKey(secret string) MasterKey {
+	key := sha256.Sum256([]byte(secret))
+	id := sha256.Sum256(key[:])
+	return localKey{id: "local-" + hex.EncodeToString(id[:8]), key: key[:]}
+}
+
+func (lk localKey) ID() string {
+	return lk.id
+}
+
+func (lk localKey) Wrap(dataKey []byte) ([]byte, error) {
+	return seal(lk.key, dataKey)
+}
+
+func (lk localKey) Unwrap(wrapped []byte) ([]byte, error) {
+	return open(lk.key, wrapped)
+}
+
+// seal encrypts with AES-GCM, the nonce is prepended to the ciphertext
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	b, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	return b, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
@@ -0,0 +1,66 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	kr := NewKeyring(NewLocalKey("current"))
+
+	e, err := kr.Seal([]byte("s3cr3t"))
+	if err != nil {
+		t.Fatal(err)
+	} else if e.KeyID != kr.KeyID() || bytes.Contains(e.Ciphertext, []byte("s3cr3t")) {
+		t.Fatalf("unexpected envelope %v", e)
+	}
+
+	b, err := kr.Open(e)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(b) != "s3cr3t" {
+		t.Errorf("expected s3cr3t got %s", b)
+	}
+
+	// each value has its own data key
+	other, err := kr.Seal([]byte("s3cr3t"))
+	if err != nil {
+		t.Fatal(err)
+	} else if bytes.Equal(other.DataKey, e.DataKey) || bytes.Equal(other.Ciphertext, e.Ciphertext) {
+		t.Error("expected a new data key per value")
+	}
+
+	e.Ciphertext[len(e.Ciphertext)-1] ^= 1
+	if _, err := kr.Open(e); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt with a tampered value got %v", err)
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	old := NewLocalKey("old")
+	e, err := NewKeyring(old).Seal([]byte("rotated"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewKeyring(NewLocalKey("new")).Open(e); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey without the old key got %v", err)
+	}
+
+	kr := NewKeyring(NewLocalKey("new"), old)
+
+	rewrapped, err := kr.Rewrap(e)
+	if err != nil {
+		t.Fatal(err)
+	} else if rewrapped.KeyID != kr.KeyID() || !bytes.Equal(rewrapped.Ciphertext, e.Ciphertext) {
+		t.Fatalf("expected the data key to be wrapped by the new key got %v", rewrapped)
+	}
+
+	b, err := NewKeyring(NewLocalKey("new")).Open(rewrapped)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(b) != "rotated" {
+		t.Errorf("expected rotated got %s", b)
+	}
+}
@@ -0,0 +1,143 @@
+package staticbackend
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/function"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestVaultSecrets(t *testing.T) {
+	for _, v := range []string{"first", "second"} {
+		resp := dbReq(t, secretActions, "PUT", "/vault/api-key", map[string]string{"value": v}, true)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatal(GetResponseBody(t, resp))
+		}
+	}
+	defer dbReq(t, secretActions, "DELETE", "/vault/api-key", nil, true)
+
+	resp := dbReq(t, secretActions, "PUT", "/vault/not.valid", map[string]string{"value": "x"}, true)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 with an invalid name got %s", resp.Status)
+	}
+
+	var value string
+	resp = dbReq(t, secretActions, "GET", "/vault/api-key", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &value); err != nil {
+		t.Fatal(err)
+	} else if value != "second" {
+		t.Errorf("expected the latest version second got %s", value)
+	}
+
+	resp = dbReq(t, secretActions, "GET", "/vault/api-key?version=1", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &value); err != nil {
+		t.Fatal(err)
+	} else if value != "first" {
+		t.Errorf("expected version 1 first got %s", value)
+	}
+
+	resp = dbReq(t, listSecrets, "GET", "/vault", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var list []model.SecretInfo
+	if err := parseBody(resp.Body, &list); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Name != "api-key" || list[0].Version != 2 || list[0].Versions != 2 {
+		t.Fatalf("unexpected secrets %v", list)
+	}
+
+	var rotated int
+	resp = dbReq(t, secretActions, "POST", "/vault/rotate", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	} else if err := parseBody(resp.Body, &rotated); err != nil {
+		t.Fatal(err)
+	} else if rotated != 2 {
+		t.Errorf("expected 2 rotated versions got %d", rotated)
+	}
+
+	resp = dbReq(t, secretActions, "DELETE", "/vault/api-key", nil, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	resp = dbReq(t, secretActions, "GET", "/vault/api-key", nil, true)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 after deleting got %s", resp.Status)
+	}
+}
+
+func TestFunctionSecret(t *testing.T) {
+	resp := dbReq(t, secretActions, "PUT", "/vault/fn-token", map[string]string{"value": "t0k3n"}, true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+	defer dbReq(t, secretActions, "DELETE", "/vault/fn-token", nil, true)
+
+	code := `
+	function handle(body) {
+		const res = secret("fn-token");
+		if (!res.ok || res.content !== "t0k3n") {
+			log("ERROR: expected the fn-token secret", res.content);
+		}
+		if (secret("fn-unknown").ok) {
+			log("ERROR: expected fn-unknown to be missing");
+		}
+	}`
+
+	data := model.ExecData{
+		FunctionName: "fn-secret",
+		Code:         code,
+		TriggerTopic: "web",
+	}
+	addResp := dbReq(t, funexec.add, "POST", "/", data, true)
+	defer addResp.Body.Close()
+	if addResp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, addResp))
+	}
+
+	execResp := dbReq(t, funexec.exec, "POST", "/fn/exec/fn-secret", url.Values{}, false, true)
+	defer execResp.Body.Close()
+	if execResp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, execResp))
+	}
+
+	// the run's history is saved in the background
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := function.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	infoResp := dbReq(t, funexec.info, "GET", "/fn/info/fn-secret", nil, true)
+	defer infoResp.Body.Close()
+	if infoResp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, infoResp))
+	}
+
+	var checkFn model.ExecData
+	if err := parseBody(infoResp.Body, &checkFn); err != nil {
+		t.Fatal(err)
+	} else if len(checkFn.History) == 0 {
+		t.Fatal("expected the function to have been executed")
+	}
+
+	for _, h := range checkFn.History {
+		for _, line := range h.Output {
+			if strings.Contains(line, "ERROR") {
+				t.Errorf("found error in function exec log: %v", h.Output)
+			}
+		}
+	}
+}
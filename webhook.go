@@ -0,0 +1,186 @@
+package staticbackend
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/staticbackendhq/core/backend"
+	"github.com/staticbackendhq/core/internal"
+	"github.com/staticbackendhq/core/middleware"
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
+)
+
+// userEventData is the user representation sent with auth lifecycle webhooks
+type userEventData struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"accountId"`
+	Email     string    `json:"email"`
+	Role      int       `json:"role"`
+	Created   time.Time `json:"created"`
+}
+
+// emitUserEvent notifies the app's webhooks of an auth lifecycle event
+func emitUserEvent(conf model.DatabaseConfig, event string, tok model.User) {
+	data := userEventData{
+		ID:        tok.ID,
+		AccountID: tok.AccountID,
+		Email:     tok.Email,
+		Role:      tok.Role,
+		Created:   tok.Created,
+	}
+
+	backend.EmitWebhook(conf, event, data)
+}
+
+// emitUserEventByEmail looks up the user before emitting the event
+func emitUserEventByEmail(conf model.DatabaseConfig, event, email string) {
+	if len(conf.Settings.Webhooks) == 0 {
+		return
+	}
+
+	tok, err := backend.DB.FindUserByEmail(conf.Name, email)
+	if err != nil {
+		backend.Log.Error().Err(err).Msgf("unable to find user for %s webhook", event)
+		return
+	}
+
+	emitUserEvent(conf, event, tok)
+}
+
+// webhooks returns or replaces the app's webhooks from /webhook
+func webhooks(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		respond(w, http.StatusOK, conf.Settings.Webhooks)
+		return
+	}
+
+	var hooks []model.WebhookSettings
+	if err := parseBody(r.Body, &hooks); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateWebhooks(hooks); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s := conf.Settings
+	s.Webhooks = hooks
+
+	if err := backend.DB.UpdateDatabaseSettings(conf.ID, s); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the cached config is used by the WithDB middleware
+	conf.Settings = s
+	if err := backend.Cache.SetTyped(conf.ID, conf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, true)
+}
+
+func validateWebhooks(hooks []model.WebhookSettings) error {
+	for _, wh := range hooks {
+		if err := validateWebhookURL(wh.URL); err != nil {
+			return err
+		} else if len(wh.Secret) == 0 {
+			return fmt.Errorf("the webhook %s requires a secret to sign its deliveries", wh.URL)
+		}
+	}
+	return nil
+}
+
+// validateFormWebhooks checks the URLs the form submissions are posted to
+func validateFormWebhooks(hooks []model.FormWebhook) error {
+	for _, hook := range hooks {
+		if err := validateWebhookURL(hook.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWebhookURL refuses the URLs targeting the instance's network, the
+// host names resolving to a private address are refused on delivery
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("invalid webhook URL %s", rawURL)
+	} else if !webhook.AllowPrivateNetworks && internal.IsPrivateHost(u.Hostname()) {
+		return fmt.Errorf("the webhook %s cannot target a private address", rawURL)
+	}
+	return nil
+}
+
+// webhookDeliveries searches the delivery log from GET /webhook/deliveries
+// with the url, event, status and limit query string parameters
+func webhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	filter := model.WebhookDeliveryFilter{
+		URL:    qs.Get("url"),
+		Event:  qs.Get("event"),
+		Status: qs.Get("status"),
+		Limit:  100,
+	}
+
+	if s := qs.Get("limit"); len(s) > 0 {
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	list, err := backend.DB.ListWebhookDeliveries(conf.Name, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(w, http.StatusOK, list)
+}
+
+// redeliverWebhook queues a delivery again from POST
+// /webhook/redeliver/{id} and returns the ID of the new delivery
+func redeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conf, _, err := middleware.Extract(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := backend.RedeliverWebhook(conf, getURLPart(r.URL.Path, 3))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	respond(w, http.StatusOK, id)
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+// Channel messages are posted in batches of up to BatchSize messages, at
+// most BatchInterval after the first one was published.
+var (
+	BatchSize     = 100
+	BatchInterval = time.Second
+)
+
+// ChannelMessage is a channel message sent with the channel.messages event
+type ChannelMessage struct {
+	Channel   string    `json:"channel"`
+	Type      string    `json:"type"`
+	Data      string    `json:"data"`
+	Encoding  string    `json:"encoding,omitempty"`
+	AccountID string    `json:"accountId,omitempty"`
+	UserID    string    `json:"userId,omitempty"`
+	Published time.Time `json:"published"`
+}
+
+type batch struct {
+	hook  model.ChannelWebhook
+	msgs  []ChannelMessage
+	timer *time.Timer
+}
+
+// ChannelBatcher groups the channel messages per database and webhook before
+// delivering them
+type ChannelBatcher struct {
+	log *logger.Logger
+
+	mx      sync.Mutex
+	pending map[string]*batch
+}
+
+// NewChannelBatcher returns a ready to use ChannelBatcher
+func NewChannelBatcher(log *logger.Logger) *ChannelBatcher {
+	return &ChannelBatcher{
+		log:     log,
+		pending: make(map[string]*batch),
+	}
+}
+
+// Add queues the message for the webhooks matching its channel
+func (cb *ChannelBatcher) Add(base string, hooks []model.ChannelWebhook, msg model.Command) {
+	for _, hook := range hooks {
+		if !hook.Matches(msg.Channel) {
+			continue
+		}
+
+		cm := ChannelMessage{
+			Channel:   msg.Channel,
+			Type:      msg.Type,
+			Data:      msg.Data,
+			Encoding:  msg.Encoding,
+			AccountID: msg.Auth.AccountID,
+			UserID:    msg.Auth.UserID,
+			Published: time.Now(),
+		}
+
+		cb.queue(base+"|"+hook.URL, hook, cm)
+	}
+}
+
+func (cb *ChannelBatcher) queue(key string, hook model.ChannelWebhook, cm ChannelMessage) {
+	cb.mx.Lock()
+	defer cb.mx.Unlock()
+
+	b, ok := cb.pending[key]
+	if !ok {
+		b = &batch{hook: hook}
+		b.timer = time.AfterFunc(BatchInterval, func() {
+			cb.flush(key, b)
+		})
+
+		cb.pending[key] = b
+	}
+
+	b.msgs = append(b.msgs, cm)
+
+	if len(b.msgs) >= BatchSize {
+		b.timer.Stop()
+		delete(cb.pending, key)
+
+		go cb.send(b)
+	}
+}
+
+func (cb *ChannelBatcher) flush(key string, b *batch) {
+	cb.mx.Lock()
+	// the batch might have been sent when it got full
+	if cb.pending[key] != b {
+		cb.mx.Unlock()
+		return
+	}
+	delete(cb.pending, key)
+	cb.mx.Unlock()
+
+	cb.send(b)
+}
+
+func (cb *ChannelBatcher) send(b *batch) {
+	event := model.WebhookChannelMessages
+
+	body, err := json.Marshal(Payload{Event: event, Created: time.Now(), Data: b.msgs})
+	if err != nil {
+		cb.log.Error().Err(err).Msgf("unable to encode %s webhook payload", event)
+		return
+	}
+
+	wh := model.WebhookSettings{URL: b.hook.URL, Secret: b.hook.Secret}
+	if err := deliver(wh, event, body); err != nil {
+		cb.log.Error().Err(err).Msgf("webhook %s to %s failed", event, wh.URL)
+	}
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/logger"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestChannelBatcher(t *testing.T) {
+	interval, size := BatchInterval, BatchSize
+	defer func() {
+		BatchInterval, BatchSize = interval, size
+	}()
+
+	BatchInterval = 50 * time.Millisecond
+	BatchSize = 3
+
+	// the test server listens on a loopback address
+	AllowPrivateNetworks = true
+	defer func() { AllowPrivateNetworks = false }()
+
+	received := make(chan []ChannelMessage, 2)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if err := Verify("secret", r.Header.Get("SB-Webhook-Signature"), r.Header.Get("SB-Webhook-Timestamp"), body, SignatureTolerance); err != nil {
+			t.Error(err)
+		}
+
+		var pl struct {
+			Event string           `json:"event"`
+			Data  []ChannelMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &pl); err != nil {
+			t.Error(err)
+		} else if pl.Event != model.WebhookChannelMessages {
+			t.Errorf("expected event %s got %s", model.WebhookChannelMessages, pl.Event)
+		}
+		received <- pl.Data
+	}))
+	defer ts.Close()
+
+	hooks := []model.ChannelWebhook{
+		{Channel: "orders-*", URL: ts.URL, Secret: "secret"},
+	}
+
+	cb := NewChannelBatcher(logger.Get(config.AppConfig{AppEnv: "dev"}))
+
+	// a full batch is sent right away
+	for i := 0; i < 3; i++ {
+		cb.Add("testdb", hooks, model.Command{Channel: "orders-new", Type: "created", Data: "order"})
+	}
+	// not matching, should not be sent
+	cb.Add("testdb", hooks, model.Command{Channel: "chat", Type: "msg", Data: "ignored"})
+
+	select {
+	case msgs := <-received:
+		if len(msgs) != 3 {
+			t.Errorf("expected a batch of 3 messages got %d", len(msgs))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("full batch was not delivered")
+	}
+
+	// a partial batch is sent after the interval
+	cb.Add("testdb", hooks, model.Command{Channel: "orders-paid", Type: "paid", Data: "order"})
+
+	select {
+	case msgs := <-received:
+		if len(msgs) != 1 {
+			t.Errorf("expected a batch of 1 message got %d", len(msgs))
+		} else if msgs[0].Channel != "orders-paid" {
+			t.Errorf("expected channel orders-paid got %s", msgs[0].Channel)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("partial batch was not delivered")
+	}
+}
@@ -0,0 +1,148 @@
+// Package webhook delivers signed event notifications to the URLs configured
+// in an app's settings.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/staticbackendhq/core/internal"
+	"github.com/staticbackendhq/core/model"
+)
+
+// RetryDelays are the waits between the delivery attempts of the channel
+// messages batches
+var RetryDelays = []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute}
+
+// AllowPrivateNetworks lets the webhooks be delivered to private, loopback
+// and link-local addresses, i.e. to services of the instance's network
+var AllowPrivateNetworks = false
+
+// client checks the address of each connection once the webhook's host is
+// resolved, a public host name could point to an internal address
+var client = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: checkAddress,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+}
+
+// checkAddress refuses the connections to private addresses unless
+// AllowPrivateNetworks is set
+func checkAddress(network, address string, c syscall.RawConn) error {
+	if AllowPrivateNetworks {
+		return nil
+	}
+	return internal.CheckPublicAddress(network, address, c)
+}
+
+// SignatureTolerance is how old a delivery's timestamp can be for Verify to
+// accept it. Receivers should reject older deliveries so a captured request
+// cannot be replayed.
+const SignatureTolerance = 5 * time.Minute
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpiredTimestamp = errors.New("webhook timestamp outside of the tolerance")
+)
+
+// Payload is the JSON body posted to a webhook
+type Payload struct {
+	Event   string    `json:"event"`
+	Created time.Time `json:"created"`
+	Data    any       `json:"data"`
+}
+
+// deliver posts the event to the webhook, retrying failed deliveries per
+// RetryDelays
+func deliver(wh model.WebhookSettings, event string, body []byte) (err error) {
+	for i := 0; i <= len(RetryDelays); i++ {
+		if i > 0 {
+			time.Sleep(RetryDelays[i-1])
+		}
+
+		if _, err = post(wh, event, "", body); err == nil {
+			return nil
+		}
+	}
+	return
+}
+
+// Send posts a queued delivery once and returns the response status code,
+// the delivery ID lets receivers ignore the redeliveries they processed.
+func Send(wh model.WebhookSettings, event, deliveryID string, body []byte) (int, error) {
+	return post(wh, event, deliveryID, body)
+}
+
+func post(wh model.WebhookSettings, event, deliveryID string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("SB-Webhook-Event", event)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("SB-Webhook-Timestamp", ts)
+	req.Header.Set("SB-Webhook-Signature", Sign(wh.Secret, ts, body))
+	if len(deliveryID) > 0 {
+		req.Header.Set("SB-Webhook-Delivery", deliveryID)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "timestamp.body", where
+// timestamp is the SB-Webhook-Timestamp header in Unix seconds. Receivers
+// compute the same signature with their secret and reject timestamps older
+// than SignatureTolerance, see Verify.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify validates the SB-Webhook-Signature and SB-Webhook-Timestamp headers
+// of a delivery, the timestamp must be within tolerance of the current time.
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if d := time.Since(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrExpiredTimestamp
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestDeliverSignsAndRetries(t *testing.T) {
+	RetryDelays = []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}
+
+	// the test server listens on a loopback address
+	AllowPrivateNetworks = true
+	defer func() { AllowPrivateNetworks = false }()
+
+	received := make(chan Payload, 1)
+	attempts := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if err := Verify("secret", r.Header.Get("SB-Webhook-Signature"), r.Header.Get("SB-Webhook-Timestamp"), body, SignatureTolerance); err != nil {
+			t.Error(err)
+		}
+
+		var pl Payload
+		if err := json.Unmarshal(body, &pl); err != nil {
+			t.Error(err)
+		}
+		received <- pl
+	}))
+	defer ts.Close()
+
+	wh := model.WebhookSettings{URL: ts.URL, Secret: "secret"}
+
+	body, err := json.Marshal(Payload{Event: model.WebhookUserCreated, Created: time.Now(), Data: "user-id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deliver(wh, model.WebhookUserCreated, body); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case pl := <-received:
+		if pl.Event != model.WebhookUserCreated {
+			t.Errorf("expected event %s got %s", model.WebhookUserCreated, pl.Event)
+		} else if pl.Data != "user-id" {
+			t.Errorf("expected data to be user-id got %v", pl.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts got %d", attempts)
+	}
+}
+
+func TestSendRefusesPrivateAddresses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the webhook should not be delivered to a loopback address")
+	}))
+	defer ts.Close()
+
+	wh := model.WebhookSettings{URL: ts.URL, Secret: "secret"}
+	if _, err := Send(wh, model.WebhookUserCreated, "delivery-id", []byte("{}")); err == nil {
+		t.Error("expected the delivery to a private address to fail")
+	}
+}
+
+func TestVerifyRejectsReplays(t *testing.T) {
+	body := []byte(`{"event":"user.created"}`)
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := Verify("secret", Sign("secret", now, body), now, body, SignatureTolerance); err != nil {
+		t.Errorf("expected a fresh delivery to be valid got %v", err)
+	}
+
+	if err := Verify("secret", Sign("secret", now, body), now, []byte(`{}`), SignatureTolerance); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected a changed body to be rejected got %v", err)
+	}
+
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	if err := Verify("secret", Sign("secret", old, body), old, body, SignatureTolerance); !errors.Is(err, ErrExpiredTimestamp) {
+		t.Errorf("expected an old delivery to be rejected got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package staticbackend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/core/model"
+	"github.com/staticbackendhq/core/webhook"
+)
+
+func TestWebhookDeliveryLog(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	invalid := []model.WebhookSettings{{URL: "hooks.domain.com", Secret: "unit-test"}}
+
+	resp := dbReq(t, webhooks, "POST", "/webhook", invalid, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an URL without scheme got %d", resp.StatusCode)
+	}
+
+	webhook.AllowPrivateNetworks = false
+	private := []model.WebhookSettings{{URL: "http://169.254.169.254/latest", Secret: "unit-test"}}
+
+	resp = dbReq(t, webhooks, "POST", "/webhook", private, true)
+	defer resp.Body.Close()
+	webhook.AllowPrivateNetworks = true
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a private address got %d", resp.StatusCode)
+	}
+
+	hooks := []model.WebhookSettings{
+		{URL: ts.URL, Secret: "unit-test", Events: []string{model.WebhookUserCreated}},
+	}
+
+	resp = dbReq(t, webhooks, "POST", "/webhook", hooks, true)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	defer func() {
+		resp := dbReq(t, webhooks, "POST", "/webhook", []model.WebhookSettings{}, true)
+		resp.Body.Close()
+	}()
+
+	l := model.Login{Email: "webhooklog@test.com", Password: "webhook1234"}
+	resp = dbReq(t, mship.register, "POST", "/register", l)
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		t.Fatal(GetResponseBody(t, resp))
+	}
+
+	var delivered model.WebhookDelivery
+	for i := 0; i < 20 && len(delivered.ID) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+
+		resp := dbReq(t, webhookDeliveries, "GET", "/webhook/deliveries?status=delivered&url="+ts.URL, nil, true)
+		defer resp.Body.Close()
+
+		var list []model.WebhookDelivery
+		if err := parseBody(resp.Body, &list); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(list) > 0 {
+			delivered = list[0]
+		}
+	}
+
+	if len(delivered.ID) == 0 {
+		t.Fatal("expected the user.created event to be delivered")
+	} else if delivered.Event != model.WebhookUserCreated {
+		t.Errorf("expected event %s got %s", model.WebhookUserCreated, delivered.Event)
+	}
+
+	resp = dbReq(t, redeliverWebhook, "POST", "/webhook/redeliver/"+delivered.ID, nil, true)
+	defer resp.Body.Close()
+
+	var id string
+	if err := parseBody(resp.Body, &id); err != nil {
+		t.Fatal(err)
+	} else if len(id) == 0 || id == delivered.ID {
+		t.Errorf("expected a new delivery ID got %s", id)
+	}
+}